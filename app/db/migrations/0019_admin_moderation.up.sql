@@ -0,0 +1,16 @@
+-- +migrate Up
+-- Track who verified a POI and when (admin/moderator moderation)
+ALTER TABLE points_of_interest
+ADD COLUMN IF NOT EXISTS verified_at TIMESTAMPTZ,
+ADD COLUMN IF NOT EXISTS verified_by UUID REFERENCES users (id) ON DELETE SET NULL;
+
+-- Mark subscriptions that were set by an admin rather than a payment provider
+ALTER TABLE subscriptions
+ADD COLUMN IF NOT EXISTS override_reason TEXT;
+
+-- Indexes for admin user search and filtering
+CREATE INDEX IF NOT EXISTS idx_users_role ON users (role);
+
+CREATE INDEX IF NOT EXISTS idx_users_is_active ON users (is_active);
+
+CREATE INDEX IF NOT EXISTS idx_poi_is_verified ON points_of_interest (is_verified);
@@ -0,0 +1,39 @@
+-- +migrate Up
+-- Append-only record of security-sensitive and admin actions.
+-- actor_id/target_id are deliberately not foreign keys: entries must outlive the rows they describe.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    actor_id UUID, -- NULL for system actions
+    action TEXT NOT NULL, -- e.g. 'auth.password_changed', 'admin.user_role_changed'
+    target_type TEXT NOT NULL, -- e.g. 'user', 'poi', 'list'
+    target_id UUID,
+    before JSONB, -- Changed fields only, previous values
+    after JSONB, -- Changed fields only, new values
+    metadata JSONB,
+    ip_address INET,
+    user_agent TEXT,
+    request_id TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log (created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log (target_type, target_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_action ON audit_log (action, created_at DESC);
+
+-- Reject UPDATE and DELETE so the log stays append-only
+CREATE OR REPLACE FUNCTION audit_log_append_only()
+    RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trigger_audit_log_append_only ON audit_log;
+
+CREATE TRIGGER trigger_audit_log_append_only
+BEFORE UPDATE OR DELETE ON audit_log
+FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
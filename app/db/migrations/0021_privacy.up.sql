@@ -0,0 +1,71 @@
+-- +migrate Up
+-- GDPR "download my data" jobs. The worker claims pending rows, writes a ZIP
+-- of JSON files to disk and records where it lives until expires_at.
+CREATE TABLE user_data_exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (
+        status IN (
+            'pending',
+            'processing',
+            'completed',
+            'failed',
+            'expired'
+        )
+    ),
+    file_path TEXT,
+    file_size_bytes BIGINT,
+    error_message TEXT,
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_user_data_exports_user_id ON user_data_exports (user_id, requested_at DESC);
+
+CREATE INDEX idx_user_data_exports_pending ON user_data_exports (requested_at)
+WHERE status = 'pending';
+
+-- Only one export in flight per user
+CREATE UNIQUE INDEX idx_user_data_exports_one_active ON user_data_exports (user_id)
+WHERE status IN ('pending', 'processing');
+
+-- Account deletion with a grace period. The account stays usable (so the
+-- user can cancel) until deletion_scheduled_for, when it is purged.
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS deletion_requested_at TIMESTAMPTZ,
+ADD COLUMN IF NOT EXISTS deletion_scheduled_for TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_users_deletion_scheduled_for ON users (deletion_scheduled_for)
+WHERE deletion_scheduled_for IS NOT NULL;
+
+-- Reviews outlive their author: they are anonymized instead of deleted.
+ALTER TABLE reviews ALTER COLUMN user_id DROP NOT NULL;
+
+ALTER TABLE reviews DROP CONSTRAINT IF EXISTS reviews_user_id_fkey;
+
+ALTER TABLE reviews
+ADD CONSTRAINT reviews_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL;
+
+-- The audit log outlives deleted accounts, but not their personal data:
+-- profile and email diffs, and where the user acted from. A purge sets
+-- audit_log.redact for its transaction, which lets it null before, after,
+-- ip_address and user_agent; nothing else about an entry can change, and
+-- without the setting the log stays append-only.
+CREATE OR REPLACE FUNCTION audit_log_append_only()
+    RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+       AND current_setting('audit_log.redact', TRUE) = 'on'
+       AND (NEW.id, NEW.actor_id, NEW.action, NEW.target_type, NEW.target_id, NEW.metadata, NEW.request_id, NEW.created_at)
+           IS NOT DISTINCT FROM (OLD.id, OLD.actor_id, OLD.action, OLD.target_type, OLD.target_id, OLD.metadata, OLD.request_id, OLD.created_at)
+       AND (NEW.before IS NULL OR NEW.before IS NOT DISTINCT FROM OLD.before)
+       AND (NEW.after IS NULL OR NEW.after IS NOT DISTINCT FROM OLD.after)
+       AND (NEW.ip_address IS NULL OR NEW.ip_address IS NOT DISTINCT FROM OLD.ip_address)
+       AND (NEW.user_agent IS NULL OR NEW.user_agent IS NOT DISTINCT FROM OLD.user_agent) THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,53 @@
+-- +migrate Up
+-- Durable background jobs. Workers claim due rows with a lease; a job whose
+-- lease runs out (worker crashed) is picked up again by another worker.
+CREATE TABLE background_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    kind VARCHAR(50) NOT NULL,
+    -- Entity the job works on; NULL for jobs that sweep a whole table
+    target_id UUID,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (
+        status IN (
+            'pending',
+            'running',
+            'completed',
+            'failed'
+        )
+    ),
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 5,
+    progress_total INT NOT NULL DEFAULT 0,
+    progress_done INT NOT NULL DEFAULT 0,
+    progress_failed INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    run_after TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_by TEXT,
+    lease_expires_at TIMESTAMPTZ,
+    requested_by UUID REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER trigger_set_background_jobs_updated_at
+BEFORE UPDATE ON background_jobs
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+-- Claim order for the workers
+CREATE INDEX idx_background_jobs_due ON background_jobs (run_after)
+WHERE status = 'pending';
+
+CREATE INDEX idx_background_jobs_lease ON background_jobs (lease_expires_at)
+WHERE status = 'running';
+
+CREATE INDEX idx_background_jobs_kind_created ON background_jobs (kind, created_at DESC);
+
+-- At most one queued or running job per kind and target, so enqueueing is idempotent
+CREATE UNIQUE INDEX idx_background_jobs_one_active ON background_jobs (kind, target_id) NULLS NOT DISTINCT
+WHERE status IN ('pending', 'running');
+
+-- Queue embeddings for rows that were created before the job queue existed
+INSERT INTO background_jobs (kind)
+VALUES ('poi_embedding'), ('city_embedding'), ('user_preference_embedding');
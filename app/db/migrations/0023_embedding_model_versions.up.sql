@@ -0,0 +1,61 @@
+-- +migrate Up
+-- Record which embedding model produced each vector. Vectors from different
+-- models are not comparable, so searches only match rows from the active model.
+--
+-- Re-embedding a table to a new model writes into the *_next shadow columns
+-- while searches keep using the active ones; cutover swaps them in one transaction.
+ALTER TABLE points_of_interest
+ADD COLUMN IF NOT EXISTS embedding_model TEXT,
+ADD COLUMN IF NOT EXISTS embedding_model_version INT,
+ADD COLUMN IF NOT EXISTS embedding_next VECTOR (768),
+ADD COLUMN IF NOT EXISTS embedding_next_model TEXT,
+ADD COLUMN IF NOT EXISTS embedding_next_model_version INT;
+
+ALTER TABLE cities
+ADD COLUMN IF NOT EXISTS embedding_model TEXT,
+ADD COLUMN IF NOT EXISTS embedding_model_version INT,
+ADD COLUMN IF NOT EXISTS embedding_next VECTOR (768),
+ADD COLUMN IF NOT EXISTS embedding_next_model TEXT,
+ADD COLUMN IF NOT EXISTS embedding_next_model_version INT;
+
+-- The active model per table, and the model a re-embedding run is migrating to.
+-- Search profiles get their taste vectors in 0024.
+CREATE TABLE embedding_models (
+    target_table TEXT PRIMARY KEY CHECK (
+        target_table IN (
+            'points_of_interest',
+            'cities',
+            'user_preference_profiles'
+        )
+    ),
+    model TEXT NOT NULL,
+    model_version INT NOT NULL,
+    next_model TEXT,
+    next_model_version INT,
+    migration_started_at TIMESTAMPTZ,
+    cutover_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((next_model IS NULL) = (next_model_version IS NULL))
+);
+
+CREATE TRIGGER trigger_set_embedding_models_updated_at
+BEFORE UPDATE ON embedding_models
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+-- Everything embedded so far came from the model the service has always used
+INSERT INTO embedding_models (target_table, model, model_version)
+VALUES ('points_of_interest', 'gemini-embedding-exp-03-07', 1),
+    ('cities', 'gemini-embedding-exp-03-07', 1),
+    ('user_preference_profiles', 'gemini-embedding-exp-03-07', 1);
+
+UPDATE points_of_interest
+SET embedding_model = 'gemini-embedding-exp-03-07', embedding_model_version = 1
+WHERE embedding IS NOT NULL;
+
+UPDATE cities
+SET embedding_model = 'gemini-embedding-exp-03-07', embedding_model_version = 1
+WHERE embedding IS NOT NULL;
+
+COMMENT ON COLUMN points_of_interest.embedding IS 'Text embedding vector (768 dimensions); embedding_model and embedding_model_version record the model that produced it';
+COMMENT ON COLUMN cities.embedding IS 'Text embedding vector (768 dimensions); embedding_model and embedding_model_version record the model that produced it';
@@ -0,0 +1,52 @@
+-- +migrate Up
+-- Taste vectors move from user_interests to search profiles. Interests, tags and
+-- preferences are kept per profile, and user_interests has no row for users who
+-- only set up profiles. Its vectors are derived data and are regenerated by the
+-- user_preference_embedding sweep.
+--
+-- user_interests.preference_embedding and its HNSW index (0017) are dropped
+-- rather than left unused. The column held one vector per (user, interest)
+-- pair: the embedding job wrote the same user-level vector onto every interest
+-- row of the user, and nothing reads it now. Left in place, the index is still
+-- maintained on every interest change. Queued jobs are carried over to
+-- profiles below.
+ALTER TABLE user_preference_profiles
+ADD COLUMN IF NOT EXISTS preference_embedding VECTOR (768),
+ADD COLUMN IF NOT EXISTS embedding_model TEXT,
+ADD COLUMN IF NOT EXISTS embedding_model_version INT,
+ADD COLUMN IF NOT EXISTS preference_embedding_next VECTOR (768),
+ADD COLUMN IF NOT EXISTS embedding_next_model TEXT,
+ADD COLUMN IF NOT EXISTS embedding_next_model_version INT,
+ADD COLUMN IF NOT EXISTS embedding_generated_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_user_preference_profiles_embedding_hnsw
+ON user_preference_profiles
+USING hnsw (preference_embedding vector_cosine_ops)
+WITH (m = 16, ef_construction = 64);
+
+COMMENT ON COLUMN user_preference_profiles.preference_embedding IS 'Taste vector (768 dimensions) built from the profile interests, the user''s custom interests and avoid tags, and the profile settings';
+
+DROP INDEX IF EXISTS idx_user_interests_embedding_hnsw;
+
+ALTER TABLE user_interests
+DROP COLUMN IF EXISTS preference_embedding;
+
+-- Queued preference jobs targeted users; they now target profiles. Point them
+-- at the user's default profile, whose settings the user-level vector was built
+-- from. The sweep covers the user's other profiles.
+UPDATE background_jobs j
+SET target_id = p.id
+FROM user_preference_profiles p
+WHERE j.kind IN ('user_preference_embedding', 'user_preference_reembed')
+    AND j.status IN ('pending', 'running')
+    AND p.user_id = j.target_id
+    AND p.is_default;
+
+-- Users without a default profile have nothing to embed
+DELETE FROM background_jobs j
+WHERE j.kind IN ('user_preference_embedding', 'user_preference_reembed')
+    AND j.status IN ('pending', 'running')
+    AND j.target_id IS NOT NULL
+    AND NOT EXISTS (
+        SELECT 1 FROM user_preference_profiles p WHERE p.id = j.target_id
+    );
@@ -0,0 +1,45 @@
+-- +migrate Up
+-- Implicit feedback: what users keep (favourites, saved itineraries, list
+-- additions) and what they remove. The learner folds events into per-user
+-- category and tag affinities, which feed the taste vectors and POI ranking.
+CREATE TABLE user_interaction_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    -- NULL for POIs that were only ever suggested by the LLM
+    poi_id UUID REFERENCES points_of_interest (id) ON DELETE SET NULL,
+    poi_name TEXT NOT NULL DEFAULT '',
+    category TEXT,
+    tags TEXT [] NOT NULL DEFAULT '{}',
+    kind VARCHAR(30) NOT NULL CHECK (
+        kind IN (
+            'favourite',
+            'unfavourite',
+            'itinerary_save',
+            'itinerary_remove',
+            'list_add'
+        )
+    ),
+    -- Positive when the user kept the POI, negative when they got rid of it
+    weight DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- Set once the learner has folded the event into the user's affinities
+    learned_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_user_interaction_events_unlearned ON user_interaction_events (user_id, created_at)
+WHERE learned_at IS NULL;
+
+CREATE INDEX idx_user_interaction_events_user_poi ON user_interaction_events (user_id, poi_id);
+
+CREATE INDEX idx_user_interaction_events_user_name ON user_interaction_events (user_id, LOWER(poi_name));
+
+CREATE TABLE user_affinities (
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    dimension VARCHAR(20) NOT NULL CHECK (dimension IN ('category', 'tag')),
+    -- Lower-cased category or tag
+    value TEXT NOT NULL,
+    -- Decayed sum of event weights; positive means the user favours it
+    score DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, dimension, value)
+);
@@ -0,0 +1,116 @@
+-- +migrate Up
+-- Lexical POI search: a weighted tsvector over name, tags, description and
+-- address, built with the text search dictionary of the POI's city, plus
+-- trigram indexes so misspelt names still match.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE cities
+ADD COLUMN IF NOT EXISTS text_search_config REGCONFIG NOT NULL DEFAULT 'simple';
+
+COMMENT ON COLUMN cities.text_search_config IS 'Text search dictionary for the local language, used to stem POI names and descriptions';
+
+-- Countries whose language has a Snowball stemmer; everywhere else falls back
+-- to the language-neutral simple dictionary
+CREATE OR REPLACE FUNCTION text_search_config_for_country(country TEXT) RETURNS REGCONFIG AS $$
+    SELECT (CASE LOWER(country)
+        WHEN 'portugal' THEN 'portuguese'
+        WHEN 'brazil' THEN 'portuguese'
+        WHEN 'spain' THEN 'spanish'
+        WHEN 'mexico' THEN 'spanish'
+        WHEN 'argentina' THEN 'spanish'
+        WHEN 'france' THEN 'french'
+        WHEN 'germany' THEN 'german'
+        WHEN 'austria' THEN 'german'
+        WHEN 'italy' THEN 'italian'
+        WHEN 'netherlands' THEN 'dutch'
+        WHEN 'denmark' THEN 'danish'
+        WHEN 'sweden' THEN 'swedish'
+        WHEN 'norway' THEN 'norwegian'
+        WHEN 'finland' THEN 'finnish'
+        WHEN 'hungary' THEN 'hungarian'
+        WHEN 'romania' THEN 'romanian'
+        WHEN 'russia' THEN 'russian'
+        WHEN 'turkey' THEN 'turkish'
+        WHEN 'united kingdom' THEN 'english'
+        WHEN 'uk' THEN 'english'
+        WHEN 'ireland' THEN 'english'
+        WHEN 'united states' THEN 'english'
+        WHEN 'usa' THEN 'english'
+        WHEN 'canada' THEN 'english'
+        WHEN 'australia' THEN 'english'
+        WHEN 'new zealand' THEN 'english'
+        ELSE 'simple'
+    END)::regconfig;
+$$ LANGUAGE sql IMMUTABLE;
+
+UPDATE cities SET text_search_config = text_search_config_for_country(country);
+
+-- New cities pick the dictionary of their country unless one is given
+CREATE OR REPLACE FUNCTION city_search_config_default() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.text_search_config = 'simple'::regconfig THEN
+        NEW.text_search_config := text_search_config_for_country(NEW.country);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_city_search_config_default
+BEFORE INSERT ON cities
+FOR EACH ROW EXECUTE FUNCTION city_search_config_default();
+
+ALTER TABLE points_of_interest
+ADD COLUMN IF NOT EXISTS search_config REGCONFIG NOT NULL DEFAULT 'simple',
+ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+-- Name weighs most, then tags and category, then the descriptions, then the address
+CREATE OR REPLACE FUNCTION poi_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_config := COALESCE(
+        (SELECT c.text_search_config FROM cities c WHERE c.id = NEW.city_id),
+        'simple'::regconfig
+    );
+    NEW.search_vector :=
+        setweight(to_tsvector(NEW.search_config, COALESCE(NEW.name, '')), 'A') ||
+        setweight(to_tsvector(NEW.search_config,
+            COALESCE(array_to_string(NEW.tags, ' '), '') || ' ' ||
+            COALESCE(NEW.category, '') || ' ' || COALESCE(NEW.poi_type, '')), 'B') ||
+        setweight(to_tsvector(NEW.search_config,
+            COALESCE(NEW.description, '') || ' ' || COALESCE(NEW.ai_summary, '')), 'C') ||
+        setweight(to_tsvector(NEW.search_config, COALESCE(NEW.address, '')), 'D');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_poi_search_vector
+BEFORE INSERT OR UPDATE OF name, description, ai_summary, tags, category, poi_type, address, city_id
+ON points_of_interest
+FOR EACH ROW EXECUTE FUNCTION poi_search_vector_update();
+
+-- Re-stem a city's POIs when its dictionary changes
+CREATE OR REPLACE FUNCTION city_search_config_update() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE points_of_interest SET city_id = city_id WHERE city_id = NEW.id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_city_search_config
+AFTER UPDATE OF text_search_config ON cities
+FOR EACH ROW
+WHEN (OLD.text_search_config IS DISTINCT FROM NEW.text_search_config)
+EXECUTE FUNCTION city_search_config_update();
+
+-- Build the vectors of existing POIs
+UPDATE points_of_interest SET city_id = city_id;
+
+CREATE INDEX IF NOT EXISTS idx_poi_search_vector ON points_of_interest USING GIN (search_vector);
+
+CREATE INDEX IF NOT EXISTS idx_poi_name_trgm ON points_of_interest USING GIN (name gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_poi_address_trgm ON points_of_interest USING GIN (address gin_trgm_ops);
+
+-- Superseded by idx_poi_search_vector
+DROP INDEX IF EXISTS idx_poi_name;
+
+DROP INDEX IF EXISTS idx_poi_description;
@@ -0,0 +1,22 @@
+-- +migrate Up
+-- Collection endpoints page with keyset cursors: each page continues strictly
+-- after the sort key of the previous one. These indexes match each listing's
+-- filter and sort order so a page is an index range scan however deep it is.
+CREATE INDEX IF NOT EXISTS idx_user_saved_itineraries_user_created ON user_saved_itineraries (user_id, created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_user_favorite_pois_user_added ON user_favorite_pois (user_id, added_at DESC, poi_id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_lists_user_itinerary_created ON lists (
+    user_id,
+    is_itinerary,
+    created_at DESC,
+    id DESC
+);
+
+CREATE INDEX IF NOT EXISTS idx_llm_interactions_user_created ON llm_interactions (user_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_users_created_id ON users (created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_background_jobs_created_id ON background_jobs (created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_cities_name_id ON cities (name, id) WHERE center_location IS NOT NULL;
@@ -0,0 +1,56 @@
+-- +migrate Up
+-- Restaurant search filters on what a restaurant offers, so dining preferences
+-- can be matched in the database rather than only described to the LLM.
+-- Values are lowercase snake_case keys, e.g. dietary_options {vegan, halal},
+-- allergen_free {gluten, nuts}, service_style 'fine_dining' and
+-- features {outdoor_seating, live_music}.
+ALTER TABLE restaurant_details
+ADD COLUMN IF NOT EXISTS dietary_options TEXT[] NOT NULL DEFAULT '{}',
+ADD COLUMN IF NOT EXISTS allergen_free TEXT[] NOT NULL DEFAULT '{}',
+ADD COLUMN IF NOT EXISTS service_style TEXT,
+ADD COLUMN IF NOT EXISTS features TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_restaurant_details_dietary_options ON restaurant_details USING GIN (dietary_options);
+
+CREATE INDEX IF NOT EXISTS idx_restaurant_details_allergen_free ON restaurant_details USING GIN (allergen_free);
+
+CREATE INDEX IF NOT EXISTS idx_restaurant_details_features ON restaurant_details USING GIN (features);
+
+CREATE INDEX IF NOT EXISTS idx_restaurant_details_city_cuisine ON restaurant_details (city_id, lower(cuisine_type));
+
+-- Whether opening hours stored as {"monday": "12:00-15:00, 19:00-23:00", ...}
+-- are open at a local time. A range closing at or before it opens runs past
+-- midnight, so yesterday's late ranges count too. Days without hours, or with
+-- hours in any other form, are closed.
+CREATE OR REPLACE FUNCTION opening_hours_open_at(hours JSONB, at_local TIMESTAMP) RETURNS BOOLEAN AS $$
+DECLARE
+    t TIME := at_local::time;
+    day_range TEXT;
+    opens TIME;
+    closes TIME;
+BEGIN
+    IF hours IS NULL OR jsonb_typeof(hours) <> 'object' THEN
+        RETURN FALSE;
+    END IF;
+
+    FOREACH day_range IN ARRAY COALESCE(string_to_array(hours ->> lower(to_char(at_local, 'FMDay')), ','), '{}') LOOP
+        CONTINUE WHEN day_range !~ '^\s*\d{1,2}:\d{2}\s*-\s*\d{1,2}:\d{2}\s*$';
+        opens := trim(split_part(day_range, '-', 1))::time;
+        closes := trim(split_part(day_range, '-', 2))::time;
+        IF t >= opens AND (closes <= opens OR t < closes) THEN
+            RETURN TRUE;
+        END IF;
+    END LOOP;
+
+    FOREACH day_range IN ARRAY COALESCE(string_to_array(hours ->> lower(to_char(at_local - INTERVAL '1 day', 'FMDay')), ','), '{}') LOOP
+        CONTINUE WHEN day_range !~ '^\s*\d{1,2}:\d{2}\s*-\s*\d{1,2}:\d{2}\s*$';
+        opens := trim(split_part(day_range, '-', 1))::time;
+        closes := trim(split_part(day_range, '-', 2))::time;
+        IF closes <= opens AND t < closes THEN
+            RETURN TRUE;
+        END IF;
+    END LOOP;
+
+    RETURN FALSE;
+END;
+$$ LANGUAGE plpgsql STABLE;
@@ -0,0 +1,104 @@
+-- +migrate Up
+-- Who says what about a restaurant's diets and allergens. The LLM's guesses are
+-- recorded when a restaurant is saved; users report corrections and staff
+-- verify. Per attribute the most trusted source wins, and within a source the
+-- latest word. restaurant_details.dietary_options and allergen_free hold the
+-- attributes that are currently available, so search filters stay indexed.
+-- An LLM guess is not enough to keep someone with an allergy safe:
+-- allergen_free_confirmed holds the allergens staff verified, or that at least
+-- three different users report the kitchen avoids, with more users agreeing
+-- than disagreeing. One report, or a handful of accounts outvoted, is not
+-- enough.
+ALTER TABLE restaurant_details
+ADD COLUMN IF NOT EXISTS allergen_free_confirmed TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_restaurant_details_allergen_free_confirmed ON restaurant_details USING GIN (allergen_free_confirmed);
+
+CREATE TABLE restaurant_dietary_attributes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    restaurant_id UUID NOT NULL REFERENCES restaurant_details (id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('dietary', 'allergen_free')),
+    -- Lowercase snake_case key, e.g. 'vegan', 'gluten_free' for diets and
+    -- 'gluten', 'nuts' for allergens
+    attribute TEXT NOT NULL,
+    available BOOLEAN NOT NULL,
+    source VARCHAR(20) NOT NULL CHECK (
+        source IN (
+            'llm_inferred',
+            'user_reported',
+            'verified'
+        )
+    ),
+    -- The reporting user, for user reports
+    reported_by UUID REFERENCES users (id) ON DELETE SET NULL,
+    note TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_restaurant_dietary_attributes_restaurant ON restaurant_dietary_attributes (restaurant_id, kind, attribute);
+
+-- A user's later report on an attribute replaces their earlier one
+CREATE UNIQUE INDEX idx_restaurant_dietary_attributes_user_report ON restaurant_dietary_attributes (
+    restaurant_id,
+    kind,
+    attribute,
+    reported_by
+)
+WHERE source = 'user_reported';
+
+CREATE VIEW restaurant_dietary_effective AS
+SELECT DISTINCT ON (restaurant_id, kind, attribute)
+    restaurant_id, kind, attribute, available, source, created_at AS updated_at
+FROM restaurant_dietary_attributes
+ORDER BY restaurant_id, kind, attribute,
+    CASE source WHEN 'verified' THEN 0 WHEN 'user_reported' THEN 1 ELSE 2 END,
+    created_at DESC;
+
+CREATE OR REPLACE FUNCTION restaurant_dietary_attributes_sync() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE restaurant_details r SET
+        dietary_options = ARRAY(
+            SELECT e.attribute FROM restaurant_dietary_effective e
+            WHERE e.restaurant_id = r.id AND e.kind = 'dietary' AND e.available
+            ORDER BY e.attribute
+        ),
+        allergen_free = ARRAY(
+            SELECT e.attribute FROM restaurant_dietary_effective e
+            WHERE e.restaurant_id = r.id AND e.kind = 'allergen_free' AND e.available
+            ORDER BY e.attribute
+        ),
+        allergen_free_confirmed = ARRAY(
+            SELECT e.attribute FROM restaurant_dietary_effective e
+            WHERE e.restaurant_id = r.id AND e.kind = 'allergen_free' AND e.available
+              AND (
+                  e.source = 'verified'
+                  OR (
+                      e.source = 'user_reported'
+                      AND (
+                          SELECT COUNT(DISTINCT a.reported_by) FILTER (WHERE a.available) >= 3
+                             AND COUNT(DISTINCT a.reported_by) FILTER (WHERE a.available)
+                                 > COUNT(DISTINCT a.reported_by) FILTER (WHERE NOT a.available)
+                          FROM restaurant_dietary_attributes a
+                          WHERE a.restaurant_id = r.id AND a.kind = 'allergen_free'
+                            AND a.attribute = e.attribute AND a.source = 'user_reported'
+                      )
+                  )
+              )
+            ORDER BY e.attribute
+        )
+    WHERE r.id = NEW.restaurant_id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_restaurant_dietary_attributes_sync
+AFTER INSERT OR UPDATE ON restaurant_dietary_attributes
+FOR EACH ROW EXECUTE FUNCTION restaurant_dietary_attributes_sync();
+
+-- What earlier restaurants list was inferred by the LLM
+INSERT INTO restaurant_dietary_attributes (restaurant_id, kind, attribute, available, source)
+SELECT id, 'dietary', unnest(dietary_options), TRUE, 'llm_inferred'
+FROM restaurant_details
+UNION ALL
+SELECT id, 'allergen_free', unnest(allergen_free), TRUE, 'llm_inferred'
+FROM restaurant_details;
@@ -0,0 +1,79 @@
+-- +migrate Up
+-- Structured accessibility, replacing the free text accessibility_info for
+-- matching. A POI's accessibility maps feature keys to whether it has them,
+-- e.g. {"step_free_entrance": true, "hearing_loop": false}; a feature that is
+-- not listed is unknown. Search profiles list the features a user needs.
+ALTER TABLE points_of_interest
+ADD COLUMN IF NOT EXISTS accessibility JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_poi_accessibility ON points_of_interest USING GIN (accessibility);
+
+-- Seed the features from accessibility_info where the text plainly says
+-- whether a POI has them. Denials are matched first, so "no lift" does not
+-- count as a lift; features the text does not mention stay unknown.
+UPDATE points_of_interest
+SET accessibility = jsonb_strip_nulls(jsonb_build_object(
+    'step_free_entrance', CASE
+        WHEN accessibility_info ~* '\m(not|no|without)\s+(wheelchair[- ]accessible|step[- ]free|level access|ramp)|wheelchair[- ]inaccessible' THEN FALSE
+        WHEN accessibility_info ~* 'wheelchair[- ]accessible|step[- ]free|level access|\mramps?\M' THEN TRUE
+    END,
+    'accessible_toilet', CASE
+        WHEN accessibility_info ~* '\m(no|without)\s+(accessible|disabled)\s+(toilet|restroom|bathroom)' THEN FALSE
+        WHEN accessibility_info ~* '(accessible|disabled|wheelchair)\s+(toilet|restroom|bathroom)' THEN TRUE
+    END,
+    'elevator', CASE
+        WHEN accessibility_info ~* '\m(no|without)\s+(elevator|lift)\M' THEN FALSE
+        WHEN accessibility_info ~* '\m(elevators?|lifts?)\M' THEN TRUE
+    END,
+    'hearing_loop', CASE
+        WHEN accessibility_info ~* '\m(no|without)\s+(hearing|induction) loop' THEN FALSE
+        WHEN accessibility_info ~* '(hearing|induction) loop' THEN TRUE
+    END,
+    'accessible_parking', CASE
+        WHEN accessibility_info ~* '\m(no|without)\s+(accessible|disabled) parking' THEN FALSE
+        WHEN accessibility_info ~* '(accessible|disabled) parking|blue badge' THEN TRUE
+    END,
+    'braille_signage', CASE
+        WHEN accessibility_info ~* '\m(no|without)\s+braille' THEN FALSE
+        WHEN accessibility_info ~* '\mbraille' THEN TRUE
+    END,
+    'service_animals', CASE
+        WHEN accessibility_info ~* '\m(no|without)\s+(service animals?|guide dogs?|assistance dogs?)' THEN FALSE
+        WHEN accessibility_info ~* 'service animals?|guide dogs?|assistance dogs?' THEN TRUE
+    END
+))
+WHERE accessibility_info IS NOT NULL
+  AND accessibility = '{}';
+
+ALTER TABLE user_preference_profiles
+ADD COLUMN IF NOT EXISTS accessibility_needs JSONB NOT NULL DEFAULT '{}';
+
+-- prefer_accessible_pois only ever meant a step-free way in
+UPDATE user_preference_profiles
+SET accessibility_needs = '{"features": ["step_free_entrance"]}'
+WHERE prefer_accessible_pois
+  AND accessibility_needs = '{}';
+
+-- Whether a POI meets every needed feature: 'not_accessible' when it is known
+-- to lack one, 'accessible' when it is known to have them all and 'unknown'
+-- otherwise.
+CREATE OR REPLACE FUNCTION accessibility_status(accessibility JSONB, needs TEXT[]) RETURNS TEXT AS $$
+DECLARE
+    need TEXT;
+    known BOOLEAN := TRUE;
+BEGIN
+    FOREACH need IN ARRAY COALESCE(needs, '{}') LOOP
+        IF accessibility -> need = 'false'::jsonb THEN
+            RETURN 'not_accessible';
+        END IF;
+        IF accessibility -> need IS DISTINCT FROM 'true'::jsonb THEN
+            known := FALSE;
+        END IF;
+    END LOOP;
+
+    IF known THEN
+        RETURN 'accessible';
+    END IF;
+    RETURN 'unknown';
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
@@ -0,0 +1,41 @@
+-- +migrate Up
+-- Public transit timetables imported from GTFS feeds, used for travel times
+-- between itinerary stops. Importing a feed replaces its stops and rides.
+CREATE TABLE transit_feeds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    name TEXT NOT NULL,
+    -- Where the GTFS zip is downloaded from on every import
+    source_url TEXT NOT NULL,
+    city_id UUID REFERENCES cities (id) ON DELETE SET NULL,
+    stop_count INT NOT NULL DEFAULT 0,
+    ride_count INT NOT NULL DEFAULT 0,
+    imported_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER trigger_set_transit_feeds_updated_at
+BEFORE UPDATE ON transit_feeds
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE transit_stops (
+    feed_id UUID NOT NULL REFERENCES transit_feeds (id) ON DELETE CASCADE,
+    stop_id TEXT NOT NULL, -- The feed's own stop_id
+    name TEXT NOT NULL,
+    location GEOMETRY (Point, 4326) NOT NULL,
+    PRIMARY KEY (feed_id, stop_id)
+);
+
+-- Matches the geography casts of the nearby stop lookups
+CREATE INDEX idx_transit_stops_location ON transit_stops USING GIST ((location::geography));
+
+-- The quickest single ride from one stop to a later stop of the same trip.
+-- Journeys needing a transfer are not stored.
+CREATE TABLE transit_rides (
+    feed_id UUID NOT NULL REFERENCES transit_feeds (id) ON DELETE CASCADE,
+    from_stop_id TEXT NOT NULL,
+    to_stop_id TEXT NOT NULL,
+    ride_seconds INT NOT NULL CHECK (ride_seconds >= 0),
+    PRIMARY KEY (feed_id, from_stop_id, to_stop_id)
+);
@@ -0,0 +1,61 @@
+-- +migrate Up
+-- Dated happenings in a city: festivals, concerts, matches, food fairs.
+-- Events are added by admins or ingested from iCal feeds, and itineraries
+-- for given travel dates include those that overlap them.
+CREATE TABLE event_feeds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    name TEXT NOT NULL,
+    -- An http(s) URL, or a file:// path for feeds kept on the server
+    source_url TEXT NOT NULL,
+    city_id UUID NOT NULL REFERENCES cities (id) ON DELETE CASCADE,
+    -- Category of the feed's events that do not name a known one
+    default_category TEXT NOT NULL DEFAULT 'other',
+    event_count INT NOT NULL DEFAULT 0,
+    ingested_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER trigger_set_event_feeds_updated_at
+BEFORE UPDATE ON event_feeds
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    city_id UUID NOT NULL REFERENCES cities (id) ON DELETE CASCADE,
+    poi_id UUID REFERENCES points_of_interest (id) ON DELETE SET NULL,
+    -- Set for ingested events; re-ingesting a feed updates them by UID
+    feed_id UUID REFERENCES event_feeds (id) ON DELETE CASCADE,
+    external_uid TEXT,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    category TEXT NOT NULL DEFAULT 'other' CHECK (
+        category IN (
+            'festival', 'concert', 'sports', 'cultural', 'food',
+            'exhibition', 'theatre', 'market', 'other'
+        )
+    ),
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    all_day BOOLEAN NOT NULL DEFAULT FALSE,
+    venue TEXT NOT NULL DEFAULT '',
+    location GEOMETRY (Point, 4326),
+    url TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (ends_at >= starts_at),
+    UNIQUE (feed_id, external_uid)
+);
+
+CREATE TRIGGER trigger_set_events_updated_at
+BEFORE UPDATE ON events
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+-- Date range searches within a city, in start order
+CREATE INDEX idx_events_city_starts ON events (city_id, starts_at, id);
+CREATE INDEX idx_events_ends ON events (ends_at);
+CREATE INDEX idx_events_poi ON events (poi_id) WHERE poi_id IS NOT NULL;
+-- Matches the geography casts of the radius searches
+CREATE INDEX idx_events_location ON events USING GIST ((location::geography));
@@ -0,0 +1,33 @@
+-- +migrate Up
+-- Listings of our hotels, restaurants and POIs at booking partners. The
+-- partner is the key of an adapter in the application's partner registry,
+-- and external_id is the partner's own identifier for the place. Detail
+-- endpoints ask every partner a place is listed with for availability, a
+-- price quote and a deep link to book it.
+CREATE TABLE partner_listings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    partner TEXT NOT NULL,
+    external_id TEXT NOT NULL,
+    -- Exactly one of the targets is set
+    poi_id UUID REFERENCES points_of_interest (id) ON DELETE CASCADE,
+    hotel_id UUID REFERENCES hotel_details (id) ON DELETE CASCADE,
+    restaurant_id UUID REFERENCES restaurant_details (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (num_nonnulls (poi_id, hotel_id, restaurant_id) = 1),
+    UNIQUE (partner, external_id)
+);
+
+CREATE INDEX idx_partner_listings_poi_id ON partner_listings (poi_id)
+WHERE poi_id IS NOT NULL;
+
+CREATE INDEX idx_partner_listings_hotel_id ON partner_listings (hotel_id)
+WHERE hotel_id IS NOT NULL;
+
+CREATE INDEX idx_partner_listings_restaurant_id ON partner_listings (restaurant_id)
+WHERE restaurant_id IS NOT NULL;
+
+CREATE TRIGGER trigger_set_partner_listings_updated_at
+BEFORE UPDATE ON partner_listings
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
@@ -0,0 +1,106 @@
+-- +migrate Up
+-- First-party product analytics. Clients and services record what users do
+-- (searches, POI and list views, saves, chats) as raw events, partitioned by
+-- month. A scheduled job rolls them up into daily aggregates, POI popularity
+-- and the view counters of lists.
+CREATE TABLE analytics_events (
+    id UUID NOT NULL DEFAULT gen_random_uuid (),
+    name TEXT NOT NULL CHECK (
+        name IN (
+            'search_performed', 'poi_viewed', 'poi_saved', 'itinerary_saved',
+            'chat_started', 'list_viewed', 'list_saved'
+        )
+    ),
+    source TEXT NOT NULL CHECK (source IN ('client', 'server')),
+    -- Not foreign keys: events outlive what they point at and are never
+    -- joined on write. Rollups join them against the live tables.
+    user_id UUID,
+    session_id UUID,
+    city_id UUID,
+    poi_id UUID,
+    list_id UUID,
+    properties JSONB NOT NULL DEFAULT '{}',
+    occurred_at TIMESTAMPTZ NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (id, occurred_at)
+) PARTITION BY RANGE (occurred_at);
+
+CREATE INDEX idx_analytics_events_name_occurred_at ON analytics_events (name, occurred_at);
+
+CREATE INDEX idx_analytics_events_user_id ON analytics_events (user_id, occurred_at)
+WHERE user_id IS NOT NULL;
+
+-- Catches events outside the monthly partitions, such as late ones from
+-- before the first partition
+CREATE TABLE analytics_events_default PARTITION OF analytics_events DEFAULT;
+
+-- Creates the partition of analytics_events for the month of the given day.
+-- The rollup job keeps next month's partition in place ahead of time.
+CREATE OR REPLACE FUNCTION create_analytics_events_partition(month DATE) RETURNS VOID AS $$
+DECLARE
+    start_date DATE := date_trunc('month', month)::DATE;
+BEGIN
+    EXECUTE format(
+        'CREATE TABLE IF NOT EXISTS %I PARTITION OF analytics_events FOR VALUES FROM (%L) TO (%L)',
+        'analytics_events_' || to_char(start_date, 'YYYY_MM'),
+        start_date,
+        (start_date + INTERVAL '1 month')::DATE
+    );
+END;
+$$ LANGUAGE plpgsql;
+
+SELECT create_analytics_events_partition (CURRENT_DATE);
+
+SELECT create_analytics_events_partition ((CURRENT_DATE + INTERVAL '1 month')::DATE);
+
+-- Daily activity per city, for the top cities report
+CREATE TABLE analytics_daily_city_stats (
+    day DATE NOT NULL,
+    city_id UUID NOT NULL REFERENCES cities (id) ON DELETE CASCADE,
+    searches INT NOT NULL DEFAULT 0,
+    poi_views INT NOT NULL DEFAULT 0,
+    chats_started INT NOT NULL DEFAULT 0,
+    itineraries_saved INT NOT NULL DEFAULT 0,
+    active_users INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, city_id)
+);
+
+-- Users who started a chat each day, and how many of them went on to save
+-- an itinerary the same day
+CREATE TABLE analytics_daily_conversions (
+    day DATE PRIMARY KEY,
+    chat_users INT NOT NULL DEFAULT 0,
+    converted_users INT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE analytics_daily_poi_stats (
+    day DATE NOT NULL,
+    poi_id UUID NOT NULL REFERENCES points_of_interest (id) ON DELETE CASCADE,
+    views INT NOT NULL DEFAULT 0,
+    saves INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, poi_id)
+);
+
+CREATE INDEX idx_analytics_daily_poi_stats_poi_id ON analytics_daily_poi_stats (poi_id);
+
+CREATE TABLE analytics_daily_list_stats (
+    day DATE NOT NULL,
+    list_id UUID NOT NULL REFERENCES lists (id) ON DELETE CASCADE,
+    views INT NOT NULL DEFAULT 0,
+    saves INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, list_id)
+);
+
+CREATE INDEX idx_analytics_daily_list_stats_list_id ON analytics_daily_list_stats (list_id);
+
+-- Views and saves of each POI over the recent window; score feeds the
+-- priority of POIs in search results
+CREATE TABLE poi_popularity (
+    poi_id UUID PRIMARY KEY REFERENCES points_of_interest (id) ON DELETE CASCADE,
+    views INT NOT NULL DEFAULT 0,
+    saves INT NOT NULL DEFAULT 0,
+    score INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_poi_popularity_score ON poi_popularity (score DESC);
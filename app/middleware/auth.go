@@ -20,3 +20,15 @@ type Claims struct {
 
 var JwtSecretKey = []byte("your-secret-key")
 var JwtRefreshSecretKey = []byte("your-refresh-key")
+
+// KeyFunc resolves the verification key for a token. It defaults to the shared
+// HMAC secret; set it to a key set's Keyfunc to accept RS256/EdDSA tokens.
+var KeyFunc jwt.Keyfunc = func(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, jwt.ErrSignatureInvalid
+	}
+	return JwtSecretKey, nil
+}
+
+// ValidMethods restricts the accepted `alg` header values.
+var ValidMethods = []string{jwt.SigningMethodHS256.Alg()}
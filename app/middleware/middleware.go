@@ -2,6 +2,7 @@ package appMiddleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -29,17 +30,11 @@ func Authenticate(next http.Handler) http.Handler {
 
 		// 3. Parse and validate the token
 		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			// Ensure the signing method is what you expect (optional but recommended)
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid // Or a more specific error
-			}
-			return JwtSecretKey, nil // Use your secret key
-		})
+		token, err := jwt.ParseWithClaims(tokenString, claims, KeyFunc, jwt.WithValidMethods(ValidMethods))
 
 		// 4. Handle parsing errors or invalid token
 		if err != nil {
-			if err == jwt.ErrSignatureInvalid {
+			if errors.Is(err, jwt.ErrSignatureInvalid) {
 				http.Error(w, "Invalid token signature", http.StatusUnauthorized)
 				return
 			}
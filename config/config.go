@@ -29,13 +29,137 @@ type JWTConfig struct {
 
 	// RefreshTokenTTL defines the duration for which refresh tokens are valid. REQUIRED.
 	RefreshTokenTTL time.Duration `mapstructure:"refreshTokenTTL"` // e.g., "7d", "30d"
+
+	// ActiveKeyID is the `kid` of the key used to sign new access tokens.
+	// Required when Keys is set; every other key is only used for verification.
+	ActiveKeyID string `mapstructure:"activeKeyID"` // e.g., "loci-rsa-2025-06"
+
+	// Keys lists the asymmetric signing keys. Keeping a retired key here (public
+	// key only) lets tokens it signed verify until they expire.
+	// When empty, tokens are signed with SecretKey using HS256.
+	Keys []JWTKeyConfig `mapstructure:"keys"`
+}
+
+// JWTKeyConfig describes a single asymmetric JWT key loaded from PEM files.
+type JWTKeyConfig struct {
+	// ID is published as the `kid` header and in the JWKS document.
+	ID string `mapstructure:"kid"`
+	// Algorithm is the JWS algorithm for this key: "RS256" or "EdDSA".
+	Algorithm string `mapstructure:"alg"`
+	// PrivateKeyFile is the PEM private key. Optional for verify-only keys.
+	PrivateKeyFile string `mapstructure:"privateKeyFile"`
+	// PublicKeyFile is the PEM public key. Derived from the private key if empty.
+	PublicKeyFile string `mapstructure:"publicKeyFile"`
+}
+
+// PrivacyConfig controls GDPR data exports and account deletion.
+type PrivacyConfig struct {
+	// ExportDir is where generated export ZIPs are written. Defaults to the OS temp dir.
+	ExportDir string `mapstructure:"exportDir"`
+	// ExportTTL is how long a finished export stays downloadable. Defaults to 7 days.
+	ExportTTL time.Duration `mapstructure:"exportTTL"`
+	// DeletionGracePeriod is how long a user can cancel an account deletion. Defaults to 30 days.
+	DeletionGracePeriod time.Duration `mapstructure:"deletionGracePeriod"`
+	// PollInterval is how often the worker looks for pending exports and due deletions. Defaults to 30s.
+	PollInterval time.Duration `mapstructure:"pollInterval"`
+	// ExportLease is how long an export may stay processing before another
+	// worker takes it over, e.g. after a crash. Defaults to 15m.
+	ExportLease time.Duration `mapstructure:"exportLease"`
+}
+
+// JobsConfig controls the background job workers.
+type JobsConfig struct {
+	// Workers is how many jobs run concurrently in this process. Defaults to 2.
+	Workers int `mapstructure:"workers"`
+	// PollInterval is how often an idle worker looks for due jobs. Defaults to 5s.
+	PollInterval time.Duration `mapstructure:"pollInterval"`
+	// LeaseDuration is how long a claimed job stays locked without a heartbeat
+	// before another worker may take it over. Defaults to 2m.
+	LeaseDuration time.Duration `mapstructure:"leaseDuration"`
+	// MaxAttempts is how often a job is tried before it is marked failed. Defaults to 5.
+	MaxAttempts int `mapstructure:"maxAttempts"`
+	// RetryBackoff is the delay before the first retry; it doubles on every attempt. Defaults to 30s.
+	RetryBackoff time.Duration `mapstructure:"retryBackoff"`
+	// EmbeddingSweepInterval is how often embeddings are generated for anything
+	// still missing one. Defaults to 1h.
+	EmbeddingSweepInterval time.Duration `mapstructure:"embeddingSweepInterval"`
+	// LearningSweepInterval is how often interaction events whose learning job
+	// was lost are folded into user affinities. Zero disables the sweep.
+	LearningSweepInterval time.Duration `mapstructure:"learningSweepInterval"`
+}
+
+// SubscriptionConfig controls the subscription lifecycle.
+type SubscriptionConfig struct {
+	// TrialPeriod is the length of a premium trial. Defaults to 14 days.
+	TrialPeriod time.Duration `mapstructure:"trialPeriod"`
+	// ExpiryInterval is how often subscriptions past their end date are expired. Defaults to 5m.
+	ExpiryInterval time.Duration `mapstructure:"expiryInterval"`
+	// WebhookSecret signs payment provider webhooks. When empty, webhooks are refused
+	// and paid plans can only be granted by an admin override.
+	WebhookSecret string `mapstructure:"webhookSecret"`
+	// WebhookTolerance is the maximum age of a webhook timestamp. Defaults to 5m.
+	WebhookTolerance time.Duration `mapstructure:"webhookTolerance"`
+}
+
+// WeatherConfig selects and tunes the weather provider used to adapt itineraries.
+type WeatherConfig struct {
+	// Provider is "http" for the live forecast API or "file" for a fixture. Defaults to "http".
+	Provider string `mapstructure:"provider"`
+	// BaseURL is the forecast API endpoint. Defaults to the Open-Meteo forecast API.
+	BaseURL string `mapstructure:"baseURL"`
+	// FixtureFile is the forecast JSON served by the "file" provider.
+	FixtureFile string `mapstructure:"fixtureFile"`
+	// Timeout bounds a forecast request. Defaults to 5s.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// CacheTTL is how long a forecast is reused for nearby locations. Defaults to 30m.
+	CacheTTL time.Duration `mapstructure:"cacheTTL"`
+	// ForecastDays is how many days ahead are fetched. Defaults to 3.
+	ForecastDays int `mapstructure:"forecastDays"`
+}
+
+// EventsConfig controls event feed ingestion.
+type EventsConfig struct {
+	// FeedDir is the only directory file:// feeds may be read from. Empty
+	// disables file:// feeds.
+	FeedDir string `mapstructure:"feedDir"`
+}
+
+// PartnersConfig selects the booking partners asked for offers on detail pages.
+type PartnersConfig struct {
+	// Enabled lists the partner adapters to register by key. Empty by default;
+	// "mock" is the in-repo test partner, for tests and local development only.
+	Enabled []string `mapstructure:"enabled"`
+	// Timeout bounds each partner call. Defaults to 3s.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// AnalyticsConfig tunes the product analytics pipeline.
+type AnalyticsConfig struct {
+	// FlushInterval is how often server-side events are written. Defaults to 5s.
+	FlushInterval time.Duration `mapstructure:"flushInterval"`
+	// BufferSize is how many server-side events wait to be written before
+	// new ones are dropped. Defaults to 1024.
+	BufferSize int `mapstructure:"bufferSize"`
+	// RollupInterval is how often events are rolled up into the daily
+	// aggregates. Defaults to 1h.
+	RollupInterval time.Duration `mapstructure:"rollupInterval"`
+	// PopularityWindow is how far back views and saves count towards POI
+	// popularity. Defaults to 30 days.
+	PopularityWindow time.Duration `mapstructure:"popularityWindow"`
 }
 
 type Config struct {
-	Mode         string    `mapstructure:"mode"`
-	Dotenv       string    `mapstructure:"dotenv"`
-	JWT          JWTConfig `mapstructure:"jwt"`
-	HandlerImpls struct {
+	Mode          string             `mapstructure:"mode"`
+	Dotenv        string             `mapstructure:"dotenv"`
+	JWT           JWTConfig          `mapstructure:"jwt"`
+	Privacy       PrivacyConfig      `mapstructure:"privacy"`
+	Subscriptions SubscriptionConfig `mapstructure:"subscriptions"`
+	Jobs          JobsConfig         `mapstructure:"jobs"`
+	Weather       WeatherConfig      `mapstructure:"weather"`
+	Events        EventsConfig       `mapstructure:"events"`
+	Partners      PartnersConfig     `mapstructure:"partners"`
+	Analytics     AnalyticsConfig    `mapstructure:"analytics"`
+	HandlerImpls  struct {
 		ExternalAPI struct {
 			Port      string `mapstrucutre:"port"`
 			CertFile  string `mapstructure:"certFile"`
@@ -101,8 +225,11 @@ func InitConfig() (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %s", err)
 	}
 
-	if config.JWT.SecretKey == "" {
-		return nil, fmt.Errorf("JWT_SECRET_KEY environment variable is required")
+	if config.JWT.SecretKey == "" && len(config.JWT.Keys) == 0 {
+		return nil, fmt.Errorf("JWT_SECRET_KEY environment variable or jwt.keys is required")
+	}
+	if len(config.JWT.Keys) > 0 && config.JWT.ActiveKeyID == "" {
+		return nil, fmt.Errorf("jwt.activeKeyID is required when jwt.keys is set")
 	}
 	if config.JWT.AccessTokenTTL == 0 {
 		return nil, fmt.Errorf("JWT_ACCESS_TOKEN_TTL environment variable is required and must be a valid duration (e.g., 15m)")
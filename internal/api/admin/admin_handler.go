@@ -0,0 +1,535 @@
+package admin
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/auth"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Handler = (*HandlerImpl)(nil)
+
+type Handler interface {
+	ListUsers(w http.ResponseWriter, r *http.Request)
+	GetUser(w http.ResponseWriter, r *http.Request)
+	DeactivateUser(w http.ResponseWriter, r *http.Request)
+	ReactivateUser(w http.ResponseWriter, r *http.Request)
+	SetUserRole(w http.ResponseWriter, r *http.Request)
+	OverrideSubscription(w http.ResponseWriter, r *http.Request)
+
+	VerifyPOI(w http.ResponseWriter, r *http.Request)
+	UpdatePOI(w http.ResponseWriter, r *http.Request)
+	MergePOIs(w http.ResponseWriter, r *http.Request)
+	DeletePOI(w http.ResponseWriter, r *http.Request)
+
+	VerifyRestaurantDietary(w http.ResponseWriter, r *http.Request)
+}
+
+type HandlerImpl struct {
+	logger  *slog.Logger
+	service Service
+}
+
+func NewHandler(service Service, logger *slog.Logger) *HandlerImpl {
+	return &HandlerImpl{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// actorAndPathID resolves the authenticated admin and the UUID path parameter.
+// On failure it writes the error response and returns ok=false.
+func (h *HandlerImpl) actorAndPathID(w http.ResponseWriter, r *http.Request, span trace.Span, l *slog.Logger, param string) (actorID, id uuid.UUID, ok bool) {
+	ctx := r.Context()
+	actorIDStr, found := auth.GetUserIDFromContext(ctx)
+	if !found || actorIDStr == "" {
+		l.ErrorContext(ctx, "User ID not found in context")
+		span.SetStatus(codes.Error, "Unauthorized - User ID missing")
+		api.ErrorResponse(w, r, http.StatusUnauthorized, "Authentication required")
+		return uuid.Nil, uuid.Nil, false
+	}
+	actorID, err := uuid.Parse(actorIDStr)
+	if err != nil {
+		l.ErrorContext(ctx, "Invalid user ID format", slog.String("userID_str", actorIDStr), slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Invalid User ID format")
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid user ID format")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	if param == "" {
+		return actorID, uuid.Nil, true
+	}
+	idStr := chi.URLParam(r, param)
+	id, err = uuid.Parse(idStr)
+	if err != nil {
+		l.ErrorContext(ctx, "Invalid path ID format", slog.String(param, idStr), slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Invalid path ID format")
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid "+param+" format")
+		return uuid.Nil, uuid.Nil, false
+	}
+	span.SetAttributes(attribute.String("actor.id", actorID.String()), attribute.String(param, id.String()))
+	return actorID, id, true
+}
+
+// ListUsers godoc
+// @Summary      Search Users
+// @Description  Lists users, optionally filtered by a search term, role and active status. Admin only.
+// @Tags         Admin
+// @Produce      json
+// @Param        q query string false "Matches email, username or display name"
+// @Param        role query string false "Filter by role (user, moderator, admin)"
+// @Param        is_active query bool false "Filter by active status"
+// @Param        cursor query string false "Cursor of the page to fetch, from next_cursor or the Link header; omit for the first page"
+// @Param        limit query int false "Items per page (default 20, max 100)"
+// @Success      200 {object} types.PaginatedAdminUsersResponse
+// @Failure      400 {object} types.Response "Invalid filter or cursor"
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      403 {object} types.Response "Forbidden"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/users [get]
+func (h *HandlerImpl) ListUsers(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("AdminHandler").Start(r.Context(), "ListUsers")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "ListUsers"))
+
+	page, err := api.ParsePageRequest(r)
+	if err != nil {
+		span.SetStatus(codes.Error, "Invalid cursor")
+		api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	q := r.URL.Query()
+	filter := types.AdminUserFilter{
+		Query: q.Get("q"),
+		Role:  q.Get("role"),
+		Page:  page,
+	}
+	if v := q.Get("is_active"); v != "" {
+		active, err := strconv.ParseBool(v)
+		if err != nil {
+			span.SetStatus(codes.Error, "Invalid is_active")
+			api.ErrorResponse(w, r, http.StatusBadRequest, "is_active must be true or false")
+			return
+		}
+		filter.IsActive = &active
+	}
+
+	resp, err := h.service.ListUsers(ctx, filter)
+	if err != nil {
+		l.ErrorContext(ctx, "Service failed to list users", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to list users")
+		api.WriteServiceError(w, r, err, "Failed to list users")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Users listed")
+	api.SetPageLinks(w, r, resp.NextCursor)
+	api.WriteJSONResponse(w, r, http.StatusOK, resp)
+}
+
+// GetUser godoc
+// @Summary      Get User
+// @Description  Returns a user's profile, including deactivated users. Admin only.
+// @Tags         Admin
+// @Produce      json
+// @Param        userID path string true "User ID"
+// @Success      200 {object} types.UserProfile
+// @Failure      400 {object} types.Response "Invalid user ID"
+// @Failure      404 {object} types.Response "User not found"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/users/{userID} [get]
+func (h *HandlerImpl) GetUser(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("AdminHandler").Start(r.Context(), "GetUser")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "GetUser"))
+
+	_, userID, ok := h.actorAndPathID(w, r, span, l, "userID")
+	if !ok {
+		return
+	}
+
+	profile, err := h.service.GetUser(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to get user")
+		api.WriteServiceError(w, r, err, "Failed to get user")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "User retrieved")
+	api.WriteJSONResponse(w, r, http.StatusOK, profile)
+}
+
+// DeactivateUser godoc
+// @Summary      Deactivate User
+// @Description  Deactivates a user and revokes their refresh tokens. Admin only.
+// @Tags         Admin
+// @Produce      json
+// @Param        userID path string true "User ID"
+// @Success      200 {object} types.Response
+// @Failure      400 {object} types.Response "Invalid user ID or self-deactivation"
+// @Failure      404 {object} types.Response "User not found"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/users/{userID}/deactivate [post]
+func (h *HandlerImpl) DeactivateUser(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("AdminHandler").Start(r.Context(), "DeactivateUser")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "DeactivateUser"))
+
+	actorID, userID, ok := h.actorAndPathID(w, r, span, l, "userID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeactivateUser(ctx, actorID, userID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to deactivate user")
+		api.WriteServiceError(w, r, err, "Failed to deactivate user")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "User deactivated")
+	api.WriteJSONResponse(w, r, http.StatusOK, types.Response{Success: true, Message: "User deactivated"})
+}
+
+// ReactivateUser godoc
+// @Summary      Reactivate User
+// @Description  Reactivates a deactivated user. Admin only.
+// @Tags         Admin
+// @Produce      json
+// @Param        userID path string true "User ID"
+// @Success      200 {object} types.Response
+// @Failure      400 {object} types.Response "Invalid user ID"
+// @Failure      404 {object} types.Response "User not found"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/users/{userID}/reactivate [post]
+func (h *HandlerImpl) ReactivateUser(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("AdminHandler").Start(r.Context(), "ReactivateUser")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "ReactivateUser"))
+
+	actorID, userID, ok := h.actorAndPathID(w, r, span, l, "userID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.ReactivateUser(ctx, actorID, userID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to reactivate user")
+		api.WriteServiceError(w, r, err, "Failed to reactivate user")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "User reactivated")
+	api.WriteJSONResponse(w, r, http.StatusOK, types.Response{Success: true, Message: "User reactivated"})
+}
+
+// SetUserRole godoc
+// @Summary      Change User Role
+// @Description  Sets a user's role. Admins cannot change their own role. Admin only.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        userID path string true "User ID"
+// @Param        role body types.UpdateUserRoleRequest true "New role"
+// @Success      200 {object} types.Response
+// @Failure      400 {object} types.Response "Invalid role or user ID"
+// @Failure      404 {object} types.Response "User not found"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/users/{userID}/role [put]
+func (h *HandlerImpl) SetUserRole(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("AdminHandler").Start(r.Context(), "SetUserRole")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "SetUserRole"))
+
+	actorID, userID, ok := h.actorAndPathID(w, r, span, l, "userID")
+	if !ok {
+		return
+	}
+
+	var req types.UpdateUserRoleRequest
+	if err := api.DecodeJSONBody(w, r, &req); err != nil {
+		l.ErrorContext(ctx, "Failed to decode request", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Bad request")
+		return
+	}
+	span.SetAttributes(attribute.String("role", req.Role))
+
+	if err := h.service.SetUserRole(ctx, actorID, userID, req.Role); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to set role")
+		api.WriteServiceError(w, r, err, "Failed to update user role")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Role updated")
+	api.WriteJSONResponse(w, r, http.StatusOK, types.Response{Success: true, Message: "Role updated"})
+}
+
+// OverrideSubscription godoc
+// @Summary      Override Subscription
+// @Description  Sets a user's subscription plan and status directly, bypassing the payment provider. Admin only.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        userID path string true "User ID"
+// @Param        subscription body types.SubscriptionOverrideRequest true "Subscription override"
+// @Success      200 {object} types.Response
+// @Failure      400 {object} types.Response "Invalid plan, status or user ID"
+// @Failure      404 {object} types.Response "User not found"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/users/{userID}/subscription [put]
+func (h *HandlerImpl) OverrideSubscription(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("AdminHandler").Start(r.Context(), "OverrideSubscription")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "OverrideSubscription"))
+
+	actorID, userID, ok := h.actorAndPathID(w, r, span, l, "userID")
+	if !ok {
+		return
+	}
+
+	var req types.SubscriptionOverrideRequest
+	if err := api.DecodeJSONBody(w, r, &req); err != nil {
+		l.ErrorContext(ctx, "Failed to decode request", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Bad request")
+		return
+	}
+
+	if err := h.service.OverrideSubscription(ctx, actorID, userID, req); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to override subscription")
+		api.WriteServiceError(w, r, err, "Failed to override subscription")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Subscription overridden")
+	api.WriteJSONResponse(w, r, http.StatusOK, types.Response{Success: true, Message: "Subscription updated"})
+}
+
+// VerifyPOI godoc
+// @Summary      Verify POI
+// @Description  Marks a POI as verified (or clears the flag). Moderators and admins.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        poiID path string true "POI ID"
+// @Param        verify body types.VerifyPOIRequest true "Verification flag"
+// @Success      200 {object} types.Response
+// @Failure      400 {object} types.Response "Invalid POI ID"
+// @Failure      404 {object} types.Response "POI not found"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/pois/{poiID}/verify [put]
+func (h *HandlerImpl) VerifyPOI(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("AdminHandler").Start(r.Context(), "VerifyPOI")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "VerifyPOI"))
+
+	actorID, poiID, ok := h.actorAndPathID(w, r, span, l, "poiID")
+	if !ok {
+		return
+	}
+
+	var req types.VerifyPOIRequest
+	if err := api.DecodeJSONBody(w, r, &req); err != nil {
+		l.ErrorContext(ctx, "Failed to decode request", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Bad request")
+		return
+	}
+
+	if err := h.service.VerifyPOI(ctx, actorID, poiID, req.Verified); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to verify POI")
+		api.WriteServiceError(w, r, err, "Failed to verify POI")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "POI verification updated")
+	api.WriteJSONResponse(w, r, http.StatusOK, types.Response{Success: true, Message: "POI verification updated"})
+}
+
+// UpdatePOI godoc
+// @Summary      Edit POI
+// @Description  Edits a POI's fields. Omitted fields are left unchanged. Moderators and admins.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        poiID path string true "POI ID"
+// @Param        poi body types.AdminPOIUpdate true "Fields to update"
+// @Success      200 {object} types.Response
+// @Failure      400 {object} types.Response "Invalid POI ID or fields"
+// @Failure      404 {object} types.Response "POI not found"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/pois/{poiID} [put]
+func (h *HandlerImpl) UpdatePOI(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("AdminHandler").Start(r.Context(), "UpdatePOI")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "UpdatePOI"))
+
+	actorID, poiID, ok := h.actorAndPathID(w, r, span, l, "poiID")
+	if !ok {
+		return
+	}
+
+	var req types.AdminPOIUpdate
+	if err := api.DecodeJSONBody(w, r, &req); err != nil {
+		l.ErrorContext(ctx, "Failed to decode request", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Bad request")
+		return
+	}
+
+	if err := h.service.UpdatePOI(ctx, actorID, poiID, req); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to update POI")
+		api.WriteServiceError(w, r, err, "Failed to update POI")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "POI updated")
+	api.WriteJSONResponse(w, r, http.StatusOK, types.Response{Success: true, Message: "POI updated"})
+}
+
+// MergePOIs godoc
+// @Summary      Merge Duplicate POIs
+// @Description  Merges the source POIs into the POI in the path. Favourites, list items, itinerary stops, reviews, partner listings, events and interaction history are moved, and their views and saves count towards the target's popularity; the sources are deleted. Moderators and admins.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        poiID path string true "Target POI ID"
+// @Param        merge body types.MergePOIsRequest true "Source POI IDs"
+// @Success      200 {object} types.Response
+// @Failure      400 {object} types.Response "Invalid POI IDs"
+// @Failure      404 {object} types.Response "POI not found"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/pois/{poiID}/merge [post]
+func (h *HandlerImpl) MergePOIs(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("AdminHandler").Start(r.Context(), "MergePOIs")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "MergePOIs"))
+
+	actorID, targetID, ok := h.actorAndPathID(w, r, span, l, "poiID")
+	if !ok {
+		return
+	}
+
+	var req types.MergePOIsRequest
+	if err := api.DecodeJSONBody(w, r, &req); err != nil {
+		l.ErrorContext(ctx, "Failed to decode request", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Bad request")
+		return
+	}
+	span.SetAttributes(attribute.Int("merge.sources", len(req.SourceIDs)))
+
+	if err := h.service.MergePOIs(ctx, actorID, targetID, req.SourceIDs); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to merge POIs")
+		api.WriteServiceError(w, r, err, "Failed to merge POIs")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "POIs merged")
+	api.WriteJSONResponse(w, r, http.StatusOK, types.Response{Success: true, Message: "POIs merged"})
+}
+
+// DeletePOI godoc
+// @Summary      Delete POI
+// @Description  Permanently deletes a POI. Moderators and admins.
+// @Tags         Admin
+// @Produce      json
+// @Param        poiID path string true "POI ID"
+// @Success      200 {object} types.Response
+// @Failure      400 {object} types.Response "Invalid POI ID"
+// @Failure      404 {object} types.Response "POI not found"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/pois/{poiID} [delete]
+func (h *HandlerImpl) DeletePOI(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("AdminHandler").Start(r.Context(), "DeletePOI")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "DeletePOI"))
+
+	actorID, poiID, ok := h.actorAndPathID(w, r, span, l, "poiID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeletePOI(ctx, actorID, poiID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to delete POI")
+		api.WriteServiceError(w, r, err, "Failed to delete POI")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "POI deleted")
+	api.WriteJSONResponse(w, r, http.StatusOK, types.Response{Success: true, Message: "POI deleted"})
+}
+
+// VerifyRestaurantDietary godoc
+// @Summary      Verify Restaurant Diet or Allergen
+// @Description  Records that a moderator checked a restaurant's diet or allergen with the restaurant. The verdict overrides LLM guesses and user reports, and a verified allergen counts for allergen-free searches. Moderators and admins.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        restaurantID path string true "Restaurant ID"
+// @Param        report body types.DietaryReport true "Verified attribute"
+// @Success      200 {object} types.Response
+// @Failure      400 {object} types.Response "Invalid restaurant ID or attribute"
+// @Failure      404 {object} types.Response "Restaurant not found"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/restaurants/{restaurantID}/dietary [put]
+func (h *HandlerImpl) VerifyRestaurantDietary(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("AdminHandler").Start(r.Context(), "VerifyRestaurantDietary")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "VerifyRestaurantDietary"))
+
+	actorID, restaurantID, ok := h.actorAndPathID(w, r, span, l, "restaurantID")
+	if !ok {
+		return
+	}
+
+	var req types.DietaryReport
+	if err := api.DecodeJSONBody(w, r, &req); err != nil {
+		l.ErrorContext(ctx, "Failed to decode request", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Bad request")
+		return
+	}
+
+	if err := h.service.VerifyRestaurantDietary(ctx, actorID, restaurantID, req); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to verify restaurant dietary attribute")
+		api.WriteServiceError(w, r, err, "Failed to verify restaurant dietary attribute")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Restaurant dietary attribute verified")
+	api.WriteJSONResponse(w, r, http.StatusOK, types.Response{Success: true, Message: "Restaurant dietary attribute verified"})
+}
@@ -0,0 +1,238 @@
+//go:build integration
+
+package admin
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/user"
+)
+
+var testAdminDB *pgxpool.Pool
+var testAdminRepo AdminRepo
+
+func TestMain(m *testing.M) {
+	if err := godotenv.Load("../../../.env.test"); err != nil {
+		log.Println("Warning: .env.test file not found for admin integration tests.")
+	}
+
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("TEST_DATABASE_URL environment variable is not set for admin integration tests")
+	}
+
+	config, err := pgxpool.ParseConfig(dbURL)
+	if err != nil {
+		log.Fatalf("Unable to parse TEST_DATABASE_URL: %v\n", err)
+	}
+	config.MaxConns = 5
+
+	testAdminDB, err = pgxpool.NewWithConfig(context.Background(), config)
+	if err != nil {
+		log.Fatalf("Unable to create connection pool for admin tests: %v\n", err)
+	}
+	defer testAdminDB.Close()
+
+	if err := testAdminDB.Ping(context.Background()); err != nil {
+		log.Fatalf("Unable to ping test database for admin tests: %v\n", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	testAdminRepo = NewRepository(testAdminDB, user.NewPostgresUserRepo(testAdminDB, logger), logger)
+
+	exitCode := m.Run()
+	os.Exit(exitCode)
+}
+
+func createTestPOI(t *testing.T, name string) uuid.UUID {
+	t.Helper()
+	id := uuid.New()
+	_, err := testAdminDB.Exec(context.Background(), `
+		INSERT INTO points_of_interest (id, name, location)
+		VALUES ($1, $2, ST_SetSRID(ST_MakePoint(-9.1393, 38.7223), 4326))`, id, name)
+	require.NoError(t, err, "Failed to create test POI")
+	t.Cleanup(func() {
+		_, _ = testAdminDB.Exec(context.Background(), `DELETE FROM points_of_interest WHERE id = $1`, id)
+	})
+	return id
+}
+
+func TestAdminRepository_MergePOIs_Integration(t *testing.T) {
+	ctx := context.Background()
+
+	userID := uuid.New()
+	_, err := testAdminDB.Exec(ctx, `
+		INSERT INTO users (id, username, email, password_hash)
+		VALUES ($1, $2, $3, 'hash') ON CONFLICT (id) DO NOTHING`,
+		userID, "merge_"+userID.String()[:8], "merge_"+userID.String()[:8]+"@example.com")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = testAdminDB.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, userID)
+	})
+
+	t.Run("Sources sharing an owner keep one row on the target", func(t *testing.T) {
+		target := createTestPOI(t, "TestMergeTarget")
+		source1 := createTestPOI(t, "TestMergeSource1")
+		source2 := createTestPOI(t, "TestMergeSource2")
+
+		_, err := testAdminDB.Exec(ctx, `
+			INSERT INTO user_favorite_pois (user_id, poi_id) VALUES ($1, $2), ($1, $3)`,
+			userID, source1, source2)
+		require.NoError(t, err)
+		_, err = testAdminDB.Exec(ctx, `
+			INSERT INTO saved_pois (user_id, poi_id) VALUES ($1, $2), ($1, $3)`,
+			userID, source1, source2)
+		require.NoError(t, err)
+
+		var listID uuid.UUID
+		err = testAdminDB.QueryRow(ctx, `
+			INSERT INTO lists (user_id, name) VALUES ($1, 'TestMergeList') RETURNING id`, userID).Scan(&listID)
+		require.NoError(t, err)
+		_, err = testAdminDB.Exec(ctx, `
+			INSERT INTO list_items (list_id, poi_id, position) VALUES ($1, $2, 1), ($1, $3, 2)`,
+			listID, source1, source2)
+		require.NoError(t, err)
+
+		err = testAdminRepo.MergePOIs(ctx, target, []uuid.UUID{source1, source2})
+		require.NoError(t, err)
+
+		for table, query := range map[string]string{
+			"user_favorite_pois": `SELECT COUNT(*) FROM user_favorite_pois WHERE user_id = $1 AND poi_id = $2`,
+			"saved_pois":         `SELECT COUNT(*) FROM saved_pois WHERE user_id = $1 AND poi_id = $2`,
+		} {
+			var count int
+			require.NoError(t, testAdminDB.QueryRow(ctx, query, userID, target).Scan(&count))
+			assert.Equal(t, 1, count, table)
+		}
+		var items int
+		require.NoError(t, testAdminDB.QueryRow(ctx,
+			`SELECT COUNT(*) FROM list_items WHERE list_id = $1`, listID).Scan(&items))
+		assert.Equal(t, 1, items)
+
+		var remaining int
+		require.NoError(t, testAdminDB.QueryRow(ctx,
+			`SELECT COUNT(*) FROM points_of_interest WHERE id = ANY($1::uuid[])`,
+			[]uuid.UUID{source1, source2}).Scan(&remaining))
+		assert.Zero(t, remaining)
+	})
+
+	t.Run("Owner already referencing the target keeps its row", func(t *testing.T) {
+		target := createTestPOI(t, "TestMergeTarget")
+		source := createTestPOI(t, "TestMergeSource")
+
+		_, err := testAdminDB.Exec(ctx, `
+			INSERT INTO user_favorite_pois (user_id, poi_id) VALUES ($1, $2), ($1, $3)`,
+			userID, target, source)
+		require.NoError(t, err)
+
+		err = testAdminRepo.MergePOIs(ctx, target, []uuid.UUID{source})
+		require.NoError(t, err)
+
+		var count int
+		require.NoError(t, testAdminDB.QueryRow(ctx,
+			`SELECT COUNT(*) FROM user_favorite_pois WHERE user_id = $1`, userID).Scan(&count))
+		assert.Equal(t, 1, count)
+	})
+	t.Run("Partner listings, events and interactions move to the target", func(t *testing.T) {
+		target := createTestPOI(t, "TestMergeTarget")
+		source := createTestPOI(t, "TestMergeSource")
+		suffix := uuid.NewString()[:8]
+
+		// The target is already listed with partner A, so the source's A listing is dropped
+		_, err := testAdminDB.Exec(ctx, `
+			INSERT INTO partner_listings (partner, external_id, poi_id)
+			VALUES ('merge_a', $3, $1), ('merge_a', $4, $2), ('merge_b', $5, $2)`,
+			target, source, "t-"+suffix, "s1-"+suffix, "s2-"+suffix)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_, _ = testAdminDB.Exec(context.Background(),
+				`DELETE FROM partner_listings WHERE partner IN ('merge_a', 'merge_b') AND external_id LIKE '%' || $1`, suffix)
+		})
+
+		cityID := uuid.New()
+		_, err = testAdminDB.Exec(ctx, `INSERT INTO cities (id, name, country) VALUES ($1, $2, 'Portugal')`,
+			cityID, "TestMergeCity "+suffix)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_, _ = testAdminDB.Exec(context.Background(), `DELETE FROM cities WHERE id = $1`, cityID)
+		})
+		var eventID uuid.UUID
+		err = testAdminDB.QueryRow(ctx, `
+			INSERT INTO events (city_id, poi_id, name, starts_at, ends_at)
+			VALUES ($1, $2, 'TestMergeEvent', NOW(), NOW() + INTERVAL '1 hour') RETURNING id`,
+			cityID, source).Scan(&eventID)
+		require.NoError(t, err)
+
+		var interactionID uuid.UUID
+		err = testAdminDB.QueryRow(ctx, `
+			INSERT INTO user_interaction_events (user_id, poi_id, poi_name, kind)
+			VALUES ($1, $2, 'TestMergeSource', 'favourite') RETURNING id`,
+			userID, source).Scan(&interactionID)
+		require.NoError(t, err)
+
+		require.NoError(t, testAdminRepo.MergePOIs(ctx, target, []uuid.UUID{source}))
+
+		var listings []string
+		rows, err := testAdminDB.Query(ctx,
+			`SELECT partner || ':' || external_id FROM partner_listings WHERE poi_id = $1 ORDER BY partner`, target)
+		require.NoError(t, err)
+		for rows.Next() {
+			var l string
+			require.NoError(t, rows.Scan(&l))
+			listings = append(listings, l)
+		}
+		require.NoError(t, rows.Err())
+		assert.Equal(t, []string{"merge_a:t-" + suffix, "merge_b:s2-" + suffix}, listings)
+
+		var poiID *uuid.UUID
+		require.NoError(t, testAdminDB.QueryRow(ctx, `SELECT poi_id FROM events WHERE id = $1`, eventID).Scan(&poiID))
+		require.NotNil(t, poiID)
+		assert.Equal(t, target, *poiID)
+		require.NoError(t, testAdminDB.QueryRow(ctx,
+			`SELECT poi_id FROM user_interaction_events WHERE id = $1`, interactionID).Scan(&poiID))
+		require.NotNil(t, poiID)
+		assert.Equal(t, target, *poiID)
+	})
+	t.Run("Analytics of the sources count towards the target", func(t *testing.T) {
+		target := createTestPOI(t, "TestMergeTarget")
+		source1 := createTestPOI(t, "TestMergeSource1")
+		source2 := createTestPOI(t, "TestMergeSource2")
+
+		_, err := testAdminDB.Exec(ctx, `
+			INSERT INTO analytics_daily_poi_stats (day, poi_id, views, saves) VALUES
+			    ('2025-01-10', $1, 10, 1), ('2025-01-10', $2, 4, 2), ('2025-01-11', $3, 3, 0)`,
+			target, source1, source2)
+		require.NoError(t, err)
+		_, err = testAdminDB.Exec(ctx, `
+			INSERT INTO poi_popularity (poi_id, views, saves, score) VALUES ($1, 7, 0, 7), ($2, 3, 1, 8)`,
+			source1, source2)
+		require.NoError(t, err)
+
+		require.NoError(t, testAdminRepo.MergePOIs(ctx, target, []uuid.UUID{source1, source2}))
+
+		var views, saves int
+		require.NoError(t, testAdminDB.QueryRow(ctx, `
+			SELECT views, saves FROM analytics_daily_poi_stats WHERE poi_id = $1 AND day = '2025-01-10'`,
+			target).Scan(&views, &saves))
+		assert.Equal(t, []int{14, 3}, []int{views, saves})
+		var days int
+		require.NoError(t, testAdminDB.QueryRow(ctx,
+			`SELECT COUNT(*) FROM analytics_daily_poi_stats WHERE poi_id = $1`, target).Scan(&days))
+		assert.Equal(t, 2, days)
+
+		var score int
+		require.NoError(t, testAdminDB.QueryRow(ctx,
+			`SELECT views, saves, score FROM poi_popularity WHERE poi_id = $1`, target).Scan(&views, &saves, &score))
+		assert.Equal(t, []int{10, 1, 15}, []int{views, saves, score})
+	})
+}
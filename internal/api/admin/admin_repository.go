@@ -2,12 +2,28 @@ package admin
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/embeddings"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/user"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
 )
 
+var _ AdminRepo = (*RepositoryImpl)(nil)
+
 type AdminRepo interface {
 	// GetUserByID retrieves a user's full profile by their unique ID.
 	GetUserByID(ctx context.Context, userID uuid.UUID) (*types.UserProfile, error)
@@ -26,11 +42,599 @@ type AdminRepo interface {
 	// SetUserPreferences atomically replaces a user's current interests with the provided list of interest IDs.
 	SetUserPreferences(ctx context.Context, userID uuid.UUID, interestIDs []uuid.UUID) error
 
-	// --- Other Potential Methods ---
+	// ListUsers searches/filters users. Returns the page of users and the total match count.
+	ListUsers(ctx context.Context, filter types.AdminUserFilter) ([]types.AdminUserSummary, *types.Cursor, int, error)
+	// SetUserRole changes a user's role and returns the role it replaced.
+	SetUserRole(ctx context.Context, userID uuid.UUID, role string) (string, error)
+	// OverrideSubscription upserts a user's subscription with admin-provided values.
+	OverrideSubscription(ctx context.Context, userID uuid.UUID, req types.SubscriptionOverrideRequest) error
+
+	// --- POI moderation ---
+
+	// SetPOIVerified sets or clears the verified flag, recording the moderator.
+	SetPOIVerified(ctx context.Context, poiID, moderatorID uuid.UUID, verified bool) error
+	// GetPOIEditableFields returns the current values of the fields UpdatePOI can change.
+	GetPOIEditableFields(ctx context.Context, poiID uuid.UUID) (*types.AdminPOIUpdate, error)
+	// UpdatePOI edits the non-nil fields of a POI.
+	UpdatePOI(ctx context.Context, poiID uuid.UUID, params types.AdminPOIUpdate) error
+	// MergePOIs moves favourites, list items, itinerary stops, reviews, partner listings,
+	// events and interaction history from the source POIs to the target, fills empty
+	// target fields, adds the sources' views and saves to the target's, and deletes
+	// the sources.
+	MergePOIs(ctx context.Context, targetID uuid.UUID, sourceIDs []uuid.UUID) error
+	// DeletePOI hard-deletes a POI; dependent rows cascade.
+	DeletePOI(ctx context.Context, poiID uuid.UUID) error
+	// VerifyRestaurantDietary records a moderator's verdict on a restaurant's
+	// diet or allergen, which overrides LLM guesses and user reports.
+	VerifyRestaurantDietary(ctx context.Context, restaurantID, moderatorID uuid.UUID, report types.DietaryReport) error
+}
+
+type RepositoryImpl struct {
+	logger *slog.Logger
+	pgpool *pgxpool.Pool
+	// users handles profile updates and (de)activation, which also revoke tokens.
+	users user.UserRepo
+}
+
+func NewRepository(pgxpool *pgxpool.Pool, users user.UserRepo, logger *slog.Logger) *RepositoryImpl {
+	return &RepositoryImpl{
+		logger: logger,
+		pgpool: pgxpool,
+		users:  users,
+	}
+}
+
+// GetUserByID implements AdminRepo. Unlike the user repository it also returns inactive users.
+func (r *RepositoryImpl) GetUserByID(ctx context.Context, userID uuid.UUID) (*types.UserProfile, error) {
+	ctx, span := otel.Tracer("AdminRepo").Start(ctx, "GetUserByID", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.sql.table", "users"),
+		attribute.String("db.user.id", userID.String()),
+	))
+	defer span.End()
+
+	var u types.UserProfile
+	query := `
+		SELECT id, email, username, firstname, lastname, phone, age, city, country,
+		       display_name, profile_image_url, about_you, is_active, email_verified_at,
+		       last_login_at, theme, language, created_at, updated_at
+		FROM users WHERE id = $1`
+	err := r.pgpool.QueryRow(ctx, query, userID).Scan(
+		&u.ID, &u.Email, &u.Username, &u.Firstname, &u.Lastname, &u.PhoneNumber, &u.Age, &u.City, &u.Country,
+		&u.DisplayName, &u.ProfileImageURL, &u.AboutYou, &u.IsActive, &u.EmailVerifiedAt,
+		&u.LastLoginAt, &u.Theme, &u.Language, &u.CreatedAt, &u.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			span.SetStatus(codes.Error, "User not found")
+			return nil, fmt.Errorf("user not found: %w", types.ErrNotFound)
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, fmt.Errorf("database error fetching user: %w", err)
+	}
+
+	u.Bio = u.AboutYou
+	u.Avatar = u.ProfileImageURL
+	u.JoinedDate = u.CreatedAt
+	span.SetStatus(codes.Ok, "User fetched")
+	return &u, nil
+}
+
+// UpdateProfile implements AdminRepo.
+func (r *RepositoryImpl) UpdateProfile(ctx context.Context, userID uuid.UUID, params types.UpdateProfileParams) error {
+	return r.users.UpdateProfile(ctx, userID, params)
+}
+
+// DeactivateUser implements AdminRepo. Sessions and refresh tokens are revoked.
+func (r *RepositoryImpl) DeactivateUser(ctx context.Context, userID uuid.UUID) error {
+	return r.users.DeactivateUser(ctx, userID)
+}
+
+// ReactivateUser implements AdminRepo.
+func (r *RepositoryImpl) ReactivateUser(ctx context.Context, userID uuid.UUID) error {
+	return r.users.ReactivateUser(ctx, userID)
+}
+
+// GetAllInterests implements AdminRepo.
+func (r *RepositoryImpl) GetAllInterests(ctx context.Context) ([]types.Interest, error) {
+	rows, err := r.pgpool.Query(ctx, `
+		SELECT id, name, description, active, created_at, updated_at, 'global' AS source
+		FROM interests ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching interests: %w", err)
+	}
+	defer rows.Close()
+	return scanInterests(rows)
+}
+
+// GetUserPreferences implements AdminRepo.
+func (r *RepositoryImpl) GetUserPreferences(ctx context.Context, userID uuid.UUID) ([]types.Interest, error) {
+	rows, err := r.pgpool.Query(ctx, `
+		SELECT i.id, i.name, i.description, i.active, i.created_at, i.updated_at, 'global' AS source
+		FROM user_interests ui
+		JOIN interests i ON i.id = ui.interest_id
+		WHERE ui.user_id = $1
+		ORDER BY i.name`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching user interests: %w", err)
+	}
+	defer rows.Close()
+	return scanInterests(rows)
+}
+
+func scanInterests(rows pgx.Rows) ([]types.Interest, error) {
+	var interests []types.Interest
+	for rows.Next() {
+		var i types.Interest
+		if err := rows.Scan(&i.ID, &i.Name, &i.Description, &i.Active, &i.CreatedAt, &i.UpdatedAt, &i.Source); err != nil {
+			return nil, fmt.Errorf("failed to scan interest row: %w", err)
+		}
+		interests = append(interests, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating interest rows: %w", err)
+	}
+	return interests, nil
+}
+
+// SetUserPreferences implements AdminRepo.
+func (r *RepositoryImpl) SetUserPreferences(ctx context.Context, userID uuid.UUID, interestIDs []uuid.UUID) error {
+	tx, err := r.pgpool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("database error beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = tx.Exec(ctx, `DELETE FROM user_interests WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("database error clearing user interests: %w", err)
+	}
+	if len(interestIDs) > 0 {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO user_interests (user_id, interest_id)
+			SELECT $1, unnest($2::uuid[])
+			ON CONFLICT DO NOTHING`, userID, interestIDs)
+		if err != nil {
+			return fmt.Errorf("database error setting user interests: %w", err)
+		}
+	}
+	if err = embeddings.RefreshUserPreferences(ctx, tx, userID); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("database error committing transaction: %w", err)
+	}
+	return nil
+}
+
+// ListUsers implements AdminRepo.
+func (r *RepositoryImpl) ListUsers(ctx context.Context, filter types.AdminUserFilter) ([]types.AdminUserSummary, *types.Cursor, int, error) {
+	ctx, span := otel.Tracer("AdminRepo").Start(ctx, "ListUsers", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.sql.table", "users"),
+		attribute.Int("limit", filter.Page.Limit),
+	))
+	defer span.End()
+
+	var conditions []string
+	var args []interface{}
+	argID := 1
+
+	if filter.Query != "" {
+		conditions = append(conditions, fmt.Sprintf(
+			"(u.email ILIKE $%[1]d ESCAPE '\\' OR u.username ILIKE $%[1]d ESCAPE '\\' OR u.display_name ILIKE $%[1]d ESCAPE '\\')", argID))
+		args = append(args, "%"+escapeLike(filter.Query)+"%")
+		argID++
+	}
+	if filter.Role != "" {
+		conditions = append(conditions, fmt.Sprintf("u.role = $%d", argID))
+		args = append(args, filter.Role)
+		argID++
+	}
+	if filter.IsActive != nil {
+		conditions = append(conditions, fmt.Sprintf("u.is_active = $%d", argID))
+		args = append(args, *filter.IsActive)
+		argID++
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// The total counts every match, not only those after the cursor
+	after := ""
+	if c := filter.Page.Cursor; c != nil {
+		after = fmt.Sprintf("WHERE (created_at, id) < ($%d, $%d)", argID, argID+1)
+		args = append(args, c.Time, c.ID)
+		argID += 2
+	}
+	query := fmt.Sprintf(`
+		WITH matched AS (
+			SELECT u.id, u.email, u.username, u.display_name, u.role, u.is_active,
+			       s.plan::text AS plan, s.status::text AS status, u.last_login_at, u.created_at
+			FROM users u
+			LEFT JOIN subscriptions s ON s.user_id = u.id
+			%s
+		)
+		SELECT id, email, username, display_name, role, is_active,
+		       plan, status, last_login_at, created_at,
+		       (SELECT COUNT(*) FROM matched) AS total_records
+		FROM matched
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d`, where, after, argID)
+	args = append(args, filter.Page.FetchLimit())
+
+	rows, err := r.pgpool.Query(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, nil, 0, fmt.Errorf("database error listing users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []types.AdminUserSummary{}
+	total := 0
+	for rows.Next() {
+		var u types.AdminUserSummary
+		if err := rows.Scan(&u.ID, &u.Email, &u.Username, &u.DisplayName, &u.Role, &u.IsActive,
+			&u.SubscriptionPlan, &u.SubscriptionStatus, &u.LastLoginAt, &u.CreatedAt, &total); err != nil {
+			span.RecordError(err)
+			return nil, nil, 0, fmt.Errorf("failed to scan user row: %w", err)
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, nil, 0, fmt.Errorf("error iterating user rows: %w", err)
+	}
+
+	users, next := types.NextPage(users, filter.Page.Limit, func(u types.AdminUserSummary) types.Cursor {
+		return types.Cursor{Time: &u.CreatedAt, ID: u.ID}
+	})
+	span.SetAttributes(attribute.Int("results.count", len(users)))
+	span.SetStatus(codes.Ok, "Users listed")
+	return users, next, total, nil
+}
+
+// escapeLike escapes LIKE wildcards so user input is matched literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// SetUserRole implements AdminRepo.
+func (r *RepositoryImpl) SetUserRole(ctx context.Context, userID uuid.UUID, role string) (string, error) {
+	query := `
+		UPDATE users u SET role = $1
+		FROM (SELECT id, role FROM users WHERE id = $2 FOR UPDATE) prev
+		WHERE u.id = prev.id
+		RETURNING prev.role`
+	var previous string
+	err := r.pgpool.QueryRow(ctx, query, role, userID).Scan(&previous)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", fmt.Errorf("user not found: %w", types.ErrNotFound)
+		}
+		return "", fmt.Errorf("database error updating role: %w", err)
+	}
+	return previous, nil
+}
+
+// OverrideSubscription implements AdminRepo.
+func (r *RepositoryImpl) OverrideSubscription(ctx context.Context, userID uuid.UUID, req types.SubscriptionOverrideRequest) error {
+	query := `
+		INSERT INTO subscriptions (user_id, plan, status, end_date, trial_end_date, external_provider, override_reason)
+		VALUES ($1, $2::subscription_plan_type, $3::subscription_status, $4, $5, 'admin_override', $6)
+		ON CONFLICT (user_id) DO UPDATE SET
+			plan = EXCLUDED.plan,
+			status = EXCLUDED.status,
+			end_date = EXCLUDED.end_date,
+			trial_end_date = EXCLUDED.trial_end_date,
+			external_provider = EXCLUDED.external_provider,
+			override_reason = EXCLUDED.override_reason`
+	_, err := r.pgpool.Exec(ctx, query, userID, req.Plan, req.Status, req.EndDate, req.TrialEndDate, req.Reason)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23503": // foreign_key_violation
+				return fmt.Errorf("user not found: %w", types.ErrNotFound)
+			case "22P02": // invalid_text_representation (bad enum value)
+				return fmt.Errorf("invalid plan or status: %w", types.ErrBadRequest)
+			}
+		}
+		return fmt.Errorf("database error overriding subscription: %w", err)
+	}
+	return nil
+}
+
+// SetPOIVerified implements AdminRepo.
+func (r *RepositoryImpl) SetPOIVerified(ctx context.Context, poiID, moderatorID uuid.UUID, verified bool) error {
+	query := `
+		UPDATE points_of_interest
+		SET is_verified = $1,
+		    verified_at = CASE WHEN $1 THEN NOW() ELSE NULL END,
+		    verified_by = CASE WHEN $1 THEN $2::uuid ELSE NULL END
+		WHERE id = $3`
+	tag, err := r.pgpool.Exec(ctx, query, verified, moderatorID, poiID)
+	if err != nil {
+		return fmt.Errorf("database error verifying POI: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("POI not found: %w", types.ErrNotFound)
+	}
+	return nil
+}
+
+// GetPOIEditableFields implements AdminRepo.
+func (r *RepositoryImpl) GetPOIEditableFields(ctx context.Context, poiID uuid.UUID) (*types.AdminPOIUpdate, error) {
+	query := `
+		SELECT name, description, category, address, website, phone_number, price_level, tags,
+		       ST_Y(location), ST_X(location), accessibility
+		FROM points_of_interest
+		WHERE id = $1`
+	var p types.AdminPOIUpdate
+	var name string
+	var tags []string
+	var lat, lon float64
+	err := r.pgpool.QueryRow(ctx, query, poiID).Scan(&name, &p.Description, &p.Category, &p.Address,
+		&p.Website, &p.PhoneNumber, &p.PriceLevel, &tags, &lat, &lon, &p.Accessibility)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("POI not found: %w", types.ErrNotFound)
+		}
+		return nil, fmt.Errorf("database error fetching POI: %w", err)
+	}
+	p.Name = &name
+	if tags != nil {
+		p.Tags = &tags
+	}
+	p.Latitude, p.Longitude = &lat, &lon
+	return &p, nil
+}
+
+// UpdatePOI implements AdminRepo.
+func (r *RepositoryImpl) UpdatePOI(ctx context.Context, poiID uuid.UUID, params types.AdminPOIUpdate) error {
+	var setClauses []string
+	var args []interface{}
+	argID := 1
+
+	add := func(column string, value interface{}) {
+		setClauses = append(setClauses, fmt.Sprintf("%s = $%d", column, argID))
+		args = append(args, value)
+		argID++
+	}
+	if params.Name != nil {
+		add("name", *params.Name)
+	}
+	if params.Description != nil {
+		add("description", *params.Description)
+	}
+	if params.Category != nil {
+		add("category", *params.Category)
+	}
+	if params.Address != nil {
+		add("address", *params.Address)
+	}
+	if params.Website != nil {
+		add("website", *params.Website)
+	}
+	if params.PhoneNumber != nil {
+		add("phone_number", *params.PhoneNumber)
+	}
+	if params.PriceLevel != nil {
+		add("price_level", *params.PriceLevel)
+	}
+	if params.Tags != nil {
+		add("tags", *params.Tags)
+	}
+	if params.Accessibility != nil {
+		add("accessibility", params.Accessibility)
+	}
+	if params.Latitude != nil && params.Longitude != nil {
+		setClauses = append(setClauses, fmt.Sprintf("location = ST_SetSRID(ST_MakePoint($%d, $%d), 4326)", argID, argID+1))
+		args = append(args, *params.Longitude, *params.Latitude)
+		argID += 2
+	}
+	if len(setClauses) == 0 {
+		return fmt.Errorf("no fields to update: %w", types.ErrBadRequest)
+	}
+
+	args = append(args, poiID)
+	query := fmt.Sprintf("UPDATE points_of_interest SET %s WHERE id = $%d", strings.Join(setClauses, ", "), argID)
+	tag, err := r.pgpool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("database error updating POI: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("POI not found: %w", types.ErrNotFound)
+	}
+	return nil
+}
+
+// MergePOIs implements AdminRepo.
+func (r *RepositoryImpl) MergePOIs(ctx context.Context, targetID uuid.UUID, sourceIDs []uuid.UUID) error {
+	ctx, span := otel.Tracer("AdminRepo").Start(ctx, "MergePOIs", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "points_of_interest"),
+		attribute.String("poi.target_id", targetID.String()),
+		attribute.Int("poi.source_count", len(sourceIDs)),
+	))
+	defer span.End()
+
+	tx, err := r.pgpool.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("database error beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var found int
+	if err = tx.QueryRow(ctx, `SELECT COUNT(*) FROM points_of_interest WHERE id = ANY($1::uuid[])`,
+		append([]uuid.UUID{targetID}, sourceIDs...)).Scan(&found); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("database error checking POIs: %w", err)
+	}
+	if found != len(sourceIDs)+1 {
+		span.SetStatus(codes.Error, "POI not found")
+		return fmt.Errorf("one or more POIs not found: %w", types.ErrNotFound)
+	}
+
+	// Join tables keyed by (owner, poi_id): each owner may reference the target
+	// only once. Drop the source rows of owners that already reference the
+	// target, and all but one of those referencing several sources, then
+	// re-point the rest. A place is listed at most once per partner, so the
+	// target's own partner listings win over the sources'.
+	repoint := []struct{ table, owner string }{
+		{"user_favorite_pois", "user_id"},
+		{"saved_pois", "user_id"},
+		{"list_items", "list_id"},
+		{"itinerary_pois", "itinerary_id"},
+		{"partner_listings", "partner"},
+	}
+	for _, rp := range repoint {
+		dedupe := fmt.Sprintf(`
+			DELETE FROM %[1]s s
+			WHERE s.poi_id = ANY($2::uuid[])
+			  AND EXISTS (
+			      SELECT 1 FROM %[1]s t
+			      WHERE t.%[2]s = s.%[2]s
+			        AND (t.poi_id = $1 OR (t.poi_id = ANY($2::uuid[]) AND t.poi_id < s.poi_id)))`, rp.table, rp.owner)
+		if _, err = tx.Exec(ctx, dedupe, targetID, sourceIDs); err != nil {
+			span.RecordError(err)
+			return fmt.Errorf("database error deduplicating %s: %w", rp.table, err)
+		}
+		q := fmt.Sprintf(`UPDATE %s SET poi_id = $1 WHERE poi_id = ANY($2::uuid[])`, rp.table)
+		if _, err = tx.Exec(ctx, q, targetID, sourceIDs); err != nil {
+			span.RecordError(err)
+			return fmt.Errorf("database error moving %s: %w", rp.table, err)
+		}
+	}
+
+	// Tables that may reference a POI any number of times. Events and
+	// interactions would otherwise lose their POI to ON DELETE SET NULL.
+	for _, table := range []string{"reviews", "events", "user_interaction_events"} {
+		q := fmt.Sprintf(`UPDATE %s SET poi_id = $1 WHERE poi_id = ANY($2::uuid[])`, table)
+		if _, err = tx.Exec(ctx, q, targetID, sourceIDs); err != nil {
+			span.RecordError(err)
+			return fmt.Errorf("database error moving %s: %w", table, err)
+		}
+	}
+
+	// Fill gaps on the target from the sources, union the tags, and recompute
+	// the rating (the review trigger does not fire on poi_id changes).
+	_, err = tx.Exec(ctx, `
+		UPDATE points_of_interest t SET
+			description  = COALESCE(t.description, s.description),
+			address      = COALESCE(t.address, s.address),
+			website      = COALESCE(t.website, s.website),
+			phone_number = COALESCE(t.phone_number, s.phone_number),
+			opening_hours = COALESCE(t.opening_hours, s.opening_hours),
+			price_level  = COALESCE(t.price_level, s.price_level),
+			tags = ARRAY(SELECT DISTINCT unnest(COALESCE(t.tags, '{}') || s.tags)),
+			average_rating = (SELECT AVG(rating) FROM reviews WHERE poi_id = t.id AND is_published = TRUE),
+			rating_count   = (SELECT COUNT(*) FROM reviews WHERE poi_id = t.id AND is_published = TRUE)
+		FROM (
+			SELECT MAX(description) AS description, MAX(address) AS address, MAX(website) AS website,
+			       MAX(phone_number) AS phone_number, (ARRAY_AGG(opening_hours) FILTER (WHERE opening_hours IS NOT NULL))[1] AS opening_hours,
+			       MAX(price_level) AS price_level,
+			       ARRAY(SELECT DISTINCT unnest(tags) FROM points_of_interest WHERE id = ANY($2::uuid[]) AND tags IS NOT NULL) AS tags
+			FROM points_of_interest WHERE id = ANY($2::uuid[])
+		) s
+		WHERE t.id = $1`, targetID, sourceIDs)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("database error merging POI fields: %w", err)
+	}
+
+	// Carry the sources' analytics over so the target keeps their popularity.
+	// The rollup recomputes yesterday and today from raw events, so re-point
+	// those too; older days only survive in the daily aggregates.
+	if _, err = tx.Exec(ctx, `
+		UPDATE analytics_events SET poi_id = $1
+		WHERE poi_id = ANY($2::uuid[])
+		  AND occurred_at >= (date_trunc('day', NOW() AT TIME ZONE 'UTC') - INTERVAL '1 day') AT TIME ZONE 'UTC'`,
+		targetID, sourceIDs); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("database error moving analytics events: %w", err)
+	}
+	if _, err = tx.Exec(ctx, `
+		INSERT INTO analytics_daily_poi_stats (day, poi_id, views, saves)
+		SELECT day, $1, SUM(views), SUM(saves)
+		FROM analytics_daily_poi_stats
+		WHERE poi_id = ANY($2::uuid[])
+		GROUP BY day
+		ON CONFLICT (day, poi_id) DO UPDATE
+		SET views = analytics_daily_poi_stats.views + EXCLUDED.views,
+		    saves = analytics_daily_poi_stats.saves + EXCLUDED.saves`, targetID, sourceIDs); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("database error merging POI stats: %w", err)
+	}
+	// Every popularity row covers the same window, so their sum is what the
+	// next refresh computes from the folded daily stats.
+	if _, err = tx.Exec(ctx, `
+		INSERT INTO poi_popularity (poi_id, views, saves, score, updated_at)
+		SELECT $1, SUM(views), SUM(saves), SUM(score), NOW()
+		FROM poi_popularity
+		WHERE poi_id = $1 OR poi_id = ANY($2::uuid[])
+		HAVING COUNT(*) > 0
+		ON CONFLICT (poi_id) DO UPDATE
+		SET views = EXCLUDED.views, saves = EXCLUDED.saves, score = EXCLUDED.score, updated_at = EXCLUDED.updated_at`,
+		targetID, sourceIDs); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("database error merging POI popularity: %w", err)
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM points_of_interest WHERE id = ANY($1::uuid[])`, sourceIDs); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("database error deleting merged POIs: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("database error committing transaction: %w", err)
+	}
+	span.SetStatus(codes.Ok, "POIs merged")
+	return nil
+}
+
+// DeletePOI implements AdminRepo.
+func (r *RepositoryImpl) DeletePOI(ctx context.Context, poiID uuid.UUID) error {
+	tag, err := r.pgpool.Exec(ctx, `DELETE FROM points_of_interest WHERE id = $1`, poiID)
+	if err != nil {
+		return fmt.Errorf("database error deleting POI: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("POI not found: %w", types.ErrNotFound)
+	}
+	return nil
+}
 
-	// FindUsers searches/filters users (e.g., for admin panels). Requires pagination/filtering parameters.
-	// ListUsers(ctx context.Context, filterParams ListUsersParams) ([]User, int, error) // Returns users, total count, error
+// VerifyRestaurantDietary implements AdminRepo.
+func (r *RepositoryImpl) VerifyRestaurantDietary(ctx context.Context, restaurantID, moderatorID uuid.UUID, report types.DietaryReport) error {
+	ctx, span := otel.Tracer("AdminRepo").Start(ctx, "VerifyRestaurantDietary", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "restaurant_dietary_attributes"),
+		attribute.String("restaurant.id", restaurantID.String()),
+		attribute.String("dietary.kind", report.Kind),
+		attribute.String("dietary.attribute", report.Attribute),
+	))
+	defer span.End()
 
-	// DeleteUser performs a hard delete (use with caution).
-	// DeleteUser(ctx context.Context, userID uuid.UUID) error
+	// Verifications are kept as history; the latest one counts
+	tag, err := r.pgpool.Exec(ctx, `
+		INSERT INTO restaurant_dietary_attributes (restaurant_id, kind, attribute, available, source, reported_by, note)
+		SELECT $1, $2, $3, $4, 'verified', $5, NULLIF($6, '')
+		WHERE EXISTS (SELECT 1 FROM restaurant_details WHERE id = $1)`,
+		restaurantID, report.Kind, report.Attribute, report.Available, moderatorID, report.Note)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("database error verifying restaurant dietary attribute: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("restaurant not found: %w", types.ErrNotFound)
+	}
+	return nil
 }
@@ -0,0 +1,303 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/audit"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Service = (*ServiceImpl)(nil)
+
+// Service defines the business logic for admin and moderation operations.
+type Service interface {
+	ListUsers(ctx context.Context, filter types.AdminUserFilter) (*types.PaginatedAdminUsersResponse, error)
+	GetUser(ctx context.Context, userID uuid.UUID) (*types.UserProfile, error)
+	DeactivateUser(ctx context.Context, actorID, userID uuid.UUID) error
+	ReactivateUser(ctx context.Context, actorID, userID uuid.UUID) error
+	SetUserRole(ctx context.Context, actorID, userID uuid.UUID, role string) error
+	OverrideSubscription(ctx context.Context, actorID, userID uuid.UUID, req types.SubscriptionOverrideRequest) error
+
+	VerifyPOI(ctx context.Context, actorID, poiID uuid.UUID, verified bool) error
+	UpdatePOI(ctx context.Context, actorID, poiID uuid.UUID, params types.AdminPOIUpdate) error
+	MergePOIs(ctx context.Context, actorID, targetID uuid.UUID, sourceIDs []uuid.UUID) error
+	DeletePOI(ctx context.Context, actorID, poiID uuid.UUID) error
+
+	VerifyRestaurantDietary(ctx context.Context, actorID, restaurantID uuid.UUID, report types.DietaryReport) error
+}
+
+type ServiceImpl struct {
+	logger *slog.Logger
+	repo   AdminRepo
+	audit  audit.Recorder
+}
+
+// NewServiceImpl creates a new admin service. Every mutating action is recorded to auditor.
+func NewServiceImpl(repo AdminRepo, auditor audit.Recorder, logger *slog.Logger) *ServiceImpl {
+	return &ServiceImpl{
+		logger: logger,
+		repo:   repo,
+		audit:  auditor,
+	}
+}
+
+// ListUsers returns a page of users matching the filter.
+func (s *ServiceImpl) ListUsers(ctx context.Context, filter types.AdminUserFilter) (*types.PaginatedAdminUsersResponse, error) {
+	ctx, span := otel.Tracer("AdminService").Start(ctx, "ListUsers", trace.WithAttributes(
+		attribute.String("filter.role", filter.Role),
+		attribute.Int("filter.limit", filter.Page.Limit),
+	))
+	defer span.End()
+	l := s.logger.With(slog.String("method", "ListUsers"))
+
+	filter.Page = filter.Page.Clamp(20, 100) // Default and max page size
+	if filter.Role != "" {
+		if _, ok := types.ValidRoles[filter.Role]; !ok {
+			return nil, fmt.Errorf("unknown role %q: %w", filter.Role, types.ErrBadRequest)
+		}
+	}
+
+	users, next, total, err := s.repo.ListUsers(ctx, filter)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to list users", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to list users")
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Users listed")
+	return &types.PaginatedAdminUsersResponse{
+		Users:        users,
+		Limit:        filter.Page.Limit,
+		NextCursor:   next,
+		TotalRecords: total,
+	}, nil
+}
+
+// GetUser returns a user's profile, including inactive users.
+func (s *ServiceImpl) GetUser(ctx context.Context, userID uuid.UUID) (*types.UserProfile, error) {
+	profile, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get user", slog.String("userID", userID.String()), slog.Any("error", err))
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return profile, nil
+}
+
+// DeactivateUser deactivates a user and revokes their sessions. Admins cannot deactivate themselves.
+func (s *ServiceImpl) DeactivateUser(ctx context.Context, actorID, userID uuid.UUID) error {
+	l := s.logger.With(slog.String("method", "DeactivateUser"), slog.String("actorID", actorID.String()), slog.String("userID", userID.String()))
+	if actorID == userID {
+		return fmt.Errorf("admins cannot deactivate their own account: %w", types.ErrBadRequest)
+	}
+	if err := s.repo.DeactivateUser(ctx, userID); err != nil {
+		l.ErrorContext(ctx, "Failed to deactivate user", slog.Any("error", err))
+		return fmt.Errorf("failed to deactivate user: %w", err)
+	}
+	s.audit.Record(ctx, types.AuditEntry{
+		ActorID:    &actorID,
+		Action:     types.AuditActionAdminUserDeactivated,
+		TargetType: types.AuditTargetUser,
+		TargetID:   &userID,
+	})
+	l.InfoContext(ctx, "User deactivated by admin")
+	return nil
+}
+
+// ReactivateUser reactivates a previously deactivated user.
+func (s *ServiceImpl) ReactivateUser(ctx context.Context, actorID, userID uuid.UUID) error {
+	l := s.logger.With(slog.String("method", "ReactivateUser"), slog.String("actorID", actorID.String()), slog.String("userID", userID.String()))
+	if err := s.repo.ReactivateUser(ctx, userID); err != nil {
+		l.ErrorContext(ctx, "Failed to reactivate user", slog.Any("error", err))
+		return fmt.Errorf("failed to reactivate user: %w", err)
+	}
+	s.audit.Record(ctx, types.AuditEntry{
+		ActorID:    &actorID,
+		Action:     types.AuditActionAdminUserReactivated,
+		TargetType: types.AuditTargetUser,
+		TargetID:   &userID,
+	})
+	l.InfoContext(ctx, "User reactivated by admin")
+	return nil
+}
+
+// SetUserRole changes a user's role. Admins cannot change their own role, so
+// there is always at least one admin able to undo a mistake.
+func (s *ServiceImpl) SetUserRole(ctx context.Context, actorID, userID uuid.UUID, role string) error {
+	l := s.logger.With(slog.String("method", "SetUserRole"), slog.String("actorID", actorID.String()), slog.String("userID", userID.String()))
+	if _, ok := types.ValidRoles[role]; !ok {
+		return fmt.Errorf("unknown role %q: %w", role, types.ErrBadRequest)
+	}
+	if actorID == userID {
+		return fmt.Errorf("admins cannot change their own role: %w", types.ErrBadRequest)
+	}
+	previous, err := s.repo.SetUserRole(ctx, userID, role)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to set user role", slog.Any("error", err))
+		return fmt.Errorf("failed to set user role: %w", err)
+	}
+	s.audit.Record(ctx, types.AuditEntry{
+		ActorID:    &actorID,
+		Action:     types.AuditActionRoleChanged,
+		TargetType: types.AuditTargetUser,
+		TargetID:   &userID,
+		Before:     map[string]string{"role": previous},
+		After:      map[string]string{"role": role},
+	})
+	l.InfoContext(ctx, "User role changed", slog.String("role", role))
+	return nil
+}
+
+// OverrideSubscription sets a user's plan and status directly.
+func (s *ServiceImpl) OverrideSubscription(ctx context.Context, actorID, userID uuid.UUID, req types.SubscriptionOverrideRequest) error {
+	l := s.logger.With(slog.String("method", "OverrideSubscription"), slog.String("actorID", actorID.String()), slog.String("userID", userID.String()))
+	if req.Plan == "" || req.Status == "" || req.Reason == "" {
+		return fmt.Errorf("plan, status and reason are required: %w", types.ErrBadRequest)
+	}
+	if err := s.repo.OverrideSubscription(ctx, userID, req); err != nil {
+		l.ErrorContext(ctx, "Failed to override subscription", slog.Any("error", err))
+		return fmt.Errorf("failed to override subscription: %w", err)
+	}
+	s.audit.Record(ctx, types.AuditEntry{
+		ActorID:    &actorID,
+		Action:     types.AuditActionSubscriptionOverride,
+		TargetType: types.AuditTargetUser,
+		TargetID:   &userID,
+		After:      req,
+	})
+	l.InfoContext(ctx, "Subscription overridden", slog.String("plan", req.Plan), slog.String("status", req.Status))
+	return nil
+}
+
+// VerifyPOI sets or clears a POI's verified flag.
+func (s *ServiceImpl) VerifyPOI(ctx context.Context, actorID, poiID uuid.UUID, verified bool) error {
+	if err := s.repo.SetPOIVerified(ctx, poiID, actorID, verified); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to verify POI", slog.String("poiID", poiID.String()), slog.Any("error", err))
+		return fmt.Errorf("failed to verify POI: %w", err)
+	}
+	s.audit.Record(ctx, types.AuditEntry{
+		ActorID:    &actorID,
+		Action:     types.AuditActionPOIVerified,
+		TargetType: types.AuditTargetPOI,
+		TargetID:   &poiID,
+		After:      map[string]bool{"is_verified": verified},
+	})
+	return nil
+}
+
+// UpdatePOI edits a POI's fields.
+func (s *ServiceImpl) UpdatePOI(ctx context.Context, actorID, poiID uuid.UUID, params types.AdminPOIUpdate) error {
+	if params.PriceLevel != nil && (*params.PriceLevel < 1 || *params.PriceLevel > 4) {
+		return fmt.Errorf("price_level must be between 1 and 4: %w", types.ErrBadRequest)
+	}
+	if (params.Latitude == nil) != (params.Longitude == nil) {
+		return fmt.Errorf("latitude and longitude must be set together: %w", types.ErrBadRequest)
+	}
+	if params.Latitude != nil && (*params.Latitude < -90 || *params.Latitude > 90 || *params.Longitude < -180 || *params.Longitude > 180) {
+		return fmt.Errorf("invalid coordinates: %w", types.ErrBadRequest)
+	}
+	if err := types.ValidateAccessibility(params.Accessibility); err != nil {
+		return err
+	}
+	before, err := s.repo.GetPOIEditableFields(ctx, poiID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to load POI", slog.String("poiID", poiID.String()), slog.Any("error", err))
+		return fmt.Errorf("failed to update POI: %w", err)
+	}
+	if err := s.repo.UpdatePOI(ctx, poiID, params); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to update POI", slog.String("poiID", poiID.String()), slog.Any("error", err))
+		return fmt.Errorf("failed to update POI: %w", err)
+	}
+	s.audit.Record(ctx, types.AuditEntry{
+		ActorID:    &actorID,
+		Action:     types.AuditActionPOIUpdated,
+		TargetType: types.AuditTargetPOI,
+		TargetID:   &poiID,
+		Before:     before,
+		After:      params,
+	})
+	return nil
+}
+
+// MergePOIs folds duplicate POIs into the target.
+func (s *ServiceImpl) MergePOIs(ctx context.Context, actorID, targetID uuid.UUID, sourceIDs []uuid.UUID) error {
+	if len(sourceIDs) == 0 {
+		return fmt.Errorf("at least one source POI is required: %w", types.ErrBadRequest)
+	}
+	seen := make(map[uuid.UUID]struct{}, len(sourceIDs))
+	for _, id := range sourceIDs {
+		if id == targetID {
+			return fmt.Errorf("cannot merge a POI into itself: %w", types.ErrBadRequest)
+		}
+		if _, dup := seen[id]; dup {
+			return fmt.Errorf("duplicate source POI %s: %w", id, types.ErrBadRequest)
+		}
+		seen[id] = struct{}{}
+	}
+	if err := s.repo.MergePOIs(ctx, targetID, sourceIDs); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to merge POIs", slog.String("targetID", targetID.String()), slog.Any("error", err))
+		return fmt.Errorf("failed to merge POIs: %w", err)
+	}
+	s.audit.Record(ctx, types.AuditEntry{
+		ActorID:    &actorID,
+		Action:     types.AuditActionPOIsMerged,
+		TargetType: types.AuditTargetPOI,
+		TargetID:   &targetID,
+		Metadata:   map[string]interface{}{"source_ids": sourceIDs},
+	})
+	s.logger.InfoContext(ctx, "POIs merged", slog.String("actorID", actorID.String()), slog.String("targetID", targetID.String()), slog.Int("sources", len(sourceIDs)))
+	return nil
+}
+
+// DeletePOI removes a POI.
+func (s *ServiceImpl) DeletePOI(ctx context.Context, actorID, poiID uuid.UUID) error {
+	// Keep the deleted values in the audit log; the row itself is gone afterwards
+	before, err := s.repo.GetPOIEditableFields(ctx, poiID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to load POI", slog.String("poiID", poiID.String()), slog.Any("error", err))
+		return fmt.Errorf("failed to delete POI: %w", err)
+	}
+	if err := s.repo.DeletePOI(ctx, poiID); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to delete POI", slog.String("poiID", poiID.String()), slog.Any("error", err))
+		return fmt.Errorf("failed to delete POI: %w", err)
+	}
+	s.audit.Record(ctx, types.AuditEntry{
+		ActorID:    &actorID,
+		Action:     types.AuditActionPOIDeleted,
+		TargetType: types.AuditTargetPOI,
+		TargetID:   &poiID,
+		Before:     before,
+	})
+	s.logger.InfoContext(ctx, "POI deleted", slog.String("actorID", actorID.String()), slog.String("poiID", poiID.String()))
+	return nil
+}
+
+// VerifyRestaurantDietary confirms or denies a restaurant's diet or allergen
+// after a moderator checked it with the restaurant. A verified allergen counts
+// as confirmed for allergen-free searches.
+func (s *ServiceImpl) VerifyRestaurantDietary(ctx context.Context, actorID, restaurantID uuid.UUID, report types.DietaryReport) error {
+	if err := report.Normalize(); err != nil {
+		return err
+	}
+	if err := s.repo.VerifyRestaurantDietary(ctx, restaurantID, actorID, report); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to verify restaurant dietary attribute",
+			slog.String("restaurantID", restaurantID.String()), slog.Any("error", err))
+		return fmt.Errorf("failed to verify restaurant dietary attribute: %w", err)
+	}
+	s.audit.Record(ctx, types.AuditEntry{
+		ActorID:    &actorID,
+		Action:     types.AuditActionDietaryVerified,
+		TargetType: types.AuditTargetRestaurant,
+		TargetID:   &restaurantID,
+		After:      report,
+	})
+	return nil
+}
@@ -0,0 +1,282 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+// MockAdminRepo is a mock implementation of AdminRepo
+type MockAdminRepo struct {
+	mock.Mock
+}
+
+func (m *MockAdminRepo) GetUserByID(ctx context.Context, userID uuid.UUID) (*types.UserProfile, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.UserProfile), args.Error(1)
+}
+
+func (m *MockAdminRepo) UpdateProfile(ctx context.Context, userID uuid.UUID, params types.UpdateProfileParams) error {
+	return m.Called(ctx, userID, params).Error(0)
+}
+
+func (m *MockAdminRepo) DeactivateUser(ctx context.Context, userID uuid.UUID) error {
+	return m.Called(ctx, userID).Error(0)
+}
+
+func (m *MockAdminRepo) ReactivateUser(ctx context.Context, userID uuid.UUID) error {
+	return m.Called(ctx, userID).Error(0)
+}
+
+func (m *MockAdminRepo) GetAllInterests(ctx context.Context) ([]types.Interest, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.Interest), args.Error(1)
+}
+
+func (m *MockAdminRepo) GetUserPreferences(ctx context.Context, userID uuid.UUID) ([]types.Interest, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.Interest), args.Error(1)
+}
+
+func (m *MockAdminRepo) SetUserPreferences(ctx context.Context, userID uuid.UUID, interestIDs []uuid.UUID) error {
+	return m.Called(ctx, userID, interestIDs).Error(0)
+}
+
+func (m *MockAdminRepo) ListUsers(ctx context.Context, filter types.AdminUserFilter) ([]types.AdminUserSummary, *types.Cursor, int, error) {
+	args := m.Called(ctx, filter)
+	next, _ := args.Get(1).(*types.Cursor)
+	if args.Get(0) == nil {
+		return nil, next, args.Int(2), args.Error(3)
+	}
+	return args.Get(0).([]types.AdminUserSummary), next, args.Int(2), args.Error(3)
+}
+
+func (m *MockAdminRepo) SetUserRole(ctx context.Context, userID uuid.UUID, role string) (string, error) {
+	args := m.Called(ctx, userID, role)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAdminRepo) OverrideSubscription(ctx context.Context, userID uuid.UUID, req types.SubscriptionOverrideRequest) error {
+	return m.Called(ctx, userID, req).Error(0)
+}
+
+func (m *MockAdminRepo) SetPOIVerified(ctx context.Context, poiID, moderatorID uuid.UUID, verified bool) error {
+	return m.Called(ctx, poiID, moderatorID, verified).Error(0)
+}
+
+func (m *MockAdminRepo) GetPOIEditableFields(ctx context.Context, poiID uuid.UUID) (*types.AdminPOIUpdate, error) {
+	args := m.Called(ctx, poiID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.AdminPOIUpdate), args.Error(1)
+}
+
+func (m *MockAdminRepo) UpdatePOI(ctx context.Context, poiID uuid.UUID, params types.AdminPOIUpdate) error {
+	return m.Called(ctx, poiID, params).Error(0)
+}
+
+func (m *MockAdminRepo) MergePOIs(ctx context.Context, targetID uuid.UUID, sourceIDs []uuid.UUID) error {
+	return m.Called(ctx, targetID, sourceIDs).Error(0)
+}
+
+func (m *MockAdminRepo) DeletePOI(ctx context.Context, poiID uuid.UUID) error {
+	return m.Called(ctx, poiID).Error(0)
+}
+
+func (m *MockAdminRepo) VerifyRestaurantDietary(ctx context.Context, restaurantID, moderatorID uuid.UUID, report types.DietaryReport) error {
+	return m.Called(ctx, restaurantID, moderatorID, report).Error(0)
+}
+
+// recordingAuditor keeps every audit entry in memory
+type recordingAuditor struct {
+	entries []types.AuditEntry
+}
+
+func (a *recordingAuditor) Record(_ context.Context, entry types.AuditEntry) {
+	a.entries = append(a.entries, entry)
+}
+
+// Helper to setup service with mock repository
+func setupAdminServiceTest() (*ServiceImpl, *MockAdminRepo, *recordingAuditor) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	mockRepo := new(MockAdminRepo)
+	auditor := &recordingAuditor{}
+	return NewServiceImpl(mockRepo, auditor, logger), mockRepo, auditor
+}
+
+func TestServiceImpl_ListUsers(t *testing.T) {
+	service, mockRepo, _ := setupAdminServiceTest()
+	ctx := context.Background()
+
+	t.Run("defaults and clamps pagination", func(t *testing.T) {
+		next := &types.Cursor{ID: uuid.New()}
+		mockRepo.On("ListUsers", mock.Anything, types.AdminUserFilter{Page: types.PageRequest{Limit: 100}}).
+			Return([]types.AdminUserSummary{{ID: uuid.New()}}, next, 101, nil).Once()
+		mockRepo.On("ListUsers", mock.Anything, types.AdminUserFilter{Page: types.PageRequest{Cursor: next, Limit: 20}}).
+			Return([]types.AdminUserSummary{{ID: uuid.New()}}, (*types.Cursor)(nil), 101, nil).Once()
+
+		resp, err := service.ListUsers(ctx, types.AdminUserFilter{Page: types.PageRequest{Limit: 5000}})
+
+		require.NoError(t, err)
+		assert.Equal(t, 100, resp.Limit)
+		assert.Equal(t, next, resp.NextCursor)
+		assert.Equal(t, 101, resp.TotalRecords)
+
+		resp, err = service.ListUsers(ctx, types.AdminUserFilter{Page: types.PageRequest{Cursor: next, Limit: -3}})
+
+		require.NoError(t, err)
+		assert.Equal(t, 20, resp.Limit)
+		assert.Nil(t, resp.NextCursor)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown role", func(t *testing.T) {
+		service, mockRepo, _ := setupAdminServiceTest()
+		_, err := service.ListUsers(ctx, types.AdminUserFilter{Role: "superuser"})
+
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, types.ErrBadRequest))
+		mockRepo.AssertNotCalled(t, "ListUsers", mock.Anything, mock.Anything)
+	})
+}
+
+func TestServiceImpl_SetUserRole(t *testing.T) {
+	service, mockRepo, auditor := setupAdminServiceTest()
+	ctx := context.Background()
+	actorID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.On("SetUserRole", ctx, userID, types.UserRoleModerator).Return(types.UserRoleUser, nil).Once()
+
+		require.NoError(t, service.SetUserRole(ctx, actorID, userID, types.UserRoleModerator))
+		mockRepo.AssertExpectations(t)
+
+		require.Len(t, auditor.entries, 1)
+		entry := auditor.entries[0]
+		assert.Equal(t, types.AuditActionRoleChanged, entry.Action)
+		assert.Equal(t, actorID, *entry.ActorID)
+		assert.Equal(t, userID, *entry.TargetID)
+		assert.Equal(t, map[string]string{"role": types.UserRoleUser}, entry.Before)
+		assert.Equal(t, map[string]string{"role": types.UserRoleModerator}, entry.After)
+	})
+
+	t.Run("cannot change own role", func(t *testing.T) {
+		err := service.SetUserRole(ctx, actorID, actorID, types.UserRoleUser)
+
+		assert.True(t, errors.Is(err, types.ErrBadRequest))
+		assert.Len(t, auditor.entries, 1) // nothing recorded for rejected changes
+	})
+
+	t.Run("unknown role", func(t *testing.T) {
+		err := service.SetUserRole(ctx, actorID, userID, "root")
+
+		assert.True(t, errors.Is(err, types.ErrBadRequest))
+	})
+}
+
+func TestServiceImpl_DeactivateUser(t *testing.T) {
+	service, mockRepo, _ := setupAdminServiceTest()
+	ctx := context.Background()
+	actorID := uuid.New()
+
+	err := service.DeactivateUser(ctx, actorID, actorID)
+
+	assert.True(t, errors.Is(err, types.ErrBadRequest))
+	mockRepo.AssertNotCalled(t, "DeactivateUser", mock.Anything, mock.Anything)
+}
+
+func TestServiceImpl_MergePOIs(t *testing.T) {
+	service, mockRepo, _ := setupAdminServiceTest()
+	ctx := context.Background()
+	actorID := uuid.New()
+	targetID := uuid.New()
+	sourceID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.On("MergePOIs", ctx, targetID, []uuid.UUID{sourceID}).Return(nil).Once()
+
+		require.NoError(t, service.MergePOIs(ctx, actorID, targetID, []uuid.UUID{sourceID}))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects invalid sources", func(t *testing.T) {
+		for name, sources := range map[string][]uuid.UUID{
+			"empty":     nil,
+			"self":      {targetID},
+			"duplicate": {sourceID, sourceID},
+		} {
+			err := service.MergePOIs(ctx, actorID, targetID, sources)
+			assert.True(t, errors.Is(err, types.ErrBadRequest), name)
+		}
+	})
+}
+
+func TestServiceImpl_UpdatePOI(t *testing.T) {
+	service, _, _ := setupAdminServiceTest()
+	ctx := context.Background()
+	lat := 41.15
+
+	priceLevel := 7
+	err := service.UpdatePOI(ctx, uuid.New(), uuid.New(), types.AdminPOIUpdate{PriceLevel: &priceLevel})
+	assert.True(t, errors.Is(err, types.ErrBadRequest))
+
+	err = service.UpdatePOI(ctx, uuid.New(), uuid.New(), types.AdminPOIUpdate{Latitude: &lat})
+	assert.True(t, errors.Is(err, types.ErrBadRequest))
+
+	err = service.UpdatePOI(ctx, uuid.New(), uuid.New(), types.AdminPOIUpdate{Accessibility: map[string]bool{"teleporter": true}})
+	assert.True(t, errors.Is(err, types.ErrBadRequest))
+}
+
+func TestServiceImpl_VerifyRestaurantDietary(t *testing.T) {
+	service, mockRepo, auditor := setupAdminServiceTest()
+	ctx := context.Background()
+	actorID := uuid.New()
+	restaurantID := uuid.New()
+
+	t.Run("normalizes, saves and audits the verdict", func(t *testing.T) {
+		report := types.DietaryReport{Kind: types.DietaryKindAllergenFree, Attribute: "Tree Nuts", Available: true, Note: " Separate prep area "}
+		saved := types.DietaryReport{Kind: types.DietaryKindAllergenFree, Attribute: "tree_nuts", Available: true, Note: "Separate prep area"}
+		mockRepo.On("VerifyRestaurantDietary", ctx, restaurantID, actorID, saved).Return(nil).Once()
+
+		require.NoError(t, service.VerifyRestaurantDietary(ctx, actorID, restaurantID, report))
+		mockRepo.AssertExpectations(t)
+		require.Len(t, auditor.entries, 1)
+		assert.Equal(t, types.AuditActionDietaryVerified, auditor.entries[0].Action)
+		assert.Equal(t, &restaurantID, auditor.entries[0].TargetID)
+	})
+
+	t.Run("rejects unknown kinds", func(t *testing.T) {
+		err := service.VerifyRestaurantDietary(ctx, actorID, restaurantID, types.DietaryReport{Kind: "halal", Attribute: "pork"})
+		assert.True(t, errors.Is(err, types.ErrBadRequest))
+	})
+
+	t.Run("restaurant not found", func(t *testing.T) {
+		report := types.DietaryReport{Kind: types.DietaryKindDiet, Attribute: "vegan"}
+		mockRepo.On("VerifyRestaurantDietary", ctx, restaurantID, actorID, report).
+			Return(fmt.Errorf("restaurant not found: %w", types.ErrNotFound)).Once()
+
+		err := service.VerifyRestaurantDietary(ctx, actorID, restaurantID, report)
+		assert.True(t, errors.Is(err, types.ErrNotFound))
+	})
+}
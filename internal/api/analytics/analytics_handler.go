@@ -0,0 +1,240 @@
+package analytics
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/auth"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Handler = (*HandlerImpl)(nil)
+
+type Handler interface {
+	IngestEvents(w http.ResponseWriter, r *http.Request)
+	TopCities(w http.ResponseWriter, r *http.Request)
+	Conversion(w http.ResponseWriter, r *http.Request)
+	PopularPOIs(w http.ResponseWriter, r *http.Request)
+}
+
+type HandlerImpl struct {
+	logger  *slog.Logger
+	service Service
+}
+
+func NewHandler(service Service, logger *slog.Logger) *HandlerImpl {
+	return &HandlerImpl{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// dateRange parses the optional from and to query parameters, as YYYY-MM-DD.
+func dateRange(r *http.Request) (from, to time.Time, err error) {
+	if v := r.URL.Query().Get("from"); v != "" {
+		if from, err = time.Parse(time.DateOnly, v); err != nil {
+			return from, to, err
+		}
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		if to, err = time.Parse(time.DateOnly, v); err != nil {
+			return from, to, err
+		}
+	}
+	return from, to, nil
+}
+
+// limitParam parses the optional limit query parameter.
+func limitParam(r *http.Request) (int, error) {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit < 1 {
+		return 0, errors.New("invalid limit")
+	}
+	return limit, nil
+}
+
+// IngestEvents godoc
+// @Summary      Record Analytics Events
+// @Description  Records a batch of up to 500 events of what the user did in the client: searches, POI and list views, saves and chats. Events with unknown names, older than a day or with properties over 4KB are dropped and counted as rejected. occurred_at defaults to when the batch is received.
+// @Tags         Analytics
+// @Accept       json
+// @Produce      json
+// @Param        batch body types.AnalyticsBatchRequest true "Events to record"
+// @Success      202 {object} types.AnalyticsBatchResponse
+// @Failure      400 {object} types.Response "Empty or oversized batch"
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /analytics/events [post]
+func (h *HandlerImpl) IngestEvents(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("AnalyticsHandler").Start(r.Context(), "IngestEvents")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "IngestEvents"))
+
+	userID := api.Requester(auth.GetUserIDFromContext(ctx))
+
+	var req types.AnalyticsBatchRequest
+	if err := api.DecodeJSONBody(w, r, &req); err != nil {
+		l.ErrorContext(ctx, "Failed to decode request", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Bad request")
+		return
+	}
+
+	resp, err := h.service.Ingest(ctx, userID, req.Events)
+	if err != nil {
+		l.ErrorContext(ctx, "Service failed to ingest analytics events", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to ingest events")
+		api.WriteServiceError(w, r, err, "Failed to record events")
+		return
+	}
+
+	span.SetAttributes(attribute.Int("events.accepted", resp.Accepted))
+	span.SetStatus(codes.Ok, "Events ingested")
+	api.WriteJSONResponse(w, r, http.StatusAccepted, resp)
+}
+
+// TopCities godoc
+// @Summary      Top Cities
+// @Description  Returns the cities with the most searches, POI views, chats and saved itineraries between two days, inclusive. The range defaults to the last 30 days. Admin only.
+// @Tags         Admin
+// @Produce      json
+// @Param        from query string false "First day, YYYY-MM-DD"
+// @Param        to query string false "Last day, YYYY-MM-DD; defaults to today"
+// @Param        limit query int false "Number of cities, at most 100" default(10)
+// @Success      200 {array} types.CityAnalytics
+// @Failure      400 {object} types.Response "Invalid range or limit"
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      403 {object} types.Response "Forbidden"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/analytics/top-cities [get]
+func (h *HandlerImpl) TopCities(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("AnalyticsHandler").Start(r.Context(), "TopCities")
+	defer span.End()
+
+	from, to, err := dateRange(r)
+	if err != nil {
+		span.SetStatus(codes.Error, "Invalid date range")
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid from or to, expected YYYY-MM-DD")
+		return
+	}
+	limit, err := limitParam(r)
+	if err != nil {
+		span.SetStatus(codes.Error, "Invalid limit")
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	cities, err := h.service.TopCities(ctx, from, to, limit)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Service failed to report top cities", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to report top cities")
+		api.WriteServiceError(w, r, err, "Failed to report top cities")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Top cities reported")
+	api.WriteJSONResponse(w, r, http.StatusOK, cities)
+}
+
+// Conversion godoc
+// @Summary      Chat to Itinerary Conversion
+// @Description  Returns, per day between two days inclusive, how many users started a chat and how many of them saved an itinerary later that day. The range defaults to the last 30 days. Admin only.
+// @Tags         Admin
+// @Produce      json
+// @Param        from query string false "First day, YYYY-MM-DD"
+// @Param        to query string false "Last day, YYYY-MM-DD; defaults to today"
+// @Success      200 {array} types.ConversionDay
+// @Failure      400 {object} types.Response "Invalid range"
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      403 {object} types.Response "Forbidden"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/analytics/conversion [get]
+func (h *HandlerImpl) Conversion(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("AnalyticsHandler").Start(r.Context(), "Conversion")
+	defer span.End()
+
+	from, to, err := dateRange(r)
+	if err != nil {
+		span.SetStatus(codes.Error, "Invalid date range")
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid from or to, expected YYYY-MM-DD")
+		return
+	}
+
+	days, err := h.service.Conversion(ctx, from, to)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Service failed to report conversion", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to report conversion")
+		api.WriteServiceError(w, r, err, "Failed to report conversion")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Conversion reported")
+	api.WriteJSONResponse(w, r, http.StatusOK, days)
+}
+
+// PopularPOIs godoc
+// @Summary      Popular POIs
+// @Description  Returns the POIs most viewed and saved over the popularity window, optionally of one city. A save counts as five views. Admin only.
+// @Tags         Admin
+// @Produce      json
+// @Param        city_id query string false "City ID"
+// @Param        limit query int false "Number of POIs, at most 100" default(10)
+// @Success      200 {array} types.POIPopularity
+// @Failure      400 {object} types.Response "Invalid city ID or limit"
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      403 {object} types.Response "Forbidden"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/analytics/popular-pois [get]
+func (h *HandlerImpl) PopularPOIs(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("AnalyticsHandler").Start(r.Context(), "PopularPOIs")
+	defer span.End()
+
+	var cityID *uuid.UUID
+	if v := r.URL.Query().Get("city_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			span.SetStatus(codes.Error, "Invalid city ID")
+			api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid city_id")
+			return
+		}
+		cityID = &id
+	}
+	limit, err := limitParam(r)
+	if err != nil {
+		span.SetStatus(codes.Error, "Invalid limit")
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	pois, err := h.service.PopularPOIs(ctx, cityID, limit)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Service failed to report popular POIs", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to report popular POIs")
+		api.WriteServiceError(w, r, err, "Failed to report popular POIs")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Popular POIs reported")
+	api.WriteJSONResponse(w, r, http.StatusOK, pois)
+}
@@ -0,0 +1,387 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Repository = (*RepositoryImpl)(nil)
+
+// Repository stores raw analytics events and the aggregates rolled up from them.
+type Repository interface {
+	InsertEvents(ctx context.Context, events []types.AnalyticsEvent) error
+	// EnsurePartition creates the events partition of the month of day.
+	EnsurePartition(ctx context.Context, day time.Time) error
+	// RollupDay recomputes the daily aggregates of day from its events.
+	RollupDay(ctx context.Context, day time.Time) error
+	// RefreshPopularity recomputes POI popularity from the days since since.
+	RefreshPopularity(ctx context.Context, since time.Time) error
+	// RefreshListCounters sets the view counters of lists to their totals over
+	// all days. save_count stays with the saved_lists triggers, as it counts
+	// current saves rather than save events; see the list SaveList path.
+	RefreshListCounters(ctx context.Context) error
+
+	// TopCities returns the most active cities between the days from and to.
+	TopCities(ctx context.Context, from, to time.Time, limit int) ([]types.CityAnalytics, error)
+	Conversions(ctx context.Context, from, to time.Time) ([]types.ConversionDay, error)
+	// PopularPOIs returns the POIs with the highest popularity, of one city
+	// when cityID is set.
+	PopularPOIs(ctx context.Context, cityID *uuid.UUID, limit int) ([]types.POIPopularity, error)
+}
+
+type RepositoryImpl struct {
+	logger *slog.Logger
+	pgpool *pgxpool.Pool
+}
+
+func NewRepository(pgxpool *pgxpool.Pool, logger *slog.Logger) *RepositoryImpl {
+	return &RepositoryImpl{
+		logger: logger,
+		pgpool: pgxpool,
+	}
+}
+
+// InsertEvents implements Repository.
+func (r *RepositoryImpl) InsertEvents(ctx context.Context, events []types.AnalyticsEvent) error {
+	ctx, span := otel.Tracer("AnalyticsRepo").Start(ctx, "InsertEvents", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "analytics_events"),
+		attribute.Int("events.count", len(events)),
+	))
+	defer span.End()
+
+	if len(events) == 0 {
+		return nil
+	}
+	n := len(events)
+	names, sources, properties := make([]string, n), make([]string, n), make([]string, n)
+	userIDs, sessionIDs := make([]*uuid.UUID, n), make([]*uuid.UUID, n)
+	cityIDs, poiIDs, listIDs := make([]*uuid.UUID, n), make([]*uuid.UUID, n), make([]*uuid.UUID, n)
+	occurredAt := make([]time.Time, n)
+	for i, e := range events {
+		props := []byte("{}")
+		if len(e.Properties) > 0 {
+			var err error
+			if props, err = json.Marshal(e.Properties); err != nil {
+				return fmt.Errorf("failed to encode analytics event properties: %w", err)
+			}
+		}
+		names[i], sources[i], properties[i] = e.Name, e.Source, string(props)
+		userIDs[i], sessionIDs[i] = e.UserID, e.SessionID
+		cityIDs[i], poiIDs[i], listIDs[i] = e.CityID, e.POIID, e.ListID
+		occurredAt[i] = e.OccurredAt
+	}
+
+	_, err := r.pgpool.Exec(ctx, `
+		INSERT INTO analytics_events (name, source, user_id, session_id, city_id, poi_id, list_id, properties, occurred_at)
+		SELECT name, source, user_id, session_id, city_id, poi_id, list_id, properties::jsonb, occurred_at
+		FROM unnest($1::text[], $2::text[], $3::uuid[], $4::uuid[], $5::uuid[], $6::uuid[], $7::uuid[], $8::text[], $9::timestamptz[])
+		    AS e(name, source, user_id, session_id, city_id, poi_id, list_id, properties, occurred_at)`,
+		names, sources, userIDs, sessionIDs, cityIDs, poiIDs, listIDs, properties, occurredAt)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB insert failed")
+		return fmt.Errorf("database error inserting analytics events: %w", err)
+	}
+	span.SetStatus(codes.Ok, "Analytics events inserted")
+	return nil
+}
+
+// EnsurePartition implements Repository.
+func (r *RepositoryImpl) EnsurePartition(ctx context.Context, day time.Time) error {
+	ctx, span := otel.Tracer("AnalyticsRepo").Start(ctx, "EnsurePartition", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("month", day.Format("2006-01")),
+	))
+	defer span.End()
+
+	if _, err := r.pgpool.Exec(ctx, `SELECT create_analytics_events_partition($1::date)`, day); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Partition creation failed")
+		return fmt.Errorf("database error creating analytics events partition: %w", err)
+	}
+	return nil
+}
+
+// RollupDay implements Repository.
+func (r *RepositoryImpl) RollupDay(ctx context.Context, day time.Time) error {
+	ctx, span := otel.Tracer("AnalyticsRepo").Start(ctx, "RollupDay", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("day", day.Format(time.DateOnly)),
+	))
+	defer span.End()
+
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1)
+
+	err := pgx.BeginFunc(ctx, r.pgpool, func(tx pgx.Tx) error {
+		for _, table := range []string{"analytics_daily_city_stats", "analytics_daily_conversions", "analytics_daily_poi_stats", "analytics_daily_list_stats"} {
+			if _, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE day = $1::date`, start); err != nil {
+				return fmt.Errorf("clearing %s: %w", table, err)
+			}
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO analytics_daily_city_stats (day, city_id, searches, poi_views, chats_started, itineraries_saved, active_users)
+			SELECT $1::date, e.city_id,
+			       COUNT(*) FILTER (WHERE e.name = 'search_performed'),
+			       COUNT(*) FILTER (WHERE e.name = 'poi_viewed'),
+			       COUNT(*) FILTER (WHERE e.name = 'chat_started'),
+			       COUNT(*) FILTER (WHERE e.name = 'itinerary_saved'),
+			       COUNT(DISTINCT e.user_id)
+			FROM analytics_events e
+			JOIN cities c ON c.id = e.city_id
+			WHERE e.occurred_at >= $1 AND e.occurred_at < $2
+			GROUP BY e.city_id`, start, end); err != nil {
+			return fmt.Errorf("rolling up city stats: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO analytics_daily_conversions (day, chat_users, converted_users)
+			SELECT $1::date, COUNT(*),
+			       COUNT(*) FILTER (WHERE EXISTS (
+			           SELECT 1 FROM analytics_events s
+			           WHERE s.name = 'itinerary_saved' AND s.user_id = c.user_id
+			             AND s.occurred_at >= c.first_chat AND s.occurred_at < $2))
+			FROM (
+			    SELECT user_id, MIN(occurred_at) AS first_chat
+			    FROM analytics_events
+			    WHERE name = 'chat_started' AND user_id IS NOT NULL
+			      AND occurred_at >= $1 AND occurred_at < $2
+			    GROUP BY user_id
+			) c`, start, end); err != nil {
+			return fmt.Errorf("rolling up conversions: %w", err)
+		}
+
+		// Popularity feeds search ranking, so a user counts once per POI and
+		// day however often they reload it, and only saves the server saw
+		// happen count: clients can claim any save.
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO analytics_daily_poi_stats (day, poi_id, views, saves)
+			SELECT $1::date, e.poi_id,
+			       COUNT(DISTINCT COALESCE(e.user_id, e.session_id)) FILTER (WHERE e.name = 'poi_viewed'),
+			       COUNT(DISTINCT e.user_id) FILTER (WHERE e.name = 'poi_saved' AND e.source = 'server')
+			FROM analytics_events e
+			JOIN points_of_interest p ON p.id = e.poi_id
+			WHERE e.name IN ('poi_viewed', 'poi_saved')
+			  AND e.occurred_at >= $1 AND e.occurred_at < $2
+			GROUP BY e.poi_id`, start, end); err != nil {
+			return fmt.Errorf("rolling up POI stats: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO analytics_daily_list_stats (day, list_id, views, saves)
+			SELECT $1::date, e.list_id,
+			       COUNT(*) FILTER (WHERE e.name = 'list_viewed'),
+			       COUNT(*) FILTER (WHERE e.name = 'list_saved' AND e.source = 'server')
+			FROM analytics_events e
+			JOIN lists l ON l.id = e.list_id
+			WHERE e.name IN ('list_viewed', 'list_saved')
+			  AND e.occurred_at >= $1 AND e.occurred_at < $2
+			GROUP BY e.list_id`, start, end); err != nil {
+			return fmt.Errorf("rolling up list stats: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Rollup failed")
+		return fmt.Errorf("database error rolling up analytics for %s: %w", start.Format(time.DateOnly), err)
+	}
+	span.SetStatus(codes.Ok, "Day rolled up")
+	return nil
+}
+
+// RefreshPopularity implements Repository. A save counts as much as five views.
+func (r *RepositoryImpl) RefreshPopularity(ctx context.Context, since time.Time) error {
+	ctx, span := otel.Tracer("AnalyticsRepo").Start(ctx, "RefreshPopularity", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "poi_popularity"),
+	))
+	defer span.End()
+
+	err := pgx.BeginFunc(ctx, r.pgpool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO poi_popularity (poi_id, views, saves, score, updated_at)
+			SELECT poi_id, SUM(views), SUM(saves), SUM(views) + 5 * SUM(saves), NOW()
+			FROM analytics_daily_poi_stats
+			WHERE day >= $1::date
+			GROUP BY poi_id
+			ON CONFLICT (poi_id) DO UPDATE
+			SET views = EXCLUDED.views, saves = EXCLUDED.saves, score = EXCLUDED.score, updated_at = EXCLUDED.updated_at`,
+			since); err != nil {
+			return err
+		}
+		// POIs with no views or saves in the window
+		_, err := tx.Exec(ctx, `
+			DELETE FROM poi_popularity p
+			WHERE NOT EXISTS (SELECT 1 FROM analytics_daily_poi_stats s WHERE s.poi_id = p.poi_id AND s.day >= $1::date)`,
+			since)
+		return err
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Popularity refresh failed")
+		return fmt.Errorf("database error refreshing POI popularity: %w", err)
+	}
+	span.SetStatus(codes.Ok, "Popularity refreshed")
+	return nil
+}
+
+// RefreshListCounters implements Repository.
+func (r *RepositoryImpl) RefreshListCounters(ctx context.Context) error {
+	ctx, span := otel.Tracer("AnalyticsRepo").Start(ctx, "RefreshListCounters", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "lists"),
+	))
+	defer span.End()
+
+	tag, err := r.pgpool.Exec(ctx, `
+		UPDATE lists l
+		SET view_count = t.views
+		FROM (
+		    SELECT list_id, SUM(views)::int AS views
+		    FROM analytics_daily_list_stats
+		    GROUP BY list_id
+		) t
+		WHERE l.id = t.list_id AND l.view_count <> t.views`)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Counter refresh failed")
+		return fmt.Errorf("database error refreshing list counters: %w", err)
+	}
+	span.SetAttributes(attribute.Int64("lists.updated", tag.RowsAffected()))
+	span.SetStatus(codes.Ok, "List counters refreshed")
+	return nil
+}
+
+// TopCities implements Repository. Cities are ranked by all their activity.
+func (r *RepositoryImpl) TopCities(ctx context.Context, from, to time.Time, limit int) ([]types.CityAnalytics, error) {
+	ctx, span := otel.Tracer("AnalyticsRepo").Start(ctx, "TopCities", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "analytics_daily_city_stats"),
+	))
+	defer span.End()
+
+	rows, err := r.pgpool.Query(ctx, `
+		SELECT s.city_id, c.name, SUM(s.searches), SUM(s.poi_views), SUM(s.chats_started),
+		       SUM(s.itineraries_saved), SUM(s.active_users)
+		FROM analytics_daily_city_stats s
+		JOIN cities c ON c.id = s.city_id
+		WHERE s.day BETWEEN $1::date AND $2::date
+		GROUP BY s.city_id, c.name
+		ORDER BY SUM(s.searches + s.poi_views + s.chats_started + s.itineraries_saved) DESC, c.name
+		LIMIT $3`, from, to, limit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, fmt.Errorf("database error fetching top cities: %w", err)
+	}
+	defer rows.Close()
+
+	cities := []types.CityAnalytics{}
+	for rows.Next() {
+		var c types.CityAnalytics
+		if err := rows.Scan(&c.CityID, &c.CityName, &c.Searches, &c.POIViews, &c.ChatsStarted,
+			&c.ItinerariesSaved, &c.ActiveUserDays); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("database error scanning city analytics: %w", err)
+		}
+		cities = append(cities, c)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("database error iterating city analytics: %w", err)
+	}
+	return cities, nil
+}
+
+// Conversions implements Repository.
+func (r *RepositoryImpl) Conversions(ctx context.Context, from, to time.Time) ([]types.ConversionDay, error) {
+	ctx, span := otel.Tracer("AnalyticsRepo").Start(ctx, "Conversions", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "analytics_daily_conversions"),
+	))
+	defer span.End()
+
+	rows, err := r.pgpool.Query(ctx, `
+		SELECT day, chat_users, converted_users
+		FROM analytics_daily_conversions
+		WHERE day BETWEEN $1::date AND $2::date
+		ORDER BY day`, from, to)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, fmt.Errorf("database error fetching conversions: %w", err)
+	}
+	defer rows.Close()
+
+	days := []types.ConversionDay{}
+	for rows.Next() {
+		var day time.Time
+		var d types.ConversionDay
+		if err := rows.Scan(&day, &d.ChatUsers, &d.ConvertedUsers); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("database error scanning conversions: %w", err)
+		}
+		d.Day = day.Format(time.DateOnly)
+		days = append(days, d)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("database error iterating conversions: %w", err)
+	}
+	return days, nil
+}
+
+// PopularPOIs implements Repository.
+func (r *RepositoryImpl) PopularPOIs(ctx context.Context, cityID *uuid.UUID, limit int) ([]types.POIPopularity, error) {
+	ctx, span := otel.Tracer("AnalyticsRepo").Start(ctx, "PopularPOIs", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "poi_popularity"),
+	))
+	defer span.End()
+
+	rows, err := r.pgpool.Query(ctx, `
+		SELECT pp.poi_id, p.name, p.city_id, pp.views, pp.saves, pp.score
+		FROM poi_popularity pp
+		JOIN points_of_interest p ON p.id = pp.poi_id
+		WHERE $1::uuid IS NULL OR p.city_id = $1
+		ORDER BY pp.score DESC, p.name
+		LIMIT $2`, cityID, limit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, fmt.Errorf("database error fetching popular POIs: %w", err)
+	}
+	defer rows.Close()
+
+	pois := []types.POIPopularity{}
+	for rows.Next() {
+		var p types.POIPopularity
+		if err := rows.Scan(&p.POIID, &p.Name, &p.CityID, &p.Views, &p.Saves, &p.Score); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("database error scanning POI popularity: %w", err)
+		}
+		pois = append(pois, p)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("database error iterating POI popularity: %w", err)
+	}
+	return pois, nil
+}
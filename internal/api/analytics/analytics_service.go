@@ -0,0 +1,313 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/config"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/jobs"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Service = (*ServiceImpl)(nil)
+
+const (
+	defaultFlushInterval    = 5 * time.Second
+	defaultBufferSize       = 1024
+	defaultRollupInterval   = time.Hour
+	defaultPopularityWindow = 30 * 24 * time.Hour
+
+	// maxBatchSize is how many events a client may send at once.
+	maxBatchSize = 500
+	// maxEventAge is how late client events may arrive. The rollup only
+	// revisits yesterday and today, so older ones would never be counted.
+	maxEventAge = 24 * time.Hour
+	// maxPropertiesSize is the largest encoded properties of an event.
+	maxPropertiesSize = 4 << 10
+
+	defaultReportDays  = 30
+	defaultReportLimit = 10
+	maxReportLimit     = 100
+)
+
+// Emitter records server-side analytics events. Emitting never fails the
+// caller: events that cannot be recorded are dropped.
+type Emitter interface {
+	Emit(ctx context.Context, event types.AnalyticsEvent)
+}
+
+// NopEmitter discards events.
+type NopEmitter struct{}
+
+// Emit implements Emitter.
+func (NopEmitter) Emit(context.Context, types.AnalyticsEvent) {}
+
+// JobRegistry is the part of the job service the rollup hooks into.
+type JobRegistry interface {
+	Register(kind string, fn jobs.JobFunc)
+	Schedule(kind string, every time.Duration)
+}
+
+// Service ingests analytics events and reports on their aggregates.
+type Service interface {
+	Emitter
+	// Ingest stores a client's batch of events, dropping the invalid ones.
+	Ingest(ctx context.Context, userID *uuid.UUID, events []types.AnalyticsEvent) (*types.AnalyticsBatchResponse, error)
+	// Run writes emitted events in batches until ctx is cancelled.
+	Run(ctx context.Context)
+
+	TopCities(ctx context.Context, from, to time.Time, limit int) ([]types.CityAnalytics, error)
+	Conversion(ctx context.Context, from, to time.Time) ([]types.ConversionDay, error)
+	PopularPOIs(ctx context.Context, cityID *uuid.UUID, limit int) ([]types.POIPopularity, error)
+}
+
+type ServiceImpl struct {
+	logger *slog.Logger
+	repo   Repository
+	cfg    config.AnalyticsConfig
+	events chan types.AnalyticsEvent
+	now    func() time.Time
+}
+
+func NewService(repo Repository, cfg config.AnalyticsConfig, logger *slog.Logger) *ServiceImpl {
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultFlushInterval
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaultBufferSize
+	}
+	if cfg.RollupInterval <= 0 {
+		cfg.RollupInterval = defaultRollupInterval
+	}
+	if cfg.PopularityWindow <= 0 {
+		cfg.PopularityWindow = defaultPopularityWindow
+	}
+	return &ServiceImpl{
+		logger: logger,
+		repo:   repo,
+		cfg:    cfg,
+		events: make(chan types.AnalyticsEvent, cfg.BufferSize),
+		now:    time.Now,
+	}
+}
+
+// Emit implements Emitter. It only queues the event; Run writes it.
+func (s *ServiceImpl) Emit(ctx context.Context, event types.AnalyticsEvent) {
+	event.Source = types.AnalyticsSourceServer
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = s.now()
+	}
+	select {
+	case s.events <- event:
+	default:
+		s.logger.WarnContext(ctx, "Analytics buffer full, dropping event", slog.String("event", event.Name))
+	}
+}
+
+// Run implements Service. Queued events are written once more when ctx is
+// cancelled.
+func (s *ServiceImpl) Run(ctx context.Context) {
+	l := s.logger.With(slog.String("worker", "analytics"))
+	l.InfoContext(ctx, "Analytics worker started", slog.Duration("interval", s.cfg.FlushInterval))
+
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// The request context is gone; give the last write its own.
+			flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+			s.flush(flushCtx)
+			cancel()
+			l.Info("Analytics worker stopped")
+			return
+		case <-ticker.C:
+			s.flush(ctx)
+		}
+	}
+}
+
+// flush writes the queued events.
+func (s *ServiceImpl) flush(ctx context.Context) {
+	var batch []types.AnalyticsEvent
+	for {
+		select {
+		case event := <-s.events:
+			batch = append(batch, event)
+			if len(batch) < maxBatchSize {
+				continue
+			}
+		default:
+		}
+		if len(batch) == 0 {
+			return
+		}
+		if err := s.repo.InsertEvents(ctx, batch); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to write analytics events",
+				slog.Int("events", len(batch)), slog.Any("error", err))
+		}
+		if len(batch) < maxBatchSize {
+			return
+		}
+		batch = nil
+	}
+}
+
+// Ingest implements Service.
+func (s *ServiceImpl) Ingest(ctx context.Context, userID *uuid.UUID, events []types.AnalyticsEvent) (*types.AnalyticsBatchResponse, error) {
+	ctx, span := otel.Tracer("AnalyticsService").Start(ctx, "Ingest", trace.WithAttributes(
+		attribute.Int("events.count", len(events)),
+	))
+	defer span.End()
+
+	if len(events) == 0 {
+		return nil, fmt.Errorf("%w: no events", types.ErrBadRequest)
+	}
+	if len(events) > maxBatchSize {
+		return nil, fmt.Errorf("%w: at most %d events per batch", types.ErrBadRequest, maxBatchSize)
+	}
+
+	now := s.now()
+	accepted := make([]types.AnalyticsEvent, 0, len(events))
+	for _, event := range events {
+		if !s.valid(event, now) {
+			continue
+		}
+		event.Source = types.AnalyticsSourceClient
+		event.UserID = userID
+		// Clock skew: clients cannot record events in the future
+		if event.OccurredAt.IsZero() || event.OccurredAt.After(now) {
+			event.OccurredAt = now
+		}
+		accepted = append(accepted, event)
+	}
+
+	if err := s.repo.InsertEvents(ctx, accepted); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to store events")
+		return nil, err
+	}
+	resp := &types.AnalyticsBatchResponse{Accepted: len(accepted), Rejected: len(events) - len(accepted)}
+	span.SetAttributes(attribute.Int("events.rejected", resp.Rejected))
+	span.SetStatus(codes.Ok, "Events ingested")
+	return resp, nil
+}
+
+// valid reports whether a client event can be stored.
+func (s *ServiceImpl) valid(event types.AnalyticsEvent, now time.Time) bool {
+	if !types.IsAnalyticsEvent(event.Name) {
+		return false
+	}
+	if !event.OccurredAt.IsZero() && now.Sub(event.OccurredAt) > maxEventAge {
+		return false
+	}
+	if len(event.Properties) > 0 {
+		encoded, err := json.Marshal(event.Properties)
+		if err != nil || len(encoded) > maxPropertiesSize {
+			return false
+		}
+	}
+	return true
+}
+
+// Register installs the rollup job and schedules it every RollupInterval.
+func (s *ServiceImpl) Register(registry JobRegistry) {
+	registry.Register(types.JobKindAnalyticsRollup, s.RunRollup)
+	registry.Schedule(types.JobKindAnalyticsRollup, s.cfg.RollupInterval)
+}
+
+// RunRollup makes sure the events partitions of this and next month exist,
+// rolls up yesterday and today, whose events may still be arriving, and
+// refreshes POI popularity and the list view counters from the daily aggregates.
+func (s *ServiceImpl) RunRollup(ctx context.Context, job *types.Job, report jobs.ProgressFunc) error {
+	ctx, span := otel.Tracer("AnalyticsService").Start(ctx, "RunRollup")
+	defer span.End()
+
+	now := s.now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	steps := []func() error{
+		func() error { return s.repo.EnsurePartition(ctx, thisMonth) },
+		func() error { return s.repo.EnsurePartition(ctx, thisMonth.AddDate(0, 1, 0)) },
+		func() error { return s.repo.RollupDay(ctx, today.AddDate(0, 0, -1)) },
+		func() error { return s.repo.RollupDay(ctx, today) },
+		func() error { return s.repo.RefreshPopularity(ctx, now.Add(-s.cfg.PopularityWindow)) },
+		func() error { return s.repo.RefreshListCounters(ctx) },
+	}
+
+	progress := types.JobProgress{Total: len(steps)}
+	report(progress)
+	for _, step := range steps {
+		if err := step(); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Rollup failed")
+			return err
+		}
+		progress.Done++
+		report(progress)
+	}
+	span.SetStatus(codes.Ok, "Analytics rolled up")
+	return nil
+}
+
+// reportRange defaults the report range to the last defaultReportDays days.
+func (s *ServiceImpl) reportRange(from, to time.Time) (time.Time, time.Time, error) {
+	if to.IsZero() {
+		to = s.now().UTC()
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -(defaultReportDays - 1))
+	}
+	if from.After(to) {
+		return from, to, fmt.Errorf("%w: from is after to", types.ErrBadRequest)
+	}
+	return from, to, nil
+}
+
+func reportLimit(limit int) int {
+	if limit <= 0 {
+		return defaultReportLimit
+	}
+	return min(limit, maxReportLimit)
+}
+
+// TopCities implements Service.
+func (s *ServiceImpl) TopCities(ctx context.Context, from, to time.Time, limit int) ([]types.CityAnalytics, error) {
+	from, to, err := s.reportRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.TopCities(ctx, from, to, reportLimit(limit))
+}
+
+// Conversion implements Service.
+func (s *ServiceImpl) Conversion(ctx context.Context, from, to time.Time) ([]types.ConversionDay, error) {
+	from, to, err := s.reportRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	days, err := s.repo.Conversions(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for i := range days {
+		if days[i].ChatUsers > 0 {
+			days[i].Rate = float64(days[i].ConvertedUsers) / float64(days[i].ChatUsers)
+		}
+	}
+	return days, nil
+}
+
+// PopularPOIs implements Service.
+func (s *ServiceImpl) PopularPOIs(ctx context.Context, cityID *uuid.UUID, limit int) ([]types.POIPopularity, error) {
+	return s.repo.PopularPOIs(ctx, cityID, reportLimit(limit))
+}
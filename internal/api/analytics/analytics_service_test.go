@@ -0,0 +1,268 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/config"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+// MockAnalyticsRepository is a mock implementation of Repository
+type MockAnalyticsRepository struct {
+	mock.Mock
+}
+
+func (m *MockAnalyticsRepository) InsertEvents(ctx context.Context, events []types.AnalyticsEvent) error {
+	return m.Called(ctx, events).Error(0)
+}
+
+func (m *MockAnalyticsRepository) EnsurePartition(ctx context.Context, day time.Time) error {
+	return m.Called(ctx, day).Error(0)
+}
+
+func (m *MockAnalyticsRepository) RollupDay(ctx context.Context, day time.Time) error {
+	return m.Called(ctx, day).Error(0)
+}
+
+func (m *MockAnalyticsRepository) RefreshPopularity(ctx context.Context, since time.Time) error {
+	return m.Called(ctx, since).Error(0)
+}
+
+func (m *MockAnalyticsRepository) RefreshListCounters(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+func (m *MockAnalyticsRepository) TopCities(ctx context.Context, from, to time.Time, limit int) ([]types.CityAnalytics, error) {
+	args := m.Called(ctx, from, to, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.CityAnalytics), args.Error(1)
+}
+
+func (m *MockAnalyticsRepository) Conversions(ctx context.Context, from, to time.Time) ([]types.ConversionDay, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.ConversionDay), args.Error(1)
+}
+
+func (m *MockAnalyticsRepository) PopularPOIs(ctx context.Context, cityID *uuid.UUID, limit int) ([]types.POIPopularity, error) {
+	args := m.Called(ctx, cityID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.POIPopularity), args.Error(1)
+}
+
+var testNow = time.Date(2026, 7, 10, 15, 30, 0, 0, time.UTC)
+
+func setupAnalyticsServiceTest(cfg config.AnalyticsConfig) (*ServiceImpl, *MockAnalyticsRepository) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	repo := new(MockAnalyticsRepository)
+	service := NewService(repo, cfg, logger)
+	service.now = func() time.Time { return testNow }
+	return service, repo
+}
+
+func TestService_Ingest(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("drops invalid events and stamps the rest", func(t *testing.T) {
+		service, repo := setupAnalyticsServiceTest(config.AnalyticsConfig{})
+		var stored []types.AnalyticsEvent
+		repo.On("InsertEvents", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(1).([]types.AnalyticsEvent)
+		}).Return(nil)
+
+		events := []types.AnalyticsEvent{
+			{Name: types.AnalyticsPOIViewed, OccurredAt: testNow.Add(-time.Hour)},
+			{Name: types.AnalyticsSearchPerformed},
+			{Name: types.AnalyticsChatStarted, OccurredAt: testNow.Add(time.Hour)},
+			{Name: "page_scrolled"},
+			{Name: types.AnalyticsPOIViewed, OccurredAt: testNow.Add(-25 * time.Hour)},
+			{Name: types.AnalyticsListViewed, Properties: map[string]any{"blob": strings.Repeat("x", maxPropertiesSize)}},
+			// Clients cannot pose as the server or as another user
+			{Name: types.AnalyticsPOISaved, Source: types.AnalyticsSourceServer, UserID: &uuid.UUID{}},
+		}
+
+		resp, err := service.Ingest(ctx, &userID, events)
+
+		require.NoError(t, err)
+		assert.Equal(t, types.AnalyticsBatchResponse{Accepted: 4, Rejected: 3}, *resp)
+		require.Len(t, stored, 4)
+		for _, e := range stored {
+			assert.Equal(t, types.AnalyticsSourceClient, e.Source)
+			assert.Equal(t, &userID, e.UserID)
+		}
+		assert.Equal(t, testNow.Add(-time.Hour), stored[0].OccurredAt)
+		assert.Equal(t, testNow, stored[1].OccurredAt)
+		assert.Equal(t, testNow, stored[2].OccurredAt, "future events are clamped to now")
+	})
+
+	t.Run("rejects empty and oversized batches", func(t *testing.T) {
+		service, repo := setupAnalyticsServiceTest(config.AnalyticsConfig{})
+
+		_, err := service.Ingest(ctx, &userID, nil)
+		assert.ErrorIs(t, err, types.ErrBadRequest)
+		_, err = service.Ingest(ctx, &userID, make([]types.AnalyticsEvent, maxBatchSize+1))
+		assert.ErrorIs(t, err, types.ErrBadRequest)
+		repo.AssertNotCalled(t, "InsertEvents", mock.Anything, mock.Anything)
+	})
+
+	t.Run("repository errors fail the batch", func(t *testing.T) {
+		service, repo := setupAnalyticsServiceTest(config.AnalyticsConfig{})
+		repo.On("InsertEvents", mock.Anything, mock.Anything).Return(errors.New("db down"))
+
+		_, err := service.Ingest(ctx, &userID, []types.AnalyticsEvent{{Name: types.AnalyticsPOIViewed}})
+		assert.Error(t, err)
+	})
+}
+
+func TestService_Emit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("queues events until flushed", func(t *testing.T) {
+		service, repo := setupAnalyticsServiceTest(config.AnalyticsConfig{BufferSize: 2})
+		var stored []types.AnalyticsEvent
+		repo.On("InsertEvents", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			stored = append(stored, args.Get(1).([]types.AnalyticsEvent)...)
+		}).Return(nil)
+
+		service.Emit(ctx, types.AnalyticsEvent{Name: types.AnalyticsChatStarted})
+		service.Emit(ctx, types.AnalyticsEvent{Name: types.AnalyticsItinerarySaved})
+		// The buffer is full: dropped rather than blocking the caller
+		service.Emit(ctx, types.AnalyticsEvent{Name: types.AnalyticsPOIViewed})
+		repo.AssertNotCalled(t, "InsertEvents", mock.Anything, mock.Anything)
+
+		service.flush(ctx)
+
+		require.Len(t, stored, 2)
+		assert.Equal(t, types.AnalyticsChatStarted, stored[0].Name)
+		assert.Equal(t, types.AnalyticsSourceServer, stored[0].Source)
+		assert.Equal(t, testNow, stored[0].OccurredAt)
+		repo.AssertNumberOfCalls(t, "InsertEvents", 1)
+
+		service.flush(ctx)
+		repo.AssertNumberOfCalls(t, "InsertEvents", 1)
+	})
+
+	t.Run("writes large queues in batches", func(t *testing.T) {
+		service, repo := setupAnalyticsServiceTest(config.AnalyticsConfig{BufferSize: maxBatchSize + 10})
+		repo.On("InsertEvents", mock.Anything, mock.MatchedBy(func(e []types.AnalyticsEvent) bool { return len(e) == maxBatchSize })).Return(nil).Once()
+		repo.On("InsertEvents", mock.Anything, mock.MatchedBy(func(e []types.AnalyticsEvent) bool { return len(e) == 10 })).Return(nil).Once()
+
+		for range maxBatchSize + 10 {
+			service.Emit(ctx, types.AnalyticsEvent{Name: types.AnalyticsPOIViewed})
+		}
+		service.flush(ctx)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("flushes when stopped", func(t *testing.T) {
+		service, repo := setupAnalyticsServiceTest(config.AnalyticsConfig{FlushInterval: time.Hour})
+		repo.On("InsertEvents", mock.Anything, mock.Anything).Return(nil)
+		service.Emit(ctx, types.AnalyticsEvent{Name: types.AnalyticsPOIViewed})
+
+		runCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		service.Run(runCtx)
+
+		repo.AssertNumberOfCalls(t, "InsertEvents", 1)
+	})
+}
+
+func TestService_RunRollup(t *testing.T) {
+	ctx := context.Background()
+	today := time.Date(2026, 7, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("rolls up yesterday and today", func(t *testing.T) {
+		service, repo := setupAnalyticsServiceTest(config.AnalyticsConfig{PopularityWindow: 7 * 24 * time.Hour})
+		repo.On("EnsurePartition", mock.Anything, time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)).Return(nil).Once()
+		repo.On("EnsurePartition", mock.Anything, time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC)).Return(nil).Once()
+		repo.On("RollupDay", mock.Anything, today.AddDate(0, 0, -1)).Return(nil).Once()
+		repo.On("RollupDay", mock.Anything, today).Return(nil).Once()
+		repo.On("RefreshPopularity", mock.Anything, testNow.AddDate(0, 0, -7)).Return(nil).Once()
+		repo.On("RefreshListCounters", mock.Anything).Return(nil).Once()
+
+		var last types.JobProgress
+		err := service.RunRollup(ctx, &types.Job{Kind: types.JobKindAnalyticsRollup}, func(p types.JobProgress) { last = p })
+
+		require.NoError(t, err)
+		assert.Equal(t, types.JobProgress{Total: 6, Done: 6}, last)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		service, repo := setupAnalyticsServiceTest(config.AnalyticsConfig{})
+		repo.On("EnsurePartition", mock.Anything, mock.Anything).Return(nil)
+		repo.On("RollupDay", mock.Anything, mock.Anything).Return(errors.New("db down"))
+
+		err := service.RunRollup(ctx, &types.Job{}, func(types.JobProgress) {})
+
+		assert.Error(t, err)
+		repo.AssertNumberOfCalls(t, "RollupDay", 1)
+		repo.AssertNotCalled(t, "RefreshPopularity", mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_Reports(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("conversion rates", func(t *testing.T) {
+		service, repo := setupAnalyticsServiceTest(config.AnalyticsConfig{})
+		from := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2026, 7, 2, 0, 0, 0, 0, time.UTC)
+		repo.On("Conversions", mock.Anything, from, to).Return([]types.ConversionDay{
+			{Day: "2026-07-01", ChatUsers: 4, ConvertedUsers: 1},
+			{Day: "2026-07-02"},
+		}, nil)
+
+		days, err := service.Conversion(ctx, from, to)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0.25, days[0].Rate)
+		assert.Zero(t, days[1].Rate)
+	})
+
+	t.Run("ranges default to the last 30 days", func(t *testing.T) {
+		service, repo := setupAnalyticsServiceTest(config.AnalyticsConfig{})
+		repo.On("TopCities", mock.Anything, testNow.AddDate(0, 0, -29), testNow, defaultReportLimit).Return([]types.CityAnalytics{}, nil)
+
+		_, err := service.TopCities(ctx, time.Time{}, time.Time{}, 0)
+
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("rejects inverted ranges", func(t *testing.T) {
+		service, _ := setupAnalyticsServiceTest(config.AnalyticsConfig{})
+
+		_, err := service.TopCities(ctx, testNow, testNow.AddDate(0, 0, -1), 10)
+		assert.ErrorIs(t, err, types.ErrBadRequest)
+	})
+
+	t.Run("caps the limit", func(t *testing.T) {
+		service, repo := setupAnalyticsServiceTest(config.AnalyticsConfig{})
+		repo.On("PopularPOIs", mock.Anything, (*uuid.UUID)(nil), maxReportLimit).Return([]types.POIPopularity{}, nil)
+
+		_, err := service.PopularPOIs(ctx, nil, 1000)
+
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,130 @@
+package audit
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Handler = (*HandlerImpl)(nil)
+
+type Handler interface {
+	ListAuditLog(w http.ResponseWriter, r *http.Request)
+}
+
+type HandlerImpl struct {
+	logger  *slog.Logger
+	service Service
+}
+
+func NewHandler(service Service, logger *slog.Logger) *HandlerImpl {
+	return &HandlerImpl{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// ListAuditLog godoc
+// @Summary      Query Audit Log
+// @Description  Lists audit log entries, newest first. Admin only.
+// @Tags         Admin
+// @Produce      json
+// @Param        actor_id query string false "Filter by the user who performed the action"
+// @Param        target_type query string false "Filter by target type (user, poi, list)"
+// @Param        target_id query string false "Filter by target ID"
+// @Param        action query string false "Exact action, or a prefix ending in '.' (e.g. admin.)"
+// @Param        from query string false "Only entries at or after this time (RFC3339)"
+// @Param        to query string false "Only entries before this time (RFC3339)"
+// @Param        cursor query string false "Cursor of the page to fetch, from next_cursor or the Link header; omit for the first page"
+// @Param        limit query int false "Items per page (default 50, max 200)"
+// @Success      200 {object} types.PaginatedAuditLogResponse
+// @Failure      400 {object} types.Response "Invalid filter or cursor"
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      403 {object} types.Response "Forbidden"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/audit-log [get]
+func (h *HandlerImpl) ListAuditLog(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("AuditHandler").Start(r.Context(), "ListAuditLog")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "ListAuditLog"))
+
+	page, err := api.ParsePageRequest(r)
+	if err != nil {
+		span.SetStatus(codes.Error, "Invalid cursor")
+		api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	q := r.URL.Query()
+	filter := types.AuditLogFilter{
+		TargetType: q.Get("target_type"),
+		Action:     q.Get("action"),
+		Page:       page,
+	}
+
+	parseUUID := func(name string) (*uuid.UUID, bool) {
+		v := q.Get(name)
+		if v == "" {
+			return nil, true
+		}
+		id, err := uuid.Parse(v)
+		if err != nil {
+			span.SetStatus(codes.Error, "Invalid "+name)
+			api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid "+name+" format")
+			return nil, false
+		}
+		return &id, true
+	}
+	parseTime := func(name string) (*time.Time, bool) {
+		v := q.Get(name)
+		if v == "" {
+			return nil, true
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			span.SetStatus(codes.Error, "Invalid "+name)
+			api.ErrorResponse(w, r, http.StatusBadRequest, name+" must be an RFC3339 timestamp")
+			return nil, false
+		}
+		return &t, true
+	}
+
+	var ok bool
+	if filter.ActorID, ok = parseUUID("actor_id"); !ok {
+		return
+	}
+	if filter.TargetID, ok = parseUUID("target_id"); !ok {
+		return
+	}
+	if filter.From, ok = parseTime("from"); !ok {
+		return
+	}
+	if filter.To, ok = parseTime("to"); !ok {
+		return
+	}
+
+	resp, err := h.service.Query(ctx, filter)
+	if err != nil {
+		l.ErrorContext(ctx, "Service failed to query audit log", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to query audit log")
+		if errors.Is(err, types.ErrBadRequest) {
+			api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		api.ErrorResponse(w, r, http.StatusInternalServerError, "Failed to query audit log")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Audit log queried")
+	api.SetPageLinks(w, r, resp.NextCursor)
+	api.WriteJSONResponse(w, r, http.StatusOK, resp)
+}
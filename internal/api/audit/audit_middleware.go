@@ -0,0 +1,48 @@
+package audit
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+type contextKey string
+
+const (
+	clientIPKey  contextKey = "auditClientIP"
+	userAgentKey contextKey = "auditUserAgent"
+)
+
+// RequestMetadata stores the client IP and user agent on the request context so
+// audit entries can record them. Mount it after chi's RealIP middleware so the
+// IP reflects X-Forwarded-For / X-Real-IP.
+func RequestMetadata(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr // RealIP sets RemoteAddr without a port
+		}
+		if ip := net.ParseIP(host); ip != nil {
+			ctx = context.WithValue(ctx, clientIPKey, ip.String())
+		}
+		if ua := r.UserAgent(); ua != "" {
+			ctx = context.WithValue(ctx, userAgentKey, ua)
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ClientIPFromContext returns the client IP stored by RequestMetadata, or "".
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey).(string)
+	return ip
+}
+
+// UserAgentFromContext returns the user agent stored by RequestMetadata, or "".
+func UserAgentFromContext(ctx context.Context) string {
+	ua, _ := ctx.Value(userAgentKey).(string)
+	return ua
+}
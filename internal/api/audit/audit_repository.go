@@ -0,0 +1,158 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Repository = (*RepositoryImpl)(nil)
+
+type Repository interface {
+	// Insert appends an entry to the audit log.
+	Insert(ctx context.Context, entry types.AuditLogEntry) error
+	// Query returns a page of entries matching the filter, newest first, the
+	// cursor of the next page and the total match count.
+	Query(ctx context.Context, filter types.AuditLogFilter) ([]types.AuditLogEntry, *types.Cursor, int, error)
+}
+
+type RepositoryImpl struct {
+	logger *slog.Logger
+	pgpool *pgxpool.Pool
+}
+
+func NewRepository(pgxpool *pgxpool.Pool, logger *slog.Logger) *RepositoryImpl {
+	return &RepositoryImpl{
+		logger: logger,
+		pgpool: pgxpool,
+	}
+}
+
+// Insert implements Repository.
+func (r *RepositoryImpl) Insert(ctx context.Context, entry types.AuditLogEntry) error {
+	ctx, span := otel.Tracer("AuditRepository").Start(ctx, "Insert", trace.WithAttributes(
+		attribute.String("audit.action", entry.Action),
+	))
+	defer span.End()
+
+	query := `
+		INSERT INTO audit_log (actor_id, action, target_type, target_id, before, after, metadata, ip_address, user_agent, request_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8::inet, $9, $10)`
+	_, err := r.pgpool.Exec(ctx, query,
+		entry.ActorID, entry.Action, entry.TargetType, entry.TargetID,
+		entry.Before, entry.After, entry.Metadata, entry.IPAddress, entry.UserAgent, entry.RequestID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Insert failed")
+		return fmt.Errorf("database error inserting audit entry: %w", err)
+	}
+	return nil
+}
+
+// Query implements Repository.
+func (r *RepositoryImpl) Query(ctx context.Context, filter types.AuditLogFilter) ([]types.AuditLogEntry, *types.Cursor, int, error) {
+	ctx, span := otel.Tracer("AuditRepository").Start(ctx, "Query", trace.WithAttributes(
+		attribute.String("filter.action", filter.Action),
+		attribute.String("filter.target_type", filter.TargetType),
+		attribute.Int("limit", filter.Page.Limit),
+	))
+	defer span.End()
+
+	var conditions []string
+	var args []interface{}
+	addCondition := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.ActorID != nil {
+		addCondition("actor_id = $%d", *filter.ActorID)
+	}
+	if filter.TargetType != "" {
+		addCondition("target_type = $%d", filter.TargetType)
+	}
+	if filter.TargetID != nil {
+		addCondition("target_id = $%d", *filter.TargetID)
+	}
+	if filter.Action != "" {
+		if strings.HasSuffix(filter.Action, ".") {
+			addCondition("starts_with(action, $%d)", filter.Action)
+		} else {
+			addCondition("action = $%d", filter.Action)
+		}
+	}
+	if filter.From != nil {
+		addCondition("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("created_at < $%d", *filter.To)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	// The total counts every match, not only those after the cursor
+	after := ""
+	if c := filter.Page.Cursor; c != nil {
+		args = append(args, c.Time, c.ID)
+		after = fmt.Sprintf("WHERE (created_at, id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+	args = append(args, filter.Page.FetchLimit())
+	query := fmt.Sprintf(`
+		WITH matched AS (
+			SELECT id, actor_id, action, target_type, target_id, before, after, metadata,
+			       host(ip_address) AS ip_address, user_agent, request_id, created_at
+			FROM audit_log
+			%s
+		)
+		SELECT id, actor_id, action, target_type, target_id, before, after, metadata,
+		       ip_address, user_agent, request_id, created_at,
+		       (SELECT COUNT(*) FROM matched) AS total_records
+		FROM matched
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d`, where, after, len(args))
+
+	rows, err := r.pgpool.Query(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Query failed")
+		return nil, nil, 0, fmt.Errorf("database error querying audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]types.AuditLogEntry, 0, filter.Page.FetchLimit())
+	total := 0
+	for rows.Next() {
+		var e types.AuditLogEntry
+		if err := rows.Scan(&e.ID, &e.ActorID, &e.Action, &e.TargetType, &e.TargetID,
+			&e.Before, &e.After, &e.Metadata, &e.IPAddress, &e.UserAgent, &e.RequestID, &e.CreatedAt,
+			&total); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Scan failed")
+			return nil, nil, 0, fmt.Errorf("database error scanning audit entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Row iteration failed")
+		return nil, nil, 0, fmt.Errorf("database error iterating audit entries: %w", err)
+	}
+
+	entries, next := types.NextPage(entries, filter.Page.Limit, func(e types.AuditLogEntry) types.Cursor {
+		return types.Cursor{Time: &e.CreatedAt, ID: e.ID}
+	})
+	span.SetStatus(codes.Ok, "Audit log queried")
+	return entries, next, total, nil
+}
@@ -0,0 +1,198 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Service = (*ServiceImpl)(nil)
+
+// Recorder writes audit entries. Other services depend on this rather than on Service.
+type Recorder interface {
+	// Record appends an entry to the audit log. It never fails the caller:
+	// write errors are logged so the audited action itself is not rolled back.
+	Record(ctx context.Context, entry types.AuditEntry)
+}
+
+// Service defines the business logic for the audit log.
+type Service interface {
+	Recorder
+	Query(ctx context.Context, filter types.AuditLogFilter) (*types.PaginatedAuditLogResponse, error)
+}
+
+// NopRecorder discards every entry. Used where no audit log is wired (e.g. tests).
+type NopRecorder struct{}
+
+func (NopRecorder) Record(context.Context, types.AuditEntry) {}
+
+type ServiceImpl struct {
+	logger *slog.Logger
+	repo   Repository
+}
+
+// NewService creates a new audit service.
+func NewService(repo Repository, logger *slog.Logger) *ServiceImpl {
+	return &ServiceImpl{
+		logger: logger,
+		repo:   repo,
+	}
+}
+
+// Record implements Recorder. The client IP and user agent come from RequestMetadata,
+// the request ID from chi's RequestID middleware.
+func (s *ServiceImpl) Record(ctx context.Context, entry types.AuditEntry) {
+	l := s.logger.With(slog.String("method", "Record"), slog.String("action", entry.Action))
+
+	before, after, err := Diff(entry.Before, entry.After)
+	if err != nil {
+		// Still record that the action happened, just without the diff
+		l.WarnContext(ctx, "Failed to compute audit diff", slog.Any("error", err))
+	}
+
+	row := types.AuditLogEntry{
+		ActorID:    entry.ActorID,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Before:     before,
+		After:      after,
+		Metadata:   entry.Metadata,
+	}
+	if ip := ClientIPFromContext(ctx); ip != "" {
+		row.IPAddress = &ip
+	}
+	if ua := UserAgentFromContext(ctx); ua != "" {
+		row.UserAgent = &ua
+	}
+	if reqID := middleware.GetReqID(ctx); reqID != "" {
+		row.RequestID = &reqID
+	}
+
+	// The action has already happened; record it even if the client has gone away.
+	if err := s.repo.Insert(context.WithoutCancel(ctx), row); err != nil {
+		l.ErrorContext(ctx, "Failed to write audit entry", slog.Any("error", err))
+		return
+	}
+	l.DebugContext(ctx, "Audit entry recorded")
+}
+
+// Query returns a page of audit log entries matching the filter.
+func (s *ServiceImpl) Query(ctx context.Context, filter types.AuditLogFilter) (*types.PaginatedAuditLogResponse, error) {
+	ctx, span := otel.Tracer("AuditService").Start(ctx, "Query", trace.WithAttributes(
+		attribute.String("filter.action", filter.Action),
+	))
+	defer span.End()
+
+	filter.Page = filter.Page.Clamp(50, 200) // Default and max page size
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, fmt.Errorf("from must be before to: %w", types.ErrBadRequest)
+	}
+
+	entries, next, total, err := s.repo.Query(ctx, filter)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to query audit log", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to query audit log")
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Audit log queried")
+	return &types.PaginatedAuditLogResponse{
+		Entries:      entries,
+		Limit:        filter.Page.Limit,
+		NextCursor:   next,
+		TotalRecords: total,
+	}, nil
+}
+
+// ignoredDiffFields change on every write and would only add noise to the diff.
+var ignoredDiffFields = map[string]struct{}{
+	"updated_at": {},
+}
+
+// Diff compares the JSON representations of before and after and returns only the
+// fields that changed. After is treated as a partial update, so fields it omits or
+// sets to null are ignored. If before is nil all of after is returned (creation);
+// if after is nil all of before is returned (deletion).
+func Diff(before, after interface{}) (map[string]interface{}, map[string]interface{}, error) {
+	b, err := toJSONMap(before)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encoding before: %w", err)
+	}
+	a, err := toJSONMap(after)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encoding after: %w", err)
+	}
+
+	switch {
+	case b == nil && a == nil:
+		return nil, nil, nil
+	case b == nil:
+		return nil, nonEmpty(filterFields(a)), nil
+	case a == nil:
+		return nonEmpty(filterFields(b)), nil, nil
+	}
+
+	changedBefore := make(map[string]interface{})
+	changedAfter := make(map[string]interface{})
+	for k, av := range filterFields(a) {
+		if bv, ok := b[k]; ok && reflect.DeepEqual(bv, av) {
+			continue
+		}
+		changedBefore[k] = b[k]
+		changedAfter[k] = av
+	}
+	return nonEmpty(changedBefore), nonEmpty(changedAfter), nil
+}
+
+// toJSONMap round-trips v through JSON. Non-object values are wrapped as {"value": v}.
+func toJSONMap(v interface{}) (map[string]interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, err
+	}
+	if m, ok := decoded.(map[string]interface{}); ok {
+		return m, nil
+	}
+	return map[string]interface{}{"value": decoded}, nil
+}
+
+// filterFields drops null values and ignored bookkeeping fields.
+func filterFields(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if _, skip := ignoredDiffFields[k]; skip || v == nil {
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+func nonEmpty(m map[string]interface{}) map[string]interface{} {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
@@ -0,0 +1,168 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+// MockAuditRepository is a mock implementation of Repository
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Insert(ctx context.Context, entry types.AuditLogEntry) error {
+	return m.Called(ctx, entry).Error(0)
+}
+
+func (m *MockAuditRepository) Query(ctx context.Context, filter types.AuditLogFilter) ([]types.AuditLogEntry, *types.Cursor, int, error) {
+	args := m.Called(ctx, filter)
+	next, _ := args.Get(1).(*types.Cursor)
+	if args.Get(0) == nil {
+		return nil, next, args.Int(2), args.Error(3)
+	}
+	return args.Get(0).([]types.AuditLogEntry), next, args.Int(2), args.Error(3)
+}
+
+// Helper to setup service with mock repository
+func setupAuditServiceTest() (*ServiceImpl, *MockAuditRepository) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	mockRepo := new(MockAuditRepository)
+	return NewService(mockRepo, logger), mockRepo
+}
+
+func TestDiff(t *testing.T) {
+	name := "New name"
+	type profile struct {
+		Name      string    `json:"name"`
+		Email     string    `json:"email"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+	type update struct {
+		Name  *string `json:"name,omitempty"`
+		Email *string `json:"email"`
+	}
+
+	t.Run("partial update keeps only changed fields", func(t *testing.T) {
+		before := profile{Name: "Old name", Email: "a@example.com", UpdatedAt: time.Now()}
+
+		b, a, err := Diff(before, update{Name: &name})
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"name": "Old name"}, b)
+		assert.Equal(t, map[string]interface{}{"name": "New name"}, a)
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		b, a, err := Diff(profile{Name: name}, update{Name: &name})
+
+		require.NoError(t, err)
+		assert.Nil(t, b)
+		assert.Nil(t, a)
+	})
+
+	t.Run("deletion keeps before without bookkeeping fields", func(t *testing.T) {
+		b, a, err := Diff(profile{Name: name, Email: "a@example.com", UpdatedAt: time.Now()}, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"name": name, "email": "a@example.com"}, b)
+		assert.Nil(t, a)
+	})
+
+	t.Run("nil pointer is treated as absent", func(t *testing.T) {
+		var before *profile
+		b, a, err := Diff(before, map[string]string{"role": "admin"})
+
+		require.NoError(t, err)
+		assert.Nil(t, b)
+		assert.Equal(t, map[string]interface{}{"role": "admin"}, a)
+	})
+}
+
+func TestServiceImpl_Record(t *testing.T) {
+	service, mockRepo := setupAuditServiceTest()
+	actorID := uuid.New()
+
+	var recorded types.AuditLogEntry
+	mockRepo.On("Insert", mock.Anything, mock.AnythingOfType("types.AuditLogEntry")).
+		Run(func(args mock.Arguments) { recorded = args.Get(1).(types.AuditLogEntry) }).
+		Return(nil).Once()
+
+	// Run Record inside the middleware chain so IP, user agent and request ID are populated
+	handler := middleware.RequestID(RequestMetadata(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		service.Record(r.Context(), types.AuditEntry{
+			ActorID:    &actorID,
+			Action:     types.AuditActionRoleChanged,
+			TargetType: types.AuditTargetUser,
+			TargetID:   &actorID,
+			Before:     map[string]string{"role": "user"},
+			After:      map[string]string{"role": "admin"},
+		})
+	})))
+	req := httptest.NewRequest(http.MethodPut, "/admin/users/x/role", nil)
+	req.RemoteAddr = "203.0.113.7:51234"
+	req.Header.Set("User-Agent", "audit-test")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	mockRepo.AssertExpectations(t)
+	assert.Equal(t, types.AuditActionRoleChanged, recorded.Action)
+	assert.Equal(t, map[string]interface{}{"role": "user"}, recorded.Before)
+	assert.Equal(t, map[string]interface{}{"role": "admin"}, recorded.After)
+	require.NotNil(t, recorded.IPAddress)
+	assert.Equal(t, "203.0.113.7", *recorded.IPAddress)
+	require.NotNil(t, recorded.UserAgent)
+	assert.Equal(t, "audit-test", *recorded.UserAgent)
+	require.NotNil(t, recorded.RequestID)
+	assert.NotEmpty(t, *recorded.RequestID)
+}
+
+func TestServiceImpl_RecordSwallowsErrors(t *testing.T) {
+	service, mockRepo := setupAuditServiceTest()
+	mockRepo.On("Insert", mock.Anything, mock.Anything).Return(errors.New("db down")).Once()
+
+	assert.NotPanics(t, func() {
+		service.Record(context.Background(), types.AuditEntry{Action: types.AuditActionPasswordChanged, TargetType: types.AuditTargetUser})
+	})
+	mockRepo.AssertExpectations(t)
+}
+
+func TestServiceImpl_Query(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("defaults pagination", func(t *testing.T) {
+		service, mockRepo := setupAuditServiceTest()
+		mockRepo.On("Query", mock.Anything, types.AuditLogFilter{Action: "admin.", Page: types.PageRequest{Limit: 50}}).
+			Return([]types.AuditLogEntry{}, (*types.Cursor)(nil), 0, nil).Once()
+
+		resp, err := service.Query(ctx, types.AuditLogFilter{Action: "admin."})
+
+		require.NoError(t, err)
+		assert.Equal(t, 50, resp.Limit)
+		assert.Nil(t, resp.NextCursor)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects inverted time range", func(t *testing.T) {
+		service, mockRepo := setupAuditServiceTest()
+		from := time.Now()
+		to := from.Add(-time.Hour)
+
+		_, err := service.Query(ctx, types.AuditLogFilter{From: &from, To: &to})
+
+		assert.True(t, errors.Is(err, types.ErrBadRequest))
+		mockRepo.AssertNotCalled(t, "Query", mock.Anything, mock.Anything)
+	})
+}
@@ -27,6 +27,7 @@ type Handler interface {
 	Register(w http.ResponseWriter, r *http.Request)
 	ValidateSession(w http.ResponseWriter, r *http.Request)
 	ChangePassword(w http.ResponseWriter, r *http.Request)
+	JWKS(w http.ResponseWriter, r *http.Request)
 
 	// provider
 	LoginWithGoogle(w http.ResponseWriter, r *http.Request)
@@ -431,6 +432,20 @@ func (h *HandlerImpl) ChangeEmail(w http.ResponseWriter, r *http.Request) {
 	h.respondWithError(w, http.StatusNotImplemented, "Email change not implemented")
 }
 
+// JWKS godoc
+// @Summary      JSON Web Key Set
+// @Description  Publishes the public keys used to sign access tokens so other services can verify them without the signing secret.
+// @Tags         Auth
+// @Produce      json
+// @Success      200 {object} types.JWKS "Public verification keys"
+// @Router       /.well-known/jwks.json [get]
+func (h *HandlerImpl) JWKS(w http.ResponseWriter, r *http.Request) {
+	// Verifiers may cache the set briefly; rotation keeps the old key published
+	// for at least one access-token TTL, so a short max-age is enough.
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	api.WriteJSONResponse(w, r, http.StatusOK, h.authService.JWKS())
+}
+
 // Helper functions for response handling
 
 func (h *HandlerImpl) respondWithError(w http.ResponseWriter, code int, message string) {
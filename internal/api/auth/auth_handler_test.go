@@ -77,6 +77,11 @@ func (m *MockAuthService) GenerateTokens(ctx context.Context, user *types.UserAu
 	return args.String(0), args.String(0), args.Error(1)
 }
 
+func (m *MockAuthService) JWKS() types.JWKS {
+	args := m.Called()
+	return args.Get(0).(types.JWKS)
+}
+
 func (m *MockAuthService) GetOrCreateUserFromProvider(ctx context.Context, provider string, providerUser goth.User) (*types.UserAuth, error) {
 	args := m.Called(ctx, provider, providerUser)
 	if args.Get(0) == nil {
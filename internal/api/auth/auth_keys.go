@@ -0,0 +1,228 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/config"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+// legacyKeyID identifies the shared HMAC secret. Tokens signed with it before
+// key rotation existed carry no `kid` header, so it is also the fallback key.
+const legacyKeyID = "hs256"
+
+var ErrUnknownKeyID = errors.New("unknown signing key id")
+
+// signingKey holds one entry of the key set.
+type signingKey struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   interface{} // nil for verify-only (retired) keys
+	verifyKey interface{}
+}
+
+// KeySet holds every key that may verify an access token plus the single
+// active key that signs new ones. Only asymmetric keys are published in JWKS.
+type KeySet struct {
+	activeID string
+	keys     map[string]*signingKey
+}
+
+// NewKeySet builds a key set from the JWT configuration. The HMAC secret, if
+// present, is kept as a verification key so tokens issued before the switch to
+// asymmetric signing stay valid until they expire.
+func NewKeySet(cfg config.JWTConfig) (*KeySet, error) {
+	if cfg.SecretKey == "" && len(cfg.Keys) == 0 {
+		return nil, errors.New("no jwt secret or keys configured")
+	}
+	ks := &KeySet{keys: make(map[string]*signingKey)}
+
+	if cfg.SecretKey != "" {
+		secret := []byte(cfg.SecretKey)
+		ks.keys[legacyKeyID] = &signingKey{id: legacyKeyID, method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}
+	}
+
+	for _, kc := range cfg.Keys {
+		key, err := loadSigningKey(kc)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := ks.keys[key.id]; exists {
+			return nil, fmt.Errorf("duplicate jwt key id %q", key.id)
+		}
+		ks.keys[key.id] = key
+	}
+
+	switch {
+	case cfg.ActiveKeyID != "":
+		ks.activeID = cfg.ActiveKeyID
+	case len(cfg.Keys) == 0 && cfg.SecretKey != "":
+		ks.activeID = legacyKeyID
+	default:
+		return nil, errors.New("jwt activeKeyID is required when asymmetric keys are configured")
+	}
+
+	active, ok := ks.keys[ks.activeID]
+	if !ok {
+		return nil, fmt.Errorf("%w: active key %q is not configured", ErrUnknownKeyID, ks.activeID)
+	}
+	if active.signKey == nil {
+		return nil, fmt.Errorf("active jwt key %q has no private key", ks.activeID)
+	}
+	return ks, nil
+}
+
+// NewHMACKeySet returns a key set that signs and verifies with a shared secret only.
+func NewHMACKeySet(secret string) (*KeySet, error) {
+	return NewKeySet(config.JWTConfig{SecretKey: secret})
+}
+
+func loadSigningKey(kc config.JWTKeyConfig) (*signingKey, error) {
+	if kc.ID == "" {
+		return nil, errors.New("jwt key is missing kid")
+	}
+	if kc.PrivateKeyFile == "" && kc.PublicKeyFile == "" {
+		return nil, fmt.Errorf("jwt key %q needs a privateKeyFile or publicKeyFile", kc.ID)
+	}
+
+	key := &signingKey{id: kc.ID}
+	var privPEM, pubPEM []byte
+	var err error
+	if kc.PrivateKeyFile != "" {
+		if privPEM, err = os.ReadFile(kc.PrivateKeyFile); err != nil {
+			return nil, fmt.Errorf("reading private key for %q: %w", kc.ID, err)
+		}
+	}
+	if kc.PublicKeyFile != "" {
+		if pubPEM, err = os.ReadFile(kc.PublicKeyFile); err != nil {
+			return nil, fmt.Errorf("reading public key for %q: %w", kc.ID, err)
+		}
+	}
+
+	switch kc.Algorithm {
+	case jwt.SigningMethodRS256.Alg():
+		key.method = jwt.SigningMethodRS256
+		if privPEM != nil {
+			priv, err := jwt.ParseRSAPrivateKeyFromPEM(privPEM)
+			if err != nil {
+				return nil, fmt.Errorf("parsing RSA private key for %q: %w", kc.ID, err)
+			}
+			key.signKey = priv
+			key.verifyKey = &priv.PublicKey
+		}
+		if pubPEM != nil {
+			pub, err := jwt.ParseRSAPublicKeyFromPEM(pubPEM)
+			if err != nil {
+				return nil, fmt.Errorf("parsing RSA public key for %q: %w", kc.ID, err)
+			}
+			key.verifyKey = pub
+		}
+	case jwt.SigningMethodEdDSA.Alg():
+		key.method = jwt.SigningMethodEdDSA
+		if privPEM != nil {
+			priv, err := jwt.ParseEdPrivateKeyFromPEM(privPEM)
+			if err != nil {
+				return nil, fmt.Errorf("parsing Ed25519 private key for %q: %w", kc.ID, err)
+			}
+			key.signKey = priv
+			key.verifyKey = priv.(crypto.Signer).Public()
+		}
+		if pubPEM != nil {
+			pub, err := jwt.ParseEdPublicKeyFromPEM(pubPEM)
+			if err != nil {
+				return nil, fmt.Errorf("parsing Ed25519 public key for %q: %w", kc.ID, err)
+			}
+			key.verifyKey = pub
+		}
+	default:
+		return nil, fmt.Errorf("jwt key %q has unsupported alg %q (want RS256 or EdDSA)", kc.ID, kc.Algorithm)
+	}
+	return key, nil
+}
+
+// ActiveKeyID returns the kid used for newly signed tokens.
+func (ks *KeySet) ActiveKeyID() string {
+	return ks.activeID
+}
+
+// Sign signs the claims with the active key and sets the `kid` header.
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	key := ks.keys[ks.activeID]
+	token := jwt.NewWithClaims(key.method, claims)
+	if key.id != legacyKeyID {
+		token.Header["kid"] = key.id
+	}
+	return token.SignedString(key.signKey)
+}
+
+// Keyfunc resolves the verification key for a parsed token. The token's `alg`
+// must match the algorithm the key was configured with, which rules out
+// algorithm-confusion attacks (e.g. an HS256 token "signed" with an RSA public key).
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		kid = legacyKeyID
+	}
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKeyID, kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %v for key %q", token.Header["alg"], kid)
+	}
+	return key.verifyKey, nil
+}
+
+// ValidMethods lists the algorithms of every configured key, for jwt.WithValidMethods.
+func (ks *KeySet) ValidMethods() []string {
+	seen := make(map[string]struct{})
+	methods := make([]string, 0, len(ks.keys))
+	for _, key := range ks.keys {
+		if _, ok := seen[key.method.Alg()]; ok {
+			continue
+		}
+		seen[key.method.Alg()] = struct{}{}
+		methods = append(methods, key.method.Alg())
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// JWKS returns the public half of every asymmetric key. The HMAC secret is never published.
+func (ks *KeySet) JWKS() types.JWKS {
+	ids := make([]string, 0, len(ks.keys))
+	for id := range ks.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	set := types.JWKS{Keys: make([]types.JWK, 0, len(ids))}
+	for _, id := range ids {
+		key := ks.keys[id]
+		jwk := types.JWK{Kid: key.id, Use: "sig", Alg: key.method.Alg()}
+		switch pub := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/config"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+// writeTestKeys writes an RSA and an Ed25519 key pair as PEM files and returns their paths.
+func writeTestKeys(t *testing.T) (rsaPriv, rsaPub, edPriv, edPub string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	write := func(name, blockType string, der []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+		return path
+	}
+
+	rk, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaPriv = write("rsa", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rk))
+	pubDER, err := x509.MarshalPKIXPublicKey(&rk.PublicKey)
+	require.NoError(t, err)
+	rsaPub = write("rsa.pub", "PUBLIC KEY", pubDER)
+
+	ep, ek, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	privDER, err := x509.MarshalPKCS8PrivateKey(ek)
+	require.NoError(t, err)
+	edPriv = write("ed", "PRIVATE KEY", privDER)
+	pubDER, err = x509.MarshalPKIXPublicKey(ep)
+	require.NoError(t, err)
+	edPub = write("ed.pub", "PUBLIC KEY", pubDER)
+	return
+}
+
+func testClaims() *types.Claims {
+	return &types.Claims{
+		UserID: "user123",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+}
+
+func parseWith(ks *KeySet, token string) error {
+	_, err := jwt.ParseWithClaims(token, &types.Claims{}, ks.Keyfunc, jwt.WithValidMethods(ks.ValidMethods()))
+	return err
+}
+
+func TestKeySet(t *testing.T) {
+	rsaPriv, rsaPub, edPriv, edPub := writeTestKeys(t)
+
+	t.Run("SignsWithKidAndVerifies", func(t *testing.T) {
+		ks, err := NewKeySet(config.JWTConfig{
+			ActiveKeyID: "rsa-1",
+			Keys:        []config.JWTKeyConfig{{ID: "rsa-1", Algorithm: "RS256", PrivateKeyFile: rsaPriv}},
+		})
+		require.NoError(t, err)
+
+		token, err := ks.Sign(testClaims())
+		require.NoError(t, err)
+
+		parsed, _, err := jwt.NewParser().ParseUnverified(token, &types.Claims{})
+		require.NoError(t, err)
+		assert.Equal(t, "rsa-1", parsed.Header["kid"])
+		assert.NoError(t, parseWith(ks, token))
+	})
+
+	t.Run("RotationKeepsRetiredKeyForVerification", func(t *testing.T) {
+		before, err := NewKeySet(config.JWTConfig{
+			ActiveKeyID: "rsa-1",
+			Keys:        []config.JWTKeyConfig{{ID: "rsa-1", Algorithm: "RS256", PrivateKeyFile: rsaPriv}},
+		})
+		require.NoError(t, err)
+		oldToken, err := before.Sign(testClaims())
+		require.NoError(t, err)
+
+		after, err := NewKeySet(config.JWTConfig{
+			ActiveKeyID: "ed-2",
+			Keys: []config.JWTKeyConfig{
+				{ID: "rsa-1", Algorithm: "RS256", PublicKeyFile: rsaPub},
+				{ID: "ed-2", Algorithm: "EdDSA", PrivateKeyFile: edPriv, PublicKeyFile: edPub},
+			},
+		})
+		require.NoError(t, err)
+		newToken, err := after.Sign(testClaims())
+		require.NoError(t, err)
+
+		assert.NoError(t, parseWith(after, oldToken))
+		assert.NoError(t, parseWith(after, newToken))
+		assert.Error(t, parseWith(before, newToken))
+	})
+
+	t.Run("RejectsAlgorithmConfusion", func(t *testing.T) {
+		ks, err := NewKeySet(config.JWTConfig{
+			ActiveKeyID: "rsa-1",
+			Keys:        []config.JWTKeyConfig{{ID: "rsa-1", Algorithm: "RS256", PublicKeyFile: rsaPub, PrivateKeyFile: rsaPriv}},
+		})
+		require.NoError(t, err)
+
+		pubPEM, err := os.ReadFile(rsaPub)
+		require.NoError(t, err)
+		forged := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+		forged.Header["kid"] = "rsa-1"
+		token, err := forged.SignedString(pubPEM)
+		require.NoError(t, err)
+
+		assert.Error(t, parseWith(ks, token))
+	})
+
+	t.Run("LegacyHMACTokensWithoutKid", func(t *testing.T) {
+		ks, err := NewKeySet(config.JWTConfig{
+			SecretKey:   "test-secret",
+			ActiveKeyID: "rsa-1",
+			Keys:        []config.JWTKeyConfig{{ID: "rsa-1", Algorithm: "RS256", PrivateKeyFile: rsaPriv}},
+		})
+		require.NoError(t, err)
+
+		legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims()).SignedString([]byte("test-secret"))
+		require.NoError(t, err)
+		assert.NoError(t, parseWith(ks, legacy))
+	})
+
+	t.Run("JWKSPublishesOnlyAsymmetricKeys", func(t *testing.T) {
+		ks, err := NewKeySet(config.JWTConfig{
+			SecretKey:   "test-secret",
+			ActiveKeyID: "ed-2",
+			Keys: []config.JWTKeyConfig{
+				{ID: "rsa-1", Algorithm: "RS256", PublicKeyFile: rsaPub},
+				{ID: "ed-2", Algorithm: "EdDSA", PrivateKeyFile: edPriv},
+			},
+		})
+		require.NoError(t, err)
+
+		set := ks.JWKS()
+		require.Len(t, set.Keys, 2)
+		assert.Equal(t, "OKP", set.Keys[0].Kty)
+		assert.Equal(t, "Ed25519", set.Keys[0].Crv)
+		assert.NotEmpty(t, set.Keys[0].X)
+		assert.Equal(t, "RSA", set.Keys[1].Kty)
+		assert.Equal(t, "AQAB", set.Keys[1].E)
+	})
+
+	t.Run("ActiveKeyNeedsPrivateKey", func(t *testing.T) {
+		_, err := NewKeySet(config.JWTConfig{
+			ActiveKeyID: "rsa-1",
+			Keys:        []config.JWTKeyConfig{{ID: "rsa-1", Algorithm: "RS256", PublicKeyFile: rsaPub}},
+		})
+		assert.Error(t, err)
+	})
+}
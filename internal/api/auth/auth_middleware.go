@@ -25,13 +25,14 @@ const UserPlanKey contextKey = "userPlan"
 const UserSubStatusKey contextKey = "userSubStatus"
 
 // Authenticate is middleware to validate JWT access tokens.
-// It expects the JWT secret key to be passed in.
-func Authenticate(logger *slog.Logger, jwtCfg config.JWTConfig) func(next http.Handler) http.Handler {
-	secretKey := []byte(jwtCfg.SecretKey)
-	if len(secretKey) == 0 {
-		logger.Error("FATAL: JWT Secret Key is not configured!")
-		panic("JWT Secret Key cannot be empty")
+// Tokens are verified against the key named by their `kid` header, so any key
+// still present in the key set (including retired ones) is accepted.
+func Authenticate(logger *slog.Logger, jwtCfg config.JWTConfig, keys *KeySet) func(next http.Handler) http.Handler {
+	if keys == nil {
+		logger.Error("FATAL: JWT key set is not configured!")
+		panic("JWT key set cannot be nil")
 	}
+	validMethods := keys.ValidMethods()
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -67,12 +68,7 @@ func Authenticate(logger *slog.Logger, jwtCfg config.JWTConfig) func(next http.H
 			tokenString := headerParts[1]
 
 			claims := &types.Claims{}
-			token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-				}
-				return secretKey, nil
-			})
+			token, err := jwt.ParseWithClaims(tokenString, claims, keys.Keyfunc, jwt.WithValidMethods(validMethods))
 
 			if err != nil {
 				l.WarnContext(ctx, "Token parsing/validation failed", slog.Any("error", err))
@@ -83,6 +79,8 @@ func Authenticate(logger *slog.Logger, jwtCfg config.JWTConfig) func(next http.H
 					errMsg = "Malformed token"
 				} else if errors.Is(err, jwt.ErrSignatureInvalid) {
 					errMsg = "Invalid token signature"
+				} else if errors.Is(err, ErrUnknownKeyID) {
+					errMsg = "Unknown token signing key"
 				}
 				api.ErrorResponse(w, r, http.StatusUnauthorized, errMsg)
 				return
@@ -113,6 +111,9 @@ func Authenticate(logger *slog.Logger, jwtCfg config.JWTConfig) func(next http.H
 			}
 
 			ctx = context.WithValue(ctx, UserIDKey, claims.UserID)
+			ctx = context.WithValue(ctx, UserRoleKey, claims.Role)
+			ctx = context.WithValue(ctx, UserPlanKey, claims.SubscriptionPlan)
+			ctx = context.WithValue(ctx, UserSubStatusKey, claims.SubscriptionStatus)
 			l.DebugContext(ctx, "Authentication successful, claims added to context", slog.String("userID", claims.UserID))
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
@@ -140,14 +141,19 @@ func GetUserSubStatusFromContext(ctx context.Context) (string, bool) {
 	return status, ok
 }
 
-// RequirePlanStatus checks if the user in the context has the required plan(s) and status.
+// RequirePlanStatus checks if the user in the context has one of the allowed plans
+// and one of the allowed statuses (e.g. "active" and "trialing").
 // Runs AFTER the Authenticate middleware.
-func RequirePlanStatus(logger *slog.Logger, allowedPlans []string, requiredStatus string) func(next http.Handler) http.Handler {
+func RequirePlanStatus(logger *slog.Logger, allowedPlans []string, allowedStatuses ...string) func(next http.Handler) http.Handler {
 	// Convert allowedPlans to a map for faster lookup
 	planMap := make(map[string]struct{}, len(allowedPlans))
 	for _, p := range allowedPlans {
 		planMap[p] = struct{}{}
 	}
+	statusMap := make(map[string]struct{}, len(allowedStatuses))
+	for _, st := range allowedStatuses {
+		statusMap[st] = struct{}{}
+	}
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -164,9 +170,9 @@ func RequirePlanStatus(logger *slog.Logger, allowedPlans []string, requiredStatu
 			}
 
 			// Check status first
-			if status != requiredStatus {
-				logger.WarnContext(ctx, "Subscription status check failed", slog.String("required_status", requiredStatus), slog.String("actual_status", status))
-				api.ErrorResponse(w, r, http.StatusForbidden, fmt.Sprintf("Subscription status must be '%s'", requiredStatus))
+			if _, allowed := statusMap[status]; !allowed {
+				logger.WarnContext(ctx, "Subscription status check failed", slog.Any("allowed_statuses", allowedStatuses), slog.String("actual_status", status))
+				api.ErrorResponse(w, r, http.StatusForbidden, fmt.Sprintf("Subscription status '%s' does not allow access", status))
 				return
 			}
 
@@ -183,7 +189,33 @@ func RequirePlanStatus(logger *slog.Logger, allowedPlans []string, requiredStatu
 	}
 }
 
-// Add UserIDKey, UserRoleKey etc. used by Authenticate middleware
-// Assume Authenticate middleware adds these values like:
-// ctx = context.WithValue(ctx, UserPlanKey, claims.Plan)
-// ctx = context.WithValue(ctx, UserSubStatusKey, claims.Status)
+// RequireRole checks that the user in the context has one of the allowed roles.
+// The role comes from the `rol` access-token claim, so a role change takes
+// effect once the user's current access token is refreshed.
+// Runs AFTER the Authenticate middleware.
+func RequireRole(logger *slog.Logger, allowedRoles ...string) func(next http.Handler) http.Handler {
+	roleMap := make(map[string]struct{}, len(allowedRoles))
+	for _, role := range allowedRoles {
+		roleMap[role] = struct{}{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			role, ok := GetUserRoleFromContext(ctx)
+			if !ok || role == "" {
+				logger.WarnContext(ctx, "Role claim missing from context")
+				api.ErrorResponse(w, r, http.StatusForbidden, "Insufficient permissions")
+				return
+			}
+
+			if _, allowed := roleMap[role]; !allowed {
+				logger.WarnContext(ctx, "Role check failed", slog.Any("allowed_roles", allowedRoles), slog.String("actual_role", role))
+				api.ErrorResponse(w, r, http.StatusForbidden, "Insufficient permissions")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -66,8 +66,8 @@ func NewPostgresAuthRepo(pgxpool *pgxpool.Pool, logger *slog.Logger) *PostgresAu
 // GetUserByEmail implements auth.AuthRepo.
 func (r *PostgresAuthRepo) GetUserByEmail(ctx context.Context, email string) (*types.UserAuth, error) {
 	var user types.UserAuth
-	query := `SELECT id, username, email, password_hash FROM users WHERE email = $1 AND is_active = TRUE`
-	err := r.pgpool.QueryRow(ctx, query, email).Scan(&user.ID, &user.Username, &user.Email, &user.Password)
+	query := `SELECT id, username, email, password_hash, role FROM users WHERE email = $1 AND is_active = TRUE`
+	err := r.pgpool.QueryRow(ctx, query, email).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Role)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, fmt.Errorf("user with email %s not found: %w", email, types.ErrNotFound) // Use a domain error
@@ -82,8 +82,8 @@ func (r *PostgresAuthRepo) GetUserByEmail(ctx context.Context, email string) (*t
 func (r *PostgresAuthRepo) GetUserByID(ctx context.Context, userID string) (*types.UserAuth, error) {
 	var user types.UserAuth
 	// Select fields needed by token generation or other logic
-	query := `SELECT id, username, email FROM users WHERE id = $1 AND is_active = TRUE`
-	err := r.pgpool.QueryRow(ctx, query, userID).Scan(&user.ID, &user.Username, &user.Email)
+	query := `SELECT id, username, email, role FROM users WHERE id = $1 AND is_active = TRUE`
+	err := r.pgpool.QueryRow(ctx, query, userID).Scan(&user.ID, &user.Username, &user.Email, &user.Role)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, fmt.Errorf("user with ID %s not found: %w", userID, types.ErrNotFound) // Use a domain error
@@ -3,11 +3,13 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
 
 	"github.com/FACorreiaa/go-poi-au-suggestions/config"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/audit"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -35,6 +37,7 @@ type AuthService interface {
 	VerifyPassword(ctx context.Context, userID, password string) error
 	GenerateTokens(ctx context.Context, user *types.UserAuth, sub *types.Subscription) (accessToken string, refreshToken string, err error)
 	GetOrCreateUserFromProvider(ctx context.Context, provider string, providerUser goth.User) (*types.UserAuth, error)
+	JWKS() types.JWKS
 }
 
 // AuthServiceImpl provides the implementation for AuthService.
@@ -42,12 +45,28 @@ type AuthServiceImpl struct {
 	logger *slog.Logger
 	repo   AuthRepo // Use the interface
 	cfg    *config.Config
+	keys   *KeySet
+	audit  audit.Recorder
+	// subs supplies the plan claims. When nil every user is treated as free/active.
+	subs types.SubscriptionRepository
 }
 
-// NewAuthService creates a new authentication service instance.
+// NewAuthService creates a new authentication service instance that signs
+// tokens with the configured HMAC secret, does not write an audit log and
+// issues free/active plan claims.
 func NewAuthService(repo AuthRepo, cfg *config.Config, logger *slog.Logger) *AuthServiceImpl {
-	// ... (nil checks and validation as before) ...
-	return &AuthServiceImpl{logger: logger, repo: repo, cfg: cfg}
+	keys, err := NewHMACKeySet(cfg.JWT.SecretKey)
+	if err != nil {
+		logger.Error("Failed to build HMAC key set", slog.Any("error", err))
+	}
+	return NewAuthServiceWithKeys(repo, keys, nil, audit.NopRecorder{}, cfg, logger)
+}
+
+// NewAuthServiceWithKeys creates an authentication service that signs tokens
+// with the active key of the given key set, reads plan claims from subs and
+// records security events to auditor.
+func NewAuthServiceWithKeys(repo AuthRepo, keys *KeySet, subs types.SubscriptionRepository, auditor audit.Recorder, cfg *config.Config, logger *slog.Logger) *AuthServiceImpl {
+	return &AuthServiceImpl{logger: logger, repo: repo, cfg: cfg, keys: keys, audit: auditor, subs: subs}
 }
 
 // Login validates credentials, generates tokens, stores refresh token.
@@ -70,10 +89,7 @@ func (s *AuthServiceImpl) Login(ctx context.Context, email, password string) (st
 		return "", "", fmt.Errorf("invalid credentials: %w", types.ErrUnauthenticated)
 	}
 
-	// --- Add types.Subscription Fetching Here Later ---
-	// sub, err := s.subsRepo.GetCurrenttypes.SubscriptionByUserID(ctx, user.ID) ...
-	// For now, create dummy/default sub info for token generation
-	sub := &types.Subscription{Plan: "free", Status: "active"} // Placeholder
+	sub := s.currentSubscription(ctx, user.ID)
 
 	// 3. Generate Tokens
 	accessToken, refreshToken, err := s.GenerateTokens(ctx, user, sub) // Pass user and sub
@@ -128,6 +144,14 @@ func (s *AuthServiceImpl) Register(ctx context.Context, username, email, passwor
 		return fmt.Errorf("registration failed: %w", err)
 	}
 
+	// Every account starts on the free plan
+	if s.subs != nil {
+		if err := s.subs.CreateDefaultSubscription(ctx, userID); err != nil {
+			// Not fatal: the free plan is also the fallback when no subscription exists
+			l.WarnContext(ctx, "Failed to create default subscription", slog.String("userID", userID), slog.Any("error", err))
+		}
+	}
+
 	l.InfoContext(ctx, "Registration successful", slog.String("userID", userID))
 	span.SetStatus(codes.Ok, "User registered")
 	return nil
@@ -154,8 +178,8 @@ func (s *AuthServiceImpl) RefreshSession(ctx context.Context, refreshToken strin
 		return "", "", fmt.Errorf("internal error retrieving user during refresh")
 	}
 
-	// --- Fetch types.Subscription Here Later ---
-	sub := &types.Subscription{Plan: "free", Status: "active"} // Placeholder
+	// Plan claims are re-read so upgrades and expiries show up on refresh
+	sub := s.currentSubscription(ctx, user.ID)
 
 	// 3. Generate NEW tokens
 	newAccessToken, newRefreshToken, err := s.GenerateTokens(ctx, user, sub)
@@ -232,6 +256,15 @@ func (s *AuthServiceImpl) UpdatePassword(ctx context.Context, userID, oldPasswor
 		l.WarnContext(ctx, "Failed to invalidate refresh tokens after password update", slog.Any("error", err))
 	}
 
+	if uid, err := uuid.Parse(userID); err == nil {
+		s.audit.Record(ctx, types.AuditEntry{
+			ActorID:    &uid,
+			Action:     types.AuditActionPasswordChanged,
+			TargetType: types.AuditTargetUser,
+			TargetID:   &uid,
+		})
+	}
+
 	l.InfoContext(ctx, "Password updated successfully")
 	return nil
 }
@@ -261,15 +294,64 @@ func (s *AuthServiceImpl) GetUserByID(ctx context.Context, userID string) (*type
 	return user, nil
 }
 
-// --- Internal Helper: generateTokens ---
+// currentSubscription returns the user's subscription for token claims,
+// falling back to free/active when it cannot be loaded.
+func (s *AuthServiceImpl) currentSubscription(ctx context.Context, userID string) *types.Subscription {
+	fallback := &types.Subscription{Plan: types.PlanFree, Status: types.SubscriptionActive}
+	if s.subs == nil {
+		return fallback
+	}
+	sub, err := s.subs.GetCurrentSubscriptionByUserID(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, types.ErrNotFound) {
+			s.logger.WarnContext(ctx, "Failed to load subscription, using free plan claims", slog.String("userID", userID), slog.Any("error", err))
+		}
+		return fallback
+	}
+	return sub
+}
+
+// IssueAccessToken signs a fresh access token for the user without touching
+// their refresh tokens. Used to hand out updated plan claims after a plan change.
+func (s *AuthServiceImpl) IssueAccessToken(ctx context.Context, userID string) (string, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch user: %w", err)
+	}
+	return s.signAccessToken(ctx, user, s.currentSubscription(ctx, user.ID))
+}
+
+// GenerateTokens issues an access token and a new refresh token. When sub is
+// nil the user's current subscription is looked up for the plan claims.
 func (s *AuthServiceImpl) GenerateTokens(ctx context.Context, user *types.UserAuth, sub *types.Subscription) (accessToken string, refreshToken string, err error) {
 	l := s.logger.With(slog.String("method", "generateTokens"), slog.String("userID", user.ID))
 
-	// --- Access Token ---
+	if sub == nil {
+		sub = s.currentSubscription(ctx, user.ID)
+	}
+	accessToken, err = s.signAccessToken(ctx, user, sub)
+	if err != nil {
+		return "", "", err
+	}
+
+	// --- Refresh Token ---
+	refreshToken = uuid.NewString() // Simple UUID, stored in DB
+
+	l.DebugContext(ctx, "Tokens generated successfully")
+	return accessToken, refreshToken, nil
+}
+
+// signAccessToken builds and signs the access token claims.
+func (s *AuthServiceImpl) signAccessToken(ctx context.Context, user *types.UserAuth, sub *types.Subscription) (string, error) {
+	l := s.logger.With(slog.String("method", "signAccessToken"), slog.String("userID", user.ID))
+
 	accessTTL := s.getAccessTTL()
 	issuer := s.getIssuer()
 	audience := s.getAudience()
-	secretKeyBytes := []byte(s.getSecretKey())
+	if s.keys == nil {
+		l.ErrorContext(ctx, "No JWT signing keys configured")
+		return "", fmt.Errorf("no signing keys configured")
+	}
 
 	accessClaims := &types.Claims{ // Use your Claims struct
 		RegisteredClaims: jwt.RegisteredClaims{
@@ -280,24 +362,19 @@ func (s *AuthServiceImpl) GenerateTokens(ctx context.Context, user *types.UserAu
 			Audience:  jwt.ClaimStrings{audience},
 		},
 		// Custom Claims
-		UserID:   user.ID,
-		Username: user.Username,
-		Email:    user.Email,
-		//types.SubscriptionPlan:   sub.Plan,   // Add from sub
-		//types.SubscriptionStatus: sub.Status, // Add from sub
-	}
-	accessTokenJWT := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessToken, err = accessTokenJWT.SignedString(secretKeyBytes)
+		UserID:             user.ID,
+		Username:           user.Username,
+		Email:              user.Email,
+		Role:               user.Role,
+		SubscriptionPlan:   sub.Plan,
+		SubscriptionStatus: sub.Status,
+	}
+	accessToken, err := s.keys.Sign(accessClaims)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to sign access token", slog.Any("error", err))
-		return "", "", fmt.Errorf("failed to sign access token: %w", err)
+		return "", fmt.Errorf("failed to sign access token: %w", err)
 	}
-
-	// --- Refresh Token ---
-	refreshToken = uuid.NewString() // Simple UUID, stored in DB
-
-	l.DebugContext(ctx, "Tokens generated successfully")
-	return accessToken, refreshToken, nil
+	return accessToken, nil
 }
 
 func (s *AuthServiceImpl) VerifyPassword(ctx context.Context, userID, password string) error {
@@ -331,9 +408,13 @@ func (s *AuthServiceImpl) getAudience() string {
 	}
 	return "Loci-app" // Default
 }
-func (s *AuthServiceImpl) getSecretKey() string {
-	// Already checked for empty in NewAuthService
-	return s.cfg.JWT.SecretKey
+
+// JWKS returns the public verification keys for /.well-known/jwks.json.
+func (s *AuthServiceImpl) JWKS() types.JWKS {
+	if s.keys == nil {
+		return types.JWKS{Keys: []types.JWK{}}
+	}
+	return s.keys.JWKS()
 }
 
 func (s *AuthServiceImpl) ValidateRefreshToken(ctx context.Context, refreshToken string) (string, error) {
@@ -345,19 +426,6 @@ func (s *AuthServiceImpl) ValidateRefreshToken(ctx context.Context, refreshToken
 	return userID, nil
 }
 
-// implement much later
-
-// Implement a dummy for now if needed for compilation
-type dummySubsRepo struct{}
-
-func (d *dummySubsRepo) GetCurrentSubscriptionByUserID(ctx context.Context, userID string) (*types.Subscription, error) {
-	return &types.Subscription{Plan: "free", Status: "active"}, nil // Always return free/active
-}
-func (d *dummySubsRepo) CreateDefaultSubscription(ctx context.Context, userID string) error {
-	return nil // Do nothing
-}
-func NewDummySubsRepo() types.SubscriptionRepository { return &dummySubsRepo{} }
-
 // provider
 func (s *AuthServiceImpl) GetOrCreateUserFromProvider(ctx context.Context, provider string, providerUser goth.User) (*types.UserAuth, error) {
 	// Check if the user exists based on provider and provider_user_id
@@ -390,5 +458,11 @@ func (s *AuthServiceImpl) GetOrCreateUserFromProvider(ctx context.Context, provi
 		return nil, err
 	}
 
+	if s.subs != nil {
+		if err := s.subs.CreateDefaultSubscription(ctx, newUser.ID); err != nil {
+			s.logger.WarnContext(ctx, "Failed to create default subscription", slog.String("userID", newUser.ID), slog.Any("error", err))
+		}
+	}
+
 	return newUser, nil
 }
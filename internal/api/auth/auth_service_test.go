@@ -7,11 +7,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/FACorreiaa/go-poi-au-suggestions/config"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/audit"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
 )
 
@@ -798,3 +800,70 @@ func TestValidateRefreshToken(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+// MockSubscriptionRepo is a mock implementation of types.SubscriptionRepository
+type MockSubscriptionRepo struct {
+	mock.Mock
+}
+
+func (m *MockSubscriptionRepo) GetCurrentSubscriptionByUserID(ctx context.Context, userID string) (*types.Subscription, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepo) CreateDefaultSubscription(ctx context.Context, userID string) error {
+	return m.Called(ctx, userID).Error(0)
+}
+
+func TestGenerateTokens_PlanClaims(t *testing.T) {
+	logger := slog.Default()
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:      "test-access-secret",
+			AccessTokenTTL: 15 * time.Minute,
+			Issuer:         "test-issuer",
+			Audience:       "test-audience",
+		},
+	}
+	keys, err := NewHMACKeySet(cfg.JWT.SecretKey)
+	assert.NoError(t, err)
+	user := &types.UserAuth{ID: "user-123", Email: "test@example.com", Role: "user"}
+
+	parse := func(t *testing.T, token string) *types.Claims {
+		claims := &types.Claims{}
+		_, err := jwt.ParseWithClaims(token, claims, keys.Keyfunc)
+		assert.NoError(t, err)
+		return claims
+	}
+
+	t.Run("uses the current subscription", func(t *testing.T) {
+		mockSubs := new(MockSubscriptionRepo)
+		service := NewAuthServiceWithKeys(new(MockAuthRepo), keys, mockSubs, audit.NopRecorder{}, cfg, logger)
+		mockSubs.On("GetCurrentSubscriptionByUserID", mock.Anything, user.ID).
+			Return(&types.Subscription{Plan: types.PlanPremiumAnnual, Status: types.SubscriptionTrialing}, nil).Once()
+
+		accessToken, _, err := service.GenerateTokens(context.Background(), user, nil)
+
+		assert.NoError(t, err)
+		claims := parse(t, accessToken)
+		assert.Equal(t, types.PlanPremiumAnnual, claims.SubscriptionPlan)
+		assert.Equal(t, types.SubscriptionTrialing, claims.SubscriptionStatus)
+		mockSubs.AssertExpectations(t)
+	})
+
+	t.Run("falls back to free when the lookup fails", func(t *testing.T) {
+		mockSubs := new(MockSubscriptionRepo)
+		service := NewAuthServiceWithKeys(new(MockAuthRepo), keys, mockSubs, audit.NopRecorder{}, cfg, logger)
+		mockSubs.On("GetCurrentSubscriptionByUserID", mock.Anything, user.ID).Return(nil, errors.New("db down")).Once()
+
+		accessToken, _, err := service.GenerateTokens(context.Background(), user, nil)
+
+		assert.NoError(t, err)
+		claims := parse(t, accessToken)
+		assert.Equal(t, types.PlanFree, claims.SubscriptionPlan)
+		assert.Equal(t, types.SubscriptionActive, claims.SubscriptionStatus)
+	})
+}
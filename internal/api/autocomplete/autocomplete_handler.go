@@ -0,0 +1,109 @@
+package autocomplete
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/auth"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Handler = (*HandlerImpl)(nil)
+
+type Handler interface {
+	Autocomplete(w http.ResponseWriter, r *http.Request)
+}
+
+type HandlerImpl struct {
+	logger  *slog.Logger
+	service Service
+}
+
+func NewHandler(service Service, logger *slog.Logger) *HandlerImpl {
+	return &HandlerImpl{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// Autocomplete godoc
+// @Summary      Autocomplete Search
+// @Description  Suggests cities, POIs near the user, interests, tags and the user's recent queries for a partial query, best match first. Matches prefixes and, for typos, similar spellings.
+// @Tags         Search
+// @Produce      json
+// @Param        q query string true "Partial query"
+// @Param        lat query number false "User latitude, to suggest nearby POIs"
+// @Param        lon query number false "User longitude, to suggest nearby POIs"
+// @Param        kinds query string false "Comma-separated kinds to suggest (city, poi, interest, tag, recent); all by default"
+// @Param        limit query int false "Maximum suggestions (default 8, max 20)"
+// @Success      200 {object} types.AutocompleteResponse
+// @Failure      400 {object} types.Response "Missing query, unknown kind or invalid location"
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /autocomplete [get]
+func (h *HandlerImpl) Autocomplete(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("AutocompleteHandler").Start(r.Context(), "Autocomplete")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "Autocomplete"))
+
+	userIDStr, ok := auth.GetUserIDFromContext(ctx)
+	if !ok || userIDStr == "" {
+		span.SetStatus(codes.Error, "Unauthorized")
+		api.ErrorResponse(w, r, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		l.ErrorContext(ctx, "Invalid user ID format in context", slog.String("userIDStr", userIDStr), slog.Any("error", err))
+		span.SetStatus(codes.Error, "Invalid user ID")
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid user ID format")
+		return
+	}
+
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	req := types.AutocompleteRequest{
+		Query:  q.Get("q"),
+		UserID: userID,
+		Limit:  limit,
+	}
+	if kinds := q.Get("kinds"); kinds != "" {
+		for _, kind := range strings.Split(kinds, ",") {
+			if kind = strings.TrimSpace(kind); kind != "" {
+				req.Kinds = append(req.Kinds, kind)
+			}
+		}
+	}
+	if latStr, lonStr := q.Get("lat"), q.Get("lon"); latStr != "" || lonStr != "" {
+		lat, latErr := strconv.ParseFloat(latStr, 64)
+		lon, lonErr := strconv.ParseFloat(lonStr, 64)
+		if latErr != nil || lonErr != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+			span.SetStatus(codes.Error, "Invalid location")
+			api.ErrorResponse(w, r, http.StatusBadRequest, "lat and lon must both be valid coordinates")
+			return
+		}
+		req.Location = &types.GeoPoint{Latitude: lat, Longitude: lon}
+	}
+	span.SetAttributes(attribute.String("query", req.Query), attribute.Bool("location", req.Location != nil))
+
+	resp, err := h.service.Suggest(ctx, req)
+	if err != nil {
+		l.ErrorContext(ctx, "Service failed to suggest", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to suggest")
+		api.WriteServiceError(w, r, err, "Failed to load suggestions")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Suggestions returned")
+	api.WriteJSONResponse(w, r, http.StatusOK, resp)
+}
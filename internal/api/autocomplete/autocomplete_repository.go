@@ -0,0 +1,211 @@
+package autocomplete
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Repository = (*RepositoryImpl)(nil)
+
+const (
+	// nearbyRadiusMeters bounds POI suggestions when the user's location is known.
+	nearbyRadiusMeters = 25000
+	// maxRecentPromptLength keeps generated prompts out of the recent queries;
+	// only what a user could have typed is suggested back.
+	maxRecentPromptLength = 120
+)
+
+// Repository loads autocomplete candidates.
+type Repository interface {
+	// Dictionary returns every city, active interest and active global tag,
+	// for matching in memory.
+	Dictionary(ctx context.Context) ([]types.Suggestion, error)
+	// SuggestPOIs returns POIs whose name starts with or resembles query,
+	// within reach of location when it is set.
+	SuggestPOIs(ctx context.Context, query string, location *types.GeoPoint, limit int) ([]types.Suggestion, error)
+	// RecentQueries returns the user's latest cities and short queries from
+	// their LLM interactions that contain query.
+	RecentQueries(ctx context.Context, userID uuid.UUID, query string, limit int) ([]types.Suggestion, error)
+}
+
+type RepositoryImpl struct {
+	logger *slog.Logger
+	pgpool *pgxpool.Pool
+}
+
+func NewRepository(pgxpool *pgxpool.Pool, logger *slog.Logger) *RepositoryImpl {
+	return &RepositoryImpl{
+		logger: logger,
+		pgpool: pgxpool,
+	}
+}
+
+// escapeLike escapes the LIKE wildcards in s.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (r *RepositoryImpl) Dictionary(ctx context.Context) ([]types.Suggestion, error) {
+	ctx, span := otel.Tracer("AutocompleteRepository").Start(ctx, "Dictionary")
+	defer span.End()
+
+	query := `
+        SELECT 'city', id, name, COALESCE(NULLIF(state_province, '') || ', ', '') || country
+        FROM cities
+        UNION ALL
+        SELECT 'interest', id, name::text, COALESCE(description, '')
+        FROM interests
+        WHERE active
+        UNION ALL
+        SELECT 'tag', id, name::text, tag_type
+        FROM global_tags
+        WHERE active`
+
+	rows, err := r.pgpool.Query(ctx, query)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Database query failed")
+		return nil, fmt.Errorf("failed to load autocomplete dictionary: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []types.Suggestion
+	for rows.Next() {
+		var s types.Suggestion
+		var id uuid.UUID
+		if err := rows.Scan(&s.Kind, &id, &s.Text, &s.Subtitle); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan autocomplete dictionary row: %w", err)
+		}
+		s.ID = &id
+		entries = append(entries, s)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("error iterating autocomplete dictionary rows: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("entries.count", len(entries)))
+	span.SetStatus(codes.Ok, "Dictionary loaded")
+	return entries, nil
+}
+
+func (r *RepositoryImpl) SuggestPOIs(ctx context.Context, query string, location *types.GeoPoint, limit int) ([]types.Suggestion, error) {
+	ctx, span := otel.Tracer("AutocompleteRepository").Start(ctx, "SuggestPOIs", trace.WithAttributes(
+		attribute.String("query", query),
+		attribute.Bool("location", location != nil),
+		attribute.Int("limit", limit),
+	))
+	defer span.End()
+
+	// A prefix match on the name ranks first; otherwise the trigram word
+	// similarity catches typos and matches inside the name
+	args := []interface{}{query, escapeLike(query) + "%", limit}
+	distance := `NULL::float8`
+	where := `(p.name ILIKE $2 OR $1 <% p.name)`
+	if location != nil {
+		args = append(args, location.Longitude, location.Latitude, nearbyRadiusMeters)
+		distance = `ST_Distance(p.location::geography, ST_SetSRID(ST_MakePoint($4, $5), 4326)::geography) / 1000`
+		where += ` AND ST_DWithin(p.location::geography, ST_SetSRID(ST_MakePoint($4, $5), 4326)::geography, $6)`
+	}
+
+	sql := fmt.Sprintf(`
+        SELECT p.id, p.name, COALESCE(NULLIF(p.address, ''), c.name, ''), %s AS distance_km,
+               CASE WHEN p.name ILIKE $2 THEN 1.0 ELSE word_similarity($1, p.name) END::float8 AS score
+        FROM points_of_interest p
+        LEFT JOIN cities c ON c.id = p.city_id
+        WHERE %s
+        ORDER BY score DESC, distance_km ASC NULLS LAST, p.name
+        LIMIT $3`, distance, where)
+
+	rows, err := r.pgpool.Query(ctx, sql, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Database query failed")
+		return nil, fmt.Errorf("failed to suggest POIs: %w", err)
+	}
+	defer rows.Close()
+
+	var suggestions []types.Suggestion
+	for rows.Next() {
+		s := types.Suggestion{Kind: types.SuggestionPOI}
+		var id uuid.UUID
+		if err := rows.Scan(&id, &s.Text, &s.Subtitle, &s.Distance, &s.Score); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan POI suggestion: %w", err)
+		}
+		s.ID = &id
+		suggestions = append(suggestions, s)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("error iterating POI suggestions: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("results.count", len(suggestions)))
+	span.SetStatus(codes.Ok, "POIs suggested")
+	return suggestions, nil
+}
+
+func (r *RepositoryImpl) RecentQueries(ctx context.Context, userID uuid.UUID, query string, limit int) ([]types.Suggestion, error) {
+	ctx, span := otel.Tracer("AutocompleteRepository").Start(ctx, "RecentQueries", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+		attribute.Int("limit", limit),
+	))
+	defer span.End()
+
+	sql := `
+        SELECT text, MAX(created_at) AS last_used
+        FROM (
+            SELECT city_name AS text, created_at
+            FROM llm_interactions
+            WHERE user_id = $1 AND city_name ILIKE $2
+            UNION ALL
+            SELECT prompt, created_at
+            FROM llm_interactions
+            WHERE user_id = $1 AND LENGTH(prompt) <= $4 AND prompt ILIKE $3
+        ) recent
+        GROUP BY text
+        ORDER BY last_used DESC
+        LIMIT $5`
+
+	escaped := escapeLike(query)
+	rows, err := r.pgpool.Query(ctx, sql, userID, escaped+"%", "%"+escaped+"%", maxRecentPromptLength, limit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Database query failed")
+		return nil, fmt.Errorf("failed to load recent queries: %w", err)
+	}
+	defer rows.Close()
+
+	var suggestions []types.Suggestion
+	for rows.Next() {
+		s := types.Suggestion{Kind: types.SuggestionRecent}
+		var lastUsed time.Time
+		if err := rows.Scan(&s.Text, &lastUsed); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan recent query: %w", err)
+		}
+		suggestions = append(suggestions, s)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("error iterating recent queries: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("results.count", len(suggestions)))
+	span.SetStatus(codes.Ok, "Recent queries loaded")
+	return suggestions, nil
+}
@@ -0,0 +1,343 @@
+package autocomplete
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/patrickmn/go-cache"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Service = (*ServiceImpl)(nil)
+
+const (
+	defaultLimit = 8
+	maxLimit     = 20
+	// dictionaryTTL is how often the cities, interests and tags are reloaded.
+	dictionaryTTL = 10 * time.Minute
+	// poiCacheTTL is short as POIs are added while users search.
+	poiCacheTTL = time.Minute
+	// minSimilarity is the trigram similarity below which a dictionary entry
+	// is not suggested, as pg_trgm's default similarity threshold.
+	minSimilarity = 0.3
+)
+
+// Scores of a dictionary match, best first. Trigram matches score their
+// similarity scaled below any word prefix match.
+const (
+	scoreExact      = 1.0
+	scorePrefix     = 0.9
+	scoreWordPrefix = 0.75
+	scoreSimilar    = 0.6
+)
+
+// kindPriority breaks score ties: places the user can go to come first.
+var kindPriority = map[string]int{
+	types.SuggestionRecent:   0,
+	types.SuggestionCity:     1,
+	types.SuggestionPOI:      2,
+	types.SuggestionInterest: 3,
+	types.SuggestionTag:      4,
+}
+
+// Service defines the business logic for autocomplete.
+type Service interface {
+	// Suggest returns the best suggestions of every requested kind for a
+	// partial query.
+	Suggest(ctx context.Context, req types.AutocompleteRequest) (*types.AutocompleteResponse, error)
+	// Run keeps the in-memory dictionary warm until ctx is cancelled.
+	Run(ctx context.Context)
+}
+
+// dictionaryEntry is a suggestion with its text normalised for matching.
+type dictionaryEntry struct {
+	suggestion types.Suggestion
+	normalized string
+	trigrams   map[string]struct{}
+}
+
+type ServiceImpl struct {
+	logger *slog.Logger
+	repo   Repository
+	// pois caches POI suggestions by query and rounded location
+	pois *cache.Cache
+
+	mu         sync.RWMutex
+	dictionary []dictionaryEntry
+	loadedAt   time.Time
+	now        func() time.Time
+}
+
+func NewService(repo Repository, logger *slog.Logger) *ServiceImpl {
+	return &ServiceImpl{
+		logger: logger,
+		repo:   repo,
+		pois:   cache.New(poiCacheTTL, 5*time.Minute),
+		now:    time.Now,
+	}
+}
+
+// Run implements Service.
+func (s *ServiceImpl) Run(ctx context.Context) {
+	l := s.logger.With(slog.String("worker", "autocomplete"))
+	l.InfoContext(ctx, "Autocomplete dictionary worker started", slog.Duration("interval", dictionaryTTL))
+
+	ticker := time.NewTicker(dictionaryTTL)
+	defer ticker.Stop()
+	for {
+		if err := s.refresh(ctx); err != nil {
+			l.ErrorContext(ctx, "Failed to refresh autocomplete dictionary", slog.Any("error", err))
+		}
+		select {
+		case <-ctx.Done():
+			l.Info("Autocomplete dictionary worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh reloads the dictionary.
+func (s *ServiceImpl) refresh(ctx context.Context) error {
+	suggestions, err := s.repo.Dictionary(ctx)
+	if err != nil {
+		return err
+	}
+	entries := make([]dictionaryEntry, 0, len(suggestions))
+	for _, sg := range suggestions {
+		entries = append(entries, newEntry(sg))
+	}
+
+	s.mu.Lock()
+	s.dictionary = entries
+	s.loadedAt = s.now()
+	s.mu.Unlock()
+	return nil
+}
+
+// entries returns the dictionary, loading it if the worker has not yet done so
+// or has fallen behind.
+func (s *ServiceImpl) entries(ctx context.Context) ([]dictionaryEntry, error) {
+	s.mu.RLock()
+	entries, loadedAt := s.dictionary, s.loadedAt
+	s.mu.RUnlock()
+	if !loadedAt.IsZero() && s.now().Sub(loadedAt) < 2*dictionaryTTL {
+		return entries, nil
+	}
+
+	if err := s.refresh(ctx); err != nil {
+		if entries != nil {
+			// A stale dictionary is better than no suggestions
+			s.logger.WarnContext(ctx, "Serving stale autocomplete dictionary", slog.Any("error", err))
+			return entries, nil
+		}
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dictionary, nil
+}
+
+// Suggest implements Service.
+func (s *ServiceImpl) Suggest(ctx context.Context, req types.AutocompleteRequest) (*types.AutocompleteResponse, error) {
+	ctx, span := otel.Tracer("AutocompleteService").Start(ctx, "Suggest", trace.WithAttributes(
+		attribute.String("query", req.Query),
+		attribute.StringSlice("kinds", req.Kinds),
+	))
+	defer span.End()
+
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		span.SetStatus(codes.Error, "Empty query")
+		return nil, fmt.Errorf("%w: query must not be empty", types.ErrBadRequest)
+	}
+	for _, kind := range req.Kinds {
+		if _, ok := kindPriority[kind]; !ok {
+			span.SetStatus(codes.Error, "Unknown kind")
+			return nil, fmt.Errorf("%w: unknown suggestion kind %q", types.ErrBadRequest, kind)
+		}
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	wants := func(kind string) bool { return len(req.Kinds) == 0 || slices.Contains(req.Kinds, kind) }
+
+	// POIs and recent queries need the database; fetch them while the
+	// dictionary is matched. Either failing only drops its suggestions.
+	var wg sync.WaitGroup
+	var pois, recents []types.Suggestion
+	if wants(types.SuggestionPOI) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			if pois, err = s.suggestPOIs(ctx, query, req.Location, limit); err != nil {
+				s.logger.WarnContext(ctx, "Failed to suggest POIs", slog.Any("error", err))
+			}
+		}()
+	}
+	if wants(types.SuggestionRecent) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			if recents, err = s.repo.RecentQueries(ctx, req.UserID, query, limit); err != nil {
+				s.logger.WarnContext(ctx, "Failed to load recent queries", slog.Any("error", err))
+			}
+		}()
+	}
+
+	var suggestions []types.Suggestion
+	entries, err := s.entries(ctx)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to load autocomplete dictionary", slog.Any("error", err))
+	}
+	normalized := normalize(query)
+	queryTrigrams := trigrams(normalized)
+	for _, e := range entries {
+		if !wants(e.suggestion.Kind) {
+			continue
+		}
+		if score := matchScore(normalized, queryTrigrams, e); score > 0 {
+			sg := e.suggestion
+			sg.Score = score
+			suggestions = append(suggestions, sg)
+		}
+	}
+	wg.Wait()
+
+	for _, r := range recents {
+		// Recent queries are what the user typed before; rank them by how
+		// well they match so a stale one does not beat an exact city
+		r.Score = max(matchScore(normalized, queryTrigrams, newEntry(r)), scoreWordPrefix)
+		suggestions = append(suggestions, r)
+	}
+	suggestions = append(suggestions, pois...)
+
+	suggestions = rank(suggestions, limit)
+	span.SetAttributes(attribute.Int("results.count", len(suggestions)))
+	span.SetStatus(codes.Ok, "Suggestions returned")
+	return &types.AutocompleteResponse{Query: req.Query, Suggestions: suggestions}, nil
+}
+
+// suggestPOIs returns cached POI suggestions for the query near location.
+func (s *ServiceImpl) suggestPOIs(ctx context.Context, query string, location *types.GeoPoint, limit int) ([]types.Suggestion, error) {
+	key := "poi:" + strings.ToLower(query)
+	if location != nil {
+		// Two decimals is about a kilometer, close enough for suggestions
+		key = fmt.Sprintf("%s:%.2f:%.2f", key, location.Latitude, location.Longitude)
+	}
+	key = fmt.Sprintf("%s:%d", key, limit)
+	if cached, found := s.pois.Get(key); found {
+		return cached.([]types.Suggestion), nil
+	}
+
+	pois, err := s.repo.SuggestPOIs(ctx, query, location, limit)
+	if err != nil {
+		return nil, err
+	}
+	s.pois.Set(key, pois, cache.DefaultExpiration)
+	return pois, nil
+}
+
+// rank deduplicates suggestions of the same kind and text, keeping the best
+// scored, and returns the best limit of them.
+func rank(suggestions []types.Suggestion, limit int) []types.Suggestion {
+	slices.SortStableFunc(suggestions, func(a, b types.Suggestion) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return kindPriority[a.Kind] - kindPriority[b.Kind]
+	})
+
+	seen := make(map[string]bool)
+	ranked := make([]types.Suggestion, 0, limit)
+	for _, sg := range suggestions {
+		key := sg.Kind + ":" + strings.ToLower(sg.Text)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		ranked = append(ranked, sg)
+		if len(ranked) == limit {
+			break
+		}
+	}
+	return ranked
+}
+
+func newEntry(sg types.Suggestion) dictionaryEntry {
+	normalized := normalize(sg.Text)
+	return dictionaryEntry{suggestion: sg, normalized: normalized, trigrams: trigrams(normalized)}
+}
+
+// matchScore scores how well entry matches the normalized query, or 0 if it
+// does not.
+func matchScore(query string, queryTrigrams map[string]struct{}, e dictionaryEntry) float64 {
+	switch {
+	case e.normalized == query:
+		return scoreExact
+	case strings.HasPrefix(e.normalized, query):
+		return scorePrefix
+	case strings.Contains(e.normalized, " "+query):
+		return scoreWordPrefix
+	}
+	if sim := similarity(queryTrigrams, e.trigrams); sim >= minSimilarity {
+		return scoreSimilar * sim
+	}
+	return 0
+}
+
+// normalize lowercases s and collapses everything but letters and digits to
+// single spaces.
+func normalize(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// trigrams returns the trigrams of a normalized string the way pg_trgm does:
+// each word is padded with two spaces in front and one behind.
+func trigrams(normalized string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, word := range strings.Fields(normalized) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = struct{}{}
+		}
+	}
+	return set
+}
+
+// similarity is the share of trigrams a and b have in common, as pg_trgm's
+// similarity().
+func similarity(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range a {
+		if _, ok := b[t]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
@@ -0,0 +1,160 @@
+package autocomplete
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+// MockAutocompleteRepository is a mock implementation of Repository
+type MockAutocompleteRepository struct {
+	mock.Mock
+}
+
+func (m *MockAutocompleteRepository) Dictionary(ctx context.Context) ([]types.Suggestion, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.Suggestion), args.Error(1)
+}
+
+func (m *MockAutocompleteRepository) SuggestPOIs(ctx context.Context, query string, location *types.GeoPoint, limit int) ([]types.Suggestion, error) {
+	args := m.Called(ctx, query, location, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.Suggestion), args.Error(1)
+}
+
+func (m *MockAutocompleteRepository) RecentQueries(ctx context.Context, userID uuid.UUID, query string, limit int) ([]types.Suggestion, error) {
+	args := m.Called(ctx, userID, query, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.Suggestion), args.Error(1)
+}
+
+// Helper to setup service with mock repository
+func setupAutocompleteServiceTest() (*ServiceImpl, *MockAutocompleteRepository) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	mockRepo := new(MockAutocompleteRepository)
+	return NewService(mockRepo, logger), mockRepo
+}
+
+func testDictionary() []types.Suggestion {
+	return []types.Suggestion{
+		{Kind: types.SuggestionCity, Text: "Lisbon", Subtitle: "Portugal"},
+		{Kind: types.SuggestionCity, Text: "Porto", Subtitle: "Portugal"},
+		{Kind: types.SuggestionCity, Text: "San Francisco", Subtitle: "California, USA"},
+		{Kind: types.SuggestionInterest, Text: "Live Music"},
+		{Kind: types.SuggestionTag, Text: "requires_booking", Subtitle: "logistics"},
+	}
+}
+
+func texts(suggestions []types.Suggestion) []string {
+	var out []string
+	for _, s := range suggestions {
+		out = append(out, s.Text)
+	}
+	return out
+}
+
+func TestServiceImpl_Suggest(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("merges dictionary, POIs and recent queries by score", func(t *testing.T) {
+		service, mockRepo := setupAutocompleteServiceTest()
+		mockRepo.On("Dictionary", mock.Anything).Return(testDictionary(), nil).Once()
+		mockRepo.On("SuggestPOIs", mock.Anything, "lis", (*types.GeoPoint)(nil), defaultLimit).
+			Return([]types.Suggestion{{Kind: types.SuggestionPOI, Text: "Lisbon Cathedral", Score: 1}}, nil).Once()
+		mockRepo.On("RecentQueries", mock.Anything, userID, "lis", defaultLimit).
+			Return([]types.Suggestion{{Kind: types.SuggestionRecent, Text: "Lisbon"}}, nil).Once()
+
+		resp, err := service.Suggest(ctx, types.AutocompleteRequest{Query: "lis", UserID: userID})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Lisbon Cathedral", "Lisbon", "Lisbon"}, texts(resp.Suggestions))
+		assert.Equal(t, types.SuggestionRecent, resp.Suggestions[1].Kind, "recent ties rank before cities")
+		assert.Equal(t, types.SuggestionCity, resp.Suggestions[2].Kind)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("matches word prefixes and typos", func(t *testing.T) {
+		service, mockRepo := setupAutocompleteServiceTest()
+		mockRepo.On("Dictionary", mock.Anything).Return(testDictionary(), nil).Once()
+
+		resp, err := service.Suggest(ctx, types.AutocompleteRequest{Query: "music", Kinds: []string{types.SuggestionInterest}})
+		require.NoError(t, err)
+		require.Len(t, resp.Suggestions, 1)
+		assert.Equal(t, "Live Music", resp.Suggestions[0].Text)
+		assert.Equal(t, scoreWordPrefix, resp.Suggestions[0].Score)
+
+		resp, err = service.Suggest(ctx, types.AutocompleteRequest{Query: "francsico", Kinds: []string{types.SuggestionCity}})
+		require.NoError(t, err)
+		require.Len(t, resp.Suggestions, 1)
+		assert.Equal(t, "San Francisco", resp.Suggestions[0].Text)
+		assert.Less(t, resp.Suggestions[0].Score, scoreWordPrefix)
+
+		resp, err = service.Suggest(ctx, types.AutocompleteRequest{Query: "booking", Kinds: []string{types.SuggestionTag}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"requires_booking"}, texts(resp.Suggestions))
+
+		// The dictionary is loaded once and served from memory afterwards
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("skips sources that fail", func(t *testing.T) {
+		service, mockRepo := setupAutocompleteServiceTest()
+		mockRepo.On("Dictionary", mock.Anything).Return(testDictionary(), nil).Once()
+		mockRepo.On("SuggestPOIs", mock.Anything, "por", (*types.GeoPoint)(nil), 3).Return(nil, errors.New("timeout")).Once()
+
+		resp, err := service.Suggest(ctx, types.AutocompleteRequest{
+			Query: "por",
+			Kinds: []string{types.SuggestionCity, types.SuggestionPOI},
+			Limit: 3,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Porto"}, texts(resp.Suggestions))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("caches POI suggestions near the same place", func(t *testing.T) {
+		service, mockRepo := setupAutocompleteServiceTest()
+		mockRepo.On("Dictionary", mock.Anything).Return([]types.Suggestion{}, nil).Once()
+		mockRepo.On("SuggestPOIs", mock.Anything, "cafe", mock.Anything, defaultLimit).
+			Return([]types.Suggestion{{Kind: types.SuggestionPOI, Text: "Cafe A Brasileira", Score: 1}}, nil).Once()
+
+		for _, loc := range []types.GeoPoint{{Latitude: 38.7107, Longitude: -9.1421}, {Latitude: 38.7111, Longitude: -9.1419}} {
+			resp, err := service.Suggest(ctx, types.AutocompleteRequest{
+				Query:    "cafe",
+				Location: &loc,
+				Kinds:    []string{types.SuggestionPOI},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"Cafe A Brasileira"}, texts(resp.Suggestions))
+		}
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects empty queries and unknown kinds", func(t *testing.T) {
+		service, _ := setupAutocompleteServiceTest()
+
+		_, err := service.Suggest(ctx, types.AutocompleteRequest{Query: "  "})
+		assert.ErrorIs(t, err, types.ErrBadRequest)
+
+		_, err = service.Suggest(ctx, types.AutocompleteRequest{Query: "lis", Kinds: []string{"hotel"}})
+		assert.ErrorIs(t, err, types.ErrBadRequest)
+	})
+}
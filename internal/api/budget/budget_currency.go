@@ -0,0 +1,84 @@
+package budget
+
+import (
+	"math"
+	"strings"
+)
+
+// Currency is a currency with a rough conversion from euros. Estimates are
+// meant to tell cheap from expensive, so rates only need to be approximate.
+type Currency struct {
+	Code      string
+	PerEuro   float64
+	Precision float64 // Amounts are rounded to a multiple of this
+}
+
+func (c Currency) fromEUR(amount float64) float64 {
+	return math.Round(amount*c.PerEuro/c.Precision) * c.Precision
+}
+
+var euro = Currency{Code: "EUR", PerEuro: 1, Precision: 1}
+
+var currencies = map[string]Currency{
+	"EUR": euro,
+	"USD": {Code: "USD", PerEuro: 1.08, Precision: 1},
+	"GBP": {Code: "GBP", PerEuro: 0.85, Precision: 1},
+	"CHF": {Code: "CHF", PerEuro: 0.95, Precision: 1},
+	"DKK": {Code: "DKK", PerEuro: 7.46, Precision: 5},
+	"SEK": {Code: "SEK", PerEuro: 11.5, Precision: 5},
+	"NOK": {Code: "NOK", PerEuro: 11.6, Precision: 5},
+	"PLN": {Code: "PLN", PerEuro: 4.3, Precision: 1},
+	"CZK": {Code: "CZK", PerEuro: 25, Precision: 10},
+	"HUF": {Code: "HUF", PerEuro: 395, Precision: 100},
+	"TRY": {Code: "TRY", PerEuro: 37, Precision: 10},
+	"CAD": {Code: "CAD", PerEuro: 1.48, Precision: 1},
+	"MXN": {Code: "MXN", PerEuro: 19.5, Precision: 10},
+	"BRL": {Code: "BRL", PerEuro: 6, Precision: 1},
+	"ARS": {Code: "ARS", PerEuro: 1000, Precision: 100},
+	"AUD": {Code: "AUD", PerEuro: 1.65, Precision: 1},
+	"NZD": {Code: "NZD", PerEuro: 1.8, Precision: 1},
+	"JPY": {Code: "JPY", PerEuro: 162, Precision: 100},
+	"CNY": {Code: "CNY", PerEuro: 7.8, Precision: 5},
+	"KRW": {Code: "KRW", PerEuro: 1480, Precision: 1000},
+	"SGD": {Code: "SGD", PerEuro: 1.45, Precision: 1},
+	"HKD": {Code: "HKD", PerEuro: 8.4, Precision: 5},
+	"THB": {Code: "THB", PerEuro: 38, Precision: 10},
+	"INR": {Code: "INR", PerEuro: 90, Precision: 10},
+	"AED": {Code: "AED", PerEuro: 3.97, Precision: 1},
+	"ZAR": {Code: "ZAR", PerEuro: 20, Precision: 5},
+	"MAD": {Code: "MAD", PerEuro: 10.8, Precision: 5},
+	"EGP": {Code: "EGP", PerEuro: 53, Precision: 10},
+}
+
+// countryCurrencies maps lower-cased country names and ISO 3166 codes to
+// ISO 4217 currency codes.
+var countryCurrencies = map[string]string{
+	"portugal": "EUR", "pt": "EUR", "spain": "EUR", "es": "EUR", "france": "EUR", "fr": "EUR",
+	"germany": "EUR", "de": "EUR", "italy": "EUR", "it": "EUR", "netherlands": "EUR", "nl": "EUR",
+	"belgium": "EUR", "be": "EUR", "austria": "EUR", "at": "EUR", "ireland": "EUR", "ie": "EUR",
+	"greece": "EUR", "gr": "EUR", "finland": "EUR", "fi": "EUR", "luxembourg": "EUR", "lu": "EUR",
+	"croatia": "EUR", "hr": "EUR", "slovenia": "EUR", "si": "EUR", "slovakia": "EUR", "sk": "EUR",
+	"estonia": "EUR", "ee": "EUR", "latvia": "EUR", "lv": "EUR", "lithuania": "EUR", "lt": "EUR",
+	"malta": "EUR", "mt": "EUR", "cyprus": "EUR", "cy": "EUR",
+	"united states": "USD", "united states of america": "USD", "usa": "USD", "us": "USD",
+	"united kingdom": "GBP", "uk": "GBP", "gb": "GBP", "england": "GBP", "scotland": "GBP", "wales": "GBP",
+	"switzerland": "CHF", "ch": "CHF", "denmark": "DKK", "dk": "DKK", "sweden": "SEK", "se": "SEK",
+	"norway": "NOK", "no": "NOK", "poland": "PLN", "pl": "PLN", "czech republic": "CZK", "czechia": "CZK", "cz": "CZK",
+	"hungary": "HUF", "hu": "HUF", "turkey": "TRY", "türkiye": "TRY", "tr": "TRY",
+	"canada": "CAD", "ca": "CAD", "mexico": "MXN", "mx": "MXN", "brazil": "BRL", "br": "BRL",
+	"argentina": "ARS", "ar": "ARS", "australia": "AUD", "au": "AUD", "new zealand": "NZD", "nz": "NZD",
+	"japan": "JPY", "jp": "JPY", "china": "CNY", "cn": "CNY", "south korea": "KRW", "korea": "KRW", "kr": "KRW",
+	"singapore": "SGD", "sg": "SGD", "hong kong": "HKD", "hk": "HKD", "thailand": "THB", "th": "THB",
+	"india": "INR", "in": "INR", "united arab emirates": "AED", "uae": "AED", "ae": "AED",
+	"south africa": "ZAR", "za": "ZAR", "morocco": "MAD", "ma": "MAD", "egypt": "EGP", "eg": "EGP",
+}
+
+// CurrencyFor returns the currency of a country given by name or ISO code,
+// or euros when the country is unknown.
+func CurrencyFor(country string) Currency {
+	code, ok := countryCurrencies[strings.ToLower(strings.TrimSpace(country))]
+	if !ok {
+		return euro
+	}
+	return currencies[code]
+}
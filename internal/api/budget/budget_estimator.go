@@ -0,0 +1,257 @@
+package budget
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+// Reference prices in euros for price levels 0 (free) to 4. Entry fees and
+// meals are per person, hotel nights per room.
+var (
+	entryPricesEUR = [5]float64{0, 8, 15, 30, 60}
+	mealPricesEUR  = [5]float64{0, 12, 25, 50, 110}
+	nightPricesEUR = [5]float64{0, 60, 110, 200, 400}
+)
+
+// defaultBudgetLevel is assumed for meals and stays without a price level
+// when the profile has no budget level either.
+const defaultBudgetLevel = 2
+
+// Input is an itinerary to price.
+type Input struct {
+	Country string // Decides the currency; euros when unknown
+	POIs    []types.POIDetailedInfo
+	Hotel   *types.HotelDetailedInfo // A stay on top of the POIs, if any
+	Nights  int                      // Nights at each hotel; at least one is priced
+	Guests  int                      // Defaults to one
+	Rooms   int                      // Defaults to one
+	Profile *types.UserPreferenceProfileResponse
+}
+
+// InputFromRequest fills the stay of an Input from the request's hotel
+// preferences.
+func InputFromRequest(req types.CostEstimateRequest) Input {
+	in := Input{Country: req.Country, POIs: req.POIs, Hotel: req.Hotel}
+	if prefs := req.HotelPreferences; prefs != nil {
+		in.Nights = int(prefs.NumberOfNights)
+		if in.Nights == 0 && !prefs.PreferredCheckIn.IsZero() && prefs.PreferredCheckOut.After(prefs.PreferredCheckIn) {
+			in.Nights = int(math.Ceil(prefs.PreferredCheckOut.Sub(prefs.PreferredCheckIn).Hours() / 24))
+		}
+		in.Guests = int(prefs.NumberOfGuests)
+		in.Rooms = int(prefs.NumberOfRooms)
+	}
+	return in
+}
+
+// Estimate prices every item of the itinerary in the country's currency and
+// flags those above the profile's budget level or price ranges.
+func Estimate(in Input) *types.CostEstimate {
+	currency := CurrencyFor(in.Country)
+	guests := max(in.Guests, 1)
+	rooms := max(in.Rooms, 1)
+	nights := max(in.Nights, 1)
+
+	budgetLevel := 0
+	if in.Profile != nil && in.Profile.BudgetLevel >= 1 && in.Profile.BudgetLevel <= 4 {
+		budgetLevel = in.Profile.BudgetLevel
+	}
+	assumedLevel := defaultBudgetLevel
+	if budgetLevel > 0 {
+		assumedLevel = budgetLevel
+	}
+
+	estimate := &types.CostEstimate{Currency: currency.Code, Guests: guests, Items: []types.CostItem{}}
+	var hotels []types.CostItem
+	for _, p := range in.POIs {
+		kind := kindOf(p.Category)
+		level, ok := parsePriceLevel(p.PriceLevel)
+		if !ok {
+			level, ok = parsePriceLevel(p.PriceRange)
+		}
+		if !ok {
+			level = assumedLevel
+			if kind == types.CostKindEntry {
+				// Parks, beaches and viewpoints are mostly free; other sights are cheap
+				level = 1
+				if types.IsOutdoor(p) {
+					level = 0
+				}
+			}
+		}
+		item := types.CostItem{Name: p.Name, Kind: kind, PriceLevel: level, Assumed: !ok}
+		if kind == types.CostKindHotel {
+			hotels = append(hotels, item)
+			continue
+		}
+		estimate.Items = append(estimate.Items, item)
+	}
+	if in.Hotel != nil {
+		level, ok := 0, false
+		if in.Hotel.PriceRange != nil {
+			level, ok = parsePriceLevel(*in.Hotel.PriceRange)
+		}
+		if !ok {
+			level = assumedLevel
+		}
+		hotels = append(hotels, types.CostItem{Name: in.Hotel.Name, Kind: types.CostKindHotel, PriceLevel: level, Assumed: !ok})
+	}
+	if len(hotels) > 0 {
+		estimate.Nights = nights
+		estimate.Items = append(estimate.Items, hotels...)
+	}
+
+	levelSum, priced := 0, 0
+	for i := range estimate.Items {
+		item := &estimate.Items[i]
+		var unit float64 // Per person, or per room and night
+		switch item.Kind {
+		case types.CostKindHotel:
+			unit = currency.fromEUR(nightPricesEUR[item.PriceLevel])
+			item.Amount = unit * float64(rooms*nights)
+		case types.CostKindMeal:
+			unit = currency.fromEUR(mealPricesEUR[item.PriceLevel])
+			item.Amount = unit * float64(guests)
+		default:
+			unit = currency.fromEUR(entryPricesEUR[item.PriceLevel])
+			item.Amount = unit * float64(guests)
+		}
+		item.Reason = overBudgetReason(*item, unit, budgetLevel, in.Profile, currency.Code)
+		item.OverBudget = item.Reason != ""
+		estimate.OverBudget = estimate.OverBudget || item.OverBudget
+		estimate.Total += item.Amount
+		if item.PriceLevel > 0 {
+			levelSum += item.PriceLevel
+			priced++
+		}
+	}
+
+	estimate.CostLevel = 1
+	if priced > 0 {
+		estimate.CostLevel = min(max(int(math.Round(float64(levelSum)/float64(priced))), 1), 4)
+	}
+	if budgetLevel > 0 && estimate.CostLevel > budgetLevel {
+		estimate.OverBudget = true
+	}
+	return estimate
+}
+
+// overBudgetReason explains why an item costing unit per person (or per room
+// and night) is over the profile's budget, or returns "" when it is not.
+func overBudgetReason(item types.CostItem, unit float64, budgetLevel int, profile *types.UserPreferenceProfileResponse, currency string) string {
+	if budgetLevel > 0 && item.PriceLevel > budgetLevel {
+		return fmt.Sprintf("price level %d is above your budget level %d", item.PriceLevel, budgetLevel)
+	}
+	if profile == nil {
+		return ""
+	}
+	switch item.Kind {
+	case types.CostKindMeal:
+		if prefs := profile.DiningPreferences; prefs != nil && prefs.PriceRangePerPerson != nil && prefs.PriceRangePerPerson.Max != nil &&
+			unit > *prefs.PriceRangePerPerson.Max {
+			return fmt.Sprintf("about %.0f %s per person is above your %.0f limit", unit, currency, *prefs.PriceRangePerPerson.Max)
+		}
+	case types.CostKindHotel:
+		if prefs := profile.AccommodationPreferences; prefs != nil && prefs.PriceRangePerNight != nil && prefs.PriceRangePerNight.Max != nil &&
+			unit > *prefs.PriceRangePerNight.Max {
+			return fmt.Sprintf("about %.0f %s per night is above your %.0f limit", unit, currency, *prefs.PriceRangePerNight.Max)
+		}
+	}
+	return ""
+}
+
+var (
+	hotelKeywords = []string{"hotel", "hostel", "accommodation", "guesthouse", "guest house", "resort", "lodging", "inn", "b&b", "bed and breakfast"}
+	mealKeywords  = []string{"restaurant", "cafe", "café", "coffee", "bar", "pub", "food", "dining", "bakery", "bistro", "eatery", "tavern", "brewery", "winery"}
+)
+
+// kindOf decides from a POI's category how it is priced.
+func kindOf(category string) string {
+	category = strings.ToLower(category)
+	for _, keyword := range hotelKeywords {
+		if containsWord(category, keyword) {
+			return types.CostKindHotel
+		}
+	}
+	for _, keyword := range mealKeywords {
+		if containsWord(category, keyword) {
+			return types.CostKindMeal
+		}
+	}
+	return types.CostKindEntry
+}
+
+// containsWord reports whether keyword appears in s at word boundaries, so
+// that "bar" matches "wine bar" but not "barbican".
+func containsWord(s, keyword string) bool {
+	for i := 0; ; {
+		j := strings.Index(s[i:], keyword)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(keyword)
+		if (start == 0 || !isLetter(s[start-1])) && (end == len(s) || !isLetter(s[end])) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+func isLetter(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 0x80
+}
+
+var priceWords = map[string]int{
+	"free":           0,
+	"budget":         1,
+	"cheap":          1,
+	"inexpensive":    1,
+	"moderate":       2,
+	"mid-range":      2,
+	"midrange":       2,
+	"expensive":      3,
+	"upscale":        3,
+	"luxury":         4,
+	"very expensive": 4,
+}
+
+// parsePriceLevel reads a price level stored as 1-4, as a run of currency
+// symbols ("$$", "€€€") or as a word ("free", "moderate").
+func parsePriceLevel(value string) (int, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return 0, false
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		if n < 0 || n > 4 {
+			return 0, false
+		}
+		return n, true
+	}
+	if level, ok := priceWords[value]; ok {
+		return level, true
+	}
+	symbols := 0
+	for _, r := range value {
+		switch r {
+		case '$', '€', '£', '¥', '₩', '₹':
+			symbols++
+		default:
+			// "$$ - $$$" ranges take their upper end
+			if r != ' ' && r != '-' {
+				return 0, false
+			}
+		}
+	}
+	if symbols == 0 {
+		return 0, false
+	}
+	if strings.Contains(value, "-") {
+		parts := strings.Split(value, "-")
+		symbols = len([]rune(strings.TrimSpace(parts[len(parts)-1])))
+	}
+	return min(symbols, 4), true
+}
@@ -0,0 +1,192 @@
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestParsePriceLevel(t *testing.T) {
+	tests := []struct {
+		value string
+		level int
+		ok    bool
+	}{
+		{"2", 2, true},
+		{"0", 0, true},
+		{"free", 0, true},
+		{" Moderate ", 2, true},
+		{"$$$", 3, true},
+		{"€€", 2, true},
+		{"$$$$$", 4, true},
+		{"$$ - $$$", 3, true},
+		{"luxury", 4, true},
+		{"", 0, false},
+		{"7", 0, false},
+		{"unknown", 0, false},
+		{"10€", 0, false},
+	}
+	for _, tt := range tests {
+		level, ok := parsePriceLevel(tt.value)
+		assert.Equal(t, tt.ok, ok, tt.value)
+		assert.Equal(t, tt.level, level, tt.value)
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	assert.Equal(t, types.CostKindHotel, kindOf("Boutique Hotel"))
+	assert.Equal(t, types.CostKindHotel, kindOf("hostel"))
+	assert.Equal(t, types.CostKindMeal, kindOf("Restaurant"))
+	assert.Equal(t, types.CostKindMeal, kindOf("Wine Bar"))
+	assert.Equal(t, types.CostKindMeal, kindOf("Café"))
+	assert.Equal(t, types.CostKindEntry, kindOf("Barbican Centre"))
+	assert.Equal(t, types.CostKindEntry, kindOf("Museum"))
+	assert.Equal(t, types.CostKindEntry, kindOf(""))
+}
+
+func TestCurrencyFor(t *testing.T) {
+	assert.Equal(t, "EUR", CurrencyFor("Portugal").Code)
+	assert.Equal(t, "JPY", CurrencyFor(" japan ").Code)
+	assert.Equal(t, "GBP", CurrencyFor("GB").Code)
+	assert.Equal(t, "EUR", CurrencyFor("Atlantis").Code)
+	assert.Equal(t, 2400.0, CurrencyFor("Japan").fromEUR(15))
+}
+
+func TestEstimate(t *testing.T) {
+	pois := []types.POIDetailedInfo{
+		{Name: "Jerónimos Monastery", Category: "Monastery", PriceLevel: "2"},
+		{Name: "Time Out Market", Category: "Restaurant", PriceRange: "$$"},
+		{Name: "Belcanto", Category: "Fine Dining Restaurant", PriceLevel: "4"},
+		{Name: "Jardim da Estrela", Category: "Park"},
+		{Name: "Tile Museum", Category: "Museum"},
+	}
+
+	t.Run("prices every item for all guests", func(t *testing.T) {
+		estimate := Estimate(Input{Country: "Portugal", POIs: pois, Guests: 2})
+
+		assert.Equal(t, "EUR", estimate.Currency)
+		assert.Equal(t, 2, estimate.Guests)
+		assert.Zero(t, estimate.Nights)
+		require.Len(t, estimate.Items, 5)
+		assert.Equal(t, types.CostItem{Name: "Jerónimos Monastery", Kind: types.CostKindEntry, PriceLevel: 2, Amount: 30}, estimate.Items[0])
+		assert.Equal(t, types.CostItem{Name: "Time Out Market", Kind: types.CostKindMeal, PriceLevel: 2, Amount: 50}, estimate.Items[1])
+		assert.Equal(t, 220.0, estimate.Items[2].Amount)
+		// No price level: parks are free, other sights cheap
+		assert.Equal(t, types.CostItem{Name: "Jardim da Estrela", Kind: types.CostKindEntry, PriceLevel: 0, Amount: 0, Assumed: true}, estimate.Items[3])
+		assert.Equal(t, types.CostItem{Name: "Tile Museum", Kind: types.CostKindEntry, PriceLevel: 1, Amount: 16, Assumed: true}, estimate.Items[4])
+		assert.Equal(t, 316.0, estimate.Total)
+		// Levels 2, 2, 4 and 1 average to 2.25
+		assert.Equal(t, 2, estimate.CostLevel)
+		assert.False(t, estimate.OverBudget)
+	})
+
+	t.Run("flags items above the budget level", func(t *testing.T) {
+		profile := &types.UserPreferenceProfileResponse{BudgetLevel: 2}
+		estimate := Estimate(Input{Country: "Portugal", POIs: pois, Profile: profile})
+
+		assert.True(t, estimate.OverBudget)
+		assert.True(t, estimate.Items[2].OverBudget)
+		assert.Equal(t, "price level 4 is above your budget level 2", estimate.Items[2].Reason)
+		for i, item := range estimate.Items {
+			if i != 2 {
+				assert.False(t, item.OverBudget, item.Name)
+			}
+		}
+	})
+
+	t.Run("flags meals above the price range per person", func(t *testing.T) {
+		profile := &types.UserPreferenceProfileResponse{
+			DiningPreferences: &types.DiningPreferences{PriceRangePerPerson: &types.RangeFilter{Max: ptr(30.0)}},
+		}
+		estimate := Estimate(Input{POIs: pois[:3], Profile: profile, Guests: 3})
+
+		assert.False(t, estimate.Items[1].OverBudget, "25 per person is within the range")
+		assert.True(t, estimate.Items[2].OverBudget)
+		assert.Equal(t, "about 110 EUR per person is above your 30 limit", estimate.Items[2].Reason)
+		assert.True(t, estimate.OverBudget)
+	})
+
+	t.Run("assumes the budget level for meals without a price", func(t *testing.T) {
+		profile := &types.UserPreferenceProfileResponse{BudgetLevel: 3}
+		estimate := Estimate(Input{POIs: []types.POIDetailedInfo{{Name: "Tasca", Category: "restaurant"}}, Profile: profile})
+
+		assert.Equal(t, 3, estimate.Items[0].PriceLevel)
+		assert.True(t, estimate.Items[0].Assumed)
+		assert.False(t, estimate.OverBudget)
+	})
+
+	t.Run("converts to the local currency", func(t *testing.T) {
+		estimate := Estimate(Input{Country: "Japan", POIs: pois[:1]})
+
+		assert.Equal(t, "JPY", estimate.Currency)
+		assert.Equal(t, 2400.0, estimate.Total)
+	})
+
+	t.Run("all free is the cheapest level", func(t *testing.T) {
+		estimate := Estimate(Input{POIs: pois[3:4]})
+		assert.Zero(t, estimate.Total)
+		assert.Equal(t, 1, estimate.CostLevel)
+	})
+}
+
+func TestEstimate_HotelStay(t *testing.T) {
+	req := types.CostEstimateRequest{
+		Country: "Portugal",
+		POIs:    []types.POIDetailedInfo{{Name: "Tile Museum", Category: "Museum", PriceLevel: "1"}},
+		Hotel:   &types.HotelDetailedInfo{Name: "Memmo Alfama", Category: "Hotel", PriceRange: ptr("$$$")},
+		HotelPreferences: &types.HotelUserPreferences{
+			NumberOfGuests: 2,
+			NumberOfNights: 3,
+		},
+	}
+
+	t.Run("prices the nights per room", func(t *testing.T) {
+		estimate := Estimate(InputFromRequest(req))
+
+		assert.Equal(t, 3, estimate.Nights)
+		require.Len(t, estimate.Items, 2)
+		hotel := estimate.Items[1]
+		assert.Equal(t, types.CostKindHotel, hotel.Kind)
+		assert.Equal(t, 3, hotel.PriceLevel)
+		assert.Equal(t, 600.0, hotel.Amount)
+		assert.Equal(t, 616.0, estimate.Total)
+	})
+
+	t.Run("takes the nights from the stay dates", func(t *testing.T) {
+		dated := req
+		checkIn := time.Date(2026, 5, 1, 15, 0, 0, 0, time.UTC)
+		dated.HotelPreferences = &types.HotelUserPreferences{
+			NumberOfRooms:     2,
+			PreferredCheckIn:  checkIn,
+			PreferredCheckOut: checkIn.Add(44 * time.Hour),
+		}
+		estimate := Estimate(InputFromRequest(dated))
+
+		assert.Equal(t, 2, estimate.Nights)
+		assert.Equal(t, 800.0, estimate.Items[1].Amount)
+	})
+
+	t.Run("flags nights above the price range", func(t *testing.T) {
+		in := InputFromRequest(req)
+		in.Profile = &types.UserPreferenceProfileResponse{
+			AccommodationPreferences: &types.AccommodationPreferences{PriceRangePerNight: &types.RangeFilter{Max: ptr(150.0)}},
+		}
+		estimate := Estimate(in)
+
+		assert.True(t, estimate.Items[1].OverBudget)
+		assert.Equal(t, "about 200 EUR per night is above your 150 limit", estimate.Items[1].Reason)
+	})
+
+	t.Run("prices hotel stops of an itinerary for one night", func(t *testing.T) {
+		estimate := Estimate(Input{POIs: []types.POIDetailedInfo{{Name: "Hostel", Category: "hostel", PriceLevel: "1"}}})
+
+		assert.Equal(t, 1, estimate.Nights)
+		assert.Equal(t, 60.0, estimate.Total)
+	})
+}
@@ -2,6 +2,7 @@ package llmChat
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -38,12 +39,15 @@ type Handler interface {
 	GetHotelsNearby(w http.ResponseWriter, r *http.Request)
 	// TODO
 	GetHotelByID(w http.ResponseWriter, r *http.Request)
+	SearchHotels(w http.ResponseWriter, r *http.Request)
 
 	// GetRestaurantsByPreferences restaurants
 	GetRestaurantsByPreferences(w http.ResponseWriter, r *http.Request)
 	GetRestaurantsNearby(w http.ResponseWriter, r *http.Request)
 	// TODO
 	GetRestaurantDetails(w http.ResponseWriter, r *http.Request)
+	SearchRestaurants(w http.ResponseWriter, r *http.Request)
+	ReportRestaurantDietary(w http.ResponseWriter, r *http.Request)
 
 	// RAG-enabled chat methods
 	// RAGEnabledChatQuery(w http.ResponseWriter, r *http.Request)
@@ -470,6 +474,59 @@ func (HandlerImpl *HandlerImpl) SaveItenerary(w http.ResponseWriter, r *http.Req
 	api.WriteJSONResponse(w, r, http.StatusCreated, savedItinerary)
 }
 
+// EstimateBudget prices an itinerary's POIs, and optionally a hotel stay for
+// the nights in hotel_preferences, in the city's currency, flagging items
+// above the user's budget.
+func (HandlerImpl *HandlerImpl) EstimateBudget(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("HandlerImpl").Start(r.Context(), "EstimateBudget", trace.WithAttributes(
+		semconv.HTTPRequestMethodKey.String(r.Method),
+		semconv.HTTPRouteKey.String("/llm_interaction/budget/estimate"),
+	))
+	defer span.End()
+
+	l := HandlerImpl.logger.With(slog.String("HandlerImpl", "EstimateBudget"))
+
+	userIDStr, ok := auth.GetUserIDFromContext(ctx)
+	if !ok || userIDStr == "" {
+		l.ErrorContext(ctx, "User ID not found in context")
+		api.ErrorResponse(w, r, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		l.ErrorContext(ctx, "Invalid user ID format", slog.Any("error", err))
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid user ID format")
+		return
+	}
+	span.SetAttributes(semconv.EnduserIDKey.String(userID.String()))
+
+	var req types.CostEstimateRequest
+	if err := api.DecodeJSONBody(w, r, &req); err != nil {
+		l.WarnContext(ctx, "Failed to decode cost estimate request", slog.Any("error", err))
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	estimate, err := HandlerImpl.llmInteractionService.EstimateBudget(ctx, userID, req)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to estimate budget", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to estimate budget")
+		switch {
+		case errors.Is(err, types.ErrBadRequest):
+			api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		case errors.Is(err, types.ErrNotFound):
+			api.ErrorResponse(w, r, http.StatusNotFound, "Search profile not found")
+		default:
+			api.ErrorResponse(w, r, http.StatusInternalServerError, "Failed to estimate budget")
+		}
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Success")
+	api.WriteJSONResponse(w, r, http.StatusOK, estimate)
+}
+
 func (HandlerImpl *HandlerImpl) GetUserChatSessions(w http.ResponseWriter, r *http.Request) {
 	ctx, span := otel.Tracer("HandlerImpl").Start(r.Context(), "GetUserChatSessions", trace.WithAttributes(
 		semconv.HTTPRequestMethodKey.String(r.Method),
@@ -497,8 +554,14 @@ func (HandlerImpl *HandlerImpl) GetUserChatSessions(w http.ResponseWriter, r *ht
 	span.SetAttributes(semconv.EnduserIDKey.String(userID.String()))
 	l = l.With(slog.String("userID", userID.String()))
 
+	page, err := api.ParsePageRequest(r)
+	if err != nil {
+		api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// Get chat sessions from service
-	sessions, err := HandlerImpl.llmInteractionService.GetUserChatSessions(ctx, userID)
+	sessions, next, err := HandlerImpl.llmInteractionService.GetUserChatSessions(ctx, userID, page)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to get user chat sessions", slog.Any("error", err))
 		api.ErrorResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("Failed to get chat sessions: %s", err.Error()))
@@ -506,6 +569,7 @@ func (HandlerImpl *HandlerImpl) GetUserChatSessions(w http.ResponseWriter, r *ht
 	}
 
 	l.InfoContext(ctx, "Successfully retrieved user chat sessions", slog.Int("sessionCount", len(sessions)))
+	api.SetPageLinks(w, r, next)
 	api.WriteJSONResponse(w, r, http.StatusOK, sessions)
 }
 
@@ -817,6 +881,71 @@ func (HandlerImpl *HandlerImpl) GetHotelsNearby(w http.ResponseWriter, r *http.R
 	)
 }
 
+// bookingRequest reads what to ask booking partners about from the query
+// string: check_in and check_out dates (YYYY-MM-DD), guests and rooms for
+// hotels, or an RFC3339 time at and party_size for restaurants.
+func bookingRequest(r *http.Request, targetKind string) (types.BookingRequest, error) {
+	q := r.URL.Query()
+	var req types.BookingRequest
+	var err error
+	if targetKind == types.PartnerTargetHotel {
+		if v := q.Get("check_in"); v != "" {
+			if req.CheckIn, err = time.Parse(time.DateOnly, v); err != nil {
+				return req, fmt.Errorf("%w: check_in must be YYYY-MM-DD", types.ErrBadRequest)
+			}
+			req.CheckOut = req.CheckIn.AddDate(0, 0, 1)
+		}
+		if v := q.Get("check_out"); v != "" {
+			if req.CheckOut, err = time.Parse(time.DateOnly, v); err != nil || !req.Dated() || !req.CheckOut.After(req.CheckIn) {
+				return req, fmt.Errorf("%w: check_out must be a YYYY-MM-DD date after check_in", types.ErrBadRequest)
+			}
+		}
+		if req.Guests, err = positiveQueryInt(q.Get("guests")); err != nil {
+			return req, fmt.Errorf("%w: guests must be a positive number", types.ErrBadRequest)
+		}
+		if req.Rooms, err = positiveQueryInt(q.Get("rooms")); err != nil {
+			return req, fmt.Errorf("%w: rooms must be a positive number", types.ErrBadRequest)
+		}
+		return req, nil
+	}
+	if v := q.Get("at"); v != "" {
+		if req.CheckIn, err = time.Parse(time.RFC3339, v); err != nil {
+			return req, fmt.Errorf("%w: at must be an RFC3339 time", types.ErrBadRequest)
+		}
+	}
+	if req.Guests, err = positiveQueryInt(q.Get("party_size")); err != nil {
+		return req, fmt.Errorf("%w: party_size must be a positive number", types.ErrBadRequest)
+	}
+	return req, nil
+}
+
+// positiveQueryInt parses an optional positive count, 0 when absent.
+func positiveQueryInt(v string) (int, error) {
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, errors.New("not a positive number")
+	}
+	return n, nil
+}
+
+// optionalUserID returns the authenticated user, or uuid.Nil.
+func optionalUserID(r *http.Request) uuid.UUID {
+	userIDStr, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		return uuid.Nil
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return uuid.Nil
+	}
+	return userID
+}
+
+// GetHotelByID returns a hotel with the offers of the booking partners it is
+// listed with; see bookingRequest for the query string they are asked about.
 func (HandlerImpl *HandlerImpl) GetHotelByID(w http.ResponseWriter, r *http.Request) {
 	ctx, span := otel.Tracer("HandlerImpl").Start(r.Context(), "HotelByID", trace.WithAttributes(
 		semconv.HTTPRequestMethodKey.String(r.Method),
@@ -836,6 +965,11 @@ func (HandlerImpl *HandlerImpl) GetHotelByID(w http.ResponseWriter, r *http.Requ
 	}
 	span.SetAttributes(attribute.String("app.hotel.id", hotelID.String()))
 	l = l.With(slog.String("hotelID", hotelID.String()))
+	booking, err := bookingRequest(r, types.PartnerTargetHotel)
+	if err != nil {
+		api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
 	hotel, err := HandlerImpl.llmInteractionService.GetHotelByIDResponse(ctx, hotelID)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to fetch hotel by ID", slog.Any("error", err))
@@ -847,9 +981,11 @@ func (HandlerImpl *HandlerImpl) GetHotelByID(w http.ResponseWriter, r *http.Requ
 		api.ErrorResponse(w, r, http.StatusNotFound, "Hotel not found")
 		return
 	}
+	offers := HandlerImpl.llmInteractionService.BookingOffers(ctx, optionalUserID(r), types.PartnerTargetHotel, hotelID, booking)
 	response := struct {
-		Hotel *types.HotelDetailedInfo `json:"hotel"`
-	}{Hotel: hotel}
+		Hotel  *types.HotelDetailedInfo `json:"hotel"`
+		Offers []types.PartnerOffer     `json:"offers,omitempty"`
+	}{Hotel: hotel, Offers: offers}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -868,6 +1004,90 @@ func (HandlerImpl *HandlerImpl) GetHotelByID(w http.ResponseWriter, r *http.Requ
 	)
 }
 
+// SearchHotels searches a city's hotels page by page, filtered by the query
+// string: city, lat and lon, radius_km, min_rating, price_range, category,
+// amenity (the last three comma separated), cursor and limit.
+func (HandlerImpl *HandlerImpl) SearchHotels(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("HandlerImpl").Start(r.Context(), "SearchHotels", trace.WithAttributes(
+		semconv.HTTPRequestMethodKey.String(r.Method),
+		semconv.HTTPRouteKey.String("/llm_interaction/hotel_search"),
+	))
+	defer span.End()
+
+	l := HandlerImpl.logger.With(slog.String("HandlerImpl", "SearchHotels"))
+
+	userIDStr, ok := auth.GetUserIDFromContext(ctx)
+	if !ok || userIDStr == "" {
+		l.ErrorContext(ctx, "User ID not found in context")
+		api.ErrorResponse(w, r, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		l.ErrorContext(ctx, "Invalid user ID format", slog.Any("error", err))
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid user ID format")
+		return
+	}
+	span.SetAttributes(semconv.EnduserIDKey.String(userID.String()))
+
+	q := r.URL.Query()
+	params := types.HotelSearchParameters{
+		City:        q.Get("city"),
+		PriceRanges: api.QueryList(r, "price_range"),
+		Categories:  api.QueryList(r, "category"),
+		Amenities:   api.QueryList(r, "amenity"),
+	}
+	if params.Page, err = api.ParsePageRequest(r); err != nil {
+		api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if latStr, lonStr := q.Get("lat"), q.Get("lon"); latStr != "" || lonStr != "" {
+		lat, latErr := strconv.ParseFloat(latStr, 64)
+		lon, lonErr := strconv.ParseFloat(lonStr, 64)
+		if latErr != nil || lonErr != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+			api.ErrorResponse(w, r, http.StatusBadRequest, "lat and lon must both be valid coordinates")
+			return
+		}
+		params.Latitude, params.Longitude = lat, lon
+	}
+	if v := q.Get("radius_km"); v != "" {
+		if params.RadiusKm, err = strconv.ParseFloat(v, 64); err != nil || params.RadiusKm < 0 {
+			api.ErrorResponse(w, r, http.StatusBadRequest, "radius_km must be a positive number")
+			return
+		}
+	}
+	if v := q.Get("min_rating"); v != "" {
+		minRating, err := strconv.ParseFloat(v, 64)
+		if err != nil || minRating < 0 || minRating > 5 {
+			api.ErrorResponse(w, r, http.StatusBadRequest, "min_rating must be between 0 and 5")
+			return
+		}
+		params.MinRating = &minRating
+	}
+	span.SetAttributes(attribute.String("app.city", params.City), attribute.Int("app.limit", params.Page.Limit))
+
+	resp, err := HandlerImpl.llmInteractionService.SearchHotels(ctx, userID, params)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to search hotels", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to search hotels")
+		switch {
+		case errors.Is(err, types.ErrBadRequest):
+			api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		case errors.Is(err, types.ErrNotFound):
+			api.ErrorResponse(w, r, http.StatusNotFound, "City not found")
+		default:
+			api.ErrorResponse(w, r, http.StatusInternalServerError, "Failed to search hotels")
+		}
+		return
+	}
+
+	span.SetAttributes(attribute.Int("app.hotels.count", len(resp.Hotels)))
+	span.SetStatus(codes.Ok, "Success")
+	api.SetPageLinks(w, r, resp.NextCursor)
+	api.WriteJSONResponse(w, r, http.StatusOK, resp)
+}
+
 func (HandlerImpl *HandlerImpl) GetRestaurantsByPreferences(w http.ResponseWriter, r *http.Request) {
 	ctx, span := otel.Tracer("HandlerImpl").Start(r.Context(), "GetRestaurantsByPreferences", trace.WithAttributes(
 		semconv.HTTPRequestMethodKey.String(r.Method),
@@ -1023,6 +1243,9 @@ func (HandlerImpl *HandlerImpl) GetRestaurantsNearby(w http.ResponseWriter, r *h
 	span.SetStatus(codes.Ok, "Success")
 }
 
+// GetRestaurantDetails returns a restaurant with the offers of the booking
+// partners it is listed with; see bookingRequest for the query string they
+// are asked about.
 func (HandlerImpl *HandlerImpl) GetRestaurantDetails(w http.ResponseWriter, r *http.Request) {
 	ctx, span := otel.Tracer("HandlerImpl").Start(r.Context(), "GetRestaurantDetails", trace.WithAttributes(
 		semconv.HTTPRequestMethodKey.String(r.Method),
@@ -1042,6 +1265,11 @@ func (HandlerImpl *HandlerImpl) GetRestaurantDetails(w http.ResponseWriter, r *h
 		return
 	}
 	span.SetAttributes(attribute.String("app.restaurant.id", restaurantID.String()))
+	booking, err := bookingRequest(r, types.PartnerTargetRestaurant)
+	if err != nil {
+		api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	// Call service method
 	restaurant, err := HandlerImpl.llmInteractionService.GetRestaurantDetailsResponse(ctx, restaurantID)
@@ -1057,9 +1285,11 @@ func (HandlerImpl *HandlerImpl) GetRestaurantDetails(w http.ResponseWriter, r *h
 	}
 
 	// Prepare response
+	offers := HandlerImpl.llmInteractionService.BookingOffers(ctx, optionalUserID(r), types.PartnerTargetRestaurant, restaurantID, booking)
 	response := struct {
 		Restaurant *types.RestaurantDetailedInfo `json:"restaurant"`
-	}{Restaurant: restaurant}
+		Offers     []types.PartnerOffer          `json:"offers,omitempty"`
+	}{Restaurant: restaurant, Offers: offers}
 
 	// Encode response
 	w.Header().Set("Content-Type", "application/json")
@@ -1074,6 +1304,169 @@ func (HandlerImpl *HandlerImpl) GetRestaurantDetails(w http.ResponseWriter, r *h
 	span.SetStatus(codes.Ok, "Success")
 }
 
+// SearchRestaurants searches a city's restaurants page by page, filtered by the
+// query string: city, lat and lon, radius_km, min_rating, open_now, cursor and
+// limit; price_range, cuisine, category, feature, dietary, allergen_free
+// and service_style (comma separated); and profile_id, whose dining
+// preferences apply too. allergen_free matches only allergens staff verified
+// or users confirmed.
+func (HandlerImpl *HandlerImpl) SearchRestaurants(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("HandlerImpl").Start(r.Context(), "SearchRestaurants", trace.WithAttributes(
+		semconv.HTTPRequestMethodKey.String(r.Method),
+		semconv.HTTPRouteKey.String("/llm_interaction/restaurant_search"),
+	))
+	defer span.End()
+
+	l := HandlerImpl.logger.With(slog.String("HandlerImpl", "SearchRestaurants"))
+
+	userIDStr, ok := auth.GetUserIDFromContext(ctx)
+	if !ok || userIDStr == "" {
+		l.ErrorContext(ctx, "User ID not found in context")
+		api.ErrorResponse(w, r, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		l.ErrorContext(ctx, "Invalid user ID format", slog.Any("error", err))
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid user ID format")
+		return
+	}
+	span.SetAttributes(semconv.EnduserIDKey.String(userID.String()))
+
+	q := r.URL.Query()
+	params := types.RestaurantSearchParameters{
+		City:          q.Get("city"),
+		PriceRanges:   api.QueryList(r, "price_range"),
+		Cuisines:      api.QueryList(r, "cuisine"),
+		Categories:    api.QueryList(r, "category"),
+		Features:      api.QueryList(r, "feature"),
+		DietaryNeeds:  api.QueryList(r, "dietary"),
+		AllergenFree:  api.QueryList(r, "allergen_free"),
+		ServiceStyles: api.QueryList(r, "service_style"),
+	}
+	if params.Page, err = api.ParsePageRequest(r); err != nil {
+		api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if latStr, lonStr := q.Get("lat"), q.Get("lon"); latStr != "" || lonStr != "" {
+		lat, latErr := strconv.ParseFloat(latStr, 64)
+		lon, lonErr := strconv.ParseFloat(lonStr, 64)
+		if latErr != nil || lonErr != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+			api.ErrorResponse(w, r, http.StatusBadRequest, "lat and lon must both be valid coordinates")
+			return
+		}
+		params.Latitude, params.Longitude = lat, lon
+	}
+	if v := q.Get("radius_km"); v != "" {
+		if params.RadiusKm, err = strconv.ParseFloat(v, 64); err != nil || params.RadiusKm < 0 {
+			api.ErrorResponse(w, r, http.StatusBadRequest, "radius_km must be a positive number")
+			return
+		}
+	}
+	if v := q.Get("min_rating"); v != "" {
+		minRating, err := strconv.ParseFloat(v, 64)
+		if err != nil || minRating < 0 || minRating > 5 {
+			api.ErrorResponse(w, r, http.StatusBadRequest, "min_rating must be between 0 and 5")
+			return
+		}
+		params.MinRating = &minRating
+	}
+	if v := q.Get("open_now"); v != "" {
+		openNow, err := strconv.ParseBool(v)
+		if err != nil {
+			api.ErrorResponse(w, r, http.StatusBadRequest, "open_now must be true or false")
+			return
+		}
+		params.OpenNow = &openNow
+	}
+	if v := q.Get("profile_id"); v != "" {
+		if params.ProfileID, err = uuid.Parse(v); err != nil {
+			api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid profile ID format")
+			return
+		}
+	}
+	span.SetAttributes(attribute.String("app.city", params.City), attribute.Int("app.limit", params.Page.Limit))
+
+	resp, err := HandlerImpl.llmInteractionService.SearchRestaurants(ctx, userID, params)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to search restaurants", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to search restaurants")
+		switch {
+		case errors.Is(err, types.ErrBadRequest):
+			api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		case errors.Is(err, types.ErrNotFound):
+			api.ErrorResponse(w, r, http.StatusNotFound, "City or search profile not found")
+		default:
+			api.ErrorResponse(w, r, http.StatusInternalServerError, "Failed to search restaurants")
+		}
+		return
+	}
+
+	span.SetAttributes(attribute.Int("app.restaurants.count", len(resp.Restaurants)))
+	span.SetStatus(codes.Ok, "Success")
+	api.SetPageLinks(w, r, resp.NextCursor)
+	api.WriteJSONResponse(w, r, http.StatusOK, resp)
+}
+
+// ReportRestaurantDietary lets a user correct whether a restaurant caters for
+// a diet or can avoid an allergen. It responds with the restaurant as now
+// known.
+func (HandlerImpl *HandlerImpl) ReportRestaurantDietary(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("HandlerImpl").Start(r.Context(), "ReportRestaurantDietary", trace.WithAttributes(
+		semconv.HTTPRequestMethodKey.String(r.Method),
+		semconv.HTTPRouteKey.String("/llm_interaction/restaurant/{restaurantID}/dietary"),
+	))
+	defer span.End()
+
+	l := HandlerImpl.logger.With(slog.String("HandlerImpl", "ReportRestaurantDietary"))
+
+	userIDStr, ok := auth.GetUserIDFromContext(ctx)
+	if !ok || userIDStr == "" {
+		l.ErrorContext(ctx, "User ID not found in context")
+		api.ErrorResponse(w, r, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		l.ErrorContext(ctx, "Invalid user ID format", slog.Any("error", err))
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid user ID format")
+		return
+	}
+	restaurantID, err := uuid.Parse(chi.URLParam(r, "restaurantID"))
+	if err != nil {
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid restaurant ID format")
+		return
+	}
+	span.SetAttributes(semconv.EnduserIDKey.String(userID.String()), attribute.String("app.restaurant.id", restaurantID.String()))
+
+	var report types.DietaryReport
+	if err := api.DecodeJSONBody(w, r, &report); err != nil {
+		l.WarnContext(ctx, "Failed to decode dietary report", slog.Any("error", err))
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	restaurant, err := HandlerImpl.llmInteractionService.ReportRestaurantDietary(ctx, userID, restaurantID, report)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to save dietary report", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to save dietary report")
+		switch {
+		case errors.Is(err, types.ErrBadRequest):
+			api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		case errors.Is(err, types.ErrNotFound):
+			api.ErrorResponse(w, r, http.StatusNotFound, "Restaurant not found")
+		default:
+			api.ErrorResponse(w, r, http.StatusInternalServerError, "Failed to save dietary report")
+		}
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Success")
+	api.WriteJSONResponse(w, r, http.StatusOK, restaurant)
+}
+
 // ProcessUnifiedChatMessage handles unified chat requests
 func (h *HandlerImpl) ProcessUnifiedChatMessage(w http.ResponseWriter, r *http.Request) {
 	ctx, span := otel.Tracer("HandlerImpl").Start(r.Context(), "ProcessUnifiedChatMessage", trace.WithAttributes(
@@ -1114,6 +1507,7 @@ func (h *HandlerImpl) ProcessUnifiedChatMessage(w http.ResponseWriter, r *http.R
 	var req struct {
 		Message      string              `json:"message"`
 		UserLocation *types.UserLocation `json:"user_location,omitempty"`
+		TravelDates  *types.TravelDates  `json:"travel_dates,omitempty"` // Adds the city's events on those days to itineraries and adapts them to those days' forecast
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -1131,6 +1525,13 @@ func (h *HandlerImpl) ProcessUnifiedChatMessage(w http.ResponseWriter, r *http.R
 		api.ErrorResponse(w, r, http.StatusBadRequest, "message is required")
 		return
 	}
+	if req.TravelDates != nil {
+		if _, _, err := req.TravelDates.Range(time.UTC); err != nil {
+			span.SetStatus(codes.Error, "Invalid travel dates")
+			api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
 
 	span.SetAttributes(
 		attribute.String("user.id", userID.String()),
@@ -1139,7 +1540,7 @@ func (h *HandlerImpl) ProcessUnifiedChatMessage(w http.ResponseWriter, r *http.R
 	)
 
 	// Process unified chat message
-	response, err := h.llmInteractionService.ProcessUnifiedChatMessage(ctx, userID, profileID, "", req.Message, req.UserLocation)
+	response, err := h.llmInteractionService.ProcessUnifiedChatMessage(ctx, userID, profileID, "", req.Message, req.UserLocation, req.TravelDates)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to process unified chat message", slog.Any("error", err))
 		span.RecordError(err)
@@ -314,6 +314,7 @@ func (h *HandlerImpl) ProcessUnifiedChatMessageStream(w http.ResponseWriter, r *
 	var req struct {
 		Message      string              `json:"message"`
 		UserLocation *types.UserLocation `json:"user_location,omitempty"`
+		TravelDates  *types.TravelDates  `json:"travel_dates,omitempty"` // Adds the city's events on those days to itineraries
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -331,6 +332,13 @@ func (h *HandlerImpl) ProcessUnifiedChatMessageStream(w http.ResponseWriter, r *
 		api.ErrorResponse(w, r, http.StatusBadRequest, "message is required")
 		return
 	}
+	if req.TravelDates != nil {
+		if _, _, err := req.TravelDates.Range(time.UTC); err != nil {
+			span.SetStatus(codes.Error, "Invalid travel dates")
+			api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
 
 	span.SetAttributes(
 		attribute.String("user.id", userID.String()),
@@ -414,7 +422,7 @@ func (h *HandlerImpl) ProcessUnifiedChatMessageStream(w http.ResponseWriter, r *
 	// Start processing in a goroutine
 	go func() {
 		err := h.llmInteractionService.ProcessUnifiedChatMessageStream(
-			ctx, userID, profileID, "", req.Message, req.UserLocation, eventCh,
+			ctx, userID, profileID, "", req.Message, req.UserLocation, req.TravelDates, eventCh,
 		)
 		if err != nil {
 			l.ErrorContext(ctx, "Failed to process unified chat message stream", slog.Any("error", err))
@@ -8,6 +8,9 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
+
+	generativeAI "github.com/FACorreiaa/go-poi-au-suggestions/internal/api/generative_ai"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
 )
 
 func generatePOICacheKey(city string, lat, lon, distance float64, userID uuid.UUID) string {
@@ -18,10 +21,6 @@ func generateHotelCacheKey(city string, lat, lon float64, userID uuid.UUID) stri
 	return fmt.Sprintf("hotel:%s:%.6f:%.6f:%s", city, lat, lon, userID.String())
 }
 
-func generateRestaurantCacheKey(city string, lat, lon float64, userID uuid.UUID) string {
-	return fmt.Sprintf("restaurant:%s:%.6f:%.6f:%s", city, lat, lon, userID.String())
-}
-
 func cleanJSONResponse(response string) string {
 	response = strings.TrimSpace(response)
 
@@ -324,3 +323,29 @@ func (l *ServiceImpl) generateRealisticTags(category, description string) []stri
 
 	return tags
 }
+
+// toConversationTurns converts stored session messages into the RAG history format.
+func toConversationTurns(messages []types.ConversationMessage) []generativeAI.ConversationTurn {
+	turns := make([]generativeAI.ConversationTurn, 0, len(messages))
+	for _, msg := range messages {
+		turns = append(turns, generativeAI.ConversationTurn{
+			Role:      string(msg.Role),
+			Message:   msg.Content,
+			Timestamp: msg.Timestamp,
+		})
+	}
+	return turns
+}
+
+// formatRAGAnswer appends the cited places to the answer text.
+func formatRAGAnswer(resp *generativeAI.RAGResponse) string {
+	var b strings.Builder
+	b.WriteString(resp.Answer)
+	if len(resp.SourcePOIs) > 0 {
+		b.WriteString("\n\nSources:\n")
+		for i, poi := range resp.SourcePOIs {
+			fmt.Fprintf(&b, "[%d] %s (%s)\n", i+1, poi.Name, poi.Category)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
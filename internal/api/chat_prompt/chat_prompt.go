@@ -7,6 +7,51 @@ import (
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
 )
 
+// getLearnedPreferencesPrompt describes what the user's favourites, saved
+// itineraries, lists and removals say about their taste. It is empty when
+// nothing has been learned yet.
+func getLearnedPreferencesPrompt(learned *types.LearnedPreferences) string {
+	if learned == nil || (len(learned.Favoured) == 0 && len(learned.Avoided) == 0 && len(learned.RemovedPOIs) == 0) {
+		return ""
+	}
+
+	prompt := `
+LEARNED FROM THE USER'S ACTIVITY:`
+	if len(learned.Favoured) > 0 {
+		prompt += fmt.Sprintf(`
+    - Tends to keep: [%s]. Favour places like these.`, strings.Join(learned.Favoured, ", "))
+	}
+	if len(learned.Avoided) > 0 {
+		prompt += fmt.Sprintf(`
+    - Tends to remove: [%s]. Suggest these only if they clearly fit the request.`, strings.Join(learned.Avoided, ", "))
+	}
+	if len(learned.RemovedPOIs) > 0 {
+		prompt += fmt.Sprintf(`
+    - Recently removed, do not suggest again: [%s]`, strings.Join(learned.RemovedPOIs, ", "))
+	}
+	return prompt
+}
+
+// getAccessibilityNeedsPrompt asks for places meeting the user's accessibility
+// needs and for what is known about each place's accessibility, so suggestions
+// can be checked against it. It is empty without needs.
+func getAccessibilityNeedsPrompt(needs *types.AccessibilityNeeds) string {
+	if !needs.Any() {
+		return ""
+	}
+	rule := "Do not suggest places known to lack any of them."
+	if needs.AccessibleOnly {
+		rule = "Only suggest places known to have all of them."
+	}
+	return fmt.Sprintf(`
+
+ACCESSIBILITY NEEDS:
+    - Required Features: [%s]
+    - %s
+    - For every point of interest add "accessibility": an object mapping each feature you are sure about to true or false, e.g. {"step_free_entrance": true, "hearing_loop": false}. Leave out features you do not know about.`,
+		strings.Join(needs.Features, ", "), rule)
+}
+
 func getUserPreferencesPrompt(searchProfile *types.UserPreferenceProfileResponse) string {
 	// Base preferences
 	basePrefs := fmt.Sprintf(`
@@ -19,12 +64,11 @@ BASIC PREFERENCES:
     - Prefers Dog Friendly: %t
     - Preferred Dietary Needs: [%s]
     - Preferred Pace: %s
-    - Prefers Accessible POIs: %t
     - Preferred Vibes: [%s]
     - Preferred Transport: %s`,
 		searchProfile.ProfileName, searchProfile.SearchRadiusKm, searchProfile.PreferredTime, searchProfile.BudgetLevel,
 		searchProfile.PreferOutdoorSeating, searchProfile.PreferDogFriendly, strings.Join(searchProfile.DietaryNeeds, ", "),
-		searchProfile.PreferredPace, searchProfile.PreferAccessiblePOIs, strings.Join(searchProfile.PreferredVibes, ", "),
+		searchProfile.PreferredPace, strings.Join(searchProfile.PreferredVibes, ", "),
 		searchProfile.PreferredTransport)
 
 	// User location if available
@@ -53,6 +97,8 @@ BASIC PREFERENCES:
     - Tags to Avoid: [%s]`, strings.Join(tags, ", "))
 	}
 
+	basePrefs += getAccessibilityNeedsPrompt(searchProfile.AccessibilityNeeds)
+
 	// Accommodation preferences
 	if searchProfile.AccommodationPreferences != nil {
 		accom := searchProfile.AccommodationPreferences
@@ -345,7 +391,11 @@ func getHotelsByPreferencesPrompt(city string, lat, lon float64, userPreferences
                     "latitude": <float>,
                     "longitude": <float>,
                     "category": "Primary category (e.g., Hotel, Hostel, Guesthouse)",
-                    "description": "A brief description of this hotel and why it's relevant to the user's interest."
+                    "description": "A brief description of this hotel and why it's relevant to the user's interest.",
+                    "address": "Street address of the hotel",
+                    "price_range": "Price range from $ (budget) to $$$$ (luxury)",
+                    "rating": <float between 0 and 5>,
+                    "tags": ["Amenities in lowercase (e.g., wifi, pool, gym, parking, pet-friendly)"]
                 }
             ]
         }
@@ -393,10 +443,22 @@ func getRestaurantsByPreferencesPrompt(city string, lat, lon float64, userPrefer
                     "latitude": <float>,
                     "longitude": <float>,
                     "category": "Restaurant|Bar|Cafe",
-                    "description": "Brief description of the restaurant and why it matches preferences."
+                    "description": "Brief description of the restaurant and why it matches preferences.",
+                    "address": "Street address",
+                    "price_level": "$|$$|$$$|$$$$",
+                    "cuisine_type": "Main cuisine (e.g., Italian, Japanese, Portuguese)",
+                    "rating": <float between 0 and 5>,
+                    "tags": ["tag1", "tag2"],
+                    "dietary_options": ["vegetarian", "vegan", "gluten_free", "halal", "kosher"],
+                    "allergen_free": ["gluten", "nuts", "dairy", "shellfish", "soy"],
+                    "service_style": "fine_dining|casual|fast_casual|street_food|buffet|takeaway",
+                    "features": ["outdoor_seating", "dog_friendly", "live_music", "wheelchair_accessible"],
+                    "opening_hours": {"monday": "12:00-15:00, 19:00-23:00", "tuesday": "12:00-23:00"}
                 }
             ]
         }
+        Only list dietary options, allergens the kitchen can reliably avoid, and features the restaurant is known for; leave a list empty when unsure.
+        Give opening hours per lowercase weekday as 24-hour "HH:MM-HH:MM" ranges, omitting days it is closed.
     `, city, lat, lon, userPreferences.PreferredCuisine, userPreferences.PreferredPriceRange,
 		userPreferences.DietaryRestrictions, userPreferences.Ambiance, userPreferences.SpecialFeatures)
 }
@@ -19,6 +19,7 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/jobs"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
 )
 
@@ -35,7 +36,9 @@ type Repository interface {
 	// Session methods
 	CreateSession(ctx context.Context, session types.ChatSession) error
 	GetSession(ctx context.Context, sessionID uuid.UUID) (*types.ChatSession, error)
-	GetUserChatSessions(ctx context.Context, userID uuid.UUID) ([]types.ChatSession, error)
+	// GetUserChatSessions returns a page of the user's chat sessions, most
+	// recently active first, and the cursor of the next page.
+	GetUserChatSessions(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]types.ChatSession, *types.Cursor, error)
 	UpdateSession(ctx context.Context, session types.ChatSession) error
 	AddMessageToSession(ctx context.Context, sessionID uuid.UUID, message types.ConversationMessage) error
 
@@ -579,7 +582,7 @@ func (r *RepositoryImpl) GetSession(ctx context.Context, sessionID uuid.UUID) (*
 }
 
 // GetUserChatSessions retrieves chat history from LLM interactions grouped by session/city, ordered by most recent first
-func (r *RepositoryImpl) GetUserChatSessions(ctx context.Context, userID uuid.UUID) ([]types.ChatSession, error) {
+func (r *RepositoryImpl) GetUserChatSessions(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]types.ChatSession, *types.Cursor, error) {
 	ctx, span := otel.Tracer("LlmInteractionRepo").Start(ctx, "GetUserChatSessions", trace.WithAttributes(
 		semconv.DBSystemKey.String(semconv.DBSystemPostgreSQL.Value.AsString()),
 		attribute.String("db.operation", "SELECT"),
@@ -588,7 +591,13 @@ func (r *RepositoryImpl) GetUserChatSessions(ctx context.Context, userID uuid.UU
 	))
 	defer span.End()
 
-	query := `
+	args := []interface{}{userID, page.FetchLimit()}
+	after := ""
+	if page.Cursor != nil {
+		args = append(args, page.Cursor.Time, page.Cursor.Key)
+		after = "WHERE (last_interaction, session_key) < ($3, $4)"
+	}
+	query := fmt.Sprintf(`
         WITH grouped_interactions AS (
             SELECT 
                 COALESCE(session_id, city_name || '_' || DATE(created_at)) as session_key,
@@ -620,20 +629,25 @@ func (r *RepositoryImpl) GetUserChatSessions(ctx context.Context, userID uuid.UU
             interaction_count,
             interactions
         FROM grouped_interactions
-        ORDER BY last_interaction DESC
-        LIMIT 50
-    `
+        %s
+        ORDER BY last_interaction DESC, session_key DESC
+        LIMIT $2
+    `, after)
 
-	rows, err := r.pgpool.Query(ctx, query, userID)
+	rows, err := r.pgpool.Query(ctx, query, args...)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "Failed to query LLM interactions")
 		r.logger.ErrorContext(ctx, "Failed to get user chat sessions from LLM interactions", slog.Any("error", err), slog.String("user_id", userID.String()))
-		return nil, fmt.Errorf("failed to get user chat sessions: %w", err)
+		return nil, nil, fmt.Errorf("failed to get user chat sessions: %w", err)
 	}
 	defer rows.Close()
 
+	// Sessions are keyed by a grouping the session does not carry, so the
+	// cursor is taken from the rows rather than the sessions
 	var sessions []types.ChatSession
+	var last, next *types.Cursor
+	scanned := 0
 	for rows.Next() {
 		var sessionKey, cityName string
 		var userIDFromDB uuid.UUID
@@ -646,8 +660,15 @@ func (r *RepositoryImpl) GetUserChatSessions(ctx context.Context, userID uuid.UU
 			span.RecordError(err)
 			span.SetStatus(codes.Error, "Failed to scan LLM interaction row")
 			r.logger.ErrorContext(ctx, "Failed to scan LLM interaction row", slog.Any("error", err))
-			return nil, fmt.Errorf("failed to scan LLM interaction row: %w", err)
+			return nil, nil, fmt.Errorf("failed to scan LLM interaction row: %w", err)
+		}
+		if scanned == page.Limit {
+			// The look-ahead row: another page follows the last one kept
+			next = last
+			break
 		}
+		scanned++
+		last = &types.Cursor{Time: &lastInteraction, Key: sessionKey}
 
 		var interactions []map[string]interface{}
 		if err := json.Unmarshal([]byte(interactionsJSON), &interactions); err != nil {
@@ -696,11 +717,11 @@ func (r *RepositoryImpl) GetUserChatSessions(ctx context.Context, userID uuid.UU
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "Error iterating through LLM interaction rows")
 		r.logger.ErrorContext(ctx, "Error iterating through LLM interaction rows", slog.Any("error", err))
-		return nil, fmt.Errorf("error iterating through LLM interaction rows: %w", err)
+		return nil, nil, fmt.Errorf("error iterating through LLM interaction rows: %w", err)
 	}
 
 	span.SetAttributes(attribute.Int("sessions.count", len(sessions)))
-	return sessions, nil
+	return sessions, next, nil
 }
 
 // Helper function to parse time from interface{}
@@ -1160,8 +1181,8 @@ func (r *RepositoryImpl) GetOrCreatePOI(ctx context.Context, tx pgx.Tx, POIDetai
 
 	if err == pgx.ErrNoRows {
 		createPoiQuery := `
-            INSERT INTO points_of_interest (name, city_id, location, category, description)
-            VALUES ($1, $2, ST_SetSRID(ST_MakePoint($3, $4), 4326), $5, $6) RETURNING id`
+            INSERT INTO points_of_interest (name, city_id, location, category, description, accessibility)
+            VALUES ($1, $2, ST_SetSRID(ST_MakePoint($3, $4), 4326), $5, $6, COALESCE($7::jsonb, '{}')) RETURNING id`
 		err = tx.QueryRow(ctx, createPoiQuery,
 			POIDetailedInfo.Name,
 			cityID,
@@ -1169,11 +1190,15 @@ func (r *RepositoryImpl) GetOrCreatePOI(ctx context.Context, tx pgx.Tx, POIDetai
 			POIDetailedInfo.Longitude,
 			POIDetailedInfo.Category,
 			POIDetailedInfo.DescriptionPOI, // Assumes types.POIDetailedInfo has DescriptionPOI from JSON
+			types.KnownAccessibility(POIDetailedInfo.Accessibility),
 		).Scan(&poiDBID)
 		if err != nil {
 			r.logger.ErrorContext(ctx, "GetOrCreatePOI: Failed to insert new POI", "error", err, "poi_name", POIDetailedInfo.Name)
 			return uuid.Nil, fmt.Errorf("GetOrCreatePOI: failed to insert new POI '%s': %w", POIDetailedInfo.Name, err)
 		}
+		if err = jobs.EnqueueTx(ctx, tx, types.JobKindPOIEmbedding, poiDBID); err != nil {
+			return uuid.Nil, fmt.Errorf("GetOrCreatePOI: %w", err)
+		}
 	} else if err != nil {
 		r.logger.ErrorContext(ctx, "GetOrCreatePOI: Failed to query existing POI", "error", err, "poi_name", POIDetailedInfo.Name)
 		return uuid.Nil, fmt.Errorf("GetOrCreatePOI: failed to query existing POI '%s': %w", POIDetailedInfo.Name, err)
@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"log"
 	"log/slog"
+	"maps"
+	"math"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -20,18 +23,30 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/genai"
 
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/analytics"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/budget"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/city"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/events"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/feedback"
 	generativeAI "github.com/FACorreiaa/go-poi-au-suggestions/internal/api/generative_ai"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/interests"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/partners"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/poi"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/profiles"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/tags"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/travel"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/weather"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
 )
 
 const (
 	model              = "gemini-2.0-flash"
 	defaultTemperature = 0.5
+	// minRAGConfidence is the lowest confidence at which an uncited RAG answer is still shown
+	minRAGConfidence = 0.4
+	// Page sizes of the chat session history
+	defaultSessionPageLimit = 50
+	maxSessionPageLimit     = 100
 )
 
 type ChatSession struct {
@@ -48,17 +63,33 @@ type LlmInteractiontService interface {
 	GetIteneraryResponse(ctx context.Context, cityName string, userID, profileID uuid.UUID, userLocation *types.UserLocation) (*types.AiCityResponse, error)
 	SaveItenerary(ctx context.Context, userID uuid.UUID, req types.BookmarkRequest) (uuid.UUID, error)
 	RemoveItenerary(ctx context.Context, userID, itineraryID uuid.UUID) error
+	// EstimateBudget prices POIs and an optional hotel stay against the
+	// user's budget, in the city's currency.
+	EstimateBudget(ctx context.Context, userID uuid.UUID, req types.CostEstimateRequest) (*types.CostEstimate, error)
 	GetPOIDetailedInfosResponse(ctx context.Context, userID uuid.UUID, city string, lat, lon float64) (*types.POIDetailedInfo, error)
 
 	// hotels
 	GetHotelsByPreferenceResponse(ctx context.Context, userID uuid.UUID, city string, lat, lon float64, userPreferences types.HotelUserPreferences) ([]types.HotelDetailedInfo, error)
 	GetHotelsNearbyResponse(ctx context.Context, userID uuid.UUID, city string, userLocation *types.UserLocation) ([]types.HotelDetailedInfo, error)
 	GetHotelByIDResponse(ctx context.Context, hotelID uuid.UUID) (*types.HotelDetailedInfo, error)
+	// SearchHotels returns a page of hotels matching the search filters, from
+	// the database first and the LLM only when the city has too few.
+	SearchHotels(ctx context.Context, userID uuid.UUID, params types.HotelSearchParameters) (*types.PaginatedHotelResponse, error)
 
 	// restaurants
 	GetRestaurantsByPreferencesResponse(ctx context.Context, userID uuid.UUID, city string, lat, lon float64, preferences types.RestaurantUserPreferences) ([]types.RestaurantDetailedInfo, error)
 	GetRestaurantsNearbyResponse(ctx context.Context, userID uuid.UUID, city string, userLocation types.UserLocation) ([]types.RestaurantDetailedInfo, error)
 	GetRestaurantDetailsResponse(ctx context.Context, restaurantID uuid.UUID) (*types.RestaurantDetailedInfo, error)
+	// SearchRestaurants returns a page of restaurants matching the search
+	// filters and the dining preferences of the given search profile, from the
+	// database first and the LLM only when the city has too few.
+	SearchRestaurants(ctx context.Context, userID uuid.UUID, params types.RestaurantSearchParameters) (*types.PaginatedRestaurantResponse, error)
+	// ReportRestaurantDietary records a user's correction of a restaurant's
+	// diet or allergen attribute and returns the restaurant as now known.
+	ReportRestaurantDietary(ctx context.Context, userID, restaurantID uuid.UUID, report types.DietaryReport) (*types.RestaurantDetailedInfo, error)
+	// BookingOffers returns the booking partners' offers for a hotel or
+	// restaurant, hotel offers ranked by the user's accommodation preferences.
+	BookingOffers(ctx context.Context, userID uuid.UUID, targetKind string, targetID uuid.UUID, req types.BookingRequest) []types.PartnerOffer
 
 	StartNewSession(ctx context.Context, userID, profileID uuid.UUID, cityName, message string, userLocation *types.UserLocation) (uuid.UUID, *types.AiCityResponse, error)
 	ContinueSession(ctx context.Context, sessionID uuid.UUID, message string, userLocation *types.UserLocation) (*types.AiCityResponse, error)
@@ -71,11 +102,11 @@ type LlmInteractiontService interface {
 		eventCh chan<- types.StreamEvent, // Channel to send events back
 	) error
 
-	ProcessUnifiedChatMessage(ctx context.Context, userID, profileID uuid.UUID, cityName, message string, userLocation *types.UserLocation) (interface{}, error)
-	ProcessUnifiedChatMessageStream(ctx context.Context, userID, profileID uuid.UUID, cityName, message string, userLocation *types.UserLocation, eventCh chan<- types.StreamEvent) error
+	ProcessUnifiedChatMessage(ctx context.Context, userID, profileID uuid.UUID, cityName, message string, userLocation *types.UserLocation, travelDates *types.TravelDates) (interface{}, error)
+	ProcessUnifiedChatMessageStream(ctx context.Context, userID, profileID uuid.UUID, cityName, message string, userLocation *types.UserLocation, travelDates *types.TravelDates, eventCh chan<- types.StreamEvent) error
 
 	// Chat session management
-	GetUserChatSessions(ctx context.Context, userID uuid.UUID) ([]types.ChatSession, error)
+	GetUserChatSessions(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]types.ChatSession, *types.Cursor, error)
 
 	// // Context-aware chat methods
 	// StartNewSessionWithContext(ctx context.Context, userID, profileID uuid.UUID, cityName, message string, userLocation *types.UserLocation, contextType types.ChatContextType) (uuid.UUID, *types.AiCityResponse, error)
@@ -112,6 +143,12 @@ type ServiceImpl struct {
 	llmInteractionRepo Repository
 	cityRepo           city.Repository
 	poiRepo            poi.Repository
+	feedbackRepo       feedback.Repository
+	weather            weather.WeatherProvider // nil when forecasts are unavailable
+	travel             travel.Service          // nil when travel times are not estimated
+	events             events.Service          // nil when itineraries leave out city events
+	partners           partners.Service        // nil when details carry no booking offers
+	analytics          analytics.Emitter       // nil when no analytics are recorded
 	cache              *cache.Cache
 
 	// events
@@ -127,6 +164,12 @@ func NewLlmInteractiontService(interestRepo interests.Repository,
 	llmInteractionRepo Repository,
 	cityRepo city.Repository,
 	poiRepo poi.Repository,
+	feedbackRepo feedback.Repository,
+	weatherProvider weather.WeatherProvider,
+	travelService travel.Service,
+	eventsService events.Service,
+	partnersService partners.Service,
+	emitter analytics.Emitter,
 	logger *slog.Logger) *ServiceImpl {
 	ctx := context.Background()
 	aiClient, _ := generativeAI.NewAIClient(ctx)
@@ -138,7 +181,7 @@ func NewLlmInteractiontService(interestRepo interests.Repository,
 	}
 
 	// Initialize RAG service
-	ragService, err := generativeAI.NewRAGService(ctx, logger)
+	ragService, err := generativeAI.NewRAGService(ctx, poiRepo, logger)
 	if err != nil {
 		log.Fatalf("Failed to create RAG service: %v", err) // Terminate if initialization fails
 	}
@@ -156,6 +199,12 @@ func NewLlmInteractiontService(interestRepo interests.Repository,
 		llmInteractionRepo: llmInteractionRepo,
 		cityRepo:           cityRepo,
 		poiRepo:            poiRepo,
+		feedbackRepo:       feedbackRepo,
+		weather:            weatherProvider,
+		travel:             travelService,
+		events:             eventsService,
+		partners:           partnersService,
+		analytics:          emitter,
 		cache:              cache,
 		deadLetterCh:       make(chan types.StreamEvent, 100),
 		intentClassifier:   &types.SimpleIntentClassifier{},
@@ -240,6 +289,7 @@ func (l *ServiceImpl) GenerateCityDataWorker(wg *sync.WaitGroup,
 			CityDescription: cityDataFromAI.Description,
 			Latitude:        cityDataFromAI.CenterLatitude,
 			Longitude:       cityDataFromAI.CenterLongitude,
+			Forecast:        l.forecastFor(ctx, cityDataFromAI.CenterLatitude, cityDataFromAI.CenterLongitude),
 			// BoundingBoxWKT: cityDataFromAI.BoundingBox, // TODO
 		}
 	}()
@@ -358,6 +408,7 @@ func (l *ServiceImpl) GeneratePersonalisedPOIWorker(wg *sync.WaitGroup, ctx cont
 		resultCh <- types.GenAIResponse{Err: fmt.Errorf("failed to parse personalized itinerary JSON: %w", err)}
 		return
 	}
+	itineraryData.PointsOfInterest = l.personalizePOIs(ctx, userID, profileID, itineraryData.PointsOfInterest)
 	span.SetAttributes(
 		attribute.String("itinerary.name", itineraryData.ItineraryName),
 		attribute.Int("personalized_pois.count", len(itineraryData.PointsOfInterest)),
@@ -414,6 +465,9 @@ func (l *ServiceImpl) GeneratePersonalisedPOIWorkerWithSemantics(wg *sync.WaitGr
 
 	startTime := time.Now()
 
+	// List the POIs closest to the user's taste first in the prompt
+	semanticPOIs = l.personalizePOIs(ctx, userID, profileID, semanticPOIs)
+
 	// Create enhanced prompt with semantic context
 	prompt := l.getPersonalizedPOIWithSemanticContext(interestNames, cityName, tagsPromptPart, userPrefs, semanticPOIs)
 	span.SetAttributes(attribute.Int("prompt.length", len(prompt)))
@@ -455,6 +509,7 @@ func (l *ServiceImpl) GeneratePersonalisedPOIWorkerWithSemantics(wg *sync.WaitGr
 		resultCh <- types.GenAIResponse{Err: fmt.Errorf("failed to parse semantic-enhanced personalized itinerary JSON: %w", err)}
 		return
 	}
+	itineraryData.PointsOfInterest = l.personalizePOIs(ctx, userID, profileID, itineraryData.PointsOfInterest)
 	span.SetAttributes(
 		attribute.String("itinerary.name", itineraryData.ItineraryName),
 		attribute.Int("personalized_pois.count", len(itineraryData.PointsOfInterest)),
@@ -614,6 +669,20 @@ func (l *ServiceImpl) PreparePromptData(interests []*types.Interest, tags []*typ
 	return interestNames, tagsPromptPart, userPrefs
 }
 
+// learnedPreferences returns the prompt part describing what the user's
+// activity says about their taste. Failures only cost personalization, so they
+// are logged and yield an empty string.
+func (l *ServiceImpl) learnedPreferences(ctx context.Context, userID uuid.UUID) string {
+	learned, err := l.feedbackRepo.LearnedPreferences(ctx, userID)
+	if err != nil {
+		l.logger.WarnContext(ctx, "Failed to fetch learned preferences",
+			slog.Any("error", err),
+			slog.String("user_id", userID.String()))
+		return ""
+	}
+	return getLearnedPreferencesPrompt(learned)
+}
+
 // RAG-enhanced methods for improved responses using semantic search
 
 // SearchRelevantPOIsForRAG searches for POIs semantically similar to the user's query
@@ -867,6 +936,7 @@ func (l *ServiceImpl) CollectResults(resultCh <-chan types.GenAIResponse) (itine
 			itinerary.GeneralCityData.StateProvince = res.StateProvince
 			itinerary.GeneralCityData.CenterLatitude = res.Latitude
 			itinerary.GeneralCityData.CenterLongitude = res.Longitude
+			itinerary.GeneralCityData.Forecast = res.Forecast
 		}
 		if res.ItineraryName != "" {
 			itinerary.AIItineraryResponse.ItineraryName = res.ItineraryName
@@ -937,6 +1007,7 @@ func (l *ServiceImpl) HandlePersonalisedPOIs(ctx context.Context, pois []types.P
 		l.logger.ErrorContext(ctx, "Failed to fetch sorted POIs", slog.Any("error", err))
 		return pois, nil // Return unsorted POIs
 	}
+	carryAccessibility(pois, sortedPois)
 	return sortedPois, nil
 }
 
@@ -960,6 +1031,7 @@ func (l *ServiceImpl) GetIteneraryResponse(ctx context.Context, cityName string,
 
 	// Prepare prompt data
 	interestNames, tagsPromptPart, userPrefs := l.PreparePromptData(interests, tags, searchProfile)
+	userPrefs += l.learnedPreferences(ctx, userID)
 	span.SetAttributes(
 		attribute.Int("interests.count", len(interestNames)),
 		attribute.Int("tags.count", len(tags)),
@@ -1021,6 +1093,7 @@ func (l *ServiceImpl) GetIteneraryResponse(ctx context.Context, cityName string,
 	span.SetAttributes(attribute.Int("general_pois.count", len(itinerary.PointsOfInterest)))
 
 	// Handle personalized POIs
+	rawPersonalisedPOIs = l.honourAccessibility(ctx, searchProfile.AccessibilityNeeds, rawPersonalisedPOIs)
 	sortedPois, err := l.HandlePersonalisedPOIs(ctx, rawPersonalisedPOIs, cityID, userLocation, llmInteractionID, userID, profileID)
 	if err != nil {
 		span.RecordError(err)
@@ -1028,8 +1101,13 @@ func (l *ServiceImpl) GetIteneraryResponse(ctx context.Context, cityName string,
 		return nil, err
 	}
 	itinerary.AIItineraryResponse.PointsOfInterest = sortedPois
+	l.adaptToWeather(ctx, &itinerary, searchProfile, nil)
+	l.planTravel(ctx, &itinerary, searchProfile)
+	itinerary.AIItineraryResponse.Budget = estimateCost(&itinerary, searchProfile)
 	span.SetAttributes(
 		attribute.Int("personalized_pois.count", len(sortedPois)),
+		attribute.Int("weather_swaps.count", len(itinerary.AIItineraryResponse.WeatherSwaps)),
+		attribute.Int("travel.total_minutes", itinerary.AIItineraryResponse.TotalTravelMinutes),
 		attribute.String("llm_interaction.id", llmInteractionID.String()),
 	)
 
@@ -1486,14 +1564,37 @@ func (l *ServiceImpl) SaveItenerary(ctx context.Context, userID uuid.UUID, req t
 		Tags:                   req.Tags,
 		IsPublic:               isPublic,
 	}
+
+	// Fetch the suggested POIs first so the saved itinerary carries their cost level
+	cityID := newBookmark.PrimaryCityID
+	var pois []types.POIDetailedInfo
+	var poisErr error
+	if cityID != uuid.Nil {
+		pois, poisErr = l.llmInteractionRepo.GetLlmSuggestedPOIsByInteractionSortedByDistance(ctx, req.LlmInteractionID, cityID, types.UserLocation{})
+		if poisErr != nil {
+			l.logger.WarnContext(ctx, "Failed to fetch suggested POIs", slog.Any("error", poisErr))
+			span.RecordError(poisErr)
+		} else {
+			newBookmark.EstimatedCostLevel = l.costLevel(ctx, userID, cityID, pois)
+		}
+	}
+
 	savedID, err := l.llmInteractionRepo.AddChatToBookmark(ctx, newBookmark)
 	if err != nil {
 		span.RecordError(err)
 		return uuid.Nil, err
 	}
+	saved := types.AnalyticsEvent{
+		Name:       types.AnalyticsItinerarySaved,
+		UserID:     &userID,
+		Properties: map[string]any{"itinerary_id": savedID.String()},
+	}
+	if cityID != uuid.Nil {
+		saved.CityID = &cityID
+	}
+	l.emit(ctx, saved)
 
 	// Fetch city ID (assuming PrimaryCityID is available; otherwise, derive from interaction)
-	cityID := newBookmark.PrimaryCityID
 	if cityID == uuid.Nil {
 		// Fallback: Get city from LLM interaction context if possible
 		l.logger.WarnContext(ctx, "PrimaryCityID not provided, deriving from interaction")
@@ -1509,11 +1610,7 @@ func (l *ServiceImpl) SaveItenerary(ctx context.Context, userID uuid.UUID, req t
 		return savedID, nil // Continue even if this fails
 	}
 
-	// Fetch POIs from llm_suggested_pois
-	pois, err := l.llmInteractionRepo.GetLlmSuggestedPOIsByInteractionSortedByDistance(ctx, req.LlmInteractionID, cityID, types.UserLocation{})
-	if err != nil {
-		l.logger.WarnContext(ctx, "Failed to fetch suggested POIs", slog.Any("error", err))
-		span.RecordError(err)
+	if poisErr != nil {
 		return savedID, nil
 	}
 
@@ -1529,6 +1626,17 @@ func (l *ServiceImpl) SaveItenerary(ctx context.Context, userID uuid.UUID, req t
 		return savedID, nil
 	}
 
+	// Every POI of a saved itinerary is one the user chose to keep
+	events := make([]types.InteractionEvent, 0, len(pois))
+	for _, p := range pois {
+		poiID := p.ID
+		events = append(events, types.InteractionEvent{UserID: userID, POIID: &poiID, POIName: p.Name, Category: p.Category, Kind: types.InteractionItinerarySave})
+	}
+	if err := l.feedbackRepo.Record(ctx, events...); err != nil {
+		l.logger.WarnContext(ctx, "Failed to record itinerary feedback", slog.Any("error", err))
+		span.RecordError(err)
+	}
+
 	l.logger.InfoContext(ctx, "Successfully saved itinerary",
 		slog.String("savedItineraryID", savedID.String()),
 		slog.String("itineraryID", itineraryID.String()))
@@ -1537,6 +1645,24 @@ func (l *ServiceImpl) SaveItenerary(ctx context.Context, userID uuid.UUID, req t
 	return savedID, nil
 }
 
+// costLevel estimates the cost level of an itinerary's POIs, priced against
+// the user's default profile when there is one. It is null without POIs.
+func (l *ServiceImpl) costLevel(ctx context.Context, userID, cityID uuid.UUID, pois []types.POIDetailedInfo) sql.NullInt32 {
+	if len(pois) == 0 {
+		return sql.NullInt32{}
+	}
+	in := budget.Input{POIs: pois}
+	if city, err := l.cityRepo.GetCityByID(ctx, cityID); err != nil {
+		l.logger.WarnContext(ctx, "Failed to fetch city for cost estimate, pricing in euros", slog.Any("error", err))
+	} else {
+		in.Country = city.Country
+	}
+	if profile, err := l.searchProfileRepo.GetDefaultSearchProfile(ctx, userID); err == nil {
+		in.Profile = profile
+	}
+	return sql.NullInt32{Int32: int32(budget.Estimate(in).CostLevel), Valid: true}
+}
+
 func (l *ServiceImpl) RemoveItenerary(ctx context.Context, userID, itineraryID uuid.UUID) error {
 	ctx, span := otel.Tracer("LlmInteractionService").Start(ctx, "RemoveItenerary", trace.WithAttributes(
 		attribute.String("user.id", userID.String()),
@@ -1559,8 +1685,8 @@ func (l *ServiceImpl) RemoveItenerary(ctx context.Context, userID, itineraryID u
 	return nil
 }
 
-// GetUserChatSessions retrieves all chat sessions for a user
-func (l *ServiceImpl) GetUserChatSessions(ctx context.Context, userID uuid.UUID) ([]types.ChatSession, error) {
+// GetUserChatSessions retrieves a page of chat sessions for a user
+func (l *ServiceImpl) GetUserChatSessions(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]types.ChatSession, *types.Cursor, error) {
 	ctx, span := otel.Tracer("LlmInteractionService").Start(ctx, "GetUserChatSessions", trace.WithAttributes(
 		attribute.String("user.id", userID.String()),
 	))
@@ -1569,12 +1695,12 @@ func (l *ServiceImpl) GetUserChatSessions(ctx context.Context, userID uuid.UUID)
 	l.logger.InfoContext(ctx, "Retrieving chat sessions for user",
 		slog.String("userID", userID.String()))
 
-	sessions, err := l.llmInteractionRepo.GetUserChatSessions(ctx, userID)
+	sessions, next, err := l.llmInteractionRepo.GetUserChatSessions(ctx, userID, page.Clamp(defaultSessionPageLimit, maxSessionPageLimit))
 	if err != nil {
 		l.logger.ErrorContext(ctx, "Failed to get user chat sessions", slog.Any("error", err))
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "Failed to get user chat sessions")
-		return nil, fmt.Errorf("failed to get user chat sessions: %w", err)
+		return nil, nil, fmt.Errorf("failed to get user chat sessions: %w", err)
 	}
 
 	l.logger.InfoContext(ctx, "Successfully retrieved chat sessions",
@@ -1582,7 +1708,7 @@ func (l *ServiceImpl) GetUserChatSessions(ctx context.Context, userID uuid.UUID)
 		slog.Int("sessionCount", len(sessions)))
 	span.SetAttributes(attribute.Int("sessions.count", len(sessions)))
 	span.SetStatus(codes.Ok, "Chat sessions retrieved successfully")
-	return sessions, nil
+	return sessions, next, nil
 }
 
 // getPOIDetailedInfos returns a formatted string with POI details.
@@ -1704,6 +1830,7 @@ func (l *ServiceImpl) GetPOIDetailedInfosResponse(ctx context.Context, userID uu
 	if cached, found := l.cache.Get(cacheKey); found {
 		if poi, ok := cached.(*types.POIDetailedInfo); ok {
 			l.logger.InfoContext(ctx, "Cache hit for POI details", slog.String("cache_key", cacheKey))
+			l.emitPOIViewed(ctx, userID, poi.ID, poi.CityID)
 			span.AddEvent("Cache hit")
 			span.SetStatus(codes.Ok, "POI details served from cache")
 			return poi, nil
@@ -1735,6 +1862,7 @@ func (l *ServiceImpl) GetPOIDetailedInfosResponse(ctx context.Context, userID uu
 		poi.City = city
 		l.cache.Set(cacheKey, poi, cache.DefaultExpiration)
 		l.logger.InfoContext(ctx, "Database hit for POI details", slog.String("cache_key", cacheKey))
+		l.emitPOIViewed(ctx, userID, poi.ID, cityID)
 		span.AddEvent("Database hit")
 		span.SetStatus(codes.Ok, "POI details served from database")
 		return poi, nil
@@ -1774,11 +1902,13 @@ func (l *ServiceImpl) GetPOIDetailedInfosResponse(ctx context.Context, userID uu
 	}
 
 	// Save to database
-	_, err = l.poiRepo.SavePoi(ctx, *poiResult, cityID)
+	savedPOIID, err := l.poiRepo.SavePoi(ctx, *poiResult, cityID)
 	if err != nil {
 		l.logger.WarnContext(ctx, "Failed to save POI details to database", slog.Any("error", err))
 		span.RecordError(err)
 		// Continue despite error to avoid blocking user
+	} else {
+		l.emitPOIViewed(ctx, userID, savedPOIID, cityID)
 	}
 
 	// Store in cache
@@ -1817,6 +1947,7 @@ func (l *ServiceImpl) StartNewSession(ctx context.Context, userID, profileID uui
 
 	// Prepare prompt data
 	interestNames, tagsPromptPart, userPrefs := l.PreparePromptData(interests, tags, searchProfile)
+	userPrefs += l.learnedPreferences(ctx, userID)
 	span.SetAttributes(
 		attribute.Int("interests.count", len(interestNames)),
 		attribute.Int("tags.count", len(tags)),
@@ -1902,6 +2033,7 @@ func (l *ServiceImpl) StartNewSession(ctx context.Context, userID, profileID uui
 	span.SetAttributes(attribute.Int("general_pois.count", len(itinerary.PointsOfInterest)))
 
 	// Handle personalized POIs
+	rawPersonalisedPOIs = l.honourAccessibility(ctx, searchProfile.AccessibilityNeeds, rawPersonalisedPOIs)
 	sortedPois, err := l.HandlePersonalisedPOIs(ctx, rawPersonalisedPOIs, cityID, userLocation, llmInteractionID, userID, uuid.Nil)
 	if err != nil {
 		span.RecordError(err)
@@ -1910,8 +2042,13 @@ func (l *ServiceImpl) StartNewSession(ctx context.Context, userID, profileID uui
 		return uuid.Nil, nil, err
 	}
 	itinerary.AIItineraryResponse.PointsOfInterest = sortedPois
+	l.adaptToWeather(ctx, &itinerary, searchProfile, nil)
+	l.planTravel(ctx, &itinerary, searchProfile)
+	itinerary.AIItineraryResponse.Budget = estimateCost(&itinerary, searchProfile)
 	span.SetAttributes(
 		attribute.Int("personalized_pois.count", len(sortedPois)),
+		attribute.Int("weather_swaps.count", len(itinerary.AIItineraryResponse.WeatherSwaps)),
+		attribute.Int("travel.total_minutes", itinerary.AIItineraryResponse.TotalTravelMinutes),
 		attribute.String("llm_interaction.id", llmInteractionID.String()),
 	)
 
@@ -1943,6 +2080,8 @@ func (l *ServiceImpl) StartNewSession(ctx context.Context, userID, profileID uui
 		return uuid.Nil, nil, fmt.Errorf("failed to save session: %w", err)
 	}
 
+	l.emitChatStarted(ctx, userID, &sessionID, cityName, string(types.DomainItinerary))
+
 	l.logger.InfoContext(ctx, "New session started",
 		slog.String("sessionID", sessionID.String()),
 		slog.String("itinerary_name", itinerary.AIItineraryResponse.ItineraryName),
@@ -2049,7 +2188,7 @@ func (l *ServiceImpl) ContinueSession(ctx context.Context, sessionID uuid.UUID,
 			responseText = fmt.Sprintf("Could not find %s in your itinerary.", poiName)
 		}
 	case "ask_question":
-		responseText = "I’m here to help! For now, I’ll assume you’re asking about your trip. What specifically would you like to know?"
+		responseText = l.handleSemanticQuestion(ctx, message, session, city, semanticPOIs)
 	default: // modify_itinerary
 		if matches := regexp.MustCompile(`replace\s+(.+?)\s+with\s+(.+?)(?:\s+in\s+my\s+itinerary)?`).FindStringSubmatch(strings.ToLower(message)); len(matches) == 3 {
 			oldPOI := matches[1]
@@ -2219,6 +2358,256 @@ func (l *ServiceImpl) generatePOIData(ctx context.Context, poiName, cityName str
 	return poiData, nil
 }
 
+// personalizePOIs re-ranks pois by their fit with the taste vector of the given
+// search profile, or the user's default one when profileID is uuid.Nil, and with
+// what the user has kept and removed before.
+// Ranking is best effort; on failure the original order is kept.
+func (l *ServiceImpl) personalizePOIs(ctx context.Context, userID, profileID uuid.UUID, pois []types.POIDetailedInfo) []types.POIDetailedInfo {
+	if userID == uuid.Nil || len(pois) < 2 {
+		return pois
+	}
+	scores, err := l.poiRepo.PreferenceScores(ctx, userID, profileID, pois)
+	if err != nil {
+		l.logger.WarnContext(ctx, "Failed to personalize POI ranking",
+			slog.Any("error", err),
+			slog.String("user_id", userID.String()))
+		return pois
+	}
+	return poi.RerankByPreference(pois, scores, poi.PreferenceRerankWeight)
+}
+
+// honourAccessibility marks pois with how they meet the user's accessibility
+// needs and leaves out those the needs do not allow. What is stored about a POI
+// overrides what the LLM said about it; features neither knows about are
+// unknown, so such POIs are kept but marked as such unless only accessible
+// ones are wanted.
+func (l *ServiceImpl) honourAccessibility(ctx context.Context, needs *types.AccessibilityNeeds, pois []types.POIDetailedInfo) []types.POIDetailedInfo {
+	if !needs.Any() || len(pois) == 0 {
+		return pois
+	}
+	known, err := l.poiRepo.KnownAccessibility(ctx, pois)
+	if err != nil {
+		// Fall back to what the LLM said
+		l.logger.WarnContext(ctx, "Failed to look up POI accessibility", slog.Any("error", err))
+	}
+
+	kept := make([]types.POIDetailedInfo, 0, len(pois))
+	for i, p := range pois {
+		if stored, ok := known[i]; ok {
+			accessibility := make(map[string]bool, len(p.Accessibility)+len(stored))
+			maps.Copy(accessibility, p.Accessibility)
+			maps.Copy(accessibility, stored)
+			p.Accessibility = accessibility
+		}
+		p.AccessStatus = needs.StatusOf(p.Accessibility)
+		if !needs.Allows(p.AccessStatus) {
+			l.logger.DebugContext(ctx, "Leaving out POI not meeting accessibility needs",
+				slog.String("name", p.Name), slog.String("status", p.AccessStatus))
+			continue
+		}
+		kept = append(kept, p)
+	}
+	return kept
+}
+
+// carryAccessibility copies the accessibility of pois onto the same POIs as
+// read back from llm_suggested_pois, which does not keep it.
+func carryAccessibility(pois, stored []types.POIDetailedInfo) {
+	byName := make(map[string]types.POIDetailedInfo, len(pois))
+	for _, p := range pois {
+		byName[strings.ToLower(p.Name)] = p
+	}
+	for i := range stored {
+		if p, ok := byName[strings.ToLower(stored[i].Name)]; ok {
+			stored[i].Accessibility = p.Accessibility
+			stored[i].AccessStatus = p.AccessStatus
+		}
+	}
+}
+
+// forecastFor returns the forecast at lat, lon, or nil when there is no weather
+// provider, the location is unknown or the forecast cannot be fetched.
+func (l *ServiceImpl) forecastFor(ctx context.Context, lat, lon float64) *types.WeatherForecast {
+	if l.weather == nil || (lat == 0 && lon == 0) {
+		return nil
+	}
+	forecast, err := l.weather.Forecast(ctx, lat, lon)
+	if err != nil {
+		l.logger.WarnContext(ctx, "Failed to fetch weather forecast",
+			slog.Float64("lat", lat), slog.Float64("lon", lon), slog.Any("error", err))
+		return nil
+	}
+	return forecast
+}
+
+// adaptToWeather puts the city's forecast on the itinerary and, when the
+// itinerary's days are rainy, swaps its outdoor stops for nearby indoor general
+// POIs. Those days are the travel dates when given, and today otherwise. Stops
+// are only swapped when every forecast travel day is rainy, since otherwise the
+// outdoor ones can be visited on a dry day; trips past the end of the forecast
+// are left alone. Users who prefer outdoor activities keep their stops whatever
+// the weather.
+func (l *ServiceImpl) adaptToWeather(ctx context.Context, itinerary *types.AiCityResponse, searchProfile *types.UserPreferenceProfileResponse, travelDates *types.TravelDates) {
+	cityData := &itinerary.GeneralCityData
+	if cityData.Forecast == nil {
+		cityData.Forecast = l.forecastFor(ctx, cityData.CenterLatitude, cityData.CenterLongitude)
+	}
+	if cityData.Forecast == nil {
+		return
+	}
+
+	var days []types.DailyForecast
+	var reason string
+	if travelDates != nil {
+		during, err := cityData.Forecast.During(*travelDates)
+		if err != nil {
+			l.logger.WarnContext(ctx, "Ignoring invalid travel dates", slog.Any("error", err))
+			travelDates = nil
+		} else {
+			days = during
+		}
+	}
+	if travelDates == nil {
+		cityData.Weather = cityData.Forecast.Summary()
+		if today, ok := cityData.Forecast.Today(); ok {
+			days = []types.DailyForecast{today}
+			reason = fmt.Sprintf("%s forecast today", today.Condition)
+		}
+	} else if len(days) > 0 {
+		cityData.Weather = types.SummarizeDays(days)
+		reason = fmt.Sprintf("%s forecast on %s", days[0].Condition, days[0].Date)
+		if len(days) > 1 {
+			reason = "rain forecast on every travel day"
+		}
+	}
+	if len(days) == 0 || slices.ContainsFunc(days, func(d types.DailyForecast) bool { return !d.Rainy() }) {
+		return
+	}
+	if searchProfile != nil && searchProfile.ActivityPreferences != nil &&
+		searchProfile.ActivityPreferences.IndoorOutdoorPref == "outdoor" {
+		return
+	}
+
+	pois, swaps := swapOutdoorPOIs(itinerary.AIItineraryResponse.PointsOfInterest, itinerary.PointsOfInterest, reason)
+	itinerary.AIItineraryResponse.PointsOfInterest = pois
+	itinerary.AIItineraryResponse.WeatherSwaps = swaps
+	if len(swaps) > 0 {
+		l.logger.InfoContext(ctx, "Adapted itinerary to the weather",
+			slog.String("reason", reason), slog.Int("swaps", len(swaps)))
+	}
+}
+
+// planTravel estimates the trip between consecutive itinerary stops by the
+// profile's preferred transport. Run it after the stops are final, since
+// weather swaps change them.
+func (l *ServiceImpl) planTravel(ctx context.Context, itinerary *types.AiCityResponse, searchProfile *types.UserPreferenceProfileResponse) {
+	if l.travel == nil {
+		return
+	}
+	mode := types.TransportPreferenceAny
+	if searchProfile != nil && searchProfile.PreferredTransport != "" {
+		mode = searchProfile.PreferredTransport
+	}
+	legs := l.travel.Legs(ctx, itinerary.AIItineraryResponse.PointsOfInterest, mode)
+	itinerary.AIItineraryResponse.Legs = legs
+	itinerary.AIItineraryResponse.TotalTravelMinutes = types.TotalTravelMinutes(legs)
+}
+
+// eventsDuring returns the city's events on the travel days, those matching
+// the profile's local events interests first, or nil without travel dates.
+func (l *ServiceImpl) eventsDuring(ctx context.Context, cityName string, travelDates *types.TravelDates, searchProfile *types.UserPreferenceProfileResponse) []types.Event {
+	if l.events == nil || travelDates == nil || cityName == "" {
+		return nil
+	}
+	from, to, err := travelDates.Range(time.UTC)
+	if err != nil {
+		l.logger.WarnContext(ctx, "Ignoring invalid travel dates", slog.Any("error", err))
+		return nil
+	}
+	cityID, err := l.cityRepo.GetCityIDByName(ctx, cityName)
+	if err != nil || cityID == uuid.Nil {
+		l.logger.WarnContext(ctx, "Failed to look up city for events",
+			slog.String("city", cityName), slog.Any("error", err))
+		return nil
+	}
+	var interests []string
+	if searchProfile != nil && searchProfile.ActivityPreferences != nil {
+		interests = searchProfile.ActivityPreferences.LocalEventsInterest
+	}
+	return l.events.During(ctx, cityID, from, to, interests)
+}
+
+// estimateCost prices the itinerary's stops in the city's currency against
+// the profile's budget.
+func estimateCost(itinerary *types.AiCityResponse, searchProfile *types.UserPreferenceProfileResponse) *types.CostEstimate {
+	return budget.Estimate(budget.Input{
+		Country: itinerary.GeneralCityData.Country,
+		POIs:    itinerary.AIItineraryResponse.PointsOfInterest,
+		Profile: searchProfile,
+	})
+}
+
+// swapOutdoorPOIs replaces each outdoor POI with the nearest indoor one among
+// alternatives that is not already in pois. Outdoor POIs without an indoor
+// alternative left are kept.
+func swapOutdoorPOIs(pois, alternatives []types.POIDetailedInfo, reason string) ([]types.POIDetailedInfo, []types.WeatherSwap) {
+	used := make(map[string]bool, len(pois))
+	for _, p := range pois {
+		used[strings.ToLower(p.Name)] = true
+	}
+
+	var swaps []types.WeatherSwap
+	adapted := make([]types.POIDetailedInfo, len(pois))
+	for i, p := range pois {
+		adapted[i] = p
+		if !types.IsOutdoor(p) {
+			continue
+		}
+		best, bestDist := -1, math.Inf(1)
+		for j, alt := range alternatives {
+			if used[strings.ToLower(alt.Name)] || !types.IsIndoor(alt) {
+				continue
+			}
+			if d := approxDistance(p, alt); best == -1 || d < bestDist {
+				best, bestDist = j, d
+			}
+		}
+		if best == -1 {
+			continue
+		}
+		replacement := alternatives[best]
+		used[strings.ToLower(replacement.Name)] = true
+		adapted[i] = replacement
+		swaps = append(swaps, types.WeatherSwap{Replaced: p.Name, Replacement: replacement.Name, Reason: reason})
+	}
+	return adapted, swaps
+}
+
+// approxDistance ranks how far apart two POIs are; good enough within a city.
+// POIs without coordinates are infinitely far.
+func approxDistance(a, b types.POIDetailedInfo) float64 {
+	if (a.Latitude == 0 && a.Longitude == 0) || (b.Latitude == 0 && b.Longitude == 0) {
+		return math.Inf(1)
+	}
+	dx := (b.Longitude - a.Longitude) * math.Cos(a.Latitude*math.Pi/180)
+	dy := b.Latitude - a.Latitude
+	return dx*dx + dy*dy
+}
+
+// embedPOIQuery embeds text with the model POI embeddings are currently
+// searched with, so it can be compared against stored vectors.
+func (l *ServiceImpl) embedPOIQuery(ctx context.Context, text string) ([]float32, types.EmbeddingModel, error) {
+	model, err := l.poiRepo.ActiveEmbeddingModel(ctx)
+	if err != nil {
+		return nil, types.EmbeddingModel{}, err
+	}
+	embedding, err := l.embeddingService.WithModel(model).GenerateQueryEmbedding(ctx, text)
+	if err != nil {
+		return nil, types.EmbeddingModel{}, err
+	}
+	return embedding, model, nil
+}
+
 // enhancePOIRecommendationsWithSemantics uses embeddings to find similar POIs and enrich recommendations
 func (l *ServiceImpl) enhancePOIRecommendationsWithSemantics(ctx context.Context, userMessage string, cityID uuid.UUID, userPreferences []string, limit int) ([]types.POIDetailedInfo, error) {
 	ctx, span := otel.Tracer("LlmInteractionService").Start(ctx, "enhancePOIRecommendationsWithSemantics", trace.WithAttributes(
@@ -2244,7 +2633,7 @@ func (l *ServiceImpl) enhancePOIRecommendationsWithSemantics(ctx context.Context
 		searchQuery += " " + strings.Join(userPreferences, " ")
 	}
 
-	queryEmbedding, err := l.embeddingService.GenerateQueryEmbedding(ctx, searchQuery)
+	queryEmbedding, model, err := l.embedPOIQuery(ctx, searchQuery)
 	if err != nil {
 		l.logger.ErrorContext(ctx, "Failed to generate query embedding",
 			slog.Any("error", err),
@@ -2255,7 +2644,7 @@ func (l *ServiceImpl) enhancePOIRecommendationsWithSemantics(ctx context.Context
 	}
 
 	// Search for similar POIs in the city
-	similarPOIs, err := l.poiRepo.FindSimilarPOIsByCity(ctx, queryEmbedding, cityID, limit)
+	similarPOIs, err := l.poiRepo.FindSimilarPOIsByCity(ctx, queryEmbedding, model, cityID, limit)
 	if err != nil {
 		l.logger.ErrorContext(ctx, "Failed to find similar POIs", slog.Any("error", err))
 		span.RecordError(err)
@@ -2310,7 +2699,7 @@ func (l *ServiceImpl) enhanceConversationContextWithSemantics(ctx context.Contex
 	}
 
 	// Generate embedding for the user message
-	messageEmbedding, err := l.embeddingService.GenerateQueryEmbedding(ctx, userMessage)
+	messageEmbedding, model, err := l.embedPOIQuery(ctx, userMessage)
 	if err != nil {
 		l.logger.ErrorContext(ctx, "Failed to generate message embedding", slog.Any("error", err))
 		span.RecordError(err)
@@ -2318,7 +2707,7 @@ func (l *ServiceImpl) enhanceConversationContextWithSemantics(ctx context.Contex
 	}
 
 	// Find contextually relevant POIs
-	contextualPOIs, err := l.poiRepo.FindSimilarPOIsByCity(ctx, messageEmbedding, city.ID, 5)
+	contextualPOIs, err := l.poiRepo.FindSimilarPOIsByCity(ctx, messageEmbedding, model, city.ID, 5)
 	if err != nil {
 		l.logger.WarnContext(ctx, "Failed to find contextual POIs", slog.Any("error", err))
 		span.RecordError(err)
@@ -2377,7 +2766,7 @@ func (l *ServiceImpl) generateSemanticPOIRecommendations(ctx context.Context, us
 	}
 
 	// Generate embedding for user message
-	queryEmbedding, err := l.embeddingService.GenerateQueryEmbedding(ctx, userMessage)
+	queryEmbedding, model, err := l.embedPOIQuery(ctx, userMessage)
 	if err != nil {
 		l.logger.ErrorContext(ctx, "Failed to generate query embedding", slog.Any("error", err))
 		span.RecordError(err)
@@ -2395,15 +2784,18 @@ func (l *ServiceImpl) generateSemanticPOIRecommendations(ctx context.Context, us
 				Longitude: userLocation.UserLon,
 			},
 			Radius: userLocation.SearchRadiusKm,
+			Query:  userMessage,
 		}
 
-		hybridPOIs, err := l.poiRepo.SearchPOIsHybrid(ctx, filter, queryEmbedding, semanticWeight)
+		opts := types.DefaultHybridSearchOptions()
+		opts.Weights = types.HybridWeightsFromSemantic(semanticWeight)
+		hybridPOIs, err := l.poiRepo.SearchPOIsHybrid(ctx, filter, queryEmbedding, model, opts)
 		if err != nil {
 			l.logger.ErrorContext(ctx, "Failed to perform hybrid search", slog.Any("error", err))
 			span.RecordError(err)
 			// Fall back to semantic-only search
 		} else {
-			pois = hybridPOIs
+			pois = hybridPOIs.POIs
 			l.logger.InfoContext(ctx, "Used hybrid search for POI recommendations",
 				slog.Int("poi_count", len(pois)))
 			span.AddEvent("Used hybrid search")
@@ -2412,7 +2804,7 @@ func (l *ServiceImpl) generateSemanticPOIRecommendations(ctx context.Context, us
 
 	// If hybrid search failed or no location available, use semantic-only search
 	if len(pois) == 0 {
-		semanticPOIs, err := l.poiRepo.FindSimilarPOIsByCity(ctx, queryEmbedding, cityID, 10)
+		semanticPOIs, err := l.poiRepo.FindSimilarPOIsByCity(ctx, queryEmbedding, model, cityID, 10)
 		if err != nil {
 			l.logger.ErrorContext(ctx, "Failed to find similar POIs", slog.Any("error", err))
 			span.RecordError(err)
@@ -2425,31 +2817,7 @@ func (l *ServiceImpl) generateSemanticPOIRecommendations(ctx context.Context, us
 		span.AddEvent("Used semantic-only search")
 	}
 
-	// Generate embeddings for new POIs if needed
-	for i, poi := range pois {
-		if poi.ID == uuid.Nil {
-			continue
-		}
-
-		// Generate embedding for this POI if it doesn't have one
-		embedding, err := l.embeddingService.GeneratePOIEmbedding(ctx, poi.Name, poi.DescriptionPOI, poi.Category)
-		if err != nil {
-			l.logger.WarnContext(ctx, "Failed to generate embedding for POI",
-				slog.Any("error", err),
-				slog.String("poi_name", poi.Name))
-			continue
-		}
-
-		// Update POI with embedding
-		err = l.poiRepo.UpdatePOIEmbedding(ctx, poi.ID, embedding)
-		if err != nil {
-			l.logger.WarnContext(ctx, "Failed to update POI embedding",
-				slog.Any("error", err),
-				slog.String("poi_id", poi.ID.String()))
-		}
-
-		pois[i] = poi
-	}
+	pois = l.personalizePOIs(ctx, userID, uuid.Nil, pois)
 
 	l.logger.InfoContext(ctx, "Generated semantic POI recommendations",
 		slog.String("message", userMessage),
@@ -2552,6 +2920,12 @@ func (l *ServiceImpl) handleSemanticRemovePOI(ctx context.Context, message strin
 			strings.Contains(strings.ToLower(poiName), strings.ToLower(poi.Name)) {
 
 			removedName := poi.Name
+			poiID := poi.ID
+			event := types.InteractionEvent{UserID: session.UserID, POIID: &poiID, POIName: poi.Name, Category: poi.Category, Tags: poi.Tags, Kind: types.InteractionItineraryRemove}
+			if err := l.feedbackRepo.Record(ctx, event); err != nil {
+				l.logger.WarnContext(ctx, "Failed to record POI removal", slog.Any("error", err))
+				span.RecordError(err)
+			}
 			session.CurrentItinerary.AIItineraryResponse.PointsOfInterest = append(
 				session.CurrentItinerary.AIItineraryResponse.PointsOfInterest[:i],
 				session.CurrentItinerary.AIItineraryResponse.PointsOfInterest[i+1:]...,
@@ -2573,11 +2947,29 @@ func (l *ServiceImpl) handleSemanticRemovePOI(ctx context.Context, message strin
 		}(), ", "))
 }
 
-// handleSemanticQuestion handles questions with contextual semantic information
-func (l *ServiceImpl) handleSemanticQuestion(ctx context.Context, message string, session *types.ChatSession, semanticPOIs []types.POIDetailedInfo) string {
+// handleSemanticQuestion answers questions from our own POI data through the RAG
+// pipeline, falling back to listing semanticPOIs when no grounded answer is available.
+func (l *ServiceImpl) handleSemanticQuestion(ctx context.Context, message string, session *types.ChatSession, city *types.CityDetail, semanticPOIs []types.POIDetailedInfo) string {
 	ctx, span := otel.Tracer("LlmInteractionService").Start(ctx, "handleSemanticQuestion")
 	defer span.End()
 
+	if l.ragService != nil && city != nil {
+		ragResponse, err := l.ragService.Answer(ctx, message, city.ID, city.AiSummary, toConversationTurns(session.ConversationHistory))
+		if err != nil {
+			l.logger.WarnContext(ctx, "RAG answer failed, falling back to suggestions", slog.Any("error", err))
+			span.RecordError(err)
+		} else if len(ragResponse.SourcePOIs) > 0 || ragResponse.Confidence >= minRAGConfidence {
+			l.logger.InfoContext(ctx, "Answered question from RAG",
+				slog.Int("source_pois", len(ragResponse.SourcePOIs)),
+				slog.Float64("confidence", ragResponse.Confidence))
+			span.SetAttributes(
+				attribute.Int("rag.source_pois.count", len(ragResponse.SourcePOIs)),
+				attribute.Float64("rag.confidence", ragResponse.Confidence),
+			)
+			return formatRAGAnswer(ragResponse)
+		}
+	}
+
 	var response strings.Builder
 	response.WriteString("I'm here to help with your trip planning! ")
 
@@ -2761,7 +3153,7 @@ If no city is mentioned, use empty string for city.
 	return parsed.City, parsed.Message, nil
 }
 
-func (l *ServiceImpl) ProcessUnifiedChatMessage(ctx context.Context, userID, profileID uuid.UUID, cityName, message string, userLocation *types.UserLocation) (interface{}, error) {
+func (l *ServiceImpl) ProcessUnifiedChatMessage(ctx context.Context, userID, profileID uuid.UUID, cityName, message string, userLocation *types.UserLocation, travelDates *types.TravelDates) (interface{}, error) {
 	ctx, span := otel.Tracer("LlmInteractionService").Start(ctx, "ProcessUnifiedChatMessage", trace.WithAttributes(
 		attribute.String("message", message),
 	))
@@ -2787,6 +3179,7 @@ func (l *ServiceImpl) ProcessUnifiedChatMessage(ctx context.Context, userID, pro
 	domain := domainDetector.DetectDomain(ctx, cleanedMessage)
 	span.SetAttributes(attribute.String("detected.domain", string(domain)))
 	l.logger.InfoContext(ctx, "Detected domain", slog.String("domain", string(domain)))
+	l.emitChatStarted(ctx, userID, nil, cityName, string(domain))
 
 	// Step 3: Fetch user data
 	_, searchProfile, _, err := l.FetchUserData(ctx, userID, profileID)
@@ -2837,6 +3230,7 @@ func (l *ServiceImpl) ProcessUnifiedChatMessage(ctx context.Context, userID, pro
 				resultCh <- workerResult{Err: fmt.Errorf("failed to parse city data: %w", err)}
 				return
 			}
+			cityData.Forecast = l.forecastFor(ctx, cityData.CenterLatitude, cityData.CenterLongitude)
 			resultCh <- workerResult{Data: cityData}
 		}()
 
@@ -2982,6 +3376,11 @@ func (l *ServiceImpl) ProcessUnifiedChatMessage(ctx context.Context, userID, pro
 			span.RecordError(errors[0])
 			return nil, fmt.Errorf("itinerary processing errors: %v", errors)
 		}
+		itinerary.AIItineraryResponse.PointsOfInterest = l.honourAccessibility(ctx, searchProfile.AccessibilityNeeds, itinerary.AIItineraryResponse.PointsOfInterest)
+		l.adaptToWeather(ctx, &itinerary, searchProfile, travelDates)
+		l.planTravel(ctx, &itinerary, searchProfile)
+		itinerary.AIItineraryResponse.Budget = estimateCost(&itinerary, searchProfile)
+		itinerary.AIItineraryResponse.Events = l.eventsDuring(ctx, cityName, travelDates, searchProfile)
 		finalResponse = itinerary
 
 	case types.DomainAccommodation:
@@ -3027,6 +3426,7 @@ func (l *ServiceImpl) ProcessUnifiedChatMessage(ctx context.Context, userID, pro
 			if activityResponse, ok := result.Data.(struct {
 				Activities []types.POIDetailedInfo `json:"activities"`
 			}); ok {
+				activityResponse.Activities = l.honourAccessibility(ctx, searchProfile.AccessibilityNeeds, activityResponse.Activities)
 				finalResponse = activityResponse
 			}
 		}
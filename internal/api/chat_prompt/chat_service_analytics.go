@@ -0,0 +1,56 @@
+package llmChat
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+// emit records an analytics event, when analytics are recorded.
+func (l *ServiceImpl) emit(ctx context.Context, event types.AnalyticsEvent) {
+	if l.analytics == nil {
+		return
+	}
+	l.analytics.Emit(ctx, event)
+}
+
+// emitChatStarted records that the user started a chat about a city. Cities
+// not in the database yet are recorded without an ID.
+func (l *ServiceImpl) emitChatStarted(ctx context.Context, userID uuid.UUID, sessionID *uuid.UUID, cityName, domain string) {
+	if l.analytics == nil {
+		return
+	}
+	event := types.AnalyticsEvent{
+		Name:       types.AnalyticsChatStarted,
+		UserID:     &userID,
+		SessionID:  sessionID,
+		Properties: map[string]any{"city": cityName, "domain": domain},
+	}
+	if cityName != "" {
+		cityID, err := l.cityRepo.GetCityIDByName(ctx, cityName)
+		if err != nil {
+			l.logger.DebugContext(ctx, "City of chat not known", slog.String("city", cityName), slog.Any("error", err))
+		} else if cityID != uuid.Nil {
+			event.CityID = &cityID
+		}
+	}
+	l.analytics.Emit(ctx, event)
+}
+
+// emitPOIViewed records that the user opened the details of a POI.
+func (l *ServiceImpl) emitPOIViewed(ctx context.Context, userID, poiID, cityID uuid.UUID) {
+	if poiID == uuid.Nil {
+		return
+	}
+	event := types.AnalyticsEvent{Name: types.AnalyticsPOIViewed, POIID: &poiID}
+	if userID != uuid.Nil {
+		event.UserID = &userID
+	}
+	if cityID != uuid.Nil {
+		event.CityID = &cityID
+	}
+	l.emit(ctx, event)
+}
@@ -0,0 +1,64 @@
+package llmChat
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/budget"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+// EstimateBudget prices the requested POIs and hotel stay in the city's
+// currency, against the given search profile or the user's default one.
+func (l *ServiceImpl) EstimateBudget(ctx context.Context, userID uuid.UUID, req types.CostEstimateRequest) (*types.CostEstimate, error) {
+	ctx, span := otel.Tracer("LlmInteractionService").Start(ctx, "EstimateBudget", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+		attribute.String("city.name", req.City),
+		attribute.Int("pois.count", len(req.POIs)),
+	))
+	defer span.End()
+
+	if len(req.POIs) == 0 && req.Hotel == nil {
+		span.SetStatus(codes.Error, "Nothing to price")
+		return nil, fmt.Errorf("%w: points_of_interest or hotel is required", types.ErrBadRequest)
+	}
+
+	if req.Country == "" && req.City != "" {
+		city, err := l.cityRepo.FindCityByNameAndCountry(ctx, req.City, "")
+		if err != nil {
+			l.logger.WarnContext(ctx, "Failed to look up city for cost estimate, pricing in euros",
+				slog.String("city", req.City), slog.Any("error", err))
+		} else if city != nil {
+			req.Country = city.Country
+		}
+	}
+
+	in := budget.InputFromRequest(req)
+	if req.ProfileID != nil {
+		profile, err := l.searchProfileRepo.GetSearchProfile(ctx, userID, *req.ProfileID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Failed to fetch search profile")
+			return nil, err
+		}
+		in.Profile = profile
+	} else if profile, err := l.searchProfileRepo.GetDefaultSearchProfile(ctx, userID); err == nil {
+		in.Profile = profile
+	}
+
+	estimate := budget.Estimate(in)
+	span.SetAttributes(
+		attribute.String("budget.currency", estimate.Currency),
+		attribute.Int("budget.cost_level", estimate.CostLevel),
+		attribute.Bool("budget.over_budget", estimate.OverBudget),
+	)
+	span.SetStatus(codes.Ok, "Budget estimated")
+	return estimate, nil
+}
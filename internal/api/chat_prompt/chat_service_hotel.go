@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,6 +19,17 @@ import (
 	"google.golang.org/genai"
 )
 
+const (
+	// minHotelResults is the number of stored matches below which a hotel
+	// search asks the LLM for more.
+	minHotelResults       = 5
+	defaultHotelPageLimit = 20
+	maxHotelPageLimit     = 50
+	// defaultHotelRadiusKm bounds preference searches without a radius, as
+	// the hotel prompts do.
+	defaultHotelRadiusKm = 5.0
+)
+
 // var cacheHitCounter = metric.NewCounter("cache_hits", metric.WithDescription("Number of cache hits"))
 // var dbHitCounter = metric.NewCounter("db_hits", metric.WithDescription("Number of database hits"))
 // var aiCallCounter = metric.NewCounter("ai_calls", metric.WithDescription("Number of AI calls"))
@@ -116,6 +128,9 @@ func (l *ServiceImpl) getHotelsByPreferenceDetails(wg *sync.WaitGroup, ctx conte
 	span.SetStatus(codes.Ok, "Hotel details generated and saved successfully")
 }
 
+// GetHotelsByPreferenceResponse searches the hotels near lat/lon that match the
+// user's price and rating preferences, database first. The other preferences
+// only tailor the LLM's hotels when the database has too few.
 func (l *ServiceImpl) GetHotelsByPreferenceResponse(ctx context.Context, userID uuid.UUID, city string, lat, lon float64, userPreferences types.HotelUserPreferences) ([]types.HotelDetailedInfo, error) {
 	ctx, span := otel.Tracer("LlmInteractionService").Start(ctx, "GetHotelsByPreferenceResponse", trace.WithAttributes(
 		attribute.String("city.name", city),
@@ -125,105 +140,167 @@ func (l *ServiceImpl) GetHotelsByPreferenceResponse(ctx context.Context, userID
 	))
 	defer span.End()
 
-	l.logger.DebugContext(ctx, "Starting hotel details generation by preferences",
+	l.logger.DebugContext(ctx, "Starting hotel search by preferences",
 		slog.String("city", city), slog.Float64("latitude", lat), slog.Float64("longitude", lon), slog.String("userID", userID.String()))
 
-	// Generate cache key
-	cacheKey := generateHotelCacheKey(city, lat, lon, userID)
-	span.SetAttributes(attribute.String("cache.key", cacheKey))
+	resp, err := l.searchHotels(ctx, userID, hotelSearchFromPreferences(city, lat, lon, userPreferences), userPreferences)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to search hotels")
+		return nil, err
+	}
 
-	// Check cache
-	if cached, found := l.cache.Get(cacheKey); found {
-		if hotels, ok := cached.([]types.HotelDetailedInfo); ok {
-			l.logger.InfoContext(ctx, "Cache hit for hotel details", slog.String("cache_key", cacheKey))
-			span.AddEvent("Cache hit")
-			span.SetStatus(codes.Ok, "Hotel details served from cache")
-			return hotels, nil
-		}
+	span.SetStatus(codes.Ok, "Hotels found")
+	return resp.Hotels, nil
+}
+
+// SearchHotels implements LlmInteractiontService.
+func (l *ServiceImpl) SearchHotels(ctx context.Context, userID uuid.UUID, params types.HotelSearchParameters) (*types.PaginatedHotelResponse, error) {
+	return l.searchHotels(ctx, userID, params, hotelPreferencesFromSearch(params))
+}
+
+// searchHotels searches the stored hotels and only asks the LLM, tailored to
+// prefs, when the city has fewer than minHotelResults matches.
+func (l *ServiceImpl) searchHotels(ctx context.Context, userID uuid.UUID, params types.HotelSearchParameters, prefs types.HotelUserPreferences) (*types.PaginatedHotelResponse, error) {
+	ctx, span := otel.Tracer("LlmInteractionService").Start(ctx, "SearchHotels", trace.WithAttributes(
+		attribute.String("city.name", params.City),
+		attribute.Int("limit", params.Page.Limit),
+		attribute.String("user.id", userID.String()),
+	))
+	defer span.End()
+
+	params.City = strings.TrimSpace(params.City)
+	if params.City == "" {
+		span.SetStatus(codes.Error, "Missing city")
+		return nil, fmt.Errorf("%w: city is required", types.ErrBadRequest)
 	}
+	params.Page = params.Page.Clamp(defaultHotelPageLimit, maxHotelPageLimit)
 
-	// Find city ID
-	cityData, err := l.cityRepo.FindCityByNameAndCountry(ctx, city, "")
+	cityData, err := l.cityRepo.FindCityByNameAndCountry(ctx, params.City, "")
 	if err != nil {
 		l.logger.ErrorContext(ctx, "Failed to find city", slog.Any("error", err))
 		span.RecordError(err)
 		return nil, fmt.Errorf("failed to find city: %w", err)
 	}
 	if cityData == nil {
-		l.logger.WarnContext(ctx, "City not found", slog.String("city", city))
 		span.SetStatus(codes.Error, "City not found")
-		return nil, fmt.Errorf("city %s not found", city)
+		return nil, fmt.Errorf("city %s not found: %w", params.City, types.ErrNotFound)
 	}
-	cityID := cityData.ID
 
-	// Check database
-	hotels, err := l.poiRepo.FindHotelDetails(ctx, cityID, lat, lon, 1000.0) // 1km tolerance
+	hotels, next, total, err := l.poiRepo.SearchHotels(ctx, cityData.ID, params)
 	if err != nil {
-		l.logger.ErrorContext(ctx, "Failed to query hotel details from database", slog.Any("error", err))
+		l.logger.ErrorContext(ctx, "Failed to search hotels in database", slog.Any("error", err))
 		span.RecordError(err)
-		return nil, fmt.Errorf("failed to query hotel details: %w", err)
+		return nil, fmt.Errorf("failed to search hotels: %w", err)
 	}
-	if len(hotels) > 0 {
-		for i := range hotels {
-			hotels[i].City = city
+	span.SetAttributes(attribute.Int("db.total", total))
+
+	// Later pages only exist when the first one was full
+	if total < minHotelResults && params.Page.Cursor == nil {
+		span.AddEvent("Too few stored hotels, topping up from AI")
+		hotels, next, total, err = l.topUpHotels(ctx, userID, cityData, params, prefs, hotels, next, total)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Failed to top up hotels")
+			return nil, err
 		}
-		l.cache.Set(cacheKey, hotels, cache.DefaultExpiration)
-		l.logger.InfoContext(ctx, "Database hit for hotel details", slog.String("cache_key", cacheKey))
-		span.AddEvent("Database hit")
-		span.SetStatus(codes.Ok, "Hotel details served from database")
-		return hotels, nil
 	}
 
-	// Cache and database miss: fetch from AI
-	l.logger.DebugContext(ctx, "Cache and database miss, fetching hotel details from AI", slog.String("cache_key", cacheKey))
-	span.AddEvent("Cache and database miss")
+	for i := range hotels {
+		hotels[i].City = cityData.Name
+	}
+	span.SetAttributes(attribute.Int("results.count", len(hotels)))
+	span.SetStatus(codes.Ok, "Hotels searched")
+	return &types.PaginatedHotelResponse{
+		Hotels:       hotels,
+		Limit:        params.Page.Limit,
+		NextCursor:   next,
+		TotalRecords: total,
+	}, nil
+}
+
+// topUpHotels asks the LLM for hotels near the search point, or the city
+// centre, and stores those the city does not have yet. The search then runs
+// again so the new hotels are filtered and ordered with the stored ones, and
+// the next search finds them without the LLM. The stored results are kept
+// when the LLM fails, unless there are none.
+func (l *ServiceImpl) topUpHotels(ctx context.Context, userID uuid.UUID, cityData *types.CityDetail, params types.HotelSearchParameters,
+	prefs types.HotelUserPreferences, found []types.HotelDetailedInfo, next *types.Cursor, total int) ([]types.HotelDetailedInfo, *types.Cursor, int, error) {
+	lat, lon := params.Latitude, params.Longitude
+	if lat == 0 && lon == 0 {
+		lat, lon = cityData.CenterLatitude, cityData.CenterLongitude
+	}
 
 	resultCh := make(chan []types.HotelDetailedInfo, 1)
 	var wg sync.WaitGroup
 	wg.Add(1)
+	go l.getHotelsByPreferenceDetails(&wg, ctx, params.City, lat, lon, userID, prefs, resultCh, &genai.GenerateContentConfig{Temperature: genai.Ptr[float32](defaultTemperature)})
+	generated := <-resultCh
+	wg.Wait()
+
+	return topUpDetails(ctx, l.logger, detailTopUp[types.HotelDetailedInfo]{
+		kind: "hotels",
+		name: func(h types.HotelDetailedInfo) string { return h.Name },
+		err:  func(h types.HotelDetailedInfo) error { return h.Err },
+		storedNames: func(ctx context.Context) ([]string, error) {
+			return l.poiRepo.HotelNamesByCity(ctx, cityData.ID)
+		},
+		save: func(ctx context.Context, hotel types.HotelDetailedInfo) error {
+			_, err := l.poiRepo.SaveHotelDetails(ctx, hotel, cityData.ID)
+			return err
+		},
+		search: func(ctx context.Context) ([]types.HotelDetailedInfo, *types.Cursor, int, error) {
+			return l.poiRepo.SearchHotels(ctx, cityData.ID, params)
+		},
+	}, generated, found, next, total)
+}
 
-	go l.getHotelsByPreferenceDetails(&wg, ctx, city, lat, lon, userID, userPreferences, resultCh, &genai.GenerateContentConfig{Temperature: genai.Ptr[float32](defaultTemperature)})
-
-	go func() {
-		wg.Wait()
-		close(resultCh)
-	}()
-
-	var hotelResults []types.HotelDetailedInfo
-	for res := range resultCh {
-		if res[0].Err != nil {
-			l.logger.ErrorContext(ctx, "Error generating hotel details", slog.Any("error", res[0].Err))
-			span.RecordError(res[0].Err)
-			span.SetStatus(codes.Error, "Failed to generate hotel details")
-			return nil, res[0].Err
+// hotelSearchFromPreferences filters a preference search by the price and
+// rating the user asked for, within their radius.
+func hotelSearchFromPreferences(city string, lat, lon float64, prefs types.HotelUserPreferences) types.HotelSearchParameters {
+	params := types.HotelSearchParameters{
+		City:      city,
+		Latitude:  lat,
+		Longitude: lon,
+		RadiusKm:  prefs.SearchRadiusKm,
+	}
+	if params.RadiusKm <= 0 {
+		params.RadiusKm = defaultHotelRadiusKm
+	}
+	if prefs.MinRating > 0 {
+		params.MinRating = &prefs.MinRating
+	}
+	// A maximum of "$$" allows "$" and "$$"
+	if maxPrice := strings.TrimSpace(prefs.MaxPriceRange); maxPrice != "" && strings.Trim(maxPrice, "$") == "" {
+		for n := 1; n <= len(maxPrice); n++ {
+			params.PriceRanges = append(params.PriceRanges, strings.Repeat("$", n))
 		}
-		hotelResults = res
-		break
-	}
-
-	if len(hotelResults) == 0 {
-		l.logger.WarnContext(ctx, "No hotels received for hotel details")
-		span.SetStatus(codes.Error, "No hotels received")
-		return nil, fmt.Errorf("no hotels received for hotel details")
 	}
+	return params
+}
 
-	// Save to database
-	for _, hotel := range hotelResults {
-		_, err = l.poiRepo.SaveHotelDetails(ctx, hotel, cityID)
-		if err != nil {
-			l.logger.WarnContext(ctx, "Failed to save hotel details to database", slog.Any("error", err))
-			span.RecordError(err)
-			// Continue despite error
+// hotelPreferencesFromSearch describes the search filters to the LLM, for a
+// single guest's one-night stay from today.
+func hotelPreferencesFromSearch(params types.HotelSearchParameters) types.HotelUserPreferences {
+	prefs := types.HotelUserPreferences{
+		NumberOfGuests:      1,
+		PreferredCategories: strings.Join(params.Categories, ", "),
+		PreferredTags:       params.Amenities,
+		NumberOfNights:      1,
+		NumberOfRooms:       1,
+		PreferredCheckIn:    time.Now(),
+		PreferredCheckOut:   time.Now().Add(24 * time.Hour),
+		SearchRadiusKm:      params.RadiusKm,
+	}
+	for _, price := range params.PriceRanges {
+		if len(price) > len(prefs.MaxPriceRange) {
+			prefs.MaxPriceRange = price
 		}
 	}
-
-	// Store in cache
-	l.cache.Set(cacheKey, hotelResults, cache.DefaultExpiration)
-	l.logger.DebugContext(ctx, "Stored hotel details in cache", slog.String("cache_key", cacheKey))
-	span.AddEvent("Stored in cache")
-
-	span.SetStatus(codes.Ok, "Hotel details generated and cached successfully")
-	return hotelResults, nil
+	if params.MinRating != nil {
+		prefs.MinRating = *params.MinRating
+	}
+	return prefs
 }
 
 func (l *ServiceImpl) getHotelsNearby(wg *sync.WaitGroup, ctx context.Context,
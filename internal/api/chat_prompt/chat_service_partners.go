@@ -0,0 +1,38 @@
+package llmChat
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+// BookingOffers asks the partners a hotel or restaurant is listed with for
+// their offers. Hotel offers are ranked by the cancellation policies and
+// booking mode of the user's default profile, when there is one.
+func (l *ServiceImpl) BookingOffers(ctx context.Context, userID uuid.UUID, targetKind string, targetID uuid.UUID, req types.BookingRequest) []types.PartnerOffer {
+	if l.partners == nil {
+		return nil
+	}
+	ctx, span := otel.Tracer("LlmInteractionService").Start(ctx, "BookingOffers", trace.WithAttributes(
+		attribute.String("target.kind", targetKind),
+		attribute.String("target.id", targetID.String()),
+	))
+	defer span.End()
+
+	var prefs *types.AccommodationPreferences
+	if targetKind == types.PartnerTargetHotel && userID != uuid.Nil {
+		profile, err := l.searchProfileRepo.GetDefaultSearchProfile(ctx, userID)
+		if err != nil {
+			l.logger.DebugContext(ctx, "No default profile to rank booking offers by", slog.Any("error", err))
+		} else {
+			prefs = profile.AccommodationPreferences
+		}
+	}
+	return l.partners.Offers(ctx, targetKind, targetID, req, prefs)
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,9 +16,28 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/genai"
 )
 
+const (
+	// minRestaurantResults is the number of stored matches below which a
+	// restaurant search asks the LLM for more.
+	minRestaurantResults       = 5
+	defaultRestaurantPageLimit = 20
+	maxRestaurantPageLimit     = 50
+	// defaultRestaurantRadiusKm bounds preference searches, as the old 5km
+	// database lookup did.
+	defaultRestaurantRadiusKm = 5.0
+)
+
+// generatedRestaurant is a restaurant as the LLM returns it: opening hours
+// come as an object and are stored as its JSON text.
+type generatedRestaurant struct {
+	types.RestaurantDetailedInfo
+	OpeningHours json.RawMessage `json:"opening_hours"`
+}
+
 func (l *ServiceImpl) getRestaurantsByPreferences(wg *sync.WaitGroup, ctx context.Context,
 	city string, lat, lon float64, userID uuid.UUID, preferences types.RestaurantUserPreferences,
 	resultCh chan<- []types.RestaurantDetailedInfo, config *genai.GenerateContentConfig) {
@@ -55,7 +76,7 @@ func (l *ServiceImpl) getRestaurantsByPreferences(wg *sync.WaitGroup, ctx contex
 
 	cleanTxt := cleanJSONResponse(txt)
 	var restaurantResponse struct {
-		Restaurants []types.RestaurantDetailedInfo `json:"restaurants"`
+		Restaurants []generatedRestaurant `json:"restaurants"`
 	}
 	if err := json.Unmarshal([]byte(cleanTxt), &restaurantResponse); err != nil {
 		span.RecordError(err)
@@ -78,11 +99,17 @@ func (l *ServiceImpl) getRestaurantsByPreferences(wg *sync.WaitGroup, ctx contex
 		return
 	}
 
-	for i := range restaurantResponse.Restaurants {
-		restaurantResponse.Restaurants[i].ID = uuid.New()
-		restaurantResponse.Restaurants[i].LlmInteractionID = savedInteractionID
+	restaurants := make([]types.RestaurantDetailedInfo, 0, len(restaurantResponse.Restaurants))
+	for _, generated := range restaurantResponse.Restaurants {
+		restaurant := generated.RestaurantDetailedInfo
+		if hours := strings.TrimSpace(string(generated.OpeningHours)); strings.HasPrefix(hours, "{") {
+			restaurant.OpeningHours = &hours
+		}
+		restaurant.ID = uuid.New()
+		restaurant.LlmInteractionID = savedInteractionID
+		restaurants = append(restaurants, restaurant)
 	}
-	resultCh <- restaurantResponse.Restaurants
+	resultCh <- restaurants
 	span.SetStatus(codes.Ok, "Restaurants generated successfully")
 }
 
@@ -90,58 +117,252 @@ func (l *ServiceImpl) GetRestaurantsByPreferencesResponse(ctx context.Context, u
 	ctx, span := otel.Tracer("LlmInteractionService").Start(ctx, "GetRestaurantsByPreferencesResponse")
 	defer span.End()
 
-	cacheKey := generateRestaurantCacheKey(city, lat, lon, userID)
-	span.SetAttributes(attribute.String("cache.key", cacheKey))
+	resp, err := l.searchRestaurants(ctx, userID, restaurantSearchFromPreferences(city, lat, lon, preferences), preferences)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to search restaurants")
+		return nil, err
+	}
 
-	if cached, found := l.cache.Get(cacheKey); found {
-		if restaurants, ok := cached.([]types.RestaurantDetailedInfo); ok {
-			span.SetStatus(codes.Ok, "Served from cache")
-			return restaurants, nil
+	span.SetStatus(codes.Ok, "Restaurants found")
+	return resp.Restaurants, nil
+}
+
+// SearchRestaurants implements LlmInteractiontService.
+func (l *ServiceImpl) SearchRestaurants(ctx context.Context, userID uuid.UUID, params types.RestaurantSearchParameters) (*types.PaginatedRestaurantResponse, error) {
+	if params.ProfileID != uuid.Nil {
+		profile, err := l.searchProfileRepo.GetSearchProfile(ctx, userID, params.ProfileID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch search profile: %w", err)
 		}
+		params = restaurantSearchWithProfile(params, profile)
 	}
+	return l.searchRestaurants(ctx, userID, params, restaurantPreferencesFromSearch(params))
+}
 
-	cityData, err := l.cityRepo.FindCityByNameAndCountry(ctx, city, "")
-	if err != nil || cityData == nil {
+// searchRestaurants searches the stored restaurants and only asks the LLM,
+// tailored to prefs, when the city has fewer than minRestaurantResults
+// matches. Allergen searches are never topped up: the LLM's restaurants are
+// unconfirmed, so none of them could be returned.
+func (l *ServiceImpl) searchRestaurants(ctx context.Context, userID uuid.UUID, params types.RestaurantSearchParameters, prefs types.RestaurantUserPreferences) (*types.PaginatedRestaurantResponse, error) {
+	ctx, span := otel.Tracer("LlmInteractionService").Start(ctx, "SearchRestaurants", trace.WithAttributes(
+		attribute.String("city.name", params.City),
+		attribute.Int("limit", params.Page.Limit),
+		attribute.String("user.id", userID.String()),
+	))
+	defer span.End()
+
+	params.City = strings.TrimSpace(params.City)
+	if params.City == "" {
+		span.SetStatus(codes.Error, "Missing city")
+		return nil, fmt.Errorf("%w: city is required", types.ErrBadRequest)
+	}
+	params.Page = params.Page.Clamp(defaultRestaurantPageLimit, maxRestaurantPageLimit)
+
+	cityData, err := l.cityRepo.FindCityByNameAndCountry(ctx, params.City, "")
+	if err != nil {
+		l.logger.ErrorContext(ctx, "Failed to find city", slog.Any("error", err))
 		span.RecordError(err)
-		return nil, fmt.Errorf("city %s not found: %w", city, err)
+		return nil, fmt.Errorf("failed to find city: %w", err)
+	}
+	if cityData == nil {
+		span.SetStatus(codes.Error, "City not found")
+		return nil, fmt.Errorf("city %s not found: %w", params.City, types.ErrNotFound)
 	}
-	cityID := cityData.ID
 
-	restaurants, err := l.poiRepo.FindRestaurantDetails(ctx, cityData.ID, lat, lon, 5000.0, &preferences) // 5km tolerance
+	restaurants, next, total, err := l.poiRepo.SearchRestaurants(ctx, cityData.ID, params)
 	if err != nil {
+		l.logger.ErrorContext(ctx, "Failed to search restaurants in database", slog.Any("error", err))
 		span.RecordError(err)
-		return nil, fmt.Errorf("failed to query restaurants: %w", err)
+		return nil, fmt.Errorf("failed to search restaurants: %w", err)
 	}
-	if len(restaurants) > 0 {
-		l.cache.Set(cacheKey, restaurants, cache.DefaultExpiration)
-		span.SetStatus(codes.Ok, "Served from database")
-		return restaurants, nil
+	span.SetAttributes(attribute.Int("db.total", total))
+
+	// Later pages only exist when the first one was full
+	if total < minRestaurantResults && params.Page.Cursor == nil && len(types.AttributeKeys(params.AllergenFree)) == 0 {
+		span.AddEvent("Too few stored restaurants, topping up from AI")
+		restaurants, next, total, err = l.topUpRestaurants(ctx, userID, cityData, params, prefs, restaurants, next, total)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Failed to top up restaurants")
+			return nil, err
+		}
+	}
+
+	// Dietary and allergen filters are a safety matter: whatever the query
+	// returned, never hand out a restaurant that does not cater for them
+	kept := restaurants[:0]
+	for _, restaurant := range restaurants {
+		if !catersFor(restaurant, params) {
+			l.logger.ErrorContext(ctx, "Dropping restaurant not matching dietary filters",
+				slog.String("restaurant_id", restaurant.ID.String()), slog.String("restaurant", restaurant.Name))
+			total--
+			continue
+		}
+		restaurant.City = cityData.Name
+		kept = append(kept, restaurant)
+	}
+	restaurants = kept
+	span.SetAttributes(attribute.Int("results.count", len(restaurants)))
+	span.SetStatus(codes.Ok, "Restaurants searched")
+	return &types.PaginatedRestaurantResponse{
+		Restaurants:  restaurants,
+		Limit:        params.Page.Limit,
+		NextCursor:   next,
+		TotalRecords: total,
+	}, nil
+}
+
+// topUpRestaurants asks the LLM for restaurants near the search point, or the
+// city centre, and stores those the city does not have yet. The search then
+// runs again so only the new restaurants that pass every filter are returned,
+// and the next search finds them without the LLM. The stored results are kept
+// when the LLM fails, unless there are none.
+func (l *ServiceImpl) topUpRestaurants(ctx context.Context, userID uuid.UUID, cityData *types.CityDetail, params types.RestaurantSearchParameters,
+	prefs types.RestaurantUserPreferences, found []types.RestaurantDetailedInfo, next *types.Cursor, total int) ([]types.RestaurantDetailedInfo, *types.Cursor, int, error) {
+	lat, lon := params.Latitude, params.Longitude
+	if lat == 0 && lon == 0 {
+		lat, lon = cityData.CenterLatitude, cityData.CenterLongitude
 	}
 
 	resultCh := make(chan []types.RestaurantDetailedInfo, 1)
 	var wg sync.WaitGroup
 	wg.Add(1)
-	go l.getRestaurantsByPreferences(&wg, ctx, city, lat, lon, userID, preferences, resultCh, nil)
+	go l.getRestaurantsByPreferences(&wg, ctx, params.City, lat, lon, userID, prefs, resultCh, &genai.GenerateContentConfig{Temperature: genai.Ptr[float32](defaultTemperature)})
+	generated := <-resultCh
 	wg.Wait()
-	close(resultCh)
 
-	restaurants = <-resultCh
-	if restaurants[0].Err != nil {
-		span.RecordError(restaurants[0].Err)
-		return nil, restaurants[0].Err
+	return topUpDetails(ctx, l.logger, detailTopUp[types.RestaurantDetailedInfo]{
+		kind: "restaurants",
+		name: func(r types.RestaurantDetailedInfo) string { return r.Name },
+		err:  func(r types.RestaurantDetailedInfo) error { return r.Err },
+		storedNames: func(ctx context.Context) ([]string, error) {
+			return l.poiRepo.RestaurantNamesByCity(ctx, cityData.ID)
+		},
+		save: func(ctx context.Context, restaurant types.RestaurantDetailedInfo) error {
+			_, err := l.poiRepo.SaveRestaurantDetails(ctx, restaurant, cityData.ID)
+			return err
+		},
+		search: func(ctx context.Context) ([]types.RestaurantDetailedInfo, *types.Cursor, int, error) {
+			return l.poiRepo.SearchRestaurants(ctx, cityData.ID, params)
+		},
+	}, generated, found, next, total)
+}
+
+// catersFor reports whether a restaurant currently offers every diet and
+// avoids every allergen the search asks for. Allergens count only once staff
+// or users confirmed them, not on the LLM's word alone.
+func catersFor(restaurant types.RestaurantDetailedInfo, params types.RestaurantSearchParameters) bool {
+	for _, diet := range types.AttributeKeys(params.DietaryNeeds) {
+		if !slices.Contains(restaurant.DietaryOptions, diet) {
+			return false
+		}
+	}
+	for _, allergen := range types.AttributeKeys(params.AllergenFree) {
+		if !slices.Contains(restaurant.AllergenConfirmed, allergen) {
+			return false
+		}
+	}
+	return true
+}
+
+// ReportRestaurantDietary implements LlmInteractiontService.
+func (l *ServiceImpl) ReportRestaurantDietary(ctx context.Context, userID, restaurantID uuid.UUID, report types.DietaryReport) (*types.RestaurantDetailedInfo, error) {
+	ctx, span := otel.Tracer("LlmInteractionService").Start(ctx, "ReportRestaurantDietary", trace.WithAttributes(
+		attribute.String("restaurant.id", restaurantID.String()),
+		attribute.String("user.id", userID.String()),
+	))
+	defer span.End()
+
+	if err := report.Normalize(); err != nil {
+		span.SetStatus(codes.Error, "Invalid dietary report")
+		return nil, err
 	}
 
-	for _, r := range restaurants {
-		_, err := l.poiRepo.SaveRestaurantDetails(ctx, r, cityID)
-		if err != nil {
-			l.logger.WarnContext(ctx, "Failed to save restaurant details to database", slog.Any("error", err), slog.String("restaurant_name", r.Name))
-			span.RecordError(err)
-			// Continue despite error
+	if err := l.poiRepo.ReportRestaurantDietary(ctx, restaurantID, userID, report); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to save dietary report")
+		return nil, err
+	}
+	l.logger.InfoContext(ctx, "Dietary report saved",
+		slog.String("restaurant_id", restaurantID.String()), slog.String("kind", report.Kind),
+		slog.String("attribute", report.Attribute), slog.Bool("available", report.Available))
+
+	restaurant, err := l.poiRepo.GetRestaurantByID(ctx, restaurantID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get restaurant: %w", err)
+	}
+	if restaurant == nil {
+		return nil, fmt.Errorf("restaurant %s not found: %w", restaurantID, types.ErrNotFound)
+	}
+	span.SetStatus(codes.Ok, "Dietary report saved")
+	return restaurant, nil
+}
+
+// restaurantSearchFromPreferences filters a preference search by the cuisine
+// and price the user asked for, within defaultRestaurantRadiusKm. The other
+// preferences are free text and only tailor the LLM prompt.
+func restaurantSearchFromPreferences(city string, lat, lon float64, prefs types.RestaurantUserPreferences) types.RestaurantSearchParameters {
+	params := types.RestaurantSearchParameters{
+		City:      city,
+		Latitude:  lat,
+		Longitude: lon,
+		RadiusKm:  defaultRestaurantRadiusKm,
+		Cuisines:  splitList(prefs.PreferredCuisine),
+	}
+	if price := strings.TrimSpace(prefs.PreferredPriceRange); price != "" {
+		params.PriceRanges = []string{price}
+	}
+	return params
+}
+
+// restaurantSearchWithProfile adds the dining preferences of a search profile
+// to the search: its dietary needs and allergens must all be catered for, it
+// asks for outdoor seating when preferred, and its service styles apply when
+// the search names none.
+func restaurantSearchWithProfile(params types.RestaurantSearchParameters, profile *types.UserPreferenceProfileResponse) types.RestaurantSearchParameters {
+	dietary := slices.Clone(profile.DietaryNeeds)
+	outdoorSeating := profile.PreferOutdoorSeating
+	if dining := profile.DiningPreferences; dining != nil {
+		dietary = append(dietary, dining.DietaryNeeds...)
+		params.AllergenFree = append(slices.Clone(params.AllergenFree), dining.AllergenFree...)
+		if len(params.ServiceStyles) == 0 {
+			params.ServiceStyles = dining.ServiceStyle
+		}
+		outdoorSeating = outdoorSeating || dining.OutdoorSeatingPref
+	}
+	params.DietaryNeeds = append(slices.Clone(params.DietaryNeeds), dietary...)
+	if outdoorSeating && !slices.Contains(params.Features, "outdoor_seating") {
+		params.Features = append(slices.Clone(params.Features), "outdoor_seating")
+	}
+	return params
+}
+
+// restaurantPreferencesFromSearch describes the search filters to the LLM.
+func restaurantPreferencesFromSearch(params types.RestaurantSearchParameters) types.RestaurantUserPreferences {
+	dietary := slices.Clone(params.DietaryNeeds)
+	for _, allergen := range params.AllergenFree {
+		dietary = append(dietary, allergen+"-free")
+	}
+	return types.RestaurantUserPreferences{
+		PreferredCuisine:    strings.Join(params.Cuisines, ", "),
+		PreferredPriceRange: strings.Join(params.PriceRanges, ", "),
+		DietaryRestrictions: strings.Join(dietary, ", "),
+		Ambiance:            strings.Join(params.ServiceStyles, ", "),
+		SpecialFeatures:     strings.Join(params.Features, ", "),
+	}
+}
+
+// splitList splits a comma-separated preference into its trimmed values.
+func splitList(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
 		}
 	}
-	l.cache.Set(cacheKey, restaurants, cache.DefaultExpiration)
-	span.SetStatus(codes.Ok, "Restaurants generated and cached")
-	return restaurants, nil
+	return values
 }
 
 func (l *ServiceImpl) getRestaurantsNearby(wg *sync.WaitGroup, ctx context.Context,
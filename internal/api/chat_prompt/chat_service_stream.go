@@ -179,8 +179,8 @@ func (l *ServiceImpl) streamingCityDataWorker(wg *sync.WaitGroup,
 		StateProvince   *string `json:"state_province,omitempty"`
 		Country         string  `json:"country"`
 		CenterLatitude  float64 `json:"center_latitude"`
-		CenterLongitude float64
-		Description     string `json:"description"`
+		CenterLongitude float64 `json:"center_longitude"`
+		Description     string  `json:"description"`
 	}
 	if err := json.Unmarshal([]byte(cleanTxt), &cityData); err != nil {
 		span.RecordError(err)
@@ -206,6 +206,7 @@ func (l *ServiceImpl) streamingCityDataWorker(wg *sync.WaitGroup,
 		CityDescription: cityData.Description,
 		Latitude:        cityData.CenterLatitude,
 		Longitude:       cityData.CenterLongitude,
+		Forecast:        l.forecastFor(ctxWorker, cityData.CenterLatitude, cityData.CenterLongitude),
 	}
 
 	l.sendEventWithRetry(ctxWorker, eventCh, types.StreamEvent{
@@ -738,6 +739,7 @@ func (l *ServiceImpl) StartNewSessionStreamed(ctx context.Context, userID, profi
 		span.RecordError(err)
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
+	l.emitChatStarted(ctx, userID, &sessionID, cityName, string(types.DomainItinerary))
 
 	go func() {
 		defer close(eventCh)
@@ -769,6 +771,7 @@ func (l *ServiceImpl) StartNewSessionStreamed(ctx context.Context, userID, profi
 		}
 
 		interestNames, tagsPromptPart, userPrefs := l.PreparePromptData(interests, tags, searchProfile)
+		userPrefs += l.learnedPreferences(ctx, userID)
 
 		// Enhance with semantic search context - get contextually relevant POIs
 		l.sendEvent(ctx, eventCh, types.StreamEvent{
@@ -847,6 +850,7 @@ func (l *ServiceImpl) StartNewSessionStreamed(ctx context.Context, userID, profi
 						Description:     result.CityDescription,
 						CenterLatitude:  result.Latitude,
 						CenterLongitude: result.Longitude,
+						Forecast:        result.Forecast,
 					}
 				}
 			case result, ok := <-generalPOICh:
@@ -911,6 +915,7 @@ func (l *ServiceImpl) StartNewSessionStreamed(ctx context.Context, userID, profi
 		}
 
 		l.HandleGeneralPOIs(ctx, itinerary.PointsOfInterest, cityID)
+		rawPersonalisedPOIs = l.honourAccessibility(ctx, searchProfile.AccessibilityNeeds, rawPersonalisedPOIs)
 		sortedPOIs, err := l.HandlePersonalisedPOIs(ctx, rawPersonalisedPOIs, cityID, userLocation, llmInteractionID, userID, profileID)
 		if err != nil {
 			span.RecordError(err)
@@ -923,6 +928,9 @@ func (l *ServiceImpl) StartNewSessionStreamed(ctx context.Context, userID, profi
 			return
 		}
 		itinerary.AIItineraryResponse.PointsOfInterest = sortedPOIs
+		l.adaptToWeather(ctx, &itinerary, searchProfile, nil)
+		l.planTravel(ctx, &itinerary, searchProfile)
+		itinerary.AIItineraryResponse.Budget = estimateCost(&itinerary, searchProfile)
 
 		// Update session with itinerary
 		session.CurrentItinerary = &itinerary
@@ -1620,7 +1628,7 @@ func (l *ServiceImpl) handleSemanticAddPOIStreamed(ctx context.Context, message
 ** Unified Response
  */
 // ProcessUnifiedChatMessageStream handles unified chat with optimized streaming based on Google GenAI patterns
-func (l *ServiceImpl) ProcessUnifiedChatMessageStream(ctx context.Context, userID, profileID uuid.UUID, cityName, message string, userLocation *types.UserLocation, eventCh chan<- types.StreamEvent) error {
+func (l *ServiceImpl) ProcessUnifiedChatMessageStream(ctx context.Context, userID, profileID uuid.UUID, cityName, message string, userLocation *types.UserLocation, travelDates *types.TravelDates, eventCh chan<- types.StreamEvent) error {
 	startTime := time.Now() // Track when processing starts
 	ctx, span := otel.Tracer("LlmInteractionService").Start(ctx, "ProcessUnifiedChatMessageStream", trace.WithAttributes(
 		attribute.String("message", message),
@@ -1670,6 +1678,7 @@ func (l *ServiceImpl) ProcessUnifiedChatMessageStream(ctx context.Context, userI
 	var closeOnce sync.Once
 
 	sessionID := uuid.New()
+	l.emitChatStarted(ctx, userID, &sessionID, cityName, string(domain))
 	l.sendEventSimple(ctx, eventCh, types.StreamEvent{
 		Type: types.EventTypeStart,
 		Data: map[string]interface{}{"domain": string(domain), "city": cityName, "session_id": sessionID.String()},
@@ -1724,6 +1733,17 @@ func (l *ServiceImpl) ProcessUnifiedChatMessageStream(ctx context.Context, userI
 			l.streamWorkerWithResponse(ctx, prompt, "itinerary", sendEventWithResponse, domain)
 		}()
 
+		// Worker 4: City events on the travel days, when given
+		if travelDates != nil {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if found := l.eventsDuring(ctx, cityName, travelDates, searchProfile); len(found) > 0 {
+					sendEventWithResponse(types.StreamEvent{Type: types.EventTypeEvents, Data: found})
+				}
+			}()
+		}
+
 	case types.DomainAccommodation:
 		wg.Add(1)
 		go func() {
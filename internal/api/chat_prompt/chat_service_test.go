@@ -2,7 +2,7 @@ package llmChat
 
 import (
 	"context"
-	"errors"
+	"flag"
 	"fmt"
 	"log/slog"
 	"os"
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	generativeAI "github.com/FACorreiaa/go-poi-au-suggestions/internal/api/generative_ai"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/weather"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -20,6 +21,8 @@ import (
 	"google.golang.org/genai" // For genai.GenerateContentConfig
 )
 
+var runIntegrationTests = flag.Bool("integration", false, "run tests that need a database and the Gemini API")
+
 // --- Mocks for Dependencies ---
 
 // Mock AIClient
@@ -78,12 +81,12 @@ func (m *MockPOIRepository) GetPOIsByLocationAndDistance(ctx context.Context, la
 	return args.Get(0).([]types.POIDetailedInfo), args.Error(1)
 }
 
-func (m *MockPOIRepository) GetPOIsByLocationAndDistanceWithFilters(ctx context.Context, lat, lon, radiusMeters float64, filters map[string]string) ([]types.POIDetailedInfo, error) {
-	args := m.Called(ctx, lat, lon, radiusMeters, filters)
+func (m *MockPOIRepository) GetPOIsByLocationAndDistanceWithFilters(ctx context.Context, lat, lon, radiusMeters float64, filters map[string]string, selection types.FacetSelection) (*types.POISearchResult, error) {
+	args := m.Called(ctx, lat, lon, radiusMeters, filters, selection)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]types.POIDetailedInfo), args.Error(1)
+	return args.Get(0).(*types.POISearchResult), args.Error(1)
 }
 
 func (m *MockPOIRepository) AddPoiToFavourites(ctx context.Context, userID, poiID uuid.UUID) (uuid.UUID, error) {
@@ -96,20 +99,22 @@ func (m *MockPOIRepository) RemovePoiFromFavourites(ctx context.Context, poiID u
 	return args.Error(0)
 }
 
-func (m *MockPOIRepository) GetFavouritePOIsByUserID(ctx context.Context, userID uuid.UUID) ([]types.POIDetailedInfo, error) {
-	args := m.Called(ctx, userID)
+func (m *MockPOIRepository) GetFavouritePOIsByUserID(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]types.POIDetailedInfo, *types.Cursor, error) {
+	args := m.Called(ctx, userID, page)
 	if args.Get(0) == nil {
-		return nil, args.Error(1)
+		return nil, nil, args.Error(2)
 	}
-	return args.Get(0).([]types.POIDetailedInfo), args.Error(1)
+	next, _ := args.Get(1).(*types.Cursor)
+	return args.Get(0).([]types.POIDetailedInfo), next, args.Error(2)
 }
 
-func (m *MockPOIRepository) GetPOIsByCityID(ctx context.Context, cityID uuid.UUID) ([]types.POIDetailedInfo, error) {
-	args := m.Called(ctx, cityID)
+func (m *MockPOIRepository) GetPOIsByCityID(ctx context.Context, cityID uuid.UUID, page types.PageRequest) ([]types.POIDetailedInfo, *types.Cursor, error) {
+	args := m.Called(ctx, cityID, page)
 	if args.Get(0) == nil {
-		return nil, args.Error(1)
+		return nil, nil, args.Error(2)
 	}
-	return args.Get(0).([]types.POIDetailedInfo), args.Error(1)
+	next, _ := args.Get(1).(*types.Cursor)
+	return args.Get(0).([]types.POIDetailedInfo), next, args.Error(2)
 }
 
 func (m *MockPOIRepository) FindPOIDetailedInfos(ctx context.Context, cityID uuid.UUID, lat, lon float64, tolerance float64) (*types.POIDetailedInfo, error) {
@@ -125,38 +130,67 @@ func (m *MockPOIRepository) SavePOIDetailedInfos(ctx context.Context, poi types.
 	return args.Get(0).(uuid.UUID), args.Error(1)
 }
 
-func (m *MockPOIRepository) SearchPOIs(ctx context.Context, filter types.POIFilter) ([]types.POIDetailedInfo, error) {
+func (m *MockPOIRepository) SearchPOIs(ctx context.Context, filter types.POIFilter) (*types.POISearchResult, error) {
 	args := m.Called(ctx, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]types.POIDetailedInfo), args.Error(1)
+	return args.Get(0).(*types.POISearchResult), args.Error(1)
 }
 
-func (m *MockPOIRepository) FindSimilarPOIs(ctx context.Context, queryEmbedding []float32, limit int) ([]types.POIDetailedInfo, error) {
-	args := m.Called(ctx, queryEmbedding, limit)
+func (m *MockPOIRepository) ActiveEmbeddingModel(ctx context.Context) (types.EmbeddingModel, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(types.EmbeddingModel), args.Error(1)
+}
+
+func (m *MockPOIRepository) PreferenceScores(ctx context.Context, userID, profileID uuid.UUID, pois []types.POIDetailedInfo) (map[int]types.PreferenceScore, error) {
+	args := m.Called(ctx, userID, profileID, pois)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]types.POIDetailedInfo), args.Error(1)
+	return args.Get(0).(map[int]types.PreferenceScore), args.Error(1)
 }
 
-func (m *MockPOIRepository) FindSimilarPOIsByCity(ctx context.Context, queryEmbedding []float32, cityID uuid.UUID, limit int) ([]types.POIDetailedInfo, error) {
-	args := m.Called(ctx, queryEmbedding, cityID, limit)
+func (m *MockPOIRepository) AccessibilityNeeds(ctx context.Context, userID, profileID uuid.UUID) (*types.AccessibilityNeeds, error) {
+	args := m.Called(ctx, userID, profileID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.AccessibilityNeeds), args.Error(1)
+}
+
+func (m *MockPOIRepository) KnownAccessibility(ctx context.Context, pois []types.POIDetailedInfo) (map[int]map[string]bool, error) {
+	args := m.Called(ctx, pois)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int]map[string]bool), args.Error(1)
+}
+
+func (m *MockPOIRepository) FindSimilarPOIs(ctx context.Context, queryEmbedding []float32, model types.EmbeddingModel, limit int) ([]types.POIDetailedInfo, error) {
+	args := m.Called(ctx, queryEmbedding, model, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]types.POIDetailedInfo), args.Error(1)
 }
 
-func (m *MockPOIRepository) SearchPOIsHybrid(ctx context.Context, filter types.POIFilter, queryEmbedding []float32, semanticWeight float64) ([]types.POIDetailedInfo, error) {
-	args := m.Called(ctx, filter, queryEmbedding, semanticWeight)
+func (m *MockPOIRepository) FindSimilarPOIsByCity(ctx context.Context, queryEmbedding []float32, model types.EmbeddingModel, cityID uuid.UUID, limit int) ([]types.POIDetailedInfo, error) {
+	args := m.Called(ctx, queryEmbedding, model, cityID, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]types.POIDetailedInfo), args.Error(1)
 }
 
+func (m *MockPOIRepository) SearchPOIsHybrid(ctx context.Context, filter types.POIFilter, queryEmbedding []float32, model types.EmbeddingModel, opts types.HybridSearchOptions) (*types.POISearchResult, error) {
+	args := m.Called(ctx, filter, queryEmbedding, model, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.POISearchResult), args.Error(1)
+}
+
 func (m *MockPOIRepository) UpdatePOIEmbedding(ctx context.Context, poiID uuid.UUID, embedding []float32) error {
 	args := m.Called(ctx, poiID, embedding)
 	return args.Error(0)
@@ -191,6 +225,45 @@ func (m *MockPOIRepository) GetHotelByID(ctx context.Context, hotelID uuid.UUID)
 	return args.Get(0).(*types.HotelDetailedInfo), args.Error(1)
 }
 
+func (m *MockPOIRepository) SearchHotels(ctx context.Context, cityID uuid.UUID, params types.HotelSearchParameters) ([]types.HotelDetailedInfo, *types.Cursor, int, error) {
+	args := m.Called(ctx, cityID, params)
+	next, _ := args.Get(1).(*types.Cursor)
+	if args.Get(0) == nil {
+		return nil, next, args.Int(2), args.Error(3)
+	}
+	return args.Get(0).([]types.HotelDetailedInfo), next, args.Int(2), args.Error(3)
+}
+
+func (m *MockPOIRepository) HotelNamesByCity(ctx context.Context, cityID uuid.UUID) ([]string, error) {
+	args := m.Called(ctx, cityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockPOIRepository) RestaurantNamesByCity(ctx context.Context, cityID uuid.UUID) ([]string, error) {
+	args := m.Called(ctx, cityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockPOIRepository) ReportRestaurantDietary(ctx context.Context, restaurantID, userID uuid.UUID, report types.DietaryReport) error {
+	args := m.Called(ctx, restaurantID, userID, report)
+	return args.Error(0)
+}
+
+func (m *MockPOIRepository) SearchRestaurants(ctx context.Context, cityID uuid.UUID, params types.RestaurantSearchParameters) ([]types.RestaurantDetailedInfo, *types.Cursor, int, error) {
+	args := m.Called(ctx, cityID, params)
+	next, _ := args.Get(1).(*types.Cursor)
+	if args.Get(0) == nil {
+		return nil, next, args.Int(2), args.Error(3)
+	}
+	return args.Get(0).([]types.RestaurantDetailedInfo), next, args.Int(2), args.Error(3)
+}
+
 func (m *MockPOIRepository) FindRestaurantDetails(ctx context.Context, cityID uuid.UUID, lat, lon, tolerance float64, preferences *types.RestaurantUserPreferences) ([]types.RestaurantDetailedInfo, error) {
 	args := m.Called(ctx, cityID, lat, lon, tolerance, preferences)
 	if args.Get(0) == nil {
@@ -220,12 +293,13 @@ func (m *MockPOIRepository) GetItinerary(ctx context.Context, userID, itineraryI
 	return args.Get(0).(*types.UserSavedItinerary), args.Error(1)
 }
 
-func (m *MockPOIRepository) GetItineraries(ctx context.Context, userID uuid.UUID, page, pageSize int) ([]types.UserSavedItinerary, int, error) {
-	args := m.Called(ctx, userID, page, pageSize)
+func (m *MockPOIRepository) GetItineraries(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]types.UserSavedItinerary, *types.Cursor, int, error) {
+	args := m.Called(ctx, userID, page)
 	if args.Get(0) == nil {
-		return nil, 0, args.Error(2)
+		return nil, nil, 0, args.Error(3)
 	}
-	return args.Get(0).([]types.UserSavedItinerary), args.Get(1).(int), args.Error(2)
+	next, _ := args.Get(1).(*types.Cursor)
+	return args.Get(0).([]types.UserSavedItinerary), next, args.Get(2).(int), args.Error(3)
 }
 
 func (m *MockPOIRepository) UpdateItinerary(ctx context.Context, userID uuid.UUID, itineraryID uuid.UUID, updates types.UpdateItineraryRequest) (*types.UserSavedItinerary, error) {
@@ -256,6 +330,24 @@ func (m *MockPOIRepository) CityExists(ctx context.Context, cityID uuid.UUID) (b
 	return args.Get(0).(bool), args.Error(1)
 }
 
+func (m *MockPOIRepository) FindPOIDetails(ctx context.Context, cityID uuid.UUID, lat, lon float64, tolerance float64) (*types.POIDetailedInfo, error) {
+	args := m.Called(ctx, cityID, lat, lon, tolerance)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.POIDetailedInfo), args.Error(1)
+}
+
+func (m *MockPOIRepository) SavePOIDetails(ctx context.Context, poi types.POIDetailedInfo, cityID uuid.NullUUID) (uuid.UUID, error) {
+	args := m.Called(ctx, poi, cityID)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockPOIRepository) CalculateDistancePostGIS(ctx context.Context, userLat, userLon, poiLat, poiLon float64) (float64, error) {
+	args := m.Called(ctx, userLat, userLon, poiLat, poiLon)
+	return args.Get(0).(float64), args.Error(1)
+}
+
 type MockCityRepository struct{ mock.Mock }
 
 func (m *MockCityRepository) SaveCity(ctx context.Context, city types.CityDetail) (uuid.UUID, error) {
@@ -276,16 +368,17 @@ func (m *MockCityRepository) GetCityIDByName(ctx context.Context, cityName strin
 	return args.Get(0).(uuid.UUID), args.Error(1)
 }
 
-func (m *MockCityRepository) GetAllCities(ctx context.Context) ([]types.CityDetail, error) {
-	args := m.Called(ctx)
+func (m *MockCityRepository) GetAllCities(ctx context.Context, page types.PageRequest) ([]types.CityDetail, *types.Cursor, error) {
+	args := m.Called(ctx, page)
 	if args.Get(0) == nil {
-		return nil, args.Error(1)
+		return nil, nil, args.Error(2)
 	}
-	return args.Get(0).([]types.CityDetail), args.Error(1)
+	next, _ := args.Get(1).(*types.Cursor)
+	return args.Get(0).([]types.CityDetail), next, args.Error(2)
 }
 
-func (m *MockCityRepository) FindSimilarCities(ctx context.Context, queryEmbedding []float32, limit int) ([]types.CityDetail, error) {
-	args := m.Called(ctx, queryEmbedding, limit)
+func (m *MockCityRepository) FindSimilarCities(ctx context.Context, queryEmbedding []float32, model types.EmbeddingModel, limit int) ([]types.CityDetail, error) {
+	args := m.Called(ctx, queryEmbedding, model, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -297,6 +390,14 @@ func (m *MockCityRepository) UpdateCityEmbedding(ctx context.Context, cityID uui
 	return args.Error(0)
 }
 
+func (m *MockCityRepository) GetCityByID(ctx context.Context, cityID uuid.UUID) (*types.CityDetail, error) {
+	args := m.Called(ctx, cityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.CityDetail), args.Error(1)
+}
+
 func (m *MockCityRepository) GetCitiesWithoutEmbeddings(ctx context.Context, limit int) ([]types.CityDetail, error) {
 	args := m.Called(ctx, limit)
 	if args.Get(0) == nil {
@@ -364,12 +465,13 @@ func (m *MockLLMInteractionRepository) GetSession(ctx context.Context, sessionID
 	return args.Get(0).(*types.ChatSession), args.Error(1)
 }
 
-func (m *MockLLMInteractionRepository) GetUserChatSessions(ctx context.Context, userID uuid.UUID) ([]types.ChatSession, error) {
-	args := m.Called(ctx, userID)
+func (m *MockLLMInteractionRepository) GetUserChatSessions(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]types.ChatSession, *types.Cursor, error) {
+	args := m.Called(ctx, userID, page)
 	if args.Get(0) == nil {
-		return nil, args.Error(1)
+		return nil, nil, args.Error(2)
 	}
-	return args.Get(0).([]types.ChatSession), args.Error(1)
+	next, _ := args.Get(1).(*types.Cursor)
+	return args.Get(0).([]types.ChatSession), next, args.Error(2)
 }
 
 func (m *MockLLMInteractionRepository) UpdateSession(ctx context.Context, session types.ChatSession) error {
@@ -428,6 +530,15 @@ func (m *MockinterestsRepo) GetAllInterests(ctx context.Context) ([]*types.Inter
 	return args.Get(0).([]*types.Interest), args.Error(1)
 }
 
+func (m *MockinterestsRepo) ListInterests(ctx context.Context, page types.PageRequest) ([]*types.Interest, *types.Cursor, error) {
+	args := m.Called(ctx, page)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	next, _ := args.Get(1).(*types.Cursor)
+	return args.Get(0).([]*types.Interest), next, args.Error(2)
+}
+
 func (m *MockinterestsRepo) GetInterest(ctx context.Context, interestID uuid.UUID) (*types.Interest, error) {
 	args := m.Called(ctx, interestID)
 	if args.Get(0) == nil {
@@ -456,12 +567,13 @@ func (m *MockinterestsRepo) GetInterestsForProfile(ctx context.Context, profileI
 
 type MockSearchProfileRepo struct{ mock.Mock }
 
-func (m *MockSearchProfileRepo) GetSearchProfiles(ctx context.Context, userID uuid.UUID) ([]types.UserPreferenceProfileResponse, error) {
-	args := m.Called(ctx, userID)
+func (m *MockSearchProfileRepo) GetSearchProfiles(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]types.UserPreferenceProfileResponse, *types.Cursor, error) {
+	args := m.Called(ctx, userID, page)
 	if args.Get(0) == nil {
-		return nil, args.Error(1)
+		return nil, nil, args.Error(2)
 	}
-	return args.Get(0).([]types.UserPreferenceProfileResponse), args.Error(1)
+	next, _ := args.Get(1).(*types.Cursor)
+	return args.Get(0).([]types.UserPreferenceProfileResponse), next, args.Error(2)
 }
 
 func (m *MockSearchProfileRepo) GetSearchProfile(ctx context.Context, userID, profileID uuid.UUID) (*types.UserPreferenceProfileResponse, error) {
@@ -513,6 +625,15 @@ func (m *MockTagsRepo) GetAll(ctx context.Context, userID uuid.UUID) ([]*types.T
 	return args.Get(0).([]*types.Tags), args.Error(1)
 }
 
+func (m *MockTagsRepo) List(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]*types.Tags, *types.Cursor, error) {
+	args := m.Called(ctx, userID, page)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	next, _ := args.Get(1).(*types.Cursor)
+	return args.Get(0).([]*types.Tags), next, args.Error(2)
+}
+
 func (m *MockTagsRepo) Get(ctx context.Context, userID, tagID uuid.UUID) (*types.Tags, error) {
 	args := m.Called(ctx, userID, tagID)
 	if args.Get(0) == nil {
@@ -590,6 +711,10 @@ func setupTestServiceWithMocks() (
 	// which means tests involving AI calls will be harder to unit test without mocking HTTP calls.
 	// A pragmatic approach for methods NOT heavily using AIClient directly for unit tests:
 	ctx := context.Background()
+	// NewAIClient exits without an API key; the unit tests never call the API
+	if os.Getenv("GOOGLE_GEMINI_API_KEY") == "" {
+		os.Setenv("GOOGLE_GEMINI_API_KEY", "test-key")
+	}
 	realAIC, _ := generativeAI.NewAIClient(ctx) // This will init real client (needs API key for New, but not for being a field)
 
 	service := &ServiceImpl{
@@ -609,7 +734,7 @@ func setupTestServiceWithMocks() (
 }
 
 func TestLlmInteractionServiceImpl_GetPOIDetailedInfosResponse_Unit(t *testing.T) {
-	service, mockAI, _, _, _, mockLLMRepo, mockCityRepo, mockPOIRepo := setupTestServiceWithMocks()
+	service, _, _, _, _, mockLLMRepo, mockCityRepo, mockPOIRepo := setupTestServiceWithMocks()
 	ctx := context.Background()
 	userID := uuid.New()
 	city := "Test City"
@@ -639,9 +764,9 @@ func TestLlmInteractionServiceImpl_GetPOIDetailedInfosResponse_Unit(t *testing.T
 		expectedDBDetails := &types.POIDetailedInfo{ID: expectedPOIID, Name: "DB POI", City: city, Latitude: lat, Longitude: lon}
 
 		// Mock CityRepo
-		mockCityRepo.On("FindCityByNameAndCountry", ctx, city, "").Return(&types.CityDetail{ID: uuid.New(), Name: city}, nil).Once()
+		mockCityRepo.On("FindCityByNameAndCountry", mock.Anything, city, "").Return(&types.CityDetail{ID: uuid.New(), Name: city}, nil).Once()
 		// Mock POIRepo to return data
-		mockPOIRepo.On("FindPOIDetailedInfos", ctx, mock.AnythingOfType("uuid.UUID"), lat, lon, 100.0).Return(expectedDBDetails, nil).Once()
+		mockPOIRepo.On("FindPOIDetails", mock.Anything, mock.AnythingOfType("uuid.UUID"), lat, lon, 100.0).Return(expectedDBDetails, nil).Once()
 
 		details, err := service.GetPOIDetailedInfosResponse(ctx, userID, city, lat, lon)
 		require.NoError(t, err)
@@ -663,27 +788,28 @@ func TestLlmInteractionServiceImpl_GetPOIDetailedInfosResponse_Unit(t *testing.T
 		aiResponseJSON := `{"name": "AI POI", "description": "From AI", "latitude": 10.0, "longitude": 20.0}`
 		mockGenAIResponse := &genai.GenerateContentResponse{
 			Candidates: []*genai.Candidate{
-				{Content: &genai.Content{Parts: []*genai.Part{genai.Text(aiResponseJSON)}}},
+				{Content: &genai.Content{Parts: []*genai.Part{genai.NewPartFromText(aiResponseJSON)}}},
 			},
 		}
+		_ = mockGenAIResponse
 		// This mocking assumes ServiceImpl.aiClient is an interface type
 		// and has been set to mockAI. If not, this mock won't be hit.
 		// For now, this test won't work as expected without that refactor.
-		// mockAI.On("GenerateResponse", ctx, mock.AnythingOfType("string"), mock.AnythingOfType("*genai.GenerateContentConfig")).Return(mockGenAIResponse, nil).Once()
+		// mockAI.On("GenerateResponse", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("*genai.GenerateContentConfig")).Return(mockGenAIResponse, nil).Once()
 
 		// Let's simulate what would happen if the AIClient interface was mocked:
 		// Setup for real AIClient to be called (if not mocking AIClient interface)
 		// This part would be an integration test or needs HTTP mocking.
 		// For a unit test, we absolutely need to mock the AI call.
 		// Assuming you *can* mock it (e.g. service.aiClient = mockAIClientThatReturnsSuccess):
-		if service.aiClient == nil { // Or not our mock
+		if service.aiClient == nil || !*runIntegrationTests { // Or not our mock
 			t.Skip("Skipping AI Call Success test: AIClient is not mockable in current service setup for unit test.")
 		}
 
-		mockCityRepo.On("FindCityByNameAndCountry", ctx, city, "").Return(&types.CityDetail{ID: uuid.New(), Name: city}, nil).Once()
-		mockPOIRepo.On("FindPOIDetailedInfos", ctx, mock.AnythingOfType("uuid.UUID"), lat, lon, 100.0).Return(nil, nil).Once() // DB Miss
-		mockLLMRepo.On("SaveInteraction", ctx, mock.AnythingOfType("types.LlmInteraction")).Return(uuid.New(), nil).Once()
-		mockPOIRepo.On("SavePOIDetailedInfos", ctx, mock.AnythingOfType("types.POIDetailedInfo"), mock.AnythingOfType("uuid.UUID")).Return(uuid.New(), nil).Once()
+		mockCityRepo.On("FindCityByNameAndCountry", mock.Anything, city, "").Return(&types.CityDetail{ID: uuid.New(), Name: city}, nil).Once()
+		mockPOIRepo.On("FindPOIDetails", mock.Anything, mock.AnythingOfType("uuid.UUID"), lat, lon, 100.0).Return(nil, nil).Once() // DB Miss
+		mockLLMRepo.On("SaveInteraction", mock.Anything, mock.AnythingOfType("types.LlmInteraction")).Return(uuid.New(), nil).Once()
+		mockPOIRepo.On("SavePOIDetails", mock.Anything, mock.AnythingOfType("types.POIDetailedInfo"), mock.AnythingOfType("uuid.NullUUID")).Return(uuid.New(), nil).Once()
 
 		details, err := service.GetPOIDetailedInfosResponse(ctx, userID, city, lat, lon)
 		// This will fail if the AI call is real and not mocked, or if API key is missing.
@@ -717,43 +843,420 @@ func TestLlmInteractionServiceImpl_GetPOIDetailedInfosResponse_Unit(t *testing.T
 	// - SavePOIDetailedInfos fails
 }
 
-// Example for GetItinerary (simpler, as it's mostly a direct repo call)
-func TestLlmInteractionServiceImpl_GetItinerary_Unit(t *testing.T) {
-	service, _, _, _, _, mockLLMRepo, _, _ := setupTestServiceWithMocks()
+func TestLlmInteractionServiceImpl_SearchHotels_Unit(t *testing.T) {
 	ctx := context.Background()
 	userID := uuid.New()
-	itineraryID := uuid.New()
+	city := &types.CityDetail{ID: uuid.New(), Name: "Lisbon", Country: "Portugal"}
+
+	t.Run("enough stored hotels skip the AI", func(t *testing.T) {
+		service, _, _, _, _, _, mockCityRepo, mockPOIRepo := setupTestServiceWithMocks()
+		minRating := 4.0
+		params := types.HotelSearchParameters{City: "Lisbon", MinRating: &minRating, Amenities: []string{"wifi"}, Page: types.PageRequest{Limit: 500}}
+		expected := params
+		expected.Page.Limit = maxHotelPageLimit
+		rating := 4.5
+		next := &types.Cursor{Num: &rating, Key: "Hotel Avenida", ID: uuid.New()}
+
+		mockCityRepo.On("FindCityByNameAndCountry", mock.Anything, "Lisbon", "").Return(city, nil).Once()
+		mockPOIRepo.On("SearchHotels", mock.Anything, city.ID, expected).
+			Return([]types.HotelDetailedInfo{{Name: "Hotel Avenida", Rating: 4.5}}, next, minHotelResults, nil).Once()
 
-	t.Run("Itinerary found", func(t *testing.T) {
-		expectedItinerary := &types.UserSavedItinerary{ID: itineraryID, UserID: userID, Title: "My Test Itinerary"}
-		mockLLMRepo.On("GetItinerary", ctx, userID, itineraryID).Return(expectedItinerary, nil).Once()
+		resp, err := service.SearchHotels(ctx, userID, params)
 
-		itinerary, err := service.GetItinerary(ctx, userID, itineraryID)
 		require.NoError(t, err)
-		require.NotNil(t, itinerary)
-		assert.Equal(t, "My Test Itinerary", itinerary.Title)
-		mockLLMRepo.AssertExpectations(t)
+		require.Len(t, resp.Hotels, 1)
+		assert.Equal(t, "Lisbon", resp.Hotels[0].City)
+		assert.Equal(t, minHotelResults, resp.TotalRecords)
+		assert.Equal(t, maxHotelPageLimit, resp.Limit)
+		assert.Equal(t, next, resp.NextCursor)
+		mockPOIRepo.AssertNumberOfCalls(t, "SaveHotelDetails", 0)
+		mockPOIRepo.AssertExpectations(t)
 	})
 
-	t.Run("Itinerary not found", func(t *testing.T) {
-		notFoundErr := fmt.Errorf("no itinerary found with ID %s for user %s", itineraryID, userID) // Match repo error
-		mockLLMRepo.On("GetItinerary", ctx, userID, itineraryID).Return(nil, notFoundErr).Once()
+	t.Run("later pages are not topped up", func(t *testing.T) {
+		service, _, _, _, _, _, mockCityRepo, mockPOIRepo := setupTestServiceWithMocks()
+		distance := 1.2
+		params := types.HotelSearchParameters{City: "Lisbon", Page: types.PageRequest{Cursor: &types.Cursor{Num: &distance, ID: uuid.New()}, Limit: 2}}
 
-		_, err := service.GetItinerary(ctx, userID, itineraryID)
-		require.Error(t, err)
-		assert.EqualError(t, err, notFoundErr.Error())
-		mockLLMRepo.AssertExpectations(t)
+		mockCityRepo.On("FindCityByNameAndCountry", mock.Anything, "Lisbon", "").Return(city, nil).Once()
+		mockPOIRepo.On("SearchHotels", mock.Anything, city.ID, params).Return([]types.HotelDetailedInfo{}, nil, 4, nil).Once()
+
+		resp, err := service.SearchHotels(ctx, userID, params)
+
+		require.NoError(t, err)
+		assert.Empty(t, resp.Hotels)
+		assert.Equal(t, 4, resp.TotalRecords)
+		mockPOIRepo.AssertExpectations(t)
 	})
 
-	t.Run("Repository returns other error", func(t *testing.T) {
-		dbErr := errors.New("database connection error")
-		mockLLMRepo.On("GetItinerary", ctx, userID, itineraryID).Return(nil, dbErr).Once()
+	t.Run("missing and unknown cities", func(t *testing.T) {
+		service, _, _, _, _, _, mockCityRepo, _ := setupTestServiceWithMocks()
 
-		_, err := service.GetItinerary(ctx, userID, itineraryID)
-		require.Error(t, err)
-		assert.EqualError(t, err, "database connection error") // Or however service wraps it
-		mockLLMRepo.AssertExpectations(t)
+		_, err := service.SearchHotels(ctx, userID, types.HotelSearchParameters{City: " "})
+		assert.ErrorIs(t, err, types.ErrBadRequest)
+
+		mockCityRepo.On("FindCityByNameAndCountry", mock.Anything, "Atlantis", "").Return(nil, nil).Once()
+		_, err = service.SearchHotels(ctx, userID, types.HotelSearchParameters{City: "Atlantis"})
+		assert.ErrorIs(t, err, types.ErrNotFound)
+	})
+}
+
+func TestHotelSearchFromPreferences(t *testing.T) {
+	params := hotelSearchFromPreferences("Lisbon", 38.71, -9.14, types.HotelUserPreferences{MaxPriceRange: "$$$", MinRating: 4})
+
+	assert.Equal(t, []string{"$", "$$", "$$$"}, params.PriceRanges)
+	require.NotNil(t, params.MinRating)
+	assert.Equal(t, 4.0, *params.MinRating)
+	assert.Equal(t, defaultHotelRadiusKm, params.RadiusKm)
+
+	params = hotelSearchFromPreferences("Lisbon", 38.71, -9.14, types.HotelUserPreferences{MaxPriceRange: "moderate", SearchRadiusKm: 2})
+	assert.Empty(t, params.PriceRanges)
+	assert.Nil(t, params.MinRating)
+	assert.Equal(t, 2.0, params.RadiusKm)
+}
+
+func TestLlmInteractionServiceImpl_SearchRestaurants_Unit(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	city := &types.CityDetail{ID: uuid.New(), Name: "Lisbon", Country: "Portugal"}
+
+	t.Run("profile dining preferences become filters", func(t *testing.T) {
+		service, _, _, mockProfileRepo, _, _, mockCityRepo, mockPOIRepo := setupTestServiceWithMocks()
+		openNow := true
+		params := types.RestaurantSearchParameters{City: "Lisbon", Cuisines: []string{"Portuguese"}, OpenNow: &openNow, ProfileID: uuid.New()}
+		profile := &types.UserPreferenceProfileResponse{
+			DietaryNeeds: []string{"vegetarian"},
+			DiningPreferences: &types.DiningPreferences{
+				DietaryNeeds:       []string{"vegan"},
+				AllergenFree:       []string{"gluten"},
+				ServiceStyle:       []string{"casual"},
+				OutdoorSeatingPref: true,
+			},
+		}
+		expected := params
+		expected.DietaryNeeds = []string{"vegetarian", "vegan"}
+		expected.AllergenFree = []string{"gluten"}
+		expected.ServiceStyles = []string{"casual"}
+		expected.Features = []string{"outdoor_seating"}
+		expected.Page.Limit = defaultRestaurantPageLimit
+
+		mockProfileRepo.On("GetSearchProfile", mock.Anything, userID, params.ProfileID).Return(profile, nil).Once()
+		mockCityRepo.On("FindCityByNameAndCountry", mock.Anything, "Lisbon", "").Return(city, nil).Once()
+		mockPOIRepo.On("SearchRestaurants", mock.Anything, city.ID, expected).
+			Return([]types.RestaurantDetailedInfo{{
+				Name: "Ao 26 Vegan Food Project", Rating: 4.7, DietaryOptions: []string{"vegan", "vegetarian"},
+				AllergenFree: []string{"gluten"}, AllergenConfirmed: []string{"gluten"},
+			}}, nil, minRestaurantResults, nil).Once()
+
+		resp, err := service.SearchRestaurants(ctx, userID, params)
+
+		require.NoError(t, err)
+		require.Len(t, resp.Restaurants, 1)
+		assert.Equal(t, "Lisbon", resp.Restaurants[0].City)
+		assert.Equal(t, minRestaurantResults, resp.TotalRecords)
+		mockPOIRepo.AssertNumberOfCalls(t, "SaveRestaurantDetails", 0)
+		mockProfileRepo.AssertExpectations(t)
+		mockPOIRepo.AssertExpectations(t)
+	})
+
+	t.Run("later pages are not topped up", func(t *testing.T) {
+		service, _, _, _, _, _, mockCityRepo, mockPOIRepo := setupTestServiceWithMocks()
+		rating := 4.2
+		params := types.RestaurantSearchParameters{City: "Lisbon", Page: types.PageRequest{Cursor: &types.Cursor{Num: &rating, Key: "Cervejaria Ramiro", ID: uuid.New()}, Limit: 500}}
+		expected := params
+		expected.Page.Limit = maxRestaurantPageLimit
+
+		mockCityRepo.On("FindCityByNameAndCountry", mock.Anything, "Lisbon", "").Return(city, nil).Once()
+		mockPOIRepo.On("SearchRestaurants", mock.Anything, city.ID, expected).Return([]types.RestaurantDetailedInfo{}, nil, 3, nil).Once()
+
+		resp, err := service.SearchRestaurants(ctx, userID, params)
+
+		require.NoError(t, err)
+		assert.Empty(t, resp.Restaurants)
+		assert.Equal(t, maxRestaurantPageLimit, resp.Limit)
+		assert.Nil(t, resp.NextCursor)
+		mockPOIRepo.AssertExpectations(t)
+	})
+
+	t.Run("allergen searches are not topped up", func(t *testing.T) {
+		service, _, _, _, _, _, mockCityRepo, mockPOIRepo := setupTestServiceWithMocks()
+		params := types.RestaurantSearchParameters{City: "Lisbon", AllergenFree: []string{"peanuts"}}
+		expected := params
+		expected.Page.Limit = defaultRestaurantPageLimit
+
+		mockCityRepo.On("FindCityByNameAndCountry", mock.Anything, "Lisbon", "").Return(city, nil).Once()
+		mockPOIRepo.On("SearchRestaurants", mock.Anything, city.ID, expected).Return([]types.RestaurantDetailedInfo{}, nil, 0, nil).Once()
+
+		resp, err := service.SearchRestaurants(ctx, userID, params)
+
+		require.NoError(t, err)
+		assert.Empty(t, resp.Restaurants)
+		mockPOIRepo.AssertNotCalled(t, "RestaurantNamesByCity", mock.Anything, mock.Anything)
+		mockPOIRepo.AssertExpectations(t)
+	})
+
+	t.Run("missing city and unknown profile", func(t *testing.T) {
+		service, _, _, mockProfileRepo, _, _, _, _ := setupTestServiceWithMocks()
+
+		_, err := service.SearchRestaurants(ctx, userID, types.RestaurantSearchParameters{City: ""})
+		assert.ErrorIs(t, err, types.ErrBadRequest)
+
+		profileID := uuid.New()
+		mockProfileRepo.On("GetSearchProfile", mock.Anything, userID, profileID).
+			Return(nil, fmt.Errorf("preference profile not found: %w", types.ErrNotFound)).Once()
+		_, err = service.SearchRestaurants(ctx, userID, types.RestaurantSearchParameters{City: "Lisbon", ProfileID: profileID})
+		assert.ErrorIs(t, err, types.ErrNotFound)
+	})
+}
+
+func TestLlmInteractionServiceImpl_ReportRestaurantDietary_Unit(t *testing.T) {
+	ctx := context.Background()
+	userID, restaurantID := uuid.New(), uuid.New()
+
+	t.Run("normalizes and saves the report", func(t *testing.T) {
+		service, _, _, _, _, _, _, mockPOIRepo := setupTestServiceWithMocks()
+		report := types.DietaryReport{Kind: types.DietaryKindAllergenFree, Attribute: "Gluten", Available: false, Note: " Shared fryer "}
+		saved := types.DietaryReport{Kind: types.DietaryKindAllergenFree, Attribute: "gluten", Available: false, Note: "Shared fryer"}
+		restaurant := &types.RestaurantDetailedInfo{ID: restaurantID, Dietary: []types.DietaryAttribute{
+			{Kind: types.DietaryKindAllergenFree, Attribute: "gluten", Available: false, Source: types.DietarySourceUser},
+		}}
+
+		mockPOIRepo.On("ReportRestaurantDietary", mock.Anything, restaurantID, userID, saved).Return(nil).Once()
+		mockPOIRepo.On("GetRestaurantByID", mock.Anything, restaurantID).Return(restaurant, nil).Once()
+
+		got, err := service.ReportRestaurantDietary(ctx, userID, restaurantID, report)
+
+		require.NoError(t, err)
+		assert.Equal(t, restaurant, got)
+		mockPOIRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects unknown kinds and empty attributes", func(t *testing.T) {
+		service, _, _, _, _, _, _, mockPOIRepo := setupTestServiceWithMocks()
+
+		_, err := service.ReportRestaurantDietary(ctx, userID, restaurantID, types.DietaryReport{Kind: "halal", Attribute: "halal"})
+		assert.ErrorIs(t, err, types.ErrBadRequest)
+		_, err = service.ReportRestaurantDietary(ctx, userID, restaurantID, types.DietaryReport{Kind: types.DietaryKindDiet, Attribute: " - "})
+		assert.ErrorIs(t, err, types.ErrBadRequest)
+		mockPOIRepo.AssertNotCalled(t, "ReportRestaurantDietary", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown restaurant", func(t *testing.T) {
+		service, _, _, _, _, _, _, mockPOIRepo := setupTestServiceWithMocks()
+		report := types.DietaryReport{Kind: types.DietaryKindDiet, Attribute: "vegan", Available: true}
+		mockPOIRepo.On("ReportRestaurantDietary", mock.Anything, restaurantID, userID, report).
+			Return(fmt.Errorf("restaurant %s not found: %w", restaurantID, types.ErrNotFound)).Once()
+
+		_, err := service.ReportRestaurantDietary(ctx, userID, restaurantID, report)
+		assert.ErrorIs(t, err, types.ErrNotFound)
+	})
+}
+
+func TestCatersFor(t *testing.T) {
+	restaurant := types.RestaurantDetailedInfo{DietaryOptions: []string{"vegan", "gluten_free"}, AllergenFree: []string{"nuts", "dairy"}, AllergenConfirmed: []string{"nuts"}}
+
+	assert.True(t, catersFor(restaurant, types.RestaurantSearchParameters{DietaryNeeds: []string{"Gluten Free"}, AllergenFree: []string{"nuts"}}))
+	assert.True(t, catersFor(restaurant, types.RestaurantSearchParameters{}))
+	assert.False(t, catersFor(restaurant, types.RestaurantSearchParameters{DietaryNeeds: []string{"halal"}}))
+	assert.False(t, catersFor(restaurant, types.RestaurantSearchParameters{AllergenFree: []string{"gluten"}}))
+	assert.False(t, catersFor(restaurant, types.RestaurantSearchParameters{AllergenFree: []string{"dairy"}}), "LLM-inferred allergens are unconfirmed")
+}
+
+func TestLlmInteractionServiceImpl_HonourAccessibility_Unit(t *testing.T) {
+	ctx := context.Background()
+	pois := []types.POIDetailedInfo{
+		{Name: "Museum", Accessibility: map[string]bool{types.AccessibilityStepFreeEntrance: false}},
+		{Name: "Castle", Accessibility: map[string]bool{types.AccessibilityStepFreeEntrance: false}},
+		{Name: "Market"},
+	}
+	// What is stored about the museum overrides the LLM
+	known := map[int]map[string]bool{0: {types.AccessibilityStepFreeEntrance: true}}
+
+	t.Run("drops POIs lacking a needed feature and marks unknown ones", func(t *testing.T) {
+		service, _, _, _, _, _, _, mockPOIRepo := setupTestServiceWithMocks()
+		mockPOIRepo.On("KnownAccessibility", mock.Anything, pois).Return(known, nil).Once()
+		needs := &types.AccessibilityNeeds{Features: []string{types.AccessibilityStepFreeEntrance}}
+
+		got := service.honourAccessibility(ctx, needs, pois)
+
+		require.Len(t, got, 2)
+		assert.Equal(t, "Museum", got[0].Name)
+		assert.Equal(t, types.AccessStatusAccessible, got[0].AccessStatus)
+		assert.Equal(t, "Market", got[1].Name)
+		assert.Equal(t, types.AccessStatusUnknown, got[1].AccessStatus)
+	})
+
+	t.Run("accessible only drops unknown POIs", func(t *testing.T) {
+		service, _, _, _, _, _, _, mockPOIRepo := setupTestServiceWithMocks()
+		mockPOIRepo.On("KnownAccessibility", mock.Anything, pois).Return(known, nil).Once()
+		needs := &types.AccessibilityNeeds{Features: []string{types.AccessibilityStepFreeEntrance}, AccessibleOnly: true}
+
+		got := service.honourAccessibility(ctx, needs, pois)
+
+		require.Len(t, got, 1)
+		assert.Equal(t, "Museum", got[0].Name)
+	})
+
+	t.Run("without needs POIs are left alone", func(t *testing.T) {
+		service, _, _, _, _, _, _, mockPOIRepo := setupTestServiceWithMocks()
+
+		got := service.honourAccessibility(ctx, &types.AccessibilityNeeds{}, pois)
+
+		assert.Equal(t, pois, got)
+		mockPOIRepo.AssertNotCalled(t, "KnownAccessibility", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetAccessibilityNeedsPrompt(t *testing.T) {
+	assert.Empty(t, getAccessibilityNeedsPrompt(nil))
+
+	prompt := getAccessibilityNeedsPrompt(&types.AccessibilityNeeds{
+		Features:       []string{types.AccessibilityStepFreeEntrance, types.AccessibilityHearingLoop},
+		AccessibleOnly: true,
+	})
+	assert.Contains(t, prompt, "[step_free_entrance, hearing_loop]")
+	assert.Contains(t, prompt, "Only suggest places known to have all of them.")
+}
+
+func TestLlmInteractionServiceImpl_AdaptToWeather_Unit(t *testing.T) {
+	ctx := context.Background()
+	rainy, err := weather.NewFileProvider("../weather/testdata/rainy.json")
+	require.NoError(t, err)
+
+	newItinerary := func() types.AiCityResponse {
+		return types.AiCityResponse{
+			GeneralCityData: types.GeneralCityData{City: "Lisbon", CenterLatitude: 38.72, CenterLongitude: -9.14, Weather: "Sunny, probably"},
+			PointsOfInterest: []types.POIDetailedInfo{
+				{Name: "Far Gallery", Category: "Art Gallery", Latitude: 38.80, Longitude: -9.30},
+				{Name: "Tile Museum", Category: "Museum", Latitude: 38.725, Longitude: -9.11},
+				{Name: "Jardim da Estrela", Category: "Park", Latitude: 38.71, Longitude: -9.16},
+			},
+			AIItineraryResponse: types.AIItineraryResponse{
+				PointsOfInterest: []types.POIDetailedInfo{
+					{Name: "Miradouro da Graca", Category: "Viewpoint", Latitude: 38.716, Longitude: -9.131},
+					{Name: "Carmo Convent", Category: "Museum", Latitude: 38.712, Longitude: -9.140},
+					{Name: "Monsanto Park", Category: "Park", Tags: []string{"hiking"}, Latitude: 38.73, Longitude: -9.19},
+				},
+			},
+		}
+	}
+
+	t.Run("rainy day swaps outdoor stops for the nearest indoor POIs", func(t *testing.T) {
+		service, _, _, _, _, _, _, _ := setupTestServiceWithMocks()
+		service.weather = rainy
+		itinerary := newItinerary()
+
+		service.adaptToWeather(ctx, &itinerary, nil, nil)
+
+		require.NotNil(t, itinerary.GeneralCityData.Forecast)
+		assert.Equal(t, "Today: rain, 12-16°C, 90% chance of precipitation", itinerary.GeneralCityData.Weather)
+		names := make([]string, 0, 3)
+		for _, p := range itinerary.AIItineraryResponse.PointsOfInterest {
+			names = append(names, p.Name)
+		}
+		assert.Equal(t, []string{"Tile Museum", "Carmo Convent", "Far Gallery"}, names)
+		assert.Equal(t, []types.WeatherSwap{
+			{Replaced: "Miradouro da Graca", Replacement: "Tile Museum", Reason: "rain forecast today"},
+			{Replaced: "Monsanto Park", Replacement: "Far Gallery", Reason: "rain forecast today"},
+		}, itinerary.AIItineraryResponse.WeatherSwaps)
+	})
+
+	t.Run("users preferring outdoors keep their stops", func(t *testing.T) {
+		service, _, _, _, _, _, _, _ := setupTestServiceWithMocks()
+		service.weather = rainy
+		itinerary := newItinerary()
+		profile := &types.UserPreferenceProfileResponse{
+			ActivityPreferences: &types.ActivityPreferences{IndoorOutdoorPref: "outdoor"},
+		}
+
+		service.adaptToWeather(ctx, &itinerary, profile, nil)
+
+		assert.NotNil(t, itinerary.GeneralCityData.Forecast)
+		assert.Equal(t, newItinerary().AIItineraryResponse.PointsOfInterest, itinerary.AIItineraryResponse.PointsOfInterest)
+		assert.Empty(t, itinerary.AIItineraryResponse.WeatherSwaps)
+	})
+
+	t.Run("a rainy travel day swaps outdoor stops", func(t *testing.T) {
+		service, _, _, _, _, _, _, _ := setupTestServiceWithMocks()
+		service.weather = rainy
+		itinerary := newItinerary()
+
+		service.adaptToWeather(ctx, &itinerary, nil, &types.TravelDates{Start: "2025-11-03"})
+
+		assert.Equal(t, "2025-11-03: rain, 12-16°C, 90% chance of precipitation", itinerary.GeneralCityData.Weather)
+		require.Len(t, itinerary.AIItineraryResponse.WeatherSwaps, 2)
+		assert.Equal(t, "rain forecast on 2025-11-03", itinerary.AIItineraryResponse.WeatherSwaps[0].Reason)
+	})
+
+	t.Run("dry travel days keep outdoor stops despite rain today", func(t *testing.T) {
+		service, _, _, _, _, _, _, _ := setupTestServiceWithMocks()
+		service.weather = rainy
+		itinerary := newItinerary()
+
+		service.adaptToWeather(ctx, &itinerary, nil, &types.TravelDates{Start: "2025-11-03", End: "2025-11-05"})
+
+		assert.Equal(t, "2025-11-03: rain, 12-16°C, 90% chance of precipitation; "+
+			"2025-11-04: cloudy, 11-17°C, 30% chance of precipitation; "+
+			"2025-11-05: clear, 10-19°C, 5% chance of precipitation", itinerary.GeneralCityData.Weather)
+		assert.Equal(t, newItinerary().AIItineraryResponse.PointsOfInterest, itinerary.AIItineraryResponse.PointsOfInterest)
+		assert.Empty(t, itinerary.AIItineraryResponse.WeatherSwaps)
+	})
+
+	t.Run("trips past the forecast are left alone", func(t *testing.T) {
+		service, _, _, _, _, _, _, _ := setupTestServiceWithMocks()
+		service.weather = rainy
+		itinerary := newItinerary()
+
+		service.adaptToWeather(ctx, &itinerary, nil, &types.TravelDates{Start: "2025-12-20", End: "2025-12-22"})
+
+		assert.NotNil(t, itinerary.GeneralCityData.Forecast)
+		assert.Equal(t, "Sunny, probably", itinerary.GeneralCityData.Weather)
+		assert.Equal(t, newItinerary().AIItineraryResponse.PointsOfInterest, itinerary.AIItineraryResponse.PointsOfInterest)
+		assert.Empty(t, itinerary.AIItineraryResponse.WeatherSwaps)
+	})
+
+	t.Run("without a provider the itinerary is left alone", func(t *testing.T) {
+		service, _, _, _, _, _, _, _ := setupTestServiceWithMocks()
+		itinerary := newItinerary()
+
+		service.adaptToWeather(ctx, &itinerary, nil, nil)
+
+		assert.Equal(t, newItinerary(), itinerary)
+	})
+}
+
+func TestSwapOutdoorPOIs(t *testing.T) {
+	pois := []types.POIDetailedInfo{
+		{Name: "Beach", Category: "Beach"},
+		{Name: "Indoor Market", Category: "Market", Tags: []string{"indoor"}},
+	}
+	alternatives := []types.POIDetailedInfo{{Name: "Indoor Market", Category: "Market", Tags: []string{"indoor"}}}
+
+	// The only indoor alternative is already on the itinerary
+	got, swaps := swapOutdoorPOIs(pois, alternatives, "rain")
+	assert.Equal(t, pois, got)
+	assert.Empty(t, swaps)
+}
+
+func TestRestaurantSearchFromPreferences(t *testing.T) {
+	params := restaurantSearchFromPreferences("Lisbon", 38.71, -9.14, types.RestaurantUserPreferences{
+		PreferredCuisine:    "Portuguese, Seafood",
+		PreferredPriceRange: "$$",
+		DietaryRestrictions: "no pork",
+	})
+
+	assert.Equal(t, []string{"Portuguese", "Seafood"}, params.Cuisines)
+	assert.Equal(t, []string{"$$"}, params.PriceRanges)
+	assert.Empty(t, params.DietaryNeeds, "free-text restrictions only tailor the prompt")
+	assert.Equal(t, defaultRestaurantRadiusKm, params.RadiusKm)
+
+	prefs := restaurantPreferencesFromSearch(types.RestaurantSearchParameters{
+		DietaryNeeds: []string{"vegan"},
+		AllergenFree: []string{"nuts"},
+		Features:     []string{"outdoor_seating"},
 	})
+	assert.Equal(t, "vegan, nuts-free", prefs.DietaryRestrictions)
+	assert.Equal(t, "outdoor_seating", prefs.SpecialFeatures)
 }
 
 // Add similar unit tests for:
@@ -761,8 +1264,8 @@ func TestLlmInteractionServiceImpl_GetItinerary_Unit(t *testing.T) {
 // - UpdateItinerary
 // - SaveItenerary
 // - RemoveItenerary
-// - GetHotelsByPreferenceResponse (mocking repo's FindHotelDetails, and AI call if fallback)
-// - GetRestaurantsByPreferencesResponse (mocking repo's FindRestaurantDetails, and AI call if fallback)
+// - GetHotelsByPreferenceResponse (mocking repo's SearchHotels, and AI call if fallback)
+// - GetRestaurantsByPreferencesResponse (mocking repo's SearchRestaurants, and AI call if fallback)
 // - etc.
 
 // --- Integration Tests for llmInteraction (Example for GetPOIDetailedInfosResponse) ---
@@ -0,0 +1,85 @@
+package llmChat
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+// detailTopUp describes how to store one kind of LLM generated details, such
+// as hotels or restaurants, when a city has too few of them.
+type detailTopUp[T any] struct {
+	kind string // Plural, for logs and errors
+	// name and err read a generated item
+	name func(T) string
+	err  func(T) error
+	// storedNames lists the normalised names the city already has
+	storedNames func(ctx context.Context) ([]string, error)
+	save        func(ctx context.Context, item T) error
+	// search runs the original search again
+	search func(ctx context.Context) ([]T, *types.Cursor, int, error)
+}
+
+// normalizedName is how details are matched by name.
+func normalizedName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// topUpDetails stores the generated items whose names the city does not have
+// yet, then runs the search again so the new items are filtered and ordered
+// with the stored ones. found, next and total, the first search's results, are
+// kept when nothing was generated or saved, and returned with the error only
+// when they are empty.
+func topUpDetails[T any](ctx context.Context, logger *slog.Logger, t detailTopUp[T], generated, found []T, next *types.Cursor, total int) ([]T, *types.Cursor, int, error) {
+	if len(generated) == 0 || t.err(generated[0]) != nil {
+		err := fmt.Errorf("no %s received for %s search", t.kind, t.kind)
+		if len(generated) > 0 {
+			err = t.err(generated[0])
+		}
+		if total == 0 {
+			return nil, nil, 0, err
+		}
+		logger.WarnContext(ctx, "Failed to top up from AI, serving stored results", slog.String("kind", t.kind), slog.Any("error", err))
+		return found, next, total, nil
+	}
+
+	// Found is only the filtered page; the city may hold the same names
+	// outside it, so check against everything stored.
+	stored, err := t.storedNames(ctx)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to list stored names, checking the results found only", slog.String("kind", t.kind), slog.Any("error", err))
+		for _, item := range found {
+			stored = append(stored, normalizedName(t.name(item)))
+		}
+	}
+	known := make(map[string]bool, len(stored))
+	for _, name := range stored {
+		known[name] = true
+	}
+
+	saved := 0
+	for _, item := range generated {
+		key := normalizedName(t.name(item))
+		if key == "" || known[key] {
+			continue
+		}
+		known[key] = true
+		if err := t.save(ctx, item); err != nil {
+			logger.WarnContext(ctx, "Failed to save generated details", slog.String("kind", t.kind), slog.String("name", t.name(item)), slog.Any("error", err))
+			continue
+		}
+		saved++
+	}
+	if saved == 0 {
+		return found, next, total, nil
+	}
+
+	items, next, total, err := t.search(ctx)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to search %s: %w", t.kind, err)
+	}
+	return items, next, total, nil
+}
@@ -7,6 +7,8 @@ import (
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api"
 )
 
 type Handler struct {
@@ -21,7 +23,9 @@ func NewCityHandler(service Service, logger *slog.Logger) *Handler {
 	}
 }
 
-// GetAllCities handles GET /cities - returns all cities from the database
+// GetAllCities handles GET /cities - returns a page of cities by name; the Link
+// header carries the next page. Query: cursor (from the Link header), limit
+// (default 100, max 500).
 func (h *Handler) GetAllCities(w http.ResponseWriter, r *http.Request) {
 	ctx, span := otel.Tracer("CityHandler").Start(r.Context(), "GetAllCities")
 	defer span.End()
@@ -35,9 +39,16 @@ func (h *Handler) GetAllCities(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	l.InfoContext(ctx, "Retrieving all cities")
+	page, err := api.ParsePageRequest(r)
+	if err != nil {
+		span.SetStatus(codes.Error, "Invalid cursor")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	l.InfoContext(ctx, "Retrieving cities")
 
-	cities, err := h.service.GetAllCities(ctx)
+	cities, next, err := h.service.GetAllCities(ctx, page)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to retrieve cities", slog.Any("error", err))
 		span.RecordError(err)
@@ -50,6 +61,7 @@ func (h *Handler) GetAllCities(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
 	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	api.SetPageLinks(w, r, next)
 
 	if err := json.NewEncoder(w).Encode(cities); err != nil {
 		l.ErrorContext(ctx, "Failed to encode cities response", slog.Any("error", err))
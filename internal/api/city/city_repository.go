@@ -3,6 +3,7 @@ package city
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -22,11 +23,14 @@ var _ Repository = (*RepositoryImpl)(nil)
 type Repository interface {
 	SaveCity(ctx context.Context, city types.CityDetail) (uuid.UUID, error)
 	FindCityByNameAndCountry(ctx context.Context, city, country string) (*types.CityDetail, error)
+	GetCityByID(ctx context.Context, cityID uuid.UUID) (*types.CityDetail, error)
 	GetCityIDByName(ctx context.Context, cityName string) (uuid.UUID, error)
-	GetAllCities(ctx context.Context) ([]types.CityDetail, error)
+	// GetAllCities returns a page of the cities with a known location, by name,
+	// and the cursor of the next page.
+	GetAllCities(ctx context.Context, page types.PageRequest) ([]types.CityDetail, *types.Cursor, error)
 
 	// Vector similarity search methods
-	FindSimilarCities(ctx context.Context, queryEmbedding []float32, limit int) ([]types.CityDetail, error)
+	FindSimilarCities(ctx context.Context, queryEmbedding []float32, model types.EmbeddingModel, limit int) ([]types.CityDetail, error)
 	UpdateCityEmbedding(ctx context.Context, cityID uuid.UUID, embedding []float32) error
 	GetCitiesWithoutEmbeddings(ctx context.Context, limit int) ([]types.CityDetail, error)
 
@@ -188,6 +192,42 @@ func (r *RepositoryImpl) FindCityByNameAndCountry(ctx context.Context, cityName,
 	return &cityDetail, nil
 }
 
+// GetCityByID retrieves a city by its ID, returning ErrNotFound when there is none
+func (r *RepositoryImpl) GetCityByID(ctx context.Context, cityID uuid.UUID) (*types.CityDetail, error) {
+	ctx, span := otel.Tracer("CityRepository").Start(ctx, "GetCityByID", trace.WithAttributes(
+		attribute.String("city.id", cityID.String()),
+	))
+	defer span.End()
+
+	query := `
+        SELECT id, name, country, COALESCE(state_province, ''), ai_summary,
+               ST_Y(center_location), ST_X(center_location)
+        FROM cities
+        WHERE id = $1
+    `
+	var cityDetail types.CityDetail
+	var lat, lon sql.NullFloat64
+	err := r.pgpool.QueryRow(ctx, query, cityID).Scan(
+		&cityDetail.ID,
+		&cityDetail.Name,
+		&cityDetail.Country,
+		&cityDetail.StateProvince,
+		&cityDetail.AiSummary,
+		&lat,
+		&lon,
+	)
+	if err != nil {
+		span.RecordError(err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("city %s: %w", cityID, types.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get city %s: %w", cityID, err)
+	}
+	cityDetail.CenterLatitude = lat.Float64
+	cityDetail.CenterLongitude = lon.Float64
+	return &cityDetail, nil
+}
+
 // GetCityIDByName retrieves a city ID by its name
 func (r *RepositoryImpl) GetCityIDByName(ctx context.Context, cityName string) (uuid.UUID, error) {
 	ctx, span := otel.Tracer("CityRepository").Start(ctx, "GetCityIDByName", trace.WithAttributes(
@@ -233,9 +273,10 @@ func (r *RepositoryImpl) GetCityIDByName(ctx context.Context, cityName string) (
 }
 
 // FindSimilarCities finds cities similar to the provided query embedding using cosine similarity
-func (r *RepositoryImpl) FindSimilarCities(ctx context.Context, queryEmbedding []float32, limit int) ([]types.CityDetail, error) {
+func (r *RepositoryImpl) FindSimilarCities(ctx context.Context, queryEmbedding []float32, model types.EmbeddingModel, limit int) ([]types.CityDetail, error) {
 	ctx, span := otel.Tracer("CityRepository").Start(ctx, "FindSimilarCities", trace.WithAttributes(
 		attribute.Int("embedding.dimension", len(queryEmbedding)),
+		attribute.String("embedding.model", model.String()),
 		attribute.Int("limit", limit),
 	))
 	defer span.End()
@@ -263,6 +304,8 @@ func (r *RepositoryImpl) FindSimilarCities(ctx context.Context, queryEmbedding [
             1 - (embedding <=> $1::vector) AS similarity_score
         FROM cities
         WHERE embedding IS NOT NULL
+          AND embedding_model = $3
+          AND embedding_model_version = $4
         ORDER BY embedding <=> $1::vector
         LIMIT $2
     `
@@ -272,7 +315,7 @@ func (r *RepositoryImpl) FindSimilarCities(ctx context.Context, queryEmbedding [
 		slog.Int("embedding_dim", len(queryEmbedding)),
 		slog.Int("limit", limit))
 
-	rows, err := r.pgpool.Query(ctx, query, embeddingStr, limit)
+	rows, err := r.pgpool.Query(ctx, query, embeddingStr, limit, model.Name, model.Version)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to query similar cities", slog.Any("error", err))
 		span.RecordError(err)
@@ -457,14 +500,20 @@ func (r *RepositoryImpl) GetCitiesWithoutEmbeddings(ctx context.Context, limit i
 	return cities, nil
 }
 
-// GetAllCities retrieves all cities from the database with their coordinates
-func (r *RepositoryImpl) GetAllCities(ctx context.Context) ([]types.CityDetail, error) {
+// GetAllCities retrieves a page of cities from the database with their coordinates
+func (r *RepositoryImpl) GetAllCities(ctx context.Context, page types.PageRequest) ([]types.CityDetail, *types.Cursor, error) {
 	ctx, span := otel.Tracer("CityRepository").Start(ctx, "GetAllCities")
 	defer span.End()
 
 	l := r.logger.With(slog.String("method", "GetAllCities"))
 
-	query := `
+	args := []interface{}{page.FetchLimit()}
+	after := ""
+	if page.Cursor != nil {
+		args = append(args, page.Cursor.Key, page.Cursor.ID)
+		after = "AND (name, id) > ($2, $3)"
+	}
+	query := fmt.Sprintf(`
         SELECT 
             id, 
             name, 
@@ -474,16 +523,17 @@ func (r *RepositoryImpl) GetAllCities(ctx context.Context) ([]types.CityDetail,
             ST_Y(center_location) as center_latitude,
             ST_X(center_location) as center_longitude
         FROM cities
-        WHERE center_location IS NOT NULL
-        ORDER BY name ASC
-    `
+        WHERE center_location IS NOT NULL %s
+        ORDER BY name, id
+        LIMIT $1
+    `, after)
 
-	rows, err := r.pgpool.Query(ctx, query)
+	rows, err := r.pgpool.Query(ctx, query, args...)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to query all cities", slog.Any("error", err))
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "Database query failed")
-		return nil, fmt.Errorf("failed to query all cities: %w", err)
+		return nil, nil, fmt.Errorf("failed to query all cities: %w", err)
 	}
 	defer rows.Close()
 
@@ -504,7 +554,7 @@ func (r *RepositoryImpl) GetAllCities(ctx context.Context) ([]types.CityDetail,
 		if err != nil {
 			l.ErrorContext(ctx, "Failed to scan city row", slog.Any("error", err))
 			span.RecordError(err)
-			return nil, fmt.Errorf("failed to scan city row: %w", err)
+			return nil, nil, fmt.Errorf("failed to scan city row: %w", err)
 		}
 
 		if lat.Valid {
@@ -520,14 +570,18 @@ func (r *RepositoryImpl) GetAllCities(ctx context.Context) ([]types.CityDetail,
 	if err = rows.Err(); err != nil {
 		l.ErrorContext(ctx, "Error iterating city rows", slog.Any("error", err))
 		span.RecordError(err)
-		return nil, fmt.Errorf("error iterating city rows: %w", err)
+		return nil, nil, fmt.Errorf("error iterating city rows: %w", err)
 	}
 
-	l.InfoContext(ctx, "All cities retrieved", slog.Int("count", len(cities)))
+	cities, next := types.NextPage(cities, page.Limit, func(city types.CityDetail) types.Cursor {
+		return types.Cursor{Key: city.Name, ID: city.ID}
+	})
+
+	l.InfoContext(ctx, "Cities retrieved", slog.Int("count", len(cities)))
 	span.SetAttributes(attribute.Int("results.count", len(cities)))
-	span.SetStatus(codes.Ok, "All cities retrieved")
+	span.SetStatus(codes.Ok, "Cities retrieved")
 
-	return cities, nil
+	return cities, next, nil
 }
 
 // determineCityID finds the city ID and name closest to the given latitude and longitude
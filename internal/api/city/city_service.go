@@ -11,8 +11,14 @@ import (
 	"go.opentelemetry.io/otel/codes"
 )
 
+// Page sizes of the city list.
+const (
+	defaultCityPageLimit = 100
+	maxCityPageLimit     = 500
+)
+
 type Service interface {
-	GetAllCities(ctx context.Context) ([]types.CityDetail, error)
+	GetAllCities(ctx context.Context, page types.PageRequest) ([]types.CityDetail, *types.Cursor, error)
 }
 
 type ServiceImpl struct {
@@ -27,26 +33,26 @@ func NewCityService(repo Repository, logger *slog.Logger) *ServiceImpl {
 	}
 }
 
-// GetAllCities retrieves all cities from the database
-func (s *ServiceImpl) GetAllCities(ctx context.Context) ([]types.CityDetail, error) {
+// GetAllCities retrieves a page of cities from the database
+func (s *ServiceImpl) GetAllCities(ctx context.Context, page types.PageRequest) ([]types.CityDetail, *types.Cursor, error) {
 	ctx, span := otel.Tracer("CityService").Start(ctx, "GetAllCities")
 	defer span.End()
 
 	l := s.logger.With(slog.String("method", "GetAllCities"))
 
-	l.InfoContext(ctx, "Retrieving all cities from database")
+	l.InfoContext(ctx, "Retrieving cities from database")
 
-	cities, err := s.repo.GetAllCities(ctx)
+	cities, next, err := s.repo.GetAllCities(ctx, page.Clamp(defaultCityPageLimit, maxCityPageLimit))
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to retrieve cities from repository", slog.Any("error", err))
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "Repository operation failed")
-		return nil, fmt.Errorf("failed to retrieve cities: %w", err)
+		return nil, nil, fmt.Errorf("failed to retrieve cities: %w", err)
 	}
 
 	l.InfoContext(ctx, "Successfully retrieved cities", slog.Int("count", len(cities)))
 	span.SetAttributes(attribute.Int("cities.count", len(cities)))
 	span.SetStatus(codes.Ok, "Cities retrieved successfully")
 
-	return cities, nil
+	return cities, next, nil
 }
\ No newline at end of file
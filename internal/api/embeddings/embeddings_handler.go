@@ -0,0 +1,111 @@
+package embeddings
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/auth"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Handler = (*HandlerImpl)(nil)
+
+type Handler interface {
+	ListModels(w http.ResponseWriter, r *http.Request)
+	StartMigration(w http.ResponseWriter, r *http.Request)
+}
+
+// ModelService is the part of Service the handler uses.
+type ModelService interface {
+	ListModels(ctx context.Context) ([]types.EmbeddingModelState, error)
+	StartMigration(ctx context.Context, req types.StartReembedRequest, requestedBy *uuid.UUID) (*types.Job, error)
+}
+
+type HandlerImpl struct {
+	logger  *slog.Logger
+	service ModelService
+}
+
+func NewHandler(service ModelService, logger *slog.Logger) *HandlerImpl {
+	return &HandlerImpl{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// ListModels godoc
+// @Summary      List Embedding Models
+// @Description  Returns the embedding model each table is searched with and any re-embedding in progress. Admin only.
+// @Tags         Admin
+// @Produce      json
+// @Success      200 {array} types.EmbeddingModelState
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      403 {object} types.Response "Forbidden"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/embeddings/models [get]
+func (h *HandlerImpl) ListModels(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("EmbeddingsHandler").Start(r.Context(), "ListModels")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "ListModels"))
+
+	states, err := h.service.ListModels(ctx)
+	if err != nil {
+		l.ErrorContext(ctx, "Service failed to list embedding models", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to list embedding models")
+		api.WriteServiceError(w, r, err, "Failed to list embedding models")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Embedding models listed")
+	api.WriteJSONResponse(w, r, http.StatusOK, states)
+}
+
+// StartMigration godoc
+// @Summary      Re-embed a Table with Another Model
+// @Description  Queues a job that re-embeds every row of the table with the given model into shadow columns, then switches searches to it in one transaction. Until then searches keep using the current model. Poll /admin/jobs/{jobID} for progress. Admin only.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        migration body types.StartReembedRequest true "Table (points_of_interest, cities or user_preference_profiles) and target model"
+// @Success      202 {object} types.Job
+// @Failure      400 {object} types.Response "Unknown table, invalid model or model already active"
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      403 {object} types.Response "Forbidden"
+// @Failure      409 {object} types.Response "Another migration is in progress"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/embeddings/migrations [post]
+func (h *HandlerImpl) StartMigration(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("EmbeddingsHandler").Start(r.Context(), "StartMigration")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "StartMigration"))
+
+	var req types.StartReembedRequest
+	if err := api.DecodeJSONBody(w, r, &req); err != nil {
+		l.ErrorContext(ctx, "Failed to decode request", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Bad request")
+		return
+	}
+	span.SetAttributes(attribute.String("embedding.table", req.Table))
+
+	job, err := h.service.StartMigration(ctx, req, api.Requester(auth.GetUserIDFromContext(ctx)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to start migration")
+		api.WriteServiceError(w, r, err, "Failed to start re-embedding")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Migration started")
+	api.WriteJSONResponse(w, r, http.StatusAccepted, job)
+}
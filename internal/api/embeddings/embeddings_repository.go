@@ -0,0 +1,711 @@
+package embeddings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Repository = (*RepositoryImpl)(nil)
+
+// POISource is the POI text an embedding is generated from.
+type POISource struct {
+	ID          uuid.UUID
+	Name        string
+	Description string
+	Category    string
+}
+
+// CitySource is the city text an embedding is generated from.
+type CitySource struct {
+	ID          uuid.UUID
+	Name        string
+	Country     string
+	Description string
+}
+
+// UserPreferenceSource is what a search profile's taste vector is generated from:
+// its interests, the user's custom interests and avoid tags, what the user was
+// learned to favour and avoid, and its settings.
+type UserPreferenceSource struct {
+	ProfileID   uuid.UUID
+	Interests   []string
+	Preferences map[string]string
+}
+
+// Repository reads entities that need embeddings and stores the results. The
+// Pending* methods page by ID through entities whose embedding_generated_at is NULL.
+type Repository interface {
+	// CountPending returns how many entities of a job kind still need an embedding.
+	CountPending(ctx context.Context, kind string) (int, error)
+
+	PendingPOIs(ctx context.Context, after uuid.UUID, limit int) ([]POISource, error)
+	GetPOI(ctx context.Context, poiID uuid.UUID) (*POISource, error)
+
+	PendingCities(ctx context.Context, after uuid.UUID, limit int) ([]CitySource, error)
+	GetCity(ctx context.Context, cityID uuid.UUID) (*CitySource, error)
+
+	PendingUserPreferences(ctx context.Context, after uuid.UUID, limit int) ([]UserPreferenceSource, error)
+	GetUserPreferences(ctx context.Context, profileID uuid.UUID) (*UserPreferenceSource, error)
+
+	// SaveEmbedding stores an embedding and the model that produced it in the
+	// active columns.
+	SaveEmbedding(ctx context.Context, table string, id uuid.UUID, model types.EmbeddingModel, embedding []float32) error
+
+	GetModelState(ctx context.Context, table string) (*types.EmbeddingModelState, error)
+	ListModelStates(ctx context.Context) ([]types.EmbeddingModelState, error)
+	// StartMigration records target as the model table is migrating to. Starting
+	// the migration already in progress is a no-op.
+	StartMigration(ctx context.Context, table string, target types.EmbeddingModel) (*types.EmbeddingModelState, error)
+
+	// CountReembedPending and PendingReembed cover embedded rows whose shadow
+	// column does not hold a target embedding yet.
+	CountReembedPending(ctx context.Context, table string, target types.EmbeddingModel) (int, error)
+	PendingReembed(ctx context.Context, table string, target types.EmbeddingModel, after uuid.UUID, limit int) ([]uuid.UUID, error)
+	// SaveNextEmbedding stores a target embedding in the shadow columns, leaving
+	// the active ones untouched.
+	SaveNextEmbedding(ctx context.Context, table string, id uuid.UUID, model types.EmbeddingModel, embedding []float32) error
+	// Cutover makes target the active model of table in one transaction and
+	// returns how many rows were left without a target embedding; those are
+	// queued for the regular embedding sweep.
+	Cutover(ctx context.Context, table string, target types.EmbeddingModel) (int, error)
+}
+
+type RepositoryImpl struct {
+	logger *slog.Logger
+	pgpool *pgxpool.Pool
+}
+
+func NewRepository(pgxpool *pgxpool.Pool, logger *slog.Logger) *RepositoryImpl {
+	return &RepositoryImpl{
+		logger: logger,
+		pgpool: pgxpool,
+	}
+}
+
+// vectorLiteral formats an embedding as a pgvector text literal.
+func vectorLiteral(embedding []float32) string {
+	strs := make([]string, len(embedding))
+	for i, v := range embedding {
+		strs[i] = strconv.FormatFloat(float64(v), 'f', -1, 32)
+	}
+	return "[" + strings.Join(strs, ",") + "]"
+}
+
+// CountPending implements Repository.
+func (r *RepositoryImpl) CountPending(ctx context.Context, kind string) (int, error) {
+	var query string
+	switch kind {
+	case types.JobKindPOIEmbedding:
+		query = `SELECT COUNT(*) FROM points_of_interest WHERE embedding_generated_at IS NULL`
+	case types.JobKindCityEmbedding:
+		query = `SELECT COUNT(*) FROM cities WHERE embedding_generated_at IS NULL`
+	case types.JobKindUserPreferenceEmbedding:
+		query = `SELECT COUNT(*) FROM user_preference_profiles WHERE embedding_generated_at IS NULL`
+	default:
+		return 0, fmt.Errorf("unknown embedding kind %q: %w", kind, types.ErrBadRequest)
+	}
+
+	var count int
+	if err := r.pgpool.QueryRow(ctx, query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("database error counting pending embeddings: %w", err)
+	}
+	return count, nil
+}
+
+const poiSourceColumns = `id, name, COALESCE(description, ''), COALESCE(category, poi_type, '')`
+
+// PendingPOIs implements Repository.
+func (r *RepositoryImpl) PendingPOIs(ctx context.Context, after uuid.UUID, limit int) ([]POISource, error) {
+	ctx, span := otel.Tracer("EmbeddingsRepo").Start(ctx, "PendingPOIs", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "points_of_interest"),
+		attribute.Int("limit", limit),
+	))
+	defer span.End()
+
+	query := `
+		SELECT ` + poiSourceColumns + `
+		FROM points_of_interest
+		WHERE embedding_generated_at IS NULL AND id > $1
+		ORDER BY id
+		LIMIT $2`
+	rows, err := r.pgpool.Query(ctx, query, after, limit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, fmt.Errorf("database error fetching POIs without embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var pois []POISource
+	for rows.Next() {
+		var p POISource
+		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.Category); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan POI row: %w", err)
+		}
+		pois = append(pois, p)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("error iterating POI rows: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("results.count", len(pois)))
+	span.SetStatus(codes.Ok, "Pending POIs retrieved")
+	return pois, nil
+}
+
+// GetPOI implements Repository.
+func (r *RepositoryImpl) GetPOI(ctx context.Context, poiID uuid.UUID) (*POISource, error) {
+	query := `SELECT ` + poiSourceColumns + ` FROM points_of_interest WHERE id = $1`
+	var p POISource
+	err := r.pgpool.QueryRow(ctx, query, poiID).Scan(&p.ID, &p.Name, &p.Description, &p.Category)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("POI %s not found: %w", poiID, types.ErrNotFound)
+		}
+		return nil, fmt.Errorf("database error fetching POI: %w", err)
+	}
+	return &p, nil
+}
+
+const citySourceColumns = `id, name, country, COALESCE(ai_summary, '')`
+
+// PendingCities implements Repository.
+func (r *RepositoryImpl) PendingCities(ctx context.Context, after uuid.UUID, limit int) ([]CitySource, error) {
+	ctx, span := otel.Tracer("EmbeddingsRepo").Start(ctx, "PendingCities", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "cities"),
+		attribute.Int("limit", limit),
+	))
+	defer span.End()
+
+	query := `
+		SELECT ` + citySourceColumns + `
+		FROM cities
+		WHERE embedding_generated_at IS NULL AND id > $1
+		ORDER BY id
+		LIMIT $2`
+	rows, err := r.pgpool.Query(ctx, query, after, limit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, fmt.Errorf("database error fetching cities without embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var cities []CitySource
+	for rows.Next() {
+		var c CitySource
+		if err := rows.Scan(&c.ID, &c.Name, &c.Country, &c.Description); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan city row: %w", err)
+		}
+		cities = append(cities, c)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("error iterating city rows: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("results.count", len(cities)))
+	span.SetStatus(codes.Ok, "Pending cities retrieved")
+	return cities, nil
+}
+
+// GetCity implements Repository.
+func (r *RepositoryImpl) GetCity(ctx context.Context, cityID uuid.UUID) (*CitySource, error) {
+	query := `SELECT ` + citySourceColumns + ` FROM cities WHERE id = $1`
+	var c CitySource
+	err := r.pgpool.QueryRow(ctx, query, cityID).Scan(&c.ID, &c.Name, &c.Country, &c.Description)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("city %s not found: %w", cityID, types.ErrNotFound)
+		}
+		return nil, fmt.Errorf("database error fetching city: %w", err)
+	}
+	return &c, nil
+}
+
+// userPreferenceQuery collects the taste inputs of search profiles matching the
+// %s condition. Besides what the user configured, it takes the strongest
+// category and tag affinities learned from their favourites and removals.
+const userPreferenceQuery = `
+	SELECT p.id,
+	       ARRAY(
+	           SELECT i.name FROM user_profile_interests upi
+	           JOIN interests i ON i.id = upi.interest_id
+	           WHERE upi.profile_id = p.id
+	           UNION
+	           SELECT i.name FROM user_interests ui
+	           JOIN interests i ON i.id = ui.interest_id
+	           WHERE ui.user_id = p.user_id
+	           UNION
+	           SELECT ci.name FROM user_custom_interests ci
+	           WHERE ci.user_id = p.user_id AND ci.active
+	           ORDER BY 1),
+	       COALESCE((
+	           SELECT STRING_AGG(t.name, ', ' ORDER BY t.name) FROM user_personal_tags t
+	           WHERE t.user_id = p.user_id AND t.active
+	             AND (t.profile_id IS NULL OR t.profile_id = p.id)), ''),
+	       COALESCE((
+	           SELECT STRING_AGG(a.value, ', ' ORDER BY a.score DESC) FROM (
+	               SELECT value, score FROM user_affinities
+	               WHERE user_id = p.user_id AND score >= 0.5
+	               ORDER BY score DESC LIMIT 5) a), ''),
+	       COALESCE((
+	           SELECT STRING_AGG(a.value, ', ' ORDER BY a.score) FROM (
+	               SELECT value, score FROM user_affinities
+	               WHERE user_id = p.user_id AND score <= -0.5
+	               ORDER BY score LIMIT 5) a), ''),
+	       COALESCE(p.preferred_pace::text, ''),
+	       COALESCE(p.preferred_time::text, ''),
+	       COALESCE(p.budget_level, 0),
+	       COALESCE(p.preferred_transport::text, ''),
+	       COALESCE(ARRAY_TO_STRING(p.preferred_vibes, ', '), ''),
+	       COALESCE(ARRAY_TO_STRING(p.dietary_needs, ', '), '')
+	FROM user_preference_profiles p
+	WHERE %s
+	ORDER BY p.id`
+
+func scanUserPreferences(row pgx.Row) (*UserPreferenceSource, error) {
+	var (
+		u                             UserPreferenceSource
+		avoid, favoured, learnedAvoid string
+		pace, timeOfDay, transport    string
+		vibes, dietary                string
+		budget                        int
+	)
+	if err := row.Scan(&u.ProfileID, &u.Interests, &avoid, &favoured, &learnedAvoid, &pace, &timeOfDay, &budget, &transport, &vibes, &dietary); err != nil {
+		return nil, err
+	}
+	if learnedAvoid != "" {
+		if avoid != "" {
+			avoid += ", "
+		}
+		avoid += learnedAvoid
+	}
+
+	u.Preferences = make(map[string]string)
+	for key, value := range map[string]string{
+		"avoid":     avoid,
+		"favours":   favoured,
+		"pace":      pace,
+		"time":      timeOfDay,
+		"transport": transport,
+		"vibes":     vibes,
+		"dietary":   dietary,
+	} {
+		if value != "" && value != "any" {
+			u.Preferences[key] = value
+		}
+	}
+	if budget > 0 {
+		u.Preferences["budget"] = strconv.Itoa(budget)
+	}
+	return &u, nil
+}
+
+// PendingUserPreferences implements Repository.
+func (r *RepositoryImpl) PendingUserPreferences(ctx context.Context, after uuid.UUID, limit int) ([]UserPreferenceSource, error) {
+	ctx, span := otel.Tracer("EmbeddingsRepo").Start(ctx, "PendingUserPreferences", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "user_preference_profiles"),
+		attribute.Int("limit", limit),
+	))
+	defer span.End()
+
+	query := fmt.Sprintf(userPreferenceQuery, `p.embedding_generated_at IS NULL AND p.id > $1`) + `
+	LIMIT $2`
+	rows, err := r.pgpool.Query(ctx, query, after, limit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, fmt.Errorf("database error fetching profiles without preference embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var profiles []UserPreferenceSource
+	for rows.Next() {
+		u, err := scanUserPreferences(rows)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan user preference row: %w", err)
+		}
+		profiles = append(profiles, *u)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("error iterating user preference rows: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("results.count", len(profiles)))
+	span.SetStatus(codes.Ok, "Pending user preferences retrieved")
+	return profiles, nil
+}
+
+// GetUserPreferences implements Repository.
+func (r *RepositoryImpl) GetUserPreferences(ctx context.Context, profileID uuid.UUID) (*UserPreferenceSource, error) {
+	query := fmt.Sprintf(userPreferenceQuery, `p.id = $1`)
+	u, err := scanUserPreferences(r.pgpool.QueryRow(ctx, query, profileID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("search profile %s not found: %w", profileID, types.ErrNotFound)
+		}
+		return nil, fmt.Errorf("database error fetching user preferences: %w", err)
+	}
+	return u, nil
+}
+
+// Execer runs a statement on a pool or inside a transaction.
+type Execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// refreshPreferencesQuery marks the matching search profiles stale and queues a
+// preference embedding job for each. The sweep picks up any job that is lost.
+const refreshPreferencesQuery = `
+	WITH stale AS (
+		UPDATE user_preference_profiles
+		SET embedding_generated_at = NULL
+		WHERE %s
+		RETURNING id
+	)
+	INSERT INTO background_jobs (kind, target_id)
+	SELECT $1, id FROM stale
+	ON CONFLICT (kind, target_id) WHERE status IN ('pending', 'running') DO NOTHING`
+
+// RefreshProfilePreferences queues a taste vector refresh for one search profile.
+// Call it inside the transaction that changes the profile.
+func RefreshProfilePreferences(ctx context.Context, db Execer, profileID uuid.UUID) error {
+	query := fmt.Sprintf(refreshPreferencesQuery, `id = $2`)
+	if _, err := db.Exec(ctx, query, types.JobKindUserPreferenceEmbedding, profileID); err != nil {
+		return fmt.Errorf("failed to queue preference embedding for profile %s: %w", profileID, err)
+	}
+	return nil
+}
+
+// RefreshUserPreferences queues a taste vector refresh for every search profile
+// of a user, for changes that apply to all of them such as custom interests and
+// tags not bound to a profile.
+func RefreshUserPreferences(ctx context.Context, db Execer, userID uuid.UUID) error {
+	query := fmt.Sprintf(refreshPreferencesQuery, `user_id = $2`)
+	if _, err := db.Exec(ctx, query, types.JobKindUserPreferenceEmbedding, userID); err != nil {
+		return fmt.Errorf("failed to queue preference embeddings for user %s: %w", userID, err)
+	}
+	return nil
+}
+
+// embeddingTable describes where a table keeps its embeddings.
+type embeddingTable struct {
+	key    string
+	vector string
+	next   string
+}
+
+var embeddingTables = map[string]embeddingTable{
+	types.EmbeddingTablePOIs:            {key: "id", vector: "embedding", next: "embedding_next"},
+	types.EmbeddingTableCities:          {key: "id", vector: "embedding", next: "embedding_next"},
+	types.EmbeddingTableUserPreferences: {key: "id", vector: "preference_embedding", next: "preference_embedding_next"},
+}
+
+func lookupTable(table string) (embeddingTable, error) {
+	t, ok := embeddingTables[table]
+	if !ok {
+		return embeddingTable{}, fmt.Errorf("table %q has no embeddings: %w", table, types.ErrBadRequest)
+	}
+	return t, nil
+}
+
+// SaveEmbedding implements Repository.
+func (r *RepositoryImpl) SaveEmbedding(ctx context.Context, table string, id uuid.UUID, model types.EmbeddingModel, embedding []float32) error {
+	t, err := lookupTable(table)
+	if err != nil {
+		return err
+	}
+
+	// A fresh embedding supersedes any shadow one computed from older content;
+	// clearing it makes a running re-embedding pick the row up again. The share
+	// lock waits out a cutover, after which a vector from the old model is refused.
+	query := fmt.Sprintf(`
+		UPDATE %[1]s
+		SET %[3]s = $1::vector, embedding_model = $2, embedding_model_version = $3,
+		    embedding_generated_at = NOW(),
+		    %[4]s = NULL, embedding_next_model = NULL, embedding_next_model_version = NULL
+		WHERE %[2]s = $4
+		  AND EXISTS (
+		      SELECT 1 FROM embedding_models
+		      WHERE target_table = $5 AND model = $2 AND model_version = $3
+		      FOR SHARE)`, table, t.key, t.vector, t.next)
+	tag, err := r.pgpool.Exec(ctx, query, vectorLiteral(embedding), model.Name, model.Version, id, table)
+	if err != nil {
+		return fmt.Errorf("database error saving %s embedding: %w", table, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s row %s not found or %s is no longer active: %w", table, id, model, types.ErrNotFound)
+	}
+	return nil
+}
+
+const modelStateColumns = `target_table, model, model_version, next_model, next_model_version,
+	migration_started_at, cutover_at, updated_at`
+
+func scanModelState(row pgx.Row) (*types.EmbeddingModelState, error) {
+	var (
+		st          types.EmbeddingModelState
+		nextName    *string
+		nextVersion *int
+	)
+	err := row.Scan(&st.Table, &st.Active.Name, &st.Active.Version, &nextName, &nextVersion,
+		&st.MigrationStartedAt, &st.CutoverAt, &st.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if nextName != nil && nextVersion != nil {
+		st.Next = &types.EmbeddingModel{Name: *nextName, Version: *nextVersion}
+	}
+	return &st, nil
+}
+
+// GetModelState implements Repository.
+func (r *RepositoryImpl) GetModelState(ctx context.Context, table string) (*types.EmbeddingModelState, error) {
+	query := `SELECT ` + modelStateColumns + ` FROM embedding_models WHERE target_table = $1`
+	st, err := scanModelState(r.pgpool.QueryRow(ctx, query, table))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("no embedding model recorded for %s: %w", table, types.ErrNotFound)
+		}
+		return nil, fmt.Errorf("database error fetching embedding model: %w", err)
+	}
+	return st, nil
+}
+
+// ListModelStates implements Repository.
+func (r *RepositoryImpl) ListModelStates(ctx context.Context) ([]types.EmbeddingModelState, error) {
+	query := `SELECT ` + modelStateColumns + ` FROM embedding_models ORDER BY target_table`
+	rows, err := r.pgpool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("database error listing embedding models: %w", err)
+	}
+	defer rows.Close()
+
+	states := []types.EmbeddingModelState{}
+	for rows.Next() {
+		st, err := scanModelState(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan embedding model row: %w", err)
+		}
+		states = append(states, *st)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating embedding model rows: %w", err)
+	}
+	return states, nil
+}
+
+// StartMigration implements Repository.
+func (r *RepositoryImpl) StartMigration(ctx context.Context, table string, target types.EmbeddingModel) (*types.EmbeddingModelState, error) {
+	ctx, span := otel.Tracer("EmbeddingsRepo").Start(ctx, "StartMigration", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "embedding_models"),
+		attribute.String("embedding.table", table),
+		attribute.String("embedding.target", target.String()),
+	))
+	defer span.End()
+
+	query := `
+		UPDATE embedding_models
+		SET next_model = $2, next_model_version = $3,
+		    migration_started_at = CASE WHEN next_model IS NULL THEN NOW() ELSE migration_started_at END
+		WHERE target_table = $1
+		  AND (model, model_version) IS DISTINCT FROM ($2, $3)
+		  AND (next_model IS NULL OR (next_model = $2 AND next_model_version = $3))
+		RETURNING ` + modelStateColumns
+	st, err := scanModelState(r.pgpool.QueryRow(ctx, query, table, target.Name, target.Version))
+	if err == nil {
+		span.SetStatus(codes.Ok, "Migration started")
+		return st, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB update failed")
+		return nil, fmt.Errorf("database error starting embedding migration: %w", err)
+	}
+
+	// Nothing updated; work out why
+	current, err := r.GetModelState(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	span.SetStatus(codes.Error, "Migration rejected")
+	if current.Active == target {
+		return nil, fmt.Errorf("%s already uses %s: %w", table, target, types.ErrBadRequest)
+	}
+	return nil, fmt.Errorf("%s is already migrating to %s: %w", table, current.Next, types.ErrConflict)
+}
+
+// reembedPendingWhere selects embedded rows without a shadow embedding from the
+// target model ($1, $2).
+const reembedPendingWhere = `embedding_generated_at IS NOT NULL
+	AND (embedding_next_model, embedding_next_model_version) IS DISTINCT FROM ($1, $2)`
+
+// CountReembedPending implements Repository.
+func (r *RepositoryImpl) CountReembedPending(ctx context.Context, table string, target types.EmbeddingModel) (int, error) {
+	t, err := lookupTable(table)
+	if err != nil {
+		return 0, err
+	}
+
+	query := fmt.Sprintf(`SELECT COUNT(DISTINCT %s) FROM %s WHERE %s`, t.key, table, reembedPendingWhere)
+	var count int
+	if err := r.pgpool.QueryRow(ctx, query, target.Name, target.Version).Scan(&count); err != nil {
+		return 0, fmt.Errorf("database error counting rows to re-embed: %w", err)
+	}
+	return count, nil
+}
+
+// PendingReembed implements Repository.
+func (r *RepositoryImpl) PendingReembed(ctx context.Context, table string, target types.EmbeddingModel, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	t, err := lookupTable(table)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT DISTINCT %[1]s FROM %[2]s
+		WHERE %[3]s AND %[1]s > $3
+		ORDER BY %[1]s
+		LIMIT $4`, t.key, table, reembedPendingWhere)
+	rows, err := r.pgpool.Query(ctx, query, target.Name, target.Version, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching rows to re-embed: %w", err)
+	}
+	defer rows.Close()
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan rows to re-embed: %w", err)
+	}
+	return ids, nil
+}
+
+// SaveNextEmbedding implements Repository.
+func (r *RepositoryImpl) SaveNextEmbedding(ctx context.Context, table string, id uuid.UUID, model types.EmbeddingModel, embedding []float32) error {
+	t, err := lookupTable(table)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE %[1]s
+		SET %[3]s = $1::vector, embedding_next_model = $2, embedding_next_model_version = $3
+		WHERE %[2]s = $4`, table, t.key, t.next)
+	tag, err := r.pgpool.Exec(ctx, query, vectorLiteral(embedding), model.Name, model.Version, id)
+	if err != nil {
+		return fmt.Errorf("database error saving %s shadow embedding: %w", table, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s row %s not found: %w", table, id, types.ErrNotFound)
+	}
+	return nil
+}
+
+// Cutover implements Repository.
+func (r *RepositoryImpl) Cutover(ctx context.Context, table string, target types.EmbeddingModel) (int, error) {
+	ctx, span := otel.Tracer("EmbeddingsRepo").Start(ctx, "Cutover", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", table),
+		attribute.String("embedding.target", target.String()),
+	))
+	defer span.End()
+
+	t, err := lookupTable(table)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := r.pgpool.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the state row so saves with the old model wait for the swap and then fail
+	var nextName *string
+	var nextVersion *int
+	err = tx.QueryRow(ctx, `
+		SELECT next_model, next_model_version FROM embedding_models
+		WHERE target_table = $1 FOR UPDATE`, table).Scan(&nextName, &nextVersion)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("no embedding model recorded for %s: %w", table, types.ErrNotFound)
+		}
+		span.RecordError(err)
+		return 0, fmt.Errorf("database error locking embedding model: %w", err)
+	}
+	if nextName == nil || nextVersion == nil || *nextName != target.Name || *nextVersion != target.Version {
+		return 0, fmt.Errorf("%s is not migrating to %s: %w", table, target, types.ErrConflict)
+	}
+
+	swap := fmt.Sprintf(`
+		UPDATE %[1]s
+		SET %[2]s = %[3]s, embedding_model = embedding_next_model, embedding_model_version = embedding_next_model_version,
+		    %[3]s = NULL, embedding_next_model = NULL, embedding_next_model_version = NULL
+		WHERE embedding_next_model = $1 AND embedding_next_model_version = $2`, table, t.vector, t.next)
+	if _, err := tx.Exec(ctx, swap, target.Name, target.Version); err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("database error swapping %s embeddings: %w", table, err)
+	}
+
+	// Rows still on another model drop out of searches and go back to the sweep
+	requeue := fmt.Sprintf(`
+		UPDATE %[1]s
+		SET embedding_generated_at = NULL,
+		    %[2]s = NULL, embedding_next_model = NULL, embedding_next_model_version = NULL
+		WHERE embedding_generated_at IS NOT NULL
+		  AND (embedding_model, embedding_model_version) IS DISTINCT FROM ($1, $2)`, table, t.next)
+	tag, err := tx.Exec(ctx, requeue, target.Name, target.Version)
+	if err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("database error requeueing %s embeddings: %w", table, err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE embedding_models
+		SET model = next_model, model_version = next_model_version,
+		    next_model = NULL, next_model_version = NULL, cutover_at = NOW()
+		WHERE target_table = $1`, table)
+	if err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("database error updating embedding model: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Commit failed")
+		return 0, fmt.Errorf("failed to commit cutover: %w", err)
+	}
+
+	requeued := int(tag.RowsAffected())
+	span.SetAttributes(attribute.Int("embeddings.requeued", requeued))
+	span.SetStatus(codes.Ok, "Cutover completed")
+	return requeued, nil
+}
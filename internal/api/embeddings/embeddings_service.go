@@ -0,0 +1,407 @@
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/jobs"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+// sweepPageSize is how many entities a sweep job loads per query.
+const sweepPageSize = 50
+
+// Embedder generates embedding vectors. Implemented by generativeAI.EmbeddingService.
+type Embedder interface {
+	GeneratePOIEmbedding(ctx context.Context, name, description, category string) ([]float32, error)
+	GenerateCityEmbedding(ctx context.Context, name, country, description string) ([]float32, error)
+	GenerateUserPreferenceEmbedding(ctx context.Context, interests []string, preferences map[string]string) ([]float32, error)
+}
+
+// JobRegistry is the part of the job service the embedding jobs hook into.
+type JobRegistry interface {
+	Register(kind string, fn jobs.JobFunc)
+	Schedule(kind string, every time.Duration)
+}
+
+// JobQueue queues the re-embedding job when a migration starts.
+type JobQueue interface {
+	Enqueue(ctx context.Context, req types.EnqueueJobRequest, requestedBy *uuid.UUID) (*types.Job, error)
+}
+
+// reembedKinds maps each embedding table to the job that migrates it.
+var reembedKinds = map[string]string{
+	types.EmbeddingTablePOIs:            types.JobKindPOIReembed,
+	types.EmbeddingTableCities:          types.JobKindCityReembed,
+	types.EmbeddingTableUserPreferences: types.JobKindUserPreferenceReembed,
+}
+
+// Service runs the POI, city and user preference embedding jobs, and migrates
+// tables between embedding models.
+type Service struct {
+	logger      *slog.Logger
+	repo        Repository
+	newEmbedder func(types.EmbeddingModel) Embedder
+	queue       JobQueue
+}
+
+// NewService creates the embeddings service. newEmbedder returns an Embedder
+// that generates vectors with the given model.
+func NewService(repo Repository, newEmbedder func(types.EmbeddingModel) Embedder, queue JobQueue, logger *slog.Logger) *Service {
+	return &Service{
+		logger:      logger,
+		repo:        repo,
+		newEmbedder: newEmbedder,
+		queue:       queue,
+	}
+}
+
+// Register installs the embedding and re-embedding jobs and, when sweepInterval
+// is positive, a periodic sweep of each embedding kind to catch anything that was
+// never queued.
+func (s *Service) Register(registry JobRegistry, sweepInterval time.Duration) {
+	kinds := map[string]jobs.JobFunc{
+		types.JobKindPOIEmbedding:            s.RunPOIEmbedding,
+		types.JobKindCityEmbedding:           s.RunCityEmbedding,
+		types.JobKindUserPreferenceEmbedding: s.RunUserPreferenceEmbedding,
+	}
+	for kind, fn := range kinds {
+		registry.Register(kind, fn)
+		if sweepInterval > 0 {
+			registry.Schedule(kind, sweepInterval)
+		}
+	}
+
+	registry.Register(types.JobKindPOIReembed, s.RunPOIReembed)
+	registry.Register(types.JobKindCityReembed, s.RunCityReembed)
+	registry.Register(types.JobKindUserPreferenceReembed, s.RunUserPreferenceReembed)
+}
+
+// activeEmbedder returns the active model of table and an Embedder for it.
+func (s *Service) activeEmbedder(ctx context.Context, table string) (types.EmbeddingModel, Embedder, error) {
+	state, err := s.repo.GetModelState(ctx, table)
+	if err != nil {
+		return types.EmbeddingModel{}, nil, err
+	}
+	return state.Active, s.newEmbedder(state.Active), nil
+}
+
+// RunPOIEmbedding embeds the job's target POI, or every POI without an embedding.
+func (s *Service) RunPOIEmbedding(ctx context.Context, job *types.Job, report jobs.ProgressFunc) error {
+	model, embedder, err := s.activeEmbedder(ctx, types.EmbeddingTablePOIs)
+	if err != nil {
+		return err
+	}
+	embed := func(ctx context.Context, p POISource) error {
+		embedding, err := embedder.GeneratePOIEmbedding(ctx, p.Name, p.Description, p.Category)
+		if err != nil {
+			return err
+		}
+		return s.repo.SaveEmbedding(ctx, types.EmbeddingTablePOIs, p.ID, model, embedding)
+	}
+	return run(ctx, s, job, report, s.repo.GetPOI, s.repo.PendingPOIs,
+		func(p POISource) uuid.UUID { return p.ID }, embed)
+}
+
+// RunCityEmbedding embeds the job's target city, or every city without an embedding.
+func (s *Service) RunCityEmbedding(ctx context.Context, job *types.Job, report jobs.ProgressFunc) error {
+	model, embedder, err := s.activeEmbedder(ctx, types.EmbeddingTableCities)
+	if err != nil {
+		return err
+	}
+	embed := func(ctx context.Context, c CitySource) error {
+		embedding, err := embedder.GenerateCityEmbedding(ctx, c.Name, c.Country, c.Description)
+		if err != nil {
+			return err
+		}
+		return s.repo.SaveEmbedding(ctx, types.EmbeddingTableCities, c.ID, model, embedding)
+	}
+	return run(ctx, s, job, report, s.repo.GetCity, s.repo.PendingCities,
+		func(c CitySource) uuid.UUID { return c.ID }, embed)
+}
+
+// RunUserPreferenceEmbedding embeds the taste vector of the job's target search
+// profile, or of every profile without one.
+func (s *Service) RunUserPreferenceEmbedding(ctx context.Context, job *types.Job, report jobs.ProgressFunc) error {
+	model, embedder, err := s.activeEmbedder(ctx, types.EmbeddingTableUserPreferences)
+	if err != nil {
+		return err
+	}
+	embed := func(ctx context.Context, u UserPreferenceSource) error {
+		embedding, err := embedder.GenerateUserPreferenceEmbedding(ctx, u.Interests, u.Preferences)
+		if err != nil {
+			return err
+		}
+		return s.repo.SaveEmbedding(ctx, types.EmbeddingTableUserPreferences, u.ProfileID, model, embedding)
+	}
+	return run(ctx, s, job, report, s.repo.GetUserPreferences, s.repo.PendingUserPreferences,
+		func(u UserPreferenceSource) uuid.UUID { return u.ProfileID }, embed)
+}
+
+// RunPOIReembed migrates POI embeddings to the next model, then cuts over.
+func (s *Service) RunPOIReembed(ctx context.Context, job *types.Job, report jobs.ProgressFunc) error {
+	return s.reembed(ctx, job, report, types.EmbeddingTablePOIs, func(ctx context.Context, e Embedder, id uuid.UUID) ([]float32, error) {
+		p, err := s.repo.GetPOI(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return e.GeneratePOIEmbedding(ctx, p.Name, p.Description, p.Category)
+	})
+}
+
+// RunCityReembed migrates city embeddings to the next model, then cuts over.
+func (s *Service) RunCityReembed(ctx context.Context, job *types.Job, report jobs.ProgressFunc) error {
+	return s.reembed(ctx, job, report, types.EmbeddingTableCities, func(ctx context.Context, e Embedder, id uuid.UUID) ([]float32, error) {
+		c, err := s.repo.GetCity(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return e.GenerateCityEmbedding(ctx, c.Name, c.Country, c.Description)
+	})
+}
+
+// RunUserPreferenceReembed migrates user preference embeddings to the next model,
+// then cuts over.
+func (s *Service) RunUserPreferenceReembed(ctx context.Context, job *types.Job, report jobs.ProgressFunc) error {
+	return s.reembed(ctx, job, report, types.EmbeddingTableUserPreferences, func(ctx context.Context, e Embedder, id uuid.UUID) ([]float32, error) {
+		u, err := s.repo.GetUserPreferences(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return e.GenerateUserPreferenceEmbedding(ctx, u.Interests, u.Preferences)
+	})
+}
+
+// reembed fills the shadow columns of table with embeddings from the model it is
+// migrating to, then cuts over. Searches keep using the active model until the
+// cutover, and a retry only redoes the rows that are still missing.
+func (s *Service) reembed(
+	ctx context.Context,
+	job *types.Job,
+	report jobs.ProgressFunc,
+	table string,
+	embed func(context.Context, Embedder, uuid.UUID) ([]float32, error),
+) error {
+	ctx, span := otel.Tracer("EmbeddingsService").Start(ctx, "Reembed", trace.WithAttributes(
+		attribute.String("job.kind", job.Kind),
+		attribute.String("embedding.table", table),
+	))
+	defer span.End()
+
+	state, err := s.repo.GetModelState(ctx, table)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "State lookup failed")
+		return err
+	}
+	if state.Next == nil {
+		span.SetStatus(codes.Error, "No migration in progress")
+		return fmt.Errorf("no re-embedding in progress for %s: %w", table, types.ErrBadRequest)
+	}
+	target := *state.Next
+	embedder := s.newEmbedder(target)
+	span.SetAttributes(attribute.String("embedding.target", target.String()))
+
+	total, err := s.repo.CountReembedPending(ctx, table, target)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Count failed")
+		return err
+	}
+	progress := types.JobProgress{Total: total}
+	report(progress)
+
+	after := uuid.Nil
+	for {
+		ids, err := s.repo.PendingReembed(ctx, table, target, after, sweepPageSize)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Page fetch failed")
+			return err
+		}
+		if len(ids) == 0 {
+			break
+		}
+		for _, id := range ids {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			embedding, err := embed(ctx, embedder, id)
+			if err == nil {
+				err = s.repo.SaveNextEmbedding(ctx, table, id, target, embedding)
+			}
+			if err != nil {
+				progress.Failed++
+				s.logger.WarnContext(ctx, "Failed to re-embed",
+					slog.String("table", table),
+					slog.String("id", id.String()),
+					slog.String("model", target.String()),
+					slog.Any("error", err))
+			} else {
+				progress.Done++
+			}
+			report(progress)
+		}
+		after = ids[len(ids)-1]
+	}
+
+	span.SetAttributes(attribute.Int("embeddings.done", progress.Done), attribute.Int("embeddings.failed", progress.Failed))
+	if progress.Failed > 0 {
+		span.SetStatus(codes.Error, "Some embeddings failed")
+		return fmt.Errorf("%d of %d re-embeddings failed, cutover postponed", progress.Failed, progress.Done+progress.Failed)
+	}
+
+	requeued, err := s.repo.Cutover(ctx, table, target)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Cutover failed")
+		return err
+	}
+	s.logger.InfoContext(ctx, "Embedding model cut over",
+		slog.String("table", table),
+		slog.String("from", state.Active.String()),
+		slog.String("to", target.String()),
+		slog.Int("requeued", requeued))
+	span.SetStatus(codes.Ok, "Cutover completed")
+	return nil
+}
+
+// ListModels returns the active model of every embedding table and any
+// migration in progress.
+func (s *Service) ListModels(ctx context.Context) ([]types.EmbeddingModelState, error) {
+	return s.repo.ListModelStates(ctx)
+}
+
+// StartMigration records the model a table should move to and queues the job
+// that re-embeds it. Repeating the request for a migration in progress queues
+// the job again if it is no longer running.
+func (s *Service) StartMigration(ctx context.Context, req types.StartReembedRequest, requestedBy *uuid.UUID) (*types.Job, error) {
+	ctx, span := otel.Tracer("EmbeddingsService").Start(ctx, "StartMigration", trace.WithAttributes(
+		attribute.String("embedding.table", req.Table),
+	))
+	defer span.End()
+
+	kind, ok := reembedKinds[req.Table]
+	if !ok {
+		span.SetStatus(codes.Error, "Unknown table")
+		return nil, fmt.Errorf("table %q has no embeddings: %w", req.Table, types.ErrBadRequest)
+	}
+	target := types.EmbeddingModel{Name: strings.TrimSpace(req.Model), Version: req.Version}
+	if target.Name == "" || target.Version < 1 {
+		span.SetStatus(codes.Error, "Invalid model")
+		return nil, fmt.Errorf("model name and a version of at least 1 are required: %w", types.ErrBadRequest)
+	}
+	span.SetAttributes(attribute.String("embedding.target", target.String()))
+
+	if _, err := s.repo.StartMigration(ctx, req.Table, target); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to start migration")
+		return nil, err
+	}
+
+	job, err := s.queue.Enqueue(ctx, types.EnqueueJobRequest{Kind: kind}, requestedBy)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to enqueue re-embedding")
+		return nil, fmt.Errorf("failed to queue re-embedding: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "Embedding migration started",
+		slog.String("table", req.Table),
+		slog.String("model", target.String()),
+		slog.String("job_id", job.ID.String()))
+	span.SetStatus(codes.Ok, "Migration started")
+	return job, nil
+}
+
+// run embeds a single target, or sweeps every pending entity page by page.
+// A sweep keeps going past individual failures and returns an error at the end
+// if any occurred, so the retry only picks up what is still missing.
+func run[T any](
+	ctx context.Context,
+	s *Service,
+	job *types.Job,
+	report jobs.ProgressFunc,
+	get func(context.Context, uuid.UUID) (*T, error),
+	pending func(context.Context, uuid.UUID, int) ([]T, error),
+	idOf func(T) uuid.UUID,
+	embed func(context.Context, T) error,
+) error {
+	ctx, span := otel.Tracer("EmbeddingsService").Start(ctx, "Run", trace.WithAttributes(
+		attribute.String("job.kind", job.Kind),
+		attribute.Bool("job.sweep", job.TargetID == nil),
+	))
+	defer span.End()
+
+	if job.TargetID != nil {
+		item, err := get(ctx, *job.TargetID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Target lookup failed")
+			return err
+		}
+		if err := embed(ctx, *item); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Embedding failed")
+			return fmt.Errorf("failed to embed %s: %w", job.TargetID, err)
+		}
+		report(types.JobProgress{Total: 1, Done: 1})
+		span.SetStatus(codes.Ok, "Embedding generated")
+		return nil
+	}
+
+	total, err := s.repo.CountPending(ctx, job.Kind)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Count failed")
+		return err
+	}
+	progress := types.JobProgress{Total: total}
+	report(progress)
+
+	after := uuid.Nil
+	for {
+		page, err := pending(ctx, after, sweepPageSize)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Page fetch failed")
+			return err
+		}
+		if len(page) == 0 {
+			break
+		}
+		for _, item := range page {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := embed(ctx, item); err != nil {
+				progress.Failed++
+				s.logger.WarnContext(ctx, "Failed to generate embedding",
+					slog.String("kind", job.Kind),
+					slog.String("id", idOf(item).String()),
+					slog.Any("error", err))
+			} else {
+				progress.Done++
+			}
+			report(progress)
+		}
+		after = idOf(page[len(page)-1])
+	}
+
+	span.SetAttributes(attribute.Int("embeddings.done", progress.Done), attribute.Int("embeddings.failed", progress.Failed))
+	if progress.Failed > 0 {
+		span.SetStatus(codes.Error, "Some embeddings failed")
+		return fmt.Errorf("%d of %d embeddings failed", progress.Failed, progress.Done+progress.Failed)
+	}
+	span.SetStatus(codes.Ok, "Sweep completed")
+	return nil
+}
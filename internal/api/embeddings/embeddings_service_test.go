@@ -0,0 +1,339 @@
+package embeddings
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+// MockEmbeddingsRepository is a mock implementation of Repository
+type MockEmbeddingsRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmbeddingsRepository) CountPending(ctx context.Context, kind string) (int, error) {
+	args := m.Called(ctx, kind)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockEmbeddingsRepository) PendingPOIs(ctx context.Context, after uuid.UUID, limit int) ([]POISource, error) {
+	args := m.Called(ctx, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]POISource), args.Error(1)
+}
+
+func (m *MockEmbeddingsRepository) GetPOI(ctx context.Context, poiID uuid.UUID) (*POISource, error) {
+	args := m.Called(ctx, poiID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*POISource), args.Error(1)
+}
+
+func (m *MockEmbeddingsRepository) PendingCities(ctx context.Context, after uuid.UUID, limit int) ([]CitySource, error) {
+	args := m.Called(ctx, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]CitySource), args.Error(1)
+}
+
+func (m *MockEmbeddingsRepository) GetCity(ctx context.Context, cityID uuid.UUID) (*CitySource, error) {
+	args := m.Called(ctx, cityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*CitySource), args.Error(1)
+}
+
+func (m *MockEmbeddingsRepository) PendingUserPreferences(ctx context.Context, after uuid.UUID, limit int) ([]UserPreferenceSource, error) {
+	args := m.Called(ctx, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]UserPreferenceSource), args.Error(1)
+}
+
+func (m *MockEmbeddingsRepository) GetUserPreferences(ctx context.Context, userID uuid.UUID) (*UserPreferenceSource, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*UserPreferenceSource), args.Error(1)
+}
+
+func (m *MockEmbeddingsRepository) SaveEmbedding(ctx context.Context, table string, id uuid.UUID, model types.EmbeddingModel, embedding []float32) error {
+	return m.Called(ctx, table, id, model, embedding).Error(0)
+}
+
+func (m *MockEmbeddingsRepository) GetModelState(ctx context.Context, table string) (*types.EmbeddingModelState, error) {
+	args := m.Called(ctx, table)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.EmbeddingModelState), args.Error(1)
+}
+
+func (m *MockEmbeddingsRepository) ListModelStates(ctx context.Context) ([]types.EmbeddingModelState, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.EmbeddingModelState), args.Error(1)
+}
+
+func (m *MockEmbeddingsRepository) StartMigration(ctx context.Context, table string, target types.EmbeddingModel) (*types.EmbeddingModelState, error) {
+	args := m.Called(ctx, table, target)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.EmbeddingModelState), args.Error(1)
+}
+
+func (m *MockEmbeddingsRepository) CountReembedPending(ctx context.Context, table string, target types.EmbeddingModel) (int, error) {
+	args := m.Called(ctx, table, target)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockEmbeddingsRepository) PendingReembed(ctx context.Context, table string, target types.EmbeddingModel, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	args := m.Called(ctx, table, target, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockEmbeddingsRepository) SaveNextEmbedding(ctx context.Context, table string, id uuid.UUID, model types.EmbeddingModel, embedding []float32) error {
+	return m.Called(ctx, table, id, model, embedding).Error(0)
+}
+
+func (m *MockEmbeddingsRepository) Cutover(ctx context.Context, table string, target types.EmbeddingModel) (int, error) {
+	args := m.Called(ctx, table, target)
+	return args.Int(0), args.Error(1)
+}
+
+// MockEmbedder is a mock implementation of Embedder
+type MockEmbedder struct {
+	mock.Mock
+}
+
+func (m *MockEmbedder) GeneratePOIEmbedding(ctx context.Context, name, description, category string) ([]float32, error) {
+	args := m.Called(ctx, name, description, category)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]float32), args.Error(1)
+}
+
+func (m *MockEmbedder) GenerateCityEmbedding(ctx context.Context, name, country, description string) ([]float32, error) {
+	args := m.Called(ctx, name, country, description)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]float32), args.Error(1)
+}
+
+func (m *MockEmbedder) GenerateUserPreferenceEmbedding(ctx context.Context, interests []string, preferences map[string]string) ([]float32, error) {
+	args := m.Called(ctx, interests, preferences)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]float32), args.Error(1)
+}
+
+// MockJobQueue is a mock implementation of JobQueue
+type MockJobQueue struct {
+	mock.Mock
+}
+
+func (m *MockJobQueue) Enqueue(ctx context.Context, req types.EnqueueJobRequest, requestedBy *uuid.UUID) (*types.Job, error) {
+	args := m.Called(ctx, req, requestedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Job), args.Error(1)
+}
+
+var (
+	activeModel = types.EmbeddingModel{Name: "text-embedding-a", Version: 1}
+	nextModel   = types.EmbeddingModel{Name: "text-embedding-b", Version: 1}
+)
+
+type embeddingsTest struct {
+	service   *Service
+	repo      *MockEmbeddingsRepository
+	embedders map[types.EmbeddingModel]*MockEmbedder
+	queue     *MockJobQueue
+}
+
+// setupEmbeddingsServiceTest wires a service whose embedder factory hands out one
+// mock per model, so tests can check which model produced a vector.
+func setupEmbeddingsServiceTest() *embeddingsTest {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	tt := &embeddingsTest{
+		repo: new(MockEmbeddingsRepository),
+		embedders: map[types.EmbeddingModel]*MockEmbedder{
+			activeModel: new(MockEmbedder),
+			nextModel:   new(MockEmbedder),
+		},
+		queue: new(MockJobQueue),
+	}
+	tt.service = NewService(tt.repo, func(m types.EmbeddingModel) Embedder { return tt.embedders[m] }, tt.queue, logger)
+	return tt
+}
+
+func (tt *embeddingsTest) expectState(table string, next *types.EmbeddingModel) {
+	tt.repo.On("GetModelState", mock.Anything, table).
+		Return(&types.EmbeddingModelState{Table: table, Active: activeModel, Next: next}, nil)
+}
+
+func TestService_RunPOIEmbedding(t *testing.T) {
+	ctx := context.Background()
+	embedding := []float32{0.1, 0.2}
+
+	t.Run("sweep pages past failures and reports them", func(t *testing.T) {
+		tt := setupEmbeddingsServiceTest()
+		service, mockRepo, mockEmbedder := tt.service, tt.repo, tt.embedders[activeModel]
+		tt.expectState(types.EmbeddingTablePOIs, nil)
+		first := POISource{ID: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Name: "Alfama", Category: "neighbourhood"}
+		second := POISource{ID: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Name: "Belém Tower", Category: "monument"}
+
+		mockRepo.On("CountPending", mock.Anything, types.JobKindPOIEmbedding).Return(2, nil).Once()
+		mockRepo.On("PendingPOIs", mock.Anything, uuid.Nil, sweepPageSize).Return([]POISource{first, second}, nil).Once()
+		mockRepo.On("PendingPOIs", mock.Anything, second.ID, sweepPageSize).Return([]POISource{}, nil).Once()
+		mockEmbedder.On("GeneratePOIEmbedding", mock.Anything, "Alfama", "", "neighbourhood").Return(nil, errors.New("quota exceeded")).Once()
+		mockEmbedder.On("GeneratePOIEmbedding", mock.Anything, "Belém Tower", "", "monument").Return(embedding, nil).Once()
+		mockRepo.On("SaveEmbedding", mock.Anything, types.EmbeddingTablePOIs, second.ID, activeModel, embedding).Return(nil).Once()
+
+		var progress types.JobProgress
+		err := service.RunPOIEmbedding(ctx, &types.Job{Kind: types.JobKindPOIEmbedding}, func(p types.JobProgress) { progress = p })
+
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, types.ErrNotFound)
+		assert.Equal(t, types.JobProgress{Total: 2, Done: 1, Failed: 1}, progress)
+		mockRepo.AssertExpectations(t)
+		mockEmbedder.AssertExpectations(t)
+	})
+
+	t.Run("single target", func(t *testing.T) {
+		tt := setupEmbeddingsServiceTest()
+		service, mockRepo, mockEmbedder := tt.service, tt.repo, tt.embedders[activeModel]
+		tt.expectState(types.EmbeddingTablePOIs, nil)
+		poi := POISource{ID: uuid.New(), Name: "Tram 28", Description: "Historic tram", Category: "transport"}
+
+		mockRepo.On("GetPOI", mock.Anything, poi.ID).Return(&poi, nil).Once()
+		mockEmbedder.On("GeneratePOIEmbedding", mock.Anything, poi.Name, poi.Description, poi.Category).Return(embedding, nil).Once()
+		mockRepo.On("SaveEmbedding", mock.Anything, types.EmbeddingTablePOIs, poi.ID, activeModel, embedding).Return(nil).Once()
+
+		var progress types.JobProgress
+		err := service.RunPOIEmbedding(ctx, &types.Job{Kind: types.JobKindPOIEmbedding, TargetID: &poi.ID}, func(p types.JobProgress) { progress = p })
+
+		require.NoError(t, err)
+		assert.Equal(t, types.JobProgress{Total: 1, Done: 1}, progress)
+		mockRepo.AssertNotCalled(t, "CountPending", mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_RunPOIReembed(t *testing.T) {
+	ctx := context.Background()
+	embedding := []float32{0.3, 0.4}
+	poi := POISource{ID: uuid.New(), Name: "Alfama", Category: "neighbourhood"}
+	job := &types.Job{Kind: types.JobKindPOIReembed}
+
+	t.Run("fills the shadow column with the next model, then cuts over", func(t *testing.T) {
+		tt := setupEmbeddingsServiceTest()
+		tt.expectState(types.EmbeddingTablePOIs, &nextModel)
+		tt.repo.On("CountReembedPending", mock.Anything, types.EmbeddingTablePOIs, nextModel).Return(1, nil).Once()
+		tt.repo.On("PendingReembed", mock.Anything, types.EmbeddingTablePOIs, nextModel, uuid.Nil, sweepPageSize).Return([]uuid.UUID{poi.ID}, nil).Once()
+		tt.repo.On("PendingReembed", mock.Anything, types.EmbeddingTablePOIs, nextModel, poi.ID, sweepPageSize).Return([]uuid.UUID{}, nil).Once()
+		tt.repo.On("GetPOI", mock.Anything, poi.ID).Return(&poi, nil).Once()
+		tt.embedders[nextModel].On("GeneratePOIEmbedding", mock.Anything, poi.Name, "", poi.Category).Return(embedding, nil).Once()
+		tt.repo.On("SaveNextEmbedding", mock.Anything, types.EmbeddingTablePOIs, poi.ID, nextModel, embedding).Return(nil).Once()
+		tt.repo.On("Cutover", mock.Anything, types.EmbeddingTablePOIs, nextModel).Return(0, nil).Once()
+
+		var progress types.JobProgress
+		err := tt.service.RunPOIReembed(ctx, job, func(p types.JobProgress) { progress = p })
+
+		require.NoError(t, err)
+		assert.Equal(t, types.JobProgress{Total: 1, Done: 1}, progress)
+		tt.repo.AssertExpectations(t)
+		tt.embedders[activeModel].AssertNotCalled(t, "GeneratePOIEmbedding", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("postpones the cutover when a row fails", func(t *testing.T) {
+		tt := setupEmbeddingsServiceTest()
+		tt.expectState(types.EmbeddingTablePOIs, &nextModel)
+		tt.repo.On("CountReembedPending", mock.Anything, types.EmbeddingTablePOIs, nextModel).Return(1, nil).Once()
+		tt.repo.On("PendingReembed", mock.Anything, types.EmbeddingTablePOIs, nextModel, uuid.Nil, sweepPageSize).Return([]uuid.UUID{poi.ID}, nil).Once()
+		tt.repo.On("PendingReembed", mock.Anything, types.EmbeddingTablePOIs, nextModel, poi.ID, sweepPageSize).Return([]uuid.UUID{}, nil).Once()
+		tt.repo.On("GetPOI", mock.Anything, poi.ID).Return(&poi, nil).Once()
+		tt.embedders[nextModel].On("GeneratePOIEmbedding", mock.Anything, poi.Name, "", poi.Category).Return(nil, errors.New("quota exceeded")).Once()
+
+		err := tt.service.RunPOIReembed(ctx, job, func(types.JobProgress) {})
+
+		assert.Error(t, err)
+		tt.repo.AssertNotCalled(t, "Cutover", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("fails permanently without a migration in progress", func(t *testing.T) {
+		tt := setupEmbeddingsServiceTest()
+		tt.expectState(types.EmbeddingTablePOIs, nil)
+
+		err := tt.service.RunPOIReembed(ctx, job, func(types.JobProgress) {})
+
+		assert.ErrorIs(t, err, types.ErrBadRequest)
+		tt.repo.AssertNotCalled(t, "CountReembedPending", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_StartMigration(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("records the target and queues the table's re-embedding job", func(t *testing.T) {
+		tt := setupEmbeddingsServiceTest()
+		adminID := uuid.New()
+		job := &types.Job{ID: uuid.New(), Kind: types.JobKindCityReembed}
+		tt.repo.On("StartMigration", mock.Anything, types.EmbeddingTableCities, nextModel).
+			Return(&types.EmbeddingModelState{Table: types.EmbeddingTableCities, Active: activeModel, Next: &nextModel}, nil).Once()
+		tt.queue.On("Enqueue", mock.Anything, types.EnqueueJobRequest{Kind: types.JobKindCityReembed}, &adminID).Return(job, nil).Once()
+
+		got, err := tt.service.StartMigration(ctx, types.StartReembedRequest{Table: types.EmbeddingTableCities, Model: " text-embedding-b ", Version: 1}, &adminID)
+
+		require.NoError(t, err)
+		assert.Equal(t, job, got)
+		tt.repo.AssertExpectations(t)
+		tt.queue.AssertExpectations(t)
+	})
+
+	t.Run("rejects unknown tables and incomplete models", func(t *testing.T) {
+		tt := setupEmbeddingsServiceTest()
+
+		_, err := tt.service.StartMigration(ctx, types.StartReembedRequest{Table: "users", Model: "m", Version: 1}, nil)
+		assert.ErrorIs(t, err, types.ErrBadRequest)
+		_, err = tt.service.StartMigration(ctx, types.StartReembedRequest{Table: types.EmbeddingTablePOIs, Model: "m"}, nil)
+		assert.ErrorIs(t, err, types.ErrBadRequest)
+
+		tt.repo.AssertNotCalled(t, "StartMigration", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("does not queue a job when another migration is in progress", func(t *testing.T) {
+		tt := setupEmbeddingsServiceTest()
+		tt.repo.On("StartMigration", mock.Anything, types.EmbeddingTablePOIs, nextModel).Return(nil, types.ErrConflict).Once()
+
+		_, err := tt.service.StartMigration(ctx, types.StartReembedRequest{Table: types.EmbeddingTablePOIs, Model: nextModel.Name, Version: nextModel.Version}, nil)
+
+		assert.ErrorIs(t, err, types.ErrConflict)
+		tt.queue.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
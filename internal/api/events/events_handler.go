@@ -0,0 +1,358 @@
+package events
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/auth"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Handler = (*HandlerImpl)(nil)
+
+type Handler interface {
+	SearchEvents(w http.ResponseWriter, r *http.Request)
+	GetEvent(w http.ResponseWriter, r *http.Request)
+	CreateEvent(w http.ResponseWriter, r *http.Request)
+	DeleteEvent(w http.ResponseWriter, r *http.Request)
+	ListFeeds(w http.ResponseWriter, r *http.Request)
+	CreateFeed(w http.ResponseWriter, r *http.Request)
+	IngestFeed(w http.ResponseWriter, r *http.Request)
+}
+
+type HandlerImpl struct {
+	logger  *slog.Logger
+	service Service
+}
+
+func NewHandler(service Service, logger *slog.Logger) *HandlerImpl {
+	return &HandlerImpl{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// parseTimeParam reads an RFC3339 timestamp or a YYYY-MM-DD date, taken as
+// midnight UTC.
+func parseTimeParam(value string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// SearchEvents godoc
+// @Summary      Search Events
+// @Description  Lists events on at some time between from and to, in start order. Filters by city, categories and distance from a point.
+// @Tags         Events
+// @Produce      json
+// @Param        city_id query string false "Only events in this city"
+// @Param        from query string false "Only events still on at or after this time (RFC3339 or YYYY-MM-DD)"
+// @Param        to query string false "Only events starting before this time (RFC3339 or YYYY-MM-DD)"
+// @Param        category query string false "Comma-separated categories (festival, concert, sports, cultural, food, exhibition, theatre, market, other)"
+// @Param        lat query number false "Latitude of the search point"
+// @Param        lon query number false "Longitude of the search point"
+// @Param        radius query number false "Only events within this many kilometers of the search point"
+// @Param        cursor query string false "Cursor of the page to fetch, from the Link header; omit for the first page"
+// @Param        limit query int false "Maximum events to return (default 50, max 200)"
+// @Success      200 {array} types.Event
+// @Failure      400 {object} types.Response "Invalid filter or cursor"
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /events [get]
+func (h *HandlerImpl) SearchEvents(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("EventsHandler").Start(r.Context(), "SearchEvents")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "SearchEvents"))
+
+	page, err := api.ParsePageRequest(r)
+	if err != nil {
+		span.SetStatus(codes.Error, "Invalid cursor")
+		api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	q := r.URL.Query()
+	params := types.EventSearchParams{
+		Categories: api.QueryList(r, "category"),
+		Page:       page,
+	}
+	if v := q.Get("city_id"); v != "" {
+		cityID, err := uuid.Parse(v)
+		if err != nil {
+			span.SetStatus(codes.Error, "Invalid city ID")
+			api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid city_id")
+			return
+		}
+		params.CityID = &cityID
+	}
+	for name, dest := range map[string]*time.Time{"from": &params.From, "to": &params.To} {
+		if v := q.Get(name); v != "" {
+			if *dest, err = parseTimeParam(v); err != nil {
+				span.SetStatus(codes.Error, "Invalid "+name)
+				api.ErrorResponse(w, r, http.StatusBadRequest, name+" must be an RFC3339 timestamp or YYYY-MM-DD date")
+				return
+			}
+		}
+	}
+	if latStr, lonStr := q.Get("lat"), q.Get("lon"); latStr != "" || lonStr != "" {
+		lat, latErr := strconv.ParseFloat(latStr, 64)
+		lon, lonErr := strconv.ParseFloat(lonStr, 64)
+		if latErr != nil || lonErr != nil {
+			span.SetStatus(codes.Error, "Invalid location")
+			api.ErrorResponse(w, r, http.StatusBadRequest, "lat and lon must both be valid coordinates")
+			return
+		}
+		params.Lat, params.Lon = &lat, &lon
+	}
+	if v := q.Get("radius"); v != "" {
+		if params.RadiusKm, err = strconv.ParseFloat(v, 64); err != nil {
+			span.SetStatus(codes.Error, "Invalid radius")
+			api.ErrorResponse(w, r, http.StatusBadRequest, "radius must be a number of kilometers")
+			return
+		}
+	}
+
+	events, next, err := h.service.Search(ctx, params)
+	if err != nil {
+		l.ErrorContext(ctx, "Service failed to search events", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to search events")
+		api.WriteServiceError(w, r, err, "Failed to search events")
+		return
+	}
+
+	span.SetAttributes(attribute.Int("results.count", len(events)))
+	span.SetStatus(codes.Ok, "Events searched")
+	api.SetPageLinks(w, r, next)
+	api.WriteJSONResponse(w, r, http.StatusOK, events)
+}
+
+// GetEvent godoc
+// @Summary      Get Event
+// @Description  Returns an event.
+// @Tags         Events
+// @Produce      json
+// @Param        eventID path string true "Event ID"
+// @Success      200 {object} types.Event
+// @Failure      400 {object} types.Response "Invalid event ID"
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      404 {object} types.Response "Event not found"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /events/{eventID} [get]
+func (h *HandlerImpl) GetEvent(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("EventsHandler").Start(r.Context(), "GetEvent")
+	defer span.End()
+
+	eventID, err := uuid.Parse(chi.URLParam(r, "eventID"))
+	if err != nil {
+		span.SetStatus(codes.Error, "Invalid event ID")
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+	span.SetAttributes(attribute.String("event.id", eventID.String()))
+
+	event, err := h.service.GetEvent(ctx, eventID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to get event")
+		api.WriteServiceError(w, r, err, "Failed to get event")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Event returned")
+	api.WriteJSONResponse(w, r, http.StatusOK, event)
+}
+
+// CreateEvent godoc
+// @Summary      Add an Event
+// @Description  Adds an event to a city, optionally at one of its POIs. Admins and moderators only.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        event body types.CreateEventRequest true "Event details"
+// @Success      201 {object} types.Event
+// @Failure      400 {object} types.Response "Missing name, city or start, or invalid dates or category"
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      403 {object} types.Response "Forbidden"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/events [post]
+func (h *HandlerImpl) CreateEvent(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("EventsHandler").Start(r.Context(), "CreateEvent")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "CreateEvent"))
+
+	var req types.CreateEventRequest
+	if err := api.DecodeJSONBody(w, r, &req); err != nil {
+		l.ErrorContext(ctx, "Failed to decode request", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Bad request")
+		return
+	}
+
+	event, err := h.service.CreateEvent(ctx, req)
+	if err != nil {
+		l.ErrorContext(ctx, "Service failed to create event", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to create event")
+		api.WriteServiceError(w, r, err, "Failed to create event")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Event created")
+	api.WriteJSONResponse(w, r, http.StatusCreated, event)
+}
+
+// DeleteEvent godoc
+// @Summary      Delete an Event
+// @Description  Deletes an event. Events of a feed come back on its next ingestion unless removed from the feed. Admins and moderators only.
+// @Tags         Admin
+// @Param        eventID path string true "Event ID"
+// @Success      204 "Event deleted"
+// @Failure      400 {object} types.Response "Invalid event ID"
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      403 {object} types.Response "Forbidden"
+// @Failure      404 {object} types.Response "Event not found"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/events/{eventID} [delete]
+func (h *HandlerImpl) DeleteEvent(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("EventsHandler").Start(r.Context(), "DeleteEvent")
+	defer span.End()
+
+	eventID, err := uuid.Parse(chi.URLParam(r, "eventID"))
+	if err != nil {
+		span.SetStatus(codes.Error, "Invalid event ID")
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+	span.SetAttributes(attribute.String("event.id", eventID.String()))
+
+	if err := h.service.DeleteEvent(ctx, eventID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to delete event")
+		api.WriteServiceError(w, r, err, "Failed to delete event")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Event deleted")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListFeeds godoc
+// @Summary      List Event Feeds
+// @Description  Returns the iCal feeds events are ingested from, with when each was last ingested. Admin only.
+// @Tags         Admin
+// @Produce      json
+// @Success      200 {array} types.EventFeed
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      403 {object} types.Response "Forbidden"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/events/feeds [get]
+func (h *HandlerImpl) ListFeeds(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("EventsHandler").Start(r.Context(), "ListFeeds")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "ListFeeds"))
+
+	feeds, err := h.service.ListFeeds(ctx)
+	if err != nil {
+		l.ErrorContext(ctx, "Service failed to list event feeds", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to list event feeds")
+		api.WriteServiceError(w, r, err, "Failed to list event feeds")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Event feeds listed")
+	api.WriteJSONResponse(w, r, http.StatusOK, feeds)
+}
+
+// CreateFeed godoc
+// @Summary      Add an Event Feed
+// @Description  Registers an iCal feed for a city and queues a job that reads it and stores its events. The source is an http(s) URL or a file:// path inside the server's configured feed directory. Poll /admin/jobs/{jobID} with the returned ingest_job_id for progress. Admin only.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        feed body types.CreateEventFeedRequest true "Feed name, iCal URL, city and default category"
+// @Success      202 {object} types.EventFeed
+// @Failure      400 {object} types.Response "Missing name or city, invalid URL or unknown category"
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      403 {object} types.Response "Forbidden"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/events/feeds [post]
+func (h *HandlerImpl) CreateFeed(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("EventsHandler").Start(r.Context(), "CreateFeed")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "CreateFeed"))
+
+	var req types.CreateEventFeedRequest
+	if err := api.DecodeJSONBody(w, r, &req); err != nil {
+		l.ErrorContext(ctx, "Failed to decode request", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Bad request")
+		return
+	}
+
+	feed, err := h.service.CreateFeed(ctx, req, api.Requester(auth.GetUserIDFromContext(ctx)))
+	if err != nil {
+		l.ErrorContext(ctx, "Service failed to create event feed", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to create event feed")
+		api.WriteServiceError(w, r, err, "Failed to create event feed")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Event feed created")
+	api.WriteJSONResponse(w, r, http.StatusAccepted, feed)
+}
+
+// IngestFeed godoc
+// @Summary      Re-ingest an Event Feed
+// @Description  Queues a job that reads the feed again, updating its events and removing those no longer in it. Admin only.
+// @Tags         Admin
+// @Produce      json
+// @Param        feedID path string true "Event feed ID"
+// @Success      202 {object} types.Job
+// @Failure      400 {object} types.Response "Invalid feed ID"
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      403 {object} types.Response "Forbidden"
+// @Failure      404 {object} types.Response "Feed not found"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/events/feeds/{feedID}/ingest [post]
+func (h *HandlerImpl) IngestFeed(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("EventsHandler").Start(r.Context(), "IngestFeed")
+	defer span.End()
+
+	feedID, err := uuid.Parse(chi.URLParam(r, "feedID"))
+	if err != nil {
+		span.SetStatus(codes.Error, "Invalid feed ID")
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid feed ID")
+		return
+	}
+	span.SetAttributes(attribute.String("feed.id", feedID.String()))
+
+	job, err := h.service.IngestFeed(ctx, feedID, api.Requester(auth.GetUserIDFromContext(ctx)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to queue ingestion")
+		api.WriteServiceError(w, r, err, "Failed to queue event feed ingestion")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Ingestion queued")
+	api.WriteJSONResponse(w, r, http.StatusAccepted, job)
+}
@@ -0,0 +1,342 @@
+package events
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+// categoryKeywords maps words found in iCal CATEGORIES, beyond the category
+// names themselves, to event categories.
+var categoryKeywords = map[string]string{
+	"music":        types.EventCategoryConcert,
+	"gig":          types.EventCategoryConcert,
+	"live music":   types.EventCategoryConcert,
+	"sport":        types.EventCategorySports,
+	"match":        types.EventCategorySports,
+	"culture":      types.EventCategoryCultural,
+	"heritage":     types.EventCategoryCultural,
+	"gastronomy":   types.EventCategoryFood,
+	"food & drink": types.EventCategoryFood,
+	"art":          types.EventCategoryExhibition,
+	"museum":       types.EventCategoryExhibition,
+	"theater":      types.EventCategoryTheatre,
+	"dance":        types.EventCategoryTheatre,
+	"opera":        types.EventCategoryTheatre,
+	"fair":         types.EventCategoryMarket,
+}
+
+// contentLine is one unfolded iCal property.
+type contentLine struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// parseICal reads the VEVENTs of an iCal feed. Times without a zone are read
+// in the calendar's X-WR-TIMEZONE, or UTC. Recurring events keep only their
+// first occurrence, so rescheduled instances and repeated UIDs are skipped,
+// and cancelled events are dropped. Properties of components nested in an
+// event, such as a VALARM's DESCRIPTION or TRIGGER, are ignored. Events without a UID get one derived from
+// their name and start, so re-ingesting the feed updates rather than
+// duplicates them.
+func parseICal(r io.Reader, defaultCategory string) ([]types.Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := time.UTC
+	var events []types.Event
+	seen := make(map[string]bool)
+	var current []contentLine
+	inEvent, sawCalendar := false, false
+	// nested counts the open sub-components of the current event
+	nested := 0
+	for _, raw := range lines {
+		line, ok := parseContentLine(raw)
+		if !ok {
+			continue
+		}
+		switch {
+		case line.name == "BEGIN" && strings.EqualFold(line.value, "VCALENDAR"):
+			sawCalendar = true
+		case line.name == "X-WR-TIMEZONE" && !inEvent:
+			if l, err := time.LoadLocation(line.value); err == nil {
+				loc = l
+			}
+		case inEvent && line.name == "BEGIN":
+			nested++
+		case inEvent && nested > 0:
+			if line.name == "END" {
+				nested--
+			}
+		case line.name == "BEGIN" && strings.EqualFold(line.value, "VEVENT"):
+			inEvent, current = true, nil
+		case line.name == "END" && strings.EqualFold(line.value, "VEVENT"):
+			inEvent = false
+			if event, ok := buildEvent(current, loc, defaultCategory); ok && !seen[event.ExternalUID] {
+				seen[event.ExternalUID] = true
+				events = append(events, event)
+			}
+		case inEvent:
+			current = append(current, line)
+		}
+	}
+	if !sawCalendar {
+		return nil, fmt.Errorf("%w: feed is not an iCal calendar", types.ErrBadRequest)
+	}
+	return events, nil
+}
+
+// unfold joins the continuation lines of an iCal stream, which start with a
+// space or tab, onto the line before.
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) == 0 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read iCal feed: %w", err)
+	}
+	return lines, nil
+}
+
+// parseContentLine splits NAME;PARAM=VALUE:value, minding quoted parameter
+// values that may contain colons.
+func parseContentLine(raw string) (contentLine, bool) {
+	inQuotes := false
+	colon := -1
+	for i, r := range raw {
+		if r == '"' {
+			inQuotes = !inQuotes
+		} else if r == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon <= 0 {
+		return contentLine{}, false
+	}
+	parts := strings.Split(raw[:colon], ";")
+	line := contentLine{name: strings.ToUpper(parts[0]), value: raw[colon+1:]}
+	for _, p := range parts[1:] {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			if line.params == nil {
+				line.params = make(map[string]string)
+			}
+			line.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return line, true
+}
+
+func buildEvent(lines []contentLine, loc *time.Location, defaultCategory string) (types.Event, bool) {
+	var event types.Event
+	var duration time.Duration
+	hasStart, hasEnd, hasDuration := false, false, false
+	var categories []string
+	for _, line := range lines {
+		switch line.name {
+		case "UID":
+			event.ExternalUID = strings.TrimSpace(line.value)
+		case "SUMMARY":
+			event.Name = strings.TrimSpace(unescapeText(line.value))
+		case "DESCRIPTION":
+			event.Description = strings.TrimSpace(unescapeText(line.value))
+		case "LOCATION":
+			event.Venue = strings.TrimSpace(unescapeText(line.value))
+		case "URL":
+			event.URL = strings.TrimSpace(line.value)
+		case "CATEGORIES":
+			categories = append(categories, splitText(line.value)...)
+		case "GEO":
+			if lat, lon, ok := parseGeo(line.value); ok {
+				event.Latitude, event.Longitude = &lat, &lon
+			}
+		case "STATUS":
+			if strings.EqualFold(strings.TrimSpace(line.value), "CANCELLED") {
+				return types.Event{}, false
+			}
+		case "RECURRENCE-ID":
+			return types.Event{}, false
+		case "DTSTART":
+			t, allDay, ok := parseICalTime(line, loc)
+			if !ok {
+				return types.Event{}, false
+			}
+			event.StartsAt, event.AllDay, hasStart = t, allDay, true
+		case "DTEND":
+			if t, _, ok := parseICalTime(line, loc); ok {
+				event.EndsAt, hasEnd = t, true
+			}
+		case "DURATION":
+			duration, hasDuration = parseICalDuration(line.value)
+		}
+	}
+	if !hasStart || event.Name == "" {
+		return types.Event{}, false
+	}
+	switch {
+	case hasEnd && !event.EndsAt.Before(event.StartsAt):
+	case hasDuration:
+		event.EndsAt = event.StartsAt.Add(duration)
+	case event.AllDay:
+		event.EndsAt = event.StartsAt.AddDate(0, 0, 1)
+	default:
+		event.EndsAt = event.StartsAt
+	}
+	if event.ExternalUID == "" {
+		sum := sha1.Sum([]byte(event.Name + "\x00" + event.StartsAt.UTC().Format(time.RFC3339)))
+		event.ExternalUID = "generated-" + hex.EncodeToString(sum[:])
+	}
+	event.Category = categoryOf(categories, defaultCategory)
+	return event, true
+}
+
+// parseICalTime reads a DATE or DATE-TIME value: UTC with a trailing Z, in
+// its TZID, or floating in loc. Dates are all day, starting at midnight in loc.
+func parseICalTime(line contentLine, loc *time.Location) (time.Time, bool, bool) {
+	value := strings.TrimSpace(line.value)
+	if line.params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t, true, err == nil
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err == nil
+	}
+	if tzid := line.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err == nil
+}
+
+// parseICalDuration reads durations such as PT1H30M, P1D or P2W.
+func parseICalDuration(value string) (time.Duration, bool) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "+")
+	if !strings.HasPrefix(value, "P") {
+		return 0, false
+	}
+	var total time.Duration
+	inTime := false
+	number := ""
+	for _, r := range value[1:] {
+		switch {
+		case r >= '0' && r <= '9':
+			number += string(r)
+			continue
+		case r == 'T':
+			inTime = true
+			continue
+		}
+		n, err := strconv.Atoi(number)
+		if err != nil {
+			return 0, false
+		}
+		number = ""
+		switch {
+		case r == 'W':
+			total += time.Duration(n) * 7 * 24 * time.Hour
+		case r == 'D':
+			total += time.Duration(n) * 24 * time.Hour
+		case r == 'H' && inTime:
+			total += time.Duration(n) * time.Hour
+		case r == 'M' && inTime:
+			total += time.Duration(n) * time.Minute
+		case r == 'S' && inTime:
+			total += time.Duration(n) * time.Second
+		default:
+			return 0, false
+		}
+	}
+	return total, number == ""
+}
+
+func parseGeo(value string) (float64, float64, bool) {
+	latStr, lonStr, ok := strings.Cut(value, ";")
+	if !ok {
+		return 0, 0, false
+	}
+	lat, err1 := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	lon, err2 := strconv.ParseFloat(strings.TrimSpace(lonStr), 64)
+	if err1 != nil || err2 != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return 0, 0, false
+	}
+	return lat, lon, true
+}
+
+// categoryOf returns the first of the iCal categories that names or suggests
+// an event category, or fallback.
+func categoryOf(categories []string, fallback string) string {
+	for _, c := range categories {
+		c = strings.ToLower(strings.TrimSpace(c))
+		if category := types.EventCategoryForInterest(c); category != "" {
+			return category
+		}
+		if category, ok := categoryKeywords[c]; ok {
+			return category
+		}
+		if category, ok := categoryKeywords[strings.TrimSuffix(c, "s")]; ok {
+			return category
+		}
+	}
+	return fallback
+}
+
+// unescapeText undoes iCal TEXT escaping.
+func unescapeText(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i == len(value)-1 {
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
+
+// splitText splits a TEXT list on unescaped commas.
+func splitText(value string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case ',':
+			parts = append(parts, unescapeText(value[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, unescapeText(value[start:]))
+}
@@ -0,0 +1,370 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Repository = (*RepositoryImpl)(nil)
+
+// Repository stores events and the iCal feeds they are ingested from.
+type Repository interface {
+	CreateEvent(ctx context.Context, req types.CreateEventRequest) (*types.Event, error)
+	GetEvent(ctx context.Context, eventID uuid.UUID) (*types.Event, error)
+	DeleteEvent(ctx context.Context, eventID uuid.UUID) error
+	// SearchEvents returns a page of matching events in start order.
+	SearchEvents(ctx context.Context, params types.EventSearchParams) ([]types.Event, *types.Cursor, error)
+
+	CreateFeed(ctx context.Context, req types.CreateEventFeedRequest) (*types.EventFeed, error)
+	GetFeed(ctx context.Context, feedID uuid.UUID) (*types.EventFeed, error)
+	ListFeeds(ctx context.Context) ([]types.EventFeed, error)
+	// ReplaceFeedEvents upserts the feed's events by UID and deletes those no
+	// longer in it, in one transaction. Events are linked to the city's POI
+	// named like their venue.
+	ReplaceFeedEvents(ctx context.Context, feed *types.EventFeed, events []types.Event) error
+}
+
+type RepositoryImpl struct {
+	logger *slog.Logger
+	pgpool *pgxpool.Pool
+}
+
+func NewRepository(pgxpool *pgxpool.Pool, logger *slog.Logger) *RepositoryImpl {
+	return &RepositoryImpl{
+		logger: logger,
+		pgpool: pgxpool,
+	}
+}
+
+const eventColumns = `id, city_id, poi_id, feed_id, COALESCE(external_uid, ''), name, description, category,
+	starts_at, ends_at, all_day, venue, ST_Y(location), ST_X(location), url, created_at, updated_at`
+
+// scanEvent scans eventColumns, followed by any extra columns into extra.
+func scanEvent(row pgx.Row, extra ...any) (*types.Event, error) {
+	var e types.Event
+	dest := []any{&e.ID, &e.CityID, &e.POIID, &e.FeedID, &e.ExternalUID, &e.Name, &e.Description, &e.Category,
+		&e.StartsAt, &e.EndsAt, &e.AllDay, &e.Venue, &e.Latitude, &e.Longitude, &e.URL, &e.CreatedAt, &e.UpdatedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+const feedColumns = `id, name, source_url, city_id, default_category, event_count, ingested_at, created_at, updated_at`
+
+func scanFeed(row pgx.Row) (*types.EventFeed, error) {
+	var f types.EventFeed
+	err := row.Scan(&f.ID, &f.Name, &f.SourceURL, &f.CityID, &f.DefaultCategory, &f.EventCount,
+		&f.IngestedAt, &f.CreatedAt, &f.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// CreateEvent implements Repository.
+func (r *RepositoryImpl) CreateEvent(ctx context.Context, req types.CreateEventRequest) (*types.Event, error) {
+	ctx, span := otel.Tracer("EventsRepo").Start(ctx, "CreateEvent", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "events"),
+		attribute.String("city.id", req.CityID.String()),
+	))
+	defer span.End()
+
+	event, err := scanEvent(r.pgpool.QueryRow(ctx, `
+		INSERT INTO events (city_id, poi_id, name, description, category, starts_at, ends_at, all_day, venue, location, url)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9,
+		        CASE WHEN $10::float8 IS NULL OR $11::float8 IS NULL THEN NULL
+		             ELSE ST_SetSRID(ST_MakePoint($11, $10), 4326) END,
+		        $12)
+		RETURNING `+eventColumns,
+		req.CityID, req.POIID, req.Name, req.Description, req.Category, req.StartsAt, req.EndsAt, req.AllDay,
+		req.Venue, req.Latitude, req.Longitude, req.URL))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB insert failed")
+		return nil, fmt.Errorf("database error creating event: %w", err)
+	}
+	span.SetStatus(codes.Ok, "Event created")
+	return event, nil
+}
+
+// GetEvent implements Repository.
+func (r *RepositoryImpl) GetEvent(ctx context.Context, eventID uuid.UUID) (*types.Event, error) {
+	ctx, span := otel.Tracer("EventsRepo").Start(ctx, "GetEvent", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "events"),
+		attribute.String("event.id", eventID.String()),
+	))
+	defer span.End()
+
+	event, err := scanEvent(r.pgpool.QueryRow(ctx, `SELECT `+eventColumns+` FROM events WHERE id = $1`, eventID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("event not found: %w", types.ErrNotFound)
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, fmt.Errorf("database error fetching event: %w", err)
+	}
+	return event, nil
+}
+
+// DeleteEvent implements Repository.
+func (r *RepositoryImpl) DeleteEvent(ctx context.Context, eventID uuid.UUID) error {
+	ctx, span := otel.Tracer("EventsRepo").Start(ctx, "DeleteEvent", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "events"),
+		attribute.String("event.id", eventID.String()),
+	))
+	defer span.End()
+
+	tag, err := r.pgpool.Exec(ctx, `DELETE FROM events WHERE id = $1`, eventID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB delete failed")
+		return fmt.Errorf("database error deleting event: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("event not found: %w", types.ErrNotFound)
+	}
+	span.SetStatus(codes.Ok, "Event deleted")
+	return nil
+}
+
+// SearchEvents implements Repository.
+func (r *RepositoryImpl) SearchEvents(ctx context.Context, params types.EventSearchParams) ([]types.Event, *types.Cursor, error) {
+	ctx, span := otel.Tracer("EventsRepo").Start(ctx, "SearchEvents", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.sql.table", "events"),
+	))
+	defer span.End()
+
+	var from, to *time.Time
+	if !params.From.IsZero() {
+		from = &params.From
+	}
+	if !params.To.IsZero() {
+		to = &params.To
+	}
+	args := []interface{}{params.CityID, from, to, params.Categories, params.Lat, params.Lon, params.RadiusKm * 1000,
+		params.Page.FetchLimit()}
+	after := ""
+	if c := params.Page.Cursor; c != nil {
+		args = append(args, c.Time, c.ID)
+		after = "AND (starts_at, id) > ($9, $10)"
+	}
+	query := `
+		SELECT ` + eventColumns + `,
+		       CASE WHEN $5::float8 IS NULL OR $6::float8 IS NULL OR location IS NULL THEN NULL
+		            ELSE ST_Distance(location::geography, ST_SetSRID(ST_MakePoint($6, $5), 4326)::geography) / 1000 END
+		FROM events
+		WHERE ($1::uuid IS NULL OR city_id = $1)
+		  AND ($2::timestamptz IS NULL OR ends_at > $2 OR starts_at >= $2)
+		  AND ($3::timestamptz IS NULL OR starts_at < $3)
+		  AND ($4::text[] IS NULL OR cardinality($4::text[]) = 0 OR category = ANY($4))
+		  AND ($5::float8 IS NULL OR $6::float8 IS NULL OR $7::float8 <= 0
+		       OR ST_DWithin(location::geography, ST_SetSRID(ST_MakePoint($6, $5), 4326)::geography, $7))
+		  ` + after + `
+		ORDER BY starts_at, id
+		LIMIT $8`
+	rows, err := r.pgpool.Query(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, nil, fmt.Errorf("database error searching events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []types.Event{}
+	for rows.Next() {
+		var distance *float64
+		event, err := scanEvent(rows, &distance)
+		if err != nil {
+			span.RecordError(err)
+			return nil, nil, fmt.Errorf("database error scanning event: %w", err)
+		}
+		event.Distance = distance
+		events = append(events, *event)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, nil, fmt.Errorf("database error iterating events: %w", err)
+	}
+
+	events, next := types.NextPage(events, params.Page.Limit, func(e types.Event) types.Cursor {
+		return types.Cursor{Time: &e.StartsAt, ID: e.ID}
+	})
+	span.SetAttributes(attribute.Int("results.count", len(events)))
+	span.SetStatus(codes.Ok, "Events searched")
+	return events, next, nil
+}
+
+// CreateFeed implements Repository.
+func (r *RepositoryImpl) CreateFeed(ctx context.Context, req types.CreateEventFeedRequest) (*types.EventFeed, error) {
+	ctx, span := otel.Tracer("EventsRepo").Start(ctx, "CreateFeed", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "event_feeds"),
+	))
+	defer span.End()
+
+	feed, err := scanFeed(r.pgpool.QueryRow(ctx, `
+		INSERT INTO event_feeds (name, source_url, city_id, default_category)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+feedColumns, req.Name, req.SourceURL, req.CityID, req.DefaultCategory))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB insert failed")
+		return nil, fmt.Errorf("database error creating event feed: %w", err)
+	}
+	span.SetStatus(codes.Ok, "Event feed created")
+	return feed, nil
+}
+
+// GetFeed implements Repository.
+func (r *RepositoryImpl) GetFeed(ctx context.Context, feedID uuid.UUID) (*types.EventFeed, error) {
+	ctx, span := otel.Tracer("EventsRepo").Start(ctx, "GetFeed", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "event_feeds"),
+		attribute.String("feed.id", feedID.String()),
+	))
+	defer span.End()
+
+	feed, err := scanFeed(r.pgpool.QueryRow(ctx, `SELECT `+feedColumns+` FROM event_feeds WHERE id = $1`, feedID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("event feed not found: %w", types.ErrNotFound)
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, fmt.Errorf("database error fetching event feed: %w", err)
+	}
+	return feed, nil
+}
+
+// ListFeeds implements Repository.
+func (r *RepositoryImpl) ListFeeds(ctx context.Context) ([]types.EventFeed, error) {
+	ctx, span := otel.Tracer("EventsRepo").Start(ctx, "ListFeeds", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "event_feeds"),
+	))
+	defer span.End()
+
+	rows, err := r.pgpool.Query(ctx, `SELECT `+feedColumns+` FROM event_feeds ORDER BY name, id`)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, fmt.Errorf("database error listing event feeds: %w", err)
+	}
+	defer rows.Close()
+
+	feeds := []types.EventFeed{}
+	for rows.Next() {
+		feed, err := scanFeed(rows)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("database error scanning event feed: %w", err)
+		}
+		feeds = append(feeds, *feed)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("database error iterating event feeds: %w", err)
+	}
+	return feeds, nil
+}
+
+// ReplaceFeedEvents implements Repository.
+func (r *RepositoryImpl) ReplaceFeedEvents(ctx context.Context, feed *types.EventFeed, events []types.Event) error {
+	ctx, span := otel.Tracer("EventsRepo").Start(ctx, "ReplaceFeedEvents", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "events"),
+		attribute.String("feed.id", feed.ID.String()),
+		attribute.Int("events.count", len(events)),
+	))
+	defer span.End()
+
+	n := len(events)
+	uids, names, descriptions, categories := make([]string, n), make([]string, n), make([]string, n), make([]string, n)
+	starts, ends := make([]time.Time, n), make([]time.Time, n)
+	allDay := make([]bool, n)
+	venues, urls := make([]string, n), make([]string, n)
+	lats, lons := make([]*float64, n), make([]*float64, n)
+	for i, e := range events {
+		uids[i], names[i], descriptions[i], categories[i] = e.ExternalUID, e.Name, e.Description, e.Category
+		starts[i], ends[i], allDay[i] = e.StartsAt, e.EndsAt, e.AllDay
+		venues[i], urls[i], lats[i], lons[i] = e.Venue, e.URL, e.Latitude, e.Longitude
+	}
+
+	tx, err := r.pgpool.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the feed so concurrent ingestions of it run one after the other
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM event_feeds WHERE id = $1 FOR UPDATE`, feed.ID); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("database error locking event feed: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM events WHERE feed_id = $1 AND NOT (external_uid = ANY($2))`, feed.ID, uids); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("database error removing stale events: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO events (city_id, feed_id, external_uid, name, description, category, starts_at, ends_at,
+		                    all_day, venue, url, location, poi_id)
+		SELECT $1, $2, e.uid, e.name, e.description, e.category, e.starts_at, e.ends_at,
+		       e.all_day, e.venue, e.url,
+		       CASE WHEN e.lat IS NULL OR e.lon IS NULL THEN NULL ELSE ST_SetSRID(ST_MakePoint(e.lon, e.lat), 4326) END,
+		       (SELECT p.id FROM points_of_interest p
+		        WHERE e.venue <> '' AND p.city_id = $1 AND lower(p.name) = lower(e.venue)
+		        LIMIT 1)
+		FROM unnest($3::text[], $4::text[], $5::text[], $6::text[], $7::timestamptz[], $8::timestamptz[],
+		            $9::bool[], $10::text[], $11::text[], $12::float8[], $13::float8[])
+		     AS e(uid, name, description, category, starts_at, ends_at, all_day, venue, url, lat, lon)
+		ON CONFLICT (feed_id, external_uid) DO UPDATE SET
+			name = EXCLUDED.name,
+			description = EXCLUDED.description,
+			category = EXCLUDED.category,
+			starts_at = EXCLUDED.starts_at,
+			ends_at = EXCLUDED.ends_at,
+			all_day = EXCLUDED.all_day,
+			venue = EXCLUDED.venue,
+			url = EXCLUDED.url,
+			location = EXCLUDED.location,
+			poi_id = COALESCE(EXCLUDED.poi_id, events.poi_id)`,
+		feed.CityID, feed.ID, uids, names, descriptions, categories, starts, ends, allDay, venues, urls, lats, lons)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("database error upserting events: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE event_feeds SET event_count = $2, ingested_at = NOW() WHERE id = $1`, feed.ID, n); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("database error updating event feed: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Commit failed")
+		return fmt.Errorf("failed to commit event feed ingestion: %w", err)
+	}
+	span.SetStatus(codes.Ok, "Feed events replaced")
+	return nil
+}
@@ -0,0 +1,387 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/config"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/jobs"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Service = (*ServiceImpl)(nil)
+
+// Search page sizes.
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// Itinerary events: how many are looked at, and how many are kept.
+const (
+	itineraryCandidates = 100
+	maxItineraryEvents  = 10
+)
+
+// Feed downloads.
+const (
+	maxFeedBytes    = 16 << 20
+	downloadTimeout = time.Minute
+)
+
+// Service manages city events and ingests them from iCal feeds.
+type Service interface {
+	Search(ctx context.Context, params types.EventSearchParams) ([]types.Event, *types.Cursor, error)
+	GetEvent(ctx context.Context, eventID uuid.UUID) (*types.Event, error)
+	CreateEvent(ctx context.Context, req types.CreateEventRequest) (*types.Event, error)
+	DeleteEvent(ctx context.Context, eventID uuid.UUID) error
+	// During returns the city's events overlapping [from, to) for an
+	// itinerary, those matching the interests first. It never fails: errors
+	// are logged and leave the itinerary without events.
+	During(ctx context.Context, cityID uuid.UUID, from, to time.Time, interests []string) []types.Event
+
+	ListFeeds(ctx context.Context) ([]types.EventFeed, error)
+	// CreateFeed registers an iCal feed and queues its first ingestion.
+	CreateFeed(ctx context.Context, req types.CreateEventFeedRequest, requestedBy *uuid.UUID) (*types.EventFeed, error)
+	// IngestFeed queues a fresh ingestion of a feed from its source URL.
+	IngestFeed(ctx context.Context, feedID uuid.UUID, requestedBy *uuid.UUID) (*types.Job, error)
+}
+
+// JobRegistry is the part of the job service the feed ingestion hooks into.
+type JobRegistry interface {
+	Register(kind string, fn jobs.JobFunc)
+}
+
+// JobQueue queues feed ingestions.
+type JobQueue interface {
+	Enqueue(ctx context.Context, req types.EnqueueJobRequest, requestedBy *uuid.UUID) (*types.Job, error)
+}
+
+type ServiceImpl struct {
+	logger  *slog.Logger
+	repo    Repository
+	queue   JobQueue
+	client  *http.Client
+	feedDir string
+}
+
+// NewService creates the events service. file:// feeds are read only from
+// cfg.FeedDir, and are rejected when it is empty.
+func NewService(repo Repository, queue JobQueue, cfg config.EventsConfig, logger *slog.Logger) *ServiceImpl {
+	feedDir := cfg.FeedDir
+	if feedDir != "" {
+		if abs, err := filepath.Abs(feedDir); err == nil {
+			feedDir = abs
+		}
+	}
+	return &ServiceImpl{
+		logger:  logger,
+		repo:    repo,
+		queue:   queue,
+		client:  &http.Client{Timeout: downloadTimeout},
+		feedDir: feedDir,
+	}
+}
+
+// Register installs the feed ingestion job.
+func (s *ServiceImpl) Register(registry JobRegistry) {
+	registry.Register(types.JobKindEventFeedIngest, s.RunFeedIngest)
+}
+
+// Search implements Service.
+func (s *ServiceImpl) Search(ctx context.Context, params types.EventSearchParams) ([]types.Event, *types.Cursor, error) {
+	if !params.From.IsZero() && !params.To.IsZero() && !params.From.Before(params.To) {
+		return nil, nil, fmt.Errorf("%w: from must be before to", types.ErrBadRequest)
+	}
+	for _, c := range params.Categories {
+		if !types.IsEventCategory(c) {
+			return nil, nil, fmt.Errorf("%w: unknown category %q", types.ErrBadRequest, c)
+		}
+	}
+	if (params.Lat == nil) != (params.Lon == nil) {
+		return nil, nil, fmt.Errorf("%w: lat and lon must be given together", types.ErrBadRequest)
+	}
+	if params.Lat != nil {
+		if *params.Lat < -90 || *params.Lat > 90 || *params.Lon < -180 || *params.Lon > 180 {
+			return nil, nil, fmt.Errorf("%w: lat or lon out of range", types.ErrBadRequest)
+		}
+	}
+	if params.RadiusKm < 0 || (params.RadiusKm > 0 && params.Lat == nil) {
+		return nil, nil, fmt.Errorf("%w: radius needs lat and lon and must be positive", types.ErrBadRequest)
+	}
+	params.Page = params.Page.Clamp(defaultPageLimit, maxPageLimit)
+	return s.repo.SearchEvents(ctx, params)
+}
+
+// GetEvent implements Service.
+func (s *ServiceImpl) GetEvent(ctx context.Context, eventID uuid.UUID) (*types.Event, error) {
+	return s.repo.GetEvent(ctx, eventID)
+}
+
+// CreateEvent implements Service.
+func (s *ServiceImpl) CreateEvent(ctx context.Context, req types.CreateEventRequest) (*types.Event, error) {
+	ctx, span := otel.Tracer("EventsService").Start(ctx, "CreateEvent", trace.WithAttributes(
+		attribute.String("city.id", req.CityID.String()),
+	))
+	defer span.End()
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", types.ErrBadRequest)
+	}
+	if req.CityID == uuid.Nil {
+		return nil, fmt.Errorf("%w: city_id is required", types.ErrBadRequest)
+	}
+	if req.StartsAt.IsZero() {
+		return nil, fmt.Errorf("%w: starts_at is required", types.ErrBadRequest)
+	}
+	if req.EndsAt.IsZero() {
+		req.EndsAt = req.StartsAt
+	}
+	if req.EndsAt.Before(req.StartsAt) {
+		return nil, fmt.Errorf("%w: ends_at is before starts_at", types.ErrBadRequest)
+	}
+	if req.Category == "" {
+		req.Category = types.EventCategoryOther
+	}
+	if !types.IsEventCategory(req.Category) {
+		return nil, fmt.Errorf("%w: unknown category %q", types.ErrBadRequest, req.Category)
+	}
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		return nil, fmt.Errorf("%w: latitude and longitude must be given together", types.ErrBadRequest)
+	}
+
+	event, err := s.repo.CreateEvent(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to create event")
+		return nil, err
+	}
+	span.SetStatus(codes.Ok, "Event created")
+	return event, nil
+}
+
+// DeleteEvent implements Service.
+func (s *ServiceImpl) DeleteEvent(ctx context.Context, eventID uuid.UUID) error {
+	return s.repo.DeleteEvent(ctx, eventID)
+}
+
+// During implements Service.
+func (s *ServiceImpl) During(ctx context.Context, cityID uuid.UUID, from, to time.Time, interests []string) []types.Event {
+	ctx, span := otel.Tracer("EventsService").Start(ctx, "During", trace.WithAttributes(
+		attribute.String("city.id", cityID.String()),
+		attribute.String("from", from.Format(time.RFC3339)),
+		attribute.String("to", to.Format(time.RFC3339)),
+	))
+	defer span.End()
+
+	events, _, err := s.repo.SearchEvents(ctx, types.EventSearchParams{
+		CityID: &cityID,
+		From:   from,
+		To:     to,
+		Page:   types.PageRequest{Limit: itineraryCandidates},
+	})
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to look up events for itinerary",
+			slog.String("city_id", cityID.String()), slog.Any("error", err))
+		span.RecordError(err)
+		return nil
+	}
+
+	wanted := make(map[string]bool)
+	for _, interest := range interests {
+		if category := types.EventCategoryForInterest(interest); category != "" {
+			wanted[category] = true
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return wanted[events[i].Category] && !wanted[events[j].Category]
+	})
+	if len(events) > maxItineraryEvents {
+		events = events[:maxItineraryEvents]
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].StartsAt.Before(events[j].StartsAt) })
+
+	span.SetAttributes(attribute.Int("events.count", len(events)))
+	return events
+}
+
+// ListFeeds implements Service.
+func (s *ServiceImpl) ListFeeds(ctx context.Context) ([]types.EventFeed, error) {
+	return s.repo.ListFeeds(ctx)
+}
+
+// CreateFeed implements Service.
+func (s *ServiceImpl) CreateFeed(ctx context.Context, req types.CreateEventFeedRequest, requestedBy *uuid.UUID) (*types.EventFeed, error) {
+	ctx, span := otel.Tracer("EventsService").Start(ctx, "CreateFeed")
+	defer span.End()
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", types.ErrBadRequest)
+	}
+	if req.CityID == uuid.Nil {
+		return nil, fmt.Errorf("%w: city_id is required", types.ErrBadRequest)
+	}
+	if _, err := s.parseSourceURL(req.SourceURL); err != nil {
+		return nil, err
+	}
+	if req.DefaultCategory == "" {
+		req.DefaultCategory = types.EventCategoryOther
+	}
+	if !types.IsEventCategory(req.DefaultCategory) {
+		return nil, fmt.Errorf("%w: unknown default_category %q", types.ErrBadRequest, req.DefaultCategory)
+	}
+
+	feed, err := s.repo.CreateFeed(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to create feed")
+		return nil, err
+	}
+	job, err := s.IngestFeed(ctx, feed.ID, requestedBy)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to queue ingestion")
+		return nil, err
+	}
+	feed.IngestJobID = &job.ID
+	span.SetStatus(codes.Ok, "Feed created")
+	return feed, nil
+}
+
+// IngestFeed implements Service.
+func (s *ServiceImpl) IngestFeed(ctx context.Context, feedID uuid.UUID, requestedBy *uuid.UUID) (*types.Job, error) {
+	if _, err := s.repo.GetFeed(ctx, feedID); err != nil {
+		return nil, err
+	}
+	return s.queue.Enqueue(ctx, types.EnqueueJobRequest{Kind: types.JobKindEventFeedIngest, TargetID: &feedID}, requestedBy)
+}
+
+// RunFeedIngest reads the job's target feed and replaces its events.
+func (s *ServiceImpl) RunFeedIngest(ctx context.Context, job *types.Job, report jobs.ProgressFunc) error {
+	if job.TargetID == nil {
+		return fmt.Errorf("%w: a feed ingestion needs a target feed", types.ErrBadRequest)
+	}
+	ctx, span := otel.Tracer("EventsService").Start(ctx, "RunFeedIngest", trace.WithAttributes(
+		attribute.String("feed.id", job.TargetID.String()),
+	))
+	defer span.End()
+
+	feed, err := s.repo.GetFeed(ctx, *job.TargetID)
+	if err != nil {
+		return err
+	}
+	progress := types.JobProgress{Total: 2}
+	report(progress)
+
+	body, err := s.open(ctx, feed.SourceURL)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	defer body.Close()
+	events, err := parseICal(io.LimitReader(body, maxFeedBytes), feed.DefaultCategory)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	progress.Done++
+	report(progress)
+
+	if err := s.repo.ReplaceFeedEvents(ctx, feed, events); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	progress.Done++
+	report(progress)
+
+	s.logger.InfoContext(ctx, "Ingested event feed",
+		slog.String("feed_id", feed.ID.String()), slog.Int("events", len(events)))
+	span.SetStatus(codes.Ok, "Feed ingested")
+	return nil
+}
+
+// parseSourceURL accepts http(s) URLs and file:// paths inside the feed
+// directory.
+func (s *ServiceImpl) parseSourceURL(sourceURL string) (*url.URL, error) {
+	u, err := url.Parse(sourceURL)
+	if err == nil {
+		switch {
+		case (u.Scheme == "http" || u.Scheme == "https") && u.Host != "":
+			return u, nil
+		case u.Scheme == "file" && u.Path != "":
+			if s.feedDir == "" {
+				return nil, fmt.Errorf("%w: file:// feeds are disabled", types.ErrBadRequest)
+			}
+			if !insideDir(s.feedDir, filepath.Clean(u.Path)) {
+				return nil, fmt.Errorf("%w: file:// feeds must be inside the feed directory", types.ErrBadRequest)
+			}
+			return u, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: source_url must be an http(s) or file:// URL", types.ErrBadRequest)
+}
+
+// insideDir reports whether path lies below dir, both absolute and clean.
+func insideDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// open returns the feed at sourceURL, downloaded or read from the feed
+// directory.
+func (s *ServiceImpl) open(ctx context.Context, sourceURL string) (io.ReadCloser, error) {
+	u, err := s.parseSourceURL(sourceURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "file" {
+		// Resolve symlinks, so a link in the feed directory cannot lead out of it
+		dir, err := filepath.EvalSymlinks(s.feedDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve feed directory: %w", err)
+		}
+		path, err := filepath.EvalSymlinks(filepath.Clean(u.Path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open feed file: %w", err)
+		}
+		if !insideDir(dir, path) {
+			return nil, fmt.Errorf("%w: file:// feeds must be inside the feed directory", types.ErrBadRequest)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open feed file: %w", err)
+		}
+		return f, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid feed URL: %v", types.ErrBadRequest, err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download feed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("feed download returned status %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
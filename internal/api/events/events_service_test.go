@@ -0,0 +1,477 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/config"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+// MockEventsRepository is a mock implementation of Repository
+type MockEventsRepository struct {
+	mock.Mock
+}
+
+func (m *MockEventsRepository) CreateEvent(ctx context.Context, req types.CreateEventRequest) (*types.Event, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Event), args.Error(1)
+}
+
+func (m *MockEventsRepository) GetEvent(ctx context.Context, eventID uuid.UUID) (*types.Event, error) {
+	args := m.Called(ctx, eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Event), args.Error(1)
+}
+
+func (m *MockEventsRepository) DeleteEvent(ctx context.Context, eventID uuid.UUID) error {
+	return m.Called(ctx, eventID).Error(0)
+}
+
+func (m *MockEventsRepository) SearchEvents(ctx context.Context, params types.EventSearchParams) ([]types.Event, *types.Cursor, error) {
+	args := m.Called(ctx, params)
+	var events []types.Event
+	if args.Get(0) != nil {
+		events = args.Get(0).([]types.Event)
+	}
+	var next *types.Cursor
+	if args.Get(1) != nil {
+		next = args.Get(1).(*types.Cursor)
+	}
+	return events, next, args.Error(2)
+}
+
+func (m *MockEventsRepository) CreateFeed(ctx context.Context, req types.CreateEventFeedRequest) (*types.EventFeed, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.EventFeed), args.Error(1)
+}
+
+func (m *MockEventsRepository) GetFeed(ctx context.Context, feedID uuid.UUID) (*types.EventFeed, error) {
+	args := m.Called(ctx, feedID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.EventFeed), args.Error(1)
+}
+
+func (m *MockEventsRepository) ListFeeds(ctx context.Context) ([]types.EventFeed, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.EventFeed), args.Error(1)
+}
+
+func (m *MockEventsRepository) ReplaceFeedEvents(ctx context.Context, feed *types.EventFeed, events []types.Event) error {
+	return m.Called(ctx, feed, events).Error(0)
+}
+
+// MockJobQueue is a mock implementation of JobQueue
+type MockJobQueue struct {
+	mock.Mock
+}
+
+func (m *MockJobQueue) Enqueue(ctx context.Context, req types.EnqueueJobRequest, requestedBy *uuid.UUID) (*types.Job, error) {
+	args := m.Called(ctx, req, requestedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Job), args.Error(1)
+}
+
+func setupEventsServiceTest() (*ServiceImpl, *MockEventsRepository, *MockJobQueue) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	repo := new(MockEventsRepository)
+	queue := new(MockJobQueue)
+	return NewService(repo, queue, config.EventsConfig{FeedDir: "/srv/feeds"}, logger), repo, queue
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func TestService_Search(t *testing.T) {
+	ctx := context.Background()
+	from := time.Date(2026, 6, 12, 0, 0, 0, 0, time.UTC)
+
+	t.Run("validates the filter", func(t *testing.T) {
+		service, repo, _ := setupEventsServiceTest()
+		for _, params := range []types.EventSearchParams{
+			{From: from, To: from},
+			{Categories: []string{"concert", "rave"}},
+			{Lat: ptr(38.7)},
+			{Lat: ptr(98.0), Lon: ptr(-9.1)},
+			{RadiusKm: 2},
+			{Lat: ptr(38.7), Lon: ptr(-9.1), RadiusKm: -1},
+		} {
+			_, _, err := service.Search(ctx, params)
+			assert.ErrorIs(t, err, types.ErrBadRequest, "%+v", params)
+		}
+		repo.AssertNotCalled(t, "SearchEvents", mock.Anything, mock.Anything)
+	})
+
+	t.Run("clamps the page size", func(t *testing.T) {
+		service, repo, _ := setupEventsServiceTest()
+		params := types.EventSearchParams{
+			From:       from,
+			To:         from.AddDate(0, 0, 3),
+			Categories: []string{types.EventCategoryFestival},
+			Lat:        ptr(38.71),
+			Lon:        ptr(-9.14),
+			RadiusKm:   5,
+			Page:       types.PageRequest{Limit: 1000},
+		}
+		want := params
+		want.Page.Limit = maxPageLimit
+		repo.On("SearchEvents", mock.Anything, want).Return([]types.Event{{Name: "Santo António"}}, nil, nil)
+
+		events, next, err := service.Search(ctx, params)
+
+		require.NoError(t, err)
+		assert.Len(t, events, 1)
+		assert.Nil(t, next)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestService_CreateEvent(t *testing.T) {
+	ctx := context.Background()
+	cityID := uuid.New()
+	starts := time.Date(2026, 6, 12, 20, 0, 0, 0, time.UTC)
+
+	t.Run("validates the request", func(t *testing.T) {
+		service, repo, _ := setupEventsServiceTest()
+		for _, req := range []types.CreateEventRequest{
+			{CityID: cityID, Name: " ", StartsAt: starts},
+			{Name: "Fado night", StartsAt: starts},
+			{CityID: cityID, Name: "Fado night"},
+			{CityID: cityID, Name: "Fado night", StartsAt: starts, EndsAt: starts.Add(-time.Hour)},
+			{CityID: cityID, Name: "Fado night", StartsAt: starts, Category: "rave"},
+			{CityID: cityID, Name: "Fado night", StartsAt: starts, Latitude: ptr(38.7)},
+		} {
+			_, err := service.CreateEvent(ctx, req)
+			assert.ErrorIs(t, err, types.ErrBadRequest, "%+v", req)
+		}
+		repo.AssertNotCalled(t, "CreateEvent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("defaults the end and category", func(t *testing.T) {
+		service, repo, _ := setupEventsServiceTest()
+		want := types.CreateEventRequest{CityID: cityID, Name: "Fado night", StartsAt: starts, EndsAt: starts, Category: types.EventCategoryOther}
+		repo.On("CreateEvent", mock.Anything, want).Return(&types.Event{ID: uuid.New(), Name: "Fado night"}, nil)
+
+		event, err := service.CreateEvent(ctx, types.CreateEventRequest{CityID: cityID, Name: " Fado night ", StartsAt: starts})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Fado night", event.Name)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestService_During(t *testing.T) {
+	ctx := context.Background()
+	cityID := uuid.New()
+	from := time.Date(2026, 6, 12, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 2)
+	search := types.EventSearchParams{CityID: &cityID, From: from, To: to, Page: types.PageRequest{Limit: itineraryCandidates}}
+
+	t.Run("keeps interest matches when there are too many", func(t *testing.T) {
+		service, repo, _ := setupEventsServiceTest()
+		var found []types.Event
+		for i := 0; i < maxItineraryEvents+2; i++ {
+			found = append(found, types.Event{Name: "Exhibition", Category: types.EventCategoryExhibition, StartsAt: from.Add(time.Duration(i) * time.Hour)})
+		}
+		found = append(found,
+			types.Event{Name: "Santo António", Category: types.EventCategoryFestival, StartsAt: from.Add(30 * time.Hour)},
+			types.Event{Name: "Benfica v Porto", Category: types.EventCategorySports, StartsAt: from.Add(40 * time.Hour)},
+		)
+		repo.On("SearchEvents", mock.Anything, search).Return(found, nil, nil)
+
+		events := service.During(ctx, cityID, from, to, []string{"festivals", "sports"})
+
+		require.Len(t, events, maxItineraryEvents)
+		assert.Equal(t, "Santo António", events[len(events)-2].Name)
+		assert.Equal(t, "Benfica v Porto", events[len(events)-1].Name)
+		for i := 1; i < len(events); i++ {
+			assert.False(t, events[i].StartsAt.Before(events[i-1].StartsAt), "events are in start order")
+		}
+	})
+
+	t.Run("returns nothing when the search fails", func(t *testing.T) {
+		service, repo, _ := setupEventsServiceTest()
+		repo.On("SearchEvents", mock.Anything, search).Return(nil, nil, assert.AnError)
+
+		assert.Empty(t, service.During(ctx, cityID, from, to, nil))
+	})
+}
+
+func TestService_CreateFeed(t *testing.T) {
+	ctx := context.Background()
+	adminID := uuid.New()
+	cityID := uuid.New()
+
+	t.Run("validates the request", func(t *testing.T) {
+		service, repo, _ := setupEventsServiceTest()
+		for _, req := range []types.CreateEventFeedRequest{
+			{Name: " ", SourceURL: "https://example.com/events.ics", CityID: cityID},
+			{Name: "Lisboa", SourceURL: "https://example.com/events.ics"},
+			{Name: "Lisboa", SourceURL: "ftp://example.com/events.ics", CityID: cityID},
+			{Name: "Lisboa", SourceURL: "file://", CityID: cityID},
+			{Name: "Lisboa", SourceURL: "file:///etc/passwd", CityID: cityID},
+			{Name: "Lisboa", SourceURL: "file:///srv/feeds/../../etc/passwd", CityID: cityID},
+			{Name: "Lisboa", SourceURL: "https://example.com/events.ics", CityID: cityID, DefaultCategory: "rave"},
+		} {
+			_, err := service.CreateFeed(ctx, req, &adminID)
+			assert.ErrorIs(t, err, types.ErrBadRequest, "%+v", req)
+		}
+		repo.AssertNotCalled(t, "CreateFeed", mock.Anything, mock.Anything)
+	})
+
+	t.Run("queues the first ingestion", func(t *testing.T) {
+		service, repo, queue := setupEventsServiceTest()
+		req := types.CreateEventFeedRequest{Name: "Lisboa", SourceURL: "file:///srv/feeds/lisboa.ics", CityID: cityID}
+		feed := &types.EventFeed{ID: uuid.New(), Name: req.Name, SourceURL: req.SourceURL, CityID: cityID}
+		job := &types.Job{ID: uuid.New(), Kind: types.JobKindEventFeedIngest}
+		want := req
+		want.DefaultCategory = types.EventCategoryOther
+		repo.On("CreateFeed", mock.Anything, want).Return(feed, nil)
+		repo.On("GetFeed", mock.Anything, feed.ID).Return(feed, nil)
+		queue.On("Enqueue", mock.Anything, types.EnqueueJobRequest{Kind: types.JobKindEventFeedIngest, TargetID: &feed.ID}, &adminID).Return(job, nil)
+
+		created, err := service.CreateFeed(ctx, req, &adminID)
+
+		require.NoError(t, err)
+		require.NotNil(t, created.IngestJobID)
+		assert.Equal(t, job.ID, *created.IngestJobID)
+		repo.AssertExpectations(t)
+		queue.AssertExpectations(t)
+	})
+}
+
+func TestService_IngestFeed_NotFound(t *testing.T) {
+	service, repo, queue := setupEventsServiceTest()
+	feedID := uuid.New()
+	repo.On("GetFeed", mock.Anything, feedID).Return(nil, types.ErrNotFound)
+
+	_, err := service.IngestFeed(context.Background(), feedID, nil)
+
+	assert.ErrorIs(t, err, types.ErrNotFound)
+	queue.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything, mock.Anything)
+}
+
+const sampleCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"X-WR-TIMEZONE:Europe/Lisbon\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:santos-2026@example.com\r\n" +
+	"SUMMARY:Santos Populares\r\n" +
+	"DESCRIPTION:Grilled sardines\\, music and\\nmarchas in Alfama\r\n" +
+	"CATEGORIES:Festivals,Music\r\n" +
+	"DTSTART;VALUE=DATE:20260612\r\n" +
+	"DTEND;VALUE=DATE:20260614\r\n" +
+	"LOCATION:Alfama\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:fado@example.com\r\n" +
+	"SUMMARY:Fado at the \r\n" +
+	" Castle\r\n" +
+	"CATEGORIES:Live Music\r\n" +
+	"DTSTART;TZID=\"Europe/Lisbon\":20260612T213000\r\n" +
+	"DURATION:PT1H30M\r\n" +
+	"GEO:38.7139;-9.1334\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"DESCRIPTION:Leave for the castle\r\n" +
+	"TRIGGER:-PT30M\r\n" +
+	"END:VALARM\r\n" +
+	"RRULE:FREQ=WEEKLY\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:fado@example.com\r\n" +
+	"RECURRENCE-ID;TZID=Europe/Lisbon:20260619T213000\r\n" +
+	"SUMMARY:Fado at the Castle (moved)\r\n" +
+	"DTSTART;TZID=Europe/Lisbon:20260619T220000\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:cancelled@example.com\r\n" +
+	"SUMMARY:Cancelled run\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"DTSTART:20260613T080000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Market day\r\n" +
+	"DTSTART:20260613T090000Z\r\n" +
+	"DTEND:20260613T140000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseICal(t *testing.T) {
+	lisbon, err := time.LoadLocation("Europe/Lisbon")
+	require.NoError(t, err)
+
+	events, err := parseICal(strings.NewReader("\ufeff"+sampleCalendar), types.EventCategoryMarket)
+
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	santos := events[0]
+	assert.Equal(t, "santos-2026@example.com", santos.ExternalUID)
+	assert.Equal(t, "Santos Populares", santos.Name)
+	assert.Equal(t, "Grilled sardines, music and\nmarchas in Alfama", santos.Description)
+	assert.Equal(t, types.EventCategoryFestival, santos.Category)
+	assert.True(t, santos.AllDay)
+	assert.True(t, santos.StartsAt.Equal(time.Date(2026, 6, 12, 0, 0, 0, 0, lisbon)))
+	assert.True(t, santos.EndsAt.Equal(time.Date(2026, 6, 14, 0, 0, 0, 0, lisbon)))
+	assert.Equal(t, "Alfama", santos.Venue)
+
+	fado := events[1]
+	assert.Equal(t, "Fado at the Castle", fado.Name)
+	assert.Equal(t, types.EventCategoryConcert, fado.Category)
+	assert.Empty(t, fado.Description, "the VALARM's description is not the event's")
+	assert.False(t, fado.AllDay)
+	assert.True(t, fado.StartsAt.Equal(time.Date(2026, 6, 12, 20, 30, 0, 0, time.UTC)))
+	assert.Equal(t, 90*time.Minute, fado.EndsAt.Sub(fado.StartsAt))
+	require.NotNil(t, fado.Latitude)
+	assert.Equal(t, 38.7139, *fado.Latitude)
+	assert.Equal(t, -9.1334, *fado.Longitude)
+
+	market := events[2]
+	assert.Equal(t, types.EventCategoryMarket, market.Category, "falls back to the feed's category")
+	assert.True(t, strings.HasPrefix(market.ExternalUID, "generated-"))
+	assert.True(t, market.EndsAt.Equal(time.Date(2026, 6, 13, 14, 0, 0, 0, time.UTC)))
+
+	again, err := parseICal(strings.NewReader(sampleCalendar), types.EventCategoryMarket)
+	require.NoError(t, err)
+	assert.Equal(t, market.ExternalUID, again[2].ExternalUID, "generated UIDs are stable")
+}
+
+func TestParseICal_NotACalendar(t *testing.T) {
+	_, err := parseICal(strings.NewReader("<html>Not found</html>"), types.EventCategoryOther)
+	assert.ErrorIs(t, err, types.ErrBadRequest)
+}
+
+func TestParseICalDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"PT1H30M", 90 * time.Minute, true},
+		{"P1D", 24 * time.Hour, true},
+		{"P1W", 7 * 24 * time.Hour, true},
+		{"P1DT2H", 26 * time.Hour, true},
+		{"PT45S", 45 * time.Second, true},
+		{"1H", 0, false},
+		{"P1H", 0, false},
+		{"PT5", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseICalDuration(tt.value)
+		assert.Equal(t, tt.ok, ok, tt.value)
+		if tt.ok {
+			assert.Equal(t, tt.want, got, tt.value)
+		}
+	}
+}
+
+func TestEventOverlaps(t *testing.T) {
+	day := time.Date(2026, 6, 12, 0, 0, 0, 0, time.UTC)
+	next := day.AddDate(0, 0, 1)
+
+	assert.True(t, types.Event{StartsAt: day.Add(-time.Hour), EndsAt: day.Add(time.Hour)}.Overlaps(day, next))
+	assert.True(t, types.Event{StartsAt: day, EndsAt: day}.Overlaps(day, next), "instants at the start count")
+	assert.False(t, types.Event{StartsAt: day.AddDate(0, 0, -1), EndsAt: day}.Overlaps(day, next), "ends are exclusive")
+	assert.False(t, types.Event{StartsAt: next, EndsAt: next.Add(time.Hour)}.Overlaps(day, next))
+}
+
+func TestService_RunFeedIngest(t *testing.T) {
+	t.Run("downloads the feed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(sampleCalendar))
+		}))
+		defer server.Close()
+
+		service, repo, _ := setupEventsServiceTest()
+		feed := &types.EventFeed{ID: uuid.New(), SourceURL: server.URL + "/events.ics", DefaultCategory: types.EventCategoryOther}
+		repo.On("GetFeed", mock.Anything, feed.ID).Return(feed, nil)
+		repo.On("ReplaceFeedEvents", mock.Anything, feed,
+			mock.MatchedBy(func(events []types.Event) bool { return len(events) == 3 }),
+		).Return(nil)
+
+		var reports []types.JobProgress
+		err := service.RunFeedIngest(context.Background(), &types.Job{ID: uuid.New(), TargetID: &feed.ID}, func(p types.JobProgress) {
+			reports = append(reports, p)
+		})
+
+		require.NoError(t, err)
+		require.NotEmpty(t, reports)
+		assert.Equal(t, types.JobProgress{Total: 2, Done: 2}, reports[len(reports)-1])
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("reads local files", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "lisboa.ics")
+		require.NoError(t, os.WriteFile(path, []byte(sampleCalendar), 0o600))
+
+		service, repo, _ := setupEventsServiceTest()
+		service.feedDir = filepath.Dir(path)
+		feed := &types.EventFeed{ID: uuid.New(), SourceURL: "file://" + path, DefaultCategory: types.EventCategoryOther}
+		repo.On("GetFeed", mock.Anything, feed.ID).Return(feed, nil)
+		repo.On("ReplaceFeedEvents", mock.Anything, feed, mock.Anything).Return(nil)
+
+		err := service.RunFeedIngest(context.Background(), &types.Job{TargetID: &feed.ID}, func(types.JobProgress) {})
+
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("refuses links out of the feed directory", func(t *testing.T) {
+		dir := t.TempDir()
+		outside := filepath.Join(t.TempDir(), "secret.ics")
+		require.NoError(t, os.WriteFile(outside, []byte(sampleCalendar), 0o600))
+		link := filepath.Join(dir, "lisboa.ics")
+		require.NoError(t, os.Symlink(outside, link))
+
+		service, repo, _ := setupEventsServiceTest()
+		service.feedDir = dir
+		feed := &types.EventFeed{ID: uuid.New(), SourceURL: "file://" + link}
+		repo.On("GetFeed", mock.Anything, feed.ID).Return(feed, nil)
+
+		err := service.RunFeedIngest(context.Background(), &types.Job{TargetID: &feed.ID}, func(types.JobProgress) {})
+
+		assert.ErrorIs(t, err, types.ErrBadRequest)
+		repo.AssertNotCalled(t, "ReplaceFeedEvents", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("fails when the download does", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+
+		service, repo, _ := setupEventsServiceTest()
+		feed := &types.EventFeed{ID: uuid.New(), SourceURL: server.URL}
+		repo.On("GetFeed", mock.Anything, feed.ID).Return(feed, nil)
+
+		err := service.RunFeedIngest(context.Background(), &types.Job{TargetID: &feed.ID}, func(types.JobProgress) {})
+
+		assert.Error(t, err)
+		repo.AssertNotCalled(t, "ReplaceFeedEvents", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
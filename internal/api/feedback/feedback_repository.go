@@ -0,0 +1,351 @@
+package feedback
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/embeddings"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Repository = (*RepositoryImpl)(nil)
+
+const (
+	// maxAffinity bounds an affinity score so a burst of events on one category
+	// cannot drown out everything else.
+	maxAffinity = 5.0
+	// minAffinity is the score below which an affinity is forgotten.
+	minAffinity = 0.05
+	// learnedThreshold is the score an affinity needs to show up in prompts.
+	learnedThreshold = 0.5
+	// learnedLimit caps each list in LearnedPreferences.
+	learnedLimit = 5
+	// removedWindow is how far back removed POIs are remembered for prompts.
+	removedWindow = 90 * 24 * time.Hour
+)
+
+// Repository stores interaction events and the affinities learned from them.
+type Repository interface {
+	// Record stores events and queues the learner for their users.
+	Record(ctx context.Context, events ...types.InteractionEvent) error
+
+	// PendingUsers pages by ID through users with events not learned yet.
+	PendingUsers(ctx context.Context, after uuid.UUID, limit int) ([]uuid.UUID, error)
+	// UnlearnedEvents returns the oldest events of a user not learned yet.
+	UnlearnedEvents(ctx context.Context, userID uuid.UUID, limit int) ([]types.InteractionEvent, error)
+	// Learn adds deltas to the user's affinities, decaying the current scores,
+	// and marks the events learned, in one transaction. It fails with
+	// types.ErrConflict when another learner already took any of the events.
+	Learn(ctx context.Context, userID uuid.UUID, deltas []types.Affinity, eventIDs []uuid.UUID) error
+
+	// Affinities returns the user's affinities, strongest first.
+	Affinities(ctx context.Context, userID uuid.UUID) ([]types.Affinity, error)
+	// LearnedPreferences summarises the user's strongest affinities and the POIs
+	// they recently removed.
+	LearnedPreferences(ctx context.Context, userID uuid.UUID) (*types.LearnedPreferences, error)
+}
+
+type RepositoryImpl struct {
+	logger *slog.Logger
+	pgpool *pgxpool.Pool
+}
+
+func NewRepository(pgxpool *pgxpool.Pool, logger *slog.Logger) *RepositoryImpl {
+	return &RepositoryImpl{
+		logger: logger,
+		pgpool: pgxpool,
+	}
+}
+
+// recordQuery stores an event, filling in the POI's name, category and tags
+// when the caller left them empty, and queues the learner for the user. The POI
+// ID is only kept when the POI exists, since LLM suggestions carry made-up IDs.
+const recordQuery = `
+	WITH poi AS (
+		SELECT id, name, COALESCE(category, poi_type) AS category, tags
+		FROM points_of_interest
+		WHERE id = $2
+	), event AS (
+		INSERT INTO user_interaction_events (user_id, poi_id, poi_name, category, tags, kind, weight)
+		SELECT $1,
+		       (SELECT id FROM poi),
+		       COALESCE(NULLIF($3, ''), (SELECT name FROM poi), ''),
+		       COALESCE(NULLIF($4, ''), (SELECT category FROM poi)),
+		       COALESCE(NULLIF($5::text[], '{}'), (SELECT tags FROM poi), '{}'),
+		       $6, $7
+		RETURNING user_id
+	)
+	INSERT INTO background_jobs (kind, target_id)
+	SELECT $8, user_id FROM event
+	ON CONFLICT (kind, target_id) WHERE status IN ('pending', 'running') DO NOTHING`
+
+// RecordTx stores an interaction event inside the caller's transaction, so it
+// only counts if the change it describes was committed. The weight defaults to
+// the one for the event kind.
+func RecordTx(ctx context.Context, db embeddings.Execer, event types.InteractionEvent) error {
+	weight := event.Weight
+	if weight == 0 {
+		w, ok := types.InteractionWeights[event.Kind]
+		if !ok {
+			return fmt.Errorf("unknown interaction kind %q: %w", event.Kind, types.ErrBadRequest)
+		}
+		weight = w
+	}
+	if event.UserID == uuid.Nil {
+		return fmt.Errorf("interaction event without user: %w", types.ErrBadRequest)
+	}
+
+	_, err := db.Exec(ctx, recordQuery, event.UserID, event.POIID, event.POIName, event.Category, event.Tags,
+		event.Kind, weight, types.JobKindInteractionLearning)
+	if err != nil {
+		return fmt.Errorf("failed to record %s event: %w", event.Kind, err)
+	}
+	return nil
+}
+
+// Record implements Repository.
+func (r *RepositoryImpl) Record(ctx context.Context, events ...types.InteractionEvent) error {
+	ctx, span := otel.Tracer("FeedbackRepo").Start(ctx, "Record", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.operation", "INSERT"),
+		attribute.String("db.sql.table", "user_interaction_events"),
+		attribute.Int("events.count", len(events)),
+	))
+	defer span.End()
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	tx, err := r.pgpool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("database error beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, event := range events {
+		if err := RecordTx(ctx, tx, event); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "DB insert failed")
+			return err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("database error committing interaction events: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Interaction events recorded")
+	return nil
+}
+
+// PendingUsers implements Repository.
+func (r *RepositoryImpl) PendingUsers(ctx context.Context, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	query := `
+		SELECT DISTINCT user_id FROM user_interaction_events
+		WHERE learned_at IS NULL AND user_id > $1
+		ORDER BY user_id
+		LIMIT $2`
+	rows, err := r.pgpool.Query(ctx, query, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching users with new interaction events: %w", err)
+	}
+	users, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan user row: %w", err)
+	}
+	return users, nil
+}
+
+// UnlearnedEvents implements Repository.
+func (r *RepositoryImpl) UnlearnedEvents(ctx context.Context, userID uuid.UUID, limit int) ([]types.InteractionEvent, error) {
+	ctx, span := otel.Tracer("FeedbackRepo").Start(ctx, "UnlearnedEvents", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "user_interaction_events"),
+		attribute.String("db.user.id", userID.String()),
+	))
+	defer span.End()
+
+	query := `
+		SELECT id, user_id, poi_id, poi_name, COALESCE(category, ''), tags, kind, weight, created_at
+		FROM user_interaction_events
+		WHERE user_id = $1 AND learned_at IS NULL
+		ORDER BY created_at, id
+		LIMIT $2`
+	rows, err := r.pgpool.Query(ctx, query, userID, limit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, fmt.Errorf("database error fetching interaction events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []types.InteractionEvent
+	for rows.Next() {
+		var e types.InteractionEvent
+		if err := rows.Scan(&e.ID, &e.UserID, &e.POIID, &e.POIName, &e.Category, &e.Tags, &e.Kind, &e.Weight, &e.CreatedAt); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan interaction event: %w", err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("error iterating interaction events: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("results.count", len(events)))
+	span.SetStatus(codes.Ok, "Interaction events retrieved")
+	return events, nil
+}
+
+// Learn implements Repository. Claiming the events first serialises learners
+// working on the same user, so every event is counted once.
+func (r *RepositoryImpl) Learn(ctx context.Context, userID uuid.UUID, deltas []types.Affinity, eventIDs []uuid.UUID) error {
+	ctx, span := otel.Tracer("FeedbackRepo").Start(ctx, "Learn", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.operation", "UPDATE"),
+		attribute.String("db.sql.table", "user_affinities"),
+		attribute.String("db.user.id", userID.String()),
+		attribute.Int("deltas.count", len(deltas)),
+		attribute.Int("events.count", len(eventIDs)),
+	))
+	defer span.End()
+
+	tx, err := r.pgpool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("database error beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE user_interaction_events SET learned_at = NOW()
+		WHERE user_id = $1 AND id = ANY($2) AND learned_at IS NULL`, userID, eventIDs)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB update failed")
+		return fmt.Errorf("database error marking interaction events learned: %w", err)
+	}
+	if int(tag.RowsAffected()) != len(eventIDs) {
+		span.SetStatus(codes.Error, "Events already learned")
+		return fmt.Errorf("interaction events of user %s were learned concurrently: %w", userID, types.ErrConflict)
+	}
+
+	if len(deltas) > 0 {
+		dimensions := make([]string, len(deltas))
+		values := make([]string, len(deltas))
+		scores := make([]float64, len(deltas))
+		for i, d := range deltas {
+			dimensions[i], values[i], scores[i] = d.Dimension, d.Value, d.Score
+		}
+		// Current scores decay with the same half-life as the events before the
+		// deltas are added
+		query := `
+			INSERT INTO user_affinities (user_id, dimension, value, score)
+			SELECT $1, d.dimension, d.value, GREATEST(-$5, LEAST($5, d.delta))
+			FROM unnest($2::text[], $3::text[], $4::float8[]) AS d(dimension, value, delta)
+			ON CONFLICT (user_id, dimension, value) DO UPDATE
+			SET score = GREATEST(-$5, LEAST($5,
+			        user_affinities.score * POWER(0.5, EXTRACT(EPOCH FROM NOW() - user_affinities.updated_at) / $6)
+			        + EXCLUDED.score)),
+			    updated_at = NOW()`
+		if _, err := tx.Exec(ctx, query, userID, dimensions, values, scores, maxAffinity, affinityHalfLife.Seconds()); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "DB upsert failed")
+			return fmt.Errorf("database error updating affinities: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM user_affinities WHERE user_id = $1 AND ABS(score) < $2`, userID, minAffinity); err != nil {
+			span.RecordError(err)
+			return fmt.Errorf("database error pruning affinities: %w", err)
+		}
+
+		// Learned likes and dislikes are part of every taste vector of the user
+		if err := embeddings.RefreshUserPreferences(ctx, tx, userID); err != nil {
+			span.RecordError(err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("database error committing affinities: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Affinities learned")
+	return nil
+}
+
+// Affinities implements Repository.
+func (r *RepositoryImpl) Affinities(ctx context.Context, userID uuid.UUID) ([]types.Affinity, error) {
+	query := `
+		SELECT dimension, value, score, updated_at
+		FROM user_affinities
+		WHERE user_id = $1
+		ORDER BY ABS(score) DESC, dimension, value`
+	rows, err := r.pgpool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching affinities: %w", err)
+	}
+	defer rows.Close()
+
+	var affinities []types.Affinity
+	for rows.Next() {
+		var a types.Affinity
+		if err := rows.Scan(&a.Dimension, &a.Value, &a.Score, &a.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan affinity: %w", err)
+		}
+		affinities = append(affinities, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating affinities: %w", err)
+	}
+	return affinities, nil
+}
+
+// LearnedPreferences implements Repository.
+func (r *RepositoryImpl) LearnedPreferences(ctx context.Context, userID uuid.UUID) (*types.LearnedPreferences, error) {
+	ctx, span := otel.Tracer("FeedbackRepo").Start(ctx, "LearnedPreferences", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.user.id", userID.String()),
+	))
+	defer span.End()
+
+	query := `
+		SELECT
+			ARRAY(SELECT value FROM user_affinities
+			      WHERE user_id = $1 AND score >= $2
+			      ORDER BY score DESC, value LIMIT $3),
+			ARRAY(SELECT value FROM user_affinities
+			      WHERE user_id = $1 AND score <= -$2
+			      ORDER BY score, value LIMIT $3),
+			ARRAY(SELECT MIN(poi_name) FROM user_interaction_events
+			      WHERE user_id = $1 AND poi_name <> '' AND created_at > NOW() - make_interval(secs => $4)
+			      GROUP BY LOWER(poi_name)
+			      HAVING SUM(weight) < 0
+			      ORDER BY MAX(created_at) DESC LIMIT $3)`
+	var learned types.LearnedPreferences
+	err := r.pgpool.QueryRow(ctx, query, userID, learnedThreshold, learnedLimit, removedWindow.Seconds()).
+		Scan(&learned.Favoured, &learned.Avoided, &learned.RemovedPOIs)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, fmt.Errorf("database error fetching learned preferences: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Learned preferences retrieved")
+	return &learned, nil
+}
@@ -0,0 +1,195 @@
+package feedback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/jobs"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+const (
+	// affinityHalfLife is how long it takes an event, and a learned score, to
+	// lose half its weight, so tastes can change.
+	affinityHalfLife = 60 * 24 * time.Hour
+	// tagShare is the part of an event's weight that goes to each of the POI's
+	// tags; categories say more about a POI than its tags.
+	tagShare = 0.5
+	// sweepPageSize is how many users a sweep loads per query.
+	sweepPageSize = 50
+	// eventBatchSize is how many events of a user are learned per transaction.
+	eventBatchSize = 200
+)
+
+// JobRegistry is the part of the job service the learner hooks into.
+type JobRegistry interface {
+	Register(kind string, fn jobs.JobFunc)
+	Schedule(kind string, every time.Duration)
+}
+
+// Service learns per-user category and tag affinities from interaction events.
+type Service struct {
+	logger *slog.Logger
+	repo   Repository
+	now    func() time.Time
+}
+
+// NewService creates the feedback learner.
+func NewService(repo Repository, logger *slog.Logger) *Service {
+	return &Service{
+		logger: logger,
+		repo:   repo,
+		now:    time.Now,
+	}
+}
+
+// Register installs the learning job and, when sweepInterval is positive, a
+// periodic sweep for events whose job was lost.
+func (s *Service) Register(registry JobRegistry, sweepInterval time.Duration) {
+	registry.Register(types.JobKindInteractionLearning, s.RunInteractionLearning)
+	if sweepInterval > 0 {
+		registry.Schedule(types.JobKindInteractionLearning, sweepInterval)
+	}
+}
+
+// RunInteractionLearning learns the new events of the job's target user, or of
+// every user with new events.
+func (s *Service) RunInteractionLearning(ctx context.Context, job *types.Job, report jobs.ProgressFunc) error {
+	ctx, span := otel.Tracer("FeedbackService").Start(ctx, "RunInteractionLearning", trace.WithAttributes(
+		attribute.Bool("job.sweep", job.TargetID == nil),
+	))
+	defer span.End()
+
+	if job.TargetID != nil {
+		if err := s.learn(ctx, *job.TargetID); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Learning failed")
+			return err
+		}
+		report(types.JobProgress{Total: 1, Done: 1})
+		span.SetStatus(codes.Ok, "Events learned")
+		return nil
+	}
+
+	var progress types.JobProgress
+	after := uuid.Nil
+	for {
+		page, err := s.repo.PendingUsers(ctx, after, sweepPageSize)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Page fetch failed")
+			return err
+		}
+		if len(page) == 0 {
+			break
+		}
+		progress.Total += len(page)
+		for _, userID := range page {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := s.learn(ctx, userID); err != nil {
+				progress.Failed++
+				s.logger.WarnContext(ctx, "Failed to learn interaction events",
+					slog.String("user_id", userID.String()),
+					slog.Any("error", err))
+			} else {
+				progress.Done++
+			}
+			report(progress)
+		}
+		after = page[len(page)-1]
+	}
+
+	span.SetAttributes(attribute.Int("users.done", progress.Done), attribute.Int("users.failed", progress.Failed))
+	if progress.Failed > 0 {
+		span.SetStatus(codes.Error, "Some users failed")
+		return fmt.Errorf("learning failed for %d of %d users", progress.Failed, progress.Total)
+	}
+	span.SetStatus(codes.Ok, "Sweep completed")
+	return nil
+}
+
+// learn folds every unlearned event of a user into their affinities, a batch
+// at a time. A batch another learner got to first is not an error.
+func (s *Service) learn(ctx context.Context, userID uuid.UUID) error {
+	for {
+		events, err := s.repo.UnlearnedEvents(ctx, userID, eventBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, len(events))
+		for i, e := range events {
+			ids[i] = e.ID
+		}
+		err = s.repo.Learn(ctx, userID, AffinityDeltas(events, s.now()), ids)
+		if errors.Is(err, types.ErrConflict) {
+			s.logger.InfoContext(ctx, "Interaction events learned concurrently", slog.String("user_id", userID.String()))
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(events) < eventBatchSize {
+			return nil
+		}
+	}
+}
+
+// AffinityDeltas sums what events add to each category and tag affinity. An
+// event's weight halves every affinityHalfLife since it happened, and each tag
+// gets tagShare of it. Values are lower-cased; the result is sorted.
+func AffinityDeltas(events []types.InteractionEvent, now time.Time) []types.Affinity {
+	type key struct{ dimension, value string }
+	sums := make(map[key]float64)
+	add := func(dimension, value string, delta float64) {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			return
+		}
+		sums[key{dimension, value}] += delta
+	}
+
+	for _, e := range events {
+		age := now.Sub(e.CreatedAt)
+		if age < 0 {
+			age = 0
+		}
+		weight := e.Weight * math.Pow(0.5, age.Hours()/affinityHalfLife.Hours())
+		add(types.AffinityCategory, e.Category, weight)
+		for _, tag := range e.Tags {
+			add(types.AffinityTag, tag, weight*tagShare)
+		}
+	}
+
+	deltas := make([]types.Affinity, 0, len(sums))
+	for k, score := range sums {
+		if score == 0 {
+			continue
+		}
+		deltas = append(deltas, types.Affinity{Dimension: k.dimension, Value: k.value, Score: score, UpdatedAt: now})
+	}
+	sort.Slice(deltas, func(i, j int) bool {
+		if deltas[i].Dimension != deltas[j].Dimension {
+			return deltas[i].Dimension < deltas[j].Dimension
+		}
+		return deltas[i].Value < deltas[j].Value
+	})
+	return deltas
+}
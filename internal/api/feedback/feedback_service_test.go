@@ -0,0 +1,161 @@
+package feedback
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+// MockFeedbackRepository is a mock implementation of Repository
+type MockFeedbackRepository struct {
+	mock.Mock
+}
+
+func (m *MockFeedbackRepository) Record(ctx context.Context, events ...types.InteractionEvent) error {
+	args := m.Called(ctx, events)
+	return args.Error(0)
+}
+
+func (m *MockFeedbackRepository) PendingUsers(ctx context.Context, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	args := m.Called(ctx, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockFeedbackRepository) UnlearnedEvents(ctx context.Context, userID uuid.UUID, limit int) ([]types.InteractionEvent, error) {
+	args := m.Called(ctx, userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.InteractionEvent), args.Error(1)
+}
+
+func (m *MockFeedbackRepository) Learn(ctx context.Context, userID uuid.UUID, deltas []types.Affinity, eventIDs []uuid.UUID) error {
+	args := m.Called(ctx, userID, deltas, eventIDs)
+	return args.Error(0)
+}
+
+func (m *MockFeedbackRepository) Affinities(ctx context.Context, userID uuid.UUID) ([]types.Affinity, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.Affinity), args.Error(1)
+}
+
+func (m *MockFeedbackRepository) LearnedPreferences(ctx context.Context, userID uuid.UUID) (*types.LearnedPreferences, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.LearnedPreferences), args.Error(1)
+}
+
+var now = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func setupFeedbackServiceTest() (*Service, *MockFeedbackRepository) {
+	mockRepo := new(MockFeedbackRepository)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	service := NewService(mockRepo, logger)
+	service.now = func() time.Time { return now }
+	return service, mockRepo
+}
+
+func TestAffinityDeltas(t *testing.T) {
+	t.Run("sums categories and tags, lower-cased", func(t *testing.T) {
+		events := []types.InteractionEvent{
+			{Category: "Museum", Tags: []string{"History"}, Weight: 1.0, CreatedAt: now},
+			{Category: "museum", Weight: 0.5, CreatedAt: now},
+			{Category: "Nightclub", Tags: []string{"loud"}, Weight: -0.8, CreatedAt: now},
+			{Weight: 1.0, CreatedAt: now},
+		}
+
+		deltas := AffinityDeltas(events, now)
+
+		require.Len(t, deltas, 4)
+		assert.Equal(t, types.Affinity{Dimension: types.AffinityCategory, Value: "museum", Score: 1.5, UpdatedAt: now}, deltas[0])
+		assert.Equal(t, types.Affinity{Dimension: types.AffinityCategory, Value: "nightclub", Score: -0.8, UpdatedAt: now}, deltas[1])
+		assert.Equal(t, types.Affinity{Dimension: types.AffinityTag, Value: "history", Score: 0.5, UpdatedAt: now}, deltas[2])
+		assert.Equal(t, types.Affinity{Dimension: types.AffinityTag, Value: "loud", Score: -0.4, UpdatedAt: now}, deltas[3])
+	})
+
+	t.Run("old events count less", func(t *testing.T) {
+		events := []types.InteractionEvent{
+			{Category: "park", Weight: 1.0, CreatedAt: now.Add(-affinityHalfLife)},
+		}
+
+		deltas := AffinityDeltas(events, now)
+
+		require.Len(t, deltas, 1)
+		assert.InDelta(t, 0.5, deltas[0].Score, 1e-9)
+	})
+
+	t.Run("a keep and a removal cancel out", func(t *testing.T) {
+		events := []types.InteractionEvent{
+			{Category: "bar", Weight: 1.0, CreatedAt: now},
+			{Category: "bar", Weight: -1.0, CreatedAt: now},
+		}
+
+		assert.Empty(t, AffinityDeltas(events, now))
+	})
+}
+
+func TestService_RunInteractionLearning(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	event := types.InteractionEvent{ID: uuid.New(), UserID: userID, Category: "museum", Weight: 1.0, CreatedAt: now}
+	deltas := []types.Affinity{{Dimension: types.AffinityCategory, Value: "museum", Score: 1.0, UpdatedAt: now}}
+
+	t.Run("single target", func(t *testing.T) {
+		service, mockRepo := setupFeedbackServiceTest()
+		mockRepo.On("UnlearnedEvents", mock.Anything, userID, eventBatchSize).Return([]types.InteractionEvent{event}, nil).Once()
+		mockRepo.On("Learn", mock.Anything, userID, deltas, []uuid.UUID{event.ID}).Return(nil).Once()
+
+		var progress types.JobProgress
+		err := service.RunInteractionLearning(ctx, &types.Job{Kind: types.JobKindInteractionLearning, TargetID: &userID}, func(p types.JobProgress) { progress = p })
+
+		require.NoError(t, err)
+		assert.Equal(t, types.JobProgress{Total: 1, Done: 1}, progress)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("events learned concurrently are not an error", func(t *testing.T) {
+		service, mockRepo := setupFeedbackServiceTest()
+		mockRepo.On("UnlearnedEvents", mock.Anything, userID, eventBatchSize).Return([]types.InteractionEvent{event}, nil).Once()
+		mockRepo.On("Learn", mock.Anything, userID, deltas, []uuid.UUID{event.ID}).Return(types.ErrConflict).Once()
+
+		err := service.RunInteractionLearning(ctx, &types.Job{Kind: types.JobKindInteractionLearning, TargetID: &userID}, func(types.JobProgress) {})
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("sweep pages past failures and reports them", func(t *testing.T) {
+		service, mockRepo := setupFeedbackServiceTest()
+		failing := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+		mockRepo.On("PendingUsers", mock.Anything, uuid.Nil, sweepPageSize).Return([]uuid.UUID{failing, userID}, nil).Once()
+		mockRepo.On("PendingUsers", mock.Anything, userID, sweepPageSize).Return([]uuid.UUID{}, nil).Once()
+		mockRepo.On("UnlearnedEvents", mock.Anything, failing, eventBatchSize).Return(nil, errors.New("connection reset")).Once()
+		mockRepo.On("UnlearnedEvents", mock.Anything, userID, eventBatchSize).Return([]types.InteractionEvent{event}, nil).Once()
+		mockRepo.On("Learn", mock.Anything, userID, deltas, []uuid.UUID{event.ID}).Return(nil).Once()
+
+		var progress types.JobProgress
+		err := service.RunInteractionLearning(ctx, &types.Job{Kind: types.JobKindInteractionLearning}, func(p types.JobProgress) { progress = p })
+
+		assert.Error(t, err)
+		assert.Equal(t, types.JobProgress{Total: 2, Done: 1, Failed: 1}, progress)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -33,6 +33,7 @@ type ChatSession struct {
 type RAGService struct {
 	aiClient         *AIClient
 	embeddingService *EmbeddingService
+	retriever        POIRetriever
 	logger           *slog.Logger
 }
 
@@ -265,8 +266,8 @@ func (cs *ChatSession) SendMessageStream(ctx context.Context, message string) it
 	return cs.chat.SendMessageStream(ctx, genai.Part{Text: message})
 }
 
-// NewRAGService creates a new RAG service instance
-func NewRAGService(ctx context.Context, logger *slog.Logger) (*RAGService, error) {
+// NewRAGService creates a new RAG service instance that retrieves context through retriever
+func NewRAGService(ctx context.Context, retriever POIRetriever, logger *slog.Logger) (*RAGService, error) {
 	ctx, span := otel.Tracer("RAGService").Start(ctx, "NewRAGService")
 	defer span.End()
 
@@ -288,6 +289,7 @@ func NewRAGService(ctx context.Context, logger *slog.Logger) (*RAGService, error
 	return &RAGService{
 		aiClient:         aiClient,
 		embeddingService: embeddingService,
+		retriever:        retriever,
 		logger:           logger,
 	}, nil
 }
@@ -11,19 +11,27 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/genai"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
 )
 
 const (
 	// Gemini embedding model - using the latest embedding model
 	//EmbeddingModel = "text-embedding-004"
 	EmbeddingModel = "gemini-embedding-exp-03-07"
+	// EmbeddingModelVersion is bumped when the embedding input text changes
+	EmbeddingModelVersion = 1
 	// Standard embedding dimension for Gemini text-embedding-004
 	EmbeddingDimension = 768
 )
 
+// DefaultEmbeddingModel is the model new tables start on.
+var DefaultEmbeddingModel = types.EmbeddingModel{Name: EmbeddingModel, Version: EmbeddingModelVersion}
+
 type EmbeddingService struct {
 	client *genai.Client
 	logger *slog.Logger
+	model  types.EmbeddingModel
 }
 
 type EmbeddingRequest struct {
@@ -62,14 +70,29 @@ func NewEmbeddingService(ctx context.Context, logger *slog.Logger) (*EmbeddingSe
 	return &EmbeddingService{
 		client: client,
 		logger: logger,
+		model:  DefaultEmbeddingModel,
 	}, nil
 }
 
+// Model returns the model this service embeds with.
+func (es *EmbeddingService) Model() types.EmbeddingModel {
+	return es.model
+}
+
+// WithModel returns a service sharing the same client that embeds with model.
+// Use the active model of the table being searched or written so that vectors
+// stay comparable.
+func (es *EmbeddingService) WithModel(model types.EmbeddingModel) *EmbeddingService {
+	clone := *es
+	clone.model = model
+	return &clone
+}
+
 // GenerateEmbedding generates an embedding vector for the given text
 func (es *EmbeddingService) GenerateEmbedding(ctx context.Context, text string, config *genai.EmbedContentConfig) ([]float32, error) {
 	ctx, span := otel.Tracer("EmbeddingService").Start(ctx, "GenerateEmbedding", trace.WithAttributes(
 		attribute.String("text.length", fmt.Sprintf("%d", len(text))),
-		attribute.String("model", EmbeddingModel),
+		attribute.String("model", es.model.String()),
 	))
 	defer span.End()
 
@@ -81,7 +104,7 @@ func (es *EmbeddingService) GenerateEmbedding(ctx context.Context, text string,
 	}
 
 	// Use the embedding model to generate embeddings
-	embedding, err := es.client.Models.EmbedContent(ctx, EmbeddingModel, genai.Text(text), config)
+	embedding, err := es.client.Models.EmbedContent(ctx, es.model.Name, genai.Text(text), config)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "Failed to generate embedding")
@@ -110,13 +133,13 @@ func (es *EmbeddingService) GenerateEmbedding(ctx context.Context, text string,
 
 	span.SetAttributes(
 		attribute.Int("embedding.dimension", len(contentEmbedding.Values)),
-		attribute.String("embedding.model", EmbeddingModel),
+		attribute.String("embedding.model", es.model.String()),
 	)
 	span.SetStatus(codes.Ok, "Embedding generated successfully")
 
 	es.logger.DebugContext(ctx, "Embedding generated",
 		slog.Int("dimension", len(contentEmbedding.Values)),
-		slog.String("model", EmbeddingModel))
+		slog.String("model", es.model.String()))
 
 	return contentEmbedding.Values, nil
 }
@@ -254,13 +277,13 @@ func (es *EmbeddingService) BatchGenerateEmbeddings(ctx context.Context, texts [
 
 	span.SetAttributes(
 		attribute.Int("successful.embeddings", len(embeddings)),
-		attribute.String("model", EmbeddingModel),
+		attribute.String("model", es.model.String()),
 	)
 	span.SetStatus(codes.Ok, "Batch embeddings generated successfully")
 
 	es.logger.InfoContext(ctx, "Batch embeddings generated",
 		slog.Int("count", len(embeddings)),
-		slog.String("model", EmbeddingModel))
+		slog.String("model", es.model.String()))
 
 	return embeddings, nil
 }
@@ -0,0 +1,291 @@
+package generativeAI
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genai"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+const (
+	// ragRetrievalLimit is how many POIs are retrieved as candidate sources
+	ragRetrievalLimit = 8
+	// ragMaxHistoryTurns bounds how much of the conversation is sent with the question
+	ragMaxHistoryTurns = 6
+	// Cosine similarities below ragSimilarityFloor are treated as unrelated and
+	// above ragSimilarityCeiling as a certain match when calibrating confidence.
+	ragSimilarityFloor   = 0.5
+	ragSimilarityCeiling = 0.85
+	ragTemperature       = 0.2
+)
+
+// POIRetriever finds POIs semantically close to a query embedding.
+// Implemented by poi.RepositoryImpl, which stores the cosine similarity in POIDetailedInfo.Distance.
+type POIRetriever interface {
+	ActiveEmbeddingModel(ctx context.Context) (types.EmbeddingModel, error)
+	FindSimilarPOIsByCity(ctx context.Context, queryEmbedding []float32, model types.EmbeddingModel, cityID uuid.UUID, limit int) ([]types.POIDetailedInfo, error)
+}
+
+var citationPattern = regexp.MustCompile(` ?\[(\d+)\]`)
+
+// ragAnswer is the JSON shape the model is asked to return
+type ragAnswer struct {
+	Answer       string   `json:"answer"`
+	CitedSources []int    `json:"cited_sources"`
+	Grounded     bool     `json:"grounded"`
+	Suggestions  []string `json:"suggestions"`
+}
+
+// RetrieveContext embeds the query and returns the closest POIs in the city, best match first.
+func (r *RAGService) RetrieveContext(ctx context.Context, query string, cityID uuid.UUID, limit int) ([]types.POIDetailedInfo, error) {
+	ctx, span := otel.Tracer("RAGService").Start(ctx, "RetrieveContext", trace.WithAttributes(
+		attribute.String("city.id", cityID.String()),
+		attribute.Int("limit", limit),
+	))
+	defer span.End()
+
+	model, err := r.retriever.ActiveEmbeddingModel(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to resolve embedding model")
+		return nil, fmt.Errorf("failed to resolve embedding model: %w", err)
+	}
+
+	queryEmbedding, err := r.embeddingService.WithModel(model).GenerateQueryEmbedding(ctx, query)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to generate query embedding")
+		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
+	}
+
+	pois, err := r.retriever.FindSimilarPOIsByCity(ctx, queryEmbedding, model, cityID, limit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to retrieve POIs")
+		return nil, fmt.Errorf("failed to retrieve similar POIs: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("retrieved.count", len(pois)))
+	span.SetStatus(codes.Ok, "Context retrieved")
+	return pois, nil
+}
+
+// Answer runs the full pipeline for a question about a city: retrieve the
+// closest POIs, then generate an answer grounded in them.
+func (r *RAGService) Answer(ctx context.Context, query string, cityID uuid.UUID, cityContext string, history []ConversationTurn) (*RAGResponse, error) {
+	ctx, span := otel.Tracer("RAGService").Start(ctx, "Answer", trace.WithAttributes(
+		attribute.String("city.id", cityID.String()),
+		attribute.Int("history.turns", len(history)),
+	))
+	defer span.End()
+
+	pois, err := r.RetrieveContext(ctx, query, cityID, ragRetrievalLimit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Retrieval failed")
+		return nil, err
+	}
+
+	resp, err := r.GenerateRAGResponse(ctx, RAGContext{
+		Query:               query,
+		RelevantPOIs:        pois,
+		CityContext:         cityContext,
+		ConversationHistory: history,
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Generation failed")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "Question answered")
+	return resp, nil
+}
+
+// GenerateRAGResponse answers ragCtx.Query using only the supplied POIs and city context.
+// RelevantPOIs must be ordered best match first with the similarity in Distance.
+func (r *RAGService) GenerateRAGResponse(ctx context.Context, ragCtx RAGContext) (*RAGResponse, error) {
+	ctx, span := otel.Tracer("RAGService").Start(ctx, "GenerateRAGResponse", trace.WithAttributes(
+		attribute.Int("relevant_pois.count", len(ragCtx.RelevantPOIs)),
+	))
+	defer span.End()
+	l := r.logger.With(slog.String("method", "GenerateRAGResponse"))
+
+	prompt := buildRAGPrompt(ragCtx)
+	span.SetAttributes(attribute.Int("prompt.length", len(prompt)))
+
+	response, err := r.aiClient.GenerateResponse(ctx, prompt, &genai.GenerateContentConfig{
+		Temperature:      genai.Ptr[float32](ragTemperature),
+		ResponseMIMEType: "application/json",
+	})
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to generate RAG answer", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to generate answer")
+		return nil, fmt.Errorf("failed to generate answer: %w", err)
+	}
+
+	answer, err := parseRAGAnswer(response.Text())
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to parse RAG answer", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to parse answer")
+		return nil, err
+	}
+
+	sources, renumbered := citedPOIs(ragCtx.RelevantPOIs, answer.CitedSources)
+	result := &RAGResponse{
+		Answer:      renumberCitations(answer.Answer, renumbered),
+		SourcePOIs:  sources,
+		Confidence:  calibrateConfidence(ragCtx.RelevantPOIs, sources, answer.Grounded),
+		Suggestions: answer.Suggestions,
+	}
+
+	l.InfoContext(ctx, "RAG answer generated",
+		slog.Int("source_pois", len(result.SourcePOIs)),
+		slog.Float64("confidence", result.Confidence),
+		slog.Bool("grounded", answer.Grounded))
+	span.SetAttributes(
+		attribute.Int("source_pois.count", len(result.SourcePOIs)),
+		attribute.Float64("response.confidence", result.Confidence),
+	)
+	span.SetStatus(codes.Ok, "RAG answer generated")
+	return result, nil
+}
+
+// buildRAGPrompt lists the POIs as numbered sources so the model can cite them by index.
+func buildRAGPrompt(ragCtx RAGContext) string {
+	var b strings.Builder
+	b.WriteString("You are a travel assistant answering a question about a city using ONLY the information below.\n")
+	b.WriteString("If the sources and city context do not answer the question, say so briefly and set \"grounded\" to false. Never invent places.\n\n")
+
+	if ragCtx.CityContext != "" {
+		b.WriteString("City context:\n")
+		b.WriteString(ragCtx.CityContext)
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString("Sources:\n")
+	if len(ragCtx.RelevantPOIs) == 0 {
+		b.WriteString("(none)\n")
+	}
+	for i, poi := range ragCtx.RelevantPOIs {
+		fmt.Fprintf(&b, "[%d] %s (%s)", i+1, poi.Name, poi.Category)
+		if poi.DescriptionPOI != "" {
+			fmt.Fprintf(&b, ": %s", poi.DescriptionPOI)
+		}
+		b.WriteString("\n")
+	}
+
+	history := ragCtx.ConversationHistory
+	if len(history) > ragMaxHistoryTurns {
+		history = history[len(history)-ragMaxHistoryTurns:]
+	}
+	if len(history) > 0 {
+		b.WriteString("\nRecent conversation:\n")
+		for _, turn := range history {
+			fmt.Fprintf(&b, "%s: %s\n", turn.Role, turn.Message)
+		}
+	}
+
+	fmt.Fprintf(&b, "\nQuestion: %s\n\n", ragCtx.Query)
+	b.WriteString(`Respond with JSON only:
+{
+  "answer": "concise answer citing sources inline as [n]",
+  "cited_sources": [source numbers the answer relies on],
+  "grounded": true if the answer is supported by the sources or city context,
+  "suggestions": ["up to 3 short follow-up questions"]
+}`)
+	return b.String()
+}
+
+// parseRAGAnswer decodes the model output, tolerating markdown code fences.
+func parseRAGAnswer(text string) (*ragAnswer, error) {
+	text = strings.TrimSpace(text)
+	text = strings.TrimPrefix(text, "```json")
+	text = strings.TrimPrefix(text, "```")
+	text = strings.TrimSuffix(text, "```")
+
+	var answer ragAnswer
+	if err := json.Unmarshal([]byte(strings.TrimSpace(text)), &answer); err != nil {
+		return nil, fmt.Errorf("failed to decode RAG answer: %w", err)
+	}
+	if strings.TrimSpace(answer.Answer) == "" {
+		return nil, fmt.Errorf("RAG answer is empty")
+	}
+	return &answer, nil
+}
+
+// citedPOIs maps 1-based source numbers back to POIs, dropping duplicates and
+// numbers the model made up. The returned map gives each kept source number its
+// position in the result, so inline citations can be renumbered to match.
+func citedPOIs(pois []types.POIDetailedInfo, citations []int) ([]types.POIDetailedInfo, map[int]int) {
+	renumbered := make(map[int]int, len(citations))
+	var cited []types.POIDetailedInfo
+	for _, n := range citations {
+		if n < 1 || n > len(pois) || renumbered[n] != 0 {
+			continue
+		}
+		cited = append(cited, pois[n-1])
+		renumbered[n] = len(cited)
+	}
+	return cited, renumbered
+}
+
+// renumberCitations rewrites inline [n] markers to SourcePOIs positions and
+// strips markers for sources that were not kept.
+func renumberCitations(answer string, renumbered map[int]int) string {
+	return citationPattern.ReplaceAllStringFunc(answer, func(marker string) string {
+		lead, number := "", marker
+		if strings.HasPrefix(marker, " ") {
+			lead, number = " ", marker[1:]
+		}
+		n, _ := strconv.Atoi(number[1 : len(number)-1])
+		if to, ok := renumbered[n]; ok {
+			return fmt.Sprintf("%s[%d]", lead, to)
+		}
+		return ""
+	})
+}
+
+// normalizeSimilarity maps a cosine similarity onto [0,1] between the floor and ceiling.
+func normalizeSimilarity(similarity float64) float64 {
+	v := (similarity - ragSimilarityFloor) / (ragSimilarityCeiling - ragSimilarityFloor)
+	return math.Max(0, math.Min(1, v))
+}
+
+// calibrateConfidence scores an answer from retrieval quality rather than trusting
+// the model: mostly the mean similarity of the cited sources, plus a small bonus
+// for corroboration by several sources. Uncited answers can only score from the
+// best retrieved match, and answers the model flags as ungrounded are halved.
+func calibrateConfidence(retrieved, cited []types.POIDetailedInfo, grounded bool) float64 {
+	var confidence float64
+	switch {
+	case len(cited) > 0:
+		var sum float64
+		for _, poi := range cited {
+			sum += normalizeSimilarity(poi.Distance)
+		}
+		corroboration := math.Min(float64(len(cited)), 3) / 3
+		confidence = 0.8*(sum/float64(len(cited))) + 0.2*corroboration
+	case len(retrieved) > 0:
+		confidence = 0.3 * normalizeSimilarity(retrieved[0].Distance)
+	}
+	if !grounded {
+		confidence *= 0.5
+	}
+	return math.Round(confidence*100) / 100
+}
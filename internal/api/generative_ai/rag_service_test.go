@@ -0,0 +1,89 @@
+package generativeAI
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+func TestBuildRAGPrompt(t *testing.T) {
+	history := make([]ConversationTurn, 0, ragMaxHistoryTurns+2)
+	for i := 0; i < ragMaxHistoryTurns+2; i++ {
+		history = append(history, ConversationTurn{Role: "user", Message: string(rune('a' + i))})
+	}
+
+	prompt := buildRAGPrompt(RAGContext{
+		Query:       "Where can I see tiles?",
+		CityContext: "Lisbon is the capital of Portugal.",
+		RelevantPOIs: []types.POIDetailedInfo{
+			{Name: "Museu do Azulejo", Category: "Museum", DescriptionPOI: "National tile museum"},
+			{Name: "Alfama", Category: "Neighbourhood"},
+		},
+		ConversationHistory: history,
+	})
+
+	assert.Contains(t, prompt, "Lisbon is the capital of Portugal.")
+	assert.Contains(t, prompt, "[1] Museu do Azulejo (Museum): National tile museum")
+	assert.Contains(t, prompt, "[2] Alfama (Neighbourhood)\n")
+	assert.Contains(t, prompt, "Question: Where can I see tiles?")
+	// Only the most recent turns are kept
+	assert.NotContains(t, prompt, "user: a\n")
+	assert.NotContains(t, prompt, "user: b\n")
+	assert.Contains(t, prompt, "user: c\n")
+}
+
+func TestParseRAGAnswer(t *testing.T) {
+	t.Run("strips code fences", func(t *testing.T) {
+		answer, err := parseRAGAnswer("```json\n{\"answer\":\"Try [1].\",\"cited_sources\":[1],\"grounded\":true}\n```")
+
+		require.NoError(t, err)
+		assert.Equal(t, "Try [1].", answer.Answer)
+		assert.Equal(t, []int{1}, answer.CitedSources)
+		assert.True(t, answer.Grounded)
+	})
+
+	t.Run("empty answer", func(t *testing.T) {
+		_, err := parseRAGAnswer(`{"answer":"  ","grounded":false}`)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestCitedPOIs(t *testing.T) {
+	pois := []types.POIDetailedInfo{{Name: "A"}, {Name: "B"}, {Name: "C"}}
+
+	cited, renumbered := citedPOIs(pois, []int{3, 0, 3, 7, 1})
+
+	require.Len(t, cited, 2)
+	assert.Equal(t, "C", cited[0].Name)
+	assert.Equal(t, "A", cited[1].Name)
+	assert.Equal(t, "Visit C [1] and A [2].", renumberCitations("Visit C [3] and A [1] [7].", renumbered))
+}
+
+func TestCalibrateConfidence(t *testing.T) {
+	strong := types.POIDetailedInfo{Name: "strong", Distance: 0.9}
+	weak := types.POIDetailedInfo{Name: "weak", Distance: 0.55}
+	retrieved := []types.POIDetailedInfo{strong, weak}
+
+	tests := []struct {
+		name     string
+		cited    []types.POIDetailedInfo
+		grounded bool
+		want     float64
+	}{
+		{name: "single strong citation", cited: []types.POIDetailedInfo{strong}, grounded: true, want: 0.87},
+		{name: "weak citation drags the score down", cited: []types.POIDetailedInfo{strong, weak}, grounded: true, want: 0.59},
+		{name: "uncited answer scores from best match only", grounded: true, want: 0.3},
+		{name: "ungrounded answer is halved", cited: []types.POIDetailedInfo{strong}, grounded: false, want: 0.43},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, calibrateConfidence(retrieved, tt.cited, tt.grounded), 0.01)
+		})
+	}
+
+	assert.Zero(t, calibrateConfidence(nil, nil, true))
+}
@@ -50,11 +50,14 @@ func NewHandlerImpl(interestsService interestsService, logger *slog.Logger) *Han
 
 // GetAllInterests godoc
 // @Summary      Get All Interests
-// @Description  Retrieves all available interests.
+// @Description  Retrieves a page of the available interests, by name. The Link header carries the next page.
 // @Tags         User
 // @Accept       json
 // @Produce      json
+// @Param        cursor query string false "Cursor of the page to fetch, from the Link header; omit for the first page"
+// @Param        limit query int false "Number of interests per page (default 50, max 200)"
 // @Success      200 {array} types.Interest "All Interests"
+// @Failure      400 {object} types.Response "Invalid cursor"
 // @Failure      500 {object} types.Response "Internal Server Error"
 // @Router       /user/interests [get]
 func (h *HandlerImpl) GetAllInterests(w http.ResponseWriter, r *http.Request) {
@@ -66,7 +69,14 @@ func (h *HandlerImpl) GetAllInterests(w http.ResponseWriter, r *http.Request) {
 
 	l := h.logger.With(slog.String("HandlerImpl", "GetAllInterests"))
 
-	interests, err := h.interestsService.GetAllInterests(ctx)
+	page, err := api.ParsePageRequest(r)
+	if err != nil {
+		span.SetStatus(codes.Error, "Invalid cursor")
+		api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	interests, next, err := h.interestsService.GetAllInterests(ctx, page)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to get all interests", slog.Any("error", err))
 		span.RecordError(err)
@@ -76,6 +86,7 @@ func (h *HandlerImpl) GetAllInterests(w http.ResponseWriter, r *http.Request) {
 	}
 
 	span.SetStatus(codes.Ok, "All interests retrieved successfully")
+	api.SetPageLinks(w, r, next)
 	api.WriteJSONResponse(w, r, http.StatusOK, interests)
 }
 
@@ -17,6 +17,7 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/embeddings"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
 )
 
@@ -28,6 +29,9 @@ type Repository interface {
 	CreateInterest(ctx context.Context, name string, description *string, isActive bool, userID string) (*types.Interest, error)
 	Removeinterests(ctx context.Context, userID uuid.UUID, interestID uuid.UUID) error
 	GetAllInterests(ctx context.Context) ([]*types.Interest, error)
+	// ListInterests returns a page of the interests GetAllInterests returns, by
+	// name, and the cursor of the next page.
+	ListInterests(ctx context.Context, page types.PageRequest) ([]*types.Interest, *types.Cursor, error)
 	GetInterest(ctx context.Context, interestID uuid.UUID) (*types.Interest, error)
 	Updateinterests(ctx context.Context, userID uuid.UUID, interestID uuid.UUID, params types.UpdateinterestsParams) error
 	AddInterestToProfile(ctx context.Context, profileID, interestID uuid.UUID) error
@@ -69,6 +73,14 @@ func (r *RepositoryImpl) CreateInterest(ctx context.Context, name string, descri
 		return nil, fmt.Errorf("interest name cannot be empty: %w", types.ErrBadRequest) // Example domain error
 	}
 
+	tx, err := r.pgpool.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Begin transaction failed")
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	var interest types.Interest
 	query := `
         INSERT INTO user_custom_interests (name, description, active, created_at, updated_at, user_id)
@@ -76,7 +88,7 @@ func (r *RepositoryImpl) CreateInterest(ctx context.Context, name string, descri
         RETURNING id, name, description, active, created_at, updated_at`
 
 	// Note: Use current time for both created_at (via DEFAULT) and updated_at on insert
-	err := r.pgpool.QueryRow(ctx, query, name, description, isActive, userID).Scan(
+	err = tx.QueryRow(ctx, query, name, description, isActive, userID).Scan(
 		&interest.ID,
 		&interest.Name,
 		&interest.Description,
@@ -103,6 +115,20 @@ func (r *RepositoryImpl) CreateInterest(ctx context.Context, name string, descri
 		return nil, fmt.Errorf("database error creating interest: %w", err)
 	}
 
+	ownerID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", types.ErrBadRequest)
+	}
+	if err := embeddings.RefreshUserPreferences(ctx, tx, ownerID); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Commit transaction failed")
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	l.InfoContext(ctx, "Global interest created successfully", slog.String("interestID", interest.ID.String()))
 	span.SetAttributes(attribute.String("db.interest.id", interest.ID.String()))
 	span.SetStatus(codes.Ok, "Interest created")
@@ -123,8 +149,16 @@ func (r *RepositoryImpl) Removeinterests(ctx context.Context, userID uuid.UUID,
 	l := r.logger.With(slog.String("method", "Removeinterests"), slog.String("userID", userID.String()), slog.String("interestID", interestID.String()))
 	l.DebugContext(ctx, "Removing user interest")
 
+	tx, err := r.pgpool.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Begin transaction failed")
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	query := "DELETE FROM user_custom_interests WHERE user_id = $1 AND id = $2"
-	tag, err := r.pgpool.Exec(ctx, query, userID, interestID)
+	tag, err := tx.Exec(ctx, query, userID, interestID)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to delete user interest", slog.Any("error", err))
 		span.RecordError(err)
@@ -139,6 +173,16 @@ func (r *RepositoryImpl) Removeinterests(ctx context.Context, userID uuid.UUID,
 		return fmt.Errorf("interest association not found: %w", types.ErrNotFound)
 	}
 
+	if err := embeddings.RefreshUserPreferences(ctx, tx, userID); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Commit transaction failed")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	l.InfoContext(ctx, "User interest removed successfully")
 	span.SetStatus(codes.Ok, "Interest removed")
 	return nil
@@ -201,6 +245,63 @@ func (r *RepositoryImpl) GetAllInterests(ctx context.Context) ([]*types.Interest
 }
 
 // GetUserEnhancedInterests implements user.UserRepo.
+// ListInterests implements Repository.
+func (r *RepositoryImpl) ListInterests(ctx context.Context, page types.PageRequest) ([]*types.Interest, *types.Cursor, error) {
+	ctx, span := otel.Tracer("UserRepo").Start(ctx, "ListInterests", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "interests, user_custom_interests"),
+	))
+	defer span.End()
+
+	args := []interface{}{page.FetchLimit()}
+	after := ""
+	if page.Cursor != nil {
+		args = append(args, page.Cursor.Key, page.Cursor.ID)
+		after = "WHERE (name, id) > ($2, $3)"
+	}
+	query := fmt.Sprintf(`
+        SELECT id, name, description, active, created_at, updated_at, type
+        FROM (
+            SELECT id, name, description, false AS active, created_at, updated_at, 'global' AS type
+            FROM interests
+            UNION
+            SELECT id, name, description, active, created_at, updated_at, 'custom' AS type
+            FROM user_custom_interests
+        ) i
+        %s
+        ORDER BY name, id
+        LIMIT $1`, after)
+
+	rows, err := r.pgpool.Query(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, nil, fmt.Errorf("database error listing interests: %w", err)
+	}
+	defer rows.Close()
+
+	var interests []*types.Interest
+	for rows.Next() {
+		var i types.Interest
+		if err := rows.Scan(&i.ID, &i.Name, &i.Description, &i.Active, &i.CreatedAt, &i.UpdatedAt, &i.Source); err != nil {
+			span.RecordError(err)
+			return nil, nil, fmt.Errorf("database error scanning interest: %w", err)
+		}
+		interests = append(interests, &i)
+	}
+	if err = rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, nil, fmt.Errorf("database error reading interests: %w", err)
+	}
+
+	interests, next := types.NextPage(interests, page.Limit, func(i *types.Interest) types.Cursor {
+		return types.Cursor{Key: i.Name, ID: i.ID}
+	})
+	span.SetAttributes(attribute.Int("results.count", len(interests)))
+	span.SetStatus(codes.Ok, "Interests listed")
+	return interests, next, nil
+}
+
 //func (r *RepositoryImpl) GetUserEnhancedInterests(ctx context.Context, userID uuid.UUID) ([]types.EnhancedInterest, error) {
 //	ctx, span := otel.Tracer("UserRepo").Start(ctx, "GetUserEnhancedInterests", trace.WithAttributes(
 //		semconv.DBSystemPostgreSQL,
@@ -332,8 +433,16 @@ func (r *RepositoryImpl) Updateinterests(ctx context.Context, userID uuid.UUID,
 
 	l.DebugContext(ctx, "Executing dynamic update query", slog.String("query", query))
 
+	tx, err := r.pgpool.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Begin transaction failed")
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	// Execute query
-	tag, err := r.pgpool.Exec(ctx, query, args...)
+	tag, err := tx.Exec(ctx, query, args...)
 	if err != nil {
 		// Check for unique constraint on (user_id, name) if name was updated
 		var pgErr *pgconn.PgError
@@ -358,6 +467,16 @@ func (r *RepositoryImpl) Updateinterests(ctx context.Context, userID uuid.UUID,
 		return fmt.Errorf("custom interest with ID %s not found for user %s: %w", interestID.String(), userID.String(), types.ErrNotFound)
 	}
 
+	if err := embeddings.RefreshUserPreferences(ctx, tx, userID); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Commit transaction failed")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	l.InfoContext(ctx, "User custom interest updated successfully")
 	span.SetStatus(codes.Ok, "Custom interest updated")
 	return nil
@@ -422,7 +541,15 @@ func (r *RepositoryImpl) AddInterestToProfile(ctx context.Context, profileID, in
         VALUES ($1, $2, $3)
         ON CONFLICT DO NOTHING`
 
-	_, err := r.pgpool.Exec(ctx, query, profileID, interestID, 1) // Default preference_level = 1
+	tx, err := r.pgpool.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Begin transaction failed")
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, query, profileID, interestID, 1) // Default preference_level = 1
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to link interest to profile", slog.Any("error", err))
 		span.RecordError(err)
@@ -430,6 +557,16 @@ func (r *RepositoryImpl) AddInterestToProfile(ctx context.Context, profileID, in
 		return fmt.Errorf("database error linking interest to profile: %w", err)
 	}
 
+	if err := embeddings.RefreshProfilePreferences(ctx, tx, profileID); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Commit transaction failed")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	l.DebugContext(ctx, "Interest linked to profile successfully")
 	span.SetStatus(codes.Ok, "Interest linked")
 	return nil
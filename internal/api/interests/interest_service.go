@@ -17,11 +17,17 @@ import (
 // Ensure implementation satisfies the interface
 var _ interestsService = (*interestsServiceImpl)(nil)
 
+// Page sizes of the interests list.
+const (
+	defaultInterestPageLimit = 50
+	maxInterestPageLimit     = 200
+)
+
 // interestsService defines the business logic contract for user operations.
 type interestsService interface {
 	//Removeinterests remove interests
 	Removeinterests(ctx context.Context, userID uuid.UUID, interestID uuid.UUID) error
-	GetAllInterests(ctx context.Context) ([]*types.Interest, error)
+	GetAllInterests(ctx context.Context, page types.PageRequest) ([]*types.Interest, *types.Cursor, error)
 	CreateInterest(ctx context.Context, name string, description *string, isActive bool, userID string) (*types.Interest, error)
 	Updateinterests(ctx context.Context, userID uuid.UUID, interestID uuid.UUID, params types.UpdateinterestsParams) error
 }
@@ -89,25 +95,25 @@ func (s *interestsServiceImpl) Removeinterests(ctx context.Context, userID uuid.
 	return nil
 }
 
-// GetAllInterests retrieves all available interests.
-func (s *interestsServiceImpl) GetAllInterests(ctx context.Context) ([]*types.Interest, error) {
+// GetAllInterests retrieves a page of the available interests.
+func (s *interestsServiceImpl) GetAllInterests(ctx context.Context, page types.PageRequest) ([]*types.Interest, *types.Cursor, error) {
 	ctx, span := otel.Tracer("interestsService").Start(ctx, "GetAllInterests")
 	defer span.End()
 
 	l := s.logger.With(slog.String("method", "GetAllInterests"))
 	l.DebugContext(ctx, "Fetching all interests")
 
-	interests, err := s.repo.GetAllInterests(ctx)
+	interests, next, err := s.repo.ListInterests(ctx, page.Clamp(defaultInterestPageLimit, maxInterestPageLimit))
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to fetch all interests", slog.Any("error", err))
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "Failed to fetch all interests")
-		return nil, fmt.Errorf("error fetching all interests: %w", err)
+		return nil, nil, fmt.Errorf("error fetching all interests: %w", err)
 	}
 
 	l.InfoContext(ctx, "All interests fetched successfully", slog.Int("count", len(interests)))
 	span.SetStatus(codes.Ok, "All interests fetched successfully")
-	return interests, nil
+	return interests, next, nil
 }
 
 func (s *interestsServiceImpl) Updateinterests(ctx context.Context, userID uuid.UUID, interestID uuid.UUID, params types.UpdateinterestsParams) error {
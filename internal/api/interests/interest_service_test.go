@@ -40,6 +40,15 @@ func (m *MockinterestsRepo) GetAllInterests(ctx context.Context) ([]*types.Inter
 	return args.Get(0).([]*types.Interest), args.Error(1)
 }
 
+func (m *MockinterestsRepo) ListInterests(ctx context.Context, page types.PageRequest) ([]*types.Interest, *types.Cursor, error) {
+	args := m.Called(ctx, page)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	next, _ := args.Get(1).(*types.Cursor)
+	return args.Get(0).([]*types.Interest), next, args.Error(2)
+}
+
 func (m *MockinterestsRepo) Updateinterests(ctx context.Context, userID uuid.UUID, interestID uuid.UUID, params types.UpdateinterestsParams) error {
 	args := m.Called(ctx, userID, interestID, params)
 	return args.Error(0)
@@ -226,14 +235,14 @@ func TestGetAllInterests(t *testing.T) {
 		{
 			name: "Success",
 			setupMock: func() {
-				mockRepo.On("GetAllInterests", ctx).Return(expectedInterests, nil)
+				mockRepo.On("ListInterests", mock.Anything, types.PageRequest{Limit: defaultInterestPageLimit}).Return(expectedInterests, nil, nil).Once()
 			},
 			expectedError: false,
 		},
 		{
 			name: "Repository Error",
 			setupMock: func() {
-				mockRepo.On("GetAllInterests", ctx).Return(nil, errors.New("repository error"))
+				mockRepo.On("ListInterests", mock.Anything, types.PageRequest{Limit: defaultInterestPageLimit}).Return(nil, nil, errors.New("repository error")).Once()
 			},
 			expectedError: true,
 		},
@@ -246,7 +255,7 @@ func TestGetAllInterests(t *testing.T) {
 			tc.setupMock()
 
 			// Call the method
-			interests, err := service.GetAllInterests(ctx)
+			interests, _, err := service.GetAllInterests(ctx, types.PageRequest{})
 
 			// Assertions
 			if tc.expectedError {
@@ -0,0 +1,194 @@
+package jobs
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/auth"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Handler = (*HandlerImpl)(nil)
+
+type Handler interface {
+	EnqueueJob(w http.ResponseWriter, r *http.Request)
+	ListJobs(w http.ResponseWriter, r *http.Request)
+	GetJob(w http.ResponseWriter, r *http.Request)
+	GeneratePOIEmbeddings(w http.ResponseWriter, r *http.Request)
+}
+
+type HandlerImpl struct {
+	logger  *slog.Logger
+	service Service
+}
+
+func NewHandler(service Service, logger *slog.Logger) *HandlerImpl {
+	return &HandlerImpl{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// EnqueueJob godoc
+// @Summary      Enqueue Background Job
+// @Description  Queues a background job. Without target_id, embedding jobs process every entity that has no embedding yet. If the same job is already pending or running it is returned instead. Admin only.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        job body types.EnqueueJobRequest true "Job kind and optional target"
+// @Success      202 {object} types.Job
+// @Failure      400 {object} types.Response "Unknown job kind"
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      403 {object} types.Response "Forbidden"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/jobs [post]
+func (h *HandlerImpl) EnqueueJob(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("JobsHandler").Start(r.Context(), "EnqueueJob")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "EnqueueJob"))
+
+	var req types.EnqueueJobRequest
+	if err := api.DecodeJSONBody(w, r, &req); err != nil {
+		l.ErrorContext(ctx, "Failed to decode request", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Bad request")
+		return
+	}
+	span.SetAttributes(attribute.String("job.kind", req.Kind))
+
+	job, err := h.service.Enqueue(ctx, req, api.Requester(auth.GetUserIDFromContext(ctx)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to enqueue job")
+		api.WriteServiceError(w, r, err, "Failed to enqueue job")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Job enqueued")
+	api.WriteJSONResponse(w, r, http.StatusAccepted, job)
+}
+
+// ListJobs godoc
+// @Summary      List Background Jobs
+// @Description  Lists background jobs with their progress, newest first. Admin only.
+// @Tags         Admin
+// @Produce      json
+// @Param        kind query string false "Filter by job kind"
+// @Param        status query string false "Filter by status (pending, running, completed, failed)"
+// @Param        cursor query string false "Cursor of the page to fetch, from the Link header; omit for the first page"
+// @Param        limit query int false "Maximum jobs to return (default 50, max 200)"
+// @Success      200 {array} types.Job
+// @Failure      400 {object} types.Response "Invalid filter or cursor"
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      403 {object} types.Response "Forbidden"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/jobs [get]
+func (h *HandlerImpl) ListJobs(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("JobsHandler").Start(r.Context(), "ListJobs")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "ListJobs"))
+
+	page, err := api.ParsePageRequest(r)
+	if err != nil {
+		span.SetStatus(codes.Error, "Invalid cursor")
+		api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	q := r.URL.Query()
+	filter := types.JobFilter{
+		Kind:   q.Get("kind"),
+		Status: q.Get("status"),
+		Page:   page,
+	}
+
+	jobs, next, err := h.service.ListJobs(ctx, filter)
+	if err != nil {
+		l.ErrorContext(ctx, "Service failed to list jobs", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to list jobs")
+		api.WriteServiceError(w, r, err, "Failed to list jobs")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Jobs listed")
+	api.SetPageLinks(w, r, next)
+	api.WriteJSONResponse(w, r, http.StatusOK, jobs)
+}
+
+// GetJob godoc
+// @Summary      Get Background Job
+// @Description  Returns a background job's status and progress. Admin only.
+// @Tags         Admin
+// @Produce      json
+// @Param        jobID path string true "Job ID"
+// @Success      200 {object} types.Job
+// @Failure      400 {object} types.Response "Invalid job ID"
+// @Failure      404 {object} types.Response "Job not found"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/jobs/{jobID} [get]
+func (h *HandlerImpl) GetJob(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("JobsHandler").Start(r.Context(), "GetJob")
+	defer span.End()
+
+	jobID, err := uuid.Parse(chi.URLParam(r, "jobID"))
+	if err != nil {
+		span.SetStatus(codes.Error, "Invalid job ID")
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid jobID format")
+		return
+	}
+	span.SetAttributes(attribute.String("job.id", jobID.String()))
+
+	job, err := h.service.GetJob(ctx, jobID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to get job")
+		api.WriteServiceError(w, r, err, "Failed to get job")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Job retrieved")
+	api.WriteJSONResponse(w, r, http.StatusOK, job)
+}
+
+// GeneratePOIEmbeddings godoc
+// @Summary      Generate Embeddings for POIs
+// @Description  Queues a background job that generates embeddings for every POI without one. Poll /admin/jobs/{jobID} for progress.
+// @Tags         POI
+// @Produce      json
+// @Success      202 {object} types.Job
+// @Failure      401 {object} types.Response "Authentication required"
+// @Failure      500 {object} types.Response "Internal Server Error"
+// @Security     BearerAuth
+// @Router       /pois/embeddings/generate [post]
+func (h *HandlerImpl) GeneratePOIEmbeddings(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("JobsHandler").Start(r.Context(), "GeneratePOIEmbeddings")
+	defer span.End()
+
+	userID := api.Requester(auth.GetUserIDFromContext(ctx))
+	if userID == nil {
+		span.SetStatus(codes.Error, "Unauthorized")
+		api.ErrorResponse(w, r, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	job, err := h.service.Enqueue(ctx, types.EnqueueJobRequest{Kind: types.JobKindPOIEmbedding}, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to enqueue job")
+		api.WriteServiceError(w, r, err, "Failed to queue embedding generation")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Job enqueued")
+	api.WriteJSONResponse(w, r, http.StatusAccepted, job)
+}
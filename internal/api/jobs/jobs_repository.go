@@ -0,0 +1,285 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Repository = (*RepositoryImpl)(nil)
+
+// Repository defines persistence for background jobs.
+type Repository interface {
+	// Enqueue queues a job. If the same kind and target is already pending or
+	// running, that job is returned instead of a new one.
+	Enqueue(ctx context.Context, kind string, targetID, requestedBy *uuid.UUID, maxAttempts int) (*types.Job, error)
+	// GetJob returns a job by ID.
+	GetJob(ctx context.Context, jobID uuid.UUID) (*types.Job, error)
+	// ListJobs returns the most recent jobs matching filter.
+	ListJobs(ctx context.Context, filter types.JobFilter) ([]types.Job, *types.Cursor, error)
+	// Claim leases the next due job of one of kinds to workerID, or returns nil if
+	// there is nothing to do. Running jobs whose lease has expired are claimed
+	// again. Safe to call from several workers and processes.
+	Claim(ctx context.Context, workerID string, kinds []string, lease time.Duration) (*types.Job, error)
+	// Heartbeat records progress and extends the lease. Returns ErrNotFound if
+	// workerID no longer holds the job.
+	Heartbeat(ctx context.Context, jobID uuid.UUID, workerID string, progress types.JobProgress, lease time.Duration) error
+	// Complete marks a job as completed.
+	Complete(ctx context.Context, jobID uuid.UUID, workerID string, progress types.JobProgress) error
+	// Retry puts a job back in the queue to run again at runAfter.
+	Retry(ctx context.Context, jobID uuid.UUID, workerID string, reason string, runAfter time.Time) error
+	// Fail marks a job as permanently failed.
+	Fail(ctx context.Context, jobID uuid.UUID, workerID string, reason string) error
+}
+
+type RepositoryImpl struct {
+	logger *slog.Logger
+	pgpool *pgxpool.Pool
+}
+
+func NewRepository(pgxpool *pgxpool.Pool, logger *slog.Logger) *RepositoryImpl {
+	return &RepositoryImpl{
+		logger: logger,
+		pgpool: pgxpool,
+	}
+}
+
+const jobColumns = `id, kind, target_id, status, attempts, max_attempts,
+		       progress_total, progress_done, progress_failed, last_error, run_after,
+		       locked_by, lease_expires_at, requested_by, created_at, started_at, completed_at, updated_at`
+
+func scanJob(row pgx.Row) (*types.Job, error) {
+	var j types.Job
+	err := row.Scan(&j.ID, &j.Kind, &j.TargetID, &j.Status, &j.Attempts, &j.MaxAttempts,
+		&j.Progress.Total, &j.Progress.Done, &j.Progress.Failed, &j.LastError, &j.RunAfter,
+		&j.LockedBy, &j.LeaseExpiresAt, &j.RequestedBy, &j.CreatedAt, &j.StartedAt, &j.CompletedAt, &j.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// EnqueueTx queues a job for targetID inside the caller's transaction, so the job
+// only exists if the row it refers to was committed. Already queued jobs are left alone.
+func EnqueueTx(ctx context.Context, tx pgx.Tx, kind string, targetID uuid.UUID) error {
+	query := `
+		INSERT INTO background_jobs (kind, target_id)
+		VALUES ($1, $2)
+		ON CONFLICT (kind, target_id) WHERE status IN ('pending', 'running') DO NOTHING`
+	if _, err := tx.Exec(ctx, query, kind, targetID); err != nil {
+		return fmt.Errorf("failed to enqueue %s job: %w", kind, err)
+	}
+	return nil
+}
+
+// Enqueue implements Repository.
+func (r *RepositoryImpl) Enqueue(ctx context.Context, kind string, targetID, requestedBy *uuid.UUID, maxAttempts int) (*types.Job, error) {
+	ctx, span := otel.Tracer("JobsRepo").Start(ctx, "Enqueue", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.operation", "INSERT"),
+		attribute.String("db.sql.table", "background_jobs"),
+		attribute.String("job.kind", kind),
+	))
+	defer span.End()
+
+	query := `
+		INSERT INTO background_jobs (kind, target_id, requested_by, max_attempts)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (kind, target_id) WHERE status IN ('pending', 'running') DO NOTHING
+		RETURNING ` + jobColumns
+	job, err := scanJob(r.pgpool.QueryRow(ctx, query, kind, targetID, requestedBy, maxAttempts))
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Already queued; hand back the job that is in flight
+		query = `
+			SELECT ` + jobColumns + ` FROM background_jobs
+			WHERE kind = $1 AND target_id IS NOT DISTINCT FROM $2 AND status IN ('pending', 'running')`
+		job, err = scanJob(r.pgpool.QueryRow(ctx, query, kind, targetID))
+	}
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" { // foreign_key_violation on requested_by
+			return nil, fmt.Errorf("requesting user not found: %w", types.ErrNotFound)
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB insert failed")
+		return nil, fmt.Errorf("database error enqueueing job: %w", err)
+	}
+
+	span.SetAttributes(attribute.String("job.id", job.ID.String()))
+	span.SetStatus(codes.Ok, "Job enqueued")
+	return job, nil
+}
+
+// GetJob implements Repository.
+func (r *RepositoryImpl) GetJob(ctx context.Context, jobID uuid.UUID) (*types.Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM background_jobs WHERE id = $1`
+	job, err := scanJob(r.pgpool.QueryRow(ctx, query, jobID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("job not found: %w", types.ErrNotFound)
+		}
+		return nil, fmt.Errorf("database error fetching job: %w", err)
+	}
+	return job, nil
+}
+
+// ListJobs implements Repository.
+func (r *RepositoryImpl) ListJobs(ctx context.Context, filter types.JobFilter) ([]types.Job, *types.Cursor, error) {
+	ctx, span := otel.Tracer("JobsRepo").Start(ctx, "ListJobs", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.sql.table", "background_jobs"),
+	))
+	defer span.End()
+
+	args := []interface{}{filter.Kind, filter.Status, filter.Page.FetchLimit()}
+	after := ""
+	if c := filter.Page.Cursor; c != nil {
+		args = append(args, c.Time, c.ID)
+		after = "AND (created_at, id) < ($4, $5)"
+	}
+	query := `
+		SELECT ` + jobColumns + ` FROM background_jobs
+		WHERE ($1 = '' OR kind = $1) AND ($2 = '' OR status = $2) ` + after + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3`
+	rows, err := r.pgpool.Query(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, nil, fmt.Errorf("database error listing jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []types.Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			span.RecordError(err)
+			return nil, nil, fmt.Errorf("failed to scan job row: %w", err)
+		}
+		jobs = append(jobs, *job)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, nil, fmt.Errorf("error iterating job rows: %w", err)
+	}
+
+	jobs, next := types.NextPage(jobs, filter.Page.Limit, func(j types.Job) types.Cursor {
+		return types.Cursor{Time: &j.CreatedAt, ID: j.ID}
+	})
+	span.SetAttributes(attribute.Int("results.count", len(jobs)))
+	span.SetStatus(codes.Ok, "Jobs listed")
+	return jobs, next, nil
+}
+
+// Claim implements Repository. A reclaimed job counts as a new attempt, so a job
+// that keeps crashing its worker still runs out of attempts.
+func (r *RepositoryImpl) Claim(ctx context.Context, workerID string, kinds []string, lease time.Duration) (*types.Job, error) {
+	query := `
+		UPDATE background_jobs SET
+			status = 'running',
+			attempts = attempts + 1,
+			locked_by = $1,
+			lease_expires_at = NOW() + make_interval(secs => $2),
+			started_at = COALESCE(started_at, NOW()),
+			last_error = CASE WHEN status = 'running' THEN 'lease expired' ELSE last_error END
+		WHERE id = (
+			SELECT id FROM background_jobs
+			WHERE kind = ANY($3)
+			  AND ((status = 'pending' AND run_after <= NOW())
+			    OR (status = 'running' AND lease_expires_at < NOW()))
+			ORDER BY run_after
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + jobColumns
+	job, err := scanJob(r.pgpool.QueryRow(ctx, query, workerID, lease.Seconds(), kinds))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("database error claiming job: %w", err)
+	}
+	return job, nil
+}
+
+// Heartbeat implements Repository.
+func (r *RepositoryImpl) Heartbeat(ctx context.Context, jobID uuid.UUID, workerID string, progress types.JobProgress, lease time.Duration) error {
+	query := `
+		UPDATE background_jobs
+		SET progress_total = $3, progress_done = $4, progress_failed = $5,
+		    lease_expires_at = NOW() + make_interval(secs => $6)
+		WHERE id = $1 AND locked_by = $2 AND status = 'running'`
+	tag, err := r.pgpool.Exec(ctx, query, jobID, workerID, progress.Total, progress.Done, progress.Failed, lease.Seconds())
+	if err != nil {
+		return fmt.Errorf("database error updating job progress: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("job lease lost: %w", types.ErrNotFound)
+	}
+	return nil
+}
+
+// Complete implements Repository.
+func (r *RepositoryImpl) Complete(ctx context.Context, jobID uuid.UUID, workerID string, progress types.JobProgress) error {
+	query := `
+		UPDATE background_jobs
+		SET status = 'completed', completed_at = NOW(), locked_by = NULL, lease_expires_at = NULL,
+		    progress_total = $3, progress_done = $4, progress_failed = $5
+		WHERE id = $1 AND locked_by = $2 AND status = 'running'`
+	tag, err := r.pgpool.Exec(ctx, query, jobID, workerID, progress.Total, progress.Done, progress.Failed)
+	if err != nil {
+		return fmt.Errorf("database error completing job: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("job lease lost: %w", types.ErrNotFound)
+	}
+	return nil
+}
+
+// Retry implements Repository.
+func (r *RepositoryImpl) Retry(ctx context.Context, jobID uuid.UUID, workerID string, reason string, runAfter time.Time) error {
+	query := `
+		UPDATE background_jobs
+		SET status = 'pending', last_error = $3, run_after = $4, locked_by = NULL, lease_expires_at = NULL
+		WHERE id = $1 AND locked_by = $2 AND status = 'running'`
+	tag, err := r.pgpool.Exec(ctx, query, jobID, workerID, reason, runAfter)
+	if err != nil {
+		return fmt.Errorf("database error rescheduling job: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("job lease lost: %w", types.ErrNotFound)
+	}
+	return nil
+}
+
+// Fail implements Repository.
+func (r *RepositoryImpl) Fail(ctx context.Context, jobID uuid.UUID, workerID string, reason string) error {
+	query := `
+		UPDATE background_jobs
+		SET status = 'failed', last_error = $3, completed_at = NOW(), locked_by = NULL, lease_expires_at = NULL
+		WHERE id = $1 AND locked_by = $2 AND status = 'running'`
+	tag, err := r.pgpool.Exec(ctx, query, jobID, workerID, reason)
+	if err != nil {
+		return fmt.Errorf("database error failing job: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("job lease lost: %w", types.ErrNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,348 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/config"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Service = (*ServiceImpl)(nil)
+
+const (
+	defaultWorkers       = 2
+	defaultPollInterval  = 5 * time.Second
+	defaultLeaseDuration = 2 * time.Minute
+	defaultMaxAttempts   = 5
+	defaultRetryBackoff  = 30 * time.Second
+	maxRetryBackoff      = time.Hour
+	defaultListLimit     = 50
+	maxListLimit         = 200
+)
+
+// ProgressFunc reports how far a job has got. The latest value is persisted with
+// the next lease heartbeat and when the job finishes.
+type ProgressFunc func(progress types.JobProgress)
+
+// JobFunc does the work for one job. Returning an error wrapping ErrNotFound or
+// ErrBadRequest fails the job straight away; any other error is retried with backoff.
+// ctx is cancelled if the worker loses its lease or the process shuts down.
+type JobFunc func(ctx context.Context, job *types.Job, report ProgressFunc) error
+
+// Service defines the business logic for background jobs.
+type Service interface {
+	// Enqueue queues a job of a registered kind, or returns the one already in flight.
+	Enqueue(ctx context.Context, req types.EnqueueJobRequest, requestedBy *uuid.UUID) (*types.Job, error)
+	// GetJob returns a job with its progress.
+	GetJob(ctx context.Context, jobID uuid.UUID) (*types.Job, error)
+	// ListJobs returns the most recent jobs matching filter.
+	ListJobs(ctx context.Context, filter types.JobFilter) ([]types.Job, *types.Cursor, error)
+	// Run starts the workers and schedules until ctx is cancelled.
+	Run(ctx context.Context)
+}
+
+type schedule struct {
+	kind  string
+	every time.Duration
+}
+
+type ServiceImpl struct {
+	logger    *slog.Logger
+	repo      Repository
+	cfg       config.JobsConfig
+	kinds     map[string]JobFunc
+	kindNames []string
+	schedules []schedule
+	workerID  string
+	now       func() time.Time
+}
+
+// NewService creates a new job service. Zero config values fall back to defaults.
+func NewService(repo Repository, cfg config.JobsConfig, logger *slog.Logger) *ServiceImpl {
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultWorkers
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if cfg.LeaseDuration <= 0 {
+		cfg.LeaseDuration = defaultLeaseDuration
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = defaultRetryBackoff
+	}
+	host, _ := os.Hostname()
+	return &ServiceImpl{
+		logger:   logger,
+		repo:     repo,
+		cfg:      cfg,
+		kinds:    make(map[string]JobFunc),
+		workerID: fmt.Sprintf("%s-%d", host, os.Getpid()),
+		now:      time.Now,
+	}
+}
+
+// Register installs the function that runs jobs of kind. Call before Run. Jobs
+// of kinds no worker has registered stay queued.
+func (s *ServiceImpl) Register(kind string, fn JobFunc) {
+	if _, ok := s.kinds[kind]; !ok {
+		s.kindNames = append(s.kindNames, kind)
+	}
+	s.kinds[kind] = fn
+}
+
+// Schedule enqueues a job of kind without a target every interval while Run is
+// active. Enqueueing is idempotent, so a slow job is never queued twice. Call before Run.
+func (s *ServiceImpl) Schedule(kind string, every time.Duration) {
+	s.schedules = append(s.schedules, schedule{kind: kind, every: every})
+}
+
+// Enqueue implements Service.
+func (s *ServiceImpl) Enqueue(ctx context.Context, req types.EnqueueJobRequest, requestedBy *uuid.UUID) (*types.Job, error) {
+	ctx, span := otel.Tracer("JobsService").Start(ctx, "Enqueue", trace.WithAttributes(
+		attribute.String("job.kind", req.Kind),
+	))
+	defer span.End()
+
+	if _, ok := s.kinds[req.Kind]; !ok {
+		span.SetStatus(codes.Error, "Unknown job kind")
+		return nil, fmt.Errorf("unknown job kind %q: %w", req.Kind, types.ErrBadRequest)
+	}
+
+	job, err := s.repo.Enqueue(ctx, req.Kind, req.TargetID, requestedBy, s.cfg.MaxAttempts)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to enqueue job", slog.String("kind", req.Kind), slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to enqueue job")
+		return nil, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+
+	span.SetAttributes(attribute.String("job.id", job.ID.String()))
+	span.SetStatus(codes.Ok, "Job enqueued")
+	return job, nil
+}
+
+// GetJob implements Service.
+func (s *ServiceImpl) GetJob(ctx context.Context, jobID uuid.UUID) (*types.Job, error) {
+	job, err := s.repo.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return job, nil
+}
+
+// ListJobs implements Service.
+func (s *ServiceImpl) ListJobs(ctx context.Context, filter types.JobFilter) ([]types.Job, *types.Cursor, error) {
+	switch filter.Status {
+	case "", types.JobPending, types.JobRunning, types.JobCompleted, types.JobFailed:
+	default:
+		return nil, nil, fmt.Errorf("unknown job status %q: %w", filter.Status, types.ErrBadRequest)
+	}
+	filter.Page = filter.Page.Clamp(defaultListLimit, maxListLimit)
+
+	jobs, next, err := s.repo.ListJobs(ctx, filter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	return jobs, next, nil
+}
+
+// Run implements Service.
+func (s *ServiceImpl) Run(ctx context.Context) {
+	l := s.logger.With(slog.String("worker", "jobs"))
+	if len(s.kindNames) == 0 {
+		l.WarnContext(ctx, "No job kinds registered, job workers not started")
+		return
+	}
+	l.InfoContext(ctx, "Job workers started",
+		slog.Any("kinds", s.kindNames),
+		slog.Int("workers", s.cfg.Workers),
+		slog.Duration("lease", s.cfg.LeaseDuration))
+
+	var wg sync.WaitGroup
+	for _, sch := range s.schedules {
+		wg.Add(1)
+		go func(sch schedule) {
+			defer wg.Done()
+			s.runSchedule(ctx, sch)
+		}(sch)
+	}
+	for i := 0; i < s.cfg.Workers; i++ {
+		wg.Add(1)
+		go func(workerID string) {
+			defer wg.Done()
+			s.runWorker(ctx, workerID)
+		}(fmt.Sprintf("%s-%d", s.workerID, i))
+	}
+	wg.Wait()
+	l.Info("Job workers stopped")
+}
+
+func (s *ServiceImpl) runSchedule(ctx context.Context, sch schedule) {
+	ticker := time.NewTicker(sch.every)
+	defer ticker.Stop()
+	for {
+		if _, err := s.repo.Enqueue(ctx, sch.kind, nil, nil, s.cfg.MaxAttempts); err != nil && ctx.Err() == nil {
+			s.logger.ErrorContext(ctx, "Failed to enqueue scheduled job", slog.String("kind", sch.kind), slog.Any("error", err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *ServiceImpl) runWorker(ctx context.Context, workerID string) {
+	for {
+		worked, err := s.runNext(ctx, workerID)
+		if err != nil && ctx.Err() == nil {
+			s.logger.ErrorContext(ctx, "Job worker error", slog.String("worker_id", workerID), slog.Any("error", err))
+		}
+		if worked && ctx.Err() == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.cfg.PollInterval):
+		}
+	}
+}
+
+// progressTracker holds the latest progress reported by a running job.
+type progressTracker struct {
+	mu       sync.Mutex
+	progress types.JobProgress
+}
+
+func (p *progressTracker) set(progress types.JobProgress) {
+	p.mu.Lock()
+	p.progress = progress
+	p.mu.Unlock()
+}
+
+func (p *progressTracker) get() types.JobProgress {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.progress
+}
+
+// runNext claims and runs one job. It reports whether a job was claimed.
+func (s *ServiceImpl) runNext(ctx context.Context, workerID string) (bool, error) {
+	job, err := s.repo.Claim(ctx, workerID, s.kindNames, s.cfg.LeaseDuration)
+	if err != nil {
+		return false, err
+	}
+	if job == nil {
+		return false, nil
+	}
+
+	ctx, span := otel.Tracer("JobsService").Start(ctx, "RunJob", trace.WithAttributes(
+		attribute.String("job.id", job.ID.String()),
+		attribute.String("job.kind", job.Kind),
+		attribute.Int("job.attempt", job.Attempts),
+	))
+	defer span.End()
+	l := s.logger.With(slog.String("job_id", job.ID.String()), slog.String("kind", job.Kind), slog.Int("attempt", job.Attempts))
+
+	fn := s.kinds[job.Kind]
+	if job.Attempts > job.MaxAttempts {
+		// Reclaimed after its last attempt's worker died
+		span.SetStatus(codes.Error, "Attempts exhausted")
+		return true, s.repo.Fail(ctx, job.ID, workerID, fmt.Sprintf("gave up after %d attempts", job.MaxAttempts))
+	}
+
+	tracker := &progressTracker{progress: job.Progress}
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		s.heartbeat(jobCtx, cancel, job.ID, workerID, tracker, l)
+	}()
+
+	l.InfoContext(ctx, "Job started")
+	runErr := fn(jobCtx, job, tracker.set)
+	leaseLost := jobCtx.Err() != nil && ctx.Err() == nil
+	cancel()
+	<-heartbeatDone
+
+	// Record the outcome even when shutting down, so the job is not left locked
+	// until its lease runs out
+	finishCtx := context.WithoutCancel(ctx)
+	switch {
+	case leaseLost:
+		l.WarnContext(ctx, "Job lease lost, abandoning")
+		span.SetStatus(codes.Error, "Lease lost")
+		return true, nil
+	case runErr == nil:
+		l.InfoContext(ctx, "Job completed", slog.Any("progress", tracker.get()))
+		span.SetStatus(codes.Ok, "Job completed")
+		return true, s.repo.Complete(finishCtx, job.ID, workerID, tracker.get())
+	case ctx.Err() != nil:
+		l.InfoContext(ctx, "Job interrupted by shutdown, requeueing")
+		return true, s.repo.Retry(finishCtx, job.ID, workerID, "interrupted by shutdown", s.now())
+	case isPermanent(runErr) || job.Attempts >= job.MaxAttempts:
+		l.ErrorContext(ctx, "Job failed", slog.Any("error", runErr))
+		span.RecordError(runErr)
+		span.SetStatus(codes.Error, "Job failed")
+		return true, s.repo.Fail(finishCtx, job.ID, workerID, runErr.Error())
+	default:
+		runAfter := s.now().Add(s.backoff(job.Attempts))
+		l.WarnContext(ctx, "Job failed, will retry", slog.Any("error", runErr), slog.Time("run_after", runAfter))
+		span.RecordError(runErr)
+		span.SetStatus(codes.Error, "Job will retry")
+		return true, s.repo.Retry(finishCtx, job.ID, workerID, runErr.Error(), runAfter)
+	}
+}
+
+// heartbeat extends the lease and saves progress until ctx is done. It cancels
+// the job if another worker has taken the lease over.
+func (s *ServiceImpl) heartbeat(ctx context.Context, cancel context.CancelFunc, jobID uuid.UUID, workerID string, tracker *progressTracker, l *slog.Logger) {
+	ticker := time.NewTicker(s.cfg.LeaseDuration / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		err := s.repo.Heartbeat(ctx, jobID, workerID, tracker.get(), s.cfg.LeaseDuration)
+		if errors.Is(err, types.ErrNotFound) {
+			cancel()
+			return
+		}
+		if err != nil && ctx.Err() == nil {
+			l.WarnContext(ctx, "Job heartbeat failed", slog.Any("error", err))
+		}
+	}
+}
+
+// backoff doubles the retry delay with every attempt, up to an hour.
+func (s *ServiceImpl) backoff(attempt int) time.Duration {
+	delay := s.cfg.RetryBackoff
+	for i := 1; i < attempt && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryBackoff)
+}
+
+func isPermanent(err error) bool {
+	return errors.Is(err, types.ErrNotFound) || errors.Is(err, types.ErrBadRequest)
+}
@@ -0,0 +1,216 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/config"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+// MockJobsRepository is a mock implementation of Repository
+type MockJobsRepository struct {
+	mock.Mock
+}
+
+func (m *MockJobsRepository) Enqueue(ctx context.Context, kind string, targetID, requestedBy *uuid.UUID, maxAttempts int) (*types.Job, error) {
+	args := m.Called(ctx, kind, targetID, requestedBy, maxAttempts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Job), args.Error(1)
+}
+
+func (m *MockJobsRepository) GetJob(ctx context.Context, jobID uuid.UUID) (*types.Job, error) {
+	args := m.Called(ctx, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Job), args.Error(1)
+}
+
+func (m *MockJobsRepository) ListJobs(ctx context.Context, filter types.JobFilter) ([]types.Job, *types.Cursor, error) {
+	args := m.Called(ctx, filter)
+	next, _ := args.Get(1).(*types.Cursor)
+	if args.Get(0) == nil {
+		return nil, next, args.Error(2)
+	}
+	return args.Get(0).([]types.Job), next, args.Error(2)
+}
+
+func (m *MockJobsRepository) Claim(ctx context.Context, workerID string, kinds []string, lease time.Duration) (*types.Job, error) {
+	args := m.Called(ctx, workerID, kinds, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Job), args.Error(1)
+}
+
+func (m *MockJobsRepository) Heartbeat(ctx context.Context, jobID uuid.UUID, workerID string, progress types.JobProgress, lease time.Duration) error {
+	return m.Called(ctx, jobID, workerID, progress, lease).Error(0)
+}
+
+func (m *MockJobsRepository) Complete(ctx context.Context, jobID uuid.UUID, workerID string, progress types.JobProgress) error {
+	return m.Called(ctx, jobID, workerID, progress).Error(0)
+}
+
+func (m *MockJobsRepository) Retry(ctx context.Context, jobID uuid.UUID, workerID string, reason string, runAfter time.Time) error {
+	return m.Called(ctx, jobID, workerID, reason, runAfter).Error(0)
+}
+
+func (m *MockJobsRepository) Fail(ctx context.Context, jobID uuid.UUID, workerID string, reason string) error {
+	return m.Called(ctx, jobID, workerID, reason).Error(0)
+}
+
+const testKind = "test_job"
+
+// Helper to setup service with mock repository and a fixed clock
+func setupJobsServiceTest(fn JobFunc) (*ServiceImpl, *MockJobsRepository, time.Time) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	mockRepo := new(MockJobsRepository)
+	service := NewService(mockRepo, config.JobsConfig{RetryBackoff: time.Minute}, logger)
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	service.Register(testKind, fn)
+	return service, mockRepo, now
+}
+
+func runningJob(attempts int) *types.Job {
+	return &types.Job{ID: uuid.New(), Kind: testKind, Status: types.JobRunning, Attempts: attempts, MaxAttempts: 3}
+}
+
+func TestServiceImpl_RunNext(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("completes with the last reported progress", func(t *testing.T) {
+		service, mockRepo, _ := setupJobsServiceTest(func(_ context.Context, _ *types.Job, report ProgressFunc) error {
+			report(types.JobProgress{Total: 2, Done: 1})
+			report(types.JobProgress{Total: 2, Done: 2})
+			return nil
+		})
+		job := runningJob(1)
+		mockRepo.On("Claim", mock.Anything, "w1", []string{testKind}, service.cfg.LeaseDuration).Return(job, nil).Once()
+		mockRepo.On("Complete", mock.Anything, job.ID, "w1", types.JobProgress{Total: 2, Done: 2}).Return(nil).Once()
+
+		worked, err := service.runNext(ctx, "w1")
+
+		require.NoError(t, err)
+		assert.True(t, worked)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("retries transient errors with exponential backoff", func(t *testing.T) {
+		service, mockRepo, now := setupJobsServiceTest(func(context.Context, *types.Job, ProgressFunc) error {
+			return errors.New("embedding API timeout")
+		})
+		job := runningJob(2)
+		mockRepo.On("Claim", mock.Anything, "w1", mock.Anything, mock.Anything).Return(job, nil).Once()
+		mockRepo.On("Retry", mock.Anything, job.ID, "w1", "embedding API timeout", now.Add(2*time.Minute)).Return(nil).Once()
+
+		worked, err := service.runNext(ctx, "w1")
+
+		require.NoError(t, err)
+		assert.True(t, worked)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("fails permanently on not found", func(t *testing.T) {
+		service, mockRepo, _ := setupJobsServiceTest(func(context.Context, *types.Job, ProgressFunc) error {
+			return fmt.Errorf("POI gone: %w", types.ErrNotFound)
+		})
+		job := runningJob(1)
+		mockRepo.On("Claim", mock.Anything, "w1", mock.Anything, mock.Anything).Return(job, nil).Once()
+		mockRepo.On("Fail", mock.Anything, job.ID, "w1", mock.AnythingOfType("string")).Return(nil).Once()
+
+		_, err := service.runNext(ctx, "w1")
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "Retry", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("fails on the last attempt", func(t *testing.T) {
+		service, mockRepo, _ := setupJobsServiceTest(func(context.Context, *types.Job, ProgressFunc) error {
+			return errors.New("still broken")
+		})
+		job := runningJob(3)
+		mockRepo.On("Claim", mock.Anything, "w1", mock.Anything, mock.Anything).Return(job, nil).Once()
+		mockRepo.On("Fail", mock.Anything, job.ID, "w1", "still broken").Return(nil).Once()
+
+		_, err := service.runNext(ctx, "w1")
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("gives up on a reclaimed job past its attempts without running it", func(t *testing.T) {
+		ran := false
+		service, mockRepo, _ := setupJobsServiceTest(func(context.Context, *types.Job, ProgressFunc) error {
+			ran = true
+			return nil
+		})
+		job := runningJob(4)
+		mockRepo.On("Claim", mock.Anything, "w1", mock.Anything, mock.Anything).Return(job, nil).Once()
+		mockRepo.On("Fail", mock.Anything, job.ID, "w1", "gave up after 3 attempts").Return(nil).Once()
+
+		_, err := service.runNext(ctx, "w1")
+
+		require.NoError(t, err)
+		assert.False(t, ran)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("nothing due", func(t *testing.T) {
+		service, mockRepo, _ := setupJobsServiceTest(nil)
+		mockRepo.On("Claim", mock.Anything, "w1", mock.Anything, mock.Anything).Return(nil, nil).Once()
+
+		worked, err := service.runNext(ctx, "w1")
+
+		require.NoError(t, err)
+		assert.False(t, worked)
+	})
+}
+
+func TestServiceImpl_Backoff(t *testing.T) {
+	service, _, _ := setupJobsServiceTest(nil)
+
+	assert.Equal(t, time.Minute, service.backoff(1))
+	assert.Equal(t, 4*time.Minute, service.backoff(3))
+	assert.Equal(t, time.Hour, service.backoff(20))
+}
+
+func TestServiceImpl_Enqueue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("rejects unregistered kinds", func(t *testing.T) {
+		service, mockRepo, _ := setupJobsServiceTest(nil)
+
+		_, err := service.Enqueue(ctx, types.EnqueueJobRequest{Kind: "nope"}, nil)
+
+		assert.ErrorIs(t, err, types.ErrBadRequest)
+		mockRepo.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("uses the configured attempt limit", func(t *testing.T) {
+		service, mockRepo, _ := setupJobsServiceTest(nil)
+		targetID := uuid.New()
+		job := &types.Job{ID: uuid.New(), Kind: testKind, TargetID: &targetID}
+		mockRepo.On("Enqueue", mock.Anything, testKind, &targetID, (*uuid.UUID)(nil), defaultMaxAttempts).Return(job, nil).Once()
+
+		got, err := service.Enqueue(ctx, types.EnqueueJobRequest{Kind: testKind, TargetID: &targetID}, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, job, got)
+		mockRepo.AssertExpectations(t)
+	})
+}
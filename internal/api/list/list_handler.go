@@ -24,6 +24,8 @@ type Handler interface {
 	GetListDetailsHandler(w http.ResponseWriter, r *http.Request)
 	UpdateListDetailsHandler(w http.ResponseWriter, r *http.Request)
 	DeleteListHandler(w http.ResponseWriter, r *http.Request)
+	SaveListHandler(w http.ResponseWriter, r *http.Request)
+	UnsaveListHandler(w http.ResponseWriter, r *http.Request)
 	AddPOIListItemHandler(w http.ResponseWriter, r *http.Request)
 	UpdatePOIListItemHandler(w http.ResponseWriter, r *http.Request)
 	RemovePOIListItemHandler(w http.ResponseWriter, r *http.Request)
@@ -333,6 +335,118 @@ func (h *HandlerImpl) DeleteListHandler(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *HandlerImpl) SaveListHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("ItineraryListHandler").Start(r.Context(), "SaveList")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "SaveListHandler"))
+
+	userIDStr, ok := auth.GetUserIDFromContext(ctx)
+	if !ok || userIDStr == "" {
+		l.ErrorContext(ctx, "User ID not found in context")
+		span.SetStatus(codes.Error, "Unauthorized - User ID missing")
+		api.ErrorResponse(w, r, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		l.ErrorContext(ctx, "Invalid user ID format", slog.String("userID_str", userIDStr), slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Invalid User ID format")
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid user ID format")
+		return
+	}
+	span.SetAttributes(attribute.String("user.id", userID.String()))
+
+	listIDStr := chi.URLParam(r, "listID")
+	listID, err := uuid.Parse(listIDStr)
+	if err != nil {
+		l.ErrorContext(ctx, "Invalid list ID format", slog.String("listID_str", listIDStr), slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Invalid List ID format")
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid list ID format")
+		return
+	}
+	span.SetAttributes(attribute.String("list.id", listID.String()))
+
+	l.DebugContext(ctx, "Attempting to save list")
+	err = h.service.SaveList(ctx, listID, userID)
+	if err != nil {
+		l.ErrorContext(ctx, "Service failed to save list", slog.Any("error", err))
+		span.RecordError(err)
+		if strings.Contains(err.Error(), "not found") {
+			span.SetStatus(codes.Error, "List not found")
+			api.ErrorResponse(w, r, http.StatusNotFound, "List not found")
+		} else if strings.Contains(err.Error(), "access denied") {
+			span.SetStatus(codes.Error, "Forbidden")
+			api.ErrorResponse(w, r, http.StatusForbidden, "This list is not public")
+		} else if strings.Contains(err.Error(), "your own list") {
+			span.SetStatus(codes.Error, "Own list")
+			api.ErrorResponse(w, r, http.StatusBadRequest, "You cannot save your own list")
+		} else {
+			span.SetStatus(codes.Error, "Failed to save list")
+			api.ErrorResponse(w, r, http.StatusInternalServerError, "Failed to save list: "+err.Error())
+		}
+		return
+	}
+
+	l.InfoContext(ctx, "List saved successfully")
+	span.SetStatus(codes.Ok, "List saved")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *HandlerImpl) UnsaveListHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("ItineraryListHandler").Start(r.Context(), "UnsaveList")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "UnsaveListHandler"))
+
+	userIDStr, ok := auth.GetUserIDFromContext(ctx)
+	if !ok || userIDStr == "" {
+		l.ErrorContext(ctx, "User ID not found in context")
+		span.SetStatus(codes.Error, "Unauthorized - User ID missing")
+		api.ErrorResponse(w, r, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		l.ErrorContext(ctx, "Invalid user ID format", slog.String("userID_str", userIDStr), slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Invalid User ID format")
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid user ID format")
+		return
+	}
+	span.SetAttributes(attribute.String("user.id", userID.String()))
+
+	listIDStr := chi.URLParam(r, "listID")
+	listID, err := uuid.Parse(listIDStr)
+	if err != nil {
+		l.ErrorContext(ctx, "Invalid list ID format", slog.String("listID_str", listIDStr), slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Invalid List ID format")
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid list ID format")
+		return
+	}
+	span.SetAttributes(attribute.String("list.id", listID.String()))
+
+	l.DebugContext(ctx, "Attempting to unsave list")
+	err = h.service.UnsaveList(ctx, listID, userID)
+	if err != nil {
+		l.ErrorContext(ctx, "Service failed to unsave list", slog.Any("error", err))
+		span.RecordError(err)
+		if strings.Contains(err.Error(), "not found") {
+			span.SetStatus(codes.Error, "Saved list not found")
+			api.ErrorResponse(w, r, http.StatusNotFound, "List not saved")
+		} else {
+			span.SetStatus(codes.Error, "Failed to unsave list")
+			api.ErrorResponse(w, r, http.StatusInternalServerError, "Failed to unsave list: "+err.Error())
+		}
+		return
+	}
+
+	l.InfoContext(ctx, "List unsaved successfully")
+	span.SetStatus(codes.Ok, "List unsaved")
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (h *HandlerImpl) AddPOIListItemHandler(w http.ResponseWriter, r *http.Request) {
 	ctx, span := otel.Tracer("ItineraryListHandler").Start(r.Context(), "AddPOIListItem")
 	defer span.End()
@@ -577,8 +691,15 @@ func (h *HandlerImpl) GetUserListsHandler(w http.ResponseWriter, r *http.Request
 	// If listType is empty, isItinerary defaults to false (collections)
 	span.SetAttributes(attribute.Bool("filter.is_itinerary", isItinerary))
 
+	page, err := api.ParsePageRequest(r)
+	if err != nil {
+		span.SetStatus(codes.Error, "Invalid cursor")
+		api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	l.DebugContext(ctx, "Attempting to get user lists", slog.Bool("is_itinerary", isItinerary))
-	lists, err := h.service.GetUserLists(ctx, userID, isItinerary)
+	lists, next, err := h.service.GetUserLists(ctx, userID, isItinerary, page)
 	if err != nil {
 		l.ErrorContext(ctx, "Service failed to get user lists", slog.Any("error", err))
 		span.RecordError(err)
@@ -589,5 +710,6 @@ func (h *HandlerImpl) GetUserListsHandler(w http.ResponseWriter, r *http.Request
 
 	l.InfoContext(ctx, "User lists fetched successfully", slog.Int("count", len(lists)))
 	span.SetStatus(codes.Ok, "User lists fetched")
+	api.SetPageLinks(w, r, next)
 	api.WriteJSONResponse(w, r, http.StatusOK, lists)
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/feedback"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -33,7 +34,13 @@ type Repository interface {
 	UpdateListItem(ctx context.Context, item types.ListItem) error
 	DeleteListItem(ctx context.Context, listID, poiID uuid.UUID) error // Adjusted signature
 	DeleteList(ctx context.Context, listID uuid.UUID) error
-	GetUserLists(ctx context.Context, userID uuid.UUID, isItinerary bool) ([]*types.List, error)
+	// GetUserLists returns a page of the user's lists, newest first, and the
+	// cursor of the next page.
+	GetUserLists(ctx context.Context, userID uuid.UUID, isItinerary bool, page types.PageRequest) ([]*types.List, *types.Cursor, error)
+	// SaveList records that the user saved the list, reporting false when
+	// they already had.
+	SaveList(ctx context.Context, userID, listID uuid.UUID) (bool, error)
+	UnsaveList(ctx context.Context, userID, listID uuid.UUID) error
 }
 
 func NewRepository(pgxpool *pgxpool.Pool, logger *slog.Logger) *RepositoryImpl {
@@ -172,8 +179,15 @@ func (r *RepositoryImpl) GetListItems(ctx context.Context, listID uuid.UUID) ([]
 	return items, nil
 }
 
-// AddListItem inserts a new item into the list_items table
+// AddListItem inserts a new item into the list_items table and records the
+// addition as feedback for the list's owner
 func (r *RepositoryImpl) AddListItem(ctx context.Context, item types.ListItem) error {
+	tx, err := r.pgpool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
         INSERT INTO list_items (
             list_id, poi_id, position, notes, day_number, time_slot, duration, created_at, updated_at
@@ -181,7 +195,7 @@ func (r *RepositoryImpl) AddListItem(ctx context.Context, item types.ListItem) e
             $1, $2, $3, $4, $5, $6, $7, $8, $9
         )
     `
-	_, err := r.pgpool.Exec(ctx, query,
+	_, err = tx.Exec(ctx, query,
 		item.ListID, item.PoiID, item.Position, item.Notes,
 		item.DayNumber, item.TimeSlot, item.Duration, item.CreatedAt, item.UpdatedAt,
 	)
@@ -189,6 +203,19 @@ func (r *RepositoryImpl) AddListItem(ctx context.Context, item types.ListItem) e
 		r.logger.ErrorContext(ctx, "Failed to add list item", slog.Any("error", err))
 		return fmt.Errorf("failed to add list item: %w", err)
 	}
+
+	var ownerID uuid.UUID
+	if err := tx.QueryRow(ctx, `SELECT user_id FROM lists WHERE id = $1`, item.ListID).Scan(&ownerID); err != nil {
+		return fmt.Errorf("failed to fetch list owner: %w", err)
+	}
+	poiID := item.PoiID
+	if err := feedback.RecordTx(ctx, tx, types.InteractionEvent{UserID: ownerID, POIID: &poiID, Kind: types.InteractionListAdd}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return nil
 }
 
@@ -220,6 +247,32 @@ func (r *RepositoryImpl) DeleteList(ctx context.Context, listID uuid.UUID) error
 	return nil
 }
 
+// SaveList inserts the user's save of a list into the saved_lists table; its
+// triggers keep the list's save_count
+func (r *RepositoryImpl) SaveList(ctx context.Context, userID, listID uuid.UUID) (bool, error) {
+	query := `INSERT INTO saved_lists (user_id, list_id) VALUES ($1, $2) ON CONFLICT (user_id, list_id) DO NOTHING`
+	result, err := r.pgpool.Exec(ctx, query, userID, listID)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to save list", slog.Any("error", err))
+		return false, fmt.Errorf("failed to save list: %w", err)
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// UnsaveList deletes the user's save of a list from the saved_lists table
+func (r *RepositoryImpl) UnsaveList(ctx context.Context, userID, listID uuid.UUID) error {
+	query := `DELETE FROM saved_lists WHERE user_id = $1 AND list_id = $2`
+	result, err := r.pgpool.Exec(ctx, query, userID, listID)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to unsave list", slog.Any("error", err))
+		return fmt.Errorf("failed to unsave list: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("no saved list found with ID %s", listID)
+	}
+	return nil
+}
+
 // UpdateList updates a list in the lists table
 func (r *RepositoryImpl) UpdateList(ctx context.Context, list types.List) error {
 	query := `
@@ -301,19 +354,26 @@ func (r *RepositoryImpl) UpdateListItem(ctx context.Context, item types.ListItem
 	return nil
 }
 
-// GetUserLists retrieves all lists for a user, optionally filtered by isItinerary
-func (r *RepositoryImpl) GetUserLists(ctx context.Context, userID uuid.UUID, isItinerary bool) ([]*types.List, error) {
-	query := `
+// GetUserLists retrieves a page of lists for a user, optionally filtered by isItinerary
+func (r *RepositoryImpl) GetUserLists(ctx context.Context, userID uuid.UUID, isItinerary bool, page types.PageRequest) ([]*types.List, *types.Cursor, error) {
+	args := []interface{}{userID, isItinerary, page.FetchLimit()}
+	after := ""
+	if page.Cursor != nil {
+		args = append(args, page.Cursor.Time, page.Cursor.ID)
+		after = "AND (created_at, id) < ($4, $5)"
+	}
+	query := fmt.Sprintf(`
         SELECT id, user_id, name, description, image_url, is_public, is_itinerary,
                parent_list_id, city_id, view_count, save_count, created_at, updated_at
         FROM lists
-        WHERE user_id = $1 AND is_itinerary = $2
-        ORDER BY created_at DESC
-    `
-	rows, err := r.pgpool.Query(ctx, query, userID, isItinerary)
+        WHERE user_id = $1 AND is_itinerary = $2 %s
+        ORDER BY created_at DESC, id DESC
+        LIMIT $3
+    `, after)
+	rows, err := r.pgpool.Query(ctx, query, args...)
 	if err != nil {
 		r.logger.ErrorContext(ctx, "Failed to get user lists", slog.Any("error", err))
-		return nil, fmt.Errorf("failed to get user lists: %w", err)
+		return nil, nil, fmt.Errorf("failed to get user lists: %w", err)
 	}
 	defer rows.Close()
 
@@ -326,13 +386,17 @@ func (r *RepositoryImpl) GetUserLists(ctx context.Context, userID uuid.UUID, isI
 		)
 		if err != nil {
 			r.logger.ErrorContext(ctx, "Failed to scan list", slog.Any("error", err))
-			return nil, fmt.Errorf("failed to scan list: %w", err)
+			return nil, nil, fmt.Errorf("failed to scan list: %w", err)
 		}
 		lists = append(lists, &list)
 	}
 	if err = rows.Err(); err != nil {
 		r.logger.ErrorContext(ctx, "Error iterating list rows", slog.Any("error", err))
-		return nil, fmt.Errorf("error iterating list rows: %w", err)
+		return nil, nil, fmt.Errorf("error iterating list rows: %w", err)
 	}
-	return lists, nil
+
+	lists, next := types.NextPage(lists, page.Limit, func(list *types.List) types.Cursor {
+		return types.Cursor{Time: &list.CreatedAt, ID: list.ID}
+	})
+	return lists, next, nil
 }
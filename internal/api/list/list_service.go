@@ -12,11 +12,19 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/analytics"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/audit"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
 )
 
 var _ Service = (*ServiceImpl)(nil)
 
+// Page sizes of a user's lists.
+const (
+	defaultListPageLimit = 20
+	maxListPageLimit     = 100
+)
+
 type Service interface {
 	CreateTopLevelList(ctx context.Context, userID uuid.UUID, name, description string, cityID *uuid.UUID, isItinerary, isPublic bool) (*types.List, error)
 	CreateItineraryForList(ctx context.Context, userID, parentListID uuid.UUID, name, description string, isPublic bool) (*types.List, error)
@@ -26,19 +34,26 @@ type Service interface {
 	AddPOIListItem(ctx context.Context, userID, listID, poiID uuid.UUID, params types.AddListItemRequest) (*types.ListItem, error)
 	UpdatePOIListItem(ctx context.Context, userID, listID, poiID uuid.UUID, params types.UpdateListItemRequest) (*types.ListItem, error)
 	RemovePOIListItem(ctx context.Context, userID, listID, poiID uuid.UUID) error
-	GetUserLists(ctx context.Context, userID uuid.UUID, isItinerary bool) ([]*types.List, error)
+	GetUserLists(ctx context.Context, userID uuid.UUID, isItinerary bool, page types.PageRequest) ([]*types.List, *types.Cursor, error)
+	// SaveList saves another user's public list to the user's saved lists.
+	SaveList(ctx context.Context, listID, userID uuid.UUID) error
+	UnsaveList(ctx context.Context, listID, userID uuid.UUID) error
 }
 
 type ServiceImpl struct {
 	logger         *slog.Logger
 	listRepository Repository
+	audit          audit.Recorder
+	analytics      analytics.Emitter
 }
 
 // NewServiceImpl creates a new instance of ServiceImpl
-func NewServiceImpl(repo Repository, logger *slog.Logger) *ServiceImpl {
+func NewServiceImpl(repo Repository, auditor audit.Recorder, emitter analytics.Emitter, logger *slog.Logger) *ServiceImpl {
 	return &ServiceImpl{
 		logger:         logger,
 		listRepository: repo,
+		audit:          auditor,
+		analytics:      emitter,
 	}
 }
 
@@ -188,6 +203,16 @@ func (s *ServiceImpl) GetListDetails(ctx context.Context, listID, userID uuid.UU
 		Items: items,
 	}
 
+	// Owners looking at their own lists are not counted as views
+	if list.UserID != userID {
+		s.analytics.Emit(ctx, types.AnalyticsEvent{
+			Name:   types.AnalyticsListViewed,
+			UserID: &userID,
+			ListID: &listID,
+			CityID: nilIfZero(list.CityID),
+		})
+	}
+
 	l.InfoContext(ctx, "List details fetched successfully",
 		slog.Int("itemCount", len(items)))
 	span.SetStatus(codes.Ok, "List details fetched")
@@ -223,6 +248,7 @@ func (s *ServiceImpl) UpdateListDetails(ctx context.Context, listID, userID uuid
 		span.SetStatus(codes.Error, "User does not own list")
 		return nil, fmt.Errorf("user does not own list")
 	}
+	before := list
 
 	// Update fields if provided
 	if params.Name != nil {
@@ -252,6 +278,15 @@ func (s *ServiceImpl) UpdateListDetails(ctx context.Context, listID, userID uuid
 		return nil, fmt.Errorf("failed to update list: %w", err)
 	}
 
+	s.audit.Record(ctx, types.AuditEntry{
+		ActorID:    &userID,
+		Action:     types.AuditActionListUpdated,
+		TargetType: types.AuditTargetList,
+		TargetID:   &listID,
+		Before:     before,
+		After:      list,
+	})
+
 	l.InfoContext(ctx, "List updated successfully")
 	span.SetStatus(codes.Ok, "List updated")
 	return &list, nil
@@ -296,11 +331,91 @@ func (s *ServiceImpl) DeleteUserList(ctx context.Context, listID, userID uuid.UU
 		return fmt.Errorf("failed to delete list: %w", err)
 	}
 
+	s.audit.Record(ctx, types.AuditEntry{
+		ActorID:    &userID,
+		Action:     types.AuditActionListDeleted,
+		TargetType: types.AuditTargetList,
+		TargetID:   &listID,
+		Before:     list,
+	})
+
 	l.InfoContext(ctx, "List deleted successfully")
 	span.SetStatus(codes.Ok, "List deleted")
 	return nil
 }
 
+// SaveList saves another user's public list
+func (s *ServiceImpl) SaveList(ctx context.Context, listID, userID uuid.UUID) error {
+	ctx, span := otel.Tracer("ItineraryListService").Start(ctx, "SaveList", trace.WithAttributes(
+		attribute.String("list.id", listID.String()),
+		attribute.String("user.id", userID.String()),
+	))
+	defer span.End()
+
+	l := s.logger.With(slog.String("method", "SaveList"),
+		slog.String("listID", listID.String()),
+		slog.String("userID", userID.String()))
+	l.DebugContext(ctx, "Saving list")
+
+	list, err := s.listRepository.GetList(ctx, listID)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to fetch list", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "List not found")
+		return fmt.Errorf("list not found: %w", err)
+	}
+	if list.UserID == userID {
+		span.SetStatus(codes.Error, "Own list")
+		return fmt.Errorf("cannot save your own list")
+	}
+	if !list.IsPublic {
+		l.WarnContext(ctx, "Access denied to list",
+			slog.String("listOwnerID", list.UserID.String()))
+		span.SetStatus(codes.Error, "Access denied")
+		return fmt.Errorf("access denied to list")
+	}
+
+	saved, err := s.listRepository.SaveList(ctx, userID, listID)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to save list", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to save list")
+		return fmt.Errorf("failed to save list: %w", err)
+	}
+
+	// Saving a list again is not another save
+	if saved {
+		s.analytics.Emit(ctx, types.AnalyticsEvent{
+			Name:   types.AnalyticsListSaved,
+			UserID: &userID,
+			ListID: &listID,
+			CityID: nilIfZero(list.CityID),
+		})
+	}
+
+	l.InfoContext(ctx, "List saved successfully")
+	span.SetStatus(codes.Ok, "List saved")
+	return nil
+}
+
+// UnsaveList removes a list from the user's saved lists
+func (s *ServiceImpl) UnsaveList(ctx context.Context, listID, userID uuid.UUID) error {
+	ctx, span := otel.Tracer("ItineraryListService").Start(ctx, "UnsaveList", trace.WithAttributes(
+		attribute.String("list.id", listID.String()),
+		attribute.String("user.id", userID.String()),
+	))
+	defer span.End()
+
+	if err := s.listRepository.UnsaveList(ctx, userID, listID); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to unsave list", slog.String("listID", listID.String()), slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to unsave list")
+		return fmt.Errorf("failed to unsave list: %w", err)
+	}
+	span.SetStatus(codes.Ok, "List unsaved")
+	return nil
+}
+
 // AddPOIListItem adds a POI to a list
 func (s *ServiceImpl) AddPOIListItem(ctx context.Context, userID, listID, poiID uuid.UUID, params types.AddListItemRequest) (*types.ListItem, error) {
 	ctx, span := otel.Tracer("ItineraryListService").Start(ctx, "AddPOIListItem", trace.WithAttributes(
@@ -362,6 +477,14 @@ func (s *ServiceImpl) AddPOIListItem(ctx context.Context, userID, listID, poiID
 		return nil, fmt.Errorf("failed to add POI to list: %w", err)
 	}
 
+	s.analytics.Emit(ctx, types.AnalyticsEvent{
+		Name:   types.AnalyticsPOISaved,
+		UserID: &userID,
+		POIID:  &poiID,
+		ListID: &listID,
+		CityID: nilIfZero(list.CityID),
+	})
+
 	l.InfoContext(ctx, "POI added to list successfully")
 	span.SetStatus(codes.Ok, "POI added to list")
 	return &item, nil
@@ -488,8 +611,8 @@ func (s *ServiceImpl) RemovePOIListItem(ctx context.Context, userID, listID, poi
 	return nil
 }
 
-// GetUserLists retrieves all lists for a user
-func (s *ServiceImpl) GetUserLists(ctx context.Context, userID uuid.UUID, isItinerary bool) ([]*types.List, error) {
+// GetUserLists retrieves a page of lists for a user
+func (s *ServiceImpl) GetUserLists(ctx context.Context, userID uuid.UUID, isItinerary bool, page types.PageRequest) ([]*types.List, *types.Cursor, error) {
 	ctx, span := otel.Tracer("ItineraryListService").Start(ctx, "GetUserLists", trace.WithAttributes(
 		attribute.String("user.id", userID.String()),
 		attribute.Bool("is_itinerary", isItinerary),
@@ -501,18 +624,17 @@ func (s *ServiceImpl) GetUserLists(ctx context.Context, userID uuid.UUID, isItin
 		slog.Bool("isItinerary", isItinerary))
 	l.DebugContext(ctx, "Getting user lists")
 
-	// Note: We need to add a GetUserLists method to the repository
-	lists, err := s.listRepository.GetUserLists(ctx, userID, isItinerary)
+	lists, next, err := s.listRepository.GetUserLists(ctx, userID, isItinerary, page.Clamp(defaultListPageLimit, maxListPageLimit))
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to get user lists", slog.Any("error", err))
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "Failed to get user lists")
-		return nil, fmt.Errorf("failed to get user lists: %w", err)
+		return nil, nil, fmt.Errorf("failed to get user lists: %w", err)
 	}
 
 	l.InfoContext(ctx, "User lists fetched successfully", slog.Int("count", len(lists)))
 	span.SetStatus(codes.Ok, "User lists fetched")
-	return lists, nil
+	return lists, next, nil
 }
 
 // todo
@@ -585,3 +707,11 @@ func (s *ServiceImpl) GetUserLists(ctx context.Context, userID uuid.UUID, isItin
 // 	// Update the list via repo methods
 // 	return nil
 // }
+
+// nilIfZero returns nil for lists without a city.
+func nilIfZero(id uuid.UUID) *uuid.UUID {
+	if id == uuid.Nil {
+		return nil
+	}
+	return &id
+}
@@ -8,6 +8,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/analytics"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/audit"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -74,12 +76,13 @@ func (m *MockListRepository) DeleteListItem(ctx context.Context, listID, poiID u
 	return args.Error(0)
 }
 
-func (m *MockListRepository) GetUserLists(ctx context.Context, userID uuid.UUID, isItinerary bool) ([]*types.List, error) {
-	args := m.Called(ctx, userID, isItinerary)
+func (m *MockListRepository) GetUserLists(ctx context.Context, userID uuid.UUID, isItinerary bool, page types.PageRequest) ([]*types.List, *types.Cursor, error) {
+	args := m.Called(ctx, userID, isItinerary, page)
 	if args.Get(0) == nil {
-		return nil, args.Error(1)
+		return nil, nil, args.Error(2)
 	}
-	return args.Get(0).([]*types.List), args.Error(1)
+	next, _ := args.Get(1).(*types.Cursor)
+	return args.Get(0).([]*types.List), next, args.Error(2)
 }
 
 func (m *MockListRepository) GetSubLists(ctx context.Context, parentListID uuid.UUID) ([]*types.List, error) {
@@ -90,11 +93,30 @@ func (m *MockListRepository) GetSubLists(ctx context.Context, parentListID uuid.
 	return args.Get(0).([]*types.List), args.Error(1)
 }
 
+func (m *MockListRepository) SaveList(ctx context.Context, userID, listID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, userID, listID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockListRepository) UnsaveList(ctx context.Context, userID, listID uuid.UUID) error {
+	args := m.Called(ctx, userID, listID)
+	return args.Error(0)
+}
+
+// recordingEmitter keeps the analytics events emitted
+type recordingEmitter struct {
+	events []types.AnalyticsEvent
+}
+
+func (e *recordingEmitter) Emit(_ context.Context, event types.AnalyticsEvent) {
+	e.events = append(e.events, event)
+}
+
 // Helper to setup service with mock repository
 func setupListServiceTest() (*ServiceImpl, *MockListRepository) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
 	mockRepo := new(MockListRepository)
-	service := NewServiceImpl(mockRepo, logger)
+	service := NewServiceImpl(mockRepo, audit.NopRecorder{}, analytics.NopEmitter{}, logger)
 	return service, mockRepo
 }
 
@@ -437,21 +459,23 @@ func TestServiceImpl_GetUserLists(t *testing.T) {
 	}
 
 	t.Run("success", func(t *testing.T) {
-		mockRepo.On("GetUserLists", ctx, userID, false).Return(expectedLists, nil).Once()
+		next := &types.Cursor{Time: &expectedLists[1].CreatedAt, ID: expectedLists[1].ID}
+		mockRepo.On("GetUserLists", ctx, userID, false, types.PageRequest{Limit: 2}).Return(expectedLists, next, nil).Once()
 
-		result, err := service.GetUserLists(ctx, userID, false)
+		result, cursor, err := service.GetUserLists(ctx, userID, false, types.PageRequest{Limit: 2})
 		
 		require.NoError(t, err)
 		assert.Equal(t, expectedLists, result)
 		assert.Len(t, result, 2)
+		assert.Equal(t, next, cursor)
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("repository error", func(t *testing.T) {
 		repoErr := errors.New("db error")
-		mockRepo.On("GetUserLists", ctx, userID, true).Return(nil, repoErr).Once()
+		mockRepo.On("GetUserLists", ctx, userID, true, types.PageRequest{Limit: defaultListPageLimit}).Return(nil, nil, repoErr).Once()
 
-		_, err := service.GetUserLists(ctx, userID, true)
+		_, _, err := service.GetUserLists(ctx, userID, true, types.PageRequest{})
 		
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to get user lists")
@@ -507,4 +531,55 @@ func TestServiceImpl_DeleteUserList(t *testing.T) {
 		assert.Contains(t, err.Error(), "list not found")
 		mockRepo.AssertExpectations(t)
 	})
-}
\ No newline at end of file
+}
+
+func TestServiceImpl_SaveList(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	listID := uuid.New()
+	public := types.List{ID: listID, UserID: uuid.New(), Name: "Lisbon Cafés", IsPublic: true}
+
+	setup := func() (*ServiceImpl, *MockListRepository, *recordingEmitter) {
+		logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		mockRepo := new(MockListRepository)
+		emitter := &recordingEmitter{}
+		return NewServiceImpl(mockRepo, audit.NopRecorder{}, emitter, logger), mockRepo, emitter
+	}
+
+	t.Run("saves a public list and records the save", func(t *testing.T) {
+		service, mockRepo, emitter := setup()
+		mockRepo.On("GetList", mock.Anything, listID).Return(public, nil).Once()
+		mockRepo.On("SaveList", mock.Anything, userID, listID).Return(true, nil).Once()
+
+		err := service.SaveList(ctx, listID, userID)
+
+		require.NoError(t, err)
+		require.Len(t, emitter.events, 1)
+		assert.Equal(t, types.AnalyticsListSaved, emitter.events[0].Name)
+		assert.Equal(t, &listID, emitter.events[0].ListID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("saving again records nothing", func(t *testing.T) {
+		service, mockRepo, emitter := setup()
+		mockRepo.On("GetList", mock.Anything, listID).Return(public, nil).Once()
+		mockRepo.On("SaveList", mock.Anything, userID, listID).Return(false, nil).Once()
+
+		require.NoError(t, service.SaveList(ctx, listID, userID))
+		assert.Empty(t, emitter.events)
+	})
+
+	t.Run("private and own lists cannot be saved", func(t *testing.T) {
+		service, mockRepo, _ := setup()
+		private := public
+		private.IsPublic = false
+		own := public
+		own.UserID = userID
+		mockRepo.On("GetList", mock.Anything, listID).Return(private, nil).Once()
+		mockRepo.On("GetList", mock.Anything, listID).Return(own, nil).Once()
+
+		assert.ErrorContains(t, service.SaveList(ctx, listID, userID), "access denied")
+		assert.ErrorContains(t, service.SaveList(ctx, listID, userID), "your own list")
+		mockRepo.AssertNotCalled(t, "SaveList", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
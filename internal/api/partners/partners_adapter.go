@@ -0,0 +1,88 @@
+package partners
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/config"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+// Adapter talks to one booking partner about the places listed with it.
+type Adapter interface {
+	// Key identifies the partner in the registry and in partner_listings.
+	Key() string
+	// Name is the partner's display name.
+	Name() string
+	// Availability reports whether the listing can be booked as requested.
+	Availability(ctx context.Context, listing types.PartnerListing, req types.BookingRequest) (*types.PartnerAvailability, error)
+	// Quote prices the requested booking of the listing.
+	Quote(ctx context.Context, listing types.PartnerListing, req types.BookingRequest) (*types.PriceQuote, error)
+	// DeepLink is the URL of the listing's booking page at the partner,
+	// prefilled with the request where the partner supports it.
+	DeepLink(listing types.PartnerListing, req types.BookingRequest) string
+}
+
+// Registry holds the adapters of the enabled partners by key.
+type Registry struct {
+	mu       sync.RWMutex
+	adapters map[string]Adapter
+}
+
+// NewRegistry creates a registry holding adapters.
+func NewRegistry(adapters ...Adapter) *Registry {
+	r := &Registry{adapters: make(map[string]Adapter)}
+	for _, a := range adapters {
+		r.adapters[a.Key()] = a
+	}
+	return r
+}
+
+// NewRegistryFromConfig creates a registry of the partners enabled in cfg.
+func NewRegistryFromConfig(cfg config.PartnersConfig) (*Registry, error) {
+	r := NewRegistry()
+	for _, key := range cfg.Enabled {
+		switch key {
+		case MockPartnerKey:
+			if err := r.Register(NewMockPartner()); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unknown booking partner %q", key)
+		}
+	}
+	return r, nil
+}
+
+// Register adds an adapter, failing if its key is taken.
+func (r *Registry) Register(a Adapter) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.adapters[a.Key()]; ok {
+		return fmt.Errorf("booking partner %q is already registered", a.Key())
+	}
+	r.adapters[a.Key()] = a
+	return nil
+}
+
+// Get returns the adapter registered under key.
+func (r *Registry) Get(key string) (Adapter, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	a, ok := r.adapters[key]
+	return a, ok
+}
+
+// Partners lists the registered partners by key.
+func (r *Registry) Partners() []types.PartnerInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	partners := make([]types.PartnerInfo, 0, len(r.adapters))
+	for _, a := range r.adapters {
+		partners = append(partners, types.PartnerInfo{Key: a.Key(), Name: a.Name()})
+	}
+	sort.Slice(partners, func(i, j int) bool { return partners[i].Key < partners[j].Key })
+	return partners
+}
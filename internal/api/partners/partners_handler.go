@@ -0,0 +1,177 @@
+package partners
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Handler = (*HandlerImpl)(nil)
+
+type Handler interface {
+	ListPartners(w http.ResponseWriter, r *http.Request)
+	ListListings(w http.ResponseWriter, r *http.Request)
+	CreateListing(w http.ResponseWriter, r *http.Request)
+	DeleteListing(w http.ResponseWriter, r *http.Request)
+}
+
+type HandlerImpl struct {
+	logger  *slog.Logger
+	service Service
+}
+
+func NewHandler(service Service, logger *slog.Logger) *HandlerImpl {
+	return &HandlerImpl{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// ListPartners godoc
+// @Summary      List Booking Partners
+// @Description  Returns the booking partners enabled in this deployment, whose keys partner listings refer to. Admin only.
+// @Tags         Admin
+// @Produce      json
+// @Success      200 {array} types.PartnerInfo
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      403 {object} types.Response "Forbidden"
+// @Security     BearerAuth
+// @Router       /admin/partners [get]
+func (h *HandlerImpl) ListPartners(w http.ResponseWriter, r *http.Request) {
+	_, span := otel.Tracer("PartnersHandler").Start(r.Context(), "ListPartners")
+	defer span.End()
+
+	span.SetStatus(codes.Ok, "Partners listed")
+	api.WriteJSONResponse(w, r, http.StatusOK, h.service.Partners())
+}
+
+// ListListings godoc
+// @Summary      List Partner Listings
+// @Description  Returns the mappings of hotels, restaurants and POIs to their listings at booking partners, optionally those of one kind or one place. Admin only.
+// @Tags         Admin
+// @Produce      json
+// @Param        target_kind query string false "poi, hotel or restaurant"
+// @Param        target_id query string false "ID of the place; needs target_kind"
+// @Success      200 {array} types.PartnerListing
+// @Failure      400 {object} types.Response "Unknown target kind or invalid target ID"
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      403 {object} types.Response "Forbidden"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/partners/listings [get]
+func (h *HandlerImpl) ListListings(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("PartnersHandler").Start(r.Context(), "ListListings")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "ListListings"))
+
+	targetKind := r.URL.Query().Get("target_kind")
+	var targetID *uuid.UUID
+	if v := r.URL.Query().Get("target_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			span.SetStatus(codes.Error, "Invalid target ID")
+			api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid target_id")
+			return
+		}
+		targetID = &id
+	}
+	span.SetAttributes(attribute.String("target.kind", targetKind))
+
+	listings, err := h.service.ListListings(ctx, targetKind, targetID)
+	if err != nil {
+		l.ErrorContext(ctx, "Service failed to list partner listings", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to list partner listings")
+		api.WriteServiceError(w, r, err, "Failed to list partner listings")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Partner listings listed")
+	api.WriteJSONResponse(w, r, http.StatusOK, listings)
+}
+
+// CreateListing godoc
+// @Summary      Map a Place to a Partner Listing
+// @Description  Records that a hotel, restaurant or POI is listed at a booking partner under the partner's external ID, so its detail page shows the partner's availability, price and booking link. Admin only.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        listing body types.CreatePartnerListingRequest true "Partner key, external ID and the place it maps to"
+// @Success      201 {object} types.PartnerListing
+// @Failure      400 {object} types.Response "Unknown partner or target kind, or missing external or target ID"
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      403 {object} types.Response "Forbidden"
+// @Failure      404 {object} types.Response "Place not found"
+// @Failure      409 {object} types.Response "External ID already mapped for the partner"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/partners/listings [post]
+func (h *HandlerImpl) CreateListing(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("PartnersHandler").Start(r.Context(), "CreateListing")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "CreateListing"))
+
+	var req types.CreatePartnerListingRequest
+	if err := api.DecodeJSONBody(w, r, &req); err != nil {
+		l.ErrorContext(ctx, "Failed to decode request", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Bad request")
+		return
+	}
+
+	listing, err := h.service.CreateListing(ctx, req)
+	if err != nil {
+		l.ErrorContext(ctx, "Service failed to create partner listing", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to create partner listing")
+		api.WriteServiceError(w, r, err, "Failed to create partner listing")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Partner listing created")
+	api.WriteJSONResponse(w, r, http.StatusCreated, listing)
+}
+
+// DeleteListing godoc
+// @Summary      Delete a Partner Listing
+// @Description  Removes a place's mapping to a partner listing. Admin only.
+// @Tags         Admin
+// @Param        listingID path string true "Partner listing ID"
+// @Success      204 "Partner listing deleted"
+// @Failure      400 {object} types.Response "Invalid listing ID"
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      403 {object} types.Response "Forbidden"
+// @Failure      404 {object} types.Response "Partner listing not found"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/partners/listings/{listingID} [delete]
+func (h *HandlerImpl) DeleteListing(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("PartnersHandler").Start(r.Context(), "DeleteListing")
+	defer span.End()
+
+	listingID, err := uuid.Parse(chi.URLParam(r, "listingID"))
+	if err != nil {
+		span.SetStatus(codes.Error, "Invalid listing ID")
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid listing ID")
+		return
+	}
+	span.SetAttributes(attribute.String("listing.id", listingID.String()))
+
+	if err := h.service.DeleteListing(ctx, listingID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to delete partner listing")
+		api.WriteServiceError(w, r, err, "Failed to delete partner listing")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Partner listing deleted")
+	w.WriteHeader(http.StatusNoContent)
+}
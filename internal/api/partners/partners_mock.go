@@ -0,0 +1,117 @@
+package partners
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+const (
+	MockPartnerKey = "mock"
+
+	mockBaseURL  = "https://partner.example.com/book"
+	mockCurrency = "EUR"
+)
+
+var _ Adapter = (*MockPartner)(nil)
+
+// MockListing is the canned answer of the mock partner for one listing.
+type MockListing struct {
+	Available          bool
+	InstantBook        bool
+	CancellationPolicy string
+	// Rate is the price per room and night for hotels, or per guest elsewhere.
+	Rate float64
+}
+
+// MockPartner is an in-process partner for tests and development. Listings
+// not in Listings are available for instant booking with free cancellation,
+// at a rate derived from their external ID. Err, when set, fails every
+// availability and quote call.
+type MockPartner struct {
+	Listings map[string]MockListing
+	Err      error
+}
+
+// NewMockPartner creates a mock partner answering with the defaults.
+func NewMockPartner() *MockPartner {
+	return &MockPartner{Listings: make(map[string]MockListing)}
+}
+
+// Key implements Adapter.
+func (m *MockPartner) Key() string { return MockPartnerKey }
+
+// Name implements Adapter.
+func (m *MockPartner) Name() string { return "Mock Partner" }
+
+// Availability implements Adapter.
+func (m *MockPartner) Availability(_ context.Context, listing types.PartnerListing, _ types.BookingRequest) (*types.PartnerAvailability, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	l := m.listing(listing.ExternalID)
+	return &types.PartnerAvailability{
+		Available:          l.Available,
+		InstantBook:        l.InstantBook,
+		CancellationPolicy: l.CancellationPolicy,
+	}, nil
+}
+
+// Quote implements Adapter.
+func (m *MockPartner) Quote(_ context.Context, listing types.PartnerListing, req types.BookingRequest) (*types.PriceQuote, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	l := m.listing(listing.ExternalID)
+	units := max(req.Guests, 1)
+	if listing.TargetKind == types.PartnerTargetHotel {
+		units = max(req.Rooms, 1) * req.Nights()
+	}
+	return &types.PriceQuote{
+		Amount:   math.Round(l.Rate*float64(units)*100) / 100,
+		Currency: mockCurrency,
+	}, nil
+}
+
+// DeepLink implements Adapter.
+func (m *MockPartner) DeepLink(listing types.PartnerListing, req types.BookingRequest) string {
+	link := mockBaseURL + "/" + url.PathEscape(listing.TargetKind) + "/" + url.PathEscape(listing.ExternalID)
+	q := url.Values{}
+	if req.Dated() {
+		if listing.TargetKind == types.PartnerTargetHotel {
+			q.Set("check_in", req.CheckIn.Format(time.DateOnly))
+			q.Set("check_out", req.CheckIn.AddDate(0, 0, req.Nights()).Format(time.DateOnly))
+		} else {
+			q.Set("at", req.CheckIn.Format(time.RFC3339))
+		}
+	}
+	if req.Guests > 0 {
+		q.Set("guests", strconv.Itoa(req.Guests))
+	}
+	if req.Rooms > 0 {
+		q.Set("rooms", strconv.Itoa(req.Rooms))
+	}
+	if len(q) > 0 {
+		link += "?" + q.Encode()
+	}
+	return link
+}
+
+func (m *MockPartner) listing(externalID string) MockListing {
+	if l, ok := m.Listings[externalID]; ok {
+		return l
+	}
+	h := fnv.New32a()
+	h.Write([]byte(externalID))
+	return MockListing{
+		Available:          true,
+		InstantBook:        true,
+		CancellationPolicy: types.CancellationFree,
+		Rate:               float64(60 + h.Sum32()%140),
+	}
+}
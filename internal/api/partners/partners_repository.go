@@ -0,0 +1,176 @@
+package partners
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Repository = (*RepositoryImpl)(nil)
+
+// Repository stores the listings of our places at booking partners.
+type Repository interface {
+	CreateListing(ctx context.Context, req types.CreatePartnerListingRequest) (*types.PartnerListing, error)
+	DeleteListing(ctx context.Context, listingID uuid.UUID) error
+	// ListListings returns the listings of one place, or of every place of
+	// a kind when targetID is nil, or all listings when targetKind is empty.
+	ListListings(ctx context.Context, targetKind string, targetID *uuid.UUID) ([]types.PartnerListing, error)
+}
+
+type RepositoryImpl struct {
+	logger *slog.Logger
+	pgpool *pgxpool.Pool
+}
+
+func NewRepository(pgxpool *pgxpool.Pool, logger *slog.Logger) *RepositoryImpl {
+	return &RepositoryImpl{
+		logger: logger,
+		pgpool: pgxpool,
+	}
+}
+
+// targetColumns maps target kinds to their partner_listings column.
+var targetColumns = map[string]string{
+	types.PartnerTargetPOI:        "poi_id",
+	types.PartnerTargetHotel:      "hotel_id",
+	types.PartnerTargetRestaurant: "restaurant_id",
+}
+
+const listingColumns = `id, partner, external_id, poi_id, hotel_id, restaurant_id, created_at, updated_at`
+
+func scanListing(row pgx.Row) (*types.PartnerListing, error) {
+	var l types.PartnerListing
+	var poiID, hotelID, restaurantID *uuid.UUID
+	if err := row.Scan(&l.ID, &l.Partner, &l.ExternalID, &poiID, &hotelID, &restaurantID, &l.CreatedAt, &l.UpdatedAt); err != nil {
+		return nil, err
+	}
+	switch {
+	case poiID != nil:
+		l.TargetKind, l.TargetID = types.PartnerTargetPOI, *poiID
+	case hotelID != nil:
+		l.TargetKind, l.TargetID = types.PartnerTargetHotel, *hotelID
+	case restaurantID != nil:
+		l.TargetKind, l.TargetID = types.PartnerTargetRestaurant, *restaurantID
+	}
+	return &l, nil
+}
+
+// CreateListing implements Repository.
+func (r *RepositoryImpl) CreateListing(ctx context.Context, req types.CreatePartnerListingRequest) (*types.PartnerListing, error) {
+	ctx, span := otel.Tracer("PartnersRepo").Start(ctx, "CreateListing", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "partner_listings"),
+		attribute.String("partner", req.Partner),
+		attribute.String("target.kind", req.TargetKind),
+	))
+	defer span.End()
+
+	column, ok := targetColumns[req.TargetKind]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown target kind %q", types.ErrBadRequest, req.TargetKind)
+	}
+	listing, err := scanListing(r.pgpool.QueryRow(ctx, `
+		INSERT INTO partner_listings (partner, external_id, `+column+`)
+		VALUES ($1, $2, $3)
+		RETURNING `+listingColumns, req.Partner, req.ExternalID, req.TargetID))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505": // unique_violation on (partner, external_id)
+				return nil, fmt.Errorf("%s listing %q is already mapped: %w", req.Partner, req.ExternalID, types.ErrConflict)
+			case "23503": // foreign_key_violation on the target
+				return nil, fmt.Errorf("%s not found: %w", req.TargetKind, types.ErrNotFound)
+			}
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB insert failed")
+		return nil, fmt.Errorf("database error creating partner listing: %w", err)
+	}
+	span.SetStatus(codes.Ok, "Partner listing created")
+	return listing, nil
+}
+
+// DeleteListing implements Repository.
+func (r *RepositoryImpl) DeleteListing(ctx context.Context, listingID uuid.UUID) error {
+	ctx, span := otel.Tracer("PartnersRepo").Start(ctx, "DeleteListing", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "partner_listings"),
+		attribute.String("listing.id", listingID.String()),
+	))
+	defer span.End()
+
+	tag, err := r.pgpool.Exec(ctx, `DELETE FROM partner_listings WHERE id = $1`, listingID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB delete failed")
+		return fmt.Errorf("database error deleting partner listing: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("partner listing not found: %w", types.ErrNotFound)
+	}
+	span.SetStatus(codes.Ok, "Partner listing deleted")
+	return nil
+}
+
+// ListListings implements Repository.
+func (r *RepositoryImpl) ListListings(ctx context.Context, targetKind string, targetID *uuid.UUID) ([]types.PartnerListing, error) {
+	ctx, span := otel.Tracer("PartnersRepo").Start(ctx, "ListListings", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "partner_listings"),
+		attribute.String("target.kind", targetKind),
+	))
+	defer span.End()
+
+	query := `SELECT ` + listingColumns + ` FROM partner_listings`
+	var args []any
+	if targetKind != "" {
+		column, ok := targetColumns[targetKind]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown target kind %q", types.ErrBadRequest, targetKind)
+		}
+		if targetID != nil {
+			query += ` WHERE ` + column + ` = $1`
+			args = append(args, *targetID)
+		} else {
+			query += ` WHERE ` + column + ` IS NOT NULL`
+		}
+	}
+	query += ` ORDER BY partner, external_id`
+
+	rows, err := r.pgpool.Query(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, fmt.Errorf("database error listing partner listings: %w", err)
+	}
+	defer rows.Close()
+
+	listings := []types.PartnerListing{}
+	for rows.Next() {
+		listing, err := scanListing(rows)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("database error scanning partner listing: %w", err)
+		}
+		listings = append(listings, *listing)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("database error iterating partner listings: %w", err)
+	}
+	return listings, nil
+}
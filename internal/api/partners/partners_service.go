@@ -0,0 +1,223 @@
+package partners
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Service = (*ServiceImpl)(nil)
+
+const defaultTimeout = 3 * time.Second
+
+// Service maps our places to booking partners and collects their offers.
+type Service interface {
+	// Partners lists the registered booking partners.
+	Partners() []types.PartnerInfo
+	ListListings(ctx context.Context, targetKind string, targetID *uuid.UUID) ([]types.PartnerListing, error)
+	CreateListing(ctx context.Context, req types.CreatePartnerListingRequest) (*types.PartnerListing, error)
+	DeleteListing(ctx context.Context, listingID uuid.UUID) error
+	// Offers asks every partner the place is listed with about the booking,
+	// available offers first, those the accommodation preferences accept
+	// next, cheapest first. It never fails: a partner that errors or times
+	// out is offered by deep link alone.
+	Offers(ctx context.Context, targetKind string, targetID uuid.UUID, req types.BookingRequest, prefs *types.AccommodationPreferences) []types.PartnerOffer
+}
+
+type ServiceImpl struct {
+	logger   *slog.Logger
+	repo     Repository
+	registry *Registry
+	timeout  time.Duration
+}
+
+// NewService creates the partners service. Each partner call is bounded by
+// timeout, or by 3s when it is not positive.
+func NewService(repo Repository, registry *Registry, timeout time.Duration, logger *slog.Logger) *ServiceImpl {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &ServiceImpl{
+		logger:   logger,
+		repo:     repo,
+		registry: registry,
+		timeout:  timeout,
+	}
+}
+
+// Partners implements Service.
+func (s *ServiceImpl) Partners() []types.PartnerInfo {
+	return s.registry.Partners()
+}
+
+// ListListings implements Service.
+func (s *ServiceImpl) ListListings(ctx context.Context, targetKind string, targetID *uuid.UUID) ([]types.PartnerListing, error) {
+	if targetKind != "" && !types.IsPartnerTarget(targetKind) {
+		return nil, fmt.Errorf("%w: unknown target kind %q", types.ErrBadRequest, targetKind)
+	}
+	if targetID != nil && targetKind == "" {
+		return nil, fmt.Errorf("%w: target_id needs target_kind", types.ErrBadRequest)
+	}
+	return s.repo.ListListings(ctx, targetKind, targetID)
+}
+
+// CreateListing implements Service.
+func (s *ServiceImpl) CreateListing(ctx context.Context, req types.CreatePartnerListingRequest) (*types.PartnerListing, error) {
+	req.ExternalID = strings.TrimSpace(req.ExternalID)
+	if _, ok := s.registry.Get(req.Partner); !ok {
+		return nil, fmt.Errorf("%w: unknown partner %q", types.ErrBadRequest, req.Partner)
+	}
+	if req.ExternalID == "" {
+		return nil, fmt.Errorf("%w: external_id is required", types.ErrBadRequest)
+	}
+	if !types.IsPartnerTarget(req.TargetKind) {
+		return nil, fmt.Errorf("%w: unknown target kind %q", types.ErrBadRequest, req.TargetKind)
+	}
+	if req.TargetID == uuid.Nil {
+		return nil, fmt.Errorf("%w: target_id is required", types.ErrBadRequest)
+	}
+	return s.repo.CreateListing(ctx, req)
+}
+
+// DeleteListing implements Service.
+func (s *ServiceImpl) DeleteListing(ctx context.Context, listingID uuid.UUID) error {
+	return s.repo.DeleteListing(ctx, listingID)
+}
+
+// Offers implements Service.
+func (s *ServiceImpl) Offers(ctx context.Context, targetKind string, targetID uuid.UUID, req types.BookingRequest, prefs *types.AccommodationPreferences) []types.PartnerOffer {
+	ctx, span := otel.Tracer("PartnersService").Start(ctx, "Offers", trace.WithAttributes(
+		attribute.String("target.kind", targetKind),
+		attribute.String("target.id", targetID.String()),
+		attribute.Bool("dated", req.Dated()),
+	))
+	defer span.End()
+
+	listings, err := s.repo.ListListings(ctx, targetKind, &targetID)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to look up partner listings",
+			slog.String("target_kind", targetKind), slog.String("target_id", targetID.String()), slog.Any("error", err))
+		span.RecordError(err)
+		return nil
+	}
+
+	offers := make([]*types.PartnerOffer, len(listings))
+	var wg sync.WaitGroup
+	for i, listing := range listings {
+		adapter, ok := s.registry.Get(listing.Partner)
+		if !ok {
+			// Listings of partners that are not enabled are kept for when they are
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			offers[i] = s.offer(ctx, adapter, listing, req, prefs)
+		}()
+	}
+	wg.Wait()
+
+	result := []types.PartnerOffer{}
+	for _, offer := range offers {
+		if offer != nil {
+			result = append(result, *offer)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return offerLess(result[i], result[j]) })
+
+	span.SetAttributes(attribute.Int("offers.count", len(result)))
+	return result
+}
+
+// offer asks one partner about a listing, within the service timeout.
+func (s *ServiceImpl) offer(ctx context.Context, adapter Adapter, listing types.PartnerListing, req types.BookingRequest, prefs *types.AccommodationPreferences) *types.PartnerOffer {
+	offer := &types.PartnerOffer{
+		Partner:            adapter.Key(),
+		PartnerName:        adapter.Name(),
+		ExternalID:         listing.ExternalID,
+		DeepLink:           adapter.DeepLink(listing, req),
+		MatchesPreferences: true,
+	}
+	if !req.Dated() {
+		return offer
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	l := s.logger.With(slog.String("partner", adapter.Key()), slog.String("external_id", listing.ExternalID))
+
+	availability, err := adapter.Availability(ctx, listing, req)
+	if err != nil {
+		l.WarnContext(ctx, "Partner availability check failed", slog.Any("error", err))
+		return offer
+	}
+	offer.Available = &availability.Available
+	offer.InstantBook = availability.InstantBook
+	offer.CancellationPolicy = availability.CancellationPolicy
+	offer.MatchesPreferences = matchesPreferences(*offer, prefs)
+	if !availability.Available {
+		return offer
+	}
+
+	quote, err := adapter.Quote(ctx, listing, req)
+	if err != nil {
+		l.WarnContext(ctx, "Partner price quote failed", slog.Any("error", err))
+		return offer
+	}
+	offer.Quote = quote
+	return offer
+}
+
+// matchesPreferences reports whether the offer's cancellation policy and
+// booking mode are ones prefs accept. Unknown policies match.
+func matchesPreferences(offer types.PartnerOffer, prefs *types.AccommodationPreferences) bool {
+	if prefs == nil {
+		return true
+	}
+	if len(prefs.CancellationPolicy) > 0 && offer.CancellationPolicy != "" &&
+		!slices.Contains(prefs.CancellationPolicy, offer.CancellationPolicy) {
+		return false
+	}
+	if prefs.BookingFlexibility == types.BookingInstant && !offer.InstantBook {
+		return false
+	}
+	return true
+}
+
+// offerLess orders available offers before unchecked ones before those that
+// are not, then offers matching preferences first, then the cheapest.
+func offerLess(a, b types.PartnerOffer) bool {
+	if ra, rb := availabilityRank(a), availabilityRank(b); ra != rb {
+		return ra < rb
+	}
+	if a.MatchesPreferences != b.MatchesPreferences {
+		return a.MatchesPreferences
+	}
+	if a.Quote != nil && b.Quote != nil {
+		return a.Quote.Amount < b.Quote.Amount
+	}
+	return a.Quote != nil && b.Quote == nil
+}
+
+func availabilityRank(offer types.PartnerOffer) int {
+	switch {
+	case offer.Available == nil:
+		return 1
+	case *offer.Available:
+		return 0
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,234 @@
+package partners
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/config"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+// MockPartnersRepository is a mock implementation of Repository
+type MockPartnersRepository struct {
+	mock.Mock
+}
+
+func (m *MockPartnersRepository) CreateListing(ctx context.Context, req types.CreatePartnerListingRequest) (*types.PartnerListing, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.PartnerListing), args.Error(1)
+}
+
+func (m *MockPartnersRepository) DeleteListing(ctx context.Context, listingID uuid.UUID) error {
+	return m.Called(ctx, listingID).Error(0)
+}
+
+func (m *MockPartnersRepository) ListListings(ctx context.Context, targetKind string, targetID *uuid.UUID) ([]types.PartnerListing, error) {
+	args := m.Called(ctx, targetKind, targetID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.PartnerListing), args.Error(1)
+}
+
+// namedPartner is a mock partner registered under another key.
+type namedPartner struct {
+	*MockPartner
+	key string
+}
+
+func (p namedPartner) Key() string { return p.key }
+
+func setupPartnersServiceTest(adapters ...Adapter) (*ServiceImpl, *MockPartnersRepository) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	repo := new(MockPartnersRepository)
+	return NewService(repo, NewRegistry(adapters...), time.Second, logger), repo
+}
+
+func TestRegistry(t *testing.T) {
+	t.Run("from config", func(t *testing.T) {
+		registry, err := NewRegistryFromConfig(config.PartnersConfig{Enabled: []string{MockPartnerKey}})
+		require.NoError(t, err)
+		adapter, ok := registry.Get(MockPartnerKey)
+		require.True(t, ok)
+		assert.Equal(t, "Mock Partner", adapter.Name())
+		assert.Equal(t, []types.PartnerInfo{{Key: "mock", Name: "Mock Partner"}}, registry.Partners())
+	})
+
+	t.Run("rejects unknown partners", func(t *testing.T) {
+		_, err := NewRegistryFromConfig(config.PartnersConfig{Enabled: []string{"acme"}})
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects duplicate keys", func(t *testing.T) {
+		registry := NewRegistry(NewMockPartner())
+		assert.Error(t, registry.Register(NewMockPartner()))
+		require.NoError(t, registry.Register(namedPartner{NewMockPartner(), "other"}))
+		assert.Len(t, registry.Partners(), 2)
+	})
+}
+
+func TestMockPartner(t *testing.T) {
+	ctx := context.Background()
+	partner := NewMockPartner()
+	partner.Listings["h-1"] = MockListing{Available: true, CancellationPolicy: types.CancellationNonRefundable, Rate: 100}
+	hotel := types.PartnerListing{Partner: MockPartnerKey, ExternalID: "h-1", TargetKind: types.PartnerTargetHotel}
+	checkIn := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	req := types.BookingRequest{CheckIn: checkIn, CheckOut: checkIn.AddDate(0, 0, 3), Guests: 2, Rooms: 2}
+
+	t.Run("quotes per room and night for hotels", func(t *testing.T) {
+		availability, err := partner.Availability(ctx, hotel, req)
+		require.NoError(t, err)
+		assert.Equal(t, types.PartnerAvailability{Available: true, CancellationPolicy: types.CancellationNonRefundable}, *availability)
+
+		quote, err := partner.Quote(ctx, hotel, req)
+		require.NoError(t, err)
+		assert.Equal(t, types.PriceQuote{Amount: 600, Currency: "EUR"}, *quote)
+	})
+
+	t.Run("quotes per guest for restaurants", func(t *testing.T) {
+		restaurant := types.PartnerListing{ExternalID: "h-1", TargetKind: types.PartnerTargetRestaurant}
+		quote, err := partner.Quote(ctx, restaurant, types.BookingRequest{CheckIn: checkIn, Guests: 4})
+		require.NoError(t, err)
+		assert.Equal(t, 400.0, quote.Amount)
+	})
+
+	t.Run("defaults unknown listings", func(t *testing.T) {
+		other := types.PartnerListing{ExternalID: "h-2", TargetKind: types.PartnerTargetHotel}
+		availability, err := partner.Availability(ctx, other, req)
+		require.NoError(t, err)
+		assert.True(t, availability.Available)
+		assert.True(t, availability.InstantBook)
+		assert.Equal(t, types.CancellationFree, availability.CancellationPolicy)
+
+		first, _ := partner.Quote(ctx, other, req)
+		second, _ := partner.Quote(ctx, other, req)
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("deep links carry the request", func(t *testing.T) {
+		assert.Equal(t,
+			"https://partner.example.com/book/hotel/h-1?check_in=2026-07-01&check_out=2026-07-04&guests=2&rooms=2",
+			partner.DeepLink(hotel, req))
+		assert.Equal(t, "https://partner.example.com/book/hotel/h-1", partner.DeepLink(hotel, types.BookingRequest{}))
+	})
+}
+
+func TestService_CreateListing(t *testing.T) {
+	ctx := context.Background()
+	hotelID := uuid.New()
+
+	t.Run("validates the request", func(t *testing.T) {
+		service, repo := setupPartnersServiceTest(NewMockPartner())
+		for _, req := range []types.CreatePartnerListingRequest{
+			{Partner: "acme", ExternalID: "h-1", TargetKind: types.PartnerTargetHotel, TargetID: hotelID},
+			{Partner: MockPartnerKey, ExternalID: " ", TargetKind: types.PartnerTargetHotel, TargetID: hotelID},
+			{Partner: MockPartnerKey, ExternalID: "h-1", TargetKind: "museum", TargetID: hotelID},
+			{Partner: MockPartnerKey, ExternalID: "h-1", TargetKind: types.PartnerTargetHotel},
+		} {
+			_, err := service.CreateListing(ctx, req)
+			assert.ErrorIs(t, err, types.ErrBadRequest, "%+v", req)
+		}
+		repo.AssertNotCalled(t, "CreateListing", mock.Anything, mock.Anything)
+	})
+
+	t.Run("trims the external ID", func(t *testing.T) {
+		service, repo := setupPartnersServiceTest(NewMockPartner())
+		want := types.CreatePartnerListingRequest{Partner: MockPartnerKey, ExternalID: "h-1", TargetKind: types.PartnerTargetHotel, TargetID: hotelID}
+		repo.On("CreateListing", mock.Anything, want).Return(&types.PartnerListing{ID: uuid.New(), ExternalID: "h-1"}, nil)
+
+		want.ExternalID = " h-1 "
+		listing, err := service.CreateListing(ctx, want)
+
+		require.NoError(t, err)
+		assert.Equal(t, "h-1", listing.ExternalID)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestService_Offers(t *testing.T) {
+	ctx := context.Background()
+	hotelID := uuid.New()
+	checkIn := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	dated := types.BookingRequest{CheckIn: checkIn, CheckOut: checkIn.AddDate(0, 0, 2), Guests: 2}
+
+	mockPartner := NewMockPartner()
+	mockPartner.Listings["cheap-nonref"] = MockListing{Available: true, InstantBook: true, CancellationPolicy: types.CancellationNonRefundable, Rate: 80}
+	mockPartner.Listings["flexible"] = MockListing{Available: true, InstantBook: true, CancellationPolicy: types.CancellationFree, Rate: 120}
+	mockPartner.Listings["full"] = MockListing{CancellationPolicy: types.CancellationFree, Rate: 50}
+	failing := namedPartner{&MockPartner{Err: errors.New("partner down")}, "failing"}
+
+	listing := func(partner, externalID string) types.PartnerListing {
+		return types.PartnerListing{ID: uuid.New(), Partner: partner, ExternalID: externalID, TargetKind: types.PartnerTargetHotel, TargetID: hotelID}
+	}
+	listings := []types.PartnerListing{
+		listing("failing", "down-1"),
+		listing(MockPartnerKey, "full"),
+		listing(MockPartnerKey, "flexible"),
+		listing(MockPartnerKey, "cheap-nonref"),
+		listing("disabled", "gone-1"),
+	}
+
+	t.Run("ranks available offers the profile accepts first", func(t *testing.T) {
+		service, repo := setupPartnersServiceTest(mockPartner, failing)
+		repo.On("ListListings", mock.Anything, types.PartnerTargetHotel, &hotelID).Return(listings, nil)
+		prefs := &types.AccommodationPreferences{CancellationPolicy: []string{types.CancellationFree}}
+
+		offers := service.Offers(ctx, types.PartnerTargetHotel, hotelID, dated, prefs)
+
+		require.Len(t, offers, 4)
+		var ids []string
+		for _, o := range offers {
+			ids = append(ids, o.ExternalID)
+		}
+		assert.Equal(t, []string{"flexible", "cheap-nonref", "down-1", "full"}, ids)
+
+		assert.True(t, offers[0].MatchesPreferences)
+		assert.Equal(t, &types.PriceQuote{Amount: 240, Currency: "EUR"}, offers[0].Quote)
+		assert.False(t, offers[1].MatchesPreferences)
+		assert.Equal(t, 160.0, offers[1].Quote.Amount)
+		// A failing partner still offers its booking page
+		assert.Nil(t, offers[2].Available)
+		assert.NotEmpty(t, offers[2].DeepLink)
+		// Unavailable listings are not quoted
+		assert.False(t, *offers[3].Available)
+		assert.Nil(t, offers[3].Quote)
+	})
+
+	t.Run("without dates only links", func(t *testing.T) {
+		service, repo := setupPartnersServiceTest(mockPartner)
+		repo.On("ListListings", mock.Anything, types.PartnerTargetHotel, &hotelID).Return(listings[1:2], nil)
+
+		offers := service.Offers(ctx, types.PartnerTargetHotel, hotelID, types.BookingRequest{}, nil)
+
+		require.Len(t, offers, 1)
+		assert.Nil(t, offers[0].Available)
+		assert.Nil(t, offers[0].Quote)
+		assert.Equal(t, "https://partner.example.com/book/hotel/full", offers[0].DeepLink)
+	})
+
+	t.Run("instant booking preference", func(t *testing.T) {
+		offer := types.PartnerOffer{CancellationPolicy: types.CancellationFree}
+		assert.False(t, matchesPreferences(offer, &types.AccommodationPreferences{BookingFlexibility: types.BookingInstant}))
+		assert.True(t, matchesPreferences(offer, &types.AccommodationPreferences{BookingFlexibility: types.BookingRequestOnly}))
+		assert.True(t, matchesPreferences(offer, nil))
+	})
+
+	t.Run("repository errors leave no offers", func(t *testing.T) {
+		service, repo := setupPartnersServiceTest(mockPartner)
+		repo.On("ListListings", mock.Anything, types.PartnerTargetHotel, &hotelID).Return(nil, errors.New("db down"))
+
+		assert.Empty(t, service.Offers(ctx, types.PartnerTargetHotel, hotelID, dated, nil))
+	})
+}
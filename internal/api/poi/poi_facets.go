@@ -0,0 +1,221 @@
+package poi
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+const (
+	// ratingBucketSQL puts a POI's average rating in a non-overlapping bucket,
+	// so selecting several buckets reads as "any of".
+	ratingBucketSQL = `CASE
+            WHEN average_rating IS NULL THEN 'unrated'
+            WHEN average_rating >= 4.5 THEN '4.5-5'
+            WHEN average_rating >= 4 THEN '4-4.5'
+            WHEN average_rating >= 3 THEN '3-4'
+            ELSE 'under-3'
+        END`
+	// distanceRingSQL puts the distance in meters given by %[1]s in a ring
+	// around the search origin.
+	distanceRingSQL = `CASE
+            WHEN %[1]s < 1000 THEN '0-1km'
+            WHEN %[1]s < 3000 THEN '1-3km'
+            WHEN %[1]s < 5000 THEN '3-5km'
+            WHEN %[1]s < 10000 THEN '5-10km'
+            ELSE 'over-10km'
+        END`
+	// maxTagBuckets caps the tag facet, which has a long tail. Selected tags
+	// are always kept.
+	maxTagBuckets = 20
+)
+
+// facetOrder is how bucketed facets are listed; other facets are listed by count.
+var facetOrder = map[string][]string{
+	types.FacetPriceLevel: {"free", "1", "2", "3", "4"},
+	types.FacetRating:     {"4.5-5", "4-4.5", "3-4", "under-3", "unrated"},
+	types.FacetDistance:   {"0-1km", "1-3km", "3-5km", "5-10km", "over-10km"},
+}
+
+// facet is how a POI's value of a facet is computed.
+type facet struct {
+	name string
+	expr string
+	// multi facets have an array of values, e.g. tags
+	multi bool
+}
+
+// facetFilter holds the facet selection of a search. Its values are query
+// arguments shared by the results query and the facet counts, so both are
+// built from the same argument list.
+type facetFilter struct {
+	facets    []facet
+	selection types.FacetSelection
+	params    map[string]int
+}
+
+// newFacetFilter appends the selected values to args. distanceSQL is the
+// distance in meters from the search origin; without one there is no distance
+// facet.
+func newFacetFilter(selection types.FacetSelection, distanceSQL string, args *[]interface{}) facetFilter {
+	f := facetFilter{
+		facets: []facet{
+			{name: types.FacetCategory, expr: `NULLIF(category, '')`},
+			{name: types.FacetPriceLevel, expr: `COALESCE(price_level::text, 'free')`},
+			{name: types.FacetRating, expr: ratingBucketSQL},
+			{name: types.FacetTag, expr: `COALESCE(tags, '{}')`, multi: true},
+		},
+		selection: selection,
+		params:    make(map[string]int),
+	}
+	if distanceSQL != "" {
+		f.facets = append(f.facets, facet{name: types.FacetDistance, expr: fmt.Sprintf(distanceRingSQL, distanceSQL)})
+	}
+
+	for _, fc := range f.facets {
+		if values := selection.Values(fc.name); len(values) > 0 {
+			*args = append(*args, values)
+			f.params[fc.name] = len(*args)
+		}
+	}
+	return f
+}
+
+// condition matches column against the selection of fc, or is empty when
+// nothing of fc is selected.
+func (f facetFilter) condition(fc facet, column string) string {
+	n, ok := f.params[fc.name]
+	if !ok {
+		return ""
+	}
+	if fc.multi {
+		return fmt.Sprintf(`%s && $%d::text[]`, column, n)
+	}
+	return fmt.Sprintf(`%s = ANY($%d::text[])`, column, n)
+}
+
+// where returns the conditions of the selection on the POI's own columns.
+func (f facetFilter) where() []string {
+	var conditions []string
+	for _, fc := range f.facets {
+		if c := f.condition(fc, "("+fc.expr+")"); c != "" {
+			conditions = append(conditions, c)
+		}
+	}
+	return conditions
+}
+
+// countQuery counts every facet's values over the POIs matching baseWhere.
+// Each facet is counted with every selection but its own, so the other values
+// of a multi-select facet keep their counts.
+func (f facetFilter) countQuery(baseWhere string) string {
+	// Every selection is evaluated once, as a flag, so each argument is used
+	// even by a query whose only selection is the facet being counted
+	var columns []string
+	for _, fc := range f.facets {
+		columns = append(columns, fmt.Sprintf(`%s AS f_%s`, fc.expr, fc.name))
+		if c := f.condition(fc, "("+fc.expr+")"); c != "" {
+			columns = append(columns, fmt.Sprintf(`%s AS s_%s`, c, fc.name))
+		}
+	}
+
+	counts := make([]string, len(f.facets))
+	for i, fc := range f.facets {
+		others := []string{"TRUE"}
+		for _, other := range f.facets {
+			if other.name == fc.name {
+				continue
+			}
+			if _, ok := f.params[other.name]; ok {
+				others = append(others, "s_"+other.name)
+			}
+		}
+		from, value := `faceted`, `f_`+fc.name
+		if fc.multi {
+			from, value = `faceted, UNNEST(f_`+fc.name+`) AS v`, `v`
+		}
+		counts[i] = fmt.Sprintf(`
+        SELECT '%s', %s, COUNT(*) FROM %s
+        WHERE %s IS NOT NULL AND %s
+        GROUP BY %s`, fc.name, value, from, value, strings.Join(others, " AND "), value)
+	}
+
+	return fmt.Sprintf(`
+        WITH faceted AS (
+            SELECT %s
+            FROM points_of_interest
+            WHERE %s
+        )%s`, strings.Join(columns, ",\n                   "), baseWhere, strings.Join(counts, "\n        UNION ALL"))
+}
+
+// scan reads the rows of countQuery into facets, marking the selected values.
+// Selected values without results are listed with a zero count so they can be
+// cleared.
+func (f facetFilter) scan(rows pgx.Rows) (types.POIFacets, error) {
+	defer rows.Close()
+
+	facets := types.POIFacets{
+		Categories:  []types.FacetBucket{},
+		PriceLevels: []types.FacetBucket{},
+		Ratings:     []types.FacetBucket{},
+		Tags:        []types.FacetBucket{},
+	}
+	for rows.Next() {
+		var name string
+		var bucket types.FacetBucket
+		if err := rows.Scan(&name, &bucket.Value, &bucket.Count); err != nil {
+			return facets, fmt.Errorf("failed to scan facet row: %w", err)
+		}
+		if buckets := facets.Buckets(name); buckets != nil {
+			*buckets = append(*buckets, bucket)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return facets, fmt.Errorf("error iterating facet rows: %w", err)
+	}
+
+	for _, fc := range f.facets {
+		buckets := facets.Buckets(fc.name)
+		selected := f.selection.Values(fc.name)
+		for _, value := range selected {
+			if !slices.ContainsFunc(*buckets, func(b types.FacetBucket) bool { return b.Value == value }) {
+				*buckets = append(*buckets, types.FacetBucket{Value: value})
+			}
+		}
+		for i := range *buckets {
+			(*buckets)[i].Selected = slices.Contains(selected, (*buckets)[i].Value)
+		}
+		sortFacetBuckets(fc.name, *buckets)
+		if fc.name == types.FacetTag && len(*buckets) > maxTagBuckets {
+			kept := slices.Clone((*buckets)[:maxTagBuckets])
+			for _, b := range (*buckets)[maxTagBuckets:] {
+				if b.Selected {
+					kept = append(kept, b)
+				}
+			}
+			*buckets = kept
+		}
+	}
+	return facets, nil
+}
+
+// sortFacetBuckets lists bucketed facets in their natural order and the rest
+// by count, then value.
+func sortFacetBuckets(name string, buckets []types.FacetBucket) {
+	if order, ok := facetOrder[name]; ok {
+		slices.SortStableFunc(buckets, func(a, b types.FacetBucket) int {
+			return slices.Index(order, a.Value) - slices.Index(order, b.Value)
+		})
+		return
+	}
+	slices.SortStableFunc(buckets, func(a, b types.FacetBucket) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Value, b.Value)
+	})
+}
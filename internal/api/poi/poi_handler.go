@@ -1,7 +1,9 @@
 package poi
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -37,7 +39,6 @@ type Handler interface {
 	SearchPOIsSemantic(w http.ResponseWriter, r *http.Request)
 	SearchPOIsSemanticByCity(w http.ResponseWriter, r *http.Request)
 	SearchPOIsHybrid(w http.ResponseWriter, r *http.Request)
-	GenerateEmbeddingsForPOIs(w http.ResponseWriter, r *http.Request)
 
 	GetItinerary(w http.ResponseWriter, r *http.Request)
 	GetItineraries(w http.ResponseWriter, r *http.Request)
@@ -192,10 +193,12 @@ func (h *HandlerImpl) RemovePoiFromFavourites(w http.ResponseWriter, r *http.Req
 
 // GetFavouritePOIsByUserID godoc
 // @Summary      Get User's Favourite POIs
-// @Description  Retrieves all points of interest that the authenticated user has marked as favourites
+// @Description  Retrieves a page of the points of interest that the authenticated user has marked as favourites, most recently added first. The Link header carries the next page.
 // @Tags         POI
 // @Accept       json
 // @Produce      json
+// @Param        cursor query string false "Cursor of the page to fetch, from the Link header; omit for the first page"
+// @Param        limit query int false "Number of POIs per page (default 20, max 100)"
 // @Success      200 {array} interface{} "List of favourite POIs"
 // @Failure      400 {object} types.Response "Invalid Input"
 // @Failure      401 {object} types.Response "Authentication required"
@@ -228,7 +231,13 @@ func (HandlerImpl *HandlerImpl) GetFavouritePOIsByUserID(w http.ResponseWriter,
 	span.SetAttributes(semconv.EnduserIDKey.String(userID.String()))
 	l = l.With(slog.String("userID", userID.String()))
 
-	favouritePOIs, err := HandlerImpl.poiService.GetFavouritePOIsByUserID(ctx, userID)
+	page, err := api.ParsePageRequest(r)
+	if err != nil {
+		api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	favouritePOIs, next, err := HandlerImpl.poiService.GetFavouritePOIsByUserID(ctx, userID, page)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to fetch favourite POIs by user ID", slog.Any("error", err))
 		api.ErrorResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("Failed to fetch favourite POIs: %s", err.Error()))
@@ -236,16 +245,19 @@ func (HandlerImpl *HandlerImpl) GetFavouritePOIsByUserID(w http.ResponseWriter,
 	}
 
 	l.InfoContext(ctx, "Successfully fetched favourite POIs by user ID")
+	api.SetPageLinks(w, r, next)
 	api.WriteJSONResponse(w, r, http.StatusOK, favouritePOIs)
 }
 
 // GetPOIsByCityID godoc
 // @Summary      Get POIs by City ID
-// @Description  Retrieves all points of interest for a specific city
+// @Description  Retrieves a page of the points of interest of a specific city, by name. The Link header carries the next page.
 // @Tags         POI
 // @Accept       json
 // @Produce      json
 // @Param        cityID path string true "City ID"
+// @Param        cursor query string false "Cursor of the page to fetch, from the Link header; omit for the first page"
+// @Param        limit query int false "Number of POIs per page (default 20, max 100)"
 // @Success      200 {array} interface{} "List of POIs in the city"
 // @Failure      400 {object} types.Response "Invalid Input"
 // @Failure      500 {object} types.Response "Internal Server Error"
@@ -274,7 +286,13 @@ func (h *HandlerImpl) GetPOIsByCityID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pois, err := h.poiService.GetPOIsByCityID(ctx, cityID)
+	page, err := api.ParsePageRequest(r)
+	if err != nil {
+		api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	pois, next, err := h.poiService.GetPOIsByCityID(ctx, cityID, page)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to get POIs by city ID", slog.Any("error", err))
 		api.ErrorResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("Failed to get POIs: %s", err.Error()))
@@ -282,13 +300,35 @@ func (h *HandlerImpl) GetPOIsByCityID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	l.InfoContext(ctx, "Successfully retrieved POIs by city ID")
+	api.SetPageLinks(w, r, next)
 	api.WriteJSONResponse(w, r, http.StatusOK, pois)
 	span.SetAttributes(semconv.EnduserIDKey.String(cityID.String()))
 	span.SetAttributes(semconv.HTTPResponseStatusCodeKey.Int(http.StatusOK))
 	span.SetAttributes(semconv.HTTPRouteKey.String("/poi/city"))
 }
 
-// GetPOI from DB handler
+// GetPOIs godoc
+// @Summary      Search POIs
+// @Description  Finds POIs near a location, optionally by category. With a text query (q) results are ranked by relevance across name, tags, description and address, tolerate typos, and carry a highlighted snippet; the radius is then optional.
+// @Tags         POI
+// @Produce      json
+// @Param        q query string false "Free text query"
+// @Param        lat query number false "Latitude"
+// @Param        lon query number false "Longitude"
+// @Param        radius query number false "Search radius in kilometers"
+// @Param        category query string false "POI category filter"
+// @Param        facet_category query []string false "Categories to include (multi-select)"
+// @Param        facet_price_level query []string false "Price levels to include: free, 1-4 (multi-select)"
+// @Param        facet_rating query []string false "Rating buckets to include (multi-select)"
+// @Param        facet_tag query []string false "Tags to include (multi-select)"
+// @Param        facet_distance query []string false "Distance rings to include (multi-select)"
+// @Param        profile_id query string false "Search profile whose accessibility needs apply (defaults to the user's default profile)"
+// @Param        accessibility query []string false "Accessibility features needed, overriding the profile's (e.g. step_free_entrance, hearing_loop)"
+// @Param        accessible_only query bool false "Leave out POIs whose accessibility is unknown"
+// @Param        limit query int false "Number of POIs to return, best first (default 50, max 100); facets count all matches"
+// @Success      200 {object} types.POISearchResult "Matching POIs with facet counts"
+// @Failure      500 {object} types.Response "Internal Server Error"
+// @Router       /pois/search [get]
 func (h *HandlerImpl) GetPOIs(w http.ResponseWriter, r *http.Request) {
 	ctx, span := otel.Tracer("SearchPOIs").Start(r.Context(), "SearchPOIs", trace.WithAttributes(
 		semconv.HTTPRequestMethodKey.String(r.Method),
@@ -302,22 +342,43 @@ func (h *HandlerImpl) GetPOIs(w http.ResponseWriter, r *http.Request) {
 	lon, _ := strconv.ParseFloat(r.URL.Query().Get("lon"), 64)
 	radius, _ := strconv.ParseFloat(r.URL.Query().Get("radius"), 64)
 	category := r.URL.Query().Get("category")
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	profileID, accessibility, err := parseAccessibilityFilter(r)
+	if err != nil {
+		api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	filter := types.POIFilter{
-		Location: types.GeoPoint{Latitude: lat, Longitude: lon},
-		Radius:   radius,
-		Category: category,
+		Location:      types.GeoPoint{Latitude: lat, Longitude: lon},
+		Radius:        radius,
+		Category:      category,
+		Query:         query,
+		Facets:        parseFacetSelection(r),
+		Accessibility: accessibility,
+		ProfileID:     profileID,
+		Limit:         limit,
 	}
 
-	pois, err := h.poiService.SearchPOIs(ctx, filter)
+	result, err := h.poiService.SearchPOIs(ctx, optionalUserID(ctx), filter)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to search POIs", slog.Any("error", err))
+		if errors.Is(err, types.ErrBadRequest) {
+			api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, types.ErrNotFound) {
+			api.ErrorResponse(w, r, http.StatusNotFound, "Search profile not found")
+			return
+		}
 		api.ErrorResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("Failed to search POIs: %s", err.Error()))
 		return
 	}
 
 	l.InfoContext(ctx, "Successfully searched POIs")
-	api.WriteJSONResponse(w, r, http.StatusOK, pois)
+	api.WriteJSONResponse(w, r, http.StatusOK, result)
 }
 
 // GetItinerary godoc
@@ -398,13 +459,13 @@ func (h *HandlerImpl) GetItinerary(w http.ResponseWriter, r *http.Request) {
 
 // GetItineraries godoc
 // @Summary      List Saved Itineraries
-// @Description  Retrieves a paginated list of saved itineraries for the authenticated user.
+// @Description  Retrieves a page of saved itineraries for the authenticated user, newest first. The Link header carries the next page.
 // @Tags         Itineraries
 // @Produce      json
-// @Param        page query int false "Page number for pagination (default 1)"
-// @Param        page_size query int false "Number of items per page (default 10)"
+// @Param        cursor query string false "Cursor of the page to fetch, from next_cursor or the Link header; omit for the first page"
+// @Param        limit query int false "Number of itineraries per page (default 20, max 100)"
 // @Success      200 {object} llmChat.PaginatedUserItinerariesResponse "Successfully retrieved list of itineraries"
-// @Failure      400 {object} types.Response "Invalid pagination parameters"
+// @Failure      400 {object} types.Response "Invalid cursor"
 // @Failure      401 {object} types.Response "Unauthorized"
 // @Failure      500 {object} types.Response "Internal server error"
 // @Security     BearerAuth
@@ -431,24 +492,16 @@ func (h *HandlerImpl) GetItineraries(w http.ResponseWriter, r *http.Request) {
 	}
 	span.SetAttributes(attribute.String("user.id", userID.String()))
 
-	// Get pagination parameters from query
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
-
-	if page <= 0 {
-		page = 1 // Default to page 1
-	}
-	if pageSize <= 0 {
-		pageSize = 10 // Default page size
-	}
-	if pageSize > 100 { // Max page size
-		pageSize = 100
+	page, err := api.ParsePageRequest(r)
+	if err != nil {
+		span.SetStatus(codes.Error, "Invalid cursor")
+		api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
-	span.SetAttributes(attribute.Int("query.page", page), attribute.Int("query.page_size", pageSize))
-	l = l.With(slog.Int("page", page), slog.Int("pageSize", pageSize))
+	span.SetAttributes(attribute.Int("query.limit", page.Limit), attribute.Bool("query.cursor", page.Cursor != nil))
 
 	l.DebugContext(ctx, "Attempting to fetch itineraries")
-	paginatedResponse, err := h.poiService.GetItineraries(ctx, userID, page, pageSize)
+	paginatedResponse, err := h.poiService.GetItineraries(ctx, userID, page)
 	if err != nil {
 		l.ErrorContext(ctx, "Service failed to get itineraries", slog.Any("error", err))
 		span.RecordError(err)
@@ -459,6 +512,7 @@ func (h *HandlerImpl) GetItineraries(w http.ResponseWriter, r *http.Request) {
 
 	l.InfoContext(ctx, "Successfully fetched itineraries", slog.Int("count", len(paginatedResponse.Itineraries)), slog.Int("total_records", paginatedResponse.TotalRecords))
 	span.SetStatus(codes.Ok, "Itineraries retrieved")
+	api.SetPageLinks(w, r, paginatedResponse.NextCursor)
 	api.WriteJSONResponse(w, r, http.StatusOK, paginatedResponse)
 }
 
@@ -726,9 +780,24 @@ func (h *HandlerImpl) SearchPOIsSemanticByCity(w http.ResponseWriter, r *http.Re
 // @Param        latitude query number true "User latitude"
 // @Param        longitude query number true "User longitude"
 // @Param        radius query number true "Search radius in kilometers"
-// @Param        semantic_weight query number false "Weight for semantic vs spatial (0.0-1.0, default: 0.5)"
+// @Param        semantic_weight query number false "Share of text relevance against proximity (0.0-1.0, default: 0.5)"
+// @Param        fusion query string false "How the rankings are fused: rrf (default) or linear"
+// @Param        w_lexical query number false "Weight of the lexical ranking"
+// @Param        w_semantic query number false "Weight of the semantic ranking"
+// @Param        w_spatial query number false "Weight of the proximity ranking"
+// @Param        w_rating query number false "Weight of the rating ranking"
+// @Param        w_popularity query number false "Weight of the popularity ranking"
+// @Param        explain query bool false "Attach each result's component scores, ranks and contributions"
 // @Param        category query string false "POI category filter"
-// @Success      200 {array} types.POIDetail "List of POIs ranked by hybrid score"
+// @Param        facet_category query []string false "Categories to include (multi-select)"
+// @Param        facet_price_level query []string false "Price levels to include: free, 1-4 (multi-select)"
+// @Param        facet_rating query []string false "Rating buckets to include (multi-select)"
+// @Param        facet_tag query []string false "Tags to include (multi-select)"
+// @Param        facet_distance query []string false "Distance rings to include (multi-select)"
+// @Param        profile_id query string false "Search profile whose accessibility needs apply (defaults to the user's default profile)"
+// @Param        accessibility query []string false "Accessibility features needed, overriding the profile's (e.g. step_free_entrance, hearing_loop)"
+// @Param        accessible_only query bool false "Leave out POIs whose accessibility is unknown"
+// @Success      200 {array} types.POIDetail "List of POIs ranked by hybrid score, with facet counts"
 // @Failure      400 {object} types.Response "Invalid Input"
 // @Failure      401 {object} types.Response "Authentication required"
 // @Failure      500 {object} types.Response "Internal Server Error"
@@ -796,17 +865,34 @@ func (h *HandlerImpl) SearchPOIsHybrid(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	opts, err := parseHybridSearchOptions(r, semanticWeight)
+	if err != nil {
+		l.ErrorContext(ctx, "Invalid hybrid search options", slog.Any("error", err))
+		api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// Get optional category filter
 	category := r.URL.Query().Get("category")
 
+	profileID, accessibility, err := parseAccessibilityFilter(r)
+	if err != nil {
+		l.ErrorContext(ctx, "Invalid accessibility filter", slog.Any("error", err))
+		api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// Build filter
 	filter := types.POIFilter{
 		Location: types.GeoPoint{
 			Latitude:  latitude,
 			Longitude: longitude,
 		},
-		Radius:   radius,
-		Category: category,
+		Radius:        radius,
+		Category:      category,
+		Facets:        parseFacetSelection(r),
+		Accessibility: accessibility,
+		ProfileID:     profileID,
 	}
 
 	span.SetAttributes(
@@ -815,15 +901,28 @@ func (h *HandlerImpl) SearchPOIsHybrid(w http.ResponseWriter, r *http.Request) {
 		attribute.Float64("location.longitude", longitude),
 		attribute.Float64("search.radius", radius),
 		attribute.Float64("semantic.weight", semanticWeight),
+		attribute.String("fusion", opts.Fusion),
+		attribute.Bool("explain", opts.Explain),
 		attribute.String("filter.category", category),
 	)
 
+	// Results are personalized for signed-in users
+	userID := optionalUserID(ctx)
+
 	// Perform hybrid search
-	pois, err := h.poiService.SearchPOIsHybrid(ctx, filter, query, semanticWeight)
+	result, err := h.poiService.SearchPOIsHybrid(ctx, userID, filter, query, opts)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to perform hybrid search", slog.Any("error", err))
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "Hybrid search failed")
+		if errors.Is(err, types.ErrBadRequest) {
+			api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, types.ErrNotFound) {
+			api.ErrorResponse(w, r, http.StatusNotFound, "Search profile not found")
+			return
+		}
 		api.ErrorResponse(w, r, http.StatusInternalServerError, "Failed to perform hybrid search")
 		return
 	}
@@ -831,94 +930,138 @@ func (h *HandlerImpl) SearchPOIsHybrid(w http.ResponseWriter, r *http.Request) {
 	l.InfoContext(ctx, "Hybrid search completed",
 		slog.String("query", query),
 		slog.Float64("semantic_weight", semanticWeight),
-		slog.Int("results", len(pois)))
-	span.SetAttributes(attribute.Int("results.count", len(pois)))
+		slog.Int("results", len(result.POIs)))
+	span.SetAttributes(attribute.Int("results.count", len(result.POIs)))
 	span.SetStatus(codes.Ok, "Hybrid search completed")
 
 	api.WriteJSONResponse(w, r, http.StatusOK, map[string]interface{}{
 		"query":           query,
 		"semantic_weight": semanticWeight,
+		"fusion":          opts.Fusion,
+		"weights":         opts.Weights,
 		"filter":          filter,
-		"results":         pois,
-		"count":           len(pois),
+		"results":         result.POIs,
+		"facets":          result.Facets,
+		"count":           len(result.POIs),
 	})
 }
 
-// GenerateEmbeddingsForPOIs godoc
-// @Summary      Generate Embeddings for POIs
-// @Description  Manually trigger embedding generation for POIs that don't have embeddings
-// @Tags         POI
-// @Accept       json
-// @Produce      json
-// @Param        batch_size query int false "Batch size for processing (default: 10, max: 100)"
-// @Success      200 {object} interface{} "Embedding generation status"
-// @Failure      400 {object} types.Response "Invalid Input"
-// @Failure      401 {object} types.Response "Authentication required"
-// @Failure      500 {object} types.Response "Internal Server Error"
-// @Security     BearerAuth
-// @Router       /poi/embeddings/generate [post]
-func (h *HandlerImpl) GenerateEmbeddingsForPOIs(w http.ResponseWriter, r *http.Request) {
-	ctx, span := otel.Tracer("GenerateEmbeddingsForPOIs").Start(r.Context(), "GenerateEmbeddingsForPOIs", trace.WithAttributes(
-		semconv.HTTPRequestMethodKey.String(r.Method),
-		semconv.HTTPRouteKey.String("/poi/embeddings/generate"),
-	))
-	defer span.End()
-
-	l := h.logger.With(slog.String("handler", "GenerateEmbeddingsForPOIs"))
-	l.DebugContext(ctx, "Generate embeddings endpoint invoked")
-
-	// Check for admin/authorized user (you might want to add specific authorization here)
-	userIDStr, ok := auth.GetUserIDFromContext(ctx)
-	if !ok || userIDStr == "" {
-		l.ErrorContext(ctx, "User ID not found in context")
-		api.ErrorResponse(w, r, http.StatusUnauthorized, "Authentication required")
-		return
+// optionalUserID returns the signed-in user, or uuid.Nil on public requests.
+func optionalUserID(ctx context.Context) uuid.UUID {
+	if userIDStr, ok := auth.GetUserIDFromContext(ctx); ok {
+		if parsed, err := uuid.Parse(userIDStr); err == nil {
+			return parsed
+		}
 	}
+	return uuid.Nil
+}
 
-	// Parse batch size
-	batchSizeStr := r.URL.Query().Get("batch_size")
-	batchSize := 10 // default
-	if batchSizeStr != "" {
-		var err error
-		batchSize, err = strconv.Atoi(batchSizeStr)
-		if err != nil || batchSize <= 0 || batchSize > 100 {
-			l.ErrorContext(ctx, "Invalid batch size", slog.String("batch_size", batchSizeStr))
-			api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid batch size (must be 1-100)")
-			return
+// parseAccessibilityFilter reads the search profile whose accessibility needs
+// apply (profile_id), or needs given outright: accessibility features, repeated
+// or comma separated, and accessible_only. Needs given outright override the
+// profile's; nil needs leave them to the profile.
+func parseAccessibilityFilter(r *http.Request) (uuid.UUID, *types.AccessibilityNeeds, error) {
+	q := r.URL.Query()
+	profileID := uuid.Nil
+	if raw := q.Get("profile_id"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			return uuid.Nil, nil, fmt.Errorf("invalid profile_id")
 		}
+		profileID = parsed
 	}
 
-	span.SetAttributes(attribute.Int("batch.size", batchSize))
+	if !q.Has("accessibility") && !q.Has("accessible_only") {
+		return profileID, nil, nil
+	}
+	needs := &types.AccessibilityNeeds{}
+	for _, raw := range q["accessibility"] {
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				needs.Features = append(needs.Features, v)
+			}
+		}
+	}
+	if raw := q.Get("accessible_only"); raw != "" {
+		accessibleOnly, err := strconv.ParseBool(raw)
+		if err != nil {
+			return uuid.Nil, nil, fmt.Errorf("invalid accessible_only")
+		}
+		needs.AccessibleOnly = accessibleOnly
+	}
+	return profileID, needs, needs.Validate()
+}
 
-	// Generate embeddings for all POIs
-	err := h.poiService.GenerateEmbeddingsForAllPOIs(ctx, batchSize)
-	if err != nil {
-		l.ErrorContext(ctx, "Failed to generate embeddings", slog.Any("error", err))
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Embedding generation failed")
-
-		// Check if it's a partial failure
-		if strings.Contains(err.Error(), "errors out of") {
-			api.WriteJSONResponse(w, r, http.StatusPartialContent, map[string]interface{}{
-				"status":     "partial_success",
-				"message":    err.Error(),
-				"batch_size": batchSize,
-			})
-			return
+// parseFacetSelection reads multi-select facet filters from the query string.
+// A facet's values can be repeated (facet_tag=art&facet_tag=food) or comma
+// separated (facet_tag=art,food).
+func parseFacetSelection(r *http.Request) types.FacetSelection {
+	var selection types.FacetSelection
+	params := []struct {
+		param  string
+		values *[]string
+	}{
+		{"facet_category", &selection.Categories},
+		{"facet_price_level", &selection.PriceLevels},
+		{"facet_rating", &selection.Ratings},
+		{"facet_tag", &selection.Tags},
+		{"facet_distance", &selection.Distances},
+	}
+	for _, p := range params {
+		for _, raw := range r.URL.Query()[p.param] {
+			for _, v := range strings.Split(raw, ",") {
+				if v = strings.TrimSpace(v); v != "" {
+					*p.values = append(*p.values, v)
+				}
+			}
 		}
+	}
+	return selection
+}
 
-		api.ErrorResponse(w, r, http.StatusInternalServerError, "Failed to generate embeddings")
-		return
+// parseHybridSearchOptions reads the fusion mode, the per-signal weight
+// overrides and explain from the query string. Weights not overridden follow
+// semanticWeight.
+func parseHybridSearchOptions(r *http.Request, semanticWeight float64) (types.HybridSearchOptions, error) {
+	q := r.URL.Query()
+	opts := types.DefaultHybridSearchOptions()
+	opts.Weights = types.HybridWeightsFromSemantic(semanticWeight)
+
+	if fusion := q.Get("fusion"); fusion != "" {
+		opts.Fusion = fusion
+	}
+
+	overrides := []struct {
+		param  string
+		weight *float64
+	}{
+		{"w_lexical", &opts.Weights.Lexical},
+		{"w_semantic", &opts.Weights.Semantic},
+		{"w_spatial", &opts.Weights.Spatial},
+		{"w_rating", &opts.Weights.Rating},
+		{"w_popularity", &opts.Weights.Popularity},
+	}
+	for _, o := range overrides {
+		raw := q.Get(o.param)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return opts, fmt.Errorf("invalid %s: %q is not a number", o.param, raw)
+		}
+		*o.weight = v
 	}
 
-	l.InfoContext(ctx, "Embeddings generation completed", slog.Int("batch_size", batchSize))
-	span.SetStatus(codes.Ok, "Embeddings generated successfully")
+	if raw := q.Get("explain"); raw != "" {
+		explain, err := strconv.ParseBool(raw)
+		if err != nil {
+			return opts, fmt.Errorf("invalid explain: %q is not a boolean", raw)
+		}
+		opts.Explain = explain
+	}
 
-	api.WriteJSONResponse(w, r, http.StatusOK, map[string]interface{}{
-		"status":     "success",
-		"message":    "Embeddings generated successfully for all POIs",
-		"batch_size": batchSize,
-	})
+	return opts, opts.Validate()
 }
 
 // TODO GetPOIsByDistance test this
@@ -1008,8 +1151,14 @@ func (HandlerImpl *HandlerImpl) GetPOIsByDistance(w http.ResponseWriter, r *http
 
 	// Call service method with filters
 	var pois []types.POIDetailedInfo
-	if len(filters) > 0 {
-		pois, err = HandlerImpl.poiService.GetGeneralPOIByDistanceWithFilters(ctx, userID, lat, lon, distance, filters)
+	var facets *types.POIFacets
+	selection := parseFacetSelection(r)
+	if len(filters) > 0 || !selection.IsEmpty() {
+		var result *types.POISearchResult
+		result, err = HandlerImpl.poiService.GetGeneralPOIByDistanceWithFilters(ctx, userID, lat, lon, distance, filters, selection)
+		if err == nil {
+			pois, facets = result.POIs, &result.Facets
+		}
 	} else {
 		pois, err = HandlerImpl.poiService.GetGeneralPOIByDistance(ctx, userID, lat, lon, distance)
 	}
@@ -1022,7 +1171,8 @@ func (HandlerImpl *HandlerImpl) GetPOIsByDistance(w http.ResponseWriter, r *http
 	// Prepare response
 	response := struct {
 		PointsOfInterest []types.POIDetailedInfo `json:"points_of_interest"`
-	}{PointsOfInterest: pois}
+		Facets           *types.POIFacets        `json:"facets,omitempty"`
+	}{PointsOfInterest: pois, Facets: facets}
 
 	// Encode response
 	w.Header().Set("Content-Type", "application/json")
@@ -36,10 +36,11 @@ func calculateDistance(lat1, lon1, lat2, lon2 float64) float64 {
 	return distance
 }
 
-func generateFilteredPOICacheKeyWithFilters(lat, lon, distance float64, filters map[string]string, userID uuid.UUID) string {
+func generateFilteredPOICacheKeyWithFilters(lat, lon, distance float64, filters map[string]string, selection types.FacetSelection, userID uuid.UUID) string {
 	// Serialize filters to JSON for consistent cache key
 	filtersJSON, _ := json.Marshal(filters)
-	return fmt.Sprintf("poi_filtered:%f:%f:%f:%s:%s", lat, lon, distance, userID.String(), string(filtersJSON))
+	selectionJSON, _ := json.Marshal(selection)
+	return fmt.Sprintf("poi_filtered:%f:%f:%f:%s:%s:%s", lat, lon, distance, userID.String(), string(filtersJSON), string(selectionJSON))
 }
 
 func generateFilteredPOICacheKey(lat, lon, distance float64, userID uuid.UUID) string {
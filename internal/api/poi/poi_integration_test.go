@@ -4,13 +4,14 @@ package poi
 
 import (
 	"context"
-	"database/sql"
 	"log"
 	"log/slog"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/analytics"
+	generativeAI "github.com/FACorreiaa/go-poi-au-suggestions/internal/api/generative_ai"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types" // Adjust path
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -21,7 +22,7 @@ import (
 )
 
 var testDB *pgxpool.Pool
-var testService POIService // Use the interface
+var testService Service // Use the interface
 
 func TestMain(m *testing.M) {
 	// Load .env.test or similar for test database credentials
@@ -65,8 +66,8 @@ func TestMain(m *testing.M) {
 
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
 	// Use your actual PostgresPOIRepository implementation
-	realRepo := NewPostgresPOIRepository(testDB, logger) // Assuming this constructor exists
-	testService = NewPOIServiceImpl(realRepo, logger)
+	realRepo := NewRepository(testDB, logger)
+	testService = NewServiceImpl(realRepo, &generativeAI.EmbeddingService{}, nil, analytics.NopEmitter{}, logger)
 
 	// Run tests
 	exitCode := m.Run()
@@ -105,12 +106,12 @@ func insertTestCity(t *testing.T, id uuid.UUID, name string) {
 		id, name, "Testland")
 	require.NoError(t, err)
 }
-func insertTestPOI(t *testing.T, id uuid.UUID, name string, cityID uuid.UUID) types.POIDetail {
+func insertTestPOI(t *testing.T, id uuid.UUID, name string, cityID uuid.UUID) types.POIDetailedInfo {
 	t.Helper()
-	poi := types.POIDetail{ID: id, Name: name, CityID: cityID, Latitude: 1.0, Longitude: 1.0, Category: "Test"} // Add other required fields
+	poi := types.POIDetailedInfo{ID: id, Name: name, CityID: cityID, Latitude: 1.0, Longitude: 1.0, Category: "Test"} // Add other required fields
 	_, err := testDB.Exec(context.Background(),
 		"INSERT INTO pois (id, city_id, name, description_poi, latitude, longitude, location, category) VALUES ($1, $2, $3, $4, $5, $6, ST_SetSRID(ST_MakePoint($7, $8), 4326), $9) ON CONFLICT (id) DO NOTHING",
-		poi.ID, poi.CityID, poi.Name, sql.NullString{String: "Desc", Valid: true}, poi.Latitude, poi.Longitude, poi.Longitude, poi.Latitude, poi.Category)
+		poi.ID, poi.CityID, poi.Name, "Desc", poi.Latitude, poi.Longitude, poi.Longitude, poi.Latitude, poi.Category)
 	require.NoError(t, err)
 	return poi
 }
@@ -132,7 +133,7 @@ func TestPOIServiceImpl_Favourites_Integration(t *testing.T) {
 		require.NoError(t, err)
 		assert.NotEqual(t, uuid.Nil, favID) // Repository should return the ID of the favourite entry
 
-		favourites, err := testService.GetFavouritePOIsByUserID(ctx, userID)
+		favourites, _, err := testService.GetFavouritePOIsByUserID(ctx, userID, types.PageRequest{})
 		require.NoError(t, err)
 		require.Len(t, favourites, 1)
 		assert.Equal(t, poiID1, favourites[0].ID) // Assuming GetFavouritePOIsByUserID returns POIDetail with the original POI's ID
@@ -146,7 +147,7 @@ func TestPOIServiceImpl_Favourites_Integration(t *testing.T) {
 		err := testService.RemovePoiFromFavourites(ctx, poiID1, userID)
 		require.NoError(t, err)
 
-		favourites, err := testService.GetFavouritePOIsByUserID(ctx, userID)
+		favourites, _, err := testService.GetFavouritePOIsByUserID(ctx, userID, types.PageRequest{})
 		require.NoError(t, err)
 		assert.Empty(t, favourites)
 	})
@@ -156,7 +157,7 @@ func TestPOIServiceImpl_Favourites_Integration(t *testing.T) {
 		otherUserID := uuid.New()
 		insertTestUser(t, otherUserID, "nofav_user")
 
-		favourites, err := testService.GetFavouritePOIsByUserID(ctx, otherUserID)
+		favourites, _, err := testService.GetFavouritePOIsByUserID(ctx, otherUserID, types.PageRequest{})
 		require.NoError(t, err)
 		assert.Empty(t, favourites)
 	})
@@ -175,7 +176,7 @@ func TestPOIServiceImpl_GetPOIsByCityID_Integration(t *testing.T) {
 	// No POIs for cityID2 initially
 
 	t.Run("Get POIs for city with POIs", func(t *testing.T) {
-		pois, err := testService.GetPOIsByCityID(ctx, cityID1)
+		pois, _, err := testService.GetPOIsByCityID(ctx, cityID1, types.PageRequest{})
 		require.NoError(t, err)
 		require.Len(t, pois, 2)
 		// Check if poi1 and poi2 are in the list (order might not be guaranteed)
@@ -194,7 +195,7 @@ func TestPOIServiceImpl_GetPOIsByCityID_Integration(t *testing.T) {
 	})
 
 	t.Run("Get POIs for city with no POIs", func(t *testing.T) {
-		pois, err := testService.GetPOIsByCityID(ctx, cityID2)
+		pois, _, err := testService.GetPOIsByCityID(ctx, cityID2, types.PageRequest{})
 		require.NoError(t, err)
 		assert.Empty(t, pois)
 	})
@@ -210,52 +211,73 @@ func TestPOIServiceImpl_SearchPOIs_Integration(t *testing.T) {
 	require.NoError(t, err)
 
 	// Seed specific POIs for searching
-	poiMuseum := types.POIDetail{ID: uuid.New(), CityID: searchCityID, Name: "Grand Museum", Category: "Museum", Latitude: 10.0, Longitude: 10.0, DescriptionPOI: sql.NullString{String: "Ancient artifacts", Valid: true}}
-	poiPark := types.POIDetail{ID: uuid.New(), CityID: searchCityID, Name: "City Park", Category: "Park", Latitude: 10.1, Longitude: 10.1, DescriptionPOI: sql.NullString{String: "Green space", Valid: true}}
-	poiCafe := types.POIDetail{ID: uuid.New(), CityID: searchCityID, Name: "Art Cafe", Category: "Cafe", Latitude: 10.05, Longitude: 10.05, DescriptionPOI: sql.NullString{String: "Coffee and art", Valid: true}, Tags: []string{"art", "coffee"}}
+	poiMuseum := types.POIDetailedInfo{ID: uuid.New(), CityID: searchCityID, Name: "Grand Museum", Category: "Museum", Latitude: 10.0, Longitude: 10.0, Description: "Ancient artifacts"}
+	poiPark := types.POIDetailedInfo{ID: uuid.New(), CityID: searchCityID, Name: "City Park", Category: "Park", Latitude: 10.1, Longitude: 10.1, Description: "Green space"}
+	poiCafe := types.POIDetailedInfo{ID: uuid.New(), CityID: searchCityID, Name: "Art Cafe", Category: "Cafe", Latitude: 10.05, Longitude: 10.05, Description: "Coffee and art", Tags: []string{"art", "coffee"}}
 
 	insertPOIForIntegration(t, poiMuseum)
 	insertPOIForIntegration(t, poiPark)
 	insertPOIForIntegration(t, poiCafe)
+	searchCenter := types.GeoPoint{Latitude: 10.05, Longitude: 10.05}
 
 	t.Run("Search by category", func(t *testing.T) {
-		filter := types.POIFilter{CityID: searchCityID, Category: "Museum"}
-		pois, err := testService.SearchPOIs(ctx, filter)
+		filter := types.POIFilter{Location: searchCenter, Radius: 50, Category: "Museum"}
+		result, err := testService.SearchPOIs(ctx, uuid.Nil, filter)
 		require.NoError(t, err)
-		require.Len(t, pois, 1)
-		assert.Equal(t, "Grand Museum", pois[0].Name)
+		require.Len(t, result.POIs, 1)
+		assert.Equal(t, "Grand Museum", result.POIs[0].Name)
 	})
 
 	t.Run("Search by tag", func(t *testing.T) {
-		filter := types.POIFilter{CityID: searchCityID, Tags: []string{"art"}}
-		pois, err := testService.SearchPOIs(ctx, filter)
+		filter := types.POIFilter{Location: searchCenter, Radius: 50, Facets: types.FacetSelection{Tags: []string{"art"}}}
+		result, err := testService.SearchPOIs(ctx, uuid.Nil, filter)
 		require.NoError(t, err)
-		require.Len(t, pois, 1) // Only Art Cafe has "art" tag in this setup
-		assert.Equal(t, "Art Cafe", pois[0].Name)
+		require.Len(t, result.POIs, 1) // Only Art Cafe has "art" tag in this setup
+		assert.Equal(t, "Art Cafe", result.POIs[0].Name)
 	})
 
 	t.Run("Search by name substring", func(t *testing.T) {
-		filter := types.POIFilter{CityID: searchCityID, Name: "grand"} // Case-insensitive search usually
-		pois, err := testService.SearchPOIs(ctx, filter)
+		filter := types.POIFilter{Location: searchCenter, Radius: 50, Query: "grand"} // Case-insensitive search usually
+		result, err := testService.SearchPOIs(ctx, uuid.Nil, filter)
 		require.NoError(t, err)
-		require.Len(t, pois, 1)
-		assert.Equal(t, "Grand Museum", pois[0].Name)
+		require.Len(t, result.POIs, 1)
+		assert.Equal(t, "Grand Museum", result.POIs[0].Name)
 	})
 
 	t.Run("Search with no results", func(t *testing.T) {
-		filter := types.POIFilter{CityID: searchCityID, Category: "Zoo"}
-		pois, err := testService.SearchPOIs(ctx, filter)
+		filter := types.POIFilter{Location: searchCenter, Radius: 50, Category: "Zoo"}
+		result, err := testService.SearchPOIs(ctx, uuid.Nil, filter)
 		require.NoError(t, err)
-		assert.Empty(t, pois)
+		assert.Empty(t, result.POIs)
+	})
+}
+
+func TestServiceImpl_GetGeneralPOIByDistanceResponse_Integration(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	insertTestUser(t, userID, "distanceuser")
+	insertTestCity(t, uuid.New(), "Lisbon")
+
+	t.Run("Get POIs by distance", func(t *testing.T) {
+		lat := 38.7223
+		lon := -9.1393
+		distance := 5.0 // 5km radius
+
+		pois, err := testService.GetGeneralPOIByDistance(ctx, userID, lat, lon, distance)
+		require.NoError(t, err)
+
+		// POIs should be empty if no test data exists, but method should succeed
+		assert.NotNil(t, pois)
+		// assert.GreaterOrEqual(t, len(pois), 0) // Could be 0 if no POIs in test DB
 	})
 }
 
 // Helper for SearchPOIs_Integration
-func insertPOIForIntegration(t *testing.T, poi types.POIDetail) {
+func insertPOIForIntegration(t *testing.T, poi types.POIDetailedInfo) {
 	t.Helper()
 	_, err := testDB.Exec(context.Background(),
 		"INSERT INTO pois (id, city_id, name, description_poi, latitude, longitude, location, category, tags) VALUES ($1, $2, $3, $4, $5, $6, ST_SetSRID(ST_MakePoint($7, $8), 4326), $9, $10) ON CONFLICT (id) DO NOTHING",
-		poi.ID, poi.CityID, poi.Name, poi.DescriptionPOI, poi.Latitude, poi.Longitude, poi.Longitude, poi.Latitude, poi.Category, pq.Array(poi.Tags)) // Use pq.Array for []string
+		poi.ID, poi.CityID, poi.Name, poi.Description, poi.Latitude, poi.Longitude, poi.Longitude, poi.Latitude, poi.Category, poi.Tags)
 	require.NoError(t, err)
 }
 
@@ -18,6 +18,8 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/feedback"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/jobs"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
 	"github.com/google/uuid"
 
@@ -32,32 +34,65 @@ type Repository interface {
 	//GetPOIsByNamesAndCitySortedByDistance(ctx context.Context, names []string, cityID uuid.UUID, userLocation types.UserLocation) ([]types.POIDetailedInfo, error)
 	GetPOIsByCityAndDistance(ctx context.Context, cityID uuid.UUID, userLocation types.UserLocation) ([]types.POIDetailedInfo, error)
 	GetPOIsByLocationAndDistance(ctx context.Context, lat, lon, radiusMeters float64) ([]types.POIDetailedInfo, error)
-	GetPOIsByLocationAndDistanceWithFilters(ctx context.Context, lat, lon, radiusMeters float64, filters map[string]string) ([]types.POIDetailedInfo, error)
+	GetPOIsByLocationAndDistanceWithFilters(ctx context.Context, lat, lon, radiusMeters float64, filters map[string]string, selection types.FacetSelection) (*types.POISearchResult, error)
 	AddPoiToFavourites(ctx context.Context, userID, poiID uuid.UUID) (uuid.UUID, error)
 	RemovePoiFromFavourites(ctx context.Context, poiID uuid.UUID, userID uuid.UUID) error
-	GetFavouritePOIsByUserID(ctx context.Context, userID uuid.UUID) ([]types.POIDetailedInfo, error)
-	GetPOIsByCityID(ctx context.Context, cityID uuid.UUID) ([]types.POIDetailedInfo, error)
+	// GetFavouritePOIsByUserID returns a page of the user's favourites, most
+	// recently added first, and the cursor of the next page.
+	GetFavouritePOIsByUserID(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]types.POIDetailedInfo, *types.Cursor, error)
+	// GetPOIsByCityID returns a page of the city's POIs by name.
+	GetPOIsByCityID(ctx context.Context, cityID uuid.UUID, page types.PageRequest) ([]types.POIDetailedInfo, *types.Cursor, error)
 
 	// POI details
 	FindPOIDetails(ctx context.Context, cityID uuid.UUID, lat, lon float64, tolerance float64) (*types.POIDetailedInfo, error)
 	SavePOIDetails(ctx context.Context, poi types.POIDetailedInfo, cityID uuid.NullUUID) (uuid.UUID, error)
-	SearchPOIs(ctx context.Context, filter types.POIFilter) ([]types.POIDetailedInfo, error)
+	SearchPOIs(ctx context.Context, filter types.POIFilter) (*types.POISearchResult, error)
 
 	// Vector similarity search methods
-	FindSimilarPOIs(ctx context.Context, queryEmbedding []float32, limit int) ([]types.POIDetailedInfo, error)
-	FindSimilarPOIsByCity(ctx context.Context, queryEmbedding []float32, cityID uuid.UUID, limit int) ([]types.POIDetailedInfo, error)
-	SearchPOIsHybrid(ctx context.Context, filter types.POIFilter, queryEmbedding []float32, semanticWeight float64) ([]types.POIDetailedInfo, error)
-	UpdatePOIEmbedding(ctx context.Context, poiID uuid.UUID, embedding []float32) error
-	GetPOIsWithoutEmbeddings(ctx context.Context, limit int) ([]types.POIDetailedInfo, error)
+	// ActiveEmbeddingModel returns the model POI embeddings are currently searched with.
+	// Query embeddings must be generated with it.
+	ActiveEmbeddingModel(ctx context.Context) (types.EmbeddingModel, error)
+	FindSimilarPOIs(ctx context.Context, queryEmbedding []float32, model types.EmbeddingModel, limit int) ([]types.POIDetailedInfo, error)
+	FindSimilarPOIsByCity(ctx context.Context, queryEmbedding []float32, model types.EmbeddingModel, cityID uuid.UUID, limit int) ([]types.POIDetailedInfo, error)
+	SearchPOIsHybrid(ctx context.Context, filter types.POIFilter, queryEmbedding []float32, model types.EmbeddingModel, opts types.HybridSearchOptions) (*types.POISearchResult, error)
+	// PreferenceScores scores pois against the taste vector of a search profile,
+	// or of the user's default profile when profileID is uuid.Nil, and against the
+	// user's learned affinities and past feedback. The result is keyed by index
+	// into pois and leaves out POIs there is nothing to say about.
+	PreferenceScores(ctx context.Context, userID, profileID uuid.UUID, pois []types.POIDetailedInfo) (map[int]types.PreferenceScore, error)
+	// AccessibilityNeeds returns the accessibility needs of a search profile,
+	// or of the user's default profile when profileID is uuid.Nil.
+	AccessibilityNeeds(ctx context.Context, userID, profileID uuid.UUID) (*types.AccessibilityNeeds, error)
+	// KnownAccessibility looks up what is stored about the accessibility of
+	// pois, matched like PreferenceScores. The result is keyed by index into
+	// pois and leaves out POIs nothing is known about.
+	KnownAccessibility(ctx context.Context, pois []types.POIDetailedInfo) (map[int]map[string]bool, error)
 
 	// Hotels
 	FindHotelDetails(ctx context.Context, cityID uuid.UUID, lat, lon, tolerance float64) ([]types.HotelDetailedInfo, error)
 	SaveHotelDetails(ctx context.Context, hotel types.HotelDetailedInfo, cityID uuid.UUID) (uuid.UUID, error)
 	GetHotelByID(ctx context.Context, hotelID uuid.UUID) (*types.HotelDetailedInfo, error)
+	// SearchHotels returns a page of the stored hotels of a city matching the
+	// search filters, nearest (or best rated) first, the cursor of the next
+	// page and the total match count.
+	SearchHotels(ctx context.Context, cityID uuid.UUID, params types.HotelSearchParameters) ([]types.HotelDetailedInfo, *types.Cursor, int, error)
+	// HotelNamesByCity returns the names of every stored hotel of a city,
+	// lowercased and trimmed, so new ones can be told apart.
+	HotelNamesByCity(ctx context.Context, cityID uuid.UUID) ([]string, error)
 	// Restaurants
 	FindRestaurantDetails(ctx context.Context, cityID uuid.UUID, lat, lon, tolerance float64, preferences *types.RestaurantUserPreferences) ([]types.RestaurantDetailedInfo, error)
 	SaveRestaurantDetails(ctx context.Context, restaurant types.RestaurantDetailedInfo, cityID uuid.UUID) (uuid.UUID, error)
 	GetRestaurantByID(ctx context.Context, restaurantID uuid.UUID) (*types.RestaurantDetailedInfo, error)
+	// ReportRestaurantDietary records a user's report on a restaurant's diet
+	// or allergen attribute, replacing their earlier report on it.
+	ReportRestaurantDietary(ctx context.Context, restaurantID, userID uuid.UUID, report types.DietaryReport) error
+	// SearchRestaurants returns a page of the stored restaurants of a city
+	// matching the search filters, nearest (or best rated) first, the cursor
+	// of the next page and the total match count.
+	SearchRestaurants(ctx context.Context, cityID uuid.UUID, params types.RestaurantSearchParameters) ([]types.RestaurantDetailedInfo, *types.Cursor, int, error)
+	// RestaurantNamesByCity returns the names of every stored restaurant of a
+	// city, lowercased and trimmed, so new ones can be told apart.
+	RestaurantNamesByCity(ctx context.Context, cityID uuid.UUID) ([]string, error)
 	// GetPOIsByCityIDAndCategory(ctx context.Context, cityID uuid.UUID, category string) ([]types.POIDetailedInfo, error)
 	// GetPOIsByCityIDAndCategories(ctx context.Context, cityID uuid.UUID, categories []string) ([]types.POIDetailedInfo, error)
 	// GetPOIsByCityIDAndName(ctx context.Context, cityID uuid.UUID, name string) ([]types.POIDetailedInfo, error)
@@ -68,7 +103,9 @@ type Repository interface {
 	//AddPersonalizedPOItoFavourites(ctx context.Context, poiID uuid.UUID, userID uuid.UUID) (uuid.UUID, error)
 
 	GetItinerary(ctx context.Context, userID, itineraryID uuid.UUID) (*types.UserSavedItinerary, error)
-	GetItineraries(ctx context.Context, userID uuid.UUID, page, pageSize int) ([]types.UserSavedItinerary, int, error)
+	// GetItineraries returns a page of the user's saved itineraries, newest
+	// first, the cursor of the next page and how many the user has in all.
+	GetItineraries(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]types.UserSavedItinerary, *types.Cursor, int, error)
 	UpdateItinerary(ctx context.Context, userID uuid.UUID, itineraryID uuid.UUID, updates types.UpdateItineraryRequest) (*types.UserSavedItinerary, error)
 	SaveItinerary(ctx context.Context, userID, cityID uuid.UUID) (uuid.UUID, error)
 	SaveItineraryPOIs(ctx context.Context, itineraryID uuid.UUID, pois []types.POIDetailedInfo) error
@@ -108,21 +145,24 @@ func (r *RepositoryImpl) SavePoi(ctx context.Context, poi types.POIDetailedInfo,
 
 	query := `
         INSERT INTO points_of_interest (
-            name, description, location, city_id, poi_type, source, ai_summary
+            name, description, location, city_id, poi_type, source, ai_summary, accessibility
         ) VALUES (
-            $1, $2, ST_SetSRID(ST_MakePoint($3, $4), 4326), $5, $6, $7, $8
+            $1, $2, ST_SetSRID(ST_MakePoint($3, $4), 4326), $5, $6, $7, $8, COALESCE($9::jsonb, '{}')
         ) RETURNING id
     `
 	var id uuid.UUID
 	if err = tx.QueryRow(ctx, query,
 		poi.Name, poi.DescriptionPOI, poi.Longitude, poi.Latitude, cityID,
-		poi.Category, "loci_ai", poi.DescriptionPOI,
+		poi.Category, "loci_ai", poi.DescriptionPOI, types.KnownAccessibility(poi.Accessibility),
 	).Scan(&id); err != nil {
 		if err == pgx.ErrNoRows {
 			return uuid.Nil, nil
 		}
 		return uuid.Nil, fmt.Errorf("failed to insert POI: %w", err)
 	}
+	if err := jobs.EnqueueTx(ctx, tx, types.JobKindPOIEmbedding, id); err != nil {
+		return uuid.Nil, err
+	}
 	if err := tx.Commit(ctx); err != nil {
 		return uuid.Nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -266,6 +306,9 @@ func (r *RepositoryImpl) AddPoiToFavourites(ctx context.Context, userID, poiID u
 		}
 		return uuid.Nil, fmt.Errorf("failed to insert favourite POI: %w", err)
 	}
+	if err := feedback.RecordTx(ctx, tx, types.InteractionEvent{UserID: userID, POIID: &poiID, Kind: types.InteractionFavourite}); err != nil {
+		return uuid.Nil, err
+	}
 	if err := tx.Commit(ctx); err != nil {
 		return uuid.Nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -292,6 +335,9 @@ func (r *RepositoryImpl) RemovePoiFromFavourites(ctx context.Context, poiID uuid
 	if result.RowsAffected() == 0 {
 		return fmt.Errorf("no favourite POI found for deletion")
 	}
+	if err := feedback.RecordTx(ctx, tx, types.InteractionEvent{UserID: userID, POIID: &poiID, Kind: types.InteractionUnfavourite}); err != nil {
+		return err
+	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
@@ -300,59 +346,75 @@ func (r *RepositoryImpl) RemovePoiFromFavourites(ctx context.Context, poiID uuid
 	return nil
 }
 
-func (r *RepositoryImpl) GetFavouritePOIsByUserID(ctx context.Context, userID uuid.UUID) ([]types.POIDetailedInfo, error) {
-	tx, err := r.pgpool.BeginTx(ctx, pgx.TxOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to start transaction: %w", err)
+func (r *RepositoryImpl) GetFavouritePOIsByUserID(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]types.POIDetailedInfo, *types.Cursor, error) {
+	args := []interface{}{userID, page.FetchLimit()}
+	after := ""
+	if page.Cursor != nil {
+		args = append(args, page.Cursor.Time, page.Cursor.ID)
+		after = "AND (uf.added_at, uf.poi_id) < ($3, $4)"
 	}
-	defer tx.Rollback(ctx)
-	query := `
+	query := fmt.Sprintf(`
 		SELECT
 			p.id, p.name, ST_X(p.location) AS longitude, ST_Y(p.location) AS latitude,
-			p.poi_type AS category, p.ai_summary AS description_poi
+			p.poi_type AS category, p.ai_summary AS description_poi, uf.added_at
 		FROM points_of_interest p
 		INNER JOIN user_favorite_pois uf ON p.id = uf.poi_id
-		WHERE uf.user_id = $1
-	`
-	rows, err := tx.Query(ctx, query, userID)
+		WHERE uf.user_id = $1 %s
+		ORDER BY uf.added_at DESC, uf.poi_id DESC
+		LIMIT $2
+	`, after)
+	rows, err := r.pgpool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query favourite POIs: %w", err)
+		return nil, nil, fmt.Errorf("failed to query favourite POIs: %w", err)
 	}
 	defer rows.Close()
-	var pois []types.POIDetailedInfo
+
+	// The page is ordered by when each POI was favourited, which the POI does not carry
+	type favourite struct {
+		poi     types.POIDetailedInfo
+		addedAt time.Time
+	}
+	var favourites []favourite
 	for rows.Next() {
-		var poi types.POIDetailedInfo
-		err := rows.Scan(&poi.ID, &poi.Name, &poi.Longitude, &poi.Latitude, &poi.Category, &poi.DescriptionPOI)
+		var f favourite
+		err := rows.Scan(&f.poi.ID, &f.poi.Name, &f.poi.Longitude, &f.poi.Latitude, &f.poi.Category, &f.poi.DescriptionPOI, &f.addedAt)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan favourite POI row: %w", err)
+			return nil, nil, fmt.Errorf("failed to scan favourite POI row: %w", err)
 		}
-		pois = append(pois, poi)
+		favourites = append(favourites, f)
 	}
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating favourite POI rows: %w", err)
+		return nil, nil, fmt.Errorf("error iterating favourite POI rows: %w", err)
 	}
-	if err := tx.Commit(ctx); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+
+	favourites, next := types.NextPage(favourites, page.Limit, func(f favourite) types.Cursor {
+		return types.Cursor{Time: &f.addedAt, ID: f.poi.ID}
+	})
+	pois := make([]types.POIDetailedInfo, 0, len(favourites))
+	for _, f := range favourites {
+		pois = append(pois, f.poi)
 	}
 	r.logger.Info("Favourite POIs retrieved successfully", slog.String("userID", userID.String()), slog.Int("count", len(pois)))
-	return pois, nil
+	return pois, next, nil
 }
 
-func (r *RepositoryImpl) GetPOIsByCityID(ctx context.Context, cityID uuid.UUID) ([]types.POIDetailedInfo, error) {
-	tx, err := r.pgpool.BeginTx(ctx, pgx.TxOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to start transaction: %w", err)
+func (r *RepositoryImpl) GetPOIsByCityID(ctx context.Context, cityID uuid.UUID, page types.PageRequest) ([]types.POIDetailedInfo, *types.Cursor, error) {
+	args := []interface{}{cityID, page.FetchLimit()}
+	after := ""
+	if page.Cursor != nil {
+		args = append(args, page.Cursor.Key, page.Cursor.ID)
+		after = "AND (name, id) > ($3, $4)"
 	}
-	defer tx.Rollback(ctx)
-
-	query := `
+	query := fmt.Sprintf(`
 		SELECT id, name, description, ST_X(location) AS longitude, ST_Y(location) AS latitude, poi_type
 		FROM points_of_interest
-		WHERE city_id = $1
-	`
-	rows, err := tx.Query(ctx, query, cityID)
+		WHERE city_id = $1 %s
+		ORDER BY name, id
+		LIMIT $2
+	`, after)
+	rows, err := r.pgpool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query POIs by city ID: %w", err)
+		return nil, nil, fmt.Errorf("failed to query POIs by city ID: %w", err)
 	}
 	defer rows.Close()
 
@@ -361,21 +423,20 @@ func (r *RepositoryImpl) GetPOIsByCityID(ctx context.Context, cityID uuid.UUID)
 		var poi types.POIDetailedInfo
 		err := rows.Scan(&poi.ID, &poi.Name, &poi.DescriptionPOI, &poi.Longitude, &poi.Latitude, &poi.Category)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan POI row: %w", err)
+			return nil, nil, fmt.Errorf("failed to scan POI row: %w", err)
 		}
 		pois = append(pois, poi)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating POI rows: %w", err)
-	}
-
-	if err := tx.Commit(ctx); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, nil, fmt.Errorf("error iterating POI rows: %w", err)
 	}
 
+	pois, next := types.NextPage(pois, page.Limit, func(poi types.POIDetailedInfo) types.Cursor {
+		return types.Cursor{Key: poi.Name, ID: poi.ID}
+	})
 	r.logger.Info("POIs retrieved successfully by city ID", slog.String("cityID", cityID.String()), slog.Int("count", len(pois)))
-	return pois, nil
+	return pois, next, nil
 }
 
 func (r *RepositoryImpl) FindPOIDetails(ctx context.Context, cityID uuid.UUID, lat, lon float64, tolerance float64) (*types.POIDetailedInfo, error) {
@@ -560,10 +621,10 @@ func (r *RepositoryImpl) SavePOIDetails(ctx context.Context, poi types.POIDetail
         INSERT INTO points_of_interest (
             id, name, description, location, city_id, address, poi_type,
             website, phone_number, opening_hours, category, price_level,
-            average_rating, source, ai_summary, tags
+            average_rating, source, ai_summary, tags, accessibility
         ) VALUES (
             $1, $2, $3, ST_SetSRID(ST_MakePoint($4, $5), 4326), $6, $7, $8,
-            $9, $10, $11, $12, $13, $14, $15, $16, $17
+            $9, $10, $11, $12, $13, $14, $15, $16, $17, COALESCE($18::jsonb, '{}')
         )
     `
 	_, err = tx.Exec(ctx, poisQuery,
@@ -573,6 +634,7 @@ func (r *RepositoryImpl) SavePOIDetails(ctx context.Context, poi types.POIDetail
 		poi.Website, poi.PhoneNumber, poi.OpeningHours,
 		poi.Category, priceLevel, poi.Rating,
 		"loci_ai", poi.Description, poi.Tags,
+		types.KnownAccessibility(poi.Accessibility),
 	)
 	if err != nil {
 		r.logger.ErrorContext(ctx, "Failed to save POI to points_of_interest",
@@ -730,6 +792,41 @@ func (r *RepositoryImpl) SaveHotelDetails(ctx context.Context, hotel types.Hotel
 	return id, nil
 }
 
+// HotelNamesByCity implements Repository.
+func (r *RepositoryImpl) HotelNamesByCity(ctx context.Context, cityID uuid.UUID) ([]string, error) {
+	return r.detailNamesByCity(ctx, "hotel_details", cityID)
+}
+
+// RestaurantNamesByCity implements Repository.
+func (r *RepositoryImpl) RestaurantNamesByCity(ctx context.Context, cityID uuid.UUID) ([]string, error) {
+	return r.detailNamesByCity(ctx, "restaurant_details", cityID)
+}
+
+// detailNamesByCity lists the distinct normalised names in a details table.
+func (r *RepositoryImpl) detailNamesByCity(ctx context.Context, table string, cityID uuid.UUID) ([]string, error) {
+	ctx, span := otel.Tracer("Repository").Start(ctx, "DetailNamesByCity", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", table),
+		attribute.String("city.id", cityID.String()),
+	))
+	defer span.End()
+
+	query := fmt.Sprintf(`SELECT DISTINCT lower(trim(name)) FROM %s WHERE city_id = $1`, table)
+	rows, err := r.pgpool.Query(ctx, query, cityID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Query failed")
+		return nil, fmt.Errorf("failed to list %s names: %w", table, err)
+	}
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to scan %s names: %w", table, err)
+	}
+	span.SetStatus(codes.Ok, "Names listed")
+	return names, nil
+}
+
 func (r *RepositoryImpl) GetHotelByID(ctx context.Context, hotelID uuid.UUID) (*types.HotelDetailedInfo, error) {
 	ctx, span := otel.Tracer("HotelRepository").Start(ctx, "GetHotelByID", trace.WithAttributes(
 		attribute.String("hotel.id", hotelID.String()),
@@ -770,6 +867,323 @@ func (r *RepositoryImpl) GetHotelByID(ctx context.Context, hotelID uuid.UUID) (*
 	return &hotel, nil
 }
 
+// lodgingCategories are the POI categories searched as hotels when the search
+// does not name categories.
+var lodgingCategories = []string{
+	"hotel", "hostel", "guesthouse", "guest house", "boutique hotel", "resort",
+	"motel", "bed and breakfast", "apartment hotel", "aparthotel", "inn", "lodging", "accommodation",
+}
+
+func (r *RepositoryImpl) SearchHotels(ctx context.Context, cityID uuid.UUID, params types.HotelSearchParameters) ([]types.HotelDetailedInfo, *types.Cursor, int, error) {
+	ctx, span := otel.Tracer("HotelRepository").Start(ctx, "SearchHotels", trace.WithAttributes(
+		attribute.String("city.id", cityID.String()),
+		attribute.Int("limit", params.Page.Limit),
+	))
+	defer span.End()
+
+	categories := lodgingCategories
+	if len(params.Categories) > 0 {
+		categories = lowerAll(params.Categories)
+	}
+	args := []interface{}{cityID, categories}
+	var conditions []string
+	addCondition := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+	}
+
+	distance := "NULL::float8"
+	byDistance := params.Latitude != 0 || params.Longitude != 0
+	if byDistance {
+		args = append(args, params.Longitude, params.Latitude)
+		point := fmt.Sprintf("ST_SetSRID(ST_MakePoint($%d, $%d), 4326)::geography", len(args)-1, len(args))
+		distance = "ST_Distance(location::geography, " + point + ") / 1000"
+		if params.RadiusKm > 0 {
+			addCondition("ST_DWithin(location::geography, "+point+", $%d)", params.RadiusKm*1000)
+		}
+	}
+	if len(params.Categories) > 0 {
+		conditions = append(conditions, "lower(category) = ANY($2)")
+	}
+	if params.MinRating != nil {
+		addCondition("rating >= $%d", *params.MinRating)
+	}
+	if len(params.PriceRanges) > 0 {
+		addCondition("price_range = ANY($%d)", params.PriceRanges)
+	}
+	if len(params.Amenities) > 0 {
+		addCondition("ARRAY(SELECT lower(t) FROM unnest(tags) t) @> $%d", lowerAll(params.Amenities))
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	orderBy, after, args, err := searchKeyset(byDistance, params.Page.Cursor, args)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	args = append(args, params.Page.FetchLimit())
+
+	// Hotels come from the hotel table and from lodging POIs; a hotel in both
+	// is listed once, as its hotel row. The total counts every match, not only
+	// those after the cursor
+	query := fmt.Sprintf(`
+        WITH candidates AS (
+            SELECT id, name, COALESCE(description, '') AS description, latitude, longitude, location,
+                   COALESCE(address, '') AS address, website, phone_number, opening_hours::text AS opening_hours,
+                   price_range, COALESCE(category, '') AS category, COALESCE(tags, '{}') AS tags,
+                   COALESCE(images, '{}') AS images, COALESCE(rating, 0) AS rating, llm_interaction_id,
+                   0 AS source_rank, created_at
+            FROM hotel_details
+            WHERE city_id = $1
+            UNION ALL
+            SELECT id, name, COALESCE(description, ''), ST_Y(location), ST_X(location), location,
+                   COALESCE(address, ''), website, phone_number, opening_hours::text,
+                   repeat('$', price_level), COALESCE(category, ''), COALESCE(tags, '{}'),
+                   '{}'::text[], COALESCE(average_rating, 0)::float8, NULL::uuid,
+                   1, created_at
+            FROM points_of_interest
+            WHERE city_id = $1 AND lower(category) = ANY($2)
+        ), hotels AS (
+            SELECT DISTINCT ON (lower(name)) *
+            FROM candidates
+            ORDER BY lower(name), source_rank, created_at
+        ), matched AS (
+            SELECT id, name, description, latitude, longitude, address, website, phone_number,
+                   opening_hours, price_range, category, tags, images, rating, llm_interaction_id,
+                   %s AS distance_km
+            FROM hotels
+            %s
+        )
+        SELECT id, name, description, latitude, longitude, address, website, phone_number,
+               opening_hours, price_range, category, tags, images, rating, llm_interaction_id,
+               distance_km, (SELECT COUNT(*) FROM matched) AS total_records
+        FROM matched
+        %s
+        ORDER BY %s
+        LIMIT $%d`, distance, where, after, orderBy, len(args))
+
+	rows, err := r.pgpool.Query(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to search hotels")
+		return nil, nil, 0, fmt.Errorf("failed to search hotels: %w", err)
+	}
+	defer rows.Close()
+
+	hotels := []types.HotelDetailedInfo{}
+	total := 0
+	for rows.Next() {
+		var hotel types.HotelDetailedInfo
+		var llmInteractionID uuid.NullUUID
+		if err := rows.Scan(
+			&hotel.ID, &hotel.Name, &hotel.Description, &hotel.Latitude, &hotel.Longitude,
+			&hotel.Address, &hotel.Website, &hotel.PhoneNumber, &hotel.OpeningHours, &hotel.PriceRange,
+			&hotel.Category, &hotel.Tags, &hotel.Images, &hotel.Rating, &llmInteractionID,
+			&hotel.Distance, &total,
+		); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Failed to scan hotel")
+			return nil, nil, 0, fmt.Errorf("failed to scan hotel search row: %w", err)
+		}
+		if llmInteractionID.Valid {
+			hotel.LlmInteractionID = llmInteractionID.UUID
+		}
+		hotels = append(hotels, hotel)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to iterate hotels")
+		return nil, nil, 0, fmt.Errorf("failed to iterate hotel search rows: %w", err)
+	}
+
+	hotels, next := types.NextPage(hotels, params.Page.Limit, func(h types.HotelDetailedInfo) types.Cursor {
+		return searchCursor(h.Distance, h.Rating, h.Name, h.ID)
+	})
+	span.SetAttributes(attribute.Int("results.count", len(hotels)), attribute.Int("results.total", total))
+	span.SetStatus(codes.Ok, "Hotels searched")
+	return hotels, next, total, nil
+}
+
+// searchKeyset returns the order of a hotel or restaurant search and, past
+// the first page, the condition on the rows after cursor. Searches around a
+// point go nearest first, the others best rated and then by name.
+func searchKeyset(byDistance bool, cursor *types.Cursor, args []interface{}) (orderBy, after string, _ []interface{}, err error) {
+	orderBy = "rating DESC, name, id"
+	if byDistance {
+		orderBy = "distance_km, id"
+	}
+	if cursor == nil {
+		return orderBy, "", args, nil
+	}
+	if cursor.Num == nil {
+		return "", "", nil, fmt.Errorf("%w: malformed cursor", types.ErrBadRequest)
+	}
+	if byDistance {
+		args = append(args, *cursor.Num, cursor.ID)
+		after = fmt.Sprintf("WHERE (distance_km, id) > ($%d, $%d)", len(args)-1, len(args))
+		return orderBy, after, args, nil
+	}
+	args = append(args, *cursor.Num, cursor.Key, cursor.ID)
+	after = fmt.Sprintf("WHERE rating < $%[1]d OR (rating = $%[1]d AND (name, id) > ($%[2]d, $%[3]d))",
+		len(args)-2, len(args)-1, len(args))
+	return orderBy, after, args, nil
+}
+
+// searchCursor is the cursor after a hotel or restaurant: its distance when
+// the search has a point, else its rating and name.
+func searchCursor(distance *float64, rating float64, name string, id uuid.UUID) types.Cursor {
+	if distance != nil {
+		return types.Cursor{Num: distance, ID: id}
+	}
+	return types.Cursor{Num: &rating, Key: name, ID: id}
+}
+
+// lowerAll returns values lowercased, for case-insensitive matching.
+func lowerAll(values []string) []string {
+	lowered := make([]string, len(values))
+	for i, v := range values {
+		lowered[i] = strings.ToLower(strings.TrimSpace(v))
+	}
+	return lowered
+}
+
+// restaurantDietaryAttributes selects the current dietary and allergen
+// attributes of the restaurant_details row as a JSON array.
+const restaurantDietaryAttributes = `COALESCE((
+            SELECT json_agg(json_build_object(
+                'kind', e.kind, 'attribute', e.attribute, 'available', e.available,
+                'source', e.source, 'updated_at', e.updated_at
+            ) ORDER BY e.kind, e.attribute)
+            FROM restaurant_dietary_effective e
+            WHERE e.restaurant_id = restaurant_details.id
+        ), '[]'::json)`
+
+func (r *RepositoryImpl) SearchRestaurants(ctx context.Context, cityID uuid.UUID, params types.RestaurantSearchParameters) ([]types.RestaurantDetailedInfo, *types.Cursor, int, error) {
+	ctx, span := otel.Tracer("RestaurantRepository").Start(ctx, "SearchRestaurants", trace.WithAttributes(
+		attribute.String("city.id", cityID.String()),
+		attribute.Int("limit", params.Page.Limit),
+	))
+	defer span.End()
+
+	args := []interface{}{cityID}
+	conditions := []string{"city_id = $1"}
+	addCondition := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+	}
+
+	distance := "NULL::float8"
+	byDistance := params.Latitude != 0 || params.Longitude != 0
+	if byDistance {
+		args = append(args, params.Longitude, params.Latitude)
+		point := fmt.Sprintf("ST_SetSRID(ST_MakePoint($%d, $%d), 4326)::geography", len(args)-1, len(args))
+		distance = "ST_Distance(location::geography, " + point + ") / 1000"
+		if params.RadiusKm > 0 {
+			addCondition("ST_DWithin(location::geography, "+point+", $%d)", params.RadiusKm*1000)
+		}
+	}
+	if params.MinRating != nil {
+		addCondition("rating >= $%d", *params.MinRating)
+	}
+	if len(params.PriceRanges) > 0 {
+		addCondition("price_level = ANY($%d)", params.PriceRanges)
+	}
+	if len(params.Cuisines) > 0 {
+		addCondition("lower(cuisine_type) = ANY($%d)", lowerAll(params.Cuisines))
+	}
+	if len(params.Categories) > 0 {
+		addCondition("lower(category) = ANY($%d)", lowerAll(params.Categories))
+	}
+	if keys := types.AttributeKeys(params.Features); len(keys) > 0 {
+		addCondition("features @> $%d", keys)
+	}
+	if keys := types.AttributeKeys(params.DietaryNeeds); len(keys) > 0 {
+		addCondition("dietary_options @> $%d", keys)
+	}
+	if keys := types.AttributeKeys(params.AllergenFree); len(keys) > 0 {
+		// Only allergens staff or users confirmed; an LLM guess is no
+		// safe answer for someone with an allergy
+		addCondition("allergen_free_confirmed @> $%d", keys)
+	}
+	if keys := types.AttributeKeys(params.ServiceStyles); len(keys) > 0 {
+		addCondition("service_style = ANY($%d)", keys)
+	}
+	if params.OpenNow != nil && *params.OpenNow {
+		// Cities have no time zone; the local time is approximated from the
+		// longitude, an hour per 15 degrees
+		conditions = append(conditions, "opening_hours_open_at(opening_hours, "+
+			"(now() AT TIME ZONE 'UTC') + make_interval(hours => round(longitude / 15)::int))")
+	}
+	orderBy, after, args, err := searchKeyset(byDistance, params.Page.Cursor, args)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	args = append(args, params.Page.FetchLimit())
+
+	// The total counts every match, not only those after the cursor
+	query := fmt.Sprintf(`
+        WITH matched AS (
+            SELECT id, name, COALESCE(description, '') AS description, latitude, longitude, address, website,
+                   phone_number, opening_hours::text AS opening_hours, price_level, COALESCE(category, '') AS category,
+                   COALESCE(tags, '{}') AS tags, COALESCE(images, '{}') AS images, COALESCE(rating, 0) AS rating,
+                   cuisine_type, dietary_options, allergen_free, allergen_free_confirmed, service_style, features,
+                   llm_interaction_id, %s AS dietary, %s AS distance_km
+            FROM restaurant_details
+            WHERE %s
+        )
+        SELECT id, name, description, latitude, longitude, address, website, phone_number,
+               opening_hours, price_level, category, tags, images, rating, cuisine_type, dietary_options,
+               allergen_free, allergen_free_confirmed, service_style, features, llm_interaction_id, dietary,
+               distance_km, (SELECT COUNT(*) FROM matched) AS total_records
+        FROM matched
+        %s
+        ORDER BY %s
+        LIMIT $%d`, restaurantDietaryAttributes, distance, strings.Join(conditions, " AND "), after, orderBy, len(args))
+
+	rows, err := r.pgpool.Query(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to search restaurants")
+		return nil, nil, 0, fmt.Errorf("failed to search restaurants: %w", err)
+	}
+	defer rows.Close()
+
+	restaurants := []types.RestaurantDetailedInfo{}
+	total := 0
+	for rows.Next() {
+		var restaurant types.RestaurantDetailedInfo
+		var llmInteractionID uuid.NullUUID
+		if err := rows.Scan(
+			&restaurant.ID, &restaurant.Name, &restaurant.Description, &restaurant.Latitude, &restaurant.Longitude,
+			&restaurant.Address, &restaurant.Website, &restaurant.PhoneNumber, &restaurant.OpeningHours,
+			&restaurant.PriceLevel, &restaurant.Category, &restaurant.Tags, &restaurant.Images, &restaurant.Rating,
+			&restaurant.CuisineType, &restaurant.DietaryOptions, &restaurant.AllergenFree, &restaurant.AllergenConfirmed, &restaurant.ServiceStyle,
+			&restaurant.Features, &llmInteractionID, &restaurant.Dietary, &restaurant.Distance, &total,
+		); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Failed to scan restaurant")
+			return nil, nil, 0, fmt.Errorf("failed to scan restaurant search row: %w", err)
+		}
+		if llmInteractionID.Valid {
+			restaurant.LlmInteractionID = llmInteractionID.UUID
+		}
+		restaurants = append(restaurants, restaurant)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to iterate restaurants")
+		return nil, nil, 0, fmt.Errorf("failed to iterate restaurant search rows: %w", err)
+	}
+
+	restaurants, next := types.NextPage(restaurants, params.Page.Limit, func(r types.RestaurantDetailedInfo) types.Cursor {
+		return searchCursor(r.Distance, r.Rating, r.Name, r.ID)
+	})
+	span.SetAttributes(attribute.Int("results.count", len(restaurants)), attribute.Int("results.total", total))
+	span.SetStatus(codes.Ok, "Restaurants searched")
+	return restaurants, next, total, nil
+}
+
 func (r *RepositoryImpl) FindRestaurantDetails(ctx context.Context, cityID uuid.UUID, lat, lon, tolerance float64, preferences *types.RestaurantUserPreferences) ([]types.RestaurantDetailedInfo, error) {
 	ctx, span := otel.Tracer("RestaurantRepository").Start(ctx, "FindRestaurantDetails")
 	defer span.End()
@@ -882,15 +1296,38 @@ func (r *RepositoryImpl) SaveRestaurantDetails(ctx context.Context, restaurant t
 		category.Valid = true
 	}
 
+	var serviceStyle sql.NullString
+	if restaurant.ServiceStyle != nil {
+		if keys := types.AttributeKeys([]string{*restaurant.ServiceStyle}); len(keys) > 0 {
+			serviceStyle.String = keys[0]
+			serviceStyle.Valid = true
+		}
+	}
+	dietaryOptions := types.AttributeKeys(restaurant.DietaryOptions)
+	allergenFree := types.AttributeKeys(restaurant.AllergenFree)
+	features := types.AttributeKeys(restaurant.Features)
+
+	// The dietary and allergen attributes are recorded as inferred by the LLM,
+	// so user reports and verification can later override them
 	query := `
-        INSERT INTO restaurant_details (
-            id, city_id, name, description, latitude, longitude, location,
-            address, website, phone_number, opening_hours, price_level, category,
-            cuisine_type, tags, images, rating, llm_interaction_id
-        ) VALUES (
-            $1, $2, $3, $4, $5, $6, ST_SetSRID(ST_MakePoint($7, $8), 4326),
-            $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19 -- Added $19
-        ) RETURNING id
+        WITH saved AS (
+            INSERT INTO restaurant_details (
+                id, city_id, name, description, latitude, longitude, location,
+                address, website, phone_number, opening_hours, price_level, category,
+                cuisine_type, tags, images, rating, llm_interaction_id,
+                dietary_options, allergen_free, service_style, features
+            ) VALUES (
+                $1, $2, $3, $4, $5, $6, ST_SetSRID(ST_MakePoint($7, $8), 4326),
+                $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19,
+                $20, $21, $22, $23
+            ) RETURNING id
+        ), inferred AS (
+            INSERT INTO restaurant_dietary_attributes (restaurant_id, kind, attribute, available, source)
+            SELECT saved.id, 'dietary', a, TRUE, 'llm_inferred' FROM saved, unnest($20::text[]) a
+            UNION ALL
+            SELECT saved.id, 'allergen_free', a, TRUE, 'llm_inferred' FROM saved, unnest($21::text[]) a
+        )
+        SELECT id FROM saved
     `
 	var id uuid.UUID
 	err := r.pgpool.QueryRow(ctx, query,
@@ -913,6 +1350,10 @@ func (r *RepositoryImpl) SaveRestaurantDetails(ctx context.Context, restaurant t
 		restaurant.Images,           // $17: images (TEXT[])
 		restaurant.Rating,           // $18: rating (DOUBLE PRECISION)
 		restaurant.LlmInteractionID, // $19: llm_interaction_id (UUID)
+		dietaryOptions,              // $20: dietary_options (TEXT[])
+		allergenFree,                // $21: allergen_free (TEXT[])
+		serviceStyle,                // $22: service_style (sql.NullString)
+		features,                    // $23: features (TEXT[])
 	).Scan(&id)
 
 	if err != nil {
@@ -935,6 +1376,35 @@ func (r *RepositoryImpl) SaveRestaurantDetails(ctx context.Context, restaurant t
 	return id, nil
 }
 
+func (r *RepositoryImpl) ReportRestaurantDietary(ctx context.Context, restaurantID, userID uuid.UUID, report types.DietaryReport) error {
+	ctx, span := otel.Tracer("RestaurantRepository").Start(ctx, "ReportRestaurantDietary", trace.WithAttributes(
+		attribute.String("restaurant.id", restaurantID.String()),
+		attribute.String("dietary.kind", report.Kind),
+		attribute.String("dietary.attribute", report.Attribute),
+	))
+	defer span.End()
+
+	tag, err := r.pgpool.Exec(ctx, `
+        INSERT INTO restaurant_dietary_attributes (restaurant_id, kind, attribute, available, source, reported_by, note)
+        SELECT $1, $2, $3, $4, 'user_reported', $5, NULLIF($6, '')
+        WHERE EXISTS (SELECT 1 FROM restaurant_details WHERE id = $1)
+        ON CONFLICT (restaurant_id, kind, attribute, reported_by) WHERE source = 'user_reported'
+        DO UPDATE SET available = EXCLUDED.available, note = EXCLUDED.note, created_at = NOW()`,
+		restaurantID, report.Kind, report.Attribute, report.Available, userID, report.Note)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to save dietary report")
+		return fmt.Errorf("failed to save dietary report: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		span.SetStatus(codes.Error, "Restaurant not found")
+		return fmt.Errorf("restaurant %s not found: %w", restaurantID, types.ErrNotFound)
+	}
+
+	span.SetStatus(codes.Ok, "Dietary report saved")
+	return nil
+}
+
 func (r *RepositoryImpl) GetRestaurantByID(ctx context.Context, restaurantID uuid.UUID) (*types.RestaurantDetailedInfo, error) {
 	ctx, span := otel.Tracer("RestaurantRepository").Start(ctx, "GetRestaurantByID")
 	defer span.End()
@@ -942,7 +1412,8 @@ func (r *RepositoryImpl) GetRestaurantByID(ctx context.Context, restaurantID uui
 	query := `
         SELECT 
             id, name, description, latitude, longitude, address, website, phone_number,
-            opening_hours, price_level, category, tags, images, rating, cuisine_type, llm_interaction_id
+            opening_hours, price_level, category, tags, images, rating, cuisine_type, llm_interaction_id,
+            dietary_options, allergen_free, allergen_free_confirmed, service_style, features, ` + restaurantDietaryAttributes + `
         FROM restaurant_details
         WHERE id = $1
     `
@@ -955,7 +1426,11 @@ func (r *RepositoryImpl) GetRestaurantByID(ctx context.Context, restaurantID uui
 		&restaurant.OpeningHours, &restaurant.PriceLevel,
 		&restaurant.Category, &restaurant.Tags,
 		&restaurant.Images, &restaurant.Rating,
-		&restaurant.CuisineType, &llmID)
+		&restaurant.CuisineType, &llmID,
+		&restaurant.DietaryOptions, &restaurant.AllergenFree,
+		&restaurant.AllergenConfirmed,
+		&restaurant.ServiceStyle, &restaurant.Features,
+		&restaurant.Dietary)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			span.SetStatus(codes.Ok, "Restaurant not found")
@@ -971,56 +1446,150 @@ func (r *RepositoryImpl) GetRestaurantByID(ctx context.Context, restaurantID uui
 	return &restaurant, nil
 }
 
-func (r *RepositoryImpl) SearchPOIs(ctx context.Context, filter types.POIFilter) ([]types.POIDetailedInfo, error) {
+// Lexical search. Queries are parsed with each POI's own text search dictionary,
+// so a stemmed match works in the language of the POI's city. Trigram word
+// similarity on the name catches typos the dictionaries cannot.
+const (
+	// lexicalMatchSQL is the WHERE condition for a text query in $%[1]d.
+	lexicalMatchSQL = `(search_vector @@ websearch_to_tsquery(search_config, $%[1]d) OR $%[1]d <%% name)`
+	// lexicalScoreSQL ranks a match between 0 and 1: cover density over the
+	// weighted vector, normalised to rank/(rank+1), blended with name similarity.
+	lexicalScoreSQL = `(0.7 * ts_rank_cd(search_vector, websearch_to_tsquery(search_config, $%[1]d), 32)
+	                    + 0.3 * word_similarity($%[1]d, name))`
+	// snippetSQL highlights the query terms in the description.
+	snippetSQL = `ts_headline(search_config, COALESCE(NULLIF(description, ''), ai_summary, ''),
+	                          websearch_to_tsquery(search_config, $%[1]d),
+	                          'StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "')`
+)
+
+// accessibilityFilter adds the condition that POIs meet needs, leaving out those
+// known to lack a needed feature, or with AccessibleOnly any not known to have
+// them all. It returns the SQL for the status of a POI, empty without needs.
+func accessibilityFilter(needs *types.AccessibilityNeeds, conditions *[]string, args *[]interface{}) string {
+	if !needs.Any() {
+		return `''`
+	}
+	*args = append(*args, needs.Features)
+	status := fmt.Sprintf(`accessibility_status(accessibility, $%d::text[])`, len(*args))
+	if needs.AccessibleOnly {
+		*conditions = append(*conditions, fmt.Sprintf(`%s = '%s'`, status, types.AccessStatusAccessible))
+	} else {
+		*conditions = append(*conditions, fmt.Sprintf(`%s <> '%s'`, status, types.AccessStatusNotAccessible))
+	}
+	return status
+}
+
+// SearchPOIs finds POIs within filter.Radius of filter.Location, optionally in a
+// category. With a text query the results are ranked by lexical score and carry
+// a highlighted snippet; the radius is then optional. With accessibility needs
+// POIs known not to meet them are left out and the rest carry their status.
+// Only the best filter.Limit matches are returned, when set, but the facets of
+// all matches are counted in the same round trip.
+func (r *RepositoryImpl) SearchPOIs(ctx context.Context, filter types.POIFilter) (*types.POISearchResult, error) {
 	ctx, span := otel.Tracer("Repository").Start(ctx, "SearchPOIs", trace.WithAttributes(
 		attribute.Float64("location.latitude", filter.Location.Latitude),
 		attribute.Float64("location.longitude", filter.Location.Longitude),
 		attribute.Float64("radius", filter.Radius),
 		attribute.String("category", filter.Category),
+		attribute.String("query", filter.Query),
+		attribute.Int("limit", filter.Limit),
 	))
 	defer span.End()
 
 	l := r.logger.With(slog.String("method", "SearchPOIs"))
 
-	// Base query using PostGIS for geospatial filtering
-	query := `
-        SELECT 
-            id, 
-            name, 
-            description, 
-            ST_X(location::geometry) AS longitude, 
-            ST_Y(location::geometry) AS latitude, 
-            category,
-            ST_Distance(
-                location,
-                ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography
-            ) AS distance_meters
-        FROM points_of_interest
-        WHERE ST_DWithin(
-            location,
-            ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography,
-            $3
-        )
-    `
-	args := []interface{}{
-		filter.Location.Longitude, // $1
-		filter.Location.Latitude,  // $2
-		filter.Radius * 1000,      // $3 (convert km to meters for ST_DWithin)
+	textSearch := filter.Query != ""
+	spatial := !textSearch || filter.Radius > 0
+
+	var args []interface{}
+	var conditions []string
+	distance := `0`
+	if spatial {
+		args = append(args,
+			filter.Location.Longitude, // $1
+			filter.Location.Latitude,  // $2
+			filter.Radius*1000,        // $3 (convert km to meters for ST_DWithin)
+		)
+		distance = `ST_Distance(location, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography)`
+		conditions = append(conditions, `ST_DWithin(location, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography, $3)`)
+	}
+
+	score, snippet := `0`, `''`
+	if textSearch {
+		args = append(args, filter.Query)
+		n := len(args)
+		conditions = append(conditions, fmt.Sprintf(lexicalMatchSQL, n))
+		score = fmt.Sprintf(lexicalScoreSQL, n)
+		snippet = fmt.Sprintf(snippetSQL, n)
 	}
 
 	// Add category filter if provided
 	if filter.Category != "" {
-		query += ` AND category = $4`
-		args = append(args, filter.Category) // $4
+		args = append(args, filter.Category)
+		conditions = append(conditions, fmt.Sprintf(`category = $%d`, len(args)))
 	}
 
-	// Order by distance
-	query += ` ORDER BY distance_meters ASC`
+	accessStatus := accessibilityFilter(filter.Accessibility, &conditions, &args)
+
+	// Without an origin there are no distance rings
+	origin := ""
+	if spatial {
+		origin = distance
+	}
+	facets := newFacetFilter(filter.Facets, origin, &args)
+	facetQuery := facets.countQuery(strings.Join(conditions, " AND "))
+	conditions = append(conditions, facets.where()...)
+
+	limit := ""
+	if filter.Limit > 0 {
+		limit = fmt.Sprintf("LIMIT %d", filter.Limit)
+	}
+	// Snippets are only highlighted for the rows returned
+	query := fmt.Sprintf(`
+        SELECT
+            id,
+            name,
+            description,
+            longitude,
+            latitude,
+            category,
+            distance_meters,
+            lexical_score,
+            %s AS snippet,
+            accessibility,
+            accessibility_status
+        FROM (
+            SELECT
+                id,
+                name,
+                description,
+                ai_summary,
+                search_config,
+                ST_X(location::geometry) AS longitude,
+                ST_Y(location::geometry) AS latitude,
+                category,
+                %s AS distance_meters,
+                %s AS lexical_score,
+                accessibility,
+                %s AS accessibility_status
+            FROM points_of_interest
+            WHERE %s
+            ORDER BY lexical_score DESC, distance_meters ASC
+            %s
+        ) matches
+        ORDER BY lexical_score DESC, distance_meters ASC`,
+		snippet, distance, score, accessStatus, strings.Join(conditions, " AND "), limit)
 
 	l.DebugContext(ctx, "Executing POI search query", slog.String("query", query), slog.Any("args", args))
 
-	// Execute query
-	rows, err := r.pgpool.Query(ctx, query, args...)
+	// Results and facet counts go out in one batch
+	batch := &pgx.Batch{}
+	batch.Queue(query, args...)
+	batch.Queue(facetQuery, args...)
+	br := r.pgpool.SendBatch(ctx, batch)
+	defer br.Close()
+
+	rows, err := br.Query()
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to query POIs", slog.Any("error", err))
 		span.RecordError(err)
@@ -1033,8 +1602,8 @@ func (r *RepositoryImpl) SearchPOIs(ctx context.Context, filter types.POIFilter)
 	var pois []types.POIDetailedInfo
 	for rows.Next() {
 		var poi types.POIDetailedInfo
-		var distanceMeters float64
-		var description sql.NullString // Handle NULL description
+		var distanceMeters, lexicalScore float64
+		var description, category sql.NullString // Handle NULL columns
 
 		err := rows.Scan(
 			&poi.ID,
@@ -1042,8 +1611,12 @@ func (r *RepositoryImpl) SearchPOIs(ctx context.Context, filter types.POIFilter)
 			&description,
 			&poi.Longitude,
 			&poi.Latitude,
-			&poi.Category,
+			&category,
 			&distanceMeters,
+			&lexicalScore,
+			&poi.Snippet,
+			&poi.Accessibility,
+			&poi.AccessStatus,
 		)
 		if err != nil {
 			l.ErrorContext(ctx, "Failed to scan POI row", slog.Any("error", err))
@@ -1055,6 +1628,7 @@ func (r *RepositoryImpl) SearchPOIs(ctx context.Context, filter types.POIFilter)
 		if description.Valid {
 			poi.DescriptionPOI = description.String
 		}
+		poi.Category = category.String
 
 		// Convert distance from meters to kilometers
 		poi.Distance = distanceMeters / 1000
@@ -1068,6 +1642,15 @@ func (r *RepositoryImpl) SearchPOIs(ctx context.Context, filter types.POIFilter)
 		span.RecordError(err)
 		return nil, fmt.Errorf("error iterating POI rows: %w", err)
 	}
+	rows.Close()
+
+	result, err := r.scanFacets(br, facets, pois)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to count POI facets", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Facet query failed")
+		return nil, err
+	}
 
 	// Log and set span status
 	if len(pois) == 0 {
@@ -1078,7 +1661,20 @@ func (r *RepositoryImpl) SearchPOIs(ctx context.Context, filter types.POIFilter)
 		span.SetStatus(codes.Ok, "POIs found")
 	}
 
-	return pois, nil
+	return result, nil
+}
+
+// scanFacets reads the facet counts queued after a search's results.
+func (r *RepositoryImpl) scanFacets(br pgx.BatchResults, facets facetFilter, pois []types.POIDetailedInfo) (*types.POISearchResult, error) {
+	rows, err := br.Query()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count POI facets: %w", err)
+	}
+	counts, err := facets.scan(rows)
+	if err != nil {
+		return nil, err
+	}
+	return &types.POISearchResult{POIs: pois, Facets: counts}, nil
 }
 
 func (r *RepositoryImpl) GetItinerary(ctx context.Context, userID, itineraryID uuid.UUID) (*types.UserSavedItinerary, error) {
@@ -1126,30 +1722,37 @@ func (r *RepositoryImpl) GetItinerary(ctx context.Context, userID, itineraryID u
 	return &itinerary, nil
 }
 
-func (r *RepositoryImpl) GetItineraries(ctx context.Context, userID uuid.UUID, page, pageSize int) ([]types.UserSavedItinerary, int, error) {
+func (r *RepositoryImpl) GetItineraries(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]types.UserSavedItinerary, *types.Cursor, int, error) {
 	ctx, span := otel.Tracer("LlmInteractionRepo").Start(ctx, "GetItineraries", trace.WithAttributes(
 		semconv.DBSystemPostgreSQL,
 		attribute.String("db.operation", "SELECT"),
 		attribute.String("db.sql.table", "user_saved_itineraries"),
 		attribute.String("user.id", userID.String()),
-		attribute.Int("page", page),
-		attribute.Int("page_size", pageSize),
+		attribute.Bool("page.cursor", page.Cursor != nil),
+		attribute.Int("page.limit", page.Limit),
 	))
 	defer span.End()
 
-	offset := (page - 1) * pageSize
-	query := `
+	args := []interface{}{userID, page.FetchLimit()}
+	after := ""
+	if page.Cursor != nil {
+		args = append(args, page.Cursor.Time, page.Cursor.ID)
+		after = "AND (created_at, id) < ($3, $4)"
+	}
+	query := fmt.Sprintf(`
 		SELECT 
 			id, user_id, source_llm_interaction_id, primary_city_id, title, description,
-			markdown_content, tags, estimated_duration_days, estimated_cost_level, is_public
+			markdown_content, tags, estimated_duration_days, estimated_cost_level, is_public,
+			created_at, updated_at
 		FROM user_saved_itineraries
-		WHERE user_id = $1
-		LIMIT $2 OFFSET $3
-	`
-	rows, err := r.pgpool.Query(ctx, query, userID, pageSize, offset)
+		WHERE user_id = $1 %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`, after)
+	rows, err := r.pgpool.Query(ctx, query, args...)
 	if err != nil {
 		span.RecordError(err)
-		return nil, 0, fmt.Errorf("failed to query user_saved_itineraries: %w", err)
+		return nil, nil, 0, fmt.Errorf("failed to query user_saved_itineraries: %w", err)
 	}
 	defer rows.Close()
 
@@ -1168,33 +1771,39 @@ func (r *RepositoryImpl) GetItineraries(ctx context.Context, userID uuid.UUID, p
 			&itinerary.EstimatedDurationDays,
 			&itinerary.EstimatedCostLevel,
 			&itinerary.IsPublic,
+			&itinerary.CreatedAt,
+			&itinerary.UpdatedAt,
 		); err != nil {
 			if err == pgx.ErrNoRows {
 				continue // No more rows to scan
 			}
-			return nil, 0, fmt.Errorf("failed to scan user_saved_itineraries row: %w", err)
+			return nil, nil, 0, fmt.Errorf("failed to scan user_saved_itineraries row: %w", err)
 		}
 		itineraries = append(itineraries, itinerary)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("error iterating user_saved_itineraries rows: %w", err)
+		return nil, nil, 0, fmt.Errorf("error iterating user_saved_itineraries rows: %w", err)
 	}
 
+	itineraries, next := types.NextPage(itineraries, page.Limit, func(it types.UserSavedItinerary) types.Cursor {
+		return types.Cursor{Time: &it.CreatedAt, ID: it.ID}
+	})
+
 	countQuery := `
 		SELECT COUNT(*) FROM user_saved_itineraries WHERE user_id = $1
 	`
 	var totalRecords int
 	if err := r.pgpool.QueryRow(ctx, countQuery, userID).Scan(&totalRecords); err != nil {
 		span.RecordError(err)
-		return nil, 0, fmt.Errorf("failed to count user_saved_itineraries: %w", err)
+		return nil, nil, 0, fmt.Errorf("failed to count user_saved_itineraries: %w", err)
 	}
 	span.SetAttributes(
 		attribute.Int("total_records", totalRecords),
 		attribute.Int("itineraries.count", len(itineraries)),
 	)
 	span.SetStatus(codes.Ok, "Itineraries retrieved successfully")
-	return itineraries, totalRecords, nil
+	return itineraries, next, totalRecords, nil
 }
 
 func (r *RepositoryImpl) UpdateItinerary(ctx context.Context, userID uuid.UUID, itineraryID uuid.UUID, updates types.UpdateItineraryRequest) (*types.UserSavedItinerary, error) {
@@ -1417,10 +2026,218 @@ func (r *RepositoryImpl) CityExists(ctx context.Context, cityID uuid.UUID) (bool
 	return exists, nil
 }
 
-// FindSimilarPOIs finds POIs similar to the provided query embedding using cosine similarity
-func (r *RepositoryImpl) FindSimilarPOIs(ctx context.Context, queryEmbedding []float32, limit int) ([]types.POIDetailedInfo, error) {
+// ActiveEmbeddingModel returns the model POI embeddings are currently searched with
+func (r *RepositoryImpl) ActiveEmbeddingModel(ctx context.Context) (types.EmbeddingModel, error) {
+	var model types.EmbeddingModel
+	err := r.pgpool.QueryRow(ctx,
+		`SELECT model, model_version FROM embedding_models WHERE target_table = $1`,
+		types.EmbeddingTablePOIs,
+	).Scan(&model.Name, &model.Version)
+	if err != nil {
+		return types.EmbeddingModel{}, fmt.Errorf("failed to get active POI embedding model: %w", err)
+	}
+	return model, nil
+}
+
+// Weights of the feedback part of PreferenceScores. Affinities are squashed with
+// TANH first, so each term stays within its weight. The user's own feedback on a
+// POI counts most: a place they removed should sink even if it fits their taste.
+const (
+	categoryAffinityWeight = 0.3
+	tagAffinityWeight      = 0.15
+	poiFeedbackWeight      = 1.0
+)
+
+// PreferenceScores implements Repository. POIs are matched by ID, or by name
+// and city when they have not been saved yet, as with LLM output. Similarity is
+// only computed for POIs embedded with the same model as the taste vector.
+func (r *RepositoryImpl) PreferenceScores(ctx context.Context, userID, profileID uuid.UUID, pois []types.POIDetailedInfo) (map[int]types.PreferenceScore, error) {
+	ctx, span := otel.Tracer("Repository").Start(ctx, "PreferenceScores", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+		attribute.String("profile.id", profileID.String()),
+		attribute.Int("pois.count", len(pois)),
+	))
+	defer span.End()
+
+	if len(pois) == 0 {
+		return map[int]types.PreferenceScore{}, nil
+	}
+
+	ids := make([]uuid.UUID, len(pois))
+	names := make([]string, len(pois))
+	cities := make([]string, len(pois))
+	categories := make([]string, len(pois))
+	for i, p := range pois {
+		ids[i], names[i], cities[i], categories[i] = p.ID, p.Name, p.City, p.Category
+	}
+
+	query := `
+		WITH taste AS (
+			SELECT preference_embedding, embedding_model, embedding_model_version
+			FROM user_preference_profiles
+			WHERE user_id = $1
+			  AND (id = $2 OR ($2 = '00000000-0000-0000-0000-000000000000'::uuid AND is_default))
+			  AND preference_embedding IS NOT NULL
+		), matched AS (
+			SELECT input.pos, input.name, match.id,
+			       LOWER(COALESCE(NULLIF(input.category, ''), match.category)) AS category,
+			       COALESCE(match.tags, '{}') AS tags,
+			       (SELECT 1 - (match.embedding <=> taste.preference_embedding)
+			        FROM taste
+			        WHERE match.embedding_model = taste.embedding_model
+			          AND match.embedding_model_version = taste.embedding_model_version) AS similarity
+			FROM unnest($3::uuid[], $4::text[], $5::text[], $6::text[]) WITH ORDINALITY AS input(id, name, city, category, pos)
+			LEFT JOIN LATERAL (
+				SELECT p.id, COALESCE(p.category, p.poi_type) AS category, p.tags,
+				       p.embedding, p.embedding_model, p.embedding_model_version
+				FROM points_of_interest p
+				WHERE p.id = input.id OR (
+				      input.id = '00000000-0000-0000-0000-000000000000'::uuid
+				      AND LOWER(p.name) = LOWER(input.name)
+				      AND (input.city = '' OR p.city_id IN (SELECT c.id FROM cities c WHERE LOWER(c.name) = LOWER(input.city))))
+				LIMIT 1
+			) match ON TRUE
+		), scored AS (
+			SELECT m.pos, m.similarity,
+			       $7 * TANH(COALESCE((
+			           SELECT a.score FROM user_affinities a
+			           WHERE a.user_id = $1 AND a.dimension = 'category' AND a.value = m.category), 0))
+			       + $8 * TANH(COALESCE((
+			           SELECT AVG(a.score) FROM user_affinities a
+			           WHERE a.user_id = $1 AND a.dimension = 'tag'
+			             AND a.value IN (SELECT LOWER(t) FROM unnest(m.tags) AS t)), 0))
+			       + $9 * TANH(COALESCE((
+			           SELECT SUM(e.weight) FROM user_interaction_events e
+			           WHERE e.user_id = $1 AND (e.poi_id = m.id OR LOWER(e.poi_name) = LOWER(m.name))), 0)) AS feedback
+			FROM matched m
+		)
+		SELECT pos - 1, similarity, feedback
+		FROM scored
+		WHERE similarity IS NOT NULL OR feedback <> 0`
+
+	rows, err := r.pgpool.Query(ctx, query, userID, profileID, ids, names, cities, categories,
+		categoryAffinityWeight, tagAffinityWeight, poiFeedbackWeight)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Database query failed")
+		return nil, fmt.Errorf("failed to score POIs against preferences: %w", err)
+	}
+	defer rows.Close()
+
+	scores := make(map[int]types.PreferenceScore)
+	for rows.Next() {
+		var pos int
+		var score types.PreferenceScore
+		if err := rows.Scan(&pos, &score.Similarity, &score.Feedback); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan preference score: %w", err)
+		}
+		scores[pos] = score
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("error iterating preference score rows: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("scored.count", len(scores)))
+	span.SetStatus(codes.Ok, "POIs scored")
+	return scores, nil
+}
+
+// AccessibilityNeeds implements Repository.
+func (r *RepositoryImpl) AccessibilityNeeds(ctx context.Context, userID, profileID uuid.UUID) (*types.AccessibilityNeeds, error) {
+	ctx, span := otel.Tracer("Repository").Start(ctx, "AccessibilityNeeds", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+		attribute.String("profile.id", profileID.String()),
+	))
+	defer span.End()
+
+	query := `
+		SELECT accessibility_needs
+		FROM user_preference_profiles
+		WHERE user_id = $1
+		  AND (id = $2 OR ($2 = '00000000-0000-0000-0000-000000000000'::uuid AND is_default))`
+
+	var needs types.AccessibilityNeeds
+	if err := r.pgpool.QueryRow(ctx, query, userID, profileID).Scan(&needs); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("search profile not found: %w", types.ErrNotFound)
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Database query failed")
+		return nil, fmt.Errorf("failed to fetch accessibility needs: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Accessibility needs fetched")
+	return &needs, nil
+}
+
+// KnownAccessibility implements Repository.
+func (r *RepositoryImpl) KnownAccessibility(ctx context.Context, pois []types.POIDetailedInfo) (map[int]map[string]bool, error) {
+	ctx, span := otel.Tracer("Repository").Start(ctx, "KnownAccessibility", trace.WithAttributes(
+		attribute.Int("pois.count", len(pois)),
+	))
+	defer span.End()
+
+	known := make(map[int]map[string]bool)
+	if len(pois) == 0 {
+		return known, nil
+	}
+
+	ids := make([]uuid.UUID, len(pois))
+	names := make([]string, len(pois))
+	cities := make([]string, len(pois))
+	for i, p := range pois {
+		ids[i], names[i], cities[i] = p.ID, p.Name, p.City
+	}
+
+	query := `
+		SELECT input.pos - 1, match.accessibility
+		FROM unnest($1::uuid[], $2::text[], $3::text[]) WITH ORDINALITY AS input(id, name, city, pos)
+		JOIN LATERAL (
+			SELECT p.accessibility
+			FROM points_of_interest p
+			WHERE p.id = input.id OR (
+			      input.id = '00000000-0000-0000-0000-000000000000'::uuid
+			      AND LOWER(p.name) = LOWER(input.name)
+			      AND (input.city = '' OR p.city_id IN (SELECT c.id FROM cities c WHERE LOWER(c.name) = LOWER(input.city))))
+			LIMIT 1
+		) match ON TRUE
+		WHERE match.accessibility <> '{}'::jsonb`
+
+	rows, err := r.pgpool.Query(ctx, query, ids, names, cities)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Database query failed")
+		return nil, fmt.Errorf("failed to look up POI accessibility: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pos int
+		var accessibility map[string]bool
+		if err := rows.Scan(&pos, &accessibility); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan POI accessibility: %w", err)
+		}
+		known[pos] = accessibility
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("error iterating POI accessibility rows: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("known.count", len(known)))
+	span.SetStatus(codes.Ok, "POI accessibility looked up")
+	return known, nil
+}
+
+// FindSimilarPOIs finds POIs similar to the provided query embedding using cosine similarity.
+// Only POIs embedded with model are compared.
+func (r *RepositoryImpl) FindSimilarPOIs(ctx context.Context, queryEmbedding []float32, model types.EmbeddingModel, limit int) ([]types.POIDetailedInfo, error) {
 	ctx, span := otel.Tracer("Repository").Start(ctx, "FindSimilarPOIs", trace.WithAttributes(
 		attribute.Int("embedding.dimension", len(queryEmbedding)),
+		attribute.String("embedding.model", model.String()),
 		attribute.Int("limit", limit),
 	))
 	defer span.End()
@@ -1446,7 +2263,7 @@ func (r *RepositoryImpl) FindSimilarPOIs(ctx context.Context, queryEmbedding []f
             poi_type AS category,
             1 - (embedding <=> $1::vector) AS similarity_score
         FROM points_of_interest
-        WHERE embedding IS NOT NULL
+        WHERE embedding IS NOT NULL AND embedding_model = $3 AND embedding_model_version = $4
         ORDER BY embedding <=> $1::vector
         LIMIT $2
     `
@@ -1456,7 +2273,7 @@ func (r *RepositoryImpl) FindSimilarPOIs(ctx context.Context, queryEmbedding []f
 		slog.Int("embedding_dim", len(queryEmbedding)),
 		slog.Int("limit", limit))
 
-	rows, err := r.pgpool.Query(ctx, query, embeddingStr, limit)
+	rows, err := r.pgpool.Query(ctx, query, embeddingStr, limit, model.Name, model.Version)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to query similar POIs", slog.Any("error", err))
 		span.RecordError(err)
@@ -1509,11 +2326,13 @@ func (r *RepositoryImpl) FindSimilarPOIs(ctx context.Context, queryEmbedding []f
 	return pois, nil
 }
 
-// FindSimilarPOIsByCity finds POIs similar to the provided query embedding within a specific city
-func (r *RepositoryImpl) FindSimilarPOIsByCity(ctx context.Context, queryEmbedding []float32, cityID uuid.UUID, limit int) ([]types.POIDetailedInfo, error) {
+// FindSimilarPOIsByCity finds POIs similar to the provided query embedding within a specific city.
+// Only POIs embedded with model are compared.
+func (r *RepositoryImpl) FindSimilarPOIsByCity(ctx context.Context, queryEmbedding []float32, model types.EmbeddingModel, cityID uuid.UUID, limit int) ([]types.POIDetailedInfo, error) {
 	ctx, span := otel.Tracer("Repository").Start(ctx, "FindSimilarPOIsByCity", trace.WithAttributes(
 		attribute.String("city.id", cityID.String()),
 		attribute.Int("embedding.dimension", len(queryEmbedding)),
+		attribute.String("embedding.model", model.String()),
 		attribute.Int("limit", limit),
 	))
 	defer span.End()
@@ -1540,6 +2359,7 @@ func (r *RepositoryImpl) FindSimilarPOIsByCity(ctx context.Context, queryEmbeddi
             1 - (embedding <=> $1::vector) AS similarity_score
         FROM points_of_interest
         WHERE embedding IS NOT NULL AND city_id = $2
+          AND embedding_model = $4 AND embedding_model_version = $5
         ORDER BY embedding <=> $1::vector
         LIMIT $3
     `
@@ -1549,7 +2369,7 @@ func (r *RepositoryImpl) FindSimilarPOIsByCity(ctx context.Context, queryEmbeddi
 		slog.Int("embedding_dim", len(queryEmbedding)),
 		slog.Int("limit", limit))
 
-	rows, err := r.pgpool.Query(ctx, query, embeddingStr, cityID, limit)
+	rows, err := r.pgpool.Query(ctx, query, embeddingStr, cityID, limit, model.Name, model.Version)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to query similar POIs by city", slog.Any("error", err))
 		span.RecordError(err)
@@ -1607,15 +2427,21 @@ func (r *RepositoryImpl) FindSimilarPOIsByCity(ctx context.Context, queryEmbeddi
 	return pois, nil
 }
 
-// SearchPOIsHybrid combines spatial filtering with semantic similarity search
-func (r *RepositoryImpl) SearchPOIsHybrid(ctx context.Context, filter types.POIFilter, queryEmbedding []float32, semanticWeight float64) ([]types.POIDetailedInfo, error) {
+// SearchPOIsHybrid ranks the POIs within filter.Radius of filter.Location by
+// fusing their lexical, semantic, spatial, rating and popularity signals as
+// opts asks. POIs embedded with a model other than model, and POIs not
+// matching filter.Query, simply lack that signal. The score breakdown is kept
+// on the results only when opts.Explain is set. The facets of every candidate
+// are counted in the same round trip.
+func (r *RepositoryImpl) SearchPOIsHybrid(ctx context.Context, filter types.POIFilter, queryEmbedding []float32, model types.EmbeddingModel, opts types.HybridSearchOptions) (*types.POISearchResult, error) {
 	ctx, span := otel.Tracer("Repository").Start(ctx, "SearchPOIsHybrid", trace.WithAttributes(
 		attribute.Float64("location.latitude", filter.Location.Latitude),
 		attribute.Float64("location.longitude", filter.Location.Longitude),
 		attribute.Float64("radius", filter.Radius),
 		attribute.String("category", filter.Category),
-		attribute.Float64("semantic.weight", semanticWeight),
+		attribute.String("fusion", opts.Fusion),
 		attribute.Int("embedding.dimension", len(queryEmbedding)),
+		attribute.String("embedding.model", model.String()),
 	))
 	defer span.End()
 
@@ -1630,66 +2456,76 @@ func (r *RepositoryImpl) SearchPOIsHybrid(ctx context.Context, filter types.POIF
 		return strs
 	}(), ","))
 
-	// Hybrid search combining spatial distance and semantic similarity
-	query := `
-        SELECT 
-            id, 
-            name, 
-            description, 
-            ST_X(location::geometry) AS longitude, 
-            ST_Y(location::geometry) AS latitude, 
-            poi_type AS category,
-            ST_Distance(
-                location,
-                ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography
-            ) AS distance_meters,
-            CASE 
-                WHEN embedding IS NOT NULL THEN 1 - (embedding <=> $6::vector)
-                ELSE 0 
-            END AS similarity_score,
-            -- Hybrid score: weighted combination of spatial proximity and semantic similarity
-            CASE 
-                WHEN embedding IS NOT NULL THEN
-                    (1 - $5) * (1 / (1 + ST_Distance(location, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography) / 1000)) +
-                    $5 * (1 - (embedding <=> $6::vector))
-                ELSE
-                    (1 - $5) * (1 / (1 + ST_Distance(location, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography) / 1000))
-            END AS hybrid_score
-        FROM points_of_interest
-        WHERE ST_DWithin(
-            location,
-            ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography,
-            $3
-        )
-    `
-
 	args := []interface{}{
 		filter.Location.Longitude, // $1
 		filter.Location.Latitude,  // $2
 		filter.Radius * 1000,      // $3 (convert km to meters)
 	}
+	distance := `ST_Distance(location, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography)`
+	conditions := []string{`ST_DWithin(location, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography, $3)`}
 
 	// Add category filter if provided
-	argIndex := 4
 	if filter.Category != "" {
-		query += fmt.Sprintf(` AND poi_type = $%d`, argIndex)
 		args = append(args, filter.Category)
-		argIndex++
+		conditions = append(conditions, fmt.Sprintf(`poi_type = $%d`, len(args)))
 	}
 
-	// Add semantic weight and embedding (adjust indexes based on whether category was added)
-	args = append(args, semanticWeight) // semantic weight
-	args = append(args, embeddingStr)   // embedding
+	accessStatus := accessibilityFilter(filter.Accessibility, &conditions, &args)
+
+	facets := newFacetFilter(filter.Facets, distance, &args)
+	facetQuery := facets.countQuery(strings.Join(conditions, " AND "))
+	facetArgs := len(args)
+	conditions = append(conditions, facets.where()...)
 
-	// Order by hybrid score (descending)
-	query += ` ORDER BY hybrid_score DESC`
+	// The ranking signals only need the remaining arguments
+	args = append(args, embeddingStr, model.Name, model.Version)
+	embedding := len(args) - 2 // $n of embeddingStr
+
+	// Without the query text there is no lexical signal
+	lexical, snippet := `NULL::float8`, `''`
+	if filter.Query != "" {
+		args = append(args, filter.Query)
+		n := len(args)
+		lexical = fmt.Sprintf(`CASE WHEN %s THEN %s END`, fmt.Sprintf(lexicalMatchSQL, n), fmt.Sprintf(lexicalScoreSQL, n))
+		snippet = fmt.Sprintf(snippetSQL, n)
+	}
+
+	// Every signal is fetched raw; FuseHybrid ranks and combines them
+	query := fmt.Sprintf(`
+        SELECT
+            id,
+            name,
+            description,
+            ST_X(location::geometry) AS longitude,
+            ST_Y(location::geometry) AS latitude,
+            poi_type AS category,
+            %[1]s AS distance_meters,
+            CASE
+                WHEN embedding IS NOT NULL AND embedding_model = $%[3]d AND embedding_model_version = $%[4]d THEN 1 - (embedding <=> $%[2]d::vector)
+            END AS similarity_score,
+            %[5]s AS lexical_score,
+            average_rating::float8,
+            COALESCE(rating_count, 0),
+            %[6]s AS snippet,
+            accessibility,
+            %[7]s AS accessibility_status
+        FROM points_of_interest
+        WHERE %[8]s`,
+		distance, embedding, embedding+1, embedding+2, lexical, snippet, accessStatus, strings.Join(conditions, " AND "))
 
 	l.DebugContext(ctx, "Executing hybrid search query",
 		slog.String("query", query),
 		slog.Any("args_count", len(args)),
-		slog.Float64("semantic_weight", semanticWeight))
+		slog.String("fusion", opts.Fusion))
 
-	rows, err := r.pgpool.Query(ctx, query, args...)
+	// Results and facet counts go out in one batch
+	batch := &pgx.Batch{}
+	batch.Queue(query, args...)
+	batch.Queue(facetQuery, args[:facetArgs]...)
+	br := r.pgpool.SendBatch(ctx, batch)
+	defer br.Close()
+
+	rows, err := br.Query()
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to execute hybrid search", slog.Any("error", err))
 		span.RecordError(err)
@@ -1699,9 +2535,11 @@ func (r *RepositoryImpl) SearchPOIsHybrid(ctx context.Context, filter types.POIF
 	defer rows.Close()
 
 	var pois []types.POIDetailedInfo
+	var signals []HybridSignals
 	for rows.Next() {
 		var poi types.POIDetailedInfo
-		var distanceMeters, similarityScore, hybridScore float64
+		var signal HybridSignals
+		var distanceMeters float64
 		var description sql.NullString
 
 		err := rows.Scan(
@@ -1712,8 +2550,13 @@ func (r *RepositoryImpl) SearchPOIsHybrid(ctx context.Context, filter types.POIF
 			&poi.Latitude,
 			&poi.Category,
 			&distanceMeters,
-			&similarityScore,
-			&hybridScore,
+			&signal.Semantic,
+			&signal.Lexical,
+			&signal.Rating,
+			&signal.RatingCount,
+			&poi.Snippet,
+			&poi.Accessibility,
+			&poi.AccessStatus,
 		)
 		if err != nil {
 			l.ErrorContext(ctx, "Failed to scan hybrid search POI row", slog.Any("error", err))
@@ -1727,8 +2570,10 @@ func (r *RepositoryImpl) SearchPOIsHybrid(ctx context.Context, filter types.POIF
 
 		// Store the actual distance in meters converted to km
 		poi.Distance = distanceMeters / 1000
+		signal.DistanceKm = poi.Distance
 
 		pois = append(pois, poi)
+		signals = append(signals, signal)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -1737,144 +2582,44 @@ func (r *RepositoryImpl) SearchPOIsHybrid(ctx context.Context, filter types.POIF
 		return nil, fmt.Errorf("error iterating hybrid search POI rows: %w", err)
 	}
 
-	l.InfoContext(ctx, "Hybrid search POIs found",
-		slog.Int("count", len(pois)),
-		slog.Float64("semantic_weight", semanticWeight))
-	span.SetAttributes(
-		attribute.Int("results.count", len(pois)),
-		attribute.Float64("semantic.weight", semanticWeight),
-	)
-	span.SetStatus(codes.Ok, "Hybrid search completed")
-
-	return pois, nil
-}
-
-// UpdatePOIEmbedding updates the embedding vector for a specific POI
-func (r *RepositoryImpl) UpdatePOIEmbedding(ctx context.Context, poiID uuid.UUID, embedding []float32) error {
-	ctx, span := otel.Tracer("Repository").Start(ctx, "UpdatePOIEmbedding", trace.WithAttributes(
-		attribute.String("poi.id", poiID.String()),
-		attribute.Int("embedding.dimension", len(embedding)),
-	))
-	defer span.End()
-
-	l := r.logger.With(slog.String("method", "UpdatePOIEmbedding"))
-
-	// Convert []float32 to pgvector format string
-	embeddingStr := fmt.Sprintf("[%v]", strings.Join(func() []string {
-		strs := make([]string, len(embedding))
-		for i, v := range embedding {
-			strs[i] = fmt.Sprintf("%f", v)
-		}
-		return strs
-	}(), ","))
-
-	query := `
-        UPDATE points_of_interest 
-        SET embedding = $1::vector, embedding_generated_at = NOW()
-        WHERE id = $2
-    `
+	rows.Close()
 
-	result, err := r.pgpool.Exec(ctx, query, embeddingStr, poiID)
+	result, err := r.scanFacets(br, facets, FuseHybrid(pois, signals, opts))
 	if err != nil {
-		l.ErrorContext(ctx, "Failed to update POI embedding",
-			slog.Any("error", err),
-			slog.String("poi_id", poiID.String()))
+		l.ErrorContext(ctx, "Failed to count hybrid search facets", slog.Any("error", err))
 		span.RecordError(err)
-		span.SetStatus(codes.Error, "Database update failed")
-		return fmt.Errorf("failed to update POI embedding: %w", err)
-	}
-
-	if result.RowsAffected() == 0 {
-		err := fmt.Errorf("no POI found with ID %s", poiID.String())
-		l.WarnContext(ctx, "No POI found for embedding update", slog.String("poi_id", poiID.String()))
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "POI not found")
-		return err
+		span.SetStatus(codes.Error, "Facet query failed")
+		return nil, err
 	}
 
-	l.InfoContext(ctx, "POI embedding updated successfully",
-		slog.String("poi_id", poiID.String()),
-		slog.Int("embedding_dimension", len(embedding)))
+	l.InfoContext(ctx, "Hybrid search POIs found",
+		slog.Int("count", len(result.POIs)),
+		slog.String("fusion", opts.Fusion))
 	span.SetAttributes(
-		attribute.String("poi.id", poiID.String()),
-		attribute.Int("embedding.dimension", len(embedding)),
+		attribute.Int("results.count", len(result.POIs)),
 	)
-	span.SetStatus(codes.Ok, "POI embedding updated")
+	span.SetStatus(codes.Ok, "Hybrid search completed")
 
-	return nil
+	return result, nil
 }
 
-// GetPOIsWithoutEmbeddings retrieves POIs that don't have embeddings generated yet
-func (r *RepositoryImpl) GetPOIsWithoutEmbeddings(ctx context.Context, limit int) ([]types.POIDetailedInfo, error) {
-	ctx, span := otel.Tracer("Repository").Start(ctx, "GetPOIsWithoutEmbeddings", trace.WithAttributes(
-		attribute.Int("limit", limit),
-	))
-	defer span.End()
-
-	l := r.logger.With(slog.String("method", "GetPOIsWithoutEmbeddings"))
-
-	query := `
-        SELECT 
-            id, 
-            name, 
-            description, 
-            ST_X(location::geometry) AS longitude, 
-            ST_Y(location::geometry) AS latitude, 
-            poi_type AS category,
-            city_id
-        FROM points_of_interest
-        WHERE embedding IS NULL
-        ORDER BY created_at ASC
-        LIMIT $1
-    `
-
-	rows, err := r.pgpool.Query(ctx, query, limit)
-	if err != nil {
-		l.ErrorContext(ctx, "Failed to query POIs without embeddings", slog.Any("error", err))
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Database query failed")
-		return nil, fmt.Errorf("failed to query POIs without embeddings: %w", err)
+// poiPriority maps a POI's popularity to a 1-10 scale for display. Its
+// ratings, sponsorship and the views and saves of its recent analytics
+// popularity score all count towards it.
+func poiPriority(ratingCount sql.NullInt32, isSponsored sql.NullBool, popularity int) int {
+	popularityScore := popularity
+	if ratingCount.Valid {
+		popularityScore += int(ratingCount.Int32)
 	}
-	defer rows.Close()
-
-	var pois []types.POIDetailedInfo
-	for rows.Next() {
-		var poi types.POIDetailedInfo
-		var description sql.NullString
-
-		err := rows.Scan(
-			&poi.ID,
-			&poi.Name,
-			&description,
-			&poi.Longitude,
-			&poi.Latitude,
-			&poi.Category,
-			&poi.CityID,
-		)
-		if err != nil {
-			l.ErrorContext(ctx, "Failed to scan POI without embedding row", slog.Any("error", err))
-			span.RecordError(err)
-			return nil, fmt.Errorf("failed to scan POI without embedding row: %w", err)
-		}
-
-		if description.Valid {
-			poi.DescriptionPOI = description.String
-		}
-
-		pois = append(pois, poi)
+	if isSponsored.Valid && isSponsored.Bool {
+		popularityScore += 50 // Boost sponsored items
 	}
-
-	if err = rows.Err(); err != nil {
-		l.ErrorContext(ctx, "Error iterating POI without embedding rows", slog.Any("error", err))
-		span.RecordError(err)
-		return nil, fmt.Errorf("error iterating POI without embedding rows: %w", err)
+	if popularityScore > 100 {
+		return 10
+	} else if popularityScore > 0 {
+		return (popularityScore / 10) + 1
 	}
-
-	l.InfoContext(ctx, "POIs without embeddings found", slog.Int("count", len(pois)))
-	span.SetAttributes(attribute.Int("results.count", len(pois)))
-	span.SetStatus(codes.Ok, "POIs without embeddings retrieved")
-
-	return pois, nil
+	return 1
 }
 
 // GetPOIsByLocationAndDistance retrieves POIs within a specified radius from a given location using PostGIS
@@ -1908,7 +2653,8 @@ func (r *RepositoryImpl) GetPOIsByLocationAndDistance(ctx context.Context, lat,
 						city_id,
 						COALESCE(tags, '{}') as tags,
 						COALESCE(rating_count, 0) as rating_count,
-						COALESCE(is_sponsored, false) as is_sponsored
+						COALESCE(is_sponsored, false) as is_sponsored,
+						popularity
 					FROM (
 						SELECT 
 							id, 
@@ -1928,7 +2674,8 @@ func (r *RepositoryImpl) GetPOIsByLocationAndDistance(ctx context.Context, lat,
 							city_id,
 							tags,
 							rating_count,
-							is_sponsored
+							is_sponsored,
+							COALESCE((SELECT pp.score FROM poi_popularity pp WHERE pp.poi_id = points_of_interest.id), 0) as popularity
 						FROM points_of_interest
 						WHERE ST_DWithin(
 							location::geography, 
@@ -1968,6 +2715,7 @@ func (r *RepositoryImpl) GetPOIsByLocationAndDistance(ctx context.Context, lat,
 		var tagsRaw []byte // Postgres array of text
 		var ratingCount sql.NullInt32
 		var isSponsored sql.NullBool
+		var popularity int
 
 		err := rows.Scan(
 			&poi.ID,
@@ -1988,6 +2736,7 @@ func (r *RepositoryImpl) GetPOIsByLocationAndDistance(ctx context.Context, lat,
 			&tagsRaw,
 			&ratingCount,
 			&isSponsored,
+			&popularity,
 		)
 		if err != nil {
 			l.ErrorContext(ctx, "Failed to scan POI row", slog.Any("error", err))
@@ -2046,22 +2795,7 @@ func (r *RepositoryImpl) GetPOIsByLocationAndDistance(ctx context.Context, lat,
 			}
 		}
 
-		// Calculate popularity from rating count and sponsored status
-		popularityScore := 0
-		if ratingCount.Valid {
-			popularityScore = int(ratingCount.Int32)
-		}
-		if isSponsored.Valid && isSponsored.Bool {
-			popularityScore += 50 // Boost sponsored items
-		}
-		// Map popularity score to 1-10 scale for display
-		if popularityScore > 100 {
-			poi.Priority = 10
-		} else if popularityScore > 0 {
-			poi.Priority = (popularityScore / 10) + 1
-		} else {
-			poi.Priority = 1
-		}
+		poi.Priority = poiPriority(ratingCount, isSponsored, popularity)
 
 		pois = append(pois, poi)
 	}
@@ -2082,7 +2816,8 @@ func (r *RepositoryImpl) GetPOIsByLocationAndDistance(ctx context.Context, lat,
 }
 
 // GetPOIsByLocationAndDistanceWithFilters retrieves POIs within a specified radius with advanced filtering (category, price, popularity)
-func (r *RepositoryImpl) GetPOIsByLocationAndDistanceWithFilters(ctx context.Context, lat, lon, radiusMeters float64, filters map[string]string) ([]types.POIDetailedInfo, error) {
+// and the facet selection. The facets of all matches, not only the first 50 returned, are counted in the same round trip.
+func (r *RepositoryImpl) GetPOIsByLocationAndDistanceWithFilters(ctx context.Context, lat, lon, radiusMeters float64, filters map[string]string, selection types.FacetSelection) (*types.POISearchResult, error) {
 	ctx, span := otel.Tracer("POIRepository").Start(ctx, "GetPOIsByLocationAndDistanceWithFilters", trace.WithAttributes(
 		attribute.Float64("location.lat", lat),
 		attribute.Float64("location.lon", lon),
@@ -2113,9 +2848,11 @@ func (r *RepositoryImpl) GetPOIsByLocationAndDistanceWithFilters(ctx context.Con
             city_id,
             COALESCE(tags, '{}') as tags,
             COALESCE(rating_count, 0) as rating_count,
-            COALESCE(is_sponsored, false) as is_sponsored
+            COALESCE(is_sponsored, false) as is_sponsored,
+            COALESCE((SELECT pp.score FROM poi_popularity pp WHERE pp.poi_id = points_of_interest.id), 0) as popularity
         FROM points_of_interest
-        WHERE ST_DWithin(
+        WHERE `
+	where := `ST_DWithin(
             location::geography, 
             ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography, 
             $3
@@ -2127,7 +2864,7 @@ func (r *RepositoryImpl) GetPOIsByLocationAndDistanceWithFilters(ctx context.Con
 
 	// Add category filter if provided
 	if categoryFilter, exists := filters["category"]; exists && categoryFilter != "" && categoryFilter != "all" {
-		baseQuery += fmt.Sprintf(` AND LOWER(category) ILIKE $%d`, argCounter)
+		where += fmt.Sprintf(` AND LOWER(category) ILIKE $%d`, argCounter)
 		args = append(args, "%"+strings.ToLower(categoryFilter)+"%")
 		argCounter++
 	}
@@ -2136,22 +2873,22 @@ func (r *RepositoryImpl) GetPOIsByLocationAndDistanceWithFilters(ctx context.Con
 	if priceFilter, exists := filters["price_range"]; exists && priceFilter != "" && priceFilter != "all" {
 		switch strings.ToLower(priceFilter) {
 		case "free":
-			baseQuery += fmt.Sprintf(` AND (price_level IS NULL OR price_level = 0)`)
+			where += fmt.Sprintf(` AND (price_level IS NULL OR price_level = 0)`)
 		case "budget", "€":
-			baseQuery += fmt.Sprintf(` AND price_level = 1`)
+			where += fmt.Sprintf(` AND price_level = 1`)
 		case "moderate", "€€":
-			baseQuery += fmt.Sprintf(` AND price_level = 2`)
+			where += fmt.Sprintf(` AND price_level = 2`)
 		case "expensive", "€€€":
-			baseQuery += fmt.Sprintf(` AND price_level = 3`)
+			where += fmt.Sprintf(` AND price_level = 3`)
 		case "luxury", "€€€€":
-			baseQuery += fmt.Sprintf(` AND price_level = 4`)
+			where += fmt.Sprintf(` AND price_level = 4`)
 		}
 	}
 
 	// Add minimum rating filter if provided
 	if minRatingFilter, exists := filters["min_rating"]; exists && minRatingFilter != "" && minRatingFilter != "all" {
 		if minRating, err := strconv.ParseFloat(minRatingFilter, 64); err == nil {
-			baseQuery += fmt.Sprintf(` AND average_rating >= %.1f`, minRating)
+			where += fmt.Sprintf(` AND average_rating >= %.1f`, minRating)
 		}
 	}
 
@@ -2159,16 +2896,22 @@ func (r *RepositoryImpl) GetPOIsByLocationAndDistanceWithFilters(ctx context.Con
 	if popularityFilter, exists := filters["popularity"]; exists && popularityFilter != "" && popularityFilter != "all" {
 		switch strings.ToLower(popularityFilter) {
 		case "high":
-			baseQuery += fmt.Sprintf(` AND average_rating >= 4.0`)
+			where += fmt.Sprintf(` AND average_rating >= 4.0`)
 		case "medium":
-			baseQuery += fmt.Sprintf(` AND average_rating >= 3.0 AND average_rating < 4.0`)
+			where += fmt.Sprintf(` AND average_rating >= 3.0 AND average_rating < 4.0`)
 		case "any":
-			baseQuery += fmt.Sprintf(` AND average_rating >= 1.0`)
+			where += fmt.Sprintf(` AND average_rating >= 1.0`)
 		}
 	}
 
+	facets := newFacetFilter(selection, `ST_Distance(location::geography, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography)`, &args)
+	facetQuery := facets.countQuery(where)
+	for _, condition := range facets.where() {
+		where += ` AND ` + condition
+	}
+
 	// Order by distance
-	baseQuery += ` ORDER BY distance_km ASC LIMIT 50`
+	baseQuery += where + ` ORDER BY distance_km ASC LIMIT 50`
 
 	l.DebugContext(ctx, "Executing POI advanced filter query",
 		slog.String("query", baseQuery),
@@ -2177,7 +2920,14 @@ func (r *RepositoryImpl) GetPOIsByLocationAndDistanceWithFilters(ctx context.Con
 		slog.Float64("radius_meters", radiusMeters),
 		slog.Any("filters", filters))
 
-	rows, err := r.pgpool.Query(ctx, baseQuery, args...)
+	// Results and facet counts go out in one batch
+	batch := &pgx.Batch{}
+	batch.Queue(baseQuery, args...)
+	batch.Queue(facetQuery, args...)
+	br := r.pgpool.SendBatch(ctx, batch)
+	defer br.Close()
+
+	rows, err := br.Query()
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to query POIs with advanced filters", slog.Any("error", err))
 		span.RecordError(err)
@@ -2197,6 +2947,7 @@ func (r *RepositoryImpl) GetPOIsByLocationAndDistanceWithFilters(ctx context.Con
 		var tagsRaw []byte // Postgres array of text
 		var ratingCount sql.NullInt32
 		var isSponsored sql.NullBool
+		var popularity int
 
 		err := rows.Scan(
 			&poi.ID,
@@ -2217,6 +2968,7 @@ func (r *RepositoryImpl) GetPOIsByLocationAndDistanceWithFilters(ctx context.Con
 			&tagsRaw,
 			&ratingCount,
 			&isSponsored,
+			&popularity,
 		)
 		if err != nil {
 			l.ErrorContext(ctx, "Failed to scan POI row with filters", slog.Any("error", err))
@@ -2275,22 +3027,7 @@ func (r *RepositoryImpl) GetPOIsByLocationAndDistanceWithFilters(ctx context.Con
 			}
 		}
 
-		// Calculate popularity from rating count and sponsored status
-		popularityScore := 0
-		if ratingCount.Valid {
-			popularityScore = int(ratingCount.Int32)
-		}
-		if isSponsored.Valid && isSponsored.Bool {
-			popularityScore += 50 // Boost sponsored items
-		}
-		// Map popularity score to 1-10 scale for display
-		if popularityScore > 100 {
-			poi.Priority = 10
-		} else if popularityScore > 0 {
-			poi.Priority = (popularityScore / 10) + 1
-		} else {
-			poi.Priority = 1
-		}
+		poi.Priority = poiPriority(ratingCount, isSponsored, popularity)
 
 		pois = append(pois, poi)
 	}
@@ -2300,6 +3037,15 @@ func (r *RepositoryImpl) GetPOIsByLocationAndDistanceWithFilters(ctx context.Con
 		span.RecordError(err)
 		return nil, fmt.Errorf("error iterating POI rows with filters: %w", err)
 	}
+	rows.Close()
+
+	result, err := r.scanFacets(br, facets, pois)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to count POI facets with filters", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Facet query failed")
+		return nil, err
+	}
 
 	l.InfoContext(ctx, "POIs by location and distance with filters found",
 		slog.Int("count", len(pois)),
@@ -2308,7 +3054,7 @@ func (r *RepositoryImpl) GetPOIsByLocationAndDistanceWithFilters(ctx context.Con
 	span.SetAttributes(attribute.Int("results.count", len(pois)))
 	span.SetStatus(codes.Ok, "POIs by location and distance with filters retrieved")
 
-	return pois, nil
+	return result, nil
 }
 
 // calculateDistancePostGIS computes the distance between two points using PostGIS (in meters)
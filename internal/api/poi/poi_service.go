@@ -3,13 +3,17 @@ package poi
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"sort"
 	"sync"
 	"time"
 
 	"google.golang.org/genai"
 
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/analytics"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/city"
 	generativeAI "github.com/FACorreiaa/go-poi-au-suggestions/internal/api/generative_ai"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
@@ -23,31 +27,45 @@ import (
 
 var _ Service = (*ServiceImpl)(nil)
 
+// PreferenceRerankWeight is how much a result's similarity to the user's taste
+// vector counts against its original rank when personalizing results.
+const PreferenceRerankWeight = 0.3
+
+// Page sizes of the POI and itinerary collections.
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// Result sizes of a POI search.
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 100
+)
+
 // Service defines the business logic contract for POI operations.
 type Service interface {
 	AddPoiToFavourites(ctx context.Context, userID, poiID uuid.UUID) (uuid.UUID, error)
 	RemovePoiFromFavourites(ctx context.Context, poiID uuid.UUID, userID uuid.UUID) error
-	GetFavouritePOIsByUserID(ctx context.Context, userID uuid.UUID) ([]types.POIDetailedInfo, error)
-	GetPOIsByCityID(ctx context.Context, cityID uuid.UUID) ([]types.POIDetailedInfo, error)
+	GetFavouritePOIsByUserID(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]types.POIDetailedInfo, *types.Cursor, error)
+	GetPOIsByCityID(ctx context.Context, cityID uuid.UUID, page types.PageRequest) ([]types.POIDetailedInfo, *types.Cursor, error)
 
 	// Traditional search
-	SearchPOIs(ctx context.Context, filter types.POIFilter) ([]types.POIDetailedInfo, error)
+	SearchPOIs(ctx context.Context, userID uuid.UUID, filter types.POIFilter) (*types.POISearchResult, error)
 
 	// Semantic search methods
 	SearchPOIsSemantic(ctx context.Context, query string, limit int) ([]types.POIDetailedInfo, error)
 	SearchPOIsSemanticByCity(ctx context.Context, query string, cityID uuid.UUID, limit int) ([]types.POIDetailedInfo, error)
-	SearchPOIsHybrid(ctx context.Context, filter types.POIFilter, query string, semanticWeight float64) ([]types.POIDetailedInfo, error)
-	GenerateEmbeddingForPOI(ctx context.Context, poiID uuid.UUID) error
-	GenerateEmbeddingsForAllPOIs(ctx context.Context, batchSize int) error
+	SearchPOIsHybrid(ctx context.Context, userID uuid.UUID, filter types.POIFilter, query string, opts types.HybridSearchOptions) (*types.POISearchResult, error)
 
 	// Itinerary management
 	GetItinerary(ctx context.Context, userID, itineraryID uuid.UUID) (*types.UserSavedItinerary, error)
-	GetItineraries(ctx context.Context, userID uuid.UUID, page, pageSize int) (*types.PaginatedUserItinerariesResponse, error)
+	GetItineraries(ctx context.Context, userID uuid.UUID, page types.PageRequest) (*types.PaginatedUserItinerariesResponse, error)
 	UpdateItinerary(ctx context.Context, userID, itineraryID uuid.UUID, updates types.UpdateItineraryRequest) (*types.UserSavedItinerary, error)
 
 	// Discover Service
 	GetGeneralPOIByDistance(ctx context.Context, userID uuid.UUID, lat, lon, distance float64) ([]types.POIDetailedInfo, error) //, categoryFilter string
-	GetGeneralPOIByDistanceWithFilters(ctx context.Context, userID uuid.UUID, lat, lon, distance float64, filters map[string]string) ([]types.POIDetailedInfo, error)
+	GetGeneralPOIByDistanceWithFilters(ctx context.Context, userID uuid.UUID, lat, lon, distance float64, filters map[string]string, selection types.FacetSelection) (*types.POISearchResult, error)
 	//filters types.POIFilters
 }
 
@@ -58,11 +76,13 @@ type ServiceImpl struct {
 	aiClient         *generativeAI.AIClient
 	cityRepo         city.Repository
 	cache            *cache.Cache
+	analytics        analytics.Emitter
 }
 
 func NewServiceImpl(poiRepository Repository,
 	embeddingService *generativeAI.EmbeddingService,
 	cityRepo city.Repository,
+	emitter analytics.Emitter,
 	logger *slog.Logger) *ServiceImpl {
 
 	aiClient, err := generativeAI.NewAIClient(context.Background())
@@ -79,6 +99,7 @@ func NewServiceImpl(poiRepository Repository,
 		cityRepo:         cityRepo,
 		cache:            cache.New(5*time.Minute, 10*time.Minute),
 		embeddingService: embeddingService,
+		analytics:        emitter,
 	}
 }
 func (s *ServiceImpl) AddPoiToFavourites(ctx context.Context, userID, poiID uuid.UUID) (uuid.UUID, error) {
@@ -87,6 +108,11 @@ func (s *ServiceImpl) AddPoiToFavourites(ctx context.Context, userID, poiID uuid
 		s.logger.Error("failed to add POI to favourites", "error", err)
 		return uuid.Nil, err
 	}
+	s.analytics.Emit(ctx, types.AnalyticsEvent{
+		Name:   types.AnalyticsPOISaved,
+		UserID: &userID,
+		POIID:  &poiID,
+	})
 	return poi, nil
 }
 func (s *ServiceImpl) RemovePoiFromFavourites(ctx context.Context, poiID uuid.UUID, userID uuid.UUID) error {
@@ -97,30 +123,60 @@ func (s *ServiceImpl) RemovePoiFromFavourites(ctx context.Context, poiID uuid.UU
 
 	return nil
 }
-func (s *ServiceImpl) GetFavouritePOIsByUserID(ctx context.Context, userID uuid.UUID) ([]types.POIDetailedInfo, error) {
-	pois, err := s.poiRepository.GetFavouritePOIsByUserID(ctx, userID)
+func (s *ServiceImpl) GetFavouritePOIsByUserID(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]types.POIDetailedInfo, *types.Cursor, error) {
+	pois, next, err := s.poiRepository.GetFavouritePOIsByUserID(ctx, userID, page.Clamp(defaultPageLimit, maxPageLimit))
 	if err != nil {
 		s.logger.Error("failed to get favourite POIs by user ID", "error", err)
-		return nil, err
+		return nil, nil, err
 	}
-	return pois, nil
+	return pois, next, nil
 }
-func (s *ServiceImpl) GetPOIsByCityID(ctx context.Context, cityID uuid.UUID) ([]types.POIDetailedInfo, error) {
-	pois, err := s.poiRepository.GetPOIsByCityID(ctx, cityID)
+func (s *ServiceImpl) GetPOIsByCityID(ctx context.Context, cityID uuid.UUID, page types.PageRequest) ([]types.POIDetailedInfo, *types.Cursor, error) {
+	pois, next, err := s.poiRepository.GetPOIsByCityID(ctx, cityID, page.Clamp(defaultPageLimit, maxPageLimit))
 	if err != nil {
 		s.logger.Error("failed to get POIs by city ID", "error", err)
-		return nil, err
+		return nil, nil, err
 	}
-	return pois, nil
+	return pois, next, nil
 }
 
-func (s *ServiceImpl) SearchPOIs(ctx context.Context, filter types.POIFilter) ([]types.POIDetailedInfo, error) {
-	pois, err := s.poiRepository.SearchPOIs(ctx, filter)
+// SearchPOIs searches POIs, honouring the accessibility needs of the user's
+// search profile unless the filter names its own.
+func (s *ServiceImpl) SearchPOIs(ctx context.Context, userID uuid.UUID, filter types.POIFilter) (*types.POISearchResult, error) {
+	filter, err := s.withAccessibilityNeeds(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultSearchLimit
+	}
+	filter.Limit = min(filter.Limit, maxSearchLimit)
+	result, err := s.poiRepository.SearchPOIs(ctx, filter)
 	if err != nil {
 		s.logger.Error("failed to search POIs", "error", err)
 		return nil, err
 	}
-	return pois, nil
+	s.emitSearch(ctx, userID, filter, result, "filter")
+	return result, nil
+}
+
+// emitSearch records a search. Searches are by location, so one is counted
+// towards the city of its best result.
+func (s *ServiceImpl) emitSearch(ctx context.Context, userID uuid.UUID, filter types.POIFilter, result *types.POISearchResult, mode string) {
+	event := types.AnalyticsEvent{
+		Name:   types.AnalyticsSearchPerformed,
+		UserID: &userID,
+		Properties: map[string]any{
+			"mode":     mode,
+			"query":    filter.Query,
+			"category": filter.Category,
+			"results":  len(result.POIs),
+		},
+	}
+	if len(result.POIs) > 0 && result.POIs[0].CityID != uuid.Nil {
+		event.CityID = &result.POIs[0].CityID
+	}
+	s.analytics.Emit(ctx, event)
 }
 
 func (l *ServiceImpl) GetItinerary(ctx context.Context, userID, itineraryID uuid.UUID) (*types.UserSavedItinerary, error) {
@@ -141,24 +197,17 @@ func (l *ServiceImpl) GetItinerary(ctx context.Context, userID, itineraryID uuid
 	return itinerary, nil
 }
 
-func (l *ServiceImpl) GetItineraries(ctx context.Context, userID uuid.UUID, page, pageSize int) (*types.PaginatedUserItinerariesResponse, error) {
+func (l *ServiceImpl) GetItineraries(ctx context.Context, userID uuid.UUID, page types.PageRequest) (*types.PaginatedUserItinerariesResponse, error) {
 	_, span := otel.Tracer("LlmInteractionService").Start(ctx, "GetItineraries", trace.WithAttributes(
 		attribute.String("user.id", userID.String()),
-		attribute.Int("page", page),
-		attribute.Int("page_size", pageSize),
+		attribute.Int("page.limit", page.Limit),
 	))
 	defer span.End()
 
 	l.logger.DebugContext(ctx, "Service: Getting itineraries for user", slog.String("userID", userID.String()))
 
-	if page <= 0 {
-		page = 1 // Default to page 1
-	}
-	if pageSize <= 0 {
-		pageSize = 10 // Default page size
-	}
-
-	itineraries, totalRecords, err := l.poiRepository.GetItineraries(ctx, userID, page, pageSize)
+	page = page.Clamp(defaultPageLimit, maxPageLimit)
+	itineraries, next, totalRecords, err := l.poiRepository.GetItineraries(ctx, userID, page)
 	if err != nil {
 		l.logger.ErrorContext(ctx, "Repository failed to get itineraries", slog.Any("error", err))
 		span.RecordError(err)
@@ -171,8 +220,8 @@ func (l *ServiceImpl) GetItineraries(ctx context.Context, userID uuid.UUID, page
 	return &types.PaginatedUserItinerariesResponse{
 		Itineraries:  itineraries,
 		TotalRecords: totalRecords,
-		Page:         page,
-		PageSize:     pageSize,
+		Limit:        page.Limit,
+		NextCursor:   next,
 	}, nil
 }
 
@@ -204,6 +253,20 @@ func (l *ServiceImpl) UpdateItinerary(ctx context.Context, userID, itineraryID u
 	return updatedItinerary, nil
 }
 
+// embedQuery embeds a search query with the model POI embeddings are currently
+// searched with, so the vectors are comparable.
+func (s *ServiceImpl) embedQuery(ctx context.Context, query string) ([]float32, types.EmbeddingModel, error) {
+	model, err := s.poiRepository.ActiveEmbeddingModel(ctx)
+	if err != nil {
+		return nil, types.EmbeddingModel{}, err
+	}
+	queryEmbedding, err := s.embeddingService.WithModel(model).GenerateQueryEmbedding(ctx, query)
+	if err != nil {
+		return nil, types.EmbeddingModel{}, err
+	}
+	return queryEmbedding, model, nil
+}
+
 // SearchPOIsSemantic performs semantic search for POIs using natural language queries
 func (s *ServiceImpl) SearchPOIsSemantic(ctx context.Context, query string, limit int) ([]types.POIDetailedInfo, error) {
 	ctx, span := otel.Tracer("POIService").Start(ctx, "SearchPOIsSemantic", trace.WithAttributes(
@@ -223,7 +286,7 @@ func (s *ServiceImpl) SearchPOIsSemantic(ctx context.Context, query string, limi
 	}
 
 	// Generate embedding for the query
-	queryEmbedding, err := s.embeddingService.GenerateQueryEmbedding(ctx, query)
+	queryEmbedding, model, err := s.embedQuery(ctx, query)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to generate query embedding",
 			slog.Any("error", err),
@@ -234,7 +297,7 @@ func (s *ServiceImpl) SearchPOIsSemantic(ctx context.Context, query string, limi
 	}
 
 	// Search for similar POIs
-	pois, err := s.poiRepository.FindSimilarPOIs(ctx, queryEmbedding, limit)
+	pois, err := s.poiRepository.FindSimilarPOIs(ctx, queryEmbedding, model, limit)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to find similar POIs", slog.Any("error", err))
 		span.RecordError(err)
@@ -274,7 +337,7 @@ func (s *ServiceImpl) SearchPOIsSemanticByCity(ctx context.Context, query string
 	}
 
 	// Generate embedding for the query
-	queryEmbedding, err := s.embeddingService.GenerateQueryEmbedding(ctx, query)
+	queryEmbedding, model, err := s.embedQuery(ctx, query)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to generate query embedding",
 			slog.Any("error", err),
@@ -285,7 +348,7 @@ func (s *ServiceImpl) SearchPOIsSemanticByCity(ctx context.Context, query string
 	}
 
 	// Search for similar POIs in the specified city
-	pois, err := s.poiRepository.FindSimilarPOIsByCity(ctx, queryEmbedding, cityID, limit)
+	pois, err := s.poiRepository.FindSimilarPOIsByCity(ctx, queryEmbedding, model, cityID, limit)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to find similar POIs by city", slog.Any("error", err))
 		span.RecordError(err)
@@ -307,230 +370,284 @@ func (s *ServiceImpl) SearchPOIsSemanticByCity(ctx context.Context, query string
 	return pois, nil
 }
 
-// SearchPOIsHybrid performs hybrid search combining spatial and semantic similarity
-func (s *ServiceImpl) SearchPOIsHybrid(ctx context.Context, filter types.POIFilter, query string, semanticWeight float64) ([]types.POIDetailedInfo, error) {
-	ctx, span := otel.Tracer("POIService").Start(ctx, "SearchPOIsHybrid", trace.WithAttributes(
-		attribute.String("query", query),
-		attribute.Float64("semantic.weight", semanticWeight),
-		attribute.Float64("location.latitude", filter.Location.Latitude),
-		attribute.Float64("location.longitude", filter.Location.Longitude),
-		attribute.Float64("radius", filter.Radius),
-	))
-	defer span.End()
-
-	l := s.logger.With(slog.String("method", "SearchPOIsHybrid"))
-
-	if s.embeddingService == nil {
-		err := fmt.Errorf("embedding service not available")
-		l.ErrorContext(ctx, "Embedding service not initialized", slog.Any("error", err))
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Embedding service not available")
-		return nil, err
+// RerankByPreference reorders pois by blending their original rank with how
+// well they fit the user, as returned by Repository.PreferenceScores. POIs
+// without a similarity are given the mean similarity, so only their feedback
+// moves them. The order is unchanged when nothing was scored.
+func RerankByPreference(pois []types.POIDetailedInfo, scores map[int]types.PreferenceScore, weight float64) []types.POIDetailedInfo {
+	if len(scores) == 0 || len(pois) < 2 {
+		return pois
 	}
 
-	// Validate semantic weight
-	if semanticWeight < 0 || semanticWeight > 1 {
-		err := fmt.Errorf("semantic weight must be between 0 and 1, got: %f", semanticWeight)
-		l.ErrorContext(ctx, "Invalid semantic weight", slog.Float64("semantic_weight", semanticWeight))
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Invalid semantic weight")
-		return nil, err
+	var mean float64
+	var compared int
+	for _, score := range scores {
+		if score.Similarity != nil {
+			mean += *score.Similarity
+			compared++
+		}
 	}
-
-	// Generate embedding for the query
-	queryEmbedding, err := s.embeddingService.GenerateQueryEmbedding(ctx, query)
-	if err != nil {
-		l.ErrorContext(ctx, "Failed to generate query embedding",
-			slog.Any("error", err),
-			slog.String("query", query))
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to generate query embedding")
-		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
+	if compared > 0 {
+		mean /= float64(compared)
 	}
 
-	// Perform hybrid search
-	pois, err := s.poiRepository.SearchPOIsHybrid(ctx, filter, queryEmbedding, semanticWeight)
-	if err != nil {
-		l.ErrorContext(ctx, "Failed to perform hybrid search", slog.Any("error", err))
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to perform hybrid search")
-		return nil, fmt.Errorf("failed to perform hybrid search: %w", err)
+	n := float64(len(pois))
+	blended := make([]float64, len(pois))
+	order := make([]int, len(pois))
+	for i := range pois {
+		fit := mean
+		if score, ok := scores[i]; ok {
+			if score.Similarity != nil {
+				fit = *score.Similarity
+			}
+			fit += score.Feedback
+		}
+		blended[i] = (1-weight)*(1-float64(i)/n) + weight*fit
+		order[i] = i
 	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return blended[order[a]] > blended[order[b]]
+	})
 
-	l.InfoContext(ctx, "Hybrid search completed",
-		slog.String("query", query),
-		slog.Float64("semantic_weight", semanticWeight),
-		slog.Int("results", len(pois)))
-	span.SetAttributes(
-		attribute.String("query", query),
-		attribute.Float64("semantic.weight", semanticWeight),
-		attribute.Int("results.count", len(pois)),
-	)
-	span.SetStatus(codes.Ok, "Hybrid search completed")
+	reranked := make([]types.POIDetailedInfo, len(pois))
+	for i, idx := range order {
+		reranked[i] = pois[idx]
+	}
+	return reranked
+}
 
-	return pois, nil
+// rrfK damps reciprocal rank fusion so the top few ranks of one signal do not
+// drown out the others. 60 is the value from the original RRF paper.
+const rrfK = 60
+
+// HybridSignals are the raw ranking signals of one hybrid search candidate. A
+// nil signal is one the POI lacks.
+type HybridSignals struct {
+	Lexical     *float64
+	Semantic    *float64
+	DistanceKm  float64
+	Rating      *float64 // Average rating, 0-5
+	RatingCount int
 }
 
-// GenerateEmbeddingForPOI generates and stores embedding for a specific POI
-func (s *ServiceImpl) GenerateEmbeddingForPOI(ctx context.Context, poiID uuid.UUID) error {
-	ctx, span := otel.Tracer("POIService").Start(ctx, "GenerateEmbeddingForPOI", trace.WithAttributes(
-		attribute.String("poi.id", poiID.String()),
-	))
-	defer span.End()
+// FuseHybrid orders pois, whose signals are at the same index, by fusing the
+// signals as opts asks. Scores are brought to 0-1 first: proximity decays
+// with distance and popularity is the log of the rating count relative to the
+// most rated candidate. A missing signal adds nothing. Each POI carries its
+// HybridExplain when opts.Explain is set.
+func FuseHybrid(pois []types.POIDetailedInfo, signals []HybridSignals, opts types.HybridSearchOptions) []types.POIDetailedInfo {
+	if len(pois) == 0 {
+		return pois
+	}
 
-	l := s.logger.With(slog.String("method", "GenerateEmbeddingForPOI"))
+	var maxPopularity float64
+	for _, s := range signals {
+		maxPopularity = math.Max(maxPopularity, math.Log1p(float64(s.RatingCount)))
+	}
 
-	if s.embeddingService == nil {
-		err := fmt.Errorf("embedding service not available")
-		l.ErrorContext(ctx, "Embedding service not initialized", slog.Any("error", err))
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Embedding service not available")
-		return err
+	explains := make([]types.HybridExplain, len(pois))
+	for i, s := range signals {
+		e := &explains[i]
+		e.Fusion = opts.Fusion
+		e.Weights = opts.Weights
+		e.Lexical.Score = s.Lexical
+		e.Semantic.Score = s.Semantic
+		spatial := 1 / (1 + s.DistanceKm)
+		e.Spatial.Score = &spatial
+		if s.Rating != nil {
+			rating := *s.Rating / 5
+			e.Rating.Score = &rating
+		}
+		if s.RatingCount > 0 && maxPopularity > 0 {
+			popularity := math.Log1p(float64(s.RatingCount)) / maxPopularity
+			e.Popularity.Score = &popularity
+		}
 	}
 
-	// Get POI details to generate embedding
-	pois, err := s.poiRepository.GetPOIsWithoutEmbeddings(ctx, 1)
-	if err != nil {
-		l.ErrorContext(ctx, "Failed to get POI details", slog.Any("error", err))
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to get POI details")
-		return fmt.Errorf("failed to get POI details: %w", err)
+	components := []struct {
+		weight float64
+		get    func(e *types.HybridExplain) *types.HybridComponent
+	}{
+		{opts.Weights.Lexical, func(e *types.HybridExplain) *types.HybridComponent { return &e.Lexical }},
+		{opts.Weights.Semantic, func(e *types.HybridExplain) *types.HybridComponent { return &e.Semantic }},
+		{opts.Weights.Spatial, func(e *types.HybridExplain) *types.HybridComponent { return &e.Spatial }},
+		{opts.Weights.Rating, func(e *types.HybridExplain) *types.HybridComponent { return &e.Rating }},
+		{opts.Weights.Popularity, func(e *types.HybridExplain) *types.HybridComponent { return &e.Popularity }},
 	}
+	totalWeight := opts.Weights.Lexical + opts.Weights.Semantic + opts.Weights.Spatial +
+		opts.Weights.Rating + opts.Weights.Popularity
+
+	for _, c := range components {
+		// Competition ranking: tied scores share a rank
+		var scored []int
+		for i := range explains {
+			if c.get(&explains[i]).Score != nil {
+				scored = append(scored, i)
+			}
+		}
+		sort.SliceStable(scored, func(a, b int) bool {
+			return *c.get(&explains[scored[a]]).Score > *c.get(&explains[scored[b]]).Score
+		})
+		for pos, i := range scored {
+			component := c.get(&explains[i])
+			rank := pos + 1
+			if pos > 0 {
+				previous := c.get(&explains[scored[pos-1]])
+				if *previous.Score == *component.Score {
+					rank = *previous.Rank
+				}
+			}
+			component.Rank = &rank
 
-	if len(pois) == 0 {
-		l.InfoContext(ctx, "No POI found for embedding generation", slog.String("poi_id", poiID.String()))
-		span.SetStatus(codes.Ok, "No POI found")
-		return fmt.Errorf("POI not found or already has embedding")
+			switch opts.Fusion {
+			case types.FusionLinear:
+				if totalWeight > 0 {
+					component.Contribution = c.weight * *component.Score / totalWeight
+				}
+			default:
+				component.Contribution = c.weight / float64(rrfK+rank)
+			}
+			explains[i].Score += component.Contribution
+		}
 	}
 
-	poi := pois[0]
+	order := make([]int, len(pois))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		if explains[order[a]].Score != explains[order[b]].Score {
+			return explains[order[a]].Score > explains[order[b]].Score
+		}
+		return signals[order[a]].DistanceKm < signals[order[b]].DistanceKm
+	})
 
-	// Generate embedding using POI information
-	embedding, err := s.embeddingService.GeneratePOIEmbedding(ctx, poi.Name, poi.DescriptionPOI, poi.Category)
-	if err != nil {
-		l.ErrorContext(ctx, "Failed to generate POI embedding",
-			slog.Any("error", err),
-			slog.String("poi_id", poiID.String()))
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to generate POI embedding")
-		return fmt.Errorf("failed to generate POI embedding: %w", err)
+	fused := make([]types.POIDetailedInfo, len(pois))
+	for i, idx := range order {
+		fused[i] = pois[idx]
+		if opts.Explain {
+			explain := explains[idx]
+			fused[i].Explain = &explain
+		}
 	}
+	return fused
+}
 
-	// Update POI with generated embedding
-	err = s.poiRepository.UpdatePOIEmbedding(ctx, poiID, embedding)
+// personalize re-ranks pois for the user's search profile, or their default
+// profile when profileID is uuid.Nil, favouring what they keep and sinking what
+// they remove. It is best effort: on failure the POIs
+// are returned in their original order.
+func (s *ServiceImpl) personalize(ctx context.Context, userID, profileID uuid.UUID, pois []types.POIDetailedInfo) []types.POIDetailedInfo {
+	if userID == uuid.Nil || len(pois) < 2 {
+		return pois
+	}
+	scores, err := s.poiRepository.PreferenceScores(ctx, userID, profileID, pois)
 	if err != nil {
-		l.ErrorContext(ctx, "Failed to update POI embedding",
+		s.logger.WarnContext(ctx, "Failed to personalize POI ranking",
 			slog.Any("error", err),
-			slog.String("poi_id", poiID.String()))
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to update POI embedding")
-		return fmt.Errorf("failed to update POI embedding: %w", err)
+			slog.String("user_id", userID.String()))
+		return pois
 	}
+	return RerankByPreference(pois, scores, PreferenceRerankWeight)
+}
 
-	l.InfoContext(ctx, "POI embedding generated and stored successfully",
-		slog.String("poi_id", poiID.String()),
-		slog.String("poi_name", poi.Name))
-	span.SetAttributes(
-		attribute.String("poi.id", poiID.String()),
-		attribute.String("poi.name", poi.Name),
-	)
-	span.SetStatus(codes.Ok, "POI embedding generated")
-
-	return nil
+// withAccessibilityNeeds fills in the accessibility needs of filter.ProfileID,
+// or of the user's default profile, when the filter names none. A user without
+// a default profile is searched for without any.
+func (s *ServiceImpl) withAccessibilityNeeds(ctx context.Context, userID uuid.UUID, filter types.POIFilter) (types.POIFilter, error) {
+	if filter.Accessibility != nil {
+		return filter, filter.Accessibility.Validate()
+	}
+	if userID == uuid.Nil {
+		if filter.ProfileID != uuid.Nil {
+			return filter, fmt.Errorf("%w: profile_id needs a signed-in user", types.ErrBadRequest)
+		}
+		return filter, nil
+	}
+	needs, err := s.poiRepository.AccessibilityNeeds(ctx, userID, filter.ProfileID)
+	if err != nil {
+		if errors.Is(err, types.ErrNotFound) && filter.ProfileID == uuid.Nil {
+			return filter, nil
+		}
+		s.logger.ErrorContext(ctx, "Failed to fetch accessibility needs", slog.Any("error", err))
+		return filter, err
+	}
+	filter.Accessibility = needs
+	return filter, nil
 }
 
-// GenerateEmbeddingsForAllPOIs generates embeddings for all POIs that don't have them
-func (s *ServiceImpl) GenerateEmbeddingsForAllPOIs(ctx context.Context, batchSize int) error {
-	ctx, span := otel.Tracer("POIService").Start(ctx, "GenerateEmbeddingsForAllPOIs", trace.WithAttributes(
-		attribute.Int("batch.size", batchSize),
+// SearchPOIsHybrid performs hybrid search, fusing text relevance, proximity,
+// rating and popularity as opts asks. Results are re-ranked by the user's
+// taste when userID is set.
+func (s *ServiceImpl) SearchPOIsHybrid(ctx context.Context, userID uuid.UUID, filter types.POIFilter, query string, opts types.HybridSearchOptions) (*types.POISearchResult, error) {
+	ctx, span := otel.Tracer("POIService").Start(ctx, "SearchPOIsHybrid", trace.WithAttributes(
+		attribute.String("query", query),
+		attribute.String("fusion", opts.Fusion),
+		attribute.Bool("explain", opts.Explain),
+		attribute.Float64("location.latitude", filter.Location.Latitude),
+		attribute.Float64("location.longitude", filter.Location.Longitude),
+		attribute.Float64("radius", filter.Radius),
 	))
 	defer span.End()
 
-	l := s.logger.With(slog.String("method", "GenerateEmbeddingsForAllPOIs"))
+	l := s.logger.With(slog.String("method", "SearchPOIsHybrid"))
 
 	if s.embeddingService == nil {
 		err := fmt.Errorf("embedding service not available")
 		l.ErrorContext(ctx, "Embedding service not initialized", slog.Any("error", err))
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "Embedding service not available")
-		return err
+		return nil, err
 	}
 
-	if batchSize <= 0 {
-		batchSize = 10 // Default batch size
+	if err := opts.Validate(); err != nil {
+		l.ErrorContext(ctx, "Invalid hybrid search options", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Invalid hybrid search options")
+		return nil, err
 	}
 
-	totalProcessed := 0
-	totalErrors := 0
-
-	for {
-		// Get batch of POIs without embeddings
-		pois, err := s.poiRepository.GetPOIsWithoutEmbeddings(ctx, batchSize)
-		if err != nil {
-			l.ErrorContext(ctx, "Failed to get POIs without embeddings", slog.Any("error", err))
-			span.RecordError(err)
-			span.SetStatus(codes.Error, "Failed to get POIs without embeddings")
-			return fmt.Errorf("failed to get POIs without embeddings: %w", err)
-		}
-
-		if len(pois) == 0 {
-			// No more POIs to process
-			break
-		}
-
-		l.InfoContext(ctx, "Processing batch of POIs", slog.Int("batch_size", len(pois)))
-
-		// Process each POI in the batch
-		for _, poi := range pois {
-			// Generate embedding
-			embedding, err := s.embeddingService.GeneratePOIEmbedding(ctx, poi.Name, poi.DescriptionPOI, poi.Category)
-			if err != nil {
-				l.ErrorContext(ctx, "Failed to generate embedding for POI",
-					slog.Any("error", err),
-					slog.String("poi_id", poi.ID.String()),
-					slog.String("poi_name", poi.Name))
-				totalErrors++
-				continue
-			}
-
-			// Update POI with embedding
-			err = s.poiRepository.UpdatePOIEmbedding(ctx, poi.ID, embedding)
-			if err != nil {
-				l.ErrorContext(ctx, "Failed to update POI embedding",
-					slog.Any("error", err),
-					slog.String("poi_id", poi.ID.String()),
-					slog.String("poi_name", poi.Name))
-				totalErrors++
-				continue
-			}
+	// Generate embedding for the query
+	queryEmbedding, model, err := s.embedQuery(ctx, query)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to generate query embedding",
+			slog.Any("error", err),
+			slog.String("query", query))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to generate query embedding")
+		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
+	}
 
-			totalProcessed++
-			l.DebugContext(ctx, "POI embedding generated successfully",
-				slog.String("poi_id", poi.ID.String()),
-				slog.String("poi_name", poi.Name))
-		}
+	filter, err = s.withAccessibilityNeeds(ctx, userID, filter)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to resolve accessibility needs")
+		return nil, err
+	}
 
-		// Break if we processed fewer POIs than the batch size (end of data)
-		if len(pois) < batchSize {
-			break
-		}
+	// Perform hybrid search; the query text also feeds the lexical score
+	filter.Query = query
+	result, err := s.poiRepository.SearchPOIsHybrid(ctx, filter, queryEmbedding, model, opts)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to perform hybrid search", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to perform hybrid search")
+		return nil, fmt.Errorf("failed to perform hybrid search: %w", err)
 	}
+	result.POIs = s.personalize(ctx, userID, filter.ProfileID, result.POIs)
+	s.emitSearch(ctx, userID, filter, result, "hybrid")
 
-	l.InfoContext(ctx, "Batch embedding generation completed",
-		slog.Int("total_processed", totalProcessed),
-		slog.Int("total_errors", totalErrors))
+	l.InfoContext(ctx, "Hybrid search completed",
+		slog.String("query", query),
+		slog.String("fusion", opts.Fusion),
+		slog.Int("results", len(result.POIs)))
 	span.SetAttributes(
-		attribute.Int("total.processed", totalProcessed),
-		attribute.Int("total.errors", totalErrors),
+		attribute.String("query", query),
+		attribute.Int("results.count", len(result.POIs)),
 	)
+	span.SetStatus(codes.Ok, "Hybrid search completed")
 
-	if totalErrors > 0 {
-		span.SetStatus(codes.Error, fmt.Sprintf("Completed with %d errors", totalErrors))
-		return fmt.Errorf("embedding generation completed with %d errors out of %d total POIs", totalErrors, totalProcessed+totalErrors)
-	}
-
-	span.SetStatus(codes.Ok, "All POI embeddings generated successfully")
-	return nil
+	return result, nil
 }
 
 func (l *ServiceImpl) GetGeneralPOIByDistance(ctx context.Context, userID uuid.UUID, lat, lon, distance float64) ([]types.POIDetailedInfo, error) {
@@ -680,31 +797,35 @@ func (l *ServiceImpl) GetGeneralPOIByDistance(ctx context.Context, userID uuid.U
 	return poisDetailed, nil
 }
 
-func (l *ServiceImpl) GetGeneralPOIByDistanceWithFilters(ctx context.Context, userID uuid.UUID, lat, lon, distance float64, filters map[string]string) ([]types.POIDetailedInfo, error) {
+// GetGeneralPOIByDistanceWithFilters returns the POIs around a location that
+// pass the filters and the facet selection, with their facets. When the
+// database has none it falls back to the LLM; those results have no facets.
+func (l *ServiceImpl) GetGeneralPOIByDistanceWithFilters(ctx context.Context, userID uuid.UUID, lat, lon, distance float64, filters map[string]string, selection types.FacetSelection) (*types.POISearchResult, error) {
 	ctx, span := otel.Tracer("LlmInteractionService").Start(ctx, "GetGeneralPOIByDistanceWithFilters")
 	defer span.End()
 
 	// 1. Build a cache key that includes all parameters.
-	cacheKey := generateFilteredPOICacheKeyWithFilters(lat, lon, distance, filters, userID)
+	cacheKey := generateFilteredPOICacheKeyWithFilters(lat, lon, distance, filters, selection, userID)
 	span.SetAttributes(attribute.String("cache.key", cacheKey))
 
 	// 2. Check cache first.
 	if cached, found := l.cache.Get(cacheKey); found {
-		if pois, ok := cached.([]types.POIDetailedInfo); ok {
-			l.logger.InfoContext(ctx, "Serving POIs from cache", "key", cacheKey, "count", len(pois))
-			return pois, nil
+		if result, ok := cached.(*types.POISearchResult); ok {
+			l.logger.InfoContext(ctx, "Serving POIs from cache", "key", cacheKey, "count", len(result.POIs))
+			return result, nil
 		}
 	}
 
 	// 3. Check the database. This is the primary source of truth.
 	l.logger.InfoContext(ctx, "Cache miss. Querying database with filters.", "lat", lat, "lon", lon, "distance_m", distance)
-	poisFromDB, err := l.poiRepository.GetPOIsByLocationAndDistanceWithFilters(ctx, lat, lon, distance, filters)
+	fromDB, err := l.poiRepository.GetPOIsByLocationAndDistanceWithFilters(ctx, lat, lon, distance, filters, selection)
 	if err != nil {
 		l.logger.WarnContext(ctx, "Database query failed, will fall back to LLM", slog.Any("error", err))
-	} else if len(poisFromDB) > 0 {
-		l.logger.InfoContext(ctx, "Found POIs in database", "count", len(poisFromDB))
-		l.cache.Set(cacheKey, poisFromDB, cache.DefaultExpiration)
-		return poisFromDB, nil
+	} else if len(fromDB.POIs) > 0 {
+		l.logger.InfoContext(ctx, "Found POIs in database", "count", len(fromDB.POIs))
+		fromDB.POIs = l.personalize(ctx, userID, uuid.Nil, fromDB.POIs)
+		l.cache.Set(cacheKey, fromDB, cache.DefaultExpiration)
+		return fromDB, nil
 	}
 
 	// --- LLM FALLBACK ---
@@ -782,8 +903,9 @@ func (l *ServiceImpl) GetGeneralPOIByDistanceWithFilters(ctx context.Context, us
 	}
 
 	// 8. Cache and return the **filtered** list to the user for this specific request.
-	l.cache.Set(cacheKey, filteredLLMPOIs, cache.DefaultExpiration)
-	return filteredLLMPOIs, nil
+	result := &types.POISearchResult{POIs: filteredLLMPOIs}
+	l.cache.Set(cacheKey, result, cache.DefaultExpiration)
+	return result, nil
 }
 
 func (l *ServiceImpl) generatePOIsWithLLM(ctx context.Context, userID uuid.UUID, lat, lon, distance float64) (*types.GenAIResponse, error) {
@@ -3,10 +3,12 @@ package poi
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"testing"
 
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/analytics"
 	generativeAI "github.com/FACorreiaa/go-poi-au-suggestions/internal/api/generative_ai"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types" // Ensure this path is correct
 	"github.com/google/uuid"
@@ -49,12 +51,12 @@ func (m *MockPOIRepository) GetPOIsByLocationAndDistance(ctx context.Context, la
 	return args.Get(0).([]types.POIDetailedInfo), args.Error(1)
 }
 
-func (m *MockPOIRepository) GetPOIsByLocationAndDistanceWithFilters(ctx context.Context, lat, lon, radiusMeters float64, filters map[string]string) ([]types.POIDetailedInfo, error) {
-	args := m.Called(ctx, lat, lon, radiusMeters, filters)
+func (m *MockPOIRepository) GetPOIsByLocationAndDistanceWithFilters(ctx context.Context, lat, lon, radiusMeters float64, filters map[string]string, selection types.FacetSelection) (*types.POISearchResult, error) {
+	args := m.Called(ctx, lat, lon, radiusMeters, filters, selection)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]types.POIDetailedInfo), args.Error(1)
+	return args.Get(0).(*types.POISearchResult), args.Error(1)
 }
 
 func (m *MockPOIRepository) AddPoiToFavourites(ctx context.Context, userID, poiID uuid.UUID) (uuid.UUID, error) {
@@ -67,20 +69,22 @@ func (m *MockPOIRepository) RemovePoiFromFavourites(ctx context.Context, poiID u
 	return args.Error(0)
 }
 
-func (m *MockPOIRepository) GetFavouritePOIsByUserID(ctx context.Context, userID uuid.UUID) ([]types.POIDetailedInfo, error) {
-	args := m.Called(ctx, userID)
+func (m *MockPOIRepository) GetFavouritePOIsByUserID(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]types.POIDetailedInfo, *types.Cursor, error) {
+	args := m.Called(ctx, userID, page)
 	if args.Get(0) == nil {
-		return nil, args.Error(1)
+		return nil, nil, args.Error(2)
 	}
-	return args.Get(0).([]types.POIDetailedInfo), args.Error(1)
+	next, _ := args.Get(1).(*types.Cursor)
+	return args.Get(0).([]types.POIDetailedInfo), next, args.Error(2)
 }
 
-func (m *MockPOIRepository) GetPOIsByCityID(ctx context.Context, cityID uuid.UUID) ([]types.POIDetailedInfo, error) {
-	args := m.Called(ctx, cityID)
+func (m *MockPOIRepository) GetPOIsByCityID(ctx context.Context, cityID uuid.UUID, page types.PageRequest) ([]types.POIDetailedInfo, *types.Cursor, error) {
+	args := m.Called(ctx, cityID, page)
 	if args.Get(0) == nil {
-		return nil, args.Error(1)
+		return nil, nil, args.Error(2)
 	}
-	return args.Get(0).([]types.POIDetailedInfo), args.Error(1)
+	next, _ := args.Get(1).(*types.Cursor)
+	return args.Get(0).([]types.POIDetailedInfo), next, args.Error(2)
 }
 
 func (m *MockPOIRepository) FindPOIDetailedInfos(ctx context.Context, cityID uuid.UUID, lat, lon float64, tolerance float64) (*types.POIDetailedInfo, error) {
@@ -96,38 +100,67 @@ func (m *MockPOIRepository) SavePOIDetailedInfos(ctx context.Context, poi types.
 	return args.Get(0).(uuid.UUID), args.Error(1)
 }
 
-func (m *MockPOIRepository) SearchPOIs(ctx context.Context, filter types.POIFilter) ([]types.POIDetailedInfo, error) {
+func (m *MockPOIRepository) SearchPOIs(ctx context.Context, filter types.POIFilter) (*types.POISearchResult, error) {
 	args := m.Called(ctx, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]types.POIDetailedInfo), args.Error(1)
+	return args.Get(0).(*types.POISearchResult), args.Error(1)
+}
+
+func (m *MockPOIRepository) ActiveEmbeddingModel(ctx context.Context) (types.EmbeddingModel, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(types.EmbeddingModel), args.Error(1)
 }
 
-func (m *MockPOIRepository) FindSimilarPOIs(ctx context.Context, queryEmbedding []float32, limit int) ([]types.POIDetailedInfo, error) {
-	args := m.Called(ctx, queryEmbedding, limit)
+func (m *MockPOIRepository) PreferenceScores(ctx context.Context, userID, profileID uuid.UUID, pois []types.POIDetailedInfo) (map[int]types.PreferenceScore, error) {
+	args := m.Called(ctx, userID, profileID, pois)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]types.POIDetailedInfo), args.Error(1)
+	return args.Get(0).(map[int]types.PreferenceScore), args.Error(1)
+}
+
+func (m *MockPOIRepository) AccessibilityNeeds(ctx context.Context, userID, profileID uuid.UUID) (*types.AccessibilityNeeds, error) {
+	args := m.Called(ctx, userID, profileID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.AccessibilityNeeds), args.Error(1)
+}
+
+func (m *MockPOIRepository) KnownAccessibility(ctx context.Context, pois []types.POIDetailedInfo) (map[int]map[string]bool, error) {
+	args := m.Called(ctx, pois)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int]map[string]bool), args.Error(1)
 }
 
-func (m *MockPOIRepository) FindSimilarPOIsByCity(ctx context.Context, queryEmbedding []float32, cityID uuid.UUID, limit int) ([]types.POIDetailedInfo, error) {
-	args := m.Called(ctx, queryEmbedding, cityID, limit)
+func (m *MockPOIRepository) FindSimilarPOIs(ctx context.Context, queryEmbedding []float32, model types.EmbeddingModel, limit int) ([]types.POIDetailedInfo, error) {
+	args := m.Called(ctx, queryEmbedding, model, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]types.POIDetailedInfo), args.Error(1)
 }
 
-func (m *MockPOIRepository) SearchPOIsHybrid(ctx context.Context, filter types.POIFilter, queryEmbedding []float32, semanticWeight float64) ([]types.POIDetailedInfo, error) {
-	args := m.Called(ctx, filter, queryEmbedding, semanticWeight)
+func (m *MockPOIRepository) FindSimilarPOIsByCity(ctx context.Context, queryEmbedding []float32, model types.EmbeddingModel, cityID uuid.UUID, limit int) ([]types.POIDetailedInfo, error) {
+	args := m.Called(ctx, queryEmbedding, model, cityID, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]types.POIDetailedInfo), args.Error(1)
 }
 
+func (m *MockPOIRepository) SearchPOIsHybrid(ctx context.Context, filter types.POIFilter, queryEmbedding []float32, model types.EmbeddingModel, opts types.HybridSearchOptions) (*types.POISearchResult, error) {
+	args := m.Called(ctx, filter, queryEmbedding, model, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.POISearchResult), args.Error(1)
+}
+
 func (m *MockPOIRepository) UpdatePOIEmbedding(ctx context.Context, poiID uuid.UUID, embedding []float32) error {
 	args := m.Called(ctx, poiID, embedding)
 	return args.Error(0)
@@ -162,6 +195,45 @@ func (m *MockPOIRepository) GetHotelByID(ctx context.Context, hotelID uuid.UUID)
 	return args.Get(0).(*types.HotelDetailedInfo), args.Error(1)
 }
 
+func (m *MockPOIRepository) SearchHotels(ctx context.Context, cityID uuid.UUID, params types.HotelSearchParameters) ([]types.HotelDetailedInfo, *types.Cursor, int, error) {
+	args := m.Called(ctx, cityID, params)
+	next, _ := args.Get(1).(*types.Cursor)
+	if args.Get(0) == nil {
+		return nil, next, args.Int(2), args.Error(3)
+	}
+	return args.Get(0).([]types.HotelDetailedInfo), next, args.Int(2), args.Error(3)
+}
+
+func (m *MockPOIRepository) HotelNamesByCity(ctx context.Context, cityID uuid.UUID) ([]string, error) {
+	args := m.Called(ctx, cityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockPOIRepository) RestaurantNamesByCity(ctx context.Context, cityID uuid.UUID) ([]string, error) {
+	args := m.Called(ctx, cityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockPOIRepository) ReportRestaurantDietary(ctx context.Context, restaurantID, userID uuid.UUID, report types.DietaryReport) error {
+	args := m.Called(ctx, restaurantID, userID, report)
+	return args.Error(0)
+}
+
+func (m *MockPOIRepository) SearchRestaurants(ctx context.Context, cityID uuid.UUID, params types.RestaurantSearchParameters) ([]types.RestaurantDetailedInfo, *types.Cursor, int, error) {
+	args := m.Called(ctx, cityID, params)
+	next, _ := args.Get(1).(*types.Cursor)
+	if args.Get(0) == nil {
+		return nil, next, args.Int(2), args.Error(3)
+	}
+	return args.Get(0).([]types.RestaurantDetailedInfo), next, args.Int(2), args.Error(3)
+}
+
 func (m *MockPOIRepository) FindRestaurantDetails(ctx context.Context, cityID uuid.UUID, lat, lon, tolerance float64, preferences *types.RestaurantUserPreferences) ([]types.RestaurantDetailedInfo, error) {
 	args := m.Called(ctx, cityID, lat, lon, tolerance, preferences)
 	if args.Get(0) == nil {
@@ -191,12 +263,13 @@ func (m *MockPOIRepository) GetItinerary(ctx context.Context, userID, itineraryI
 	return args.Get(0).(*types.UserSavedItinerary), args.Error(1)
 }
 
-func (m *MockPOIRepository) GetItineraries(ctx context.Context, userID uuid.UUID, page, pageSize int) ([]types.UserSavedItinerary, int, error) {
-	args := m.Called(ctx, userID, page, pageSize)
+func (m *MockPOIRepository) GetItineraries(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]types.UserSavedItinerary, *types.Cursor, int, error) {
+	args := m.Called(ctx, userID, page)
 	if args.Get(0) == nil {
-		return nil, 0, args.Error(2)
+		return nil, nil, 0, args.Error(3)
 	}
-	return args.Get(0).([]types.UserSavedItinerary), args.Get(1).(int), args.Error(2)
+	next, _ := args.Get(1).(*types.Cursor)
+	return args.Get(0).([]types.UserSavedItinerary), next, args.Get(2).(int), args.Error(3)
 }
 
 func (m *MockPOIRepository) UpdateItinerary(ctx context.Context, userID uuid.UUID, itineraryID uuid.UUID, updates types.UpdateItineraryRequest) (*types.UserSavedItinerary, error) {
@@ -227,12 +300,34 @@ func (m *MockPOIRepository) CityExists(ctx context.Context, cityID uuid.UUID) (b
 	return args.Get(0).(bool), args.Error(1)
 }
 
+func (m *MockPOIRepository) FindPOIDetails(ctx context.Context, cityID uuid.UUID, lat, lon float64, tolerance float64) (*types.POIDetailedInfo, error) {
+	args := m.Called(ctx, cityID, lat, lon, tolerance)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.POIDetailedInfo), args.Error(1)
+}
+
+func (m *MockPOIRepository) SavePOIDetails(ctx context.Context, poi types.POIDetailedInfo, cityID uuid.NullUUID) (uuid.UUID, error) {
+	args := m.Called(ctx, poi, cityID)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockPOIRepository) CalculateDistancePostGIS(ctx context.Context, userLat, userLon, poiLat, poiLon float64) (float64, error) {
+	args := m.Called(ctx, userLat, userLon, poiLat, poiLon)
+	return args.Get(0).(float64), args.Error(1)
+}
+
 // Helper to setup service with mock repository
 func setupPOIServiceTest() (*ServiceImpl, *MockPOIRepository) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})) // or io.Discard
 	mockRepo := new(MockPOIRepository)
 	embeddingService := &generativeAI.EmbeddingService{} // Mock or nil
-	service := NewServiceImpl(mockRepo, embeddingService, logger)
+	// NewAIClient exits without an API key; the unit tests never call the API
+	if os.Getenv("GOOGLE_GEMINI_API_KEY") == "" {
+		os.Setenv("GOOGLE_GEMINI_API_KEY", "test-key")
+	}
+	service := NewServiceImpl(mockRepo, embeddingService, nil, analytics.NopEmitter{}, logger)
 	return service, mockRepo
 }
 
@@ -244,7 +339,7 @@ func TestPOIServiceImpl_AddPoiToFavourites(t *testing.T) {
 	expectedFavouriteID := uuid.New()
 
 	t.Run("success", func(t *testing.T) {
-		mockRepo.On("AddPoiToFavourites", ctx, userID, poiID).Return(expectedFavouriteID, nil).Once()
+		mockRepo.On("AddPoiToFavourites", mock.Anything, userID, poiID).Return(expectedFavouriteID, nil).Once()
 
 		favID, err := service.AddPoiToFavourites(ctx, userID, poiID)
 		require.NoError(t, err)
@@ -254,7 +349,7 @@ func TestPOIServiceImpl_AddPoiToFavourites(t *testing.T) {
 
 	t.Run("repository error", func(t *testing.T) {
 		expectedErr := errors.New("db error")
-		mockRepo.On("AddPoiToFavourites", ctx, userID, poiID).Return(uuid.Nil, expectedErr).Once()
+		mockRepo.On("AddPoiToFavourites", mock.Anything, userID, poiID).Return(uuid.Nil, expectedErr).Once()
 
 		_, err := service.AddPoiToFavourites(ctx, userID, poiID)
 		require.Error(t, err)
@@ -263,24 +358,119 @@ func TestPOIServiceImpl_AddPoiToFavourites(t *testing.T) {
 	})
 }
 
-func TestServiceImpl_GetGeneralPOIByDistanceResponse_Integration(t *testing.T) {
+func TestPOIServiceImpl_GetItinerary(t *testing.T) {
+	service, mockRepo := setupPOIServiceTest()
 	ctx := context.Background()
-	clearChatTables(t)
+	userID := uuid.New()
+	itineraryID := uuid.New()
+
+	t.Run("Itinerary found", func(t *testing.T) {
+		expectedItinerary := &types.UserSavedItinerary{ID: itineraryID, UserID: userID, Title: "My Test Itinerary"}
+		mockRepo.On("GetItinerary", mock.Anything, userID, itineraryID).Return(expectedItinerary, nil).Once()
+
+		itinerary, err := service.GetItinerary(ctx, userID, itineraryID)
+		require.NoError(t, err)
+		require.NotNil(t, itinerary)
+		assert.Equal(t, "My Test Itinerary", itinerary.Title)
+		mockRepo.AssertExpectations(t)
+	})
 
-	userID := createTestUserForChat(t)
-	createTestCityForChat(t)
+	t.Run("Itinerary not found", func(t *testing.T) {
+		notFoundErr := fmt.Errorf("no itinerary found with ID %s for user %s", itineraryID, userID) // Match repo error
+		mockRepo.On("GetItinerary", mock.Anything, userID, itineraryID).Return(nil, notFoundErr).Once()
 
-	t.Run("Get POIs by distance", func(t *testing.T) {
-		city := "Lisbon"
-		lat := 38.7223
-		lon := -9.1393
-		distance := 5.0 // 5km radius
+		_, err := service.GetItinerary(ctx, userID, itineraryID)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, notFoundErr)
+		mockRepo.AssertExpectations(t)
+	})
 
-		pois, err := testChatService.GetGeneralPOIByDistanceResponse(ctx, userID, city, lat, lon, distance)
+	t.Run("Repository returns other error", func(t *testing.T) {
+		dbErr := errors.New("database connection error")
+		mockRepo.On("GetItinerary", mock.Anything, userID, itineraryID).Return(nil, dbErr).Once()
+
+		_, err := service.GetItinerary(ctx, userID, itineraryID)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, dbErr)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestFuseHybrid(t *testing.T) {
+	ptr := func(v float64) *float64 { return &v }
+	pois := []types.POIDetailedInfo{{Name: "Far match"}, {Name: "Near match"}, {Name: "Near, no text"}}
+	signals := []HybridSignals{
+		{Lexical: ptr(0.9), Semantic: ptr(0.8), DistanceKm: 5},
+		{Lexical: ptr(0.9), Semantic: ptr(0.8), DistanceKm: 0.5},
+		{DistanceKm: 0.1},
+	}
+
+	t.Run("RRF ranks by fused signals", func(t *testing.T) {
+		opts := types.DefaultHybridSearchOptions()
+		fused := FuseHybrid(pois, signals, opts)
+		require.Len(t, fused, 3)
+		assert.Equal(t, "Near match", fused[0].Name)
+		assert.Equal(t, "Far match", fused[1].Name)
+		assert.Equal(t, "Near, no text", fused[2].Name)
+		assert.Nil(t, fused[0].Explain)
+	})
+
+	t.Run("Explain shares ranks on ties and skips missing signals", func(t *testing.T) {
+		opts := types.DefaultHybridSearchOptions()
+		opts.Explain = true
+		fused := FuseHybrid(pois, signals, opts)
+
+		near := fused[0].Explain
+		require.NotNil(t, near)
+		assert.Equal(t, 1, *near.Lexical.Rank)
+		assert.Equal(t, 1, *fused[1].Explain.Lexical.Rank)
+
+		noText := fused[2].Explain
+		assert.Nil(t, noText.Lexical.Score)
+		assert.Nil(t, noText.Lexical.Rank)
+		assert.Zero(t, noText.Lexical.Contribution)
+		assert.Equal(t, 1, *noText.Spatial.Rank)
+	})
+
+	t.Run("Linear weights the scores", func(t *testing.T) {
+		opts := types.HybridSearchOptions{Fusion: types.FusionLinear, Weights: types.HybridWeights{Spatial: 1}, Explain: true}
+		fused := FuseHybrid(pois, signals, opts)
+		assert.Equal(t, "Near, no text", fused[0].Name)
+		assert.InDelta(t, 1/1.1, fused[0].Explain.Score, 1e-9)
+	})
+}
+
+func TestSearchKeyset(t *testing.T) {
+	args := []interface{}{uuid.New()}
+
+	t.Run("first page", func(t *testing.T) {
+		orderBy, after, got, err := searchKeyset(true, nil, args)
 		require.NoError(t, err)
+		assert.Equal(t, "distance_km, id", orderBy)
+		assert.Empty(t, after)
+		assert.Len(t, got, 1)
+	})
+
+	t.Run("by distance", func(t *testing.T) {
+		distance := 1.5
+		cursor := searchCursor(&distance, 4.8, "Hotel Avenida", uuid.New())
+		_, after, got, err := searchKeyset(true, &cursor, args)
+		require.NoError(t, err)
+		assert.Equal(t, "WHERE (distance_km, id) > ($2, $3)", after)
+		assert.Equal(t, []interface{}{args[0], 1.5, cursor.ID}, got)
+	})
+
+	t.Run("by rating", func(t *testing.T) {
+		cursor := searchCursor(nil, 4.8, "Hotel Avenida", uuid.New())
+		orderBy, after, got, err := searchKeyset(false, &cursor, args)
+		require.NoError(t, err)
+		assert.Equal(t, "rating DESC, name, id", orderBy)
+		assert.Equal(t, "WHERE rating < $2 OR (rating = $2 AND (name, id) > ($3, $4))", after)
+		assert.Equal(t, []interface{}{args[0], 4.8, "Hotel Avenida", cursor.ID}, got)
+	})
 
-		// POIs should be empty if no test data exists, but method should succeed
-		assert.NotNil(t, pois)
-		// assert.GreaterOrEqual(t, len(pois), 0) // Could be 0 if no POIs in test DB
+	t.Run("cursor without a sort value", func(t *testing.T) {
+		_, _, _, err := searchKeyset(false, &types.Cursor{ID: uuid.New()}, args)
+		assert.ErrorIs(t, err, types.ErrBadRequest)
 	})
 }
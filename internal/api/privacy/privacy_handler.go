@@ -0,0 +1,286 @@
+package privacy
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/auth"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Handler = (*HandlerImpl)(nil)
+
+type Handler interface {
+	RequestExport(w http.ResponseWriter, r *http.Request)
+	GetExport(w http.ResponseWriter, r *http.Request)
+	DownloadExport(w http.ResponseWriter, r *http.Request)
+
+	RequestDeletion(w http.ResponseWriter, r *http.Request)
+	CancelDeletion(w http.ResponseWriter, r *http.Request)
+	GetDeletionStatus(w http.ResponseWriter, r *http.Request)
+}
+
+type HandlerImpl struct {
+	logger  *slog.Logger
+	service Service
+}
+
+func NewHandler(service Service, logger *slog.Logger) *HandlerImpl {
+	return &HandlerImpl{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// userAndExportID resolves the authenticated user and, when withExport is set,
+// the exportID path parameter. On failure it writes the error response and returns ok=false.
+func (h *HandlerImpl) userAndExportID(w http.ResponseWriter, r *http.Request, span trace.Span, l *slog.Logger, withExport bool) (userID, exportID uuid.UUID, ok bool) {
+	ctx := r.Context()
+	userIDStr, found := auth.GetUserIDFromContext(ctx)
+	if !found || userIDStr == "" {
+		l.ErrorContext(ctx, "User ID not found in context")
+		span.SetStatus(codes.Error, "Unauthorized - User ID missing")
+		api.ErrorResponse(w, r, http.StatusUnauthorized, "Authentication required")
+		return uuid.Nil, uuid.Nil, false
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		l.ErrorContext(ctx, "Invalid user ID format", slog.String("userID_str", userIDStr), slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Invalid User ID format")
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid user ID format")
+		return uuid.Nil, uuid.Nil, false
+	}
+	span.SetAttributes(attribute.String("user.id", userID.String()))
+
+	if !withExport {
+		return userID, uuid.Nil, true
+	}
+	exportIDStr := chi.URLParam(r, "exportID")
+	exportID, err = uuid.Parse(exportIDStr)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Invalid export ID format")
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid exportID format")
+		return uuid.Nil, uuid.Nil, false
+	}
+	span.SetAttributes(attribute.String("export.id", exportID.String()))
+	return userID, exportID, true
+}
+
+// RequestExport godoc
+// @Summary      Request Data Export
+// @Description  Queues a job that collects all of the user's data into a ZIP of JSON files. Poll the returned export for its status.
+// @Tags         Privacy
+// @Produce      json
+// @Success      202 {object} types.DataExport
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      409 {object} types.Response "An export is already in progress"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /user/data-export [post]
+func (h *HandlerImpl) RequestExport(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("PrivacyHandler").Start(r.Context(), "RequestExport")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "RequestExport"))
+
+	userID, _, ok := h.userAndExportID(w, r, span, l, false)
+	if !ok {
+		return
+	}
+
+	export, err := h.service.RequestExport(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to request export")
+		api.WriteServiceError(w, r, err, "Failed to request data export")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Export requested")
+	api.WriteJSONResponse(w, r, http.StatusAccepted, export)
+}
+
+// GetExport godoc
+// @Summary      Get Data Export Status
+// @Description  Returns the status of a data export.
+// @Tags         Privacy
+// @Produce      json
+// @Param        exportID path string true "Export ID"
+// @Success      200 {object} types.DataExport
+// @Failure      400 {object} types.Response "Invalid export ID"
+// @Failure      404 {object} types.Response "Export not found"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /user/data-export/{exportID} [get]
+func (h *HandlerImpl) GetExport(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("PrivacyHandler").Start(r.Context(), "GetExport")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "GetExport"))
+
+	userID, exportID, ok := h.userAndExportID(w, r, span, l, true)
+	if !ok {
+		return
+	}
+
+	export, err := h.service.GetExport(ctx, userID, exportID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to get export")
+		api.WriteServiceError(w, r, err, "Failed to get data export")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Export retrieved")
+	api.WriteJSONResponse(w, r, http.StatusOK, export)
+}
+
+// DownloadExport godoc
+// @Summary      Download Data Export
+// @Description  Downloads a completed data export as a ZIP archive.
+// @Tags         Privacy
+// @Produce      application/zip
+// @Param        exportID path string true "Export ID"
+// @Success      200 {file} file
+// @Failure      400 {object} types.Response "Invalid export ID"
+// @Failure      404 {object} types.Response "Export not found or expired"
+// @Failure      409 {object} types.Response "Export not ready yet"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /user/data-export/{exportID}/download [get]
+func (h *HandlerImpl) DownloadExport(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("PrivacyHandler").Start(r.Context(), "DownloadExport")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "DownloadExport"))
+
+	userID, exportID, ok := h.userAndExportID(w, r, span, l, true)
+	if !ok {
+		return
+	}
+
+	f, export, err := h.service.OpenExport(ctx, userID, exportID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to open export")
+		api.WriteServiceError(w, r, err, "Failed to download data export")
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="loci-data-%s.zip"`, export.RequestedAt.Format("2006-01-02")))
+	w.Header().Set("Cache-Control", "no-store")
+	var modTime time.Time
+	if export.CompletedAt != nil {
+		modTime = *export.CompletedAt
+	}
+	span.SetStatus(codes.Ok, "Export downloaded")
+	http.ServeContent(w, r, "", modTime, f)
+}
+
+// RequestDeletion godoc
+// @Summary      Delete Account
+// @Description  Schedules the account for permanent deletion after a grace period. The account stays usable until then and the deletion can be cancelled. Reviews are kept anonymously.
+// @Tags         Privacy
+// @Produce      json
+// @Success      202 {object} types.AccountDeletionStatus
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      409 {object} types.Response "Deletion already scheduled"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /user/account [delete]
+func (h *HandlerImpl) RequestDeletion(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("PrivacyHandler").Start(r.Context(), "RequestDeletion")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "RequestDeletion"))
+
+	userID, _, ok := h.userAndExportID(w, r, span, l, false)
+	if !ok {
+		return
+	}
+
+	status, err := h.service.RequestDeletion(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to schedule deletion")
+		api.WriteServiceError(w, r, err, "Failed to schedule account deletion")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Deletion scheduled")
+	api.WriteJSONResponse(w, r, http.StatusAccepted, status)
+}
+
+// CancelDeletion godoc
+// @Summary      Cancel Account Deletion
+// @Description  Cancels a scheduled account deletion during the grace period.
+// @Tags         Privacy
+// @Produce      json
+// @Success      200 {object} types.Response
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      404 {object} types.Response "No deletion scheduled"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /user/account/deletion [delete]
+func (h *HandlerImpl) CancelDeletion(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("PrivacyHandler").Start(r.Context(), "CancelDeletion")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "CancelDeletion"))
+
+	userID, _, ok := h.userAndExportID(w, r, span, l, false)
+	if !ok {
+		return
+	}
+
+	if err := h.service.CancelDeletion(ctx, userID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to cancel deletion")
+		api.WriteServiceError(w, r, err, "Failed to cancel account deletion")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Deletion cancelled")
+	api.WriteJSONResponse(w, r, http.StatusOK, types.Response{Success: true, Message: "Account deletion cancelled"})
+}
+
+// GetDeletionStatus godoc
+// @Summary      Get Account Deletion Status
+// @Description  Reports whether the account is scheduled for deletion and when.
+// @Tags         Privacy
+// @Produce      json
+// @Success      200 {object} types.AccountDeletionStatus
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /user/account/deletion [get]
+func (h *HandlerImpl) GetDeletionStatus(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("PrivacyHandler").Start(r.Context(), "GetDeletionStatus")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "GetDeletionStatus"))
+
+	userID, _, ok := h.userAndExportID(w, r, span, l, false)
+	if !ok {
+		return
+	}
+
+	status, err := h.service.GetDeletionStatus(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to get deletion status")
+		api.WriteServiceError(w, r, err, "Failed to get account deletion status")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Deletion status retrieved")
+	api.WriteJSONResponse(w, r, http.StatusOK, status)
+}
@@ -0,0 +1,458 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Repository = (*RepositoryImpl)(nil)
+
+// Repository defines persistence for data exports and account deletion.
+type Repository interface {
+	// CreateExport queues a new export. Returns ErrConflict if one is already pending or processing.
+	CreateExport(ctx context.Context, userID uuid.UUID) (*types.DataExport, error)
+	// GetExport returns one of the user's exports.
+	GetExport(ctx context.Context, userID, exportID uuid.UUID) (*types.DataExport, error)
+	// ClaimPendingExport marks the oldest pending export as processing and returns it,
+	// or nil if there is nothing to do. Exports left processing for longer than
+	// lease, by a worker that crashed or was stopped, are claimed again. Safe to
+	// call from several workers.
+	ClaimPendingExport(ctx context.Context, lease time.Duration) (*types.DataExport, error)
+	// CompleteExport records the generated archive.
+	CompleteExport(ctx context.Context, exportID uuid.UUID, filePath string, size int64, expiresAt time.Time) error
+	// FailExport marks an export as failed with a reason.
+	FailExport(ctx context.Context, exportID uuid.UUID, reason string) error
+	// ExpireExports marks completed exports past their expiry as expired and
+	// returns the archive paths that can now be removed.
+	ExpireExports(ctx context.Context) ([]string, error)
+	// ExportUserData collects every table holding the user's data, one JSON array per table.
+	ExportUserData(ctx context.Context, userID uuid.UUID) ([]types.UserDataSection, error)
+
+	// ScheduleDeletion marks the account for deletion at scheduledFor.
+	// Returns ErrConflict if a deletion is already scheduled.
+	ScheduleDeletion(ctx context.Context, userID uuid.UUID, scheduledFor time.Time) (*types.AccountDeletionStatus, error)
+	// CancelDeletion clears a scheduled deletion. Returns ErrNotFound if none is scheduled.
+	CancelDeletion(ctx context.Context, userID uuid.UUID) error
+	// GetDeletionStatus reports whether the account is scheduled for deletion.
+	GetDeletionStatus(ctx context.Context, userID uuid.UUID) (*types.AccountDeletionStatus, error)
+	// DueDeletions returns up to limit users whose grace period has ended.
+	DueDeletions(ctx context.Context, limit int) ([]uuid.UUID, error)
+	// PurgeUser hard-deletes a user whose grace period has ended. Reviews, LLM logs
+	// and audit log entries are anonymized rather than deleted. Returns the export
+	// archive paths to remove.
+	// Returns ErrNotFound if the user no longer exists or the deletion was cancelled.
+	PurgeUser(ctx context.Context, userID uuid.UUID) ([]string, error)
+}
+
+type RepositoryImpl struct {
+	logger *slog.Logger
+	pgpool *pgxpool.Pool
+}
+
+func NewRepository(pgxpool *pgxpool.Pool, logger *slog.Logger) *RepositoryImpl {
+	return &RepositoryImpl{
+		logger: logger,
+		pgpool: pgxpool,
+	}
+}
+
+const exportColumns = `id, user_id, status, file_path, file_size_bytes, error_message,
+		       requested_at, started_at, completed_at, expires_at`
+
+func scanExport(row pgx.Row) (*types.DataExport, error) {
+	var e types.DataExport
+	err := row.Scan(&e.ID, &e.UserID, &e.Status, &e.FilePath, &e.FileSizeBytes, &e.ErrorMessage,
+		&e.RequestedAt, &e.StartedAt, &e.CompletedAt, &e.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// CreateExport implements Repository.
+func (r *RepositoryImpl) CreateExport(ctx context.Context, userID uuid.UUID) (*types.DataExport, error) {
+	ctx, span := otel.Tracer("PrivacyRepo").Start(ctx, "CreateExport", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.operation", "INSERT"),
+		attribute.String("db.sql.table", "user_data_exports"),
+		attribute.String("db.user.id", userID.String()),
+	))
+	defer span.End()
+
+	query := `INSERT INTO user_data_exports (user_id) VALUES ($1) RETURNING ` + exportColumns
+	export, err := scanExport(r.pgpool.QueryRow(ctx, query, userID))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505": // unique_violation on idx_user_data_exports_one_active
+				span.SetStatus(codes.Error, "Export already in progress")
+				return nil, fmt.Errorf("an export is already in progress: %w", types.ErrConflict)
+			case "23503": // foreign_key_violation
+				span.SetStatus(codes.Error, "User not found")
+				return nil, fmt.Errorf("user not found: %w", types.ErrNotFound)
+			}
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB insert failed")
+		return nil, fmt.Errorf("database error creating export: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Export queued")
+	return export, nil
+}
+
+// GetExport implements Repository.
+func (r *RepositoryImpl) GetExport(ctx context.Context, userID, exportID uuid.UUID) (*types.DataExport, error) {
+	query := `SELECT ` + exportColumns + ` FROM user_data_exports WHERE id = $1 AND user_id = $2`
+	export, err := scanExport(r.pgpool.QueryRow(ctx, query, exportID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("export not found: %w", types.ErrNotFound)
+		}
+		return nil, fmt.Errorf("database error fetching export: %w", err)
+	}
+	return export, nil
+}
+
+// ClaimPendingExport implements Repository.
+func (r *RepositoryImpl) ClaimPendingExport(ctx context.Context, lease time.Duration) (*types.DataExport, error) {
+	query := `
+		UPDATE user_data_exports SET status = 'processing', started_at = NOW()
+		WHERE id = (
+			SELECT id FROM user_data_exports
+			WHERE status = 'pending'
+			   OR (status = 'processing' AND started_at < NOW() - make_interval(secs => $1))
+			ORDER BY requested_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + exportColumns
+	export, err := scanExport(r.pgpool.QueryRow(ctx, query, lease.Seconds()))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("database error claiming export: %w", err)
+	}
+	return export, nil
+}
+
+// CompleteExport implements Repository.
+func (r *RepositoryImpl) CompleteExport(ctx context.Context, exportID uuid.UUID, filePath string, size int64, expiresAt time.Time) error {
+	query := `
+		UPDATE user_data_exports
+		SET status = 'completed', file_path = $2, file_size_bytes = $3, completed_at = NOW(), expires_at = $4
+		WHERE id = $1`
+	tag, err := r.pgpool.Exec(ctx, query, exportID, filePath, size, expiresAt)
+	if err != nil {
+		return fmt.Errorf("database error completing export: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("export not found: %w", types.ErrNotFound)
+	}
+	return nil
+}
+
+// FailExport implements Repository.
+func (r *RepositoryImpl) FailExport(ctx context.Context, exportID uuid.UUID, reason string) error {
+	query := `
+		UPDATE user_data_exports
+		SET status = 'failed', error_message = $2, completed_at = NOW()
+		WHERE id = $1`
+	if _, err := r.pgpool.Exec(ctx, query, exportID, reason); err != nil {
+		return fmt.Errorf("database error failing export: %w", err)
+	}
+	return nil
+}
+
+// ExpireExports implements Repository.
+func (r *RepositoryImpl) ExpireExports(ctx context.Context) ([]string, error) {
+	query := `
+		UPDATE user_data_exports e SET status = 'expired', file_path = NULL
+		FROM (
+			SELECT id, file_path FROM user_data_exports
+			WHERE status = 'completed' AND expires_at <= NOW()
+			FOR UPDATE
+		) prev
+		WHERE e.id = prev.id
+		RETURNING prev.file_path`
+	rows, err := r.pgpool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("database error expiring exports: %w", err)
+	}
+	defer rows.Close()
+	return collectPaths(rows)
+}
+
+func collectPaths(rows pgx.Rows) ([]string, error) {
+	var paths []string
+	for rows.Next() {
+		var path *string
+		if err := rows.Scan(&path); err != nil {
+			return nil, fmt.Errorf("failed to scan export path: %w", err)
+		}
+		if path != nil {
+			paths = append(paths, *path)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating export paths: %w", err)
+	}
+	return paths, nil
+}
+
+// exportQueries lists every table holding a user's data. Each query takes the user ID
+// as $1 and returns a single JSON array. Credentials are never exported.
+var exportQueries = []struct {
+	name  string
+	query string
+}{
+	{"profile", `SELECT COALESCE(jsonb_agg(to_jsonb(t) - 'password_hash'), '[]'::jsonb) FROM users t WHERE t.id = $1`},
+	{"subscriptions", `SELECT COALESCE(jsonb_agg(to_jsonb(t)), '[]'::jsonb) FROM subscriptions t WHERE t.user_id = $1`},
+	{"preference_profiles", `SELECT COALESCE(jsonb_agg(to_jsonb(t) ORDER BY t.created_at), '[]'::jsonb) FROM user_preference_profiles t WHERE t.user_id = $1`},
+	{"interests", `
+		SELECT COALESCE(jsonb_agg(to_jsonb(t) || jsonb_build_object('interest_name', i.name) ORDER BY i.name), '[]'::jsonb)
+		FROM user_interests t JOIN interests i ON i.id = t.interest_id
+		WHERE t.user_id = $1`},
+	{"personal_tags", `SELECT COALESCE(jsonb_agg(to_jsonb(t) ORDER BY t.created_at), '[]'::jsonb) FROM user_personal_tags t WHERE t.user_id = $1`},
+	{"llm_interactions", `SELECT COALESCE(jsonb_agg(to_jsonb(t) ORDER BY t.created_at), '[]'::jsonb) FROM llm_interactions t WHERE t.user_id = $1`},
+	{"chat_sessions", `SELECT COALESCE(jsonb_agg(to_jsonb(t) ORDER BY t.created_at), '[]'::jsonb) FROM chat_sessions t WHERE t.user_id = $1`},
+	{"lists", `
+		SELECT COALESCE(jsonb_agg(to_jsonb(l) || jsonb_build_object('items', COALESCE((
+			SELECT jsonb_agg(to_jsonb(li) ORDER BY li.position) FROM list_items li WHERE li.list_id = l.id
+		), '[]'::jsonb)) ORDER BY l.created_at), '[]'::jsonb)
+		FROM lists l WHERE l.user_id = $1`},
+	{"favorite_pois", `SELECT COALESCE(jsonb_agg(to_jsonb(t) ORDER BY t.added_at), '[]'::jsonb) FROM user_favorite_pois t WHERE t.user_id = $1`},
+	{"saved_itineraries", `SELECT COALESCE(jsonb_agg(to_jsonb(t) ORDER BY t.created_at), '[]'::jsonb) FROM user_saved_itineraries t WHERE t.user_id = $1`},
+	{"reviews", `SELECT COALESCE(jsonb_agg(to_jsonb(t) ORDER BY t.created_at), '[]'::jsonb) FROM reviews t WHERE t.user_id = $1`},
+	{"interaction_events", `SELECT COALESCE(jsonb_agg(to_jsonb(t) ORDER BY t.created_at), '[]'::jsonb) FROM user_interaction_events t WHERE t.user_id = $1`},
+	{"affinities", `SELECT COALESCE(jsonb_agg(to_jsonb(t) ORDER BY t.dimension, t.value), '[]'::jsonb) FROM user_affinities t WHERE t.user_id = $1`},
+	{"saved_lists", `SELECT COALESCE(jsonb_agg(to_jsonb(t) ORDER BY t.saved_at), '[]'::jsonb) FROM saved_lists t WHERE t.user_id = $1`},
+	{"analytics_events", `SELECT COALESCE(jsonb_agg(to_jsonb(t) ORDER BY t.occurred_at), '[]'::jsonb) FROM analytics_events t WHERE t.user_id = $1`},
+}
+
+// ExportUserData implements Repository. All sections are read in one
+// repeatable-read transaction so the export is a consistent snapshot.
+func (r *RepositoryImpl) ExportUserData(ctx context.Context, userID uuid.UUID) ([]types.UserDataSection, error) {
+	ctx, span := otel.Tracer("PrivacyRepo").Start(ctx, "ExportUserData", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.user.id", userID.String()),
+	))
+	defer span.End()
+
+	tx, err := r.pgpool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("database error beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	sections := make([]types.UserDataSection, 0, len(exportQueries))
+	for _, q := range exportQueries {
+		var data []byte
+		if err := tx.QueryRow(ctx, q.query, userID).Scan(&data); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "DB query failed")
+			return nil, fmt.Errorf("database error exporting %s: %w", q.name, err)
+		}
+		sections = append(sections, types.UserDataSection{Name: q.name, Data: data})
+	}
+
+	span.SetStatus(codes.Ok, "User data exported")
+	return sections, nil
+}
+
+// ScheduleDeletion implements Repository.
+func (r *RepositoryImpl) ScheduleDeletion(ctx context.Context, userID uuid.UUID, scheduledFor time.Time) (*types.AccountDeletionStatus, error) {
+	query := `
+		UPDATE users SET deletion_requested_at = NOW(), deletion_scheduled_for = $2
+		WHERE id = $1 AND deletion_scheduled_for IS NULL
+		RETURNING deletion_requested_at, deletion_scheduled_for`
+	status := types.AccountDeletionStatus{Scheduled: true}
+	err := r.pgpool.QueryRow(ctx, query, userID, scheduledFor).Scan(&status.RequestedAt, &status.ScheduledFor)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			var exists bool
+			if err := r.pgpool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`, userID).Scan(&exists); err != nil {
+				return nil, fmt.Errorf("database error checking user: %w", err)
+			}
+			if !exists {
+				return nil, fmt.Errorf("user not found: %w", types.ErrNotFound)
+			}
+			return nil, fmt.Errorf("account deletion already scheduled: %w", types.ErrConflict)
+		}
+		return nil, fmt.Errorf("database error scheduling deletion: %w", err)
+	}
+	return &status, nil
+}
+
+// CancelDeletion implements Repository.
+func (r *RepositoryImpl) CancelDeletion(ctx context.Context, userID uuid.UUID) error {
+	query := `
+		UPDATE users SET deletion_requested_at = NULL, deletion_scheduled_for = NULL
+		WHERE id = $1 AND deletion_scheduled_for IS NOT NULL`
+	tag, err := r.pgpool.Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("database error cancelling deletion: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("no account deletion scheduled: %w", types.ErrNotFound)
+	}
+	return nil
+}
+
+// GetDeletionStatus implements Repository.
+func (r *RepositoryImpl) GetDeletionStatus(ctx context.Context, userID uuid.UUID) (*types.AccountDeletionStatus, error) {
+	var status types.AccountDeletionStatus
+	err := r.pgpool.QueryRow(ctx,
+		`SELECT deletion_requested_at, deletion_scheduled_for FROM users WHERE id = $1`, userID,
+	).Scan(&status.RequestedAt, &status.ScheduledFor)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("user not found: %w", types.ErrNotFound)
+		}
+		return nil, fmt.Errorf("database error fetching deletion status: %w", err)
+	}
+	status.Scheduled = status.ScheduledFor != nil
+	return &status, nil
+}
+
+// DueDeletions implements Repository.
+func (r *RepositoryImpl) DueDeletions(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	rows, err := r.pgpool.Query(ctx, `
+		SELECT id FROM users
+		WHERE deletion_scheduled_for IS NOT NULL AND deletion_scheduled_for <= NOW()
+		ORDER BY deletion_scheduled_for
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching due deletions: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan user id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating due deletions: %w", err)
+	}
+	return ids, nil
+}
+
+// PurgeUser implements Repository.
+func (r *RepositoryImpl) PurgeUser(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	ctx, span := otel.Tracer("PrivacyRepo").Start(ctx, "PurgeUser", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.operation", "DELETE"),
+		attribute.String("db.sql.table", "users"),
+		attribute.String("db.user.id", userID.String()),
+	))
+	defer span.End()
+
+	tx, err := r.pgpool.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("database error beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the user and re-check the schedule so a concurrent cancel wins
+	var id uuid.UUID
+	err = tx.QueryRow(ctx, `
+		SELECT id FROM users
+		WHERE id = $1 AND deletion_scheduled_for IS NOT NULL AND deletion_scheduled_for <= NOW()
+		FOR UPDATE`, userID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			span.SetStatus(codes.Error, "Not due for deletion")
+			return nil, fmt.Errorf("user not due for deletion: %w", types.ErrNotFound)
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("database error locking user: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `SELECT file_path FROM user_data_exports WHERE user_id = $1 AND file_path IS NOT NULL`, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("database error fetching exports: %w", err)
+	}
+	paths, err := collectPaths(rows)
+	rows.Close()
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// Allow the audit_log redaction below; the setting ends with the transaction
+	if _, err := tx.Exec(ctx, `SELECT set_config('audit_log.redact', 'on', true)`); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("database error enabling audit log redaction: %w", err)
+	}
+
+	steps := []struct {
+		what  string
+		query string
+	}{
+		// Reviews stay published but lose their author
+		{"anonymizing reviews", `UPDATE reviews SET user_id = NULL WHERE user_id = $1`},
+		// LLM logs keep only their usage metrics (model, tokens, latency, city) for
+		// cost analysis. Prompts and responses quote the user's own words and
+		// preferences, so they go along with anything linking them to the user.
+		{"anonymizing LLM interactions", `
+			UPDATE llm_interactions
+			SET user_id = NULL, session_id = NULL, prompt = '', request_payload = NULL,
+			    response_text = NULL, response_payload = NULL
+			WHERE user_id = $1`},
+		// Audit entries stay as a record of what happened, without the profile
+		// and email values they changed or where the user acted from
+		{"redacting audit log", `
+			UPDATE audit_log
+			SET before = NULL, after = NULL,
+			    ip_address = CASE WHEN actor_id = $1 THEN NULL ELSE ip_address END,
+			    user_agent = CASE WHEN actor_id = $1 THEN NULL ELSE user_agent END
+			WHERE actor_id = $1 OR (target_type = 'user' AND target_id = $1)`},
+		// Analytics events have no foreign key to cascade along. The daily
+		// aggregates already rolled up from them hold no user IDs and stay.
+		{"deleting analytics events", `DELETE FROM analytics_events WHERE user_id = $1`},
+		// chat_sessions has a NO ACTION foreign key alongside the cascading one
+		{"deleting chat sessions", `DELETE FROM chat_sessions WHERE user_id = $1`},
+		// Everything else cascades
+		{"deleting user", `DELETE FROM users WHERE id = $1`},
+	}
+	for _, step := range steps {
+		if _, err := tx.Exec(ctx, step.query, userID); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Purge failed")
+			return nil, fmt.Errorf("database error %s: %w", step.what, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("database error committing transaction: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "User purged")
+	return paths, nil
+}
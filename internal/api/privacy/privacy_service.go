@@ -0,0 +1,383 @@
+package privacy
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/config"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/audit"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Service = (*ServiceImpl)(nil)
+
+const (
+	defaultExportTTL           = 7 * 24 * time.Hour
+	defaultDeletionGracePeriod = 30 * 24 * time.Hour
+	defaultPollInterval        = 30 * time.Second
+	defaultExportLease         = 15 * time.Minute
+	// purgeBatchSize bounds how many accounts one worker tick deletes.
+	purgeBatchSize = 50
+)
+
+// Service defines the business logic for GDPR data export and account deletion.
+type Service interface {
+	// RequestExport queues a "download my data" job.
+	RequestExport(ctx context.Context, userID uuid.UUID) (*types.DataExport, error)
+	// GetExport returns the status of one of the user's exports.
+	GetExport(ctx context.Context, userID, exportID uuid.UUID) (*types.DataExport, error)
+	// OpenExport opens a completed export archive for download. The caller closes the file.
+	OpenExport(ctx context.Context, userID, exportID uuid.UUID) (*os.File, *types.DataExport, error)
+
+	// RequestDeletion schedules the account for deletion after the grace period.
+	RequestDeletion(ctx context.Context, userID uuid.UUID) (*types.AccountDeletionStatus, error)
+	// CancelDeletion cancels a scheduled deletion during the grace period.
+	CancelDeletion(ctx context.Context, userID uuid.UUID) error
+	// GetDeletionStatus reports whether the account is scheduled for deletion.
+	GetDeletionStatus(ctx context.Context, userID uuid.UUID) (*types.AccountDeletionStatus, error)
+
+	// Run processes pending exports, expires old ones and purges accounts whose
+	// grace period has ended, until ctx is cancelled.
+	Run(ctx context.Context)
+}
+
+type ServiceImpl struct {
+	logger *slog.Logger
+	repo   Repository
+	audit  audit.Recorder
+	cfg    config.PrivacyConfig
+}
+
+// NewService creates a new privacy service. Zero config values fall back to defaults.
+func NewService(repo Repository, auditor audit.Recorder, cfg config.PrivacyConfig, logger *slog.Logger) *ServiceImpl {
+	if cfg.ExportDir == "" {
+		cfg.ExportDir = filepath.Join(os.TempDir(), "loci-exports")
+	}
+	if cfg.ExportTTL <= 0 {
+		cfg.ExportTTL = defaultExportTTL
+	}
+	if cfg.DeletionGracePeriod <= 0 {
+		cfg.DeletionGracePeriod = defaultDeletionGracePeriod
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if cfg.ExportLease <= 0 {
+		cfg.ExportLease = defaultExportLease
+	}
+	return &ServiceImpl{
+		logger: logger,
+		repo:   repo,
+		audit:  auditor,
+		cfg:    cfg,
+	}
+}
+
+// RequestExport implements Service.
+func (s *ServiceImpl) RequestExport(ctx context.Context, userID uuid.UUID) (*types.DataExport, error) {
+	ctx, span := otel.Tracer("PrivacyService").Start(ctx, "RequestExport", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+	))
+	defer span.End()
+	l := s.logger.With(slog.String("method", "RequestExport"), slog.String("userID", userID.String()))
+
+	export, err := s.repo.CreateExport(ctx, userID)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to queue data export", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to queue export")
+		return nil, fmt.Errorf("failed to request data export: %w", err)
+	}
+
+	s.audit.Record(ctx, types.AuditEntry{
+		ActorID:    &userID,
+		Action:     types.AuditActionDataExportRequested,
+		TargetType: types.AuditTargetUser,
+		TargetID:   &userID,
+		Metadata:   map[string]interface{}{"export_id": export.ID},
+	})
+	l.InfoContext(ctx, "Data export queued", slog.String("exportID", export.ID.String()))
+	span.SetStatus(codes.Ok, "Export queued")
+	return export, nil
+}
+
+// GetExport implements Service.
+func (s *ServiceImpl) GetExport(ctx context.Context, userID, exportID uuid.UUID) (*types.DataExport, error) {
+	export, err := s.repo.GetExport(ctx, userID, exportID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data export: %w", err)
+	}
+	return export, nil
+}
+
+// OpenExport implements Service. Exports that are not yet ready return ErrConflict;
+// expired or missing archives return ErrNotFound.
+func (s *ServiceImpl) OpenExport(ctx context.Context, userID, exportID uuid.UUID) (*os.File, *types.DataExport, error) {
+	export, err := s.repo.GetExport(ctx, userID, exportID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get data export: %w", err)
+	}
+	switch export.Status {
+	case types.DataExportCompleted:
+	case types.DataExportPending, types.DataExportProcessing:
+		return nil, nil, fmt.Errorf("export is not ready yet: %w", types.ErrConflict)
+	default:
+		return nil, nil, fmt.Errorf("export is %s: %w", export.Status, types.ErrNotFound)
+	}
+	if export.FilePath == nil || (export.ExpiresAt != nil && time.Now().After(*export.ExpiresAt)) {
+		return nil, nil, fmt.Errorf("export has expired: %w", types.ErrNotFound)
+	}
+
+	f, err := os.Open(*export.FilePath)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Export archive missing", slog.String("exportID", exportID.String()), slog.Any("error", err))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, fmt.Errorf("export archive missing: %w", types.ErrNotFound)
+		}
+		return nil, nil, fmt.Errorf("failed to open export archive: %w", err)
+	}
+	return f, export, nil
+}
+
+// RequestDeletion implements Service. The account remains usable until the
+// grace period ends so the user can sign in and cancel.
+func (s *ServiceImpl) RequestDeletion(ctx context.Context, userID uuid.UUID) (*types.AccountDeletionStatus, error) {
+	ctx, span := otel.Tracer("PrivacyService").Start(ctx, "RequestDeletion", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+	))
+	defer span.End()
+	l := s.logger.With(slog.String("method", "RequestDeletion"), slog.String("userID", userID.String()))
+
+	scheduledFor := time.Now().Add(s.cfg.DeletionGracePeriod)
+	status, err := s.repo.ScheduleDeletion(ctx, userID, scheduledFor)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to schedule account deletion", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to schedule deletion")
+		return nil, fmt.Errorf("failed to request account deletion: %w", err)
+	}
+
+	s.audit.Record(ctx, types.AuditEntry{
+		ActorID:    &userID,
+		Action:     types.AuditActionDeletionRequested,
+		TargetType: types.AuditTargetUser,
+		TargetID:   &userID,
+		Metadata:   map[string]interface{}{"scheduled_for": status.ScheduledFor},
+	})
+	l.InfoContext(ctx, "Account deletion scheduled", slog.Time("scheduledFor", scheduledFor))
+	span.SetStatus(codes.Ok, "Deletion scheduled")
+	return status, nil
+}
+
+// CancelDeletion implements Service.
+func (s *ServiceImpl) CancelDeletion(ctx context.Context, userID uuid.UUID) error {
+	l := s.logger.With(slog.String("method", "CancelDeletion"), slog.String("userID", userID.String()))
+	if err := s.repo.CancelDeletion(ctx, userID); err != nil {
+		l.ErrorContext(ctx, "Failed to cancel account deletion", slog.Any("error", err))
+		return fmt.Errorf("failed to cancel account deletion: %w", err)
+	}
+	s.audit.Record(ctx, types.AuditEntry{
+		ActorID:    &userID,
+		Action:     types.AuditActionDeletionCancelled,
+		TargetType: types.AuditTargetUser,
+		TargetID:   &userID,
+	})
+	l.InfoContext(ctx, "Account deletion cancelled")
+	return nil
+}
+
+// GetDeletionStatus implements Service.
+func (s *ServiceImpl) GetDeletionStatus(ctx context.Context, userID uuid.UUID) (*types.AccountDeletionStatus, error) {
+	status, err := s.repo.GetDeletionStatus(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account deletion status: %w", err)
+	}
+	return status, nil
+}
+
+// Run implements Service.
+func (s *ServiceImpl) Run(ctx context.Context) {
+	l := s.logger.With(slog.String("worker", "privacy"))
+	l.InfoContext(ctx, "Privacy worker started", slog.Duration("pollInterval", s.cfg.PollInterval))
+
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		s.tick(ctx)
+		select {
+		case <-ctx.Done():
+			l.Info("Privacy worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick runs one round of background work. Errors are logged so one failure
+// does not stop the others.
+func (s *ServiceImpl) tick(ctx context.Context) {
+	for {
+		processed, err := s.processNextExport(ctx)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to process data export", slog.Any("error", err))
+		}
+		if !processed || ctx.Err() != nil {
+			break
+		}
+	}
+	if err := s.expireExports(ctx); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to expire data exports", slog.Any("error", err))
+	}
+	if err := s.purgeDueAccounts(ctx); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to purge deleted accounts", slog.Any("error", err))
+	}
+}
+
+// processNextExport claims and builds one pending export. It reports whether
+// an export was claimed, so the caller can keep draining the queue.
+func (s *ServiceImpl) processNextExport(ctx context.Context) (bool, error) {
+	export, err := s.repo.ClaimPendingExport(ctx, s.cfg.ExportLease)
+	if err != nil {
+		return false, err
+	}
+	if export == nil {
+		return false, nil
+	}
+
+	ctx, span := otel.Tracer("PrivacyService").Start(ctx, "ProcessExport", trace.WithAttributes(
+		attribute.String("export.id", export.ID.String()),
+		attribute.String("user.id", export.UserID.String()),
+	))
+	defer span.End()
+	l := s.logger.With(slog.String("exportID", export.ID.String()), slog.String("userID", export.UserID.String()))
+
+	path, size, err := s.buildArchive(ctx, export)
+	if err != nil {
+		l.ErrorContext(ctx, "Data export failed", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Export failed")
+		if ferr := s.repo.FailExport(ctx, export.ID, "failed to generate export"); ferr != nil {
+			return true, fmt.Errorf("failed to mark export as failed: %w", ferr)
+		}
+		return true, nil
+	}
+
+	if err := s.repo.CompleteExport(ctx, export.ID, path, size, time.Now().Add(s.cfg.ExportTTL)); err != nil {
+		_ = os.Remove(path)
+		span.RecordError(err)
+		return true, fmt.Errorf("failed to complete export: %w", err)
+	}
+
+	l.InfoContext(ctx, "Data export completed", slog.Int64("bytes", size))
+	span.SetStatus(codes.Ok, "Export completed")
+	return true, nil
+}
+
+// buildArchive writes the user's data as <section>.json files into a ZIP in
+// ExportDir. The file is written under a temporary name and renamed once complete.
+func (s *ServiceImpl) buildArchive(ctx context.Context, export *types.DataExport) (string, int64, error) {
+	sections, err := s.repo.ExportUserData(ctx, export.UserID)
+	if err != nil {
+		return "", 0, err
+	}
+	if err := os.MkdirAll(s.cfg.ExportDir, 0o700); err != nil {
+		return "", 0, fmt.Errorf("failed to create export dir: %w", err)
+	}
+
+	path := filepath.Join(s.cfg.ExportDir, export.ID.String()+".zip")
+	tmp, err := os.CreateTemp(s.cfg.ExportDir, export.ID.String()+"-*.tmp")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	zw := zip.NewWriter(tmp)
+	for _, section := range sections {
+		w, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     section.Name + ".json",
+			Method:   zip.Deflate,
+			Modified: export.RequestedAt,
+		})
+		if err != nil {
+			tmp.Close()
+			return "", 0, fmt.Errorf("failed to add %s to archive: %w", section.Name, err)
+		}
+		if _, err := w.Write(section.Data); err != nil {
+			tmp.Close()
+			return "", 0, fmt.Errorf("failed to write %s to archive: %w", section.Name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		tmp.Close()
+		return "", 0, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	info, err := tmp.Stat()
+	if err != nil {
+		tmp.Close()
+		return "", 0, fmt.Errorf("failed to stat archive: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", 0, fmt.Errorf("failed to close archive: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", 0, fmt.Errorf("failed to move archive into place: %w", err)
+	}
+	return path, info.Size(), nil
+}
+
+// expireExports removes archives that are past their expiry.
+func (s *ServiceImpl) expireExports(ctx context.Context) error {
+	paths, err := s.repo.ExpireExports(ctx)
+	if err != nil {
+		return err
+	}
+	s.removeFiles(ctx, paths)
+	return nil
+}
+
+// purgeDueAccounts hard-deletes accounts whose grace period has ended.
+func (s *ServiceImpl) purgeDueAccounts(ctx context.Context) error {
+	ids, err := s.repo.DueDeletions(ctx, purgeBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, userID := range ids {
+		l := s.logger.With(slog.String("userID", userID.String()))
+		paths, err := s.repo.PurgeUser(ctx, userID)
+		if err != nil {
+			if errors.Is(err, types.ErrNotFound) {
+				continue // cancelled or already purged since DueDeletions
+			}
+			l.ErrorContext(ctx, "Failed to purge account", slog.Any("error", err))
+			continue
+		}
+		s.removeFiles(ctx, paths)
+		s.audit.Record(ctx, types.AuditEntry{
+			Action:     types.AuditActionAccountDeleted,
+			TargetType: types.AuditTargetUser,
+			TargetID:   &userID,
+		})
+		l.InfoContext(ctx, "Account purged after grace period")
+	}
+	return nil
+}
+
+func (s *ServiceImpl) removeFiles(ctx context.Context, paths []string) {
+	for _, p := range paths {
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			s.logger.WarnContext(ctx, "Failed to remove export archive", slog.String("path", p), slog.Any("error", err))
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package privacy
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/config"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+// MockPrivacyRepository is a mock implementation of Repository
+type MockPrivacyRepository struct {
+	mock.Mock
+}
+
+func (m *MockPrivacyRepository) CreateExport(ctx context.Context, userID uuid.UUID) (*types.DataExport, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.DataExport), args.Error(1)
+}
+
+func (m *MockPrivacyRepository) GetExport(ctx context.Context, userID, exportID uuid.UUID) (*types.DataExport, error) {
+	args := m.Called(ctx, userID, exportID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.DataExport), args.Error(1)
+}
+
+func (m *MockPrivacyRepository) ClaimPendingExport(ctx context.Context, lease time.Duration) (*types.DataExport, error) {
+	args := m.Called(ctx, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.DataExport), args.Error(1)
+}
+
+func (m *MockPrivacyRepository) CompleteExport(ctx context.Context, exportID uuid.UUID, filePath string, size int64, expiresAt time.Time) error {
+	return m.Called(ctx, exportID, filePath, size, expiresAt).Error(0)
+}
+
+func (m *MockPrivacyRepository) FailExport(ctx context.Context, exportID uuid.UUID, reason string) error {
+	return m.Called(ctx, exportID, reason).Error(0)
+}
+
+func (m *MockPrivacyRepository) ExpireExports(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockPrivacyRepository) ExportUserData(ctx context.Context, userID uuid.UUID) ([]types.UserDataSection, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.UserDataSection), args.Error(1)
+}
+
+func (m *MockPrivacyRepository) ScheduleDeletion(ctx context.Context, userID uuid.UUID, scheduledFor time.Time) (*types.AccountDeletionStatus, error) {
+	args := m.Called(ctx, userID, scheduledFor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.AccountDeletionStatus), args.Error(1)
+}
+
+func (m *MockPrivacyRepository) CancelDeletion(ctx context.Context, userID uuid.UUID) error {
+	return m.Called(ctx, userID).Error(0)
+}
+
+func (m *MockPrivacyRepository) GetDeletionStatus(ctx context.Context, userID uuid.UUID) (*types.AccountDeletionStatus, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.AccountDeletionStatus), args.Error(1)
+}
+
+func (m *MockPrivacyRepository) DueDeletions(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockPrivacyRepository) PurgeUser(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// recordingAuditor keeps recorded entries for assertions
+type recordingAuditor struct {
+	entries []types.AuditEntry
+}
+
+func (a *recordingAuditor) Record(_ context.Context, entry types.AuditEntry) {
+	a.entries = append(a.entries, entry)
+}
+
+// Helper to setup service with mock repository, writing exports to a temp dir
+func setupPrivacyServiceTest(t *testing.T) (*ServiceImpl, *MockPrivacyRepository, *recordingAuditor) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	mockRepo := new(MockPrivacyRepository)
+	auditor := &recordingAuditor{}
+	cfg := config.PrivacyConfig{ExportDir: t.TempDir(), DeletionGracePeriod: 14 * 24 * time.Hour}
+	return NewService(mockRepo, auditor, cfg, logger), mockRepo, auditor
+}
+
+func TestServiceImpl_ProcessNextExport(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("writes a zip with one json file per section", func(t *testing.T) {
+		service, mockRepo, _ := setupPrivacyServiceTest(t)
+		export := &types.DataExport{ID: uuid.New(), UserID: uuid.New(), Status: types.DataExportProcessing, RequestedAt: time.Now()}
+		sections := []types.UserDataSection{
+			{Name: "profile", Data: []byte(`[{"email":"a@example.com"}]`)},
+			{Name: "reviews", Data: []byte(`[]`)},
+		}
+
+		var path string
+		mockRepo.On("ClaimPendingExport", mock.Anything, defaultExportLease).Return(export, nil).Once()
+		mockRepo.On("ExportUserData", mock.Anything, export.UserID).Return(sections, nil).Once()
+		mockRepo.On("CompleteExport", mock.Anything, export.ID, mock.AnythingOfType("string"), mock.AnythingOfType("int64"), mock.AnythingOfType("time.Time")).
+			Run(func(args mock.Arguments) { path = args.String(2) }).
+			Return(nil).Once()
+
+		processed, err := service.processNextExport(ctx)
+
+		require.NoError(t, err)
+		assert.True(t, processed)
+		mockRepo.AssertExpectations(t)
+
+		zr, err := zip.OpenReader(path)
+		require.NoError(t, err)
+		defer zr.Close()
+		contents := map[string]string{}
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			b, err := io.ReadAll(rc)
+			rc.Close()
+			require.NoError(t, err)
+			contents[f.Name] = string(b)
+		}
+		assert.Equal(t, map[string]string{
+			"profile.json": `[{"email":"a@example.com"}]`,
+			"reviews.json": `[]`,
+		}, contents)
+
+		// No temporary files left behind
+		entries, err := os.ReadDir(filepath.Dir(path))
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("marks export failed when data collection fails", func(t *testing.T) {
+		service, mockRepo, _ := setupPrivacyServiceTest(t)
+		export := &types.DataExport{ID: uuid.New(), UserID: uuid.New(), Status: types.DataExportProcessing}
+
+		mockRepo.On("ClaimPendingExport", mock.Anything, defaultExportLease).Return(export, nil).Once()
+		mockRepo.On("ExportUserData", mock.Anything, export.UserID).Return(nil, errors.New("db down")).Once()
+		mockRepo.On("FailExport", mock.Anything, export.ID, mock.AnythingOfType("string")).Return(nil).Once()
+
+		processed, err := service.processNextExport(ctx)
+
+		require.NoError(t, err)
+		assert.True(t, processed)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "CompleteExport", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("nothing pending", func(t *testing.T) {
+		service, mockRepo, _ := setupPrivacyServiceTest(t)
+		mockRepo.On("ClaimPendingExport", mock.Anything, defaultExportLease).Return(nil, nil).Once()
+
+		processed, err := service.processNextExport(ctx)
+
+		require.NoError(t, err)
+		assert.False(t, processed)
+	})
+}
+
+func TestServiceImpl_OpenExport(t *testing.T) {
+	ctx := context.Background()
+	userID, exportID := uuid.New(), uuid.New()
+
+	t.Run("not ready", func(t *testing.T) {
+		service, mockRepo, _ := setupPrivacyServiceTest(t)
+		mockRepo.On("GetExport", mock.Anything, userID, exportID).
+			Return(&types.DataExport{ID: exportID, Status: types.DataExportPending}, nil).Once()
+
+		_, _, err := service.OpenExport(ctx, userID, exportID)
+
+		assert.True(t, errors.Is(err, types.ErrConflict))
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		service, mockRepo, _ := setupPrivacyServiceTest(t)
+		path := filepath.Join(t.TempDir(), "export.zip")
+		require.NoError(t, os.WriteFile(path, []byte("zip"), 0o600))
+		expired := time.Now().Add(-time.Minute)
+		mockRepo.On("GetExport", mock.Anything, userID, exportID).
+			Return(&types.DataExport{ID: exportID, Status: types.DataExportCompleted, FilePath: &path, ExpiresAt: &expired}, nil).Once()
+
+		_, _, err := service.OpenExport(ctx, userID, exportID)
+
+		assert.True(t, errors.Is(err, types.ErrNotFound))
+	})
+}
+
+func TestServiceImpl_RequestDeletion(t *testing.T) {
+	service, mockRepo, auditor := setupPrivacyServiceTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	var scheduledFor time.Time
+	mockRepo.On("ScheduleDeletion", mock.Anything, userID, mock.AnythingOfType("time.Time")).
+		Run(func(args mock.Arguments) { scheduledFor = args.Get(2).(time.Time) }).
+		Return(&types.AccountDeletionStatus{Scheduled: true}, nil).Once()
+
+	status, err := service.RequestDeletion(ctx, userID)
+
+	require.NoError(t, err)
+	assert.True(t, status.Scheduled)
+	assert.WithinDuration(t, time.Now().Add(14*24*time.Hour), scheduledFor, time.Minute)
+	require.Len(t, auditor.entries, 1)
+	assert.Equal(t, types.AuditActionDeletionRequested, auditor.entries[0].Action)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestServiceImpl_PurgeDueAccounts(t *testing.T) {
+	service, mockRepo, auditor := setupPrivacyServiceTest(t)
+	ctx := context.Background()
+	purged, cancelled := uuid.New(), uuid.New()
+
+	archive := filepath.Join(t.TempDir(), "old-export.zip")
+	require.NoError(t, os.WriteFile(archive, []byte("zip"), 0o600))
+
+	mockRepo.On("DueDeletions", mock.Anything, purgeBatchSize).Return([]uuid.UUID{cancelled, purged}, nil).Once()
+	mockRepo.On("PurgeUser", mock.Anything, cancelled).Return(nil, types.ErrNotFound).Once()
+	mockRepo.On("PurgeUser", mock.Anything, purged).Return([]string{archive}, nil).Once()
+
+	require.NoError(t, service.purgeDueAccounts(ctx))
+
+	mockRepo.AssertExpectations(t)
+	_, err := os.Stat(archive)
+	assert.True(t, errors.Is(err, os.ErrNotExist), "export archive should be removed")
+	require.Len(t, auditor.entries, 1)
+	assert.Equal(t, types.AuditActionAccountDeleted, auditor.entries[0].Action)
+	assert.Nil(t, auditor.entries[0].ActorID)
+	assert.Equal(t, &purged, auditor.entries[0].TargetID)
+}
@@ -113,11 +113,14 @@ func (u *HandlerImpl) GetSearchProfile(w http.ResponseWriter, r *http.Request) {
 
 // GetSearchProfiles godoc
 // @Summary      Get User Preference Profiles
-// @Description  Fetches all preference profiles for the authenticated user
+// @Description  Fetches a page of the authenticated user's preference profiles, the default one first and the rest by name. The Link header carries the next page.
 // @Tags         User Profiles
 // @Accept       json
 // @Produce      json
+// @Param        cursor query string false "Cursor of the page to fetch, from the Link header; omit for the first page"
+// @Param        limit query int false "Number of profiles per page (default 20, max 100)"
 // @Success      200 {array} types.UserPreferenceProfileResponse "User Preference Profiles"
+// @Failure      400 {object} types.Response "Invalid cursor"
 // @Failure      401 {object} types.Response "Unauthorized"
 // @Failure      500 {object} types.Response "Internal Server Error"
 // @Security     BearerAuth
@@ -146,15 +149,25 @@ func (u *HandlerImpl) GetSearchProfiles(w http.ResponseWriter, r *http.Request)
 		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid user ID format")
 	}
 
-	profile, err := u.userService.GetSearchProfiles(ctx, userID)
+	page, err := api.ParsePageRequest(r)
+	if err != nil {
+		api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	profile, next, err := u.userService.GetSearchProfiles(ctx, userID, page)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to fetch user profile", slog.Any("error", err))
 		if errors.Is(err, types.ErrNotFound) {
 			api.ErrorResponse(w, r, http.StatusNotFound, "User profile not found")
+			return
 		}
+		api.WriteServiceError(w, r, err, "Failed to retrieve user profiles")
+		return
 	}
 
 	l.InfoContext(ctx, "User profile fetched successfully")
+	api.SetPageLinks(w, r, next)
 	api.WriteJSONResponse(w, r, http.StatusOK, profile)
 	w.WriteHeader(http.StatusOK)
 }
@@ -227,6 +240,10 @@ func (u *HandlerImpl) CreateSearchProfile(w http.ResponseWriter, r *http.Request
 			api.ErrorResponse(w, r, http.StatusConflict, "Profile name already exists")
 			return
 		}
+		if errors.Is(err, types.ErrBadRequest) {
+			api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
 		if errors.Is(err, types.ErrNotFound) {
 			api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid tag or interest ID")
 			return
@@ -382,6 +399,10 @@ func (u *HandlerImpl) UpdateSearchProfile(w http.ResponseWriter, r *http.Request
 			api.ErrorResponse(w, r, http.StatusConflict, "Profile name already exists")
 			return
 		}
+		if errors.Is(err, types.ErrBadRequest) {
+			api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
 		if errors.Is(err, types.ErrNotFound) {
 			api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid tag or interest ID")
 			return
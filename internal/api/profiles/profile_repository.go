@@ -19,6 +19,7 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/embeddings"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
 )
 
@@ -27,8 +28,9 @@ var _ Repository = (*RepositoryImpl)(nil)
 // profilessRepo defines the contract for user data persistence.
 type Repository interface {
 	// GetSearchProfiles --- User Preference Profiles ---
-	// GetSearchProfiles retrieves all preference profiles for a user
-	GetSearchProfiles(ctx context.Context, userID uuid.UUID) ([]types.UserPreferenceProfileResponse, error)
+	// GetSearchProfiles retrieves a page of a user's preference profiles, the
+	// default one first and the rest by name, and the cursor of the next page
+	GetSearchProfiles(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]types.UserPreferenceProfileResponse, *types.Cursor, error)
 	// GetSearchProfile retrieves a specific preference profile by ID
 	GetSearchProfile(ctx context.Context, userID, profileID uuid.UUID) (*types.UserPreferenceProfileResponse, error)
 	// GetDefaultSearchProfile retrieves the default preference profile for a user
@@ -67,7 +69,7 @@ func NewPostgresUserRepo(pgxpool *pgxpool.Pool, logger *slog.Logger) *Repository
 //ORDER BY upp.profile_name
 
 // GetProfiles implements user.UserRepo.
-func (r *RepositoryImpl) GetSearchProfiles(ctx context.Context, userID uuid.UUID) ([]types.UserPreferenceProfileResponse, error) {
+func (r *RepositoryImpl) GetSearchProfiles(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]types.UserPreferenceProfileResponse, *types.Cursor, error) {
 	ctx, span := otel.Tracer("UserRepo").Start(ctx, "GetUserPreferenceProfiles", trace.WithAttributes(
 		semconv.DBSystemPostgreSQL,
 		attribute.String("db.sql.table", "user_preference_profiles"),
@@ -78,21 +80,33 @@ func (r *RepositoryImpl) GetSearchProfiles(ctx context.Context, userID uuid.UUID
 	l := r.logger.With(slog.String("method", "GetUserPreferenceProfiles"), slog.String("userID", userID.String()))
 	l.DebugContext(ctx, "Fetching user preference profiles")
 
-	query := `
+	// The default profile ranks 0 and the others 1, so the keyset can carry
+	// is_default as a number.
+	args := []interface{}{userID, page.FetchLimit()}
+	after := ""
+	if page.Cursor != nil {
+		if page.Cursor.Num == nil {
+			return nil, nil, fmt.Errorf("%w: malformed cursor", types.ErrBadRequest)
+		}
+		args = append(args, *page.Cursor.Num, page.Cursor.Key, page.Cursor.ID)
+		after = "AND (CASE WHEN is_default THEN 0 ELSE 1 END, profile_name, id) > ($3, $4, $5)"
+	}
+	query := fmt.Sprintf(`
         SELECT id, user_id, profile_name, is_default, search_radius_km, preferred_time, 
                budget_level, preferred_pace, prefer_accessible_pois, prefer_outdoor_seating, 
                prefer_dog_friendly, preferred_vibes, preferred_transport, dietary_needs, 
-               created_at, updated_at
+               accessibility_needs, created_at, updated_at
         FROM user_preference_profiles
-        WHERE user_id = $1
-        ORDER BY is_default DESC, profile_name`
+        WHERE user_id = $1 %s
+        ORDER BY CASE WHEN is_default THEN 0 ELSE 1 END, profile_name, id
+        LIMIT $2`, after)
 
-	rows, err := r.pgpool.Query(ctx, query, userID)
+	rows, err := r.pgpool.Query(ctx, query, args...)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to query user preference profiles", slog.Any("error", err))
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "DB query failed")
-		return nil, fmt.Errorf("database error fetching preference profiles: %w", err)
+		return nil, nil, fmt.Errorf("database error fetching preference profiles: %w", err)
 	}
 	defer rows.Close()
 
@@ -103,12 +117,12 @@ func (r *RepositoryImpl) GetSearchProfiles(ctx context.Context, userID uuid.UUID
 			&p.ID, &p.UserID, &p.ProfileName, &p.IsDefault, &p.SearchRadiusKm, &p.PreferredTime,
 			&p.BudgetLevel, &p.PreferredPace, &p.PreferAccessiblePOIs, &p.PreferOutdoorSeating,
 			&p.PreferDogFriendly, &p.PreferredVibes, &p.PreferredTransport, &p.DietaryNeeds,
-			&p.CreatedAt, &p.UpdatedAt,
+			&p.AccessibilityNeeds, &p.CreatedAt, &p.UpdatedAt,
 		)
 		if err != nil {
 			l.ErrorContext(ctx, "Failed to scan preference profile row", slog.Any("error", err))
 			span.RecordError(err)
-			return nil, fmt.Errorf("database error scanning preference profile: %w", err)
+			return nil, nil, fmt.Errorf("database error scanning preference profile: %w", err)
 		}
 		profiles = append(profiles, p)
 	}
@@ -116,12 +130,20 @@ func (r *RepositoryImpl) GetSearchProfiles(ctx context.Context, userID uuid.UUID
 	if err = rows.Err(); err != nil {
 		l.ErrorContext(ctx, "Error iterating preference profile rows", slog.Any("error", err))
 		span.RecordError(err)
-		return nil, fmt.Errorf("database error reading preference profiles: %w", err)
+		return nil, nil, fmt.Errorf("database error reading preference profiles: %w", err)
 	}
 
+	profiles, next := types.NextPage(profiles, page.Limit, func(p types.UserPreferenceProfileResponse) types.Cursor {
+		rank := 1.0
+		if p.IsDefault {
+			rank = 0
+		}
+		return types.Cursor{Num: &rank, Key: p.ProfileName, ID: p.ID}
+	})
+
 	l.DebugContext(ctx, "Fetched user preference profiles successfully", slog.Int("count", len(profiles)))
 	span.SetStatus(codes.Ok, "Preference profiles fetched")
-	return profiles, nil
+	return profiles, next, nil
 }
 
 // GetProfile implements user.UserRepo.
@@ -140,16 +162,23 @@ func (r *RepositoryImpl) GetSearchProfile(ctx context.Context, userID, profileID
         SELECT id, user_id, profile_name, is_default, search_radius_km, preferred_time, 
                budget_level, preferred_pace, prefer_accessible_pois, prefer_outdoor_seating, 
                prefer_dog_friendly, preferred_vibes, preferred_transport, dietary_needs, 
-               created_at, updated_at
+               accessibility_needs, created_at, updated_at,
+               (SELECT d.dining_filters FROM user_dining_preferences d
+                WHERE d.user_preference_profile_id = user_preference_profiles.id) AS dining_filters,
+               (SELECT a.activity_filters FROM user_activity_preferences a
+                WHERE a.user_preference_profile_id = user_preference_profiles.id) AS activity_filters,
+               (SELECT h.accommodation_filters FROM user_accommodation_preferences h
+                WHERE h.user_preference_profile_id = user_preference_profiles.id) AS accommodation_filters
         FROM user_preference_profiles
         WHERE id = $1 AND user_id = $2`
 
 	var p types.UserPreferenceProfileResponse
+	var diningFilters, activityFilters, accommodationFilters []byte
 	err := r.pgpool.QueryRow(ctx, query, profileID, userID).Scan(
 		&p.ID, &p.UserID, &p.ProfileName, &p.IsDefault, &p.SearchRadiusKm, &p.PreferredTime,
 		&p.BudgetLevel, &p.PreferredPace, &p.PreferAccessiblePOIs, &p.PreferOutdoorSeating,
 		&p.PreferDogFriendly, &p.PreferredVibes, &p.PreferredTransport, &p.DietaryNeeds,
-		&p.CreatedAt, &p.UpdatedAt,
+		&p.AccessibilityNeeds, &p.CreatedAt, &p.UpdatedAt, &diningFilters, &activityFilters, &accommodationFilters,
 	)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to query user preference profile", slog.Any("error", err))
@@ -158,6 +187,17 @@ func (r *RepositoryImpl) GetSearchProfile(ctx context.Context, userID, profileID
 		return nil, fmt.Errorf("preference profile not found: %w", types.ErrNotFound)
 	}
 
+	if p.DiningPreferences, err = decodeDiningPreferences(diningFilters); err != nil {
+		// The profile is still usable without its dining filters
+		l.WarnContext(ctx, "Failed to decode dining preferences", slog.Any("error", err))
+	}
+	if p.ActivityPreferences, err = decodeActivityPreferences(activityFilters); err != nil {
+		l.WarnContext(ctx, "Failed to decode activity preferences", slog.Any("error", err))
+	}
+	if p.AccommodationPreferences, err = decodeAccommodationPreferences(accommodationFilters); err != nil {
+		l.WarnContext(ctx, "Failed to decode accommodation preferences", slog.Any("error", err))
+	}
+
 	l.DebugContext(ctx, "Fetched user preference profile successfully")
 	span.SetStatus(codes.Ok, "Preference profile fetched")
 	return &p, nil
@@ -179,16 +219,23 @@ func (r *RepositoryImpl) GetDefaultSearchProfile(ctx context.Context, userID uui
         SELECT id, user_id, profile_name, is_default, search_radius_km, preferred_time, 
                budget_level, preferred_pace, prefer_accessible_pois, prefer_outdoor_seating, 
                prefer_dog_friendly, preferred_vibes, preferred_transport, dietary_needs, 
-               created_at, updated_at
+               accessibility_needs, created_at, updated_at,
+               (SELECT d.dining_filters FROM user_dining_preferences d
+                WHERE d.user_preference_profile_id = user_preference_profiles.id) AS dining_filters,
+               (SELECT a.activity_filters FROM user_activity_preferences a
+                WHERE a.user_preference_profile_id = user_preference_profiles.id) AS activity_filters,
+               (SELECT h.accommodation_filters FROM user_accommodation_preferences h
+                WHERE h.user_preference_profile_id = user_preference_profiles.id) AS accommodation_filters
         FROM user_preference_profiles
         WHERE user_id = $1 AND is_default = TRUE`
 
 	var p types.UserPreferenceProfileResponse
+	var diningFilters, activityFilters, accommodationFilters []byte
 	err := r.pgpool.QueryRow(ctx, query, userID).Scan(
 		&p.ID, &p.UserID, &p.ProfileName, &p.IsDefault, &p.SearchRadiusKm, &p.PreferredTime,
 		&p.BudgetLevel, &p.PreferredPace, &p.PreferAccessiblePOIs, &p.PreferOutdoorSeating,
 		&p.PreferDogFriendly, &p.PreferredVibes, &p.PreferredTransport, &p.DietaryNeeds,
-		&p.CreatedAt, &p.UpdatedAt,
+		&p.AccessibilityNeeds, &p.CreatedAt, &p.UpdatedAt, &diningFilters, &activityFilters, &accommodationFilters,
 	)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to query default user preference profile", slog.Any("error", err))
@@ -197,6 +244,17 @@ func (r *RepositoryImpl) GetDefaultSearchProfile(ctx context.Context, userID uui
 		return nil, fmt.Errorf("default preference profile not found: %w", types.ErrNotFound)
 	}
 
+	if p.DiningPreferences, err = decodeDiningPreferences(diningFilters); err != nil {
+		// The profile is still usable without its dining filters
+		l.WarnContext(ctx, "Failed to decode dining preferences", slog.Any("error", err))
+	}
+	if p.ActivityPreferences, err = decodeActivityPreferences(activityFilters); err != nil {
+		l.WarnContext(ctx, "Failed to decode activity preferences", slog.Any("error", err))
+	}
+	if p.AccommodationPreferences, err = decodeAccommodationPreferences(accommodationFilters); err != nil {
+		l.WarnContext(ctx, "Failed to decode accommodation preferences", slog.Any("error", err))
+	}
+
 	l.DebugContext(ctx, "Fetched default user preference profile successfully")
 	span.SetStatus(codes.Ok, "Default preference profile fetched")
 	return &p, nil
@@ -266,6 +324,10 @@ func (r *RepositoryImpl) CreateSearchProfile(ctx context.Context, userID uuid.UU
 	if dietaryNeeds == nil {
 		dietaryNeeds = []string{}
 	}
+	accessibilityNeeds := types.AccessibilityNeeds{}
+	if params.AccessibilityNeeds != nil {
+		accessibilityNeeds = *params.AccessibilityNeeds
+	}
 
 	if isDefault {
 		query := "UPDATE user_preference_profiles SET is_default = FALSE WHERE user_id = $1 AND id != $2"
@@ -284,22 +346,24 @@ func (r *RepositoryImpl) CreateSearchProfile(ctx context.Context, userID uuid.UU
         INSERT INTO user_preference_profiles (
             user_id, profile_name, is_default, search_radius_km, preferred_time, 
             budget_level, preferred_pace, prefer_accessible_pois, prefer_outdoor_seating, 
-            prefer_dog_friendly, preferred_vibes, preferred_transport, dietary_needs
+            prefer_dog_friendly, preferred_vibes, preferred_transport, dietary_needs,
+            accessibility_needs
         ) VALUES (
-            $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+            $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
         ) RETURNING id, user_id, profile_name, is_default, search_radius_km, preferred_time, 
                    budget_level, preferred_pace, prefer_accessible_pois, prefer_outdoor_seating, 
                    prefer_dog_friendly, preferred_vibes, preferred_transport, dietary_needs, 
-                   created_at, updated_at`
+                   accessibility_needs, created_at, updated_at`
 	err = tx.QueryRow(ctx, query,
 		userID, params.ProfileName, isDefault, searchRadiusKm, preferredTime,
 		budgetLevel, preferredPace, preferAccessiblePOIs, preferOutdoorSeating,
 		preferDogFriendly, preferredVibes, preferredTransport, dietaryNeeds,
+		accessibilityNeeds,
 	).Scan(
 		&p.ID, &p.UserID, &p.ProfileName, &p.IsDefault, &p.SearchRadiusKm, &p.PreferredTime,
 		&p.BudgetLevel, &p.PreferredPace, &p.PreferAccessiblePOIs, &p.PreferOutdoorSeating,
 		&p.PreferDogFriendly, &p.PreferredVibes, &p.PreferredTransport, &p.DietaryNeeds,
-		&p.CreatedAt, &p.UpdatedAt,
+		&p.AccessibilityNeeds, &p.CreatedAt, &p.UpdatedAt,
 	)
 	if err != nil {
 		var pgErr *pgconn.PgError
@@ -380,6 +444,11 @@ func (r *RepositoryImpl) CreateSearchProfile(ctx context.Context, userID uuid.UU
 		}
 	}
 
+	if err := embeddings.RefreshProfilePreferences(ctx, tx, p.ID); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -486,6 +555,12 @@ func (r *RepositoryImpl) UpdateSearchProfile(ctx context.Context, userID, profil
 		paramIdx++
 	}
 
+	if params.AccessibilityNeeds != nil {
+		updates = append(updates, fmt.Sprintf("accessibility_needs = $%d", paramIdx))
+		args = append(args, *params.AccessibilityNeeds)
+		paramIdx++
+	}
+
 	// Update main profile if there are changes
 	if len(updates) > 0 {
 		// Always update the updated_at timestamp
@@ -559,6 +634,11 @@ func (r *RepositoryImpl) UpdateSearchProfile(ctx context.Context, userID, profil
 		}
 	}
 
+	if err := embeddings.RefreshProfilePreferences(ctx, tx, profileID); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
 	// Commit transaction
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
@@ -20,10 +20,16 @@ import (
 // Ensure implementation satisfies the interface
 var _ Service = (*ServiceImpl)(nil)
 
+// Page sizes of a user's search profiles.
+const (
+	defaultSearchProfilePageLimit = 20
+	maxSearchProfilePageLimit     = 100
+)
+
 // profilessService defines the business logic contract for user operations.
 type Service interface {
 	//GetSearchProfiles User  Profiles
-	GetSearchProfiles(ctx context.Context, userID uuid.UUID) ([]types.UserPreferenceProfileResponse, error)
+	GetSearchProfiles(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]types.UserPreferenceProfileResponse, *types.Cursor, error)
 	GetSearchProfile(ctx context.Context, userID, profileID uuid.UUID) (*types.UserPreferenceProfileResponse, error)
 	GetDefaultSearchProfile(ctx context.Context, userID uuid.UUID) (*types.UserPreferenceProfileResponse, error)
 	CreateSearchProfile(ctx context.Context, userID uuid.UUID, params types.CreateUserPreferenceProfileParams) (*types.UserPreferenceProfileResponse, error)
@@ -51,8 +57,8 @@ func NewUserProfilesService(prefRepo Repository, intRepo interests.Repository, t
 	}
 }
 
-// GetSearchProfiles retrieves all preference profiles for a user.
-func (s *ServiceImpl) GetSearchProfiles(ctx context.Context, userID uuid.UUID) ([]types.UserPreferenceProfileResponse, error) {
+// GetSearchProfiles retrieves a page of a user's preference profiles.
+func (s *ServiceImpl) GetSearchProfiles(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]types.UserPreferenceProfileResponse, *types.Cursor, error) {
 	ctx, span := otel.Tracer("UserService").Start(ctx, "GetSearchProfiles", trace.WithAttributes(
 		attribute.String("user.id", userID.String()),
 	))
@@ -61,17 +67,17 @@ func (s *ServiceImpl) GetSearchProfiles(ctx context.Context, userID uuid.UUID) (
 	l := s.logger.With(slog.String("method", "GetSearchProfiles"), slog.String("userID", userID.String()))
 	l.DebugContext(ctx, "Fetching user preference profiles")
 
-	profiles, err := s.prefRepo.GetSearchProfiles(ctx, userID)
+	profiles, next, err := s.prefRepo.GetSearchProfiles(ctx, userID, page.Clamp(defaultSearchProfilePageLimit, maxSearchProfilePageLimit))
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to fetch user preference profiles", slog.Any("error", err))
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "Failed to fetch user preference profiles")
-		return nil, fmt.Errorf("error fetching user preference profiles: %w", err)
+		return nil, nil, fmt.Errorf("error fetching user preference profiles: %w", err)
 	}
 
 	l.InfoContext(ctx, "User preference profiles fetched successfully", slog.Int("count", len(profiles)))
 	span.SetStatus(codes.Ok, "User preference profiles fetched successfully")
-	return profiles, nil
+	return profiles, next, nil
 }
 
 // GetSearchProfile retrieves a specific preference profile by ID.
@@ -133,6 +139,9 @@ func (s *ServiceImpl) CreateSearchProfileCC(ctx context.Context, userID uuid.UUI
 	if params.ProfileName == "" {
 		return nil, fmt.Errorf("%w: profile name cannot be empty", types.ErrBadRequest)
 	}
+	if err := params.AccessibilityNeeds.Validate(); err != nil {
+		return nil, err
+	}
 
 	tx, err := s.prefRepo.(*RepositoryImpl).pgpool.Begin(ctx)
 	if err != nil {
@@ -255,6 +264,7 @@ func (s *ServiceImpl) CreateSearchProfileCC(ctx context.Context, userID uuid.UUI
 		PreferredVibes:       createdProfileCore.PreferredVibes,
 		PreferredTransport:   createdProfileCore.PreferredTransport,
 		DietaryNeeds:         createdProfileCore.DietaryNeeds,
+		AccessibilityNeeds:   createdProfileCore.AccessibilityNeeds,
 		CreatedAt:            createdProfileCore.CreatedAt,
 		UpdatedAt:            createdProfileCore.UpdatedAt,
 		Interests:            fetchedInterests,
@@ -283,6 +293,11 @@ func (s *ServiceImpl) CreateSearchProfile(ctx context.Context, userID uuid.UUID,
 		span.SetStatus(codes.Error, "Profile name is required")
 		return nil, fmt.Errorf("%w: profile name cannot be empty", types.ErrBadRequest)
 	}
+	if err := params.AccessibilityNeeds.Validate(); err != nil {
+		l.WarnContext(ctx, "Invalid accessibility needs", slog.Any("error", err))
+		span.SetStatus(codes.Error, "Invalid accessibility needs")
+		return nil, err
+	}
 
 	// Pre-validate interests
 	var interestObjects []types.Interest
@@ -391,6 +406,7 @@ func (s *ServiceImpl) CreateSearchProfile(ctx context.Context, userID uuid.UUID,
 		PreferredVibes:       profile.PreferredVibes,
 		PreferredTransport:   profile.PreferredTransport,
 		DietaryNeeds:         profile.DietaryNeeds,
+		AccessibilityNeeds:   profile.AccessibilityNeeds,
 		Interests:            fetchedInterests,
 		Tags:                 fetchedTags,
 		CreatedAt:            profile.CreatedAt,
@@ -458,6 +474,12 @@ func (s *ServiceImpl) UpdateSearchProfile(ctx context.Context, userID, profileID
 	l := s.logger.With(slog.String("method", "SetDefaultSearchProfile"), slog.String("profileID", profileID.String()))
 	l.DebugContext(ctx, "Setting profile as default")
 
+	if err := params.AccessibilityNeeds.Validate(); err != nil {
+		l.WarnContext(ctx, "Invalid accessibility needs", slog.Any("error", err))
+		span.SetStatus(codes.Error, "Invalid accessibility needs")
+		return err
+	}
+
 	if err := s.prefRepo.UpdateSearchProfile(ctx, userID, profileID, params); err != nil {
 		l.ErrorContext(ctx, "Failed to set default user preference profile", slog.Any("error", err))
 		span.RecordError(err)
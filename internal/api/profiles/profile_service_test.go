@@ -27,12 +27,13 @@ type MockprofilessRepo struct {
 }
 
 // Implement profilessRepo methods
-func (m *MockprofilessRepo) GetSearchProfiles(ctx context.Context, userID uuid.UUID) ([]types.UserPreferenceProfileResponse, error) {
-	args := m.Called(ctx, userID)
+func (m *MockprofilessRepo) GetSearchProfiles(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]types.UserPreferenceProfileResponse, *types.Cursor, error) {
+	args := m.Called(ctx, userID, page)
 	if args.Get(0) == nil {
-		return nil, args.Error(1)
+		return nil, nil, args.Error(2)
 	}
-	return args.Get(0).([]types.UserPreferenceProfileResponse), args.Error(1)
+	next, _ := args.Get(1).(*types.Cursor)
+	return args.Get(0).([]types.UserPreferenceProfileResponse), next, args.Error(2)
 }
 func (m *MockprofilessRepo) GetSearchProfile(ctx context.Context, userID, profileID uuid.UUID) (*types.UserPreferenceProfileResponse, error) {
 	args := m.Called(ctx, userID, profileID)
@@ -106,6 +107,14 @@ func (m *MockinterestsRepo) GetAllInterests(ctx context.Context) ([]*types.Inter
 	}
 	return args.Get(0).([]*types.Interest), args.Error(1)
 }
+func (m *MockinterestsRepo) ListInterests(ctx context.Context, page types.PageRequest) ([]*types.Interest, *types.Cursor, error) {
+	args := m.Called(ctx, page)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	next, _ := args.Get(1).(*types.Cursor)
+	return args.Get(0).([]*types.Interest), next, args.Error(2)
+}
 func (m *MockinterestsRepo) CreateInterest(ctx context.Context, name string, description *string, isActive bool, userID string) (*types.Interest, error) {
 	args := m.Called(ctx, name, description, isActive, userID)
 	if args.Get(0) == nil {
@@ -153,6 +162,14 @@ func (m *MocktagsRepo) GetAll(ctx context.Context, userID uuid.UUID) ([]*types.T
 	}
 	return args.Get(0).([]*types.Tags), args.Error(1)
 }
+func (m *MocktagsRepo) List(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]*types.Tags, *types.Cursor, error) {
+	args := m.Called(ctx, userID, page)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	next, _ := args.Get(1).(*types.Cursor)
+	return args.Get(0).([]*types.Tags), next, args.Error(2)
+}
 func (m *MocktagsRepo) Create(ctx context.Context, userID uuid.UUID, params types.CreatePersonalTagParams) (*types.PersonalTag, error) {
 	args := m.Called(ctx, userID, params)
 	if args.Get(0) == nil {
@@ -219,25 +236,38 @@ func TestProfilesServiceImpl_GetSearchProfiles(t *testing.T) {
 	service, mockPrefRepo, _, _ := setupprofilessServiceTest()
 	ctx := context.Background()
 	userID := uuid.New()
+	firstPage := types.PageRequest{Limit: defaultSearchProfilePageLimit}
 
 	t.Run("success", func(t *testing.T) {
 		expectedProfiles := []types.UserPreferenceProfileResponse{
 			{ID: uuid.New(), UserID: userID, ProfileName: "Profile 1"},
 			{ID: uuid.New(), UserID: userID, ProfileName: "Profile 2"},
 		}
-		mockPrefRepo.On("GetSearchProfiles", ctx, userID).Return(expectedProfiles, nil).Once()
+		rank := 1.0
+		next := &types.Cursor{Num: &rank, Key: "Profile 2", ID: expectedProfiles[1].ID}
+		mockPrefRepo.On("GetSearchProfiles", mock.Anything, userID, firstPage).Return(expectedProfiles, next, nil).Once()
 
-		profiles, err := service.GetSearchProfiles(ctx, userID)
+		profiles, gotNext, err := service.GetSearchProfiles(ctx, userID, types.PageRequest{})
 		require.NoError(t, err)
 		assert.Equal(t, expectedProfiles, profiles)
+		assert.Equal(t, next, gotNext)
+		mockPrefRepo.AssertExpectations(t)
+	})
+
+	t.Run("limit is capped", func(t *testing.T) {
+		mockPrefRepo.On("GetSearchProfiles", mock.Anything, userID, types.PageRequest{Limit: maxSearchProfilePageLimit}).
+			Return([]types.UserPreferenceProfileResponse{}, nil, nil).Once()
+
+		_, _, err := service.GetSearchProfiles(ctx, userID, types.PageRequest{Limit: 1_000})
+		require.NoError(t, err)
 		mockPrefRepo.AssertExpectations(t)
 	})
 
 	t.Run("repository error", func(t *testing.T) {
 		repoErr := errors.New("db error fetching profiles")
-		mockPrefRepo.On("GetSearchProfiles", ctx, userID).Return(nil, repoErr).Once()
+		mockPrefRepo.On("GetSearchProfiles", mock.Anything, userID, firstPage).Return(nil, nil, repoErr).Once()
 
-		_, err := service.GetSearchProfiles(ctx, userID)
+		_, _, err := service.GetSearchProfiles(ctx, userID, types.PageRequest{})
 		require.Error(t, err)
 		assert.True(t, errors.Is(err, repoErr))
 		assert.Contains(t, err.Error(), "error fetching user preference profiles:")
@@ -38,6 +38,19 @@ func (r *RepositoryImpl) updateAccommodationPreferencesInTx(ctx context.Context,
 	return err
 }
 
+// decodeAccommodationPreferences reads the accommodation filters stored by
+// updateAccommodationPreferencesInTx, or returns nil when a profile has none.
+func decodeAccommodationPreferences(filters []byte) (*types.AccommodationPreferences, error) {
+	if len(filters) == 0 {
+		return nil, nil
+	}
+	var prefs types.AccommodationPreferences
+	if err := json.Unmarshal(filters, &prefs); err != nil {
+		return nil, err
+	}
+	return &prefs, nil
+}
+
 func (r *RepositoryImpl) updateDiningPreferencesInTx(ctx context.Context, tx pgx.Tx, profileID uuid.UUID, prefs *types.DiningPreferences) error {
 	// Convert preferences to JSONB
 	filters := map[string]interface{}{
@@ -67,6 +80,32 @@ func (r *RepositoryImpl) updateDiningPreferencesInTx(ctx context.Context, tx pgx
 	return err
 }
 
+// decodeDiningPreferences reads the dining filters stored by
+// updateDiningPreferencesInTx, or returns nil when a profile has none.
+func decodeDiningPreferences(filters []byte) (*types.DiningPreferences, error) {
+	if len(filters) == 0 {
+		return nil, nil
+	}
+	var prefs types.DiningPreferences
+	if err := json.Unmarshal(filters, &prefs); err != nil {
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+// decodeActivityPreferences reads the activity filters stored by
+// updateActivityPreferencesInTx, or returns nil when a profile has none.
+func decodeActivityPreferences(filters []byte) (*types.ActivityPreferences, error) {
+	if len(filters) == 0 {
+		return nil, nil
+	}
+	var prefs types.ActivityPreferences
+	if err := json.Unmarshal(filters, &prefs); err != nil {
+		return nil, err
+	}
+	return &prefs, nil
+}
+
 func (r *RepositoryImpl) updateActivityPreferencesInTx(ctx context.Context, tx pgx.Tx, profileID uuid.UUID, prefs *types.ActivityPreferences) error {
 	// Convert preferences to JSONB
 	filters := map[string]interface{}{
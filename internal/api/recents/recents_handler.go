@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
-	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -12,6 +11,7 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/auth"
 )
 
@@ -40,6 +40,7 @@ func NewHandler(service Service, logger *slog.Logger) *HandlerImpl {
 // @Tags recents
 // @Accept json
 // @Produce json
+// @Param cursor query string false "Cursor of the page to fetch, from next_cursor or the Link header; omit for the first page"
 // @Param limit query int false "Limit number of cities (default: 10, max: 50)"
 // @Success 200 {object} types.RecentInteractionsResponse
 // @Failure 400 {object} map[string]string
@@ -72,33 +73,25 @@ func (h *HandlerImpl) GetUserRecentInteractions(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	// Parse limit parameter
-	limit := 10 // default
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if parsedLimit, err := strconv.Atoi(limitStr); err == nil {
-			limit = parsedLimit
-		}
-	}
-
-	// Validate limit
-	if limit <= 0 {
-		limit = 10
-	}
-	if limit > 50 {
-		limit = 50
+	// Parse cursor and limit parameters
+	page, err := api.ParsePageRequest(r)
+	if err != nil {
+		span.SetStatus(codes.Error, "Invalid cursor")
+		http.Error(w, "Invalid cursor", http.StatusBadRequest)
+		return
 	}
 
 	l.InfoContext(ctx, "Processing get recent interactions request", 
 		slog.String("user_id", userID.String()),
-		slog.Int("limit", limit))
+		slog.Int("limit", page.Limit))
 
 	span.SetAttributes(
 		attribute.String("user_id", userID.String()),
-		attribute.Int("limit", limit),
+		attribute.Int("limit", page.Limit),
 	)
 
 	// Call service to get recent interactions
-	response, err := h.service.GetUserRecentInteractions(ctx, userID, limit)
+	response, err := h.service.GetUserRecentInteractions(ctx, userID, page)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to get recent interactions", slog.Any("error", err))
 		span.RecordError(err)
@@ -108,6 +101,7 @@ func (h *HandlerImpl) GetUserRecentInteractions(w http.ResponseWriter, r *http.R
 	}
 
 	// Set response headers
+	api.SetPageLinks(w, r, response.NextCursor)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
@@ -19,7 +19,9 @@ import (
 var _ Repository = (*RepositoryImpl)(nil)
 
 type Repository interface {
-	GetUserRecentInteractions(ctx context.Context, userID uuid.UUID, limit int) (*types.RecentInteractionsResponse, error)
+	// GetUserRecentInteractions returns a page of the cities the user asked
+	// about, most recently active first.
+	GetUserRecentInteractions(ctx context.Context, userID uuid.UUID, page types.PageRequest) (*types.RecentInteractionsResponse, error)
 	GetCityPOIsByInteraction(ctx context.Context, userID uuid.UUID, cityName string) ([]types.POIDetailedInfo, error)
 	GetCityHotelsByInteraction(ctx context.Context, userID uuid.UUID, cityName string) ([]types.HotelDetailedInfo, error)
 	GetCityRestaurantsByInteraction(ctx context.Context, userID uuid.UUID, cityName string) ([]types.RestaurantDetailedInfo, error)
@@ -38,16 +40,22 @@ func NewRepository(pgpool *pgxpool.Pool, logger *slog.Logger) *RepositoryImpl {
 }
 
 // GetUserRecentInteractions fetches recent interactions grouped by city
-func (r *RepositoryImpl) GetUserRecentInteractions(ctx context.Context, userID uuid.UUID, limit int) (*types.RecentInteractionsResponse, error) {
+func (r *RepositoryImpl) GetUserRecentInteractions(ctx context.Context, userID uuid.UUID, page types.PageRequest) (*types.RecentInteractionsResponse, error) {
 	ctx, span := otel.Tracer("RecentsRepository").Start(ctx, "GetUserRecentInteractions", trace.WithAttributes(
 		attribute.String("user_id", userID.String()),
-		attribute.Int("limit", limit),
+		attribute.Int("limit", page.Limit),
 	))
 	defer span.End()
 
 	l := r.logger.With(slog.String("method", "GetUserRecentInteractions"))
 
-	query := `
+	args := []interface{}{userID, page.FetchLimit()}
+	after := ""
+	if page.Cursor != nil {
+		args = append(args, page.Cursor.Time, page.Cursor.Key)
+		after = "HAVING (MAX(created_at), city_name) < ($3, $4)"
+	}
+	query := fmt.Sprintf(`
 		SELECT DISTINCT 
 			city_name,
 			MAX(created_at) as last_activity,
@@ -57,11 +65,12 @@ func (r *RepositoryImpl) GetUserRecentInteractions(ctx context.Context, userID u
 			AND city_name != '' 
 			AND city_name IS NOT NULL
 		GROUP BY city_name 
-		ORDER BY last_activity DESC 
+		%s
+		ORDER BY last_activity DESC, city_name DESC
 		LIMIT $2
-	`
+	`, after)
 
-	rows, err := r.pgpool.Query(ctx, query, userID, limit)
+	rows, err := r.pgpool.Query(ctx, query, args...)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to query recent interactions", slog.Any("error", err))
 		span.RecordError(err)
@@ -71,6 +80,8 @@ func (r *RepositoryImpl) GetUserRecentInteractions(ctx context.Context, userID u
 	defer rows.Close()
 
 	var cities []types.CityInteractions
+	var last, next *types.Cursor
+	scanned := 0
 	for rows.Next() {
 		var cityName string
 		var lastActivity time.Time
@@ -81,6 +92,13 @@ func (r *RepositoryImpl) GetUserRecentInteractions(ctx context.Context, userID u
 			l.ErrorContext(ctx, "Failed to scan city row", slog.Any("error", err))
 			continue
 		}
+		if scanned == page.Limit {
+			// The look-ahead row: another page follows the last city kept
+			next = last
+			break
+		}
+		scanned++
+		last = &types.Cursor{Time: &lastActivity, Key: cityName}
 
 		// Get detailed interactions for this city
 		interactions, err := r.getCityInteractions(ctx, userID, cityName)
@@ -123,8 +141,9 @@ func (r *RepositoryImpl) GetUserRecentInteractions(ctx context.Context, userID u
 	span.SetStatus(codes.Ok, "Recent interactions retrieved")
 
 	return &types.RecentInteractionsResponse{
-		Cities: cities,
-		Total:  total,
+		Cities:     cities,
+		Total:      total,
+		NextCursor: next,
 	}, nil
 }
 
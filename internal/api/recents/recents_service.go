@@ -18,7 +18,7 @@ import (
 var _ Service = (*ServiceImpl)(nil)
 
 type Service interface {
-	GetUserRecentInteractions(ctx context.Context, userID uuid.UUID, limit int) (*types.RecentInteractionsResponse, error)
+	GetUserRecentInteractions(ctx context.Context, userID uuid.UUID, page types.PageRequest) (*types.RecentInteractionsResponse, error)
 	GetCityDetailsForUser(ctx context.Context, userID uuid.UUID, cityName string) (*types.CityInteractions, error)
 }
 
@@ -35,29 +35,24 @@ func NewService(repo Repository, logger *slog.Logger) *ServiceImpl {
 }
 
 // GetUserRecentInteractions retrieves recent interactions for a user
-func (s *ServiceImpl) GetUserRecentInteractions(ctx context.Context, userID uuid.UUID, limit int) (*types.RecentInteractionsResponse, error) {
+func (s *ServiceImpl) GetUserRecentInteractions(ctx context.Context, userID uuid.UUID, page types.PageRequest) (*types.RecentInteractionsResponse, error) {
 	ctx, span := otel.Tracer("RecentsService").Start(ctx, "GetUserRecentInteractions", trace.WithAttributes(
 		attribute.String("user_id", userID.String()),
-		attribute.Int("limit", limit),
+		attribute.Int("limit", page.Limit),
 	))
 	defer span.End()
 
 	l := s.logger.With(slog.String("method", "GetUserRecentInteractions"))
 
 	// Validate limit
-	if limit <= 0 {
-		limit = 10
-	}
-	if limit > 50 {
-		limit = 50
-	}
+	page = page.Clamp(10, 50)
 
 	l.InfoContext(ctx, "Getting user recent interactions",
 		slog.String("user_id", userID.String()),
-		slog.Int("limit", limit))
+		slog.Int("limit", page.Limit))
 
 	// Get recent interactions from repository
-	response, err := s.repo.GetUserRecentInteractions(ctx, userID, limit)
+	response, err := s.repo.GetUserRecentInteractions(ctx, userID, page)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to get recent interactions", slog.Any("error", err))
 		span.RecordError(err)
@@ -98,7 +93,7 @@ func (s *ServiceImpl) GetCityDetailsForUser(ctx context.Context, userID uuid.UUI
 		slog.String("city_name", cityName))
 
 	// Get recent interactions to find the city data
-	recentResponse, err := s.repo.GetUserRecentInteractions(ctx, userID, 50) // Get more to find the city
+	recentResponse, err := s.repo.GetUserRecentInteractions(ctx, userID, types.PageRequest{Limit: 50}) // Get more to find the city
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to get recent interactions", slog.Any("error", err))
 		span.RecordError(err)
@@ -0,0 +1,254 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/auth"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Handler = (*HandlerImpl)(nil)
+
+type Handler interface {
+	GetSubscription(w http.ResponseWriter, r *http.Request)
+	StartTrial(w http.ResponseWriter, r *http.Request)
+	ChangePlan(w http.ResponseWriter, r *http.Request)
+	PaymentWebhook(w http.ResponseWriter, r *http.Request)
+}
+
+// maxWebhookBytes caps payment webhook bodies; provider events are small.
+const maxWebhookBytes = 64 << 10
+
+// TokenIssuer signs a fresh access token so plan changes show up in the
+// `pln`/`sts` claims straight away. Implemented by auth.AuthServiceImpl.
+type TokenIssuer interface {
+	IssueAccessToken(ctx context.Context, userID string) (string, error)
+}
+
+type HandlerImpl struct {
+	logger  *slog.Logger
+	service Service
+	tokens  TokenIssuer
+}
+
+func NewHandler(service Service, tokens TokenIssuer, logger *slog.Logger) *HandlerImpl {
+	return &HandlerImpl{
+		logger:  logger,
+		service: service,
+		tokens:  tokens,
+	}
+}
+
+// userFromContext resolves the authenticated user. On failure it writes the
+// error response and returns ok=false.
+func (h *HandlerImpl) userFromContext(w http.ResponseWriter, r *http.Request, span trace.Span, l *slog.Logger) (uuid.UUID, bool) {
+	ctx := r.Context()
+	userIDStr, found := auth.GetUserIDFromContext(ctx)
+	if !found || userIDStr == "" {
+		l.ErrorContext(ctx, "User ID not found in context")
+		span.SetStatus(codes.Error, "Unauthorized - User ID missing")
+		api.ErrorResponse(w, r, http.StatusUnauthorized, "Authentication required")
+		return uuid.Nil, false
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		l.ErrorContext(ctx, "Invalid user ID format", slog.String("userID_str", userIDStr), slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Invalid User ID format")
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid user ID format")
+		return uuid.Nil, false
+	}
+	span.SetAttributes(attribute.String("user.id", userID.String()))
+	return userID, true
+}
+
+// withFreshToken wraps the subscription with an access token carrying the new
+// plan claims. A signing failure is logged; the client can still refresh normally.
+func (h *HandlerImpl) withFreshToken(ctx context.Context, l *slog.Logger, userID uuid.UUID, sub *types.Subscription) types.SubscriptionResponse {
+	resp := types.SubscriptionResponse{Subscription: sub}
+	token, err := h.tokens.IssueAccessToken(ctx, userID.String())
+	if err != nil {
+		l.WarnContext(ctx, "Failed to issue access token after plan change", slog.Any("error", err))
+		return resp
+	}
+	resp.AccessToken = token
+	return resp
+}
+
+// GetSubscription godoc
+// @Summary      Get Subscription
+// @Description  Returns the authenticated user's subscription.
+// @Tags         Subscription
+// @Produce      json
+// @Success      200 {object} types.Subscription
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /user/subscription [get]
+func (h *HandlerImpl) GetSubscription(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("SubscriptionHandler").Start(r.Context(), "GetSubscription")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "GetSubscription"))
+
+	userID, ok := h.userFromContext(w, r, span, l)
+	if !ok {
+		return
+	}
+
+	sub, err := h.service.GetSubscription(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to get subscription")
+		api.WriteServiceError(w, r, err, "Failed to get subscription")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Subscription retrieved")
+	api.WriteJSONResponse(w, r, http.StatusOK, sub)
+}
+
+// StartTrial godoc
+// @Summary      Start Premium Trial
+// @Description  Starts a one-off trial of a premium plan. Returns a new access token carrying the trial plan.
+// @Tags         Subscription
+// @Accept       json
+// @Produce      json
+// @Param        trial body types.StartTrialRequest true "Plan to trial"
+// @Success      200 {object} types.SubscriptionResponse
+// @Failure      400 {object} types.Response "Invalid plan"
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      409 {object} types.Response "Trial already used or a paid plan is active"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /user/subscription/trial [post]
+func (h *HandlerImpl) StartTrial(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("SubscriptionHandler").Start(r.Context(), "StartTrial")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "StartTrial"))
+
+	userID, ok := h.userFromContext(w, r, span, l)
+	if !ok {
+		return
+	}
+
+	var req types.StartTrialRequest
+	if err := api.DecodeJSONBody(w, r, &req); err != nil {
+		l.ErrorContext(ctx, "Failed to decode request", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Bad request")
+		return
+	}
+
+	sub, err := h.service.StartTrial(ctx, userID, req.Plan)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to start trial")
+		api.WriteServiceError(w, r, err, "Failed to start trial")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Trial started")
+	api.WriteJSONResponse(w, r, http.StatusOK, h.withFreshToken(ctx, l, userID, sub))
+}
+
+// ChangePlan godoc
+// @Summary      Change Plan
+// @Description  Downgrades the user to the free plan, cancelling a paid plan or trial immediately. Paid plans are activated by the payment provider or an admin, never here. Returns a new access token carrying the new plan.
+// @Tags         Subscription
+// @Accept       json
+// @Produce      json
+// @Param        plan body types.ChangePlanRequest true "New plan"
+// @Success      200 {object} types.SubscriptionResponse
+// @Failure      400 {object} types.Response "Invalid plan"
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      403 {object} types.Response "Upgrades are not self-service"
+// @Failure      409 {object} types.Response "Already on this plan"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /user/subscription [put]
+func (h *HandlerImpl) ChangePlan(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("SubscriptionHandler").Start(r.Context(), "ChangePlan")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "ChangePlan"))
+
+	userID, ok := h.userFromContext(w, r, span, l)
+	if !ok {
+		return
+	}
+
+	var req types.ChangePlanRequest
+	if err := api.DecodeJSONBody(w, r, &req); err != nil {
+		l.ErrorContext(ctx, "Failed to decode request", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Bad request")
+		return
+	}
+	span.SetAttributes(attribute.String("plan", req.Plan))
+
+	sub, err := h.service.ChangePlan(ctx, userID, req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to change plan")
+		api.WriteServiceError(w, r, err, "Failed to change plan")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Plan changed")
+	api.WriteJSONResponse(w, r, http.StatusOK, h.withFreshToken(ctx, l, userID, sub))
+}
+
+// PaymentWebhook godoc
+// @Summary      Payment Provider Webhook
+// @Description  Receives subscription events from the payment provider. The X-Webhook-Signature header is the hex HMAC-SHA256 of "<X-Webhook-Timestamp>.<body>" with the shared webhook secret. subscription.activated puts the user on the paid plan until period_end.
+// @Tags         Subscription
+// @Accept       json
+// @Produce      json
+// @Param        X-Webhook-Timestamp header string true "Unix timestamp, in seconds"
+// @Param        X-Webhook-Signature header string true "Hex HMAC-SHA256 signature"
+// @Param        event body types.PaymentEvent true "Payment event"
+// @Success      200 {object} types.Response
+// @Failure      400 {object} types.Response "Invalid event"
+// @Failure      401 {object} types.Response "Invalid or stale signature"
+// @Failure      403 {object} types.Response "Webhooks not configured"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Router       /subscriptions/webhook [post]
+func (h *HandlerImpl) PaymentWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("SubscriptionHandler").Start(r.Context(), "PaymentWebhook")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "PaymentWebhook"))
+
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBytes))
+	if err != nil {
+		l.WarnContext(ctx, "Failed to read webhook body", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Bad request")
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	err = h.service.HandlePaymentWebhook(ctx, payload, r.Header.Get("X-Webhook-Timestamp"), r.Header.Get("X-Webhook-Signature"))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to handle webhook")
+		if errors.Is(err, types.ErrUnauthenticated) {
+			api.ErrorResponse(w, r, http.StatusUnauthorized, "Invalid webhook signature")
+			return
+		}
+		api.WriteServiceError(w, r, err, "Failed to handle webhook")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Webhook handled")
+	api.WriteJSONResponse(w, r, http.StatusOK, types.Response{Success: true, Message: "Event received"})
+}
@@ -0,0 +1,281 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Repository = (*RepositoryImpl)(nil)
+
+// Repository defines persistence for the subscription lifecycle. It also satisfies
+// types.SubscriptionRepository, which the auth service uses for plan claims.
+type Repository interface {
+	types.SubscriptionRepository
+	// StartTrial moves a free (or expired) subscription that has never trialled
+	// onto plan until trialEnd. Returns ErrConflict if no trial is available.
+	StartTrial(ctx context.Context, userID uuid.UUID, plan string, trialEnd time.Time) (*types.Subscription, error)
+	// ChangePlan sets the plan as active from now, with no end date, and unlinks the
+	// subscription from the payment provider.
+	ChangePlan(ctx context.Context, userID uuid.UUID, req types.ChangePlanRequest) (*types.Subscription, error)
+	// ActivatePlan puts the user on a paid plan confirmed by the payment provider,
+	// active until periodEnd. Returns ErrConflict if the subscription is already
+	// linked to externalID with a period ending no earlier, so replayed and
+	// out-of-order events cannot shorten it.
+	ActivatePlan(ctx context.Context, userID uuid.UUID, plan, provider, externalID string, periodEnd time.Time) (*types.Subscription, error)
+	// ExpireDue marks paid subscriptions past their end date as expired and returns them.
+	ExpireDue(ctx context.Context) ([]types.Subscription, error)
+}
+
+type RepositoryImpl struct {
+	logger *slog.Logger
+	pgpool *pgxpool.Pool
+}
+
+func NewRepository(pgxpool *pgxpool.Pool, logger *slog.Logger) *RepositoryImpl {
+	return &RepositoryImpl{
+		logger: logger,
+		pgpool: pgxpool,
+	}
+}
+
+const subscriptionColumns = `user_id, plan::text, status::text, start_date, end_date, trial_end_date,
+		       external_provider, external_subscription_id, updated_at`
+
+func scanSubscription(row pgx.Row) (*types.Subscription, error) {
+	var sub types.Subscription
+	err := row.Scan(&sub.UserID, &sub.Plan, &sub.Status, &sub.StartDate, &sub.EndDate, &sub.TrialEndDate,
+		&sub.ExternalProvider, &sub.ExternalSubscriptionID, &sub.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// GetCurrentSubscriptionByUserID implements types.SubscriptionRepository.
+func (r *RepositoryImpl) GetCurrentSubscriptionByUserID(ctx context.Context, userID string) (*types.Subscription, error) {
+	ctx, span := otel.Tracer("SubscriptionRepo").Start(ctx, "GetCurrentSubscriptionByUserID", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.sql.table", "subscriptions"),
+		attribute.String("db.user.id", userID),
+	))
+	defer span.End()
+
+	query := `SELECT ` + subscriptionColumns + ` FROM subscriptions WHERE user_id = $1`
+	sub, err := scanSubscription(r.pgpool.QueryRow(ctx, query, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			span.SetStatus(codes.Error, "Subscription not found")
+			return nil, fmt.Errorf("subscription not found: %w", types.ErrNotFound)
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, fmt.Errorf("database error fetching subscription: %w", err)
+	}
+	span.SetStatus(codes.Ok, "Subscription fetched")
+	return sub, nil
+}
+
+// CreateDefaultSubscription implements types.SubscriptionRepository. Existing
+// subscriptions are left untouched.
+func (r *RepositoryImpl) CreateDefaultSubscription(ctx context.Context, userID string) error {
+	query := `
+		INSERT INTO subscriptions (user_id, plan, status)
+		VALUES ($1, 'free', 'active')
+		ON CONFLICT (user_id) DO NOTHING`
+	if _, err := r.pgpool.Exec(ctx, query, userID); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" { // foreign_key_violation
+			return fmt.Errorf("user not found: %w", types.ErrNotFound)
+		}
+		return fmt.Errorf("database error creating default subscription: %w", err)
+	}
+	return nil
+}
+
+// StartTrial implements Repository. Users without a subscription row get one.
+func (r *RepositoryImpl) StartTrial(ctx context.Context, userID uuid.UUID, plan string, trialEnd time.Time) (*types.Subscription, error) {
+	ctx, span := otel.Tracer("SubscriptionRepo").Start(ctx, "StartTrial", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.operation", "UPSERT"),
+		attribute.String("db.sql.table", "subscriptions"),
+		attribute.String("db.user.id", userID.String()),
+	))
+	defer span.End()
+
+	query := `
+		INSERT INTO subscriptions (user_id, plan, status, start_date, end_date, trial_end_date)
+		VALUES ($1, $2::subscription_plan_type, 'trialing', NOW(), $3, $3)
+		ON CONFLICT (user_id) DO UPDATE SET
+			plan = EXCLUDED.plan,
+			status = EXCLUDED.status,
+			start_date = EXCLUDED.start_date,
+			end_date = EXCLUDED.end_date,
+			trial_end_date = EXCLUDED.trial_end_date
+		WHERE subscriptions.trial_end_date IS NULL
+		  AND (subscriptions.plan = 'free' OR subscriptions.status = 'expired')
+		RETURNING ` + subscriptionColumns
+	sub, err := scanSubscription(r.pgpool.QueryRow(ctx, query, userID, plan, trialEnd))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// The conflict guard rejected the update
+			span.SetStatus(codes.Error, "Trial not available")
+			return nil, fmt.Errorf("trial already used or a paid plan is active: %w", types.ErrConflict)
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return nil, fmt.Errorf("user not found: %w", types.ErrNotFound)
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB upsert failed")
+		return nil, fmt.Errorf("database error starting trial: %w", err)
+	}
+	span.SetStatus(codes.Ok, "Trial started")
+	return sub, nil
+}
+
+// ChangePlan implements Repository.
+func (r *RepositoryImpl) ChangePlan(ctx context.Context, userID uuid.UUID, req types.ChangePlanRequest) (*types.Subscription, error) {
+	ctx, span := otel.Tracer("SubscriptionRepo").Start(ctx, "ChangePlan", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.operation", "UPSERT"),
+		attribute.String("db.sql.table", "subscriptions"),
+		attribute.String("db.user.id", userID.String()),
+		attribute.String("plan", req.Plan),
+	))
+	defer span.End()
+
+	query := `
+		INSERT INTO subscriptions (user_id, plan, status, start_date, end_date, external_provider, external_subscription_id)
+		VALUES ($1, $2::subscription_plan_type, 'active', NOW(), NULL, NULL, NULL)
+		ON CONFLICT (user_id) DO UPDATE SET
+			plan = EXCLUDED.plan,
+			status = EXCLUDED.status,
+			start_date = EXCLUDED.start_date,
+			end_date = EXCLUDED.end_date,
+			external_provider = EXCLUDED.external_provider,
+			external_subscription_id = EXCLUDED.external_subscription_id,
+			override_reason = NULL
+		RETURNING ` + subscriptionColumns
+	sub, err := scanSubscription(r.pgpool.QueryRow(ctx, query, userID, req.Plan))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == "23503" { // foreign_key_violation
+				return nil, fmt.Errorf("user not found: %w", types.ErrNotFound)
+			}
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB upsert failed")
+		return nil, fmt.Errorf("database error changing plan: %w", err)
+	}
+	span.SetStatus(codes.Ok, "Plan changed")
+	return sub, nil
+}
+
+// ActivatePlan implements Repository.
+func (r *RepositoryImpl) ActivatePlan(ctx context.Context, userID uuid.UUID, plan, provider, externalID string, periodEnd time.Time) (*types.Subscription, error) {
+	ctx, span := otel.Tracer("SubscriptionRepo").Start(ctx, "ActivatePlan", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.operation", "UPSERT"),
+		attribute.String("db.sql.table", "subscriptions"),
+		attribute.String("db.user.id", userID.String()),
+		attribute.String("plan", plan),
+	))
+	defer span.End()
+
+	query := `
+		INSERT INTO subscriptions (user_id, plan, status, start_date, end_date, external_provider, external_subscription_id)
+		VALUES ($1, $2::subscription_plan_type, 'active', NOW(), $5, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE SET
+			plan = EXCLUDED.plan,
+			status = EXCLUDED.status,
+			start_date = CASE
+				WHEN subscriptions.external_subscription_id IS NOT DISTINCT FROM EXCLUDED.external_subscription_id
+				 AND subscriptions.status = 'active'
+				THEN subscriptions.start_date
+				ELSE EXCLUDED.start_date
+			END,
+			end_date = EXCLUDED.end_date,
+			external_provider = EXCLUDED.external_provider,
+			external_subscription_id = EXCLUDED.external_subscription_id,
+			override_reason = NULL
+		WHERE NOT (subscriptions.external_subscription_id IS NOT DISTINCT FROM EXCLUDED.external_subscription_id
+		           AND subscriptions.plan = EXCLUDED.plan
+		           AND subscriptions.end_date >= EXCLUDED.end_date)
+		RETURNING ` + subscriptionColumns
+	sub, err := scanSubscription(r.pgpool.QueryRow(ctx, query, userID, plan, provider, externalID, periodEnd))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// The conflict guard rejected a replayed or stale event
+			span.SetStatus(codes.Error, "Period already recorded")
+			return nil, fmt.Errorf("billing period already recorded: %w", types.ErrConflict)
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return nil, fmt.Errorf("user not found: %w", types.ErrNotFound)
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB upsert failed")
+		return nil, fmt.Errorf("database error activating plan: %w", err)
+	}
+	span.SetStatus(codes.Ok, "Plan activated")
+	return sub, nil
+}
+
+// ExpireDue implements Repository.
+func (r *RepositoryImpl) ExpireDue(ctx context.Context) ([]types.Subscription, error) {
+	ctx, span := otel.Tracer("SubscriptionRepo").Start(ctx, "ExpireDue", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.operation", "UPDATE"),
+		attribute.String("db.sql.table", "subscriptions"),
+	))
+	defer span.End()
+
+	query := `
+		UPDATE subscriptions SET status = 'expired'
+		WHERE plan <> 'free'
+		  AND status IN ('active', 'trialing', 'past_due', 'canceled')
+		  AND end_date IS NOT NULL AND end_date <= NOW()
+		RETURNING ` + subscriptionColumns
+	rows, err := r.pgpool.Query(ctx, query)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB update failed")
+		return nil, fmt.Errorf("database error expiring subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var expired []types.Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan subscription row: %w", err)
+		}
+		expired = append(expired, *sub)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("error iterating subscription rows: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("results.count", len(expired)))
+	span.SetStatus(codes.Ok, "Subscriptions expired")
+	return expired, nil
+}
@@ -0,0 +1,345 @@
+package subscription
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/config"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/audit"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Service = (*ServiceImpl)(nil)
+
+const (
+	defaultTrialPeriod      = 14 * 24 * time.Hour
+	defaultExpiryInterval   = 5 * time.Minute
+	defaultWebhookTolerance = 5 * time.Minute
+)
+
+// Service defines the business logic for the subscription lifecycle.
+type Service interface {
+	// GetSubscription returns the user's subscription, creating the free plan if missing.
+	GetSubscription(ctx context.Context, userID uuid.UUID) (*types.Subscription, error)
+	// StartTrial starts a one-off trial of a premium plan.
+	StartTrial(ctx context.Context, userID uuid.UUID, plan string) (*types.Subscription, error)
+	// ChangePlan downgrades the user to the free plan, cancelling a paid plan or
+	// trial immediately. Upgrades are refused with ErrForbidden; paid plans come
+	// from ActivatePlan.
+	ChangePlan(ctx context.Context, userID uuid.UUID, req types.ChangePlanRequest) (*types.Subscription, error)
+	// ActivatePlan puts the user on a paid plan until periodEnd once the payment
+	// provider has confirmed the payment. It must never be reachable from client input.
+	ActivatePlan(ctx context.Context, userID uuid.UUID, plan, provider, externalID string, periodEnd time.Time) (*types.Subscription, error)
+	// HandlePaymentWebhook verifies a payment provider webhook signed with the
+	// configured secret and applies it. Replayed events are acknowledged without effect.
+	HandlePaymentWebhook(ctx context.Context, payload []byte, timestamp, signature string) error
+	// Run expires subscriptions past their end date until ctx is cancelled.
+	Run(ctx context.Context)
+}
+
+type ServiceImpl struct {
+	logger *slog.Logger
+	repo   Repository
+	audit  audit.Recorder
+	cfg    config.SubscriptionConfig
+	now    func() time.Time
+}
+
+// NewService creates a new subscription service. Zero config values fall back to defaults.
+func NewService(repo Repository, auditor audit.Recorder, cfg config.SubscriptionConfig, logger *slog.Logger) *ServiceImpl {
+	if cfg.TrialPeriod <= 0 {
+		cfg.TrialPeriod = defaultTrialPeriod
+	}
+	if cfg.ExpiryInterval <= 0 {
+		cfg.ExpiryInterval = defaultExpiryInterval
+	}
+	if cfg.WebhookTolerance <= 0 {
+		cfg.WebhookTolerance = defaultWebhookTolerance
+	}
+	return &ServiceImpl{
+		logger: logger,
+		repo:   repo,
+		audit:  auditor,
+		cfg:    cfg,
+		now:    time.Now,
+	}
+}
+
+func isPremium(plan string) bool {
+	return plan == types.PlanPremiumMonthly || plan == types.PlanPremiumAnnual
+}
+
+// GetSubscription implements Service.
+func (s *ServiceImpl) GetSubscription(ctx context.Context, userID uuid.UUID) (*types.Subscription, error) {
+	l := s.logger.With(slog.String("method", "GetSubscription"), slog.String("userID", userID.String()))
+
+	sub, err := s.repo.GetCurrentSubscriptionByUserID(ctx, userID.String())
+	if errors.Is(err, types.ErrNotFound) {
+		// Accounts created before subscriptions were tracked have no row yet
+		if err := s.repo.CreateDefaultSubscription(ctx, userID.String()); err != nil {
+			l.ErrorContext(ctx, "Failed to create default subscription", slog.Any("error", err))
+			return nil, fmt.Errorf("failed to create default subscription: %w", err)
+		}
+		sub, err = s.repo.GetCurrentSubscriptionByUserID(ctx, userID.String())
+	}
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to get subscription", slog.Any("error", err))
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// StartTrial implements Service.
+func (s *ServiceImpl) StartTrial(ctx context.Context, userID uuid.UUID, plan string) (*types.Subscription, error) {
+	ctx, span := otel.Tracer("SubscriptionService").Start(ctx, "StartTrial", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+		attribute.String("plan", plan),
+	))
+	defer span.End()
+	l := s.logger.With(slog.String("method", "StartTrial"), slog.String("userID", userID.String()))
+
+	if !isPremium(plan) {
+		span.SetStatus(codes.Error, "Invalid plan")
+		return nil, fmt.Errorf("trials are only available for premium plans: %w", types.ErrBadRequest)
+	}
+
+	trialEnd := s.now().Add(s.cfg.TrialPeriod)
+	sub, err := s.repo.StartTrial(ctx, userID, plan, trialEnd)
+	if err != nil {
+		l.WarnContext(ctx, "Failed to start trial", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to start trial")
+		return nil, fmt.Errorf("failed to start trial: %w", err)
+	}
+
+	s.audit.Record(ctx, types.AuditEntry{
+		ActorID:    &userID,
+		Action:     types.AuditActionTrialStarted,
+		TargetType: types.AuditTargetSubscription,
+		TargetID:   &userID,
+		After:      map[string]interface{}{"plan": sub.Plan, "status": sub.Status, "trial_end_date": sub.TrialEndDate},
+	})
+	l.InfoContext(ctx, "Trial started", slog.String("plan", plan), slog.Time("trialEnd", trialEnd))
+	span.SetStatus(codes.Ok, "Trial started")
+	return sub, nil
+}
+
+// ChangePlan implements Service.
+func (s *ServiceImpl) ChangePlan(ctx context.Context, userID uuid.UUID, req types.ChangePlanRequest) (*types.Subscription, error) {
+	ctx, span := otel.Tracer("SubscriptionService").Start(ctx, "ChangePlan", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+		attribute.String("plan", req.Plan),
+	))
+	defer span.End()
+	l := s.logger.With(slog.String("method", "ChangePlan"), slog.String("userID", userID.String()))
+
+	if _, ok := types.ValidPlans[req.Plan]; !ok {
+		span.SetStatus(codes.Error, "Invalid plan")
+		return nil, fmt.Errorf("unknown plan %q: %w", req.Plan, types.ErrBadRequest)
+	}
+	// Nothing the client sends proves a payment, so it can only step down
+	if req.Plan != types.PlanFree {
+		span.SetStatus(codes.Error, "Upgrade refused")
+		return nil, fmt.Errorf("paid plans are activated by the payment provider: %w", types.ErrForbidden)
+	}
+
+	current, err := s.GetSubscription(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if current.Plan == req.Plan && current.Status == types.SubscriptionActive {
+		span.SetStatus(codes.Error, "Plan unchanged")
+		return nil, fmt.Errorf("already on plan %q: %w", req.Plan, types.ErrConflict)
+	}
+
+	sub, err := s.repo.ChangePlan(ctx, userID, req)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to change plan", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to change plan")
+		return nil, fmt.Errorf("failed to change plan: %w", err)
+	}
+
+	s.audit.Record(ctx, types.AuditEntry{
+		ActorID:    &userID,
+		Action:     types.AuditActionPlanChanged,
+		TargetType: types.AuditTargetSubscription,
+		TargetID:   &userID,
+		Before:     map[string]string{"plan": current.Plan, "status": current.Status},
+		After:      map[string]string{"plan": sub.Plan, "status": sub.Status},
+	})
+	l.InfoContext(ctx, "Plan changed", slog.String("from", current.Plan), slog.String("to", sub.Plan))
+	span.SetStatus(codes.Ok, "Plan changed")
+	return sub, nil
+}
+
+// ActivatePlan implements Service.
+func (s *ServiceImpl) ActivatePlan(ctx context.Context, userID uuid.UUID, plan, provider, externalID string, periodEnd time.Time) (*types.Subscription, error) {
+	ctx, span := otel.Tracer("SubscriptionService").Start(ctx, "ActivatePlan", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+		attribute.String("plan", plan),
+		attribute.String("provider", provider),
+	))
+	defer span.End()
+	l := s.logger.With(slog.String("method", "ActivatePlan"), slog.String("userID", userID.String()))
+
+	if !isPremium(plan) {
+		span.SetStatus(codes.Error, "Invalid plan")
+		return nil, fmt.Errorf("only premium plans can be activated: %w", types.ErrBadRequest)
+	}
+	if userID == uuid.Nil || provider == "" || externalID == "" {
+		span.SetStatus(codes.Error, "Missing provider reference")
+		return nil, fmt.Errorf("user, provider and subscription id are required: %w", types.ErrBadRequest)
+	}
+	if !periodEnd.After(s.now()) {
+		span.SetStatus(codes.Error, "Period already ended")
+		return nil, fmt.Errorf("billing period has already ended: %w", types.ErrBadRequest)
+	}
+
+	sub, err := s.repo.ActivatePlan(ctx, userID, plan, provider, externalID, periodEnd)
+	if err != nil {
+		l.WarnContext(ctx, "Failed to activate plan", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to activate plan")
+		return nil, fmt.Errorf("failed to activate plan: %w", err)
+	}
+
+	s.audit.Record(ctx, types.AuditEntry{
+		Action:     types.AuditActionPlanChanged,
+		TargetType: types.AuditTargetSubscription,
+		TargetID:   &userID,
+		After:      map[string]interface{}{"plan": sub.Plan, "status": sub.Status, "end_date": sub.EndDate},
+		Metadata:   map[string]interface{}{"provider": provider, "subscription_id": externalID},
+	})
+	l.InfoContext(ctx, "Plan activated", slog.String("plan", plan), slog.Time("periodEnd", periodEnd))
+	span.SetStatus(codes.Ok, "Plan activated")
+	return sub, nil
+}
+
+// HandlePaymentWebhook implements Service. The signature is the hex HMAC-SHA256
+// of "<timestamp>.<payload>", where timestamp is in Unix seconds.
+func (s *ServiceImpl) HandlePaymentWebhook(ctx context.Context, payload []byte, timestamp, signature string) error {
+	ctx, span := otel.Tracer("SubscriptionService").Start(ctx, "HandlePaymentWebhook")
+	defer span.End()
+	l := s.logger.With(slog.String("method", "HandlePaymentWebhook"))
+
+	if s.cfg.WebhookSecret == "" {
+		span.SetStatus(codes.Error, "Webhooks disabled")
+		return fmt.Errorf("payment webhooks are not configured: %w", types.ErrForbidden)
+	}
+	if err := s.verifyWebhook(payload, timestamp, signature); err != nil {
+		l.WarnContext(ctx, "Rejected payment webhook", slog.Any("error", err))
+		span.SetStatus(codes.Error, "Invalid signature")
+		return err
+	}
+
+	var event types.PaymentEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		span.SetStatus(codes.Error, "Invalid payload")
+		return fmt.Errorf("invalid webhook payload: %w", types.ErrBadRequest)
+	}
+	span.SetAttributes(attribute.String("event.id", event.ID), attribute.String("event.type", event.Type))
+	l = l.With(slog.String("eventID", event.ID), slog.String("eventType", event.Type))
+
+	switch event.Type {
+	case types.PaymentEventSubscriptionActivated:
+		_, err := s.ActivatePlan(ctx, event.UserID, event.Plan, event.Provider, event.ExternalSubscriptionID, event.PeriodEnd)
+		if errors.Is(err, types.ErrConflict) {
+			// Providers retry and reorder deliveries; the newer period already applies
+			l.InfoContext(ctx, "Payment event already applied")
+			span.SetStatus(codes.Ok, "Event already applied")
+			return nil
+		}
+		if err != nil {
+			span.RecordError(err)
+			return err
+		}
+	default:
+		l.InfoContext(ctx, "Ignoring payment event")
+	}
+	span.SetStatus(codes.Ok, "Event handled")
+	return nil
+}
+
+// verifyWebhook checks the webhook signature and rejects stale timestamps so a
+// captured request cannot be replayed later.
+func (s *ServiceImpl) verifyWebhook(payload []byte, timestamp, signature string) error {
+	secs, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid webhook timestamp: %w", types.ErrUnauthenticated)
+	}
+	age := s.now().Sub(time.Unix(secs, 0))
+	if age > s.cfg.WebhookTolerance || age < -s.cfg.WebhookTolerance {
+		return fmt.Errorf("webhook timestamp outside tolerance: %w", types.ErrUnauthenticated)
+	}
+
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("invalid webhook signature: %w", types.ErrUnauthenticated)
+	}
+	mac := hmac.New(sha256.New, []byte(s.cfg.WebhookSecret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return fmt.Errorf("invalid webhook signature: %w", types.ErrUnauthenticated)
+	}
+	return nil
+}
+
+// Run implements Service.
+func (s *ServiceImpl) Run(ctx context.Context) {
+	l := s.logger.With(slog.String("worker", "subscriptions"))
+	l.InfoContext(ctx, "Subscription expiry worker started", slog.Duration("interval", s.cfg.ExpiryInterval))
+
+	ticker := time.NewTicker(s.cfg.ExpiryInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.expireDue(ctx); err != nil {
+			l.ErrorContext(ctx, "Failed to expire subscriptions", slog.Any("error", err))
+		}
+		select {
+		case <-ctx.Done():
+			l.Info("Subscription expiry worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// expireDue expires subscriptions past their end date. Affected users keep their
+// current plan claims until their access token is next refreshed.
+func (s *ServiceImpl) expireDue(ctx context.Context) error {
+	expired, err := s.repo.ExpireDue(ctx)
+	if err != nil {
+		return err
+	}
+	for _, sub := range expired {
+		userID := sub.UserID
+		s.audit.Record(ctx, types.AuditEntry{
+			Action:     types.AuditActionSubscriptionExpired,
+			TargetType: types.AuditTargetSubscription,
+			TargetID:   &userID,
+			Metadata:   map[string]interface{}{"plan": sub.Plan, "end_date": sub.EndDate},
+		})
+	}
+	if len(expired) > 0 {
+		s.logger.InfoContext(ctx, "Subscriptions expired", slog.Int("count", len(expired)))
+	}
+	return nil
+}
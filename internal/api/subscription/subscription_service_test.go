@@ -0,0 +1,359 @@
+package subscription
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/config"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+// MockSubscriptionRepository is a mock implementation of Repository
+type MockSubscriptionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSubscriptionRepository) GetCurrentSubscriptionByUserID(ctx context.Context, userID string) (*types.Subscription, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) CreateDefaultSubscription(ctx context.Context, userID string) error {
+	return m.Called(ctx, userID).Error(0)
+}
+
+func (m *MockSubscriptionRepository) StartTrial(ctx context.Context, userID uuid.UUID, plan string, trialEnd time.Time) (*types.Subscription, error) {
+	args := m.Called(ctx, userID, plan, trialEnd)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) ChangePlan(ctx context.Context, userID uuid.UUID, req types.ChangePlanRequest) (*types.Subscription, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) ActivatePlan(ctx context.Context, userID uuid.UUID, plan, provider, externalID string, periodEnd time.Time) (*types.Subscription, error) {
+	args := m.Called(ctx, userID, plan, provider, externalID, periodEnd)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) ExpireDue(ctx context.Context) ([]types.Subscription, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.Subscription), args.Error(1)
+}
+
+// recordingAuditor keeps recorded entries for assertions
+type recordingAuditor struct {
+	entries []types.AuditEntry
+}
+
+func (a *recordingAuditor) Record(_ context.Context, entry types.AuditEntry) {
+	a.entries = append(a.entries, entry)
+}
+
+const testWebhookSecret = "whsec_test"
+
+// Helper to setup service with mock repository and a fixed clock
+func setupSubscriptionServiceTest() (*ServiceImpl, *MockSubscriptionRepository, *recordingAuditor, time.Time) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	mockRepo := new(MockSubscriptionRepository)
+	auditor := &recordingAuditor{}
+	service := NewService(mockRepo, auditor, config.SubscriptionConfig{TrialPeriod: 7 * 24 * time.Hour, WebhookSecret: testWebhookSecret}, logger)
+	now := time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	return service, mockRepo, auditor, now
+}
+
+func TestServiceImpl_GetSubscription(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("creates the free plan when missing", func(t *testing.T) {
+		service, mockRepo, _, _ := setupSubscriptionServiceTest()
+		free := &types.Subscription{UserID: userID, Plan: types.PlanFree, Status: types.SubscriptionActive}
+		mockRepo.On("GetCurrentSubscriptionByUserID", mock.Anything, userID.String()).Return(nil, types.ErrNotFound).Once()
+		mockRepo.On("CreateDefaultSubscription", mock.Anything, userID.String()).Return(nil).Once()
+		mockRepo.On("GetCurrentSubscriptionByUserID", mock.Anything, userID.String()).Return(free, nil).Once()
+
+		sub, err := service.GetSubscription(ctx, userID)
+
+		require.NoError(t, err)
+		assert.Equal(t, free, sub)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestServiceImpl_StartTrial(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		service, mockRepo, auditor, now := setupSubscriptionServiceTest()
+		trialEnd := now.Add(7 * 24 * time.Hour)
+		mockRepo.On("StartTrial", mock.Anything, userID, types.PlanPremiumMonthly, trialEnd).
+			Return(&types.Subscription{Plan: types.PlanPremiumMonthly, Status: types.SubscriptionTrialing, TrialEndDate: &trialEnd}, nil).Once()
+
+		sub, err := service.StartTrial(ctx, userID, types.PlanPremiumMonthly)
+
+		require.NoError(t, err)
+		assert.Equal(t, types.SubscriptionTrialing, sub.Status)
+		require.Len(t, auditor.entries, 1)
+		assert.Equal(t, types.AuditActionTrialStarted, auditor.entries[0].Action)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects free plan", func(t *testing.T) {
+		service, mockRepo, _, _ := setupSubscriptionServiceTest()
+
+		_, err := service.StartTrial(ctx, userID, types.PlanFree)
+
+		assert.True(t, errors.Is(err, types.ErrBadRequest))
+		mockRepo.AssertNotCalled(t, "StartTrial", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("trial already used", func(t *testing.T) {
+		service, mockRepo, auditor, _ := setupSubscriptionServiceTest()
+		mockRepo.On("StartTrial", mock.Anything, userID, types.PlanPremiumAnnual, mock.Anything).
+			Return(nil, types.ErrConflict).Once()
+
+		_, err := service.StartTrial(ctx, userID, types.PlanPremiumAnnual)
+
+		assert.True(t, errors.Is(err, types.ErrConflict))
+		assert.Empty(t, auditor.entries)
+	})
+}
+
+func TestServiceImpl_ChangePlan(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("downgrade to free", func(t *testing.T) {
+		service, mockRepo, auditor, _ := setupSubscriptionServiceTest()
+		req := types.ChangePlanRequest{Plan: types.PlanFree}
+		mockRepo.On("GetCurrentSubscriptionByUserID", mock.Anything, userID.String()).
+			Return(&types.Subscription{Plan: types.PlanPremiumAnnual, Status: types.SubscriptionActive}, nil).Once()
+		mockRepo.On("ChangePlan", mock.Anything, userID, req).
+			Return(&types.Subscription{Plan: types.PlanFree, Status: types.SubscriptionActive}, nil).Once()
+
+		sub, err := service.ChangePlan(ctx, userID, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, types.PlanFree, sub.Plan)
+		require.Len(t, auditor.entries, 1)
+		assert.Equal(t, types.AuditActionPlanChanged, auditor.entries[0].Action)
+		assert.Equal(t, map[string]string{"plan": types.PlanPremiumAnnual, "status": types.SubscriptionActive}, auditor.entries[0].Before)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("cancelling a trial", func(t *testing.T) {
+		service, mockRepo, _, _ := setupSubscriptionServiceTest()
+		mockRepo.On("GetCurrentSubscriptionByUserID", mock.Anything, userID.String()).
+			Return(&types.Subscription{Plan: types.PlanPremiumMonthly, Status: types.SubscriptionTrialing}, nil).Once()
+		mockRepo.On("ChangePlan", mock.Anything, userID, types.ChangePlanRequest{Plan: types.PlanFree}).
+			Return(&types.Subscription{Plan: types.PlanFree, Status: types.SubscriptionActive}, nil).Once()
+
+		_, err := service.ChangePlan(ctx, userID, types.ChangePlanRequest{Plan: types.PlanFree})
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("upgrades are forbidden", func(t *testing.T) {
+		for _, plan := range []string{types.PlanPremiumMonthly, types.PlanPremiumAnnual} {
+			service, mockRepo, auditor, _ := setupSubscriptionServiceTest()
+
+			_, err := service.ChangePlan(ctx, userID, types.ChangePlanRequest{Plan: plan})
+
+			assert.True(t, errors.Is(err, types.ErrForbidden), plan)
+			assert.Empty(t, auditor.entries)
+			mockRepo.AssertNotCalled(t, "ChangePlan", mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("already free is a conflict", func(t *testing.T) {
+		service, mockRepo, _, _ := setupSubscriptionServiceTest()
+		mockRepo.On("GetCurrentSubscriptionByUserID", mock.Anything, userID.String()).
+			Return(&types.Subscription{Plan: types.PlanFree, Status: types.SubscriptionActive}, nil).Once()
+
+		_, err := service.ChangePlan(ctx, userID, types.ChangePlanRequest{Plan: types.PlanFree})
+
+		assert.True(t, errors.Is(err, types.ErrConflict))
+		mockRepo.AssertNotCalled(t, "ChangePlan", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown plan", func(t *testing.T) {
+		service, _, _, _ := setupSubscriptionServiceTest()
+
+		_, err := service.ChangePlan(ctx, userID, types.ChangePlanRequest{Plan: "platinum"})
+
+		assert.True(t, errors.Is(err, types.ErrBadRequest))
+	})
+}
+
+func TestServiceImpl_ActivatePlan(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		service, mockRepo, auditor, now := setupSubscriptionServiceTest()
+		periodEnd := now.AddDate(0, 1, 0)
+		mockRepo.On("ActivatePlan", mock.Anything, userID, types.PlanPremiumMonthly, "stripe", "sub_123", periodEnd).
+			Return(&types.Subscription{Plan: types.PlanPremiumMonthly, Status: types.SubscriptionActive, EndDate: &periodEnd}, nil).Once()
+
+		sub, err := service.ActivatePlan(ctx, userID, types.PlanPremiumMonthly, "stripe", "sub_123", periodEnd)
+
+		require.NoError(t, err)
+		assert.Equal(t, types.SubscriptionActive, sub.Status)
+		require.Len(t, auditor.entries, 1)
+		assert.Equal(t, types.AuditActionPlanChanged, auditor.entries[0].Action)
+		assert.Nil(t, auditor.entries[0].ActorID)
+		assert.Equal(t, "sub_123", auditor.entries[0].Metadata["subscription_id"])
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects free plan and ended periods", func(t *testing.T) {
+		service, mockRepo, _, now := setupSubscriptionServiceTest()
+
+		_, err := service.ActivatePlan(ctx, userID, types.PlanFree, "stripe", "sub_123", now.AddDate(0, 1, 0))
+		assert.True(t, errors.Is(err, types.ErrBadRequest))
+
+		_, err = service.ActivatePlan(ctx, userID, types.PlanPremiumAnnual, "stripe", "sub_123", now.Add(-time.Hour))
+		assert.True(t, errors.Is(err, types.ErrBadRequest))
+
+		_, err = service.ActivatePlan(ctx, userID, types.PlanPremiumAnnual, "stripe", "", now.AddDate(1, 0, 0))
+		assert.True(t, errors.Is(err, types.ErrBadRequest))
+
+		mockRepo.AssertNotCalled(t, "ActivatePlan", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// signWebhook returns the payload with a timestamp and signature valid at now.
+func signWebhook(t *testing.T, event types.PaymentEvent, now time.Time, secret string) ([]byte, string, string) {
+	t.Helper()
+	payload, err := json.Marshal(event)
+	require.NoError(t, err)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + string(payload)))
+	return payload, timestamp, hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestServiceImpl_HandlePaymentWebhook(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	activated := func(now time.Time) types.PaymentEvent {
+		return types.PaymentEvent{
+			ID:                     "evt_1",
+			Type:                   types.PaymentEventSubscriptionActivated,
+			Provider:               "stripe",
+			UserID:                 userID,
+			Plan:                   types.PlanPremiumAnnual,
+			ExternalSubscriptionID: "sub_123",
+			PeriodEnd:              now.AddDate(1, 0, 0),
+		}
+	}
+
+	t.Run("signed activation upgrades the plan", func(t *testing.T) {
+		service, mockRepo, _, now := setupSubscriptionServiceTest()
+		event := activated(now)
+		mockRepo.On("ActivatePlan", mock.Anything, userID, types.PlanPremiumAnnual, "stripe", "sub_123", mock.MatchedBy(event.PeriodEnd.Equal)).
+			Return(&types.Subscription{Plan: types.PlanPremiumAnnual, Status: types.SubscriptionActive}, nil).Once()
+
+		payload, ts, sig := signWebhook(t, event, now, testWebhookSecret)
+		require.NoError(t, service.HandlePaymentWebhook(ctx, payload, ts, sig))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("replayed event is acknowledged", func(t *testing.T) {
+		service, mockRepo, auditor, now := setupSubscriptionServiceTest()
+		mockRepo.On("ActivatePlan", mock.Anything, userID, types.PlanPremiumAnnual, "stripe", "sub_123", mock.Anything).
+			Return(nil, types.ErrConflict).Once()
+
+		payload, ts, sig := signWebhook(t, activated(now), now, testWebhookSecret)
+		require.NoError(t, service.HandlePaymentWebhook(ctx, payload, ts, sig))
+		assert.Empty(t, auditor.entries)
+	})
+
+	t.Run("rejects bad signatures and stale timestamps", func(t *testing.T) {
+		service, mockRepo, _, now := setupSubscriptionServiceTest()
+
+		payload, ts, _ := signWebhook(t, activated(now), now, testWebhookSecret)
+		_, _, forged := signWebhook(t, activated(now), now, "wrong-secret")
+		assert.True(t, errors.Is(service.HandlePaymentWebhook(ctx, payload, ts, forged), types.ErrUnauthenticated))
+
+		tampered := activated(now)
+		tampered.UserID = uuid.New()
+		_, _, sig := signWebhook(t, activated(now), now, testWebhookSecret)
+		otherPayload, _, _ := signWebhook(t, tampered, now, testWebhookSecret)
+		assert.True(t, errors.Is(service.HandlePaymentWebhook(ctx, otherPayload, ts, sig), types.ErrUnauthenticated))
+
+		stale := now.Add(-10 * time.Minute)
+		payload, ts, sig = signWebhook(t, activated(now), stale, testWebhookSecret)
+		assert.True(t, errors.Is(service.HandlePaymentWebhook(ctx, payload, ts, sig), types.ErrUnauthenticated))
+
+		mockRepo.AssertNotCalled(t, "ActivatePlan", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("refused without a secret", func(t *testing.T) {
+		service, mockRepo, _, now := setupSubscriptionServiceTest()
+		service.cfg.WebhookSecret = ""
+
+		payload, ts, sig := signWebhook(t, activated(now), now, "")
+		assert.True(t, errors.Is(service.HandlePaymentWebhook(ctx, payload, ts, sig), types.ErrForbidden))
+		mockRepo.AssertNotCalled(t, "ActivatePlan", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ignores other event types", func(t *testing.T) {
+		service, mockRepo, _, now := setupSubscriptionServiceTest()
+		event := activated(now)
+		event.Type = "invoice.created"
+
+		payload, ts, sig := signWebhook(t, event, now, testWebhookSecret)
+		require.NoError(t, service.HandlePaymentWebhook(ctx, payload, ts, sig))
+		mockRepo.AssertNotCalled(t, "ActivatePlan", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestServiceImpl_ExpireDue(t *testing.T) {
+	service, mockRepo, auditor, now := setupSubscriptionServiceTest()
+	userID := uuid.New()
+	mockRepo.On("ExpireDue", mock.Anything).
+		Return([]types.Subscription{{UserID: userID, Plan: types.PlanPremiumMonthly, Status: types.SubscriptionExpired, EndDate: &now}}, nil).Once()
+
+	require.NoError(t, service.expireDue(context.Background()))
+
+	require.Len(t, auditor.entries, 1)
+	assert.Equal(t, types.AuditActionSubscriptionExpired, auditor.entries[0].Action)
+	assert.Nil(t, auditor.entries[0].ActorID)
+	assert.Equal(t, userID, *auditor.entries[0].TargetID)
+	mockRepo.AssertExpectations(t)
+}
@@ -48,11 +48,14 @@ func NewHandlerImpl(userService tagsService, logger *slog.Logger) *HandlerImpl {
 
 // GetTags godoc
 // @Summary      Get All User Tags
-// @Description  Retrieves all tags created by the authenticated user
+// @Description  Retrieves a page of the global tags and the tags created by the authenticated user, by name. The Link header carries the next page.
 // @Tags         User Tags
 // @Accept       json
 // @Produce      json
+// @Param        cursor query string false "Cursor of the page to fetch, from the Link header; omit for the first page"
+// @Param        limit query int false "Number of tags per page (default 50, max 200)"
 // @Success      200 {array} types.Tags "User Tags"
+// @Failure      400 {object} types.Response "Invalid cursor"
 // @Failure      401 {object} types.Response "Unauthorized"
 // @Failure      500 {object} types.Response "Internal Server Error"
 // @Security     BearerAuth
@@ -84,7 +87,14 @@ func (u *HandlerImpl) GetTags(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tags, err := u.tagsService.GetTags(ctx, userID)
+	page, err := api.ParsePageRequest(r)
+	if err != nil {
+		span.SetStatus(codes.Error, "Invalid cursor")
+		api.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tags, next, err := u.tagsService.GetTags(ctx, userID, page)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to get user tags", slog.Any("error", err))
 		span.RecordError(err)
@@ -93,6 +103,7 @@ func (u *HandlerImpl) GetTags(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	span.SetStatus(codes.Ok, "Tags retrieved successfully")
+	api.SetPageLinks(w, r, next)
 	api.WriteJSONResponse(w, r, http.StatusOK, tags)
 }
 
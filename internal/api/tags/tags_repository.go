@@ -16,6 +16,7 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/embeddings"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
 )
 
@@ -27,6 +28,10 @@ type Repository interface {
 	// GetAll retrieves all global tags
 	GetAll(ctx context.Context, userID uuid.UUID) ([]*types.Tags, error)
 
+	// List returns a page of the tags GetAll returns, by name, and the cursor
+	// of the next page.
+	List(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]*types.Tags, *types.Cursor, error)
+
 	// Get retrieves all avoid tags for a user
 	Get(ctx context.Context, userID, tagID uuid.UUID) (*types.Tags, error)
 
@@ -140,6 +145,70 @@ func (r *RepositoryImpl) GetAll(ctx context.Context, userID uuid.UUID) ([]*types
 	return tags, nil
 }
 
+// List implements Repository.
+func (r *RepositoryImpl) List(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]*types.Tags, *types.Cursor, error) {
+	ctx, span := otel.Tracer("UserRepo").Start(ctx, "ListTags", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "global_tags, user_personal_tags"),
+		attribute.String("db.user.id", userID.String()),
+	))
+	defer span.End()
+
+	args := []interface{}{userID, page.FetchLimit()}
+	after := ""
+	if page.Cursor != nil {
+		args = append(args, page.Cursor.Key, page.Cursor.ID)
+		after = "WHERE (name, id) > ($3, $4)"
+	}
+	query := fmt.Sprintf(`
+        SELECT id, name, description, tag_type, source, active, created_at
+        FROM (
+            SELECT g.id, g.name, g.description, g.tag_type, 'global' AS source,
+                   false AS active, g.created_at
+            FROM global_tags g
+            WHERE g.active = TRUE
+
+            UNION ALL
+
+            SELECT upt.id, upt.name, NULL AS description, upt.tag_type, 'personal' AS source,
+                   upt.active, upt.created_at
+            FROM user_personal_tags upt
+            WHERE upt.user_id = $1
+        ) t
+        %s
+        ORDER BY name, id
+        LIMIT $2`, after)
+
+	rows, err := r.pgpool.Query(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, nil, fmt.Errorf("database error listing tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []*types.Tags
+	for rows.Next() {
+		var t types.Tags
+		if err := rows.Scan(&t.ID, &t.Name, &t.Description, &t.TagType, &t.Source, &t.Active, &t.CreatedAt); err != nil {
+			span.RecordError(err)
+			return nil, nil, fmt.Errorf("database error scanning tag: %w", err)
+		}
+		tags = append(tags, &t)
+	}
+	if err = rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, nil, fmt.Errorf("database error reading tags: %w", err)
+	}
+
+	tags, next := types.NextPage(tags, page.Limit, func(t *types.Tags) types.Cursor {
+		return types.Cursor{Key: t.Name, ID: t.ID}
+	})
+	span.SetAttributes(attribute.Int("results.count", len(tags)))
+	span.SetStatus(codes.Ok, "Tags listed")
+	return tags, next, nil
+}
+
 // Get implements user.UserRepo.
 func (r *RepositoryImpl) Get(ctx context.Context, userID, tagID uuid.UUID) (*types.Tags, error) {
 	var tag types.Tags
@@ -255,6 +324,11 @@ func (r *RepositoryImpl) Create(ctx context.Context, userID uuid.UUID, params ty
 		return nil, fmt.Errorf("database error creating personal tag: %w", err)
 	}
 
+	if err := embeddings.RefreshUserPreferences(ctx, tx, userID); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
 	err = tx.Commit(ctx)
 	if err != nil {
 		span.RecordError(err)
@@ -315,6 +389,11 @@ func (r *RepositoryImpl) Update(ctx context.Context, userID, tagsID uuid.UUID, p
 		return fmt.Errorf("personal tag not found or not owned by user: %w", types.ErrNotFound)
 	}
 
+	if err := embeddings.RefreshUserPreferences(ctx, tx, userID); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
 	err = tx.Commit(ctx)
 	if err != nil {
 		span.RecordError(err)
@@ -369,6 +448,11 @@ func (r *RepositoryImpl) Delete(ctx context.Context, userID uuid.UUID, tagID uui
 		return fmt.Errorf("personal tag not found or not owned by user: %w", types.ErrNotFound)
 	}
 
+	if err := embeddings.RefreshUserPreferences(ctx, tx, userID); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
 	err = tx.Commit(ctx)
 	if err != nil {
 		span.RecordError(err)
@@ -438,10 +522,18 @@ func (r *RepositoryImpl) LinkPersonalTagToProfile(ctx context.Context, userID, p
 	l := r.logger.With(slog.String("method", "AddTagToProfile"), slog.String("profileID", profileID.String()), slog.String("tagID", tagID.String()))
 	l.DebugContext(ctx, "Linking tag to profile")
 
+	tx, err := r.pgpool.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Begin transaction failed")
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	query := `UPDATE user_personal_tags
               SET profile_id = $1, updated_at = NOW()
               WHERE id = $2 AND user_id = $3` // Ensure ownership
-	tag, err := r.pgpool.Exec(ctx, query, profileID, tagID, userID)
+	tag, err := tx.Exec(ctx, query, profileID, tagID, userID)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to link tag to profile", slog.Any("error", err))
 		span.RecordError(err)
@@ -453,6 +545,18 @@ func (r *RepositoryImpl) LinkPersonalTagToProfile(ctx context.Context, userID, p
 		return fmt.Errorf("personal tag %s not found for user %s: %w", tagID, userID, types.ErrNotFound)
 	}
 
+	// The tag leaves whichever profile it was on, so every profile may change
+	if err := embeddings.RefreshUserPreferences(ctx, tx, userID); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Commit transaction failed")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	l.DebugContext(ctx, "Tag linked to profile successfully")
 	span.SetStatus(codes.Ok, "Tag linked")
 	return nil
@@ -17,9 +17,15 @@ import (
 // Ensure implementation satisfies the interface
 var _ tagsService = (*tagsServiceImpl)(nil)
 
+// Page sizes of a user's tags.
+const (
+	defaultTagPageLimit = 50
+	maxTagPageLimit     = 200
+)
+
 // tagsService defines the business logic contract for user operations.
 type tagsService interface {
-	GetTags(ctx context.Context, userID uuid.UUID) ([]*types.Tags, error)
+	GetTags(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]*types.Tags, *types.Cursor, error)
 	GetTag(ctx context.Context, userID, tagID uuid.UUID) (*types.Tags, error)
 	CreateTag(ctx context.Context, userID uuid.UUID, params types.CreatePersonalTagParams) (*types.PersonalTag, error)
 	DeleteTag(ctx context.Context, userID uuid.UUID, tagID uuid.UUID) error
@@ -40,25 +46,25 @@ func NewtagsService(repo Repository, logger *slog.Logger) *tagsServiceImpl {
 	}
 }
 
-// GetTags retrieves all global tags.
-func (s *tagsServiceImpl) GetTags(ctx context.Context, userID uuid.UUID) ([]*types.Tags, error) {
+// GetTags retrieves a page of the global tags and the user's personal tags.
+func (s *tagsServiceImpl) GetTags(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]*types.Tags, *types.Cursor, error) {
 	ctx, span := otel.Tracer("UserService").Start(ctx, "GetAllGlobalTags")
 	defer span.End()
 
 	l := s.logger.With(slog.String("method", "GetAllGlobalTags"))
 	l.DebugContext(ctx, "Fetching all global tags")
 
-	tags, err := s.repo.GetAll(ctx, userID)
+	tags, next, err := s.repo.List(ctx, userID, page.Clamp(defaultTagPageLimit, maxTagPageLimit))
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to fetch all global tags", slog.Any("error", err))
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "Failed to fetch all global tags")
-		return nil, fmt.Errorf("error fetching all global tags: %w", err)
+		return nil, nil, fmt.Errorf("error fetching all global tags: %w", err)
 	}
 
 	l.InfoContext(ctx, "All global tags fetched successfully", slog.Int("count", len(tags)))
 	span.SetStatus(codes.Ok, "All global tags fetched successfully")
-	return tags, nil
+	return tags, next, nil
 }
 
 // GetTag retrieves all avoid tags for a user.
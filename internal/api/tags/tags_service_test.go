@@ -27,6 +27,15 @@ func (m *MocktagsRepo) GetAll(ctx context.Context, userID uuid.UUID) ([]*types.T
 	return args.Get(0).([]*types.Tags), args.Error(1)
 }
 
+func (m *MocktagsRepo) List(ctx context.Context, userID uuid.UUID, page types.PageRequest) ([]*types.Tags, *types.Cursor, error) {
+	args := m.Called(ctx, userID, page)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	next, _ := args.Get(1).(*types.Cursor)
+	return args.Get(0).([]*types.Tags), next, args.Error(2)
+}
+
 func (m *MocktagsRepo) Get(ctx context.Context, userID, tagID uuid.UUID) (*types.Tags, error) {
 	args := m.Called(ctx, userID, tagID)
 	if args.Get(0) == nil {
@@ -86,35 +95,47 @@ func TestTagsServiceImpl_GetTags(t *testing.T) {
 	service, mockRepo := setuptagsServiceTest()
 	ctx := context.Background()
 	userID := uuid.New()
+	firstPage := types.PageRequest{Limit: defaultTagPageLimit}
 
 	t.Run("success - tags found", func(t *testing.T) {
 		expectedTags := []*types.Tags{
 			{ID: uuid.New(), Name: "Outdoors", TagType: "preference"},
 			{ID: uuid.New(), Name: "Foodie", TagType: "preference"},
 		}
-		mockRepo.On("GetAll", ctx, userID).Return(expectedTags, nil).Once()
+		next := &types.Cursor{Key: "Foodie", ID: expectedTags[1].ID}
+		mockRepo.On("List", mock.Anything, userID, firstPage).Return(expectedTags, next, nil).Once()
 
-		tags, err := service.GetTags(ctx, userID)
+		tags, gotNext, err := service.GetTags(ctx, userID, types.PageRequest{})
 		require.NoError(t, err)
 		assert.Equal(t, expectedTags, tags)
+		assert.Equal(t, next, gotNext)
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("success - no tags found", func(t *testing.T) {
 		var expectedTags []*types.Tags
-		mockRepo.On("GetAll", ctx, userID).Return(expectedTags, nil).Once()
+		mockRepo.On("List", mock.Anything, userID, firstPage).Return(expectedTags, nil, nil).Once()
 
-		tags, err := service.GetTags(ctx, userID)
+		tags, next, err := service.GetTags(ctx, userID, types.PageRequest{})
 		require.NoError(t, err)
 		assert.Empty(t, tags)
+		assert.Nil(t, next)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("limit is capped", func(t *testing.T) {
+		mockRepo.On("List", mock.Anything, userID, types.PageRequest{Limit: maxTagPageLimit}).Return([]*types.Tags{}, nil, nil).Once()
+
+		_, _, err := service.GetTags(ctx, userID, types.PageRequest{Limit: 10_000})
+		require.NoError(t, err)
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("repository error", func(t *testing.T) {
 		repoErr := errors.New("db error fetching all tags")
-		mockRepo.On("GetAll", ctx, userID).Return(nil, repoErr).Once()
+		mockRepo.On("List", mock.Anything, userID, firstPage).Return(nil, nil, repoErr).Once()
 
-		_, err := service.GetTags(ctx, userID)
+		_, _, err := service.GetTags(ctx, userID, types.PageRequest{})
 		require.Error(t, err)
 		assert.True(t, errors.Is(err, repoErr))
 		assert.Contains(t, err.Error(), "error fetching all global tags:")
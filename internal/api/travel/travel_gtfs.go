@@ -0,0 +1,214 @@
+package travel
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+// maxRideSeconds bounds the rides kept from a feed; longer ones are of no use
+// between stops of a city itinerary and would only grow the table.
+const maxRideSeconds = 2 * 60 * 60
+
+// stopTime is one timed call of a trip at a stop.
+type stopTime struct {
+	stop      int32 // Index into the feed's stop IDs
+	sequence  int32
+	arrival   int32 // Seconds after midnight; may pass 24h
+	departure int32
+}
+
+// parseGTFS reads the stops and the quickest single ride between every pair of
+// stops served in order by the same trip. Stops and calls without coordinates
+// or times are skipped.
+func parseGTFS(zr *zip.Reader, feedID uuid.UUID) ([]types.TransitStop, []types.TransitRide, error) {
+	stops, err := readStops(zr, feedID)
+	if err != nil {
+		return nil, nil, err
+	}
+	stopIndex := make(map[string]int32, len(stops))
+	for i, s := range stops {
+		stopIndex[s.StopID] = int32(i)
+	}
+
+	trips, err := readStopTimes(zr, stopIndex)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	type pair struct{ from, to int32 }
+	quickest := make(map[pair]int32)
+	seenPatterns := make(map[string]bool)
+	for _, calls := range trips {
+		sort.Slice(calls, func(i, j int) bool { return calls[i].sequence < calls[j].sequence })
+		// Trips running the same stops at the same pace give the same rides
+		key := patternKey(calls)
+		if seenPatterns[key] {
+			continue
+		}
+		seenPatterns[key] = true
+		for i, from := range calls {
+			for _, to := range calls[i+1:] {
+				ride := to.arrival - from.departure
+				if ride < 0 || ride > maxRideSeconds || from.stop == to.stop {
+					continue
+				}
+				p := pair{from.stop, to.stop}
+				if best, ok := quickest[p]; !ok || ride < best {
+					quickest[p] = ride
+				}
+			}
+		}
+	}
+
+	rides := make([]types.TransitRide, 0, len(quickest))
+	for p, seconds := range quickest {
+		rides = append(rides, types.TransitRide{
+			FeedID:      feedID,
+			FromStopID:  stops[p.from].StopID,
+			ToStopID:    stops[p.to].StopID,
+			RideSeconds: int(seconds),
+		})
+	}
+	return stops, rides, nil
+}
+
+func patternKey(calls []stopTime) string {
+	var b strings.Builder
+	start := calls[0].departure
+	for _, c := range calls {
+		fmt.Fprintf(&b, "%d@%d/%d;", c.stop, c.arrival-start, c.departure-start)
+	}
+	return b.String()
+}
+
+func readStops(zr *zip.Reader, feedID uuid.UUID) ([]types.TransitStop, error) {
+	var stops []types.TransitStop
+	err := eachRecord(zr, "stops.txt", []string{"stop_id", "stop_name", "stop_lat", "stop_lon"}, func(get func(string) string) error {
+		lat, latErr := strconv.ParseFloat(get("stop_lat"), 64)
+		lon, lonErr := strconv.ParseFloat(get("stop_lon"), 64)
+		if latErr != nil || lonErr != nil || get("stop_id") == "" {
+			return nil
+		}
+		stops = append(stops, types.TransitStop{
+			FeedID:    feedID,
+			StopID:    get("stop_id"),
+			Name:      get("stop_name"),
+			Latitude:  lat,
+			Longitude: lon,
+		})
+		return nil
+	})
+	return stops, err
+}
+
+func readStopTimes(zr *zip.Reader, stopIndex map[string]int32) (map[string][]stopTime, error) {
+	trips := make(map[string][]stopTime)
+	columns := []string{"trip_id", "stop_id", "stop_sequence", "arrival_time", "departure_time"}
+	err := eachRecord(zr, "stop_times.txt", columns, func(get func(string) string) error {
+		stop, ok := stopIndex[get("stop_id")]
+		if !ok {
+			return nil
+		}
+		arrival, arrOK := parseGTFSTime(get("arrival_time"))
+		departure, depOK := parseGTFSTime(get("departure_time"))
+		if !arrOK && !depOK {
+			return nil // Untimed call between timepoints
+		}
+		if !arrOK {
+			arrival = departure
+		}
+		if !depOK {
+			departure = arrival
+		}
+		sequence, err := strconv.Atoi(get("stop_sequence"))
+		if err != nil {
+			return nil
+		}
+		trip := get("trip_id")
+		trips[trip] = append(trips[trip], stopTime{
+			stop:      stop,
+			sequence:  int32(sequence),
+			arrival:   arrival,
+			departure: departure,
+		})
+		return nil
+	})
+	return trips, err
+}
+
+// parseGTFSTime parses HH:MM:SS, where the hours may pass 24 for trips running
+// past midnight, into seconds.
+func parseGTFSTime(value string) (int32, bool) {
+	parts := strings.Split(strings.TrimSpace(value), ":")
+	if len(parts) != 3 {
+		return 0, false
+	}
+	var seconds int32
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, false
+		}
+		seconds = seconds*60 + int32(n)
+	}
+	return seconds, true
+}
+
+// eachRecord calls fn with every row of a CSV file in the feed. get returns a
+// column of the row by name, or "" when the feed does not have it.
+func eachRecord(zr *zip.Reader, name string, required []string, fn func(get func(string) string) error) error {
+	f, err := zr.Open(name)
+	if err != nil {
+		return fmt.Errorf("%w: feed has no %s", types.ErrBadRequest, name)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.ReuseRecord = true
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		return fmt.Errorf("%w: unreadable %s header: %v", types.ErrBadRequest, name, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		// Some feeds start with a byte order mark
+		columns[strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))] = i
+	}
+	for _, column := range required {
+		if _, ok := columns[column]; !ok {
+			return fmt.Errorf("%w: %s has no %s column", types.ErrBadRequest, name, column)
+		}
+	}
+
+	var record []string
+	get := func(column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	for {
+		record, err = r.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: malformed %s: %v", types.ErrBadRequest, name, err)
+		}
+		if err := fn(get); err != nil {
+			return err
+		}
+	}
+}
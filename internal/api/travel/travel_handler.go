@@ -0,0 +1,143 @@
+package travel
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/auth"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Handler = (*HandlerImpl)(nil)
+
+type Handler interface {
+	ListFeeds(w http.ResponseWriter, r *http.Request)
+	CreateFeed(w http.ResponseWriter, r *http.Request)
+	ImportFeed(w http.ResponseWriter, r *http.Request)
+}
+
+type HandlerImpl struct {
+	logger  *slog.Logger
+	service Service
+}
+
+func NewHandler(service Service, logger *slog.Logger) *HandlerImpl {
+	return &HandlerImpl{
+		logger:  logger,
+		service: service,
+	}
+}
+
+// ListFeeds godoc
+// @Summary      List Transit Feeds
+// @Description  Returns the GTFS feeds used for public transport travel times, with when each was last imported. Admin only.
+// @Tags         Admin
+// @Produce      json
+// @Success      200 {array} types.TransitFeed
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      403 {object} types.Response "Forbidden"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/transit/feeds [get]
+func (h *HandlerImpl) ListFeeds(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("TravelHandler").Start(r.Context(), "ListFeeds")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "ListFeeds"))
+
+	feeds, err := h.service.ListFeeds(ctx)
+	if err != nil {
+		l.ErrorContext(ctx, "Service failed to list transit feeds", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to list transit feeds")
+		api.WriteServiceError(w, r, err, "Failed to list transit feeds")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Transit feeds listed")
+	api.WriteJSONResponse(w, r, http.StatusOK, feeds)
+}
+
+// CreateFeed godoc
+// @Summary      Add a Transit Feed
+// @Description  Registers a GTFS feed and queues a job that downloads it and stores its stops and single-ride travel times. Poll /admin/jobs/{jobID} with the returned import_job_id for progress. Admin only.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        feed body types.CreateTransitFeedRequest true "Feed name, GTFS zip URL and optional city"
+// @Success      202 {object} types.TransitFeed
+// @Failure      400 {object} types.Response "Missing name or invalid URL"
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      403 {object} types.Response "Forbidden"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/transit/feeds [post]
+func (h *HandlerImpl) CreateFeed(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("TravelHandler").Start(r.Context(), "CreateFeed")
+	defer span.End()
+	l := h.logger.With(slog.String("handler", "CreateFeed"))
+
+	var req types.CreateTransitFeedRequest
+	if err := api.DecodeJSONBody(w, r, &req); err != nil {
+		l.ErrorContext(ctx, "Failed to decode request", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Bad request")
+		return
+	}
+
+	feed, err := h.service.CreateFeed(ctx, req, api.Requester(auth.GetUserIDFromContext(ctx)))
+	if err != nil {
+		l.ErrorContext(ctx, "Service failed to create transit feed", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to create transit feed")
+		api.WriteServiceError(w, r, err, "Failed to create transit feed")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Transit feed created")
+	api.WriteJSONResponse(w, r, http.StatusAccepted, feed)
+}
+
+// ImportFeed godoc
+// @Summary      Re-import a Transit Feed
+// @Description  Queues a job that downloads the feed again and replaces its stops and travel times. Admin only.
+// @Tags         Admin
+// @Produce      json
+// @Param        feedID path string true "Transit feed ID"
+// @Success      202 {object} types.Job
+// @Failure      400 {object} types.Response "Invalid feed ID"
+// @Failure      401 {object} types.Response "Unauthorized"
+// @Failure      403 {object} types.Response "Forbidden"
+// @Failure      404 {object} types.Response "Feed not found"
+// @Failure      500 {object} types.Response "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/transit/feeds/{feedID}/import [post]
+func (h *HandlerImpl) ImportFeed(w http.ResponseWriter, r *http.Request) {
+	ctx, span := otel.Tracer("TravelHandler").Start(r.Context(), "ImportFeed")
+	defer span.End()
+
+	feedID, err := uuid.Parse(chi.URLParam(r, "feedID"))
+	if err != nil {
+		span.SetStatus(codes.Error, "Invalid feed ID")
+		api.ErrorResponse(w, r, http.StatusBadRequest, "Invalid feed ID")
+		return
+	}
+	span.SetAttributes(attribute.String("feed.id", feedID.String()))
+
+	job, err := h.service.ImportFeed(ctx, feedID, api.Requester(auth.GetUserIDFromContext(ctx)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to queue import")
+		api.WriteServiceError(w, r, err, "Failed to queue transit feed import")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Import queued")
+	api.WriteJSONResponse(w, r, http.StatusAccepted, job)
+}
@@ -0,0 +1,319 @@
+package travel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Repository = (*RepositoryImpl)(nil)
+
+// importBatchSize is how many stops or rides are inserted per statement.
+const importBatchSize = 5000
+
+// Repository stores transit feeds and looks up their stops and rides.
+type Repository interface {
+	CreateFeed(ctx context.Context, req types.CreateTransitFeedRequest) (*types.TransitFeed, error)
+	GetFeed(ctx context.Context, feedID uuid.UUID) (*types.TransitFeed, error)
+	ListFeeds(ctx context.Context) ([]types.TransitFeed, error)
+	// ReplaceFeedData swaps a feed's stops and rides for the given ones in one transaction.
+	ReplaceFeedData(ctx context.Context, feedID uuid.UUID, stops []types.TransitStop, rides []types.TransitRide) error
+
+	// NearbyStops returns up to limit stops of any feed within radiusMeters, nearest first.
+	NearbyStops(ctx context.Context, lat, lon, radiusMeters float64, limit int) ([]types.TransitStop, error)
+	// Rides returns the stored rides from any of from to any of to, within the same feed.
+	Rides(ctx context.Context, from, to []types.TransitStop) ([]types.TransitRide, error)
+}
+
+type RepositoryImpl struct {
+	logger *slog.Logger
+	pgpool *pgxpool.Pool
+}
+
+func NewRepository(pgxpool *pgxpool.Pool, logger *slog.Logger) *RepositoryImpl {
+	return &RepositoryImpl{
+		logger: logger,
+		pgpool: pgxpool,
+	}
+}
+
+const feedColumns = `id, name, source_url, city_id, stop_count, ride_count, imported_at, created_at, updated_at`
+
+func scanFeed(row pgx.Row) (*types.TransitFeed, error) {
+	var f types.TransitFeed
+	err := row.Scan(&f.ID, &f.Name, &f.SourceURL, &f.CityID, &f.StopCount, &f.RideCount,
+		&f.ImportedAt, &f.CreatedAt, &f.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// CreateFeed implements Repository.
+func (r *RepositoryImpl) CreateFeed(ctx context.Context, req types.CreateTransitFeedRequest) (*types.TransitFeed, error) {
+	ctx, span := otel.Tracer("TravelRepo").Start(ctx, "CreateFeed", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "transit_feeds"),
+	))
+	defer span.End()
+
+	feed, err := scanFeed(r.pgpool.QueryRow(ctx, `
+		INSERT INTO transit_feeds (name, source_url, city_id)
+		VALUES ($1, $2, $3)
+		RETURNING `+feedColumns, req.Name, req.SourceURL, req.CityID))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB insert failed")
+		return nil, fmt.Errorf("database error creating transit feed: %w", err)
+	}
+	span.SetStatus(codes.Ok, "Transit feed created")
+	return feed, nil
+}
+
+// GetFeed implements Repository.
+func (r *RepositoryImpl) GetFeed(ctx context.Context, feedID uuid.UUID) (*types.TransitFeed, error) {
+	ctx, span := otel.Tracer("TravelRepo").Start(ctx, "GetFeed", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "transit_feeds"),
+		attribute.String("feed.id", feedID.String()),
+	))
+	defer span.End()
+
+	feed, err := scanFeed(r.pgpool.QueryRow(ctx, `SELECT `+feedColumns+` FROM transit_feeds WHERE id = $1`, feedID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("transit feed not found: %w", types.ErrNotFound)
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, fmt.Errorf("database error fetching transit feed: %w", err)
+	}
+	return feed, nil
+}
+
+// ListFeeds implements Repository.
+func (r *RepositoryImpl) ListFeeds(ctx context.Context) ([]types.TransitFeed, error) {
+	ctx, span := otel.Tracer("TravelRepo").Start(ctx, "ListFeeds", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "transit_feeds"),
+	))
+	defer span.End()
+
+	rows, err := r.pgpool.Query(ctx, `SELECT `+feedColumns+` FROM transit_feeds ORDER BY name, id`)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, fmt.Errorf("database error listing transit feeds: %w", err)
+	}
+	defer rows.Close()
+
+	feeds := []types.TransitFeed{}
+	for rows.Next() {
+		feed, err := scanFeed(rows)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("database error scanning transit feed: %w", err)
+		}
+		feeds = append(feeds, *feed)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("database error iterating transit feeds: %w", err)
+	}
+	return feeds, nil
+}
+
+// ReplaceFeedData implements Repository.
+func (r *RepositoryImpl) ReplaceFeedData(ctx context.Context, feedID uuid.UUID, stops []types.TransitStop, rides []types.TransitRide) error {
+	ctx, span := otel.Tracer("TravelRepo").Start(ctx, "ReplaceFeedData", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "transit_stops"),
+		attribute.String("feed.id", feedID.String()),
+		attribute.Int("stops.count", len(stops)),
+		attribute.Int("rides.count", len(rides)),
+	))
+	defer span.End()
+
+	tx, err := r.pgpool.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the feed so concurrent imports of it run one after the other
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM transit_feeds WHERE id = $1 FOR UPDATE`, feedID); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("database error locking transit feed: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM transit_rides WHERE feed_id = $1`, feedID); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("database error clearing transit rides: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM transit_stops WHERE feed_id = $1`, feedID); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("database error clearing transit stops: %w", err)
+	}
+
+	for start := 0; start < len(stops); start += importBatchSize {
+		batch := stops[start:min(start+importBatchSize, len(stops))]
+		ids := make([]string, len(batch))
+		names := make([]string, len(batch))
+		lats := make([]float64, len(batch))
+		lons := make([]float64, len(batch))
+		for i, s := range batch {
+			ids[i], names[i], lats[i], lons[i] = s.StopID, s.Name, s.Latitude, s.Longitude
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO transit_stops (feed_id, stop_id, name, location)
+			SELECT $1, s.stop_id, s.name, ST_SetSRID(ST_MakePoint(s.lon, s.lat), 4326)
+			FROM unnest($2::text[], $3::text[], $4::float8[], $5::float8[]) AS s(stop_id, name, lat, lon)
+			ON CONFLICT (feed_id, stop_id) DO NOTHING`, feedID, ids, names, lats, lons)
+		if err != nil {
+			span.RecordError(err)
+			return fmt.Errorf("database error inserting transit stops: %w", err)
+		}
+	}
+
+	for start := 0; start < len(rides); start += importBatchSize {
+		batch := rides[start:min(start+importBatchSize, len(rides))]
+		from := make([]string, len(batch))
+		to := make([]string, len(batch))
+		seconds := make([]int32, len(batch))
+		for i, ride := range batch {
+			from[i], to[i], seconds[i] = ride.FromStopID, ride.ToStopID, int32(ride.RideSeconds)
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO transit_rides (feed_id, from_stop_id, to_stop_id, ride_seconds)
+			SELECT $1, r.from_stop_id, r.to_stop_id, r.ride_seconds
+			FROM unnest($2::text[], $3::text[], $4::int[]) AS r(from_stop_id, to_stop_id, ride_seconds)
+			ON CONFLICT (feed_id, from_stop_id, to_stop_id) DO UPDATE
+			SET ride_seconds = LEAST(transit_rides.ride_seconds, EXCLUDED.ride_seconds)`, feedID, from, to, seconds)
+		if err != nil {
+			span.RecordError(err)
+			return fmt.Errorf("database error inserting transit rides: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE transit_feeds
+		SET stop_count = $2, ride_count = $3, imported_at = NOW()
+		WHERE id = $1`, feedID, len(stops), len(rides))
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("database error updating transit feed: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Commit failed")
+		return fmt.Errorf("failed to commit transit feed import: %w", err)
+	}
+	span.SetStatus(codes.Ok, "Transit feed data replaced")
+	return nil
+}
+
+// NearbyStops implements Repository.
+func (r *RepositoryImpl) NearbyStops(ctx context.Context, lat, lon, radiusMeters float64, limit int) ([]types.TransitStop, error) {
+	ctx, span := otel.Tracer("TravelRepo").Start(ctx, "NearbyStops", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "transit_stops"),
+		attribute.Float64("radius_meters", radiusMeters),
+	))
+	defer span.End()
+
+	rows, err := r.pgpool.Query(ctx, `
+		SELECT feed_id, stop_id, name, ST_Y(location), ST_X(location),
+		       ST_Distance(location::geography, ST_SetSRID(ST_MakePoint($2, $1), 4326)::geography) AS walk_meters
+		FROM transit_stops
+		WHERE ST_DWithin(location::geography, ST_SetSRID(ST_MakePoint($2, $1), 4326)::geography, $3)
+		ORDER BY walk_meters
+		LIMIT $4`, lat, lon, radiusMeters, limit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, fmt.Errorf("database error finding nearby transit stops: %w", err)
+	}
+	defer rows.Close()
+
+	var stops []types.TransitStop
+	for rows.Next() {
+		var s types.TransitStop
+		if err := rows.Scan(&s.FeedID, &s.StopID, &s.Name, &s.Latitude, &s.Longitude, &s.WalkMeters); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("database error scanning transit stop: %w", err)
+		}
+		stops = append(stops, s)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("database error iterating transit stops: %w", err)
+	}
+	return stops, nil
+}
+
+// Rides implements Repository.
+func (r *RepositoryImpl) Rides(ctx context.Context, from, to []types.TransitStop) ([]types.TransitRide, error) {
+	ctx, span := otel.Tracer("TravelRepo").Start(ctx, "Rides", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "transit_rides"),
+	))
+	defer span.End()
+
+	if len(from) == 0 || len(to) == 0 {
+		return nil, nil
+	}
+	fromFeeds, fromIDs := stopKeys(from)
+	toFeeds, toIDs := stopKeys(to)
+
+	rows, err := r.pgpool.Query(ctx, `
+		SELECT r.feed_id, r.from_stop_id, r.to_stop_id, r.ride_seconds
+		FROM transit_rides r
+		JOIN unnest($1::uuid[], $2::text[]) AS f(feed_id, stop_id)
+		  ON f.feed_id = r.feed_id AND f.stop_id = r.from_stop_id
+		JOIN unnest($3::uuid[], $4::text[]) AS t(feed_id, stop_id)
+		  ON t.feed_id = r.feed_id AND t.stop_id = r.to_stop_id`,
+		fromFeeds, fromIDs, toFeeds, toIDs)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, fmt.Errorf("database error finding transit rides: %w", err)
+	}
+	defer rows.Close()
+
+	var rides []types.TransitRide
+	for rows.Next() {
+		var ride types.TransitRide
+		if err := rows.Scan(&ride.FeedID, &ride.FromStopID, &ride.ToStopID, &ride.RideSeconds); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("database error scanning transit ride: %w", err)
+		}
+		rides = append(rides, ride)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("database error iterating transit rides: %w", err)
+	}
+	return rides, nil
+}
+
+func stopKeys(stops []types.TransitStop) ([]uuid.UUID, []string) {
+	feeds := make([]uuid.UUID, len(stops))
+	ids := make([]string, len(stops))
+	for i, s := range stops {
+		feeds[i], ids[i] = s.FeedID, s.StopID
+	}
+	return feeds, ids
+}
@@ -0,0 +1,398 @@
+package travel
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/jobs"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ Service = (*ServiceImpl)(nil)
+
+// Speed heuristics. Straight-line distances are stretched by detourFactor
+// since streets and lines rarely run straight.
+const (
+	detourFactor    = 1.3
+	walkSpeedKmh    = 4.8
+	transitSpeedKmh = 20
+	carSpeedKmh     = 25
+	// transitOverheadMinutes covers walking to and from stops and waiting
+	transitOverheadMinutes = 8
+	// carOverheadMinutes covers finding parking
+	carOverheadMinutes = 5
+	// transitWaitMinutes is the expected wait for a timetabled ride
+	transitWaitMinutes = 5
+	// minTransitMeters: shorter public transport legs are walked
+	minTransitMeters = 800
+	// maxAnyWalkMeters: with no preferred transport, longer legs take public transport
+	maxAnyWalkMeters = 1500
+)
+
+// Transit stop lookup around each end of a leg.
+const (
+	stopSearchMeters = 800
+	stopsPerEnd      = 5
+)
+
+// Feed downloads.
+const (
+	maxFeedBytes    = 512 << 20
+	downloadTimeout = 5 * time.Minute
+)
+
+// Service estimates travel times between POIs and imports GTFS feeds for them.
+type Service interface {
+	// Legs returns the trip between every pair of consecutive POIs by mode.
+	// It never fails: without timetable data it falls back to speed heuristics.
+	Legs(ctx context.Context, pois []types.POIDetailedInfo, mode types.TransportPreference) []types.TravelLeg
+	// Estimate returns a single leg.
+	Estimate(ctx context.Context, from, to types.POIDetailedInfo, mode types.TransportPreference) types.TravelLeg
+
+	ListFeeds(ctx context.Context) ([]types.TransitFeed, error)
+	// CreateFeed registers a GTFS feed and queues its first import.
+	CreateFeed(ctx context.Context, req types.CreateTransitFeedRequest, requestedBy *uuid.UUID) (*types.TransitFeed, error)
+	// ImportFeed queues a fresh import of a feed from its source URL.
+	ImportFeed(ctx context.Context, feedID uuid.UUID, requestedBy *uuid.UUID) (*types.Job, error)
+}
+
+// JobRegistry is the part of the job service the GTFS import hooks into.
+type JobRegistry interface {
+	Register(kind string, fn jobs.JobFunc)
+}
+
+// JobQueue queues GTFS imports.
+type JobQueue interface {
+	Enqueue(ctx context.Context, req types.EnqueueJobRequest, requestedBy *uuid.UUID) (*types.Job, error)
+}
+
+type ServiceImpl struct {
+	logger *slog.Logger
+	repo   Repository
+	queue  JobQueue
+	client *http.Client
+}
+
+// NewService creates the travel service.
+func NewService(repo Repository, queue JobQueue, logger *slog.Logger) *ServiceImpl {
+	return &ServiceImpl{
+		logger: logger,
+		repo:   repo,
+		queue:  queue,
+		client: &http.Client{Timeout: downloadTimeout},
+	}
+}
+
+// Register installs the GTFS import job.
+func (s *ServiceImpl) Register(registry JobRegistry) {
+	registry.Register(types.JobKindGTFSImport, s.RunGTFSImport)
+}
+
+// Legs implements Service.
+func (s *ServiceImpl) Legs(ctx context.Context, pois []types.POIDetailedInfo, mode types.TransportPreference) []types.TravelLeg {
+	if len(pois) < 2 {
+		return nil
+	}
+	ctx, span := otel.Tracer("TravelService").Start(ctx, "Legs", trace.WithAttributes(
+		attribute.String("transport.mode", string(mode)),
+		attribute.Int("pois.count", len(pois)),
+	))
+	defer span.End()
+
+	legs := make([]types.TravelLeg, 0, len(pois)-1)
+	for i := 1; i < len(pois); i++ {
+		legs = append(legs, s.Estimate(ctx, pois[i-1], pois[i], mode))
+	}
+	return legs
+}
+
+// Estimate implements Service. Public transport legs use the quickest single
+// ride of an imported feed when one connects stops near both ends, and are
+// walked when that is quicker.
+func (s *ServiceImpl) Estimate(ctx context.Context, from, to types.POIDetailedInfo, mode types.TransportPreference) types.TravelLeg {
+	if !hasLocation(from) || !hasLocation(to) {
+		return types.TravelLeg{From: from.Name, To: to.Name, Mode: resolveMode(mode, 0), Source: types.TravelSourceUnknown}
+	}
+	meters := distanceMeters(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+	leg := heuristicLeg(from.Name, to.Name, meters, mode)
+	if leg.Mode != types.TransportPreferencePublic || s.repo == nil {
+		return leg
+	}
+
+	transit, err := s.transitLeg(ctx, from, to)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to look up transit ride, using heuristic",
+			slog.String("from", from.Name), slog.String("to", to.Name), slog.Any("error", err))
+		return leg
+	}
+	if transit == nil {
+		return leg
+	}
+	if walk := heuristicLeg(from.Name, to.Name, meters, types.TransportPreferenceWalk); walk.DurationMinutes <= transit.DurationMinutes {
+		return walk
+	}
+	transit.DistanceMeters = leg.DistanceMeters
+	return *transit
+}
+
+// transitLeg returns the quickest journey of walking to a stop, waiting, one
+// ride and walking on from the last stop, or nil when no ride connects stops
+// near both ends.
+func (s *ServiceImpl) transitLeg(ctx context.Context, from, to types.POIDetailedInfo) (*types.TravelLeg, error) {
+	origins, err := s.repo.NearbyStops(ctx, from.Latitude, from.Longitude, stopSearchMeters, stopsPerEnd)
+	if err != nil || len(origins) == 0 {
+		return nil, err
+	}
+	destinations, err := s.repo.NearbyStops(ctx, to.Latitude, to.Longitude, stopSearchMeters, stopsPerEnd)
+	if err != nil || len(destinations) == 0 {
+		return nil, err
+	}
+	rides, err := s.repo.Rides(ctx, origins, destinations)
+	if err != nil {
+		return nil, err
+	}
+
+	type stopKey struct {
+		feed uuid.UUID
+		id   string
+	}
+	originByKey := make(map[stopKey]types.TransitStop, len(origins))
+	for _, stop := range origins {
+		originByKey[stopKey{stop.FeedID, stop.StopID}] = stop
+	}
+	destinationByKey := make(map[stopKey]types.TransitStop, len(destinations))
+	for _, stop := range destinations {
+		destinationByKey[stopKey{stop.FeedID, stop.StopID}] = stop
+	}
+
+	var best *types.TravelLeg
+	bestMinutes := math.Inf(1)
+	for _, ride := range rides {
+		board, okBoard := originByKey[stopKey{ride.FeedID, ride.FromStopID}]
+		alight, okAlight := destinationByKey[stopKey{ride.FeedID, ride.ToStopID}]
+		if !okBoard || !okAlight {
+			continue
+		}
+		minutes := walkMinutes(board.WalkMeters) + transitWaitMinutes +
+			float64(ride.RideSeconds)/60 + walkMinutes(alight.WalkMeters)
+		if minutes < bestMinutes {
+			bestMinutes = minutes
+			best = &types.TravelLeg{
+				From:            from.Name,
+				To:              to.Name,
+				Mode:            types.TransportPreferencePublic,
+				DurationMinutes: int(math.Ceil(minutes)),
+				Source:          types.TravelSourceGTFS,
+				BoardAt:         board.Name,
+				AlightAt:        alight.Name,
+			}
+		}
+	}
+	return best, nil
+}
+
+// heuristicLeg estimates a leg from its straight-line distance.
+func heuristicLeg(fromName, toName string, meters float64, mode types.TransportPreference) types.TravelLeg {
+	mode = resolveMode(mode, meters)
+	routeKm := meters * detourFactor / 1000
+
+	var minutes float64
+	switch mode {
+	case types.TransportPreferencePublic:
+		minutes = routeKm/transitSpeedKmh*60 + transitOverheadMinutes
+	case types.TransportPreferenceCar:
+		minutes = routeKm/carSpeedKmh*60 + carOverheadMinutes
+	default:
+		minutes = routeKm / walkSpeedKmh * 60
+	}
+	return types.TravelLeg{
+		From:            fromName,
+		To:              toName,
+		Mode:            mode,
+		DistanceMeters:  math.Round(meters),
+		DurationMinutes: int(math.Ceil(minutes)),
+		Source:          types.TravelSourceHeuristic,
+	}
+}
+
+// resolveMode picks how a leg of the given length is travelled: short public
+// transport legs are walked, and with no preference the leg is walked when
+// short enough and otherwise taken by public transport.
+func resolveMode(mode types.TransportPreference, meters float64) types.TransportPreference {
+	switch mode {
+	case types.TransportPreferenceWalk, types.TransportPreferenceCar:
+		return mode
+	case types.TransportPreferencePublic:
+		if meters < minTransitMeters {
+			return types.TransportPreferenceWalk
+		}
+		return mode
+	default:
+		if meters <= maxAnyWalkMeters {
+			return types.TransportPreferenceWalk
+		}
+		return types.TransportPreferencePublic
+	}
+}
+
+func walkMinutes(meters float64) float64 {
+	return meters * detourFactor / 1000 / walkSpeedKmh * 60
+}
+
+func hasLocation(poi types.POIDetailedInfo) bool {
+	return poi.Latitude != 0 || poi.Longitude != 0
+}
+
+// distanceMeters is the haversine distance between two coordinates.
+func distanceMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusMeters = 6371000
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return earthRadiusMeters * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// ListFeeds implements Service.
+func (s *ServiceImpl) ListFeeds(ctx context.Context) ([]types.TransitFeed, error) {
+	return s.repo.ListFeeds(ctx)
+}
+
+// CreateFeed implements Service.
+func (s *ServiceImpl) CreateFeed(ctx context.Context, req types.CreateTransitFeedRequest, requestedBy *uuid.UUID) (*types.TransitFeed, error) {
+	ctx, span := otel.Tracer("TravelService").Start(ctx, "CreateFeed")
+	defer span.End()
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", types.ErrBadRequest)
+	}
+	if u, err := url.Parse(req.SourceURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: source_url must be an http(s) URL", types.ErrBadRequest)
+	}
+
+	feed, err := s.repo.CreateFeed(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to create feed")
+		return nil, err
+	}
+	job, err := s.ImportFeed(ctx, feed.ID, requestedBy)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to queue import")
+		return nil, err
+	}
+	feed.ImportJobID = &job.ID
+	span.SetStatus(codes.Ok, "Feed created")
+	return feed, nil
+}
+
+// ImportFeed implements Service.
+func (s *ServiceImpl) ImportFeed(ctx context.Context, feedID uuid.UUID, requestedBy *uuid.UUID) (*types.Job, error) {
+	if _, err := s.repo.GetFeed(ctx, feedID); err != nil {
+		return nil, err
+	}
+	return s.queue.Enqueue(ctx, types.EnqueueJobRequest{Kind: types.JobKindGTFSImport, TargetID: &feedID}, requestedBy)
+}
+
+// RunGTFSImport downloads the job's target feed and replaces its stops and rides.
+func (s *ServiceImpl) RunGTFSImport(ctx context.Context, job *types.Job, report jobs.ProgressFunc) error {
+	if job.TargetID == nil {
+		return fmt.Errorf("%w: a GTFS import needs a target feed", types.ErrBadRequest)
+	}
+	ctx, span := otel.Tracer("TravelService").Start(ctx, "RunGTFSImport", trace.WithAttributes(
+		attribute.String("feed.id", job.TargetID.String()),
+	))
+	defer span.End()
+
+	feed, err := s.repo.GetFeed(ctx, *job.TargetID)
+	if err != nil {
+		return err
+	}
+	progress := types.JobProgress{Total: 3}
+	report(progress)
+
+	path, err := s.download(ctx, feed.SourceURL)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	defer os.Remove(path)
+	progress.Done++
+	report(progress)
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("%w: feed is not a zip archive: %v", types.ErrBadRequest, err)
+	}
+	defer zr.Close()
+	stops, rides, err := parseGTFS(&zr.Reader, feed.ID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	progress.Done++
+	report(progress)
+
+	if err := s.repo.ReplaceFeedData(ctx, feed.ID, stops, rides); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	progress.Done++
+	report(progress)
+
+	s.logger.InfoContext(ctx, "Imported GTFS feed",
+		slog.String("feed_id", feed.ID.String()), slog.Int("stops", len(stops)), slog.Int("rides", len(rides)))
+	span.SetStatus(codes.Ok, "Feed imported")
+	return nil
+}
+
+// download saves the feed at sourceURL to a temporary file, since zip archives
+// need random access.
+func (s *ServiceImpl) download(ctx context.Context, sourceURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid feed URL: %v", types.ErrBadRequest, err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download feed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("feed download returned status %d", resp.StatusCode)
+	}
+
+	f, err := os.CreateTemp("", "gtfs-*.zip")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer f.Close()
+	n, err := io.Copy(f, io.LimitReader(resp.Body, maxFeedBytes+1))
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to download feed: %w", err)
+	}
+	if n > maxFeedBytes {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("%w: feed is larger than %d MB", types.ErrBadRequest, maxFeedBytes>>20)
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,413 @@
+package travel
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+// MockTravelRepository is a mock implementation of Repository
+type MockTravelRepository struct {
+	mock.Mock
+}
+
+func (m *MockTravelRepository) CreateFeed(ctx context.Context, req types.CreateTransitFeedRequest) (*types.TransitFeed, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.TransitFeed), args.Error(1)
+}
+
+func (m *MockTravelRepository) GetFeed(ctx context.Context, feedID uuid.UUID) (*types.TransitFeed, error) {
+	args := m.Called(ctx, feedID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.TransitFeed), args.Error(1)
+}
+
+func (m *MockTravelRepository) ListFeeds(ctx context.Context) ([]types.TransitFeed, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.TransitFeed), args.Error(1)
+}
+
+func (m *MockTravelRepository) ReplaceFeedData(ctx context.Context, feedID uuid.UUID, stops []types.TransitStop, rides []types.TransitRide) error {
+	return m.Called(ctx, feedID, stops, rides).Error(0)
+}
+
+func (m *MockTravelRepository) NearbyStops(ctx context.Context, lat, lon, radiusMeters float64, limit int) ([]types.TransitStop, error) {
+	args := m.Called(ctx, lat, lon, radiusMeters, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.TransitStop), args.Error(1)
+}
+
+func (m *MockTravelRepository) Rides(ctx context.Context, from, to []types.TransitStop) ([]types.TransitRide, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.TransitRide), args.Error(1)
+}
+
+// MockJobQueue is a mock implementation of JobQueue
+type MockJobQueue struct {
+	mock.Mock
+}
+
+func (m *MockJobQueue) Enqueue(ctx context.Context, req types.EnqueueJobRequest, requestedBy *uuid.UUID) (*types.Job, error) {
+	args := m.Called(ctx, req, requestedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Job), args.Error(1)
+}
+
+func setupTravelServiceTest() (*ServiceImpl, *MockTravelRepository, *MockJobQueue) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	repo := new(MockTravelRepository)
+	queue := new(MockJobQueue)
+	return NewService(repo, queue, logger), repo, queue
+}
+
+// About 3 km apart, due north.
+var (
+	baixa    = types.POIDetailedInfo{Name: "Baixa", Latitude: 38.7000, Longitude: -9.1400}
+	saldanha = types.POIDetailedInfo{Name: "Saldanha", Latitude: 38.7270, Longitude: -9.1400}
+)
+
+func TestHeuristicLeg(t *testing.T) {
+	meters := distanceMeters(baixa.Latitude, baixa.Longitude, saldanha.Latitude, saldanha.Longitude)
+	require.InDelta(t, 3002, meters, 5)
+
+	tests := []struct {
+		mode    types.TransportPreference
+		want    types.TransportPreference
+		minutes int
+	}{
+		{types.TransportPreferenceWalk, types.TransportPreferenceWalk, 49},
+		{types.TransportPreferencePublic, types.TransportPreferencePublic, 20},
+		{types.TransportPreferenceCar, types.TransportPreferenceCar, 15},
+		{types.TransportPreferenceAny, types.TransportPreferencePublic, 20},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			leg := heuristicLeg(baixa.Name, saldanha.Name, meters, tt.mode)
+			assert.Equal(t, tt.want, leg.Mode)
+			assert.Equal(t, tt.minutes, leg.DurationMinutes)
+			assert.Equal(t, types.TravelSourceHeuristic, leg.Source)
+			assert.Equal(t, "Baixa", leg.From)
+			assert.Equal(t, "Saldanha", leg.To)
+		})
+	}
+}
+
+func TestResolveMode(t *testing.T) {
+	assert.Equal(t, types.TransportPreferenceWalk, resolveMode(types.TransportPreferencePublic, 500))
+	assert.Equal(t, types.TransportPreferencePublic, resolveMode(types.TransportPreferencePublic, 2000))
+	assert.Equal(t, types.TransportPreferenceWalk, resolveMode(types.TransportPreferenceAny, 1200))
+	assert.Equal(t, types.TransportPreferencePublic, resolveMode(types.TransportPreferenceAny, 1600))
+	assert.Equal(t, types.TransportPreferenceWalk, resolveMode("", 1400))
+	assert.Equal(t, types.TransportPreferenceCar, resolveMode(types.TransportPreferenceCar, 100))
+}
+
+func TestService_Legs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("one leg per consecutive pair without touching the repository for walks", func(t *testing.T) {
+		service, repo, _ := setupTravelServiceTest()
+		middle := types.POIDetailedInfo{Name: "Rossio", Latitude: 38.7140, Longitude: -9.1400}
+
+		legs := service.Legs(ctx, []types.POIDetailedInfo{baixa, middle, saldanha}, types.TransportPreferenceWalk)
+
+		require.Len(t, legs, 2)
+		assert.Equal(t, "Baixa", legs[0].From)
+		assert.Equal(t, "Rossio", legs[0].To)
+		assert.Equal(t, "Rossio", legs[1].From)
+		assert.Equal(t, "Saldanha", legs[1].To)
+		assert.Equal(t, legs[0].DurationMinutes+legs[1].DurationMinutes, types.TotalTravelMinutes(legs))
+		repo.AssertNotCalled(t, "NearbyStops", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("fewer than two stops have no legs", func(t *testing.T) {
+		service, _, _ := setupTravelServiceTest()
+		assert.Nil(t, service.Legs(ctx, []types.POIDetailedInfo{baixa}, types.TransportPreferenceWalk))
+	})
+
+	t.Run("stops without coordinates give an unknown leg", func(t *testing.T) {
+		service, _, _ := setupTravelServiceTest()
+		legs := service.Legs(ctx, []types.POIDetailedInfo{baixa, {Name: "Somewhere"}}, types.TransportPreferenceCar)
+
+		require.Len(t, legs, 1)
+		assert.Equal(t, types.TravelSourceUnknown, legs[0].Source)
+		assert.Equal(t, types.TransportPreferenceCar, legs[0].Mode)
+		assert.Zero(t, legs[0].DurationMinutes)
+	})
+}
+
+func TestService_Estimate_Transit(t *testing.T) {
+	ctx := context.Background()
+	feedID := uuid.New()
+	origins := []types.TransitStop{
+		{FeedID: feedID, StopID: "A", Name: "Terreiro do Paço", WalkMeters: 200},
+		{FeedID: feedID, StopID: "A2", Name: "Cais do Sodré", WalkMeters: 700},
+	}
+	destinations := []types.TransitStop{
+		{FeedID: feedID, StopID: "B", Name: "Saldanha", WalkMeters: 300},
+	}
+
+	t.Run("uses the quickest timetabled ride", func(t *testing.T) {
+		service, repo, _ := setupTravelServiceTest()
+		repo.On("NearbyStops", mock.Anything, baixa.Latitude, baixa.Longitude, float64(stopSearchMeters), stopsPerEnd).Return(origins, nil)
+		repo.On("NearbyStops", mock.Anything, saldanha.Latitude, saldanha.Longitude, float64(stopSearchMeters), stopsPerEnd).Return(destinations, nil)
+		repo.On("Rides", mock.Anything, origins, destinations).Return([]types.TransitRide{
+			{FeedID: feedID, FromStopID: "A2", ToStopID: "B", RideSeconds: 300},
+			{FeedID: feedID, FromStopID: "A", ToStopID: "B", RideSeconds: 420},
+			{FeedID: uuid.New(), FromStopID: "A", ToStopID: "B", RideSeconds: 60}, // Another feed's stop A
+		}, nil)
+
+		leg := service.Estimate(ctx, baixa, saldanha, types.TransportPreferencePublic)
+
+		// 200 m walk (3.25 min) + 5 min wait + 7 min ride + 300 m walk (4.875 min)
+		assert.Equal(t, types.TravelSourceGTFS, leg.Source)
+		assert.Equal(t, types.TransportPreferencePublic, leg.Mode)
+		assert.Equal(t, 21, leg.DurationMinutes)
+		assert.Equal(t, "Terreiro do Paço", leg.BoardAt)
+		assert.Equal(t, "Saldanha", leg.AlightAt)
+		assert.InDelta(t, 3002, leg.DistanceMeters, 5)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("walks when that is no slower than the ride", func(t *testing.T) {
+		service, repo, _ := setupTravelServiceTest()
+		near := types.POIDetailedInfo{Name: "Marquês", Latitude: 38.7081, Longitude: -9.1400} // ~900 m
+		repo.On("NearbyStops", mock.Anything, baixa.Latitude, baixa.Longitude, mock.Anything, mock.Anything).Return(origins, nil)
+		repo.On("NearbyStops", mock.Anything, near.Latitude, near.Longitude, mock.Anything, mock.Anything).Return(destinations, nil)
+		repo.On("Rides", mock.Anything, origins, destinations).Return([]types.TransitRide{
+			{FeedID: feedID, FromStopID: "A", ToStopID: "B", RideSeconds: 1800},
+		}, nil)
+
+		leg := service.Estimate(ctx, baixa, near, types.TransportPreferencePublic)
+
+		assert.Equal(t, types.TransportPreferenceWalk, leg.Mode)
+		assert.Equal(t, types.TravelSourceHeuristic, leg.Source)
+		assert.Equal(t, 15, leg.DurationMinutes)
+	})
+
+	t.Run("falls back to the heuristic without nearby stops", func(t *testing.T) {
+		service, repo, _ := setupTravelServiceTest()
+		repo.On("NearbyStops", mock.Anything, baixa.Latitude, baixa.Longitude, mock.Anything, mock.Anything).Return([]types.TransitStop{}, nil)
+
+		leg := service.Estimate(ctx, baixa, saldanha, types.TransportPreferencePublic)
+
+		assert.Equal(t, types.TravelSourceHeuristic, leg.Source)
+		assert.Equal(t, 20, leg.DurationMinutes)
+		repo.AssertNotCalled(t, "Rides", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("falls back to the heuristic when the lookup fails", func(t *testing.T) {
+		service, repo, _ := setupTravelServiceTest()
+		repo.On("NearbyStops", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("db down"))
+
+		leg := service.Estimate(ctx, baixa, saldanha, types.TransportPreferencePublic)
+
+		assert.Equal(t, types.TravelSourceHeuristic, leg.Source)
+		assert.Equal(t, types.TransportPreferencePublic, leg.Mode)
+	})
+}
+
+func TestService_CreateFeed(t *testing.T) {
+	ctx := context.Background()
+	adminID := uuid.New()
+
+	t.Run("validates the request", func(t *testing.T) {
+		service, repo, _ := setupTravelServiceTest()
+		for _, req := range []types.CreateTransitFeedRequest{
+			{Name: " ", SourceURL: "https://example.com/gtfs.zip"},
+			{Name: "Carris", SourceURL: "ftp://example.com/gtfs.zip"},
+			{Name: "Carris", SourceURL: "not a url"},
+		} {
+			_, err := service.CreateFeed(ctx, req, &adminID)
+			assert.ErrorIs(t, err, types.ErrBadRequest)
+		}
+		repo.AssertNotCalled(t, "CreateFeed", mock.Anything, mock.Anything)
+	})
+
+	t.Run("queues the first import", func(t *testing.T) {
+		service, repo, queue := setupTravelServiceTest()
+		feed := &types.TransitFeed{ID: uuid.New(), Name: "Carris", SourceURL: "https://example.com/gtfs.zip"}
+		job := &types.Job{ID: uuid.New(), Kind: types.JobKindGTFSImport}
+		repo.On("CreateFeed", mock.Anything, types.CreateTransitFeedRequest{Name: "Carris", SourceURL: feed.SourceURL}).Return(feed, nil)
+		repo.On("GetFeed", mock.Anything, feed.ID).Return(feed, nil)
+		queue.On("Enqueue", mock.Anything, types.EnqueueJobRequest{Kind: types.JobKindGTFSImport, TargetID: &feed.ID}, &adminID).Return(job, nil)
+
+		created, err := service.CreateFeed(ctx, types.CreateTransitFeedRequest{Name: " Carris ", SourceURL: feed.SourceURL}, &adminID)
+
+		require.NoError(t, err)
+		require.NotNil(t, created.ImportJobID)
+		assert.Equal(t, job.ID, *created.ImportJobID)
+		repo.AssertExpectations(t)
+		queue.AssertExpectations(t)
+	})
+}
+
+func TestService_ImportFeed_NotFound(t *testing.T) {
+	service, repo, queue := setupTravelServiceTest()
+	feedID := uuid.New()
+	repo.On("GetFeed", mock.Anything, feedID).Return(nil, types.ErrNotFound)
+
+	_, err := service.ImportFeed(context.Background(), feedID, nil)
+
+	assert.ErrorIs(t, err, types.ErrNotFound)
+	queue.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// buildFeed zips the given files into a GTFS feed.
+func buildFeed(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+var sampleFeed = map[string]string{
+	"stops.txt": "\ufeffstop_id,stop_name,stop_lat,stop_lon\n" +
+		"S1,Baixa,38.7100,-9.1370\n" +
+		"S2,Rossio,38.7140,-9.1390\n" +
+		"S3,Saldanha,38.7350,-9.1450\n" +
+		"P1,Parent station,,\n",
+	"stop_times.txt": "trip_id,arrival_time,departure_time,stop_id,stop_sequence\n" +
+		// Listed out of order; sequence decides
+		"T1,08:12:00,08:12:00,S3,3\n" +
+		"T1,08:00:00,08:00:00,S1,1\n" +
+		"T1,08:05:00,08:06:00,S2,2\n" +
+		// Quicker between S1 and S2
+		"T2,09:00:00,09:00:00,S1,1\n" +
+		"T2,09:04:00,09:04:00,S2,2\n" +
+		// Past midnight, same pace as T1
+		"T3,24:10:00,24:10:00,S1,1\n" +
+		"T3,,,S2,2\n" +
+		"T3,24:22:00,24:22:00,S3,3\n" +
+		"T4,10:00:00,10:00:00,P1,1\n",
+}
+
+func TestParseGTFS(t *testing.T) {
+	feedID := uuid.New()
+	data := buildFeed(t, sampleFeed)
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	stops, rides, err := parseGTFS(zr, feedID)
+
+	require.NoError(t, err)
+	require.Len(t, stops, 3)
+	assert.Equal(t, "S1", stops[0].StopID)
+	assert.Equal(t, "Baixa", stops[0].Name)
+	assert.Equal(t, feedID, stops[0].FeedID)
+
+	sort.Slice(rides, func(i, j int) bool {
+		return rides[i].FromStopID+rides[i].ToStopID < rides[j].FromStopID+rides[j].ToStopID
+	})
+	assert.Equal(t, []types.TransitRide{
+		{FeedID: feedID, FromStopID: "S1", ToStopID: "S2", RideSeconds: 240},
+		{FeedID: feedID, FromStopID: "S1", ToStopID: "S3", RideSeconds: 720},
+		{FeedID: feedID, FromStopID: "S2", ToStopID: "S3", RideSeconds: 360},
+	}, rides)
+}
+
+func TestParseGTFS_Invalid(t *testing.T) {
+	tests := map[string]map[string]string{
+		"missing stop_times": {"stops.txt": sampleFeed["stops.txt"]},
+		"missing column": {
+			"stops.txt":      "stop_id,stop_name,stop_lat\nS1,Baixa,38.7\n",
+			"stop_times.txt": sampleFeed["stop_times.txt"],
+		},
+	}
+	for name, files := range tests {
+		t.Run(name, func(t *testing.T) {
+			data := buildFeed(t, files)
+			zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+			require.NoError(t, err)
+
+			_, _, err = parseGTFS(zr, uuid.New())
+			assert.ErrorIs(t, err, types.ErrBadRequest)
+		})
+	}
+}
+
+func TestParseGTFSTime(t *testing.T) {
+	seconds, ok := parseGTFSTime("25:01:30")
+	assert.True(t, ok)
+	assert.Equal(t, int32(25*3600+90), seconds)
+
+	for _, value := range []string{"", "8:00", "aa:00:00", "-1:00:00"} {
+		_, ok := parseGTFSTime(value)
+		assert.False(t, ok, value)
+	}
+}
+
+func TestService_RunGTFSImport(t *testing.T) {
+	data := buildFeed(t, sampleFeed)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	service, repo, _ := setupTravelServiceTest()
+	feed := &types.TransitFeed{ID: uuid.New(), Name: "Carris", SourceURL: server.URL + "/gtfs.zip"}
+	repo.On("GetFeed", mock.Anything, feed.ID).Return(feed, nil)
+	repo.On("ReplaceFeedData", mock.Anything, feed.ID,
+		mock.MatchedBy(func(stops []types.TransitStop) bool { return len(stops) == 3 }),
+		mock.MatchedBy(func(rides []types.TransitRide) bool { return len(rides) == 3 }),
+	).Return(nil)
+
+	var reports []types.JobProgress
+	err := service.RunGTFSImport(context.Background(), &types.Job{ID: uuid.New(), TargetID: &feed.ID}, func(p types.JobProgress) {
+		reports = append(reports, p)
+	})
+
+	require.NoError(t, err)
+	require.NotEmpty(t, reports)
+	assert.Equal(t, types.JobProgress{Total: 3, Done: 3}, reports[len(reports)-1])
+	repo.AssertExpectations(t)
+}
+
+func TestService_RunGTFSImport_DownloadFails(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	service, repo, _ := setupTravelServiceTest()
+	feed := &types.TransitFeed{ID: uuid.New(), SourceURL: server.URL}
+	repo.On("GetFeed", mock.Anything, feed.ID).Return(feed, nil)
+
+	err := service.RunGTFSImport(context.Background(), &types.Job{TargetID: &feed.ID}, func(types.JobProgress) {})
+
+	assert.Error(t, err)
+	repo.AssertNotCalled(t, "ReplaceFeedData", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/audit"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types" // Adjust path
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -57,7 +58,7 @@ func TestMain(m *testing.M) {
 	// testUserRepo = repository.NewPostgresUserRepo(testUserDB, logger)
 	// For this example, let's assume a constructor NewPostgresUserRepo exists in the current 'user' package for the repo.
 	testUserRepo = NewPostgresUserRepo(testUserDB, logger) // Replace with your actual repo constructor
-	testUserService = NewUserService(testUserRepo, logger)
+	testUserService = NewUserService(testUserRepo, audit.NopRecorder{}, logger)
 
 	exitCode := m.Run()
 	os.Exit(exitCode)
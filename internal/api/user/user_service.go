@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/audit"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
 )
 
@@ -30,13 +31,15 @@ type UserService interface {
 type ServiceUserImpl struct {
 	logger *slog.Logger
 	repo   UserRepo
+	audit  audit.Recorder
 }
 
 // NewUserService creates a new user service instance.
-func NewUserService(repo UserRepo, logger *slog.Logger) *ServiceUserImpl {
+func NewUserService(repo UserRepo, auditor audit.Recorder, logger *slog.Logger) *ServiceUserImpl {
 	return &ServiceUserImpl{
 		logger: logger,
 		repo:   repo,
+		audit:  auditor,
 	}
 }
 
@@ -60,12 +63,32 @@ func (s *ServiceUserImpl) UpdateUserProfile(ctx context.Context, userID uuid.UUI
 	l := s.logger.With(slog.String("method", "UpdateUserProfile"), slog.String("userID", userID.String()))
 	l.DebugContext(ctx, "Updating user profile")
 
-	err := s.repo.UpdateProfile(ctx, userID, params)
+	// Snapshot for the audit diff; a failed read must not block the update
+	before, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		l.WarnContext(ctx, "Failed to load profile for audit diff", slog.Any("error", err))
+		before = nil
+	}
+
+	err = s.repo.UpdateProfile(ctx, userID, params)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to update user profile", slog.Any("error", err))
 		return fmt.Errorf("error updating user profile: %w", err)
 	}
 
+	action := types.AuditActionProfileUpdated
+	if params.Email != nil && (before == nil || before.Email != *params.Email) {
+		action = types.AuditActionEmailChanged
+	}
+	s.audit.Record(ctx, types.AuditEntry{
+		ActorID:    &userID,
+		Action:     action,
+		TargetType: types.AuditTargetUser,
+		TargetID:   &userID,
+		Before:     before,
+		After:      params,
+	})
+
 	l.InfoContext(ctx, "User profile updated successfully")
 	return nil
 }
@@ -111,6 +134,13 @@ func (s *ServiceUserImpl) DeactivateUser(ctx context.Context, userID uuid.UUID)
 		return fmt.Errorf("error deactivating user: %w", err)
 	}
 
+	s.audit.Record(ctx, types.AuditEntry{
+		ActorID:    &userID,
+		Action:     types.AuditActionUserDeactivated,
+		TargetType: types.AuditTargetUser,
+		TargetID:   &userID,
+	})
+
 	l.InfoContext(ctx, "User deactivated successfully")
 	return nil
 }
@@ -126,6 +156,13 @@ func (s *ServiceUserImpl) ReactivateUser(ctx context.Context, userID uuid.UUID)
 		return fmt.Errorf("error reactivating user: %w", err)
 	}
 
+	s.audit.Record(ctx, types.AuditEntry{
+		ActorID:    &userID,
+		Action:     types.AuditActionUserReactivated,
+		TargetType: types.AuditTargetUser,
+		TargetID:   &userID,
+	})
+
 	l.InfoContext(ctx, "User reactivated successfully")
 	return nil
 }
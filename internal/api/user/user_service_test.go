@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	// For potentially testing UpdateLastLogin with time
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/audit"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types" // Ensure this path is correct
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -63,7 +64,7 @@ func (m *MockUserRepo) ChangePassword(ctx context.Context, email, oldPassword, n
 func setupUserServiceTest() (*ServiceUserImpl, *MockUserRepo) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})) // or io.Discard
 	mockRepo := new(MockUserRepo)
-	service := NewUserService(mockRepo, logger)
+	service := NewUserService(mockRepo, audit.NopRecorder{}, logger)
 	return service, mockRepo
 }
 
@@ -132,6 +133,7 @@ func TestServiceUserImpl_UpdateUserProfile(t *testing.T) {
 	}
 
 	t.Run("success", func(t *testing.T) {
+		mockRepo.On("GetUserByID", ctx, userID).Return(&types.UserProfile{ID: userID}, nil).Once()
 		mockRepo.On("UpdateProfile", ctx, userID, params).Return(nil).Once()
 
 		err := service.UpdateUserProfile(ctx, userID, params)
@@ -141,6 +143,7 @@ func TestServiceUserImpl_UpdateUserProfile(t *testing.T) {
 
 	t.Run("repository error", func(t *testing.T) {
 		expectedErr := errors.New("db error on update profile")
+		mockRepo.On("GetUserByID", ctx, userID).Return(&types.UserProfile{ID: userID}, nil).Once()
 		mockRepo.On("UpdateProfile", ctx, userID, params).Return(expectedErr).Once()
 
 		err := service.UpdateUserProfile(ctx, userID, params)
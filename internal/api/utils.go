@@ -7,10 +7,14 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/go-chi/chi/v5/middleware" // For RequestID
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
 )
 
 // ErrorResponse writes a standard JSON error response including request ID.
@@ -63,6 +67,36 @@ func WriteJSONResponse(w http.ResponseWriter, r *http.Request, status int, data
 	}
 }
 
+// WriteServiceError maps the domain errors of a service to HTTP status codes.
+// Anything else is a 500 with the fallback message, so internal details stay hidden.
+func WriteServiceError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	switch {
+	case errors.Is(err, types.ErrNotFound):
+		ErrorResponse(w, r, http.StatusNotFound, "Resource not found")
+	case errors.Is(err, types.ErrBadRequest):
+		ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+	case errors.Is(err, types.ErrForbidden):
+		ErrorResponse(w, r, http.StatusForbidden, err.Error())
+	case errors.Is(err, types.ErrConflict):
+		ErrorResponse(w, r, http.StatusConflict, err.Error())
+	default:
+		ErrorResponse(w, r, http.StatusInternalServerError, fallback)
+	}
+}
+
+// Requester parses the authenticated user ID, as returned by
+// auth.GetUserIDFromContext. It is nil if the ID is missing or malformed.
+func Requester(userID string, ok bool) *uuid.UUID {
+	if !ok || userID == "" {
+		return nil
+	}
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
 // DecodeJSONBody reads and decodes a JSON request body safely.
 func DecodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) error {
 	// Set a max body size to prevent abuse (e.g., 1MB)
@@ -125,6 +159,62 @@ func DecodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) err
 	return nil
 }
 
+// ParsePageRequest reads the cursor and limit query parameters of a collection
+// endpoint. A missing or invalid limit is left at 0 for the service default.
+func ParsePageRequest(r *http.Request) (types.PageRequest, error) {
+	q := r.URL.Query()
+	var page types.PageRequest
+	page.Limit, _ = strconv.Atoi(q.Get("limit"))
+	if token := q.Get("cursor"); token != "" {
+		page.Cursor = new(types.Cursor)
+		if err := page.Cursor.UnmarshalText([]byte(token)); err != nil {
+			return page, err
+		}
+	}
+	return page, nil
+}
+
+// QueryList reads a multi-valued query parameter, given either repeated
+// (amenity=wifi&amenity=pool) or comma separated (amenity=wifi,pool).
+func QueryList(r *http.Request, name string) []string {
+	var values []string
+	for _, raw := range r.URL.Query()[name] {
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+// SetPageLinks sets the Link header of a page of results: rel="next" when
+// another page follows, and rel="first" past the first page. The links keep
+// the request's other query parameters, so filters carry over.
+func SetPageLinks(w http.ResponseWriter, r *http.Request, next *types.Cursor) {
+	link := func(cursor *types.Cursor, rel string) string {
+		u := *r.URL
+		q := u.Query()
+		q.Del("cursor")
+		if cursor != nil {
+			q.Set("cursor", cursor.String())
+		}
+		u.RawQuery = q.Encode()
+		return fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), rel)
+	}
+
+	var links []string
+	if next != nil {
+		links = append(links, link(next, "next"))
+	}
+	if r.URL.Query().Get("cursor") != "" {
+		links = append(links, link(nil, "first"))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
 func VerifyAudience(claimsAudience jwt.ClaimStrings, expectedAudience string) bool {
 	// If no audience is expected, validation passes (or fails, depending on policy)
 	if expectedAudience == "" {
@@ -0,0 +1,41 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ WeatherProvider = (*FileProvider)(nil)
+
+// FileProvider serves the same forecast, read from a JSON fixture, for every
+// location. It is meant for tests and offline development.
+type FileProvider struct {
+	forecast types.WeatherForecast
+}
+
+// NewFileProvider reads the forecast fixture at path.
+func NewFileProvider(path string) (*FileProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read weather fixture: %w", err)
+	}
+	var forecast types.WeatherForecast
+	if err := json.Unmarshal(data, &forecast); err != nil {
+		return nil, fmt.Errorf("failed to parse weather fixture %s: %w", path, err)
+	}
+	return &FileProvider{forecast: forecast}, nil
+}
+
+// Forecast returns the fixture forecast, placed at lat, lon.
+func (p *FileProvider) Forecast(_ context.Context, lat, lon float64) (*types.WeatherForecast, error) {
+	forecast := p.forecast
+	forecast.Latitude = lat
+	forecast.Longitude = lon
+	forecast.Source = ProviderFile
+	forecast.Days = append([]types.DailyForecast(nil), p.forecast.Days...)
+	return &forecast, nil
+}
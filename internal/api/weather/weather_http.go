@@ -0,0 +1,161 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/patrickmn/go-cache"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/config"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+var _ WeatherProvider = (*HTTPProvider)(nil)
+
+// HTTPProvider fetches forecasts from an Open-Meteo compatible API. Forecasts
+// are cached per location, rounded to about a kilometre.
+type HTTPProvider struct {
+	logger  *slog.Logger
+	client  *http.Client
+	baseURL string
+	days    int
+	cache   *cache.Cache
+}
+
+// NewHTTPProvider creates a provider for the API at cfg.BaseURL.
+func NewHTTPProvider(cfg config.WeatherConfig, logger *slog.Logger) *HTTPProvider {
+	return &HTTPProvider{
+		logger:  logger,
+		client:  &http.Client{Timeout: cfg.Timeout},
+		baseURL: cfg.BaseURL,
+		days:    cfg.ForecastDays,
+		cache:   cache.New(cfg.CacheTTL, 2*cfg.CacheTTL),
+	}
+}
+
+// openMeteoResponse is the part of the Open-Meteo forecast response we use.
+type openMeteoResponse struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Timezone  string  `json:"timezone"`
+	Daily     struct {
+		Time                        []string  `json:"time"`
+		WeatherCode                 []int     `json:"weather_code"`
+		TemperatureMax              []float64 `json:"temperature_2m_max"`
+		TemperatureMin              []float64 `json:"temperature_2m_min"`
+		PrecipitationSum            []float64 `json:"precipitation_sum"`
+		PrecipitationProbabilityMax []float64 `json:"precipitation_probability_max"`
+	} `json:"daily"`
+}
+
+// Forecast fetches the forecast at lat, lon, or returns the cached one.
+func (p *HTTPProvider) Forecast(ctx context.Context, lat, lon float64) (*types.WeatherForecast, error) {
+	ctx, span := otel.Tracer("WeatherProvider").Start(ctx, "Forecast", trace.WithAttributes(
+		attribute.Float64("latitude", lat),
+		attribute.Float64("longitude", lon),
+	))
+	defer span.End()
+
+	cacheKey := fmt.Sprintf("%.2f:%.2f", lat, lon)
+	if cached, found := p.cache.Get(cacheKey); found {
+		span.AddEvent("Cache hit")
+		return cached.(*types.WeatherForecast), nil
+	}
+
+	query := url.Values{}
+	query.Set("latitude", strconv.FormatFloat(lat, 'f', 4, 64))
+	query.Set("longitude", strconv.FormatFloat(lon, 'f', 4, 64))
+	query.Set("daily", "weather_code,temperature_2m_max,temperature_2m_min,precipitation_sum,precipitation_probability_max")
+	query.Set("timezone", "auto")
+	query.Set("forecast_days", strconv.Itoa(p.days))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"?"+query.Encode(), nil)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to build request")
+		return nil, fmt.Errorf("failed to build weather request: %w", err)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Request failed")
+		return nil, fmt.Errorf("weather request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("weather API returned status %d", resp.StatusCode)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Unexpected status")
+		return nil, err
+	}
+
+	var body openMeteoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to decode response")
+		return nil, fmt.Errorf("failed to decode weather response: %w", err)
+	}
+
+	forecast := &types.WeatherForecast{
+		Latitude:  body.Latitude,
+		Longitude: body.Longitude,
+		Timezone:  body.Timezone,
+		Source:    ProviderHTTP,
+	}
+	daily := body.Daily
+	for i, date := range daily.Time {
+		day := types.DailyForecast{Date: date, Condition: types.WeatherClear}
+		if i < len(daily.WeatherCode) {
+			day.Condition = conditionFromWMO(daily.WeatherCode[i])
+		}
+		if i < len(daily.TemperatureMin) {
+			day.TempMinC = daily.TemperatureMin[i]
+		}
+		if i < len(daily.TemperatureMax) {
+			day.TempMaxC = daily.TemperatureMax[i]
+		}
+		if i < len(daily.PrecipitationSum) {
+			day.PrecipitationMm = daily.PrecipitationSum[i]
+		}
+		if i < len(daily.PrecipitationProbabilityMax) {
+			day.PrecipitationProbability = int(daily.PrecipitationProbabilityMax[i])
+		}
+		forecast.Days = append(forecast.Days, day)
+	}
+
+	p.cache.Set(cacheKey, forecast, cache.DefaultExpiration)
+	p.logger.DebugContext(ctx, "Fetched weather forecast", slog.String("location", cacheKey), slog.Int("days", len(forecast.Days)))
+	span.SetAttributes(attribute.Int("forecast.days", len(forecast.Days)))
+	span.SetStatus(codes.Ok, "Forecast fetched")
+	return forecast, nil
+}
+
+// conditionFromWMO maps a WMO weather interpretation code to a condition.
+func conditionFromWMO(code int) string {
+	switch {
+	case code <= 1:
+		return types.WeatherClear
+	case code <= 3:
+		return types.WeatherCloudy
+	case code == 45 || code == 48:
+		return types.WeatherFog
+	case code >= 51 && code <= 67, code >= 80 && code <= 82:
+		return types.WeatherRain
+	case code >= 71 && code <= 77, code == 85 || code == 86:
+		return types.WeatherSnow
+	case code >= 95:
+		return types.WeatherStorm
+	default:
+		return types.WeatherCloudy
+	}
+}
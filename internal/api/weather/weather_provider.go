@@ -0,0 +1,52 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/config"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+const (
+	ProviderHTTP = "http"
+	ProviderFile = "file"
+
+	defaultBaseURL      = "https://api.open-meteo.com/v1/forecast"
+	defaultTimeout      = 5 * time.Second
+	defaultCacheTTL     = 30 * time.Minute
+	defaultForecastDays = 3
+)
+
+// WeatherProvider returns daily forecasts for a location.
+type WeatherProvider interface {
+	// Forecast returns the forecast at lat, lon, today first.
+	Forecast(ctx context.Context, lat, lon float64) (*types.WeatherForecast, error)
+}
+
+// NewProvider creates the provider selected by cfg. Zero config values fall back to defaults.
+func NewProvider(cfg config.WeatherConfig, logger *slog.Logger) (WeatherProvider, error) {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultBaseURL
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = defaultCacheTTL
+	}
+	if cfg.ForecastDays <= 0 {
+		cfg.ForecastDays = defaultForecastDays
+	}
+
+	switch cfg.Provider {
+	case "", ProviderHTTP:
+		return NewHTTPProvider(cfg, logger), nil
+	case ProviderFile:
+		return NewFileProvider(cfg.FixtureFile)
+	default:
+		return nil, fmt.Errorf("unknown weather provider %q", cfg.Provider)
+	}
+}
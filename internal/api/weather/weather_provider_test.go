@@ -0,0 +1,128 @@
+package weather
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/go-poi-au-suggestions/config"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+const openMeteoBody = `{
+	"latitude": 38.72,
+	"longitude": -9.14,
+	"timezone": "Europe/Lisbon",
+	"daily": {
+		"time": ["2025-11-03", "2025-11-04"],
+		"weather_code": [63, 2],
+		"temperature_2m_max": [16.4, 18.1],
+		"temperature_2m_min": [12.2, 11.0],
+		"precipitation_sum": [14.2, 0.0],
+		"precipitation_probability_max": [90, null]
+	}
+}`
+
+func TestHTTPProvider_Forecast(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		assert.Equal(t, "38.7223", r.URL.Query().Get("latitude"))
+		assert.Equal(t, "-9.1393", r.URL.Query().Get("longitude"))
+		assert.Equal(t, "2", r.URL.Query().Get("forecast_days"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(openMeteoBody))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(config.WeatherConfig{BaseURL: server.URL, ForecastDays: 2}, testLogger())
+	require.NoError(t, err)
+
+	forecast, err := provider.Forecast(context.Background(), 38.7223, -9.1393)
+	require.NoError(t, err)
+	assert.Equal(t, ProviderHTTP, forecast.Source)
+	assert.Equal(t, "Europe/Lisbon", forecast.Timezone)
+	require.Len(t, forecast.Days, 2)
+	assert.Equal(t, types.DailyForecast{
+		Date:                     "2025-11-03",
+		Condition:                types.WeatherRain,
+		TempMinC:                 12.2,
+		TempMaxC:                 16.4,
+		PrecipitationMm:          14.2,
+		PrecipitationProbability: 90,
+	}, forecast.Days[0])
+	assert.Equal(t, types.WeatherCloudy, forecast.Days[1].Condition)
+	assert.Zero(t, forecast.Days[1].PrecipitationProbability)
+
+	t.Run("nearby location is served from cache", func(t *testing.T) {
+		_, err := provider.Forecast(context.Background(), 38.7223, -9.1393)
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+}
+
+func TestHTTPProvider_ForecastError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	provider := NewHTTPProvider(config.WeatherConfig{BaseURL: server.URL, Timeout: time.Second, CacheTTL: time.Minute, ForecastDays: 1}, testLogger())
+	forecast, err := provider.Forecast(context.Background(), 1, 2)
+	assert.Error(t, err)
+	assert.Nil(t, forecast)
+}
+
+func TestFileProvider_Forecast(t *testing.T) {
+	provider, err := NewProvider(config.WeatherConfig{Provider: ProviderFile, FixtureFile: "testdata/rainy.json"}, testLogger())
+	require.NoError(t, err)
+
+	forecast, err := provider.Forecast(context.Background(), 41.15, -8.61)
+	require.NoError(t, err)
+	assert.Equal(t, ProviderFile, forecast.Source)
+	assert.Equal(t, 41.15, forecast.Latitude)
+	assert.Equal(t, -8.61, forecast.Longitude)
+	require.Len(t, forecast.Days, 3)
+
+	today, ok := forecast.Today()
+	require.True(t, ok)
+	assert.True(t, today.Rainy())
+	assert.False(t, forecast.Days[2].Rainy())
+	assert.Equal(t, "Today: rain, 12-16°C, 90% chance of precipitation", forecast.Summary())
+}
+
+func TestNewProvider_Errors(t *testing.T) {
+	_, err := NewProvider(config.WeatherConfig{Provider: ProviderFile, FixtureFile: "testdata/missing.json"}, testLogger())
+	assert.Error(t, err)
+
+	_, err = NewProvider(config.WeatherConfig{Provider: "carrier-pigeon"}, testLogger())
+	assert.Error(t, err)
+}
+
+func TestConditionFromWMO(t *testing.T) {
+	tests := map[int]string{
+		0:  types.WeatherClear,
+		3:  types.WeatherCloudy,
+		45: types.WeatherFog,
+		55: types.WeatherRain,
+		81: types.WeatherRain,
+		75: types.WeatherSnow,
+		86: types.WeatherSnow,
+		95: types.WeatherStorm,
+	}
+	for code, want := range tests {
+		assert.Equal(t, want, conditionFromWMO(code), "code %d", code)
+	}
+}
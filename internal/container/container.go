@@ -8,17 +8,31 @@ import (
 
 	database "github.com/FACorreiaa/go-poi-au-suggestions/app/db"
 	"github.com/FACorreiaa/go-poi-au-suggestions/config"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/admin"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/analytics"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/audit"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/auth"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/autocomplete"
 	llmChat "github.com/FACorreiaa/go-poi-au-suggestions/internal/api/chat_prompt"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/city"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/embeddings"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/events"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/feedback"
 	generativeAI "github.com/FACorreiaa/go-poi-au-suggestions/internal/api/generative_ai"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/interests"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/jobs"
 	itineraryList "github.com/FACorreiaa/go-poi-au-suggestions/internal/api/list"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/partners"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/poi"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/privacy"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/profiles"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/recents"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/subscription"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/tags"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/travel"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/user"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/weather"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
 )
 
 // Container holds all application dependencies
@@ -26,6 +40,7 @@ type Container struct {
 	Config                    *config.Config
 	Logger                    *slog.Logger
 	Pool                      *pgxpool.Pool
+	JWTKeys                   *auth.KeySet
 	AuthHandler               *auth.HandlerImpl
 	UserHandler               *user.HandlerImpl
 	InterestHandler           *interests.HandlerImpl
@@ -36,6 +51,27 @@ type Container struct {
 	ItineraryListHandler      *itineraryList.HandlerImpl
 	CityHandler               *city.Handler
 	RecentsHandler            *recents.HandlerImpl
+	AdminHandler              *admin.HandlerImpl
+	AuditHandler              *audit.HandlerImpl
+	PrivacyHandler            *privacy.HandlerImpl
+	SubscriptionHandler       *subscription.HandlerImpl
+	JobsHandler               *jobs.HandlerImpl
+	EmbeddingsHandler         *embeddings.HandlerImpl
+	AutocompleteHandler       *autocomplete.HandlerImpl
+	TravelHandler             *travel.HandlerImpl
+	EventsHandler             *events.HandlerImpl
+	PartnersHandler           *partners.HandlerImpl
+	AnalyticsHandler          *analytics.HandlerImpl
+	// PrivacyService runs the export and account deletion worker (see main.go)
+	PrivacyService *privacy.ServiceImpl
+	// SubscriptionService runs the subscription expiry worker (see main.go)
+	SubscriptionService *subscription.ServiceImpl
+	// JobsService runs the background job workers (see main.go)
+	JobsService *jobs.ServiceImpl
+	// AutocompleteService keeps the autocomplete dictionary warm (see main.go)
+	AutocompleteService *autocomplete.ServiceImpl
+	// AnalyticsService writes the server-side analytics events (see main.go)
+	AnalyticsService *analytics.ServiceImpl
 	// Add other HandlerImpls, services, and repositories as needed
 }
 
@@ -57,15 +93,29 @@ func NewContainer(cfg *config.Config, logger *slog.Logger) (*Container, error) {
 	// Initialize repositories
 	authRepo := auth.NewPostgresAuthRepo(pool, logger)
 
+	// Audit log, shared by every service that records security-sensitive actions
+	auditRepo := audit.NewRepository(pool, logger)
+	auditService := audit.NewService(auditRepo, logger)
+	auditHandler := audit.NewHandler(auditService, logger)
+
 	// Initialize services
-	authService := auth.NewAuthService(authRepo, cfg, logger)
+	jwtKeys, err := auth.NewKeySet(cfg.JWT)
+	if err != nil {
+		logger.Error("Failed to load JWT signing keys", slog.Any("error", err))
+		return nil, err
+	}
+	// Subscriptions feed the plan claims of every issued token
+	subscriptionRepo := subscription.NewRepository(pool, logger)
+	authService := auth.NewAuthServiceWithKeys(authRepo, jwtKeys, subscriptionRepo, auditService, cfg, logger)
+	subscriptionService := subscription.NewService(subscriptionRepo, auditService, cfg.Subscriptions, logger)
 
 	// Initialize HandlerImpls
 	authHandlerImpl := auth.NewAuthHandlerImpl(authService, logger)
+	subscriptionHandler := subscription.NewHandler(subscriptionService, authService, logger)
 
 	//
 	userRepo := user.NewPostgresUserRepo(pool, logger)
-	userService := user.NewUserService(userRepo, logger)
+	userService := user.NewUserService(userRepo, auditService, logger)
 	userHandlerImpl := user.NewHandlerImpl(userService, logger)
 
 	interestsRepo := interests.NewRepositoryImpl(pool, logger)
@@ -87,6 +137,42 @@ func NewContainer(cfg *config.Config, logger *slog.Logger) (*Container, error) {
 	cityHandler := city.NewCityHandler(cityService, logger)
 
 	poiRepo := poi.NewRepository(pool, logger)
+	// Interaction events and the affinities learned from them
+	feedbackRepo := feedback.NewRepository(pool, logger)
+	// Background jobs; embedding jobs stay queued until an embedding service is configured
+	jobsRepo := jobs.NewRepository(pool, logger)
+	jobsService := jobs.NewService(jobsRepo, cfg.Jobs, logger)
+	jobsHandler := jobs.NewHandler(jobsService, logger)
+	// Travel times between itinerary stops, from imported GTFS feeds where there are any
+	travelRepo := travel.NewRepository(pool, logger)
+	travelService := travel.NewService(travelRepo, jobsService, logger)
+	travelService.Register(jobsService)
+	travelHandler := travel.NewHandler(travelService, logger)
+	// City events, added by hand or ingested from iCal feeds, for dated itineraries
+	eventsRepo := events.NewRepository(pool, logger)
+	eventsService := events.NewService(eventsRepo, jobsService, cfg.Events, logger)
+	eventsService.Register(jobsService)
+	eventsHandler := events.NewHandler(eventsService, logger)
+	// Forecasts for the city data and for adapting itineraries to the weather
+	weatherProvider, err := weather.NewProvider(cfg.Weather, logger)
+	if err != nil {
+		logger.Error("Failed to create weather provider", slog.Any("error", err))
+		return nil, err
+	}
+	// Booking partners asked for availability and prices on hotel and restaurant details
+	partnerRegistry, err := partners.NewRegistryFromConfig(cfg.Partners)
+	if err != nil {
+		logger.Error("Failed to create booking partner registry", slog.Any("error", err))
+		return nil, err
+	}
+	partnersRepo := partners.NewRepository(pool, logger)
+	partnersService := partners.NewService(partnersRepo, partnerRegistry, cfg.Partners.Timeout, logger)
+	partnersHandler := partners.NewHandler(partnersService, logger)
+	// Product analytics, emitted by the chat, POI and list services and rolled up daily
+	analyticsRepo := analytics.NewRepository(pool, logger)
+	analyticsService := analytics.NewService(analyticsRepo, cfg.Analytics, logger)
+	analyticsService.Register(jobsService)
+	analyticsHandler := analytics.NewHandler(analyticsService, logger)
 	// initialise the LLM interaction service
 	llmInteractionRepo := llmChat.NewRepositoryImpl(pool, logger)
 	llmInteractionService := llmChat.NewLlmInteractiontService(interestsRepo,
@@ -96,26 +182,59 @@ func NewContainer(cfg *config.Config, logger *slog.Logger) (*Container, error) {
 		llmInteractionRepo,
 		cityRepo,
 		poiRepo,
+		feedbackRepo,
+		weatherProvider,
+		travelService,
+		eventsService,
+		partnersService,
+		analyticsService,
 		logger)
 	llmInteractionHandlerImpl := llmChat.NewLLMHandlerImpl(llmInteractionService, logger)
 
 	embeddingService, _ := generativeAI.NewEmbeddingService(context.Background(), logger)
 	poiRepository := poi.NewRepository(pool, logger)
-	poiService := poi.NewServiceImpl(poiRepository, embeddingService, cityRepo, logger)
+	poiService := poi.NewServiceImpl(poiRepository, embeddingService, cityRepo, analyticsService, logger)
 	poiHandler := poi.NewHandlerImpl(poiService, logger)
 
 	itineraryListRepository := itineraryList.NewRepository(pool, logger)
-	itineraryLisrService := itineraryList.NewServiceImpl(itineraryListRepository, logger)
+	itineraryLisrService := itineraryList.NewServiceImpl(itineraryListRepository, auditService, analyticsService, logger)
 	itineraryListHandler := itineraryList.NewHandler(itineraryLisrService, logger)
 
 	// Initialize recents components
 	recentsRepository := recents.NewRepository(pool, logger)
 	recentsService := recents.NewService(recentsRepository, logger)
 	recentsHandler := recents.NewHandler(recentsService, logger)
+
+	// Admin and moderation
+	adminRepo := admin.NewRepository(pool, userRepo, logger)
+	adminService := admin.NewServiceImpl(adminRepo, auditService, logger)
+	adminHandler := admin.NewHandler(adminService, logger)
+
+	// GDPR data export and account deletion
+	privacyRepo := privacy.NewRepository(pool, logger)
+	privacyService := privacy.NewService(privacyRepo, auditService, cfg.Privacy, logger)
+	privacyHandler := privacy.NewHandler(privacyService, logger)
+
+	embeddingsRepo := embeddings.NewRepository(pool, logger)
+	embeddingsService := embeddings.NewService(embeddingsRepo, func(model types.EmbeddingModel) embeddings.Embedder {
+		return embeddingService.WithModel(model)
+	}, jobsService, logger)
+	embeddingsHandler := embeddings.NewHandler(embeddingsService, logger)
+	if embeddingService != nil {
+		embeddingsService.Register(jobsService, cfg.Jobs.EmbeddingSweepInterval)
+	}
+	feedbackService := feedback.NewService(feedbackRepo, logger)
+	feedbackService.Register(jobsService, cfg.Jobs.LearningSweepInterval)
+
+	// Typeahead over cities, POIs, interests, tags and recent queries
+	autocompleteRepo := autocomplete.NewRepository(pool, logger)
+	autocompleteService := autocomplete.NewService(autocompleteRepo, logger)
+	autocompleteHandler := autocomplete.NewHandler(autocompleteService, logger)
 	return &Container{
 		Config:                    cfg,
 		Logger:                    logger,
 		Pool:                      pool,
+		JWTKeys:                   jwtKeys,
 		AuthHandler:               authHandlerImpl,
 		UserHandler:               userHandlerImpl,
 		InterestHandler:           HandlerImpl,
@@ -126,6 +245,22 @@ func NewContainer(cfg *config.Config, logger *slog.Logger) (*Container, error) {
 		ItineraryListHandler:      itineraryListHandler,
 		CityHandler:               cityHandler,
 		RecentsHandler:            recentsHandler,
+		AdminHandler:              adminHandler,
+		AuditHandler:              auditHandler,
+		PrivacyHandler:            privacyHandler,
+		PrivacyService:            privacyService,
+		SubscriptionHandler:       subscriptionHandler,
+		SubscriptionService:       subscriptionService,
+		JobsHandler:               jobsHandler,
+		EmbeddingsHandler:         embeddingsHandler,
+		JobsService:               jobsService,
+		AutocompleteHandler:       autocompleteHandler,
+		AutocompleteService:       autocompleteService,
+		TravelHandler:             travelHandler,
+		EventsHandler:             eventsHandler,
+		PartnersHandler:           partnersHandler,
+		AnalyticsHandler:          analyticsHandler,
+		AnalyticsService:          analyticsService,
 		// Add other HandlerImpls, services, and repositories as needed
 	}, nil
 }
@@ -8,16 +8,28 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors" // Import CORS middleware if needed
 
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/admin"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/analytics"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/audit"
 	authMiddleware "github.com/FACorreiaa/go-poi-au-suggestions/internal/api/auth"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/autocomplete"
 	llmChat "github.com/FACorreiaa/go-poi-au-suggestions/internal/api/chat_prompt"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/city"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/embeddings"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/events"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/interests"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/jobs"
 	itineraryList "github.com/FACorreiaa/go-poi-au-suggestions/internal/api/list"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/partners"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/poi"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/privacy"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/profiles"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/recents"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/subscription"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/tags"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/travel"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/user"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/types"
 )
 
 // Config contains dependencies needed for the router setup
@@ -34,6 +46,17 @@ type Config struct {
 	ItineraryListHandler    *itineraryList.HandlerImpl
 	CityHandler             *city.Handler
 	RecentsHandler          *recents.HandlerImpl
+	AdminHandler            *admin.HandlerImpl
+	AuditHandler            *audit.HandlerImpl
+	PrivacyHandler          *privacy.HandlerImpl
+	SubscriptionHandler     *subscription.HandlerImpl
+	JobsHandler             *jobs.HandlerImpl
+	EmbeddingsHandler       *embeddings.HandlerImpl
+	AutocompleteHandler     *autocomplete.HandlerImpl
+	TravelHandler           *travel.HandlerImpl
+	EventsHandler           *events.HandlerImpl
+	PartnersHandler         *partners.HandlerImpl
+	AnalyticsHandler        *analytics.HandlerImpl
 }
 
 // SetupRouter initializes and configures the main application router.
@@ -54,6 +77,9 @@ func SetupRouter(cfg *Config) chi.Router {
 
 	// Optional: Heartbeat/Health check endpoint (often public)
 
+	// Public verification keys for services that validate our access tokens
+	r.Get("/.well-known/jwks.json", cfg.AuthHandler.JWKS)
+
 	// Group API routes, potentially versioning them
 	r.Route("/api/v1", func(r chi.Router) {
 
@@ -67,6 +93,9 @@ func SetupRouter(cfg *Config) chi.Router {
 			r.Get("/auth/google/callback", cfg.AuthHandler.GoogleCallback)
 			r.Post("/auth/refresh", cfg.AuthHandler.RefreshToken) // Refresh tokens via HttpOnly cookie
 
+			// Payment provider callbacks, authenticated by their HMAC signature
+			r.Post("/subscriptions/webhook", cfg.SubscriptionHandler.PaymentWebhook)
+
 			// Public city routes
 			r.Mount("/cities", CityRoutes(cfg.CityHandler))
 		})
@@ -86,33 +115,35 @@ func SetupRouter(cfg *Config) chi.Router {
 			//r.Post("/auth/invalidate-tokens", cfg.AuthHandlerImpl.InvalidateAllUserRefreshTokens) // Needs Auth
 
 			// Mount other protected resource routes
-			r.Mount("/user", UserRoutes(cfg.UserHandler)) // User routes
+			r.Mount("/user", UserRoutes(cfg.UserHandler, cfg.PrivacyHandler)) // User routes
 			r.Mount("/user/interests", interestsRoutes(cfg.InterestHandler))
 			r.Mount("/user/search-profile", profilesRoutes(cfg.SearchProfileHandler))
 			r.Mount("/user/tags", tagsRoutes(cfg.TagsHandler))
+			r.Mount("/user/subscription", subscriptionRoutes(cfg.SubscriptionHandler))
 			r.Mount("/llm", LLMInteractionRoutes(cfg.LLMInteractionHandler))
-			r.Mount("/pois", POIRoutes(cfg.PointsOfInterestHandler)) // Points of Interest routes
+			r.Mount("/pois", POIRoutes(cfg.PointsOfInterestHandler, cfg.JobsHandler)) // Points of Interest routes
 			r.Mount("/itineraries", ItineraryListRoutes(cfg.ItineraryListHandler))
-			r.Mount("/recents", RecentsRoutes(cfg.RecentsHandler)) // Recent interactions routes
+			r.Mount("/recents", RecentsRoutes(cfg.RecentsHandler))       // Recent interactions routes
+			r.Mount("/events", EventRoutes(cfg.EventsHandler))           // City events routes
+			r.Mount("/analytics", AnalyticsRoutes(cfg.AnalyticsHandler)) // Client analytics events
+			r.Get("/autocomplete", cfg.AutocompleteHandler.Autocomplete) // GET http://localhost:8000/api/v1/autocomplete?q=lis&lat=&lon=
 			// r.Mount("/pois", POIRoutes(cfg.HandlerImpl))   // Example for POI routes
 		})
 
-		// --- Admin Routes (Example) ---
-		// Nested group for routes requiring admin role (add specific admin middleware)
-		// r.Group(func(r chi.Router) {
-		// 	r.Use(cfg.AuthenticateMiddleware)
-		// 	r.Use(middleware.RequireRole("admin"))                         // Example: you'd create this middleware
-		// 	r.Get("/auth/user-role/{userID}", cfg.AuthHandlerImpl.GetUserRole) // Example Admin action
-		// 	// r.Mount("/admin", AdminRoutes(cfg.AdminHandlerImpl))
-		// })
+		// --- Admin Routes ---
+		// Role checks are applied per route group inside AdminRoutes
+		r.Group(func(r chi.Router) {
+			r.Use(cfg.AuthenticateMiddleware)
+			r.Mount("/admin", AdminRoutes(cfg.AdminHandler, cfg.AuditHandler, cfg.JobsHandler, cfg.EmbeddingsHandler, cfg.TravelHandler, cfg.EventsHandler, cfg.PartnersHandler, cfg.AnalyticsHandler, cfg.Logger))
+		})
 		// --- Premium Routes (Require active premium subscription) ---
 		r.Group(func(r chi.Router) {
 			r.Use(cfg.AuthenticateMiddleware) // Must be authenticated
 			// Apply premium check middleware
 			r.Use(authMiddleware.RequirePlanStatus(
 				cfg.Logger,
-				[]string{"premium_monthly", "premium_annual"}, // List of allowed plans
-				"active", // Required status
+				types.PremiumPlans,                                   // List of allowed plans
+				types.SubscriptionActive, types.SubscriptionTrialing, // Allowed statuses
 			))
 
 			// Add routes specific to premium users
@@ -121,16 +152,13 @@ func SetupRouter(cfg *Config) chi.Router {
 			// r.Get("/guides/exclusive/{guideID}", cfg.GuideHandlerImpl.GetExclusiveGuide)
 		})
 
-		// --- Admin Routes ---
-		// ... (Apply Authenticate + Admin role check middleware) ...
-
 	})
 
 	return r
 }
 
 // UserRoutes creates a router for user-related endpoints
-func UserRoutes(HandlerImpl *user.HandlerImpl) http.Handler {
+func UserRoutes(HandlerImpl *user.HandlerImpl, privacyHandler *privacy.HandlerImpl) http.Handler {
 	r := chi.NewRouter()
 
 	// All user routes require authentication, handled at the parent router level
@@ -138,6 +166,24 @@ func UserRoutes(HandlerImpl *user.HandlerImpl) http.Handler {
 	// User profile routes
 	r.Get("/profile", HandlerImpl.GetUserProfile)    // GET http://localhost:8000/api/v1/user/profile
 	r.Put("/profile", HandlerImpl.UpdateUserProfile) // PUT http://localhost:8000/api/v1/user/profile
+
+	// GDPR data export and account deletion
+	r.Post("/data-export", privacyHandler.RequestExport)                     // POST http://localhost:8000/api/v1/user/data-export
+	r.Get("/data-export/{exportID}", privacyHandler.GetExport)               // GET http://localhost:8000/api/v1/user/data-export/{exportID}
+	r.Get("/data-export/{exportID}/download", privacyHandler.DownloadExport) // GET http://localhost:8000/api/v1/user/data-export/{exportID}/download
+	r.Delete("/account", privacyHandler.RequestDeletion)                     // DELETE http://localhost:8000/api/v1/user/account
+	r.Get("/account/deletion", privacyHandler.GetDeletionStatus)             // GET http://localhost:8000/api/v1/user/account/deletion
+	r.Delete("/account/deletion", privacyHandler.CancelDeletion)             // DELETE http://localhost:8000/api/v1/user/account/deletion
+	return r
+}
+
+func subscriptionRoutes(h *subscription.HandlerImpl) http.Handler {
+	r := chi.NewRouter()
+
+	r.Get("/", h.GetSubscription)  // GET http://localhost:8000/api/v1/user/subscription
+	r.Put("/", h.ChangePlan)       // PUT http://localhost:8000/api/v1/user/subscription
+	r.Post("/trial", h.StartTrial) // POST http://localhost:8000/api/v1/user/subscription/trial
+
 	return r
 }
 
@@ -197,25 +243,29 @@ func LLMInteractionRoutes(HandlerImpl *llmChat.HandlerImpl) http.Handler {
 	r.Get("/prompt-response/poi/details", HandlerImpl.GetPOIDetails)                    // GET http://localhost:8000/api/v1/llm/prompt-response/{interactionID}
 	r.Post("/prompt-response/bookmark", HandlerImpl.SaveItenerary)                      // POST http://localhost:8000/api/v1/llm/prompt-response
 	r.Delete("/prompt-response/bookmark/{itineraryID}", HandlerImpl.RemoveItenerary)    // DELETE http://localhost:8000/api/v1/llm/bookmark/{bookmarkID}
+	r.Post("/prompt-response/budget/estimate", HandlerImpl.EstimateBudget)              // POST http://localhost:8000/api/v1/llm/prompt-response/budget/estimate
 	r.Get("/prompt-response/city/hotel/preferences", HandlerImpl.GetHotelsByPreference) // GET http://localhost:8000/api/v1/pois/city/hotel/preferences
 	r.Get("/prompt-response/city/hotel/nearby", HandlerImpl.GetHotelsNearby)            // GET http://localhost:8000/api/v1/pois/city/restaurant/preferences
-	r.Get("/prompt-response/city/hotel/{hotelID}", HandlerImpl.GetHotelByID)
+	r.Get("/prompt-response/city/hotel/search", HandlerImpl.SearchHotels)               // GET http://localhost:8000/api/v1/llm/prompt-response/city/hotel/search?city=Lisbon&min_rating=4&price_range=$$&amenity=wifi&limit=20
+	r.Get("/prompt-response/city/hotel/{hotelID}", HandlerImpl.GetHotelByID)            // GET http://localhost:8000/api/v1/llm/prompt-response/city/hotel/{hotelID}?check_in=2026-07-01&check_out=2026-07-04&guests=2&rooms=1
 	r.Get("/prompt-response/city/restaurants/preferences", HandlerImpl.GetRestaurantsByPreferences)
 	r.Get("/prompt-response/city/restaurants/nearby", HandlerImpl.GetRestaurantsNearby)
+	r.Get("/prompt-response/city/restaurants/search", HandlerImpl.SearchRestaurants) // GET http://localhost:8000/api/v1/llm/prompt-response/city/restaurants/search?city=Lisbon&dietary=vegan&open_now=true&limit=20
 	// TODO save on the db
-	r.Get("/prompt-response/city/restaurants/{restaurantID}", HandlerImpl.GetRestaurantDetails) // GET http://localhost:8000/api/v1/pois/city/poi/nearby
+	r.Get("/prompt-response/city/restaurants/{restaurantID}", HandlerImpl.GetRestaurantDetails)             // GET http://localhost:8000/api/v1/pois/city/poi/nearby
+	r.Post("/prompt-response/city/restaurants/{restaurantID}/dietary", HandlerImpl.ReportRestaurantDietary) // POST {"kind": "allergen_free", "attribute": "gluten", "available": false}
 
 	return r
 }
 
-func POIRoutes(HandlerImpl *poi.HandlerImpl) http.Handler {
+func POIRoutes(HandlerImpl *poi.HandlerImpl, jobsHandler *jobs.HandlerImpl) http.Handler {
 	r := chi.NewRouter()
 	// Points of Interest routes
 	r.Get("/favourites", HandlerImpl.GetFavouritePOIsByUserID)   // GET http://localhost:8000/api/v1/pois/favourites
 	r.Post("/favourites", HandlerImpl.AddPoiToFavourites)        // POST http://localhost:8000/api/v1/pois/favourites
 	r.Delete("/favourites", HandlerImpl.RemovePoiFromFavourites) // DELETE http://localhost:8000/api/v1/pois/favourites/{poiID}
 	r.Get("/city/{cityID}", HandlerImpl.GetPOIsByCityID)
-	r.Get("/itineraries", HandlerImpl.GetItineraries)                           // GET /api/v1/itineraries?page=1&page_size=20
+	r.Get("/itineraries", HandlerImpl.GetItineraries)                           // GET /api/v1/itineraries?cursor=&limit=20
 	r.Get("/itineraries/itinerary/{itinerary_id}", HandlerImpl.GetItinerary)    // GET /api/v1/itineraries/{uuid}
 	r.Put("/itineraries/itinerary/{itinerary_id}", HandlerImpl.UpdateItinerary) // PUT /api/v1/itineraries/{uuid}
 	r.Get("/nearby", HandlerImpl.GetPOIsByDistance)                             // GET http://localhost:8000/api/v1/llm/prompt-response/poi/nearby
@@ -232,7 +282,7 @@ func POIRoutes(HandlerImpl *poi.HandlerImpl) http.Handler {
 
 	// Embedding management routes (for admin/maintenance)
 	r.Route("/embeddings", func(r chi.Router) {
-		r.Post("/generate", HandlerImpl.GenerateEmbeddingsForPOIs) // POST http://localhost:8000/api/v1/pois/embeddings/generate?batch_size=20
+		r.Post("/generate", jobsHandler.GeneratePOIEmbeddings) // POST http://localhost:8000/api/v1/pois/embeddings/generate (queues a background job)
 	})
 
 	return r
@@ -246,6 +296,8 @@ func ItineraryListRoutes(h *itineraryList.HandlerImpl) http.Handler {
 	r.Get("/lists/{listID}", h.GetListDetailsHandler)                            // Get details of a specific list
 	r.Put("/lists/{listID}", h.UpdateListDetailsHandler)                         // Update a specific list
 	r.Delete("/lists/{listID}", h.DeleteListHandler)                             // Delete a specific list
+	r.Post("/lists/{listID}/save", h.SaveListHandler)                            // Save another user's public list
+	r.Delete("/lists/{listID}/save", h.UnsaveListHandler)                        // Remove a list from the saved lists
 	r.Post("/lists/{parentListID}/itineraries", h.CreateItineraryForListHandler) // Create an itinerary within a parent list
 	r.Post("/{itineraryID}/items", h.AddPOIListItemHandler)                      // Add a POI to an itinerary
 	r.Put("/{itineraryID}/items/{poiID}", h.UpdatePOIListItemHandler)            // Update a POI in an itinerary
@@ -253,6 +305,54 @@ func ItineraryListRoutes(h *itineraryList.HandlerImpl) http.Handler {
 	return r
 }
 
+func AdminRoutes(h *admin.HandlerImpl, auditHandler *audit.HandlerImpl, jobsHandler *jobs.HandlerImpl, embeddingsHandler *embeddings.HandlerImpl, travelHandler *travel.HandlerImpl, eventsHandler *events.HandlerImpl, partnersHandler *partners.HandlerImpl, analyticsHandler *analytics.HandlerImpl, logger *slog.Logger) http.Handler {
+	r := chi.NewRouter()
+
+	// User management is admin only
+	r.Group(func(r chi.Router) {
+		r.Use(authMiddleware.RequireRole(logger, types.UserRoleAdmin))
+		r.Get("/users", h.ListUsers)                                              // GET http://localhost:8000/api/v1/admin/users?q=&role=&is_active=&cursor=&limit=20
+		r.Get("/users/{userID}", h.GetUser)                                       // GET http://localhost:8000/api/v1/admin/users/{userID}
+		r.Post("/users/{userID}/deactivate", h.DeactivateUser)                    // POST http://localhost:8000/api/v1/admin/users/{userID}/deactivate
+		r.Post("/users/{userID}/reactivate", h.ReactivateUser)                    // POST http://localhost:8000/api/v1/admin/users/{userID}/reactivate
+		r.Put("/users/{userID}/role", h.SetUserRole)                              // PUT http://localhost:8000/api/v1/admin/users/{userID}/role
+		r.Put("/users/{userID}/subscription", h.OverrideSubscription)             // PUT http://localhost:8000/api/v1/admin/users/{userID}/subscription
+		r.Get("/audit-log", auditHandler.ListAuditLog)                            // GET http://localhost:8000/api/v1/admin/audit-log?actor_id=&target_type=&action=admin.&from=&to=&cursor=&limit=50
+		r.Post("/jobs", jobsHandler.EnqueueJob)                                   // POST http://localhost:8000/api/v1/admin/jobs
+		r.Get("/jobs", jobsHandler.ListJobs)                                      // GET http://localhost:8000/api/v1/admin/jobs?kind=&status=&cursor=&limit=
+		r.Get("/jobs/{jobID}", jobsHandler.GetJob)                                // GET http://localhost:8000/api/v1/admin/jobs/{jobID}
+		r.Get("/embeddings/models", embeddingsHandler.ListModels)                 // GET http://localhost:8000/api/v1/admin/embeddings/models
+		r.Post("/embeddings/migrations", embeddingsHandler.StartMigration)        // POST http://localhost:8000/api/v1/admin/embeddings/migrations
+		r.Get("/transit/feeds", travelHandler.ListFeeds)                          // GET http://localhost:8000/api/v1/admin/transit/feeds
+		r.Post("/transit/feeds", travelHandler.CreateFeed)                        // POST http://localhost:8000/api/v1/admin/transit/feeds
+		r.Post("/transit/feeds/{feedID}/import", travelHandler.ImportFeed)        // POST http://localhost:8000/api/v1/admin/transit/feeds/{feedID}/import
+		r.Get("/events/feeds", eventsHandler.ListFeeds)                           // GET http://localhost:8000/api/v1/admin/events/feeds
+		r.Post("/events/feeds", eventsHandler.CreateFeed)                         // POST http://localhost:8000/api/v1/admin/events/feeds
+		r.Post("/events/feeds/{feedID}/ingest", eventsHandler.IngestFeed)         // POST http://localhost:8000/api/v1/admin/events/feeds/{feedID}/ingest
+		r.Get("/partners", partnersHandler.ListPartners)                          // GET http://localhost:8000/api/v1/admin/partners
+		r.Get("/partners/listings", partnersHandler.ListListings)                 // GET http://localhost:8000/api/v1/admin/partners/listings?target_kind=hotel&target_id=
+		r.Post("/partners/listings", partnersHandler.CreateListing)               // POST http://localhost:8000/api/v1/admin/partners/listings
+		r.Delete("/partners/listings/{listingID}", partnersHandler.DeleteListing) // DELETE http://localhost:8000/api/v1/admin/partners/listings/{listingID}
+		r.Get("/analytics/top-cities", analyticsHandler.TopCities)                // GET http://localhost:8000/api/v1/admin/analytics/top-cities?from=2026-01-01&to=2026-01-31&limit=10
+		r.Get("/analytics/conversion", analyticsHandler.Conversion)               // GET http://localhost:8000/api/v1/admin/analytics/conversion?from=&to=
+		r.Get("/analytics/popular-pois", analyticsHandler.PopularPOIs)            // GET http://localhost:8000/api/v1/admin/analytics/popular-pois?city_id=&limit=10
+	})
+
+	// POI, restaurant and event moderation is open to moderators as well
+	r.Group(func(r chi.Router) {
+		r.Use(authMiddleware.RequireRole(logger, types.UserRoleAdmin, types.UserRoleModerator))
+		r.Put("/pois/{poiID}/verify", h.VerifyPOI)                              // PUT http://localhost:8000/api/v1/admin/pois/{poiID}/verify
+		r.Put("/pois/{poiID}", h.UpdatePOI)                                     // PUT http://localhost:8000/api/v1/admin/pois/{poiID}
+		r.Post("/pois/{poiID}/merge", h.MergePOIs)                              // POST http://localhost:8000/api/v1/admin/pois/{poiID}/merge
+		r.Delete("/pois/{poiID}", h.DeletePOI)                                  // DELETE http://localhost:8000/api/v1/admin/pois/{poiID}
+		r.Put("/restaurants/{restaurantID}/dietary", h.VerifyRestaurantDietary) // PUT http://localhost:8000/api/v1/admin/restaurants/{restaurantID}/dietary
+		r.Post("/events", eventsHandler.CreateEvent)                            // POST http://localhost:8000/api/v1/admin/events
+		r.Delete("/events/{eventID}", eventsHandler.DeleteEvent)                // DELETE http://localhost:8000/api/v1/admin/events/{eventID}
+	})
+
+	return r
+}
+
 func CityRoutes(h *city.Handler) http.Handler {
 	r := chi.NewRouter()
 
@@ -261,6 +361,23 @@ func CityRoutes(h *city.Handler) http.Handler {
 	return r
 }
 
+func EventRoutes(h *events.HandlerImpl) http.Handler {
+	r := chi.NewRouter()
+
+	r.Get("/", h.SearchEvents)      // GET http://localhost:8000/api/v1/events?city_id=&from=&to=&category=&lat=&lon=&radius=&cursor=&limit=
+	r.Get("/{eventID}", h.GetEvent) // GET http://localhost:8000/api/v1/events/{eventID}
+
+	return r
+}
+
+func AnalyticsRoutes(h *analytics.HandlerImpl) http.Handler {
+	r := chi.NewRouter()
+
+	r.Post("/events", h.IngestEvents) // POST http://localhost:8000/api/v1/analytics/events
+
+	return r
+}
+
 func RecentsRoutes(h *recents.HandlerImpl) http.Handler {
 	r := chi.NewRouter()
 
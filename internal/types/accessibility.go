@@ -0,0 +1,117 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Accessibility features, the keys of points_of_interest.accessibility.
+const (
+	AccessibilityStepFreeEntrance  = "step_free_entrance"
+	AccessibilityAccessibleToilet  = "accessible_toilet"
+	AccessibilityElevator          = "elevator"
+	AccessibilityHearingLoop       = "hearing_loop"
+	AccessibilityAccessibleParking = "accessible_parking"
+	AccessibilityBrailleSignage    = "braille_signage"
+	AccessibilityServiceAnimals    = "service_animals"
+)
+
+// AccessibilityFeatures lists the features a POI can be described by and a
+// search profile can ask for.
+var AccessibilityFeatures = []string{
+	AccessibilityStepFreeEntrance,
+	AccessibilityAccessibleToilet,
+	AccessibilityElevator,
+	AccessibilityHearingLoop,
+	AccessibilityAccessibleParking,
+	AccessibilityBrailleSignage,
+	AccessibilityServiceAnimals,
+}
+
+// ValidateAccessibility checks that a POI's accessibility only lists known
+// features.
+func ValidateAccessibility(accessibility map[string]bool) error {
+	for f := range accessibility {
+		if !slices.Contains(AccessibilityFeatures, f) {
+			return fmt.Errorf("%w: unknown accessibility feature %q", ErrBadRequest, f)
+		}
+	}
+	return nil
+}
+
+// KnownAccessibility returns the entries of accessibility that name a known
+// feature, or nil when there are none. It cleans up accessibility suggested by
+// the LLM before it is stored.
+func KnownAccessibility(accessibility map[string]bool) map[string]bool {
+	var known map[string]bool
+	for f, has := range accessibility {
+		if slices.Contains(AccessibilityFeatures, f) {
+			if known == nil {
+				known = make(map[string]bool)
+			}
+			known[f] = has
+		}
+	}
+	return known
+}
+
+// How a POI meets a user's accessibility needs, as returned by the
+// accessibility_status SQL function.
+const (
+	AccessStatusAccessible    = "accessible"     // Known to have every needed feature
+	AccessStatusUnknown       = "unknown"        // Not known to lack any, but not known to have them all
+	AccessStatusNotAccessible = "not_accessible" // Known to lack a needed feature
+)
+
+// AccessibilityNeeds is the accessibility section of a search profile. POIs
+// known to lack a needed feature are left out; with AccessibleOnly, POIs
+// whose features are not all known are left out too.
+type AccessibilityNeeds struct {
+	Features       []string `json:"features,omitempty"`
+	AccessibleOnly bool     `json:"accessible_only,omitempty"`
+}
+
+// Any reports whether any feature is needed.
+func (n *AccessibilityNeeds) Any() bool {
+	return n != nil && len(n.Features) > 0
+}
+
+// Validate checks that every needed feature is a known one.
+func (n *AccessibilityNeeds) Validate() error {
+	if n == nil {
+		return nil
+	}
+	for _, f := range n.Features {
+		if !slices.Contains(AccessibilityFeatures, f) {
+			return fmt.Errorf("%w: unknown accessibility feature %q", ErrBadRequest, f)
+		}
+	}
+	return nil
+}
+
+// StatusOf returns how a POI with the given accessibility meets the needs,
+// matching the accessibility_status SQL function.
+func (n *AccessibilityNeeds) StatusOf(accessibility map[string]bool) string {
+	status := AccessStatusAccessible
+	for _, f := range n.Features {
+		has, known := accessibility[f]
+		if known && !has {
+			return AccessStatusNotAccessible
+		}
+		if !known {
+			status = AccessStatusUnknown
+		}
+	}
+	return status
+}
+
+// Allows reports whether a POI with the given status may be suggested.
+func (n *AccessibilityNeeds) Allows(status string) bool {
+	switch status {
+	case AccessStatusNotAccessible:
+		return false
+	case AccessStatusUnknown:
+		return !n.AccessibleOnly
+	}
+	return true
+}
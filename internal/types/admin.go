@@ -0,0 +1,92 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// User roles stored in users.role and carried in the `rol` token claim.
+const (
+	UserRoleUser      = "user"
+	UserRoleModerator = "moderator"
+	UserRoleAdmin     = "admin"
+)
+
+// ValidRoles lists the roles an admin may assign.
+var ValidRoles = map[string]struct{}{
+	UserRoleUser:      {},
+	UserRoleModerator: {},
+	UserRoleAdmin:     {},
+}
+
+// AdminUserFilter holds the search and pagination parameters for listing users.
+type AdminUserFilter struct {
+	Query    string // Matches email, username or display name (case-insensitive, substring).
+	Role     string // Exact role match; empty for any.
+	IsActive *bool  // nil for both active and inactive users.
+	Page     PageRequest
+}
+
+// AdminUserSummary is a row in the admin user search results.
+type AdminUserSummary struct {
+	ID                 uuid.UUID  `json:"id"`
+	Email              string     `json:"email"`
+	Username           *string    `json:"username,omitempty"`
+	DisplayName        *string    `json:"display_name,omitempty"`
+	Role               string     `json:"role"`
+	IsActive           bool       `json:"is_active"`
+	SubscriptionPlan   *string    `json:"subscription_plan,omitempty"`
+	SubscriptionStatus *string    `json:"subscription_status,omitempty"`
+	LastLoginAt        *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// PaginatedAdminUsersResponse is the paginated result of an admin user search.
+type PaginatedAdminUsersResponse struct {
+	Users        []AdminUserSummary `json:"users"`
+	Limit        int                `json:"limit"`
+	NextCursor   *Cursor            `json:"next_cursor,omitempty"` // Empty on the last page
+	TotalRecords int                `json:"total_records"`
+}
+
+// UpdateUserRoleRequest is the body for changing a user's role.
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required" example:"moderator"`
+}
+
+// SubscriptionOverrideRequest lets an admin set a user's subscription directly,
+// bypassing the payment provider (e.g. comps, support refunds).
+type SubscriptionOverrideRequest struct {
+	Plan         string     `json:"plan" binding:"required" example:"premium_monthly"`
+	Status       string     `json:"status" binding:"required" example:"active"`
+	EndDate      *time.Time `json:"end_date,omitempty"`
+	TrialEndDate *time.Time `json:"trial_end_date,omitempty"`
+	Reason       string     `json:"reason" binding:"required" example:"Support goodwill credit"`
+}
+
+// AdminPOIUpdate holds the POI fields a moderator may edit. Nil fields are left unchanged.
+type AdminPOIUpdate struct {
+	Name        *string   `json:"name,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	Category    *string   `json:"category,omitempty"`
+	Address     *string   `json:"address,omitempty"`
+	Website     *string   `json:"website,omitempty"`
+	PhoneNumber *string   `json:"phone_number,omitempty"`
+	PriceLevel  *int      `json:"price_level,omitempty"`
+	Tags        *[]string `json:"tags,omitempty"`
+	Latitude    *float64  `json:"latitude,omitempty"`
+	Longitude   *float64  `json:"longitude,omitempty"`
+	// Accessibility replaces the POI's known features; {} clears them.
+	Accessibility map[string]bool `json:"accessibility,omitempty"`
+}
+
+// MergePOIsRequest merges duplicate POIs into the POI in the request path.
+type MergePOIsRequest struct {
+	SourceIDs []uuid.UUID `json:"source_ids" binding:"required"`
+}
+
+// VerifyPOIRequest sets or clears a POI's verified flag.
+type VerifyPOIRequest struct {
+	Verified bool `json:"verified" example:"true"`
+}
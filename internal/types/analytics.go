@@ -0,0 +1,96 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Analytics event names.
+const (
+	AnalyticsSearchPerformed = "search_performed"
+	AnalyticsPOIViewed       = "poi_viewed"
+	AnalyticsPOISaved        = "poi_saved"
+	AnalyticsItinerarySaved  = "itinerary_saved"
+	AnalyticsChatStarted     = "chat_started"
+	AnalyticsListViewed      = "list_viewed"
+	AnalyticsListSaved       = "list_saved"
+)
+
+// AnalyticsEventNames lists the valid analytics event names.
+var AnalyticsEventNames = []string{
+	AnalyticsSearchPerformed, AnalyticsPOIViewed, AnalyticsPOISaved, AnalyticsItinerarySaved,
+	AnalyticsChatStarted, AnalyticsListViewed, AnalyticsListSaved,
+}
+
+// IsAnalyticsEvent reports whether name is one of AnalyticsEventNames.
+func IsAnalyticsEvent(name string) bool {
+	for _, n := range AnalyticsEventNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Where an analytics event was recorded.
+const (
+	AnalyticsSourceClient = "client"
+	AnalyticsSourceServer = "server"
+)
+
+// AnalyticsEvent is something a user did. Source and UserID are set by the
+// server, never taken from clients.
+type AnalyticsEvent struct {
+	Name       string         `json:"name"`
+	Source     string         `json:"-"`
+	UserID     *uuid.UUID     `json:"-"`
+	SessionID  *uuid.UUID     `json:"session_id,omitempty"`
+	CityID     *uuid.UUID     `json:"city_id,omitempty"`
+	POIID      *uuid.UUID     `json:"poi_id,omitempty"`
+	ListID     *uuid.UUID     `json:"list_id,omitempty"`
+	Properties map[string]any `json:"properties,omitempty"`
+	OccurredAt time.Time      `json:"occurred_at"` // Defaults to when it is received
+}
+
+type AnalyticsBatchRequest struct {
+	Events []AnalyticsEvent `json:"events"`
+}
+
+// AnalyticsBatchResponse counts the events of a batch that were stored and
+// those dropped as invalid.
+type AnalyticsBatchResponse struct {
+	Accepted int `json:"accepted"`
+	Rejected int `json:"rejected"`
+}
+
+// CityAnalytics is a city's activity over a date range.
+type CityAnalytics struct {
+	CityID           uuid.UUID `json:"city_id"`
+	CityName         string    `json:"city_name"`
+	Searches         int64     `json:"searches"`
+	POIViews         int64     `json:"poi_views"`
+	ChatsStarted     int64     `json:"chats_started"`
+	ItinerariesSaved int64     `json:"itineraries_saved"`
+	ActiveUserDays   int64     `json:"active_user_days"` // Sum of each day's distinct users
+}
+
+// ConversionDay is the share of a day's chat users who saved an itinerary
+// that day.
+type ConversionDay struct {
+	Day            string  `json:"day"` // YYYY-MM-DD
+	ChatUsers      int64   `json:"chat_users"`
+	ConvertedUsers int64   `json:"converted_users"`
+	Rate           float64 `json:"rate"`
+}
+
+// POIPopularity is how often a POI was viewed and saved over the popularity
+// window, each user counting once a day.
+type POIPopularity struct {
+	POIID  uuid.UUID `json:"poi_id"`
+	Name   string    `json:"name"`
+	CityID uuid.UUID `json:"city_id"`
+	Views  int64     `json:"views"`
+	Saves  int64     `json:"saves"`
+	Score  int64     `json:"score"`
+}
@@ -0,0 +1,90 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit actions. Prefixed with the service that records them.
+const (
+	AuditActionPasswordChanged      = "auth.password_changed"
+	AuditActionProfileUpdated       = "user.profile_updated"
+	AuditActionEmailChanged         = "user.email_changed"
+	AuditActionUserDeactivated      = "user.deactivated"
+	AuditActionUserReactivated      = "user.reactivated"
+	AuditActionDataExportRequested  = "user.data_export_requested"
+	AuditActionDeletionRequested    = "user.deletion_requested"
+	AuditActionDeletionCancelled    = "user.deletion_cancelled"
+	AuditActionAccountDeleted       = "user.account_deleted"
+	AuditActionAdminUserDeactivated = "admin.user_deactivated"
+	AuditActionAdminUserReactivated = "admin.user_reactivated"
+	AuditActionRoleChanged          = "admin.user_role_changed"
+	AuditActionSubscriptionOverride = "admin.subscription_overridden"
+	AuditActionPOIVerified          = "admin.poi_verified"
+	AuditActionPOIUpdated           = "admin.poi_updated"
+	AuditActionPOIsMerged           = "admin.pois_merged"
+	AuditActionPOIDeleted           = "admin.poi_deleted"
+	AuditActionDietaryVerified      = "admin.restaurant_dietary_verified"
+	AuditActionTrialStarted         = "subscription.trial_started"
+	AuditActionPlanChanged          = "subscription.plan_changed"
+	AuditActionSubscriptionExpired  = "subscription.expired"
+	AuditActionListUpdated          = "list.updated"
+	AuditActionListDeleted          = "list.deleted"
+)
+
+// Audit target types.
+const (
+	AuditTargetUser         = "user"
+	AuditTargetPOI          = "poi"
+	AuditTargetList         = "list"
+	AuditTargetSubscription = "subscription"
+	AuditTargetRestaurant   = "restaurant"
+)
+
+// AuditEntry is what services pass to the audit recorder. Before and After may be
+// any JSON-serialisable value; only the fields that differ are stored.
+type AuditEntry struct {
+	ActorID    *uuid.UUID
+	Action     string
+	TargetType string
+	TargetID   *uuid.UUID
+	Before     interface{}
+	After      interface{}
+	Metadata   map[string]interface{}
+}
+
+// AuditLogEntry is a stored audit log row.
+type AuditLogEntry struct {
+	ID         uuid.UUID              `json:"id"`
+	ActorID    *uuid.UUID             `json:"actor_id,omitempty"`
+	Action     string                 `json:"action"`
+	TargetType string                 `json:"target_type"`
+	TargetID   *uuid.UUID             `json:"target_id,omitempty"`
+	Before     map[string]interface{} `json:"before,omitempty"`
+	After      map[string]interface{} `json:"after,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	IPAddress  *string                `json:"ip_address,omitempty"`
+	UserAgent  *string                `json:"user_agent,omitempty"`
+	RequestID  *string                `json:"request_id,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// AuditLogFilter holds the filters for querying the audit log. Zero values match everything.
+type AuditLogFilter struct {
+	ActorID    *uuid.UUID
+	TargetType string
+	TargetID   *uuid.UUID
+	Action     string // Exact action, or a prefix ending in '.' (e.g. "admin.")
+	From       *time.Time
+	To         *time.Time
+	Page       PageRequest
+}
+
+// PaginatedAuditLogResponse is a page of audit log entries, newest first.
+type PaginatedAuditLogResponse struct {
+	Entries      []AuditLogEntry `json:"entries"`
+	Limit        int             `json:"limit"`
+	NextCursor   *Cursor         `json:"next_cursor,omitempty"` // Empty on the last page
+	TotalRecords int             `json:"total_records"`
+}
@@ -114,3 +114,20 @@ type Claims struct {
 	Scope                string `json:"scope,omitempty"` // Optional scope information.
 	jwt.RegisteredClaims        // Embed standard claims (ExpiresAt, IssuedAt, Subject, etc.).
 }
+
+// JWK is a public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`           // Key type: "RSA" or "OKP".
+	Kid string `json:"kid"`           // Key ID, matches the JWT `kid` header.
+	Use string `json:"use"`           // Always "sig".
+	Alg string `json:"alg"`           // JWS algorithm, e.g. "RS256" or "EdDSA".
+	N   string `json:"n,omitempty"`   // RSA modulus (base64url).
+	E   string `json:"e,omitempty"`   // RSA public exponent (base64url).
+	Crv string `json:"crv,omitempty"` // OKP curve, e.g. "Ed25519".
+	X   string `json:"x,omitempty"`   // OKP public key (base64url).
+}
+
+// JWKS is the JSON Web Key Set served at /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
@@ -0,0 +1,41 @@
+package types
+
+import "github.com/google/uuid"
+
+// Kinds of autocomplete suggestions.
+const (
+	SuggestionCity     = "city"
+	SuggestionPOI      = "poi"
+	SuggestionInterest = "interest"
+	SuggestionTag      = "tag"
+	SuggestionRecent   = "recent"
+)
+
+// Suggestion is one typeahead entry. Score is how well Text matches the
+// query, from 0 to 1.
+type Suggestion struct {
+	Kind     string     `json:"kind"`
+	ID       *uuid.UUID `json:"id,omitempty"`
+	Text     string     `json:"text"`
+	Subtitle string     `json:"subtitle,omitempty"`
+	// Distance in kilometers from the user, for POIs when a location is given
+	Distance *float64 `json:"distance,omitempty"`
+	Score    float64  `json:"score"`
+}
+
+// AutocompleteRequest is a typeahead query. Location is optional; without it
+// POIs are not limited to the user's surroundings.
+type AutocompleteRequest struct {
+	Query    string
+	UserID   uuid.UUID
+	Location *GeoPoint
+	// Kinds limits the suggestions to these kinds; empty means all
+	Kinds []string
+	Limit int
+}
+
+// AutocompleteResponse lists suggestions of every kind, best match first.
+type AutocompleteResponse struct {
+	Query       string       `json:"query"`
+	Suggestions []Suggestion `json:"suggestions"`
+}
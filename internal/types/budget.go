@@ -0,0 +1,44 @@
+package types
+
+import "github.com/google/uuid"
+
+// What an itinerary item is priced as.
+const (
+	CostKindEntry = "entry" // Admission to a sight or activity, per person
+	CostKindMeal  = "meal"  // One meal per person at a restaurant, cafe or bar
+	CostKindHotel = "hotel" // Room nights at a place to stay
+)
+
+// CostItem is the estimated cost of one itinerary item.
+type CostItem struct {
+	Name       string  `json:"name"`
+	Kind       string  `json:"kind"`
+	PriceLevel int     `json:"price_level"` // 0 (free) to 4
+	Amount     float64 `json:"amount"`      // For all guests and nights, in the estimate's currency
+	// Assumed is set when the item had no price level and one was assumed
+	// from its category or the profile's budget level.
+	Assumed    bool   `json:"assumed,omitempty"`
+	OverBudget bool   `json:"over_budget,omitempty"`
+	Reason     string `json:"reason,omitempty"` // Why the item is over budget
+}
+
+// CostEstimate is what an itinerary is expected to cost.
+type CostEstimate struct {
+	Currency   string     `json:"currency"` // ISO 4217
+	Items      []CostItem `json:"items"`
+	Total      float64    `json:"total"`
+	Guests     int        `json:"guests"`
+	Nights     int        `json:"nights,omitempty"`
+	CostLevel  int        `json:"cost_level"`  // 1 to 4, comparable with budget_level
+	OverBudget bool       `json:"over_budget"` // Some item or the trip as a whole exceeds the profile's budget
+}
+
+// CostEstimateRequest prices an itinerary, optionally with a stay at a hotel.
+type CostEstimateRequest struct {
+	City             string                `json:"city"`
+	Country          string                `json:"country,omitempty"` // Looked up from the city when empty
+	ProfileID        *uuid.UUID            `json:"profile_id,omitempty"`
+	POIs             []POIDetailedInfo     `json:"points_of_interest"`
+	Hotel            *HotelDetailedInfo    `json:"hotel,omitempty"`
+	HotelPreferences *HotelUserPreferences `json:"hotel_preferences,omitempty"` // Nights, guests and rooms of the stay
+}
@@ -36,6 +36,11 @@ type AIItineraryResponse struct {
 	PointsOfInterest   []POIDetailedInfo `json:"points_of_interest"`
 	Restaurants        []POIDetailedInfo `json:"restaurants,omitempty"`
 	Bars               []POIDetailedInfo `json:"bars,omitempty"`
+	WeatherSwaps       []WeatherSwap     `json:"weather_swaps,omitempty"` // Outdoor stops replaced because of the forecast
+	Legs               []TravelLeg       `json:"legs,omitempty"`          // Between consecutive points of interest
+	TotalTravelMinutes int               `json:"total_travel_minutes,omitempty"`
+	Budget             *CostEstimate     `json:"budget,omitempty"` // What the points of interest are expected to cost
+	Events             []Event           `json:"events,omitempty"` // On in the city during the travel dates
 }
 
 type GeneralCityData struct {
@@ -52,6 +57,8 @@ type GeneralCityData struct {
 	Weather         string  `json:"weather"`
 	Attractions     string  `json:"attractions"`
 	History         string  `json:"history"`
+
+	Forecast *WeatherForecast `json:"forecast,omitempty"` // From the weather provider; nil when unavailable
 }
 
 type AiCityResponse struct {
@@ -75,6 +82,7 @@ type GenAIResponse struct {
 	GeneralPOI           []POIDetailedInfo `json:"general_poi,omitempty"`
 	PersonalisedPOI      []POIDetailedInfo `json:"personalised_poi,omitempty"` // Consider changing to []PersonalizedPOIDetail
 	POIDetailedInfo      []POIDetailedInfo `json:"poi_detailed_info,omitempty"`
+	Forecast             *WeatherForecast  `json:"forecast,omitempty"` // For the city center
 	Err                  error             `json:"-"`
 }
 
@@ -125,8 +133,8 @@ type UpdateItineraryRequest struct {
 type PaginatedUserItinerariesResponse struct {
 	Itineraries  []UserSavedItinerary `json:"itineraries"`
 	TotalRecords int                  `json:"total_records"`
-	Page         int                  `json:"page"`
-	PageSize     int                  `json:"page_size"`
+	Limit        int                  `json:"limit"`
+	NextCursor   *Cursor              `json:"next_cursor,omitempty"` // Empty on the last page
 }
 
 type BookmarkRequest struct {
@@ -153,9 +161,17 @@ type POIDetailrequest struct {
 }
 
 type POIFilter struct {
-	Location GeoPoint `json:"location"` // e.g., "restaurant", "hotel", "bar"
-	Radius   float64  `json:"radius"`   // Radius in kilometers for filtering POIs
-	Category string   `json:"category"` // e.g., "restaurant", "hotel", "bar"
+	Location GeoPoint       `json:"location"` // e.g., "restaurant", "hotel", "bar"
+	Radius   float64        `json:"radius"`   // Radius in kilometers for filtering POIs
+	Category string         `json:"category"` // e.g., "restaurant", "hotel", "bar"
+	Query    string         `json:"query"`    // Free text matched against name, tags, description and address
+	Facets   FacetSelection `json:"facets"`   // Multi-select facet filters
+	// Accessibility needs POIs must meet. When nil, those of the search
+	// profile ProfileID, or of the user's default profile, are used.
+	Accessibility *AccessibilityNeeds `json:"accessibility,omitempty"`
+	ProfileID     uuid.UUID           `json:"profile_id,omitempty"`
+	// Limit caps the number of results, best first. Facets still count every match.
+	Limit int `json:"limit,omitempty"`
 }
 
 type GeoPoint struct {
@@ -208,7 +224,8 @@ type HotelDetailedInfo struct {
 	Tags             []string  `json:"tags"`
 	Images           []string  `json:"images"`
 	LlmInteractionID uuid.UUID `json:"llm_interaction_id"`
-	Err              error     `json:"-"` // Not serialized
+	Distance         *float64  `json:"distance,omitempty"` // Kilometers from the search point, when one is given
+	Err              error     `json:"-"`                  // Not serialized
 }
 
 type HotelPreferenceRequest struct {
@@ -228,24 +245,31 @@ type RestaurantUserPreferences struct {
 }
 
 type RestaurantDetailedInfo struct {
-	ID               uuid.UUID `json:"id"`
-	City             string    `json:"city"`
-	Name             string    `json:"name"`
-	Latitude         float64   `json:"latitude"`
-	Longitude        float64   `json:"longitude"`
-	Category         string    `json:"category"`
-	Description      string    `json:"description"`
-	Address          *string   `json:"address"`
-	Website          *string   `json:"website"`
-	PhoneNumber      *string   `json:"phone_number"`
-	OpeningHours     *string   `json:"opening_hours"`
-	PriceLevel       *string   `json:"price_level"`  // Changed to *string
-	CuisineType      *string   `json:"cuisine_type"` // Changed to *string
-	Tags             []string  `json:"tags"`
-	Images           []string  `json:"images"`
-	Rating           float64   `json:"rating"`
-	LlmInteractionID uuid.UUID `json:"llm_interaction_id"`
-	Err              error     `json:"-"`
+	ID                uuid.UUID          `json:"id"`
+	City              string             `json:"city"`
+	Name              string             `json:"name"`
+	Latitude          float64            `json:"latitude"`
+	Longitude         float64            `json:"longitude"`
+	Category          string             `json:"category"`
+	Description       string             `json:"description"`
+	Address           *string            `json:"address"`
+	Website           *string            `json:"website"`
+	PhoneNumber       *string            `json:"phone_number"`
+	OpeningHours      *string            `json:"opening_hours"`
+	PriceLevel        *string            `json:"price_level"`  // Changed to *string
+	CuisineType       *string            `json:"cuisine_type"` // Changed to *string
+	Tags              []string           `json:"tags"`
+	Images            []string           `json:"images"`
+	Rating            float64            `json:"rating"`
+	DietaryOptions    []string           `json:"dietary_options"`         // e.g., ["vegan", "halal"]
+	AllergenFree      []string           `json:"allergen_free"`           // e.g., ["gluten", "nuts"]
+	AllergenConfirmed []string           `json:"allergen_free_confirmed"` // The allergen_free entries staff or users confirmed; the rest are unconfirmed LLM guesses
+	ServiceStyle      *string            `json:"service_style"`           // e.g., "casual", "fine_dining"
+	Features          []string           `json:"features"`                // e.g., ["outdoor_seating", "live_music"]
+	Dietary           []DietaryAttribute `json:"dietary,omitempty"`       // Source of each diet and allergen, and those known to be unavailable
+	Distance          *float64           `json:"distance,omitempty"`      // Kilometers from the search point, when one is given
+	LlmInteractionID  uuid.UUID          `json:"llm_interaction_id"`
+	Err               error              `json:"-"`
 }
 
 // Context-aware chat types
@@ -334,8 +358,9 @@ type RecentInteraction struct {
 
 // RecentInteractionsResponse groups interactions by city
 type RecentInteractionsResponse struct {
-	Cities []CityInteractions `json:"cities"`
-	Total  int                `json:"total"`
+	Cities     []CityInteractions `json:"cities"`
+	Total      int                `json:"total"`
+	NextCursor *Cursor            `json:"next_cursor,omitempty"` // Empty on the last page
 }
 
 // CityInteractions groups interactions for a specific city
@@ -205,7 +205,8 @@ const (
 	EventTypeUnifiedChat     = "unified_chat"
 	EventTypeHotels          = "hotels"
 	EventTypeRestaurants     = "restaurants"
-	EventTypeChunk           = "chunk" // For immediate text chunks (Google GenAI pattern)
+	EventTypeChunk           = "chunk"  // For immediate text chunks (Google GenAI pattern)
+	EventTypeEvents          = "events" // City events on the requested travel dates
 )
 
 // StreamingResponse wraps the streaming channel and metadata
@@ -0,0 +1,80 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Dietary attribute kinds, matching the restaurant_dietary_attributes.kind
+// check constraint: a diet the restaurant caters for, or an allergen its
+// kitchen can keep out of a meal.
+const (
+	DietaryKindDiet         = "dietary"
+	DietaryKindAllergenFree = "allergen_free"
+)
+
+// Dietary attribute sources, matching the restaurant_dietary_attributes.source
+// check constraint, from least to most trusted. A more trusted source
+// overrides a less trusted one; within a source the latest word counts.
+const (
+	DietarySourceLLM      = "llm_inferred"
+	DietarySourceUser     = "user_reported"
+	DietarySourceVerified = "verified"
+)
+
+// DietaryAttribute is what is currently known about one diet or allergen at a
+// restaurant, and who said so.
+type DietaryAttribute struct {
+	Kind      string    `json:"kind"`
+	Attribute string    `json:"attribute"` // e.g., "vegan", "gluten"
+	Available bool      `json:"available"`
+	Source    string    `json:"source"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MaxDietaryNoteLength caps the note on a dietary report.
+const MaxDietaryNoteLength = 500
+
+// DietaryReport is a user's correction of a restaurant's dietary or allergen
+// attribute, e.g. that it has no gluten-free options after all, or a
+// moderator's verification of one.
+type DietaryReport struct {
+	Kind      string `json:"kind"`
+	Attribute string `json:"attribute"`
+	Available bool   `json:"available"`
+	Note      string `json:"note,omitempty"`
+}
+
+// Normalize checks the kind, turns the attribute into its stored key and trims
+// the note.
+func (r *DietaryReport) Normalize() error {
+	if r.Kind != DietaryKindDiet && r.Kind != DietaryKindAllergenFree {
+		return fmt.Errorf("%w: kind must be %q or %q", ErrBadRequest, DietaryKindDiet, DietaryKindAllergenFree)
+	}
+	keys := AttributeKeys([]string{r.Attribute})
+	if len(keys) == 0 {
+		return fmt.Errorf("%w: attribute is required", ErrBadRequest)
+	}
+	r.Attribute = keys[0]
+	r.Note = strings.TrimSpace(r.Note)
+	if len(r.Note) > MaxDietaryNoteLength {
+		return fmt.Errorf("%w: note must be at most %d characters", ErrBadRequest, MaxDietaryNoteLength)
+	}
+	return nil
+}
+
+// AttributeKeys normalizes restaurant attributes to the lowercase snake_case
+// keys they are stored as, so "Gluten Free" matches "gluten_free".
+func AttributeKeys(values []string) []string {
+	keys := make([]string, 0, len(values))
+	for _, v := range values {
+		key := strings.Join(strings.FieldsFunc(strings.ToLower(v), func(r rune) bool {
+			return r == ' ' || r == '-' || r == '_'
+		}), "_")
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,42 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// Tables that store embeddings. Each has its own active model.
+const (
+	EmbeddingTablePOIs            = "points_of_interest"
+	EmbeddingTableCities          = "cities"
+	EmbeddingTableUserPreferences = "user_preference_profiles"
+)
+
+// EmbeddingModel identifies the model that produced a vector. Bump Version when
+// the input text or settings change for the same model name, since that also
+// makes vectors incomparable.
+type EmbeddingModel struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+}
+
+func (m EmbeddingModel) String() string {
+	return fmt.Sprintf("%s@v%d", m.Name, m.Version)
+}
+
+// EmbeddingModelState is the active model of a table and any migration in progress.
+type EmbeddingModelState struct {
+	Table              string          `json:"table"`
+	Active             EmbeddingModel  `json:"active"`
+	Next               *EmbeddingModel `json:"next,omitempty"`
+	MigrationStartedAt *time.Time      `json:"migration_started_at,omitempty"`
+	CutoverAt          *time.Time      `json:"cutover_at,omitempty"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+}
+
+// StartReembedRequest asks for a table to be re-embedded with another model.
+type StartReembedRequest struct {
+	Table   string `json:"table"`
+	Model   string `json:"model"`
+	Version int    `json:"version"`
+}
@@ -0,0 +1,158 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event categories.
+const (
+	EventCategoryFestival   = "festival"
+	EventCategoryConcert    = "concert"
+	EventCategorySports     = "sports"
+	EventCategoryCultural   = "cultural"
+	EventCategoryFood       = "food"
+	EventCategoryExhibition = "exhibition"
+	EventCategoryTheatre    = "theatre"
+	EventCategoryMarket     = "market"
+	EventCategoryOther      = "other"
+)
+
+// EventCategories lists the valid event categories.
+var EventCategories = []string{
+	EventCategoryFestival, EventCategoryConcert, EventCategorySports, EventCategoryCultural, EventCategoryFood,
+	EventCategoryExhibition, EventCategoryTheatre, EventCategoryMarket, EventCategoryOther,
+}
+
+// IsEventCategory reports whether category is one of EventCategories.
+func IsEventCategory(category string) bool {
+	for _, c := range EventCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// EventCategoryForInterest maps an ActivityPreferences.LocalEventsInterest
+// value such as "festivals" or "food_events" to its event category, or ""
+// when it names none.
+func EventCategoryForInterest(interest string) string {
+	interest = strings.ToLower(strings.TrimSpace(interest))
+	interest = strings.TrimSuffix(interest, "_events")
+	if IsEventCategory(interest) {
+		return interest
+	}
+	if singular := strings.TrimSuffix(interest, "s"); IsEventCategory(singular) {
+		return singular
+	}
+	return ""
+}
+
+// Event is a dated happening in a city, optionally at a known POI.
+type Event struct {
+	ID          uuid.UUID  `json:"id"`
+	CityID      uuid.UUID  `json:"city_id"`
+	POIID       *uuid.UUID `json:"poi_id,omitempty"`
+	FeedID      *uuid.UUID `json:"feed_id,omitempty"`      // The iCal feed it was ingested from
+	ExternalUID string     `json:"external_uid,omitempty"` // Its UID in that feed
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Category    string     `json:"category"`
+	StartsAt    time.Time  `json:"starts_at"`
+	EndsAt      time.Time  `json:"ends_at"`
+	AllDay      bool       `json:"all_day"`
+	Venue       string     `json:"venue,omitempty"`
+	Latitude    *float64   `json:"latitude,omitempty"`
+	Longitude   *float64   `json:"longitude,omitempty"`
+	URL         string     `json:"url,omitempty"`
+	Distance    *float64   `json:"distance,omitempty"` // Kilometers from the search point, when one is given
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Overlaps reports whether the event is on at some time in [from, to). EndsAt
+// is exclusive, except that events without a duration are on at StartsAt.
+func (e Event) Overlaps(from, to time.Time) bool {
+	return e.StartsAt.Before(to) && (e.EndsAt.After(from) || !e.StartsAt.Before(from))
+}
+
+// CreateEventRequest adds an event by hand.
+type CreateEventRequest struct {
+	CityID      uuid.UUID  `json:"city_id"`
+	POIID       *uuid.UUID `json:"poi_id,omitempty"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Category    string     `json:"category,omitempty"` // Defaults to other
+	StartsAt    time.Time  `json:"starts_at"`
+	EndsAt      time.Time  `json:"ends_at"` // Defaults to StartsAt
+	AllDay      bool       `json:"all_day,omitempty"`
+	Venue       string     `json:"venue,omitempty"`
+	Latitude    *float64   `json:"latitude,omitempty"`
+	Longitude   *float64   `json:"longitude,omitempty"`
+	URL         string     `json:"url,omitempty"`
+}
+
+// EventSearchParams narrows an event search. Events overlapping [From, To)
+// match; zero times leave that end open.
+type EventSearchParams struct {
+	CityID     *uuid.UUID
+	From       time.Time
+	To         time.Time
+	Categories []string
+	// Lat and Lon with RadiusKm keep events located within the radius
+	Lat      *float64
+	Lon      *float64
+	RadiusKm float64
+	Page     PageRequest
+}
+
+// EventFeed is an iCal feed whose events are ingested into a city.
+type EventFeed struct {
+	ID              uuid.UUID  `json:"id"`
+	Name            string     `json:"name"`
+	SourceURL       string     `json:"source_url"`
+	CityID          uuid.UUID  `json:"city_id"`
+	DefaultCategory string     `json:"default_category"`
+	EventCount      int        `json:"event_count"`
+	IngestedAt      *time.Time `json:"ingested_at,omitempty"`
+	IngestJobID     *uuid.UUID `json:"ingest_job_id,omitempty"` // The ingestion just queued, when there is one
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// CreateEventFeedRequest registers an iCal feed to ingest into a city.
+type CreateEventFeedRequest struct {
+	Name            string    `json:"name"`
+	SourceURL       string    `json:"source_url"` // http(s) URL, or file:// path inside the server's feed directory
+	CityID          uuid.UUID `json:"city_id"`
+	DefaultCategory string    `json:"default_category,omitempty"`
+}
+
+// TravelDates are the first and last days of a trip, as YYYY-MM-DD.
+type TravelDates struct {
+	Start string `json:"start"`
+	End   string `json:"end"` // Defaults to Start
+}
+
+// Range returns the trip as the half-open interval from the start of its
+// first day to the end of its last, in loc.
+func (d TravelDates) Range(loc *time.Location) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation(time.DateOnly, d.Start, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: travel_dates.start must be YYYY-MM-DD", ErrBadRequest)
+	}
+	end := start
+	if d.End != "" {
+		if end, err = time.ParseInLocation(time.DateOnly, d.End, loc); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: travel_dates.end must be YYYY-MM-DD", ErrBadRequest)
+		}
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: travel_dates.end is before start", ErrBadRequest)
+	}
+	return start, end.AddDate(0, 0, 1), nil
+}
@@ -0,0 +1,72 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Interaction event kinds, matching the user_interaction_events.kind check constraint.
+const (
+	InteractionFavourite       = "favourite"
+	InteractionUnfavourite     = "unfavourite"
+	InteractionItinerarySave   = "itinerary_save"
+	InteractionItineraryRemove = "itinerary_remove"
+	InteractionListAdd         = "list_add"
+)
+
+// InteractionWeights is how strongly each kind of event says the user likes
+// (positive) or dislikes (negative) a POI.
+var InteractionWeights = map[string]float64{
+	InteractionFavourite:       1.0,
+	InteractionUnfavourite:     -1.0,
+	InteractionItinerarySave:   0.5,
+	InteractionItineraryRemove: -0.8,
+	InteractionListAdd:         0.7,
+}
+
+// Affinity dimensions, matching the user_affinities.dimension check constraint.
+const (
+	AffinityCategory = "category"
+	AffinityTag      = "tag"
+)
+
+// InteractionEvent is an implicit feedback signal on a POI. POIID is nil for
+// POIs that were only suggested by the LLM and never saved; they are known by
+// name. Category and tags are filled in from the POI when left empty.
+type InteractionEvent struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	POIID     *uuid.UUID `json:"poi_id,omitempty"`
+	POIName   string     `json:"poi_name"`
+	Category  string     `json:"category,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+	Kind      string     `json:"kind"`
+	Weight    float64    `json:"weight"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Affinity is how much a user favours (positive score) or avoids (negative
+// score) a category or tag, learned from their interaction events.
+type Affinity struct {
+	Dimension string    `json:"dimension"`
+	Value     string    `json:"value"`
+	Score     float64   `json:"score"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LearnedPreferences summarises a user's affinities for prompts.
+type LearnedPreferences struct {
+	Favoured []string `json:"favoured"`
+	Avoided  []string `json:"avoided"`
+	// RemovedPOIs are POIs the user recently got rid of
+	RemovedPOIs []string `json:"removed_pois"`
+}
+
+// PreferenceScore is how well a POI fits a user's taste.
+type PreferenceScore struct {
+	// Similarity to the taste vector; nil when either has no comparable embedding
+	Similarity *float64
+	// Feedback adjusts for learned affinities and the user's own feedback on the POI
+	Feedback float64
+}
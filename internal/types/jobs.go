@@ -0,0 +1,88 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Background job statuses, matching the background_jobs.status check constraint.
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+)
+
+// Background job kinds. A job with a TargetID works on that entity only; without
+// one it sweeps every entity whose embedding_generated_at is NULL. The target of
+// a user preference job is a search profile.
+const (
+	JobKindPOIEmbedding            = "poi_embedding"
+	JobKindCityEmbedding           = "city_embedding"
+	JobKindUserPreferenceEmbedding = "user_preference_embedding"
+)
+
+// Re-embedding job kinds. They take no target: each migrates its whole table to
+// the next model recorded in embedding_models, then cuts over.
+const (
+	JobKindPOIReembed            = "poi_reembed"
+	JobKindCityReembed           = "city_reembed"
+	JobKindUserPreferenceReembed = "user_preference_reembed"
+)
+
+// JobKindInteractionLearning folds the target user's new interaction events into
+// their affinities; without a target it handles every user with new events.
+const JobKindInteractionLearning = "interaction_learning"
+
+// JobKindGTFSImport downloads the target transit feed and replaces its stops
+// and rides.
+const JobKindGTFSImport = "gtfs_import"
+
+// JobKindEventFeedIngest fetches the target iCal feed and upserts its events.
+const JobKindEventFeedIngest = "event_feed_ingest"
+
+// JobKindAnalyticsRollup rolls recent analytics events up into the daily
+// aggregates, POI popularity and list counters.
+const JobKindAnalyticsRollup = "analytics_rollup"
+
+// Job is a durable unit of background work.
+type Job struct {
+	ID             uuid.UUID   `json:"id"`
+	Kind           string      `json:"kind"`
+	TargetID       *uuid.UUID  `json:"target_id,omitempty"`
+	Status         string      `json:"status"`
+	Attempts       int         `json:"attempts"`
+	MaxAttempts    int         `json:"max_attempts"`
+	Progress       JobProgress `json:"progress"`
+	LastError      *string     `json:"last_error,omitempty"`
+	RunAfter       time.Time   `json:"run_after"`
+	LockedBy       *string     `json:"-"`
+	LeaseExpiresAt *time.Time  `json:"lease_expires_at,omitempty"`
+	RequestedBy    *uuid.UUID  `json:"requested_by,omitempty"`
+	CreatedAt      time.Time   `json:"created_at"`
+	StartedAt      *time.Time  `json:"started_at,omitempty"`
+	CompletedAt    *time.Time  `json:"completed_at,omitempty"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+// JobProgress counts the items a job has worked through.
+type JobProgress struct {
+	Total  int `json:"total"`
+	Done   int `json:"done"`
+	Failed int `json:"failed"`
+}
+
+// JobFilter narrows a job listing. Empty fields match everything.
+type JobFilter struct {
+	Kind   string
+	Status string
+	Page   PageRequest
+}
+
+// EnqueueJobRequest asks for a background job. Omit TargetID to process every
+// entity that still needs it.
+type EnqueueJobRequest struct {
+	Kind     string     `json:"kind"`
+	TargetID *uuid.UUID `json:"target_id,omitempty"`
+}
@@ -0,0 +1,91 @@
+package types
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cursor is the sort key of the last item of a page; the next page starts
+// strictly after it. Time, Key and Num hold the sort columns an endpoint uses
+// and ID breaks ties, so every item has exactly one position. Clients only
+// ever see it as an opaque token.
+type Cursor struct {
+	Time *time.Time `json:"t,omitempty"`
+	Key  string     `json:"k,omitempty"`
+	Num  *float64   `json:"n,omitempty"`
+	ID   uuid.UUID  `json:"i"`
+}
+
+// cursorFields is Cursor without its text encoding, to marshal the fields.
+type cursorFields Cursor
+
+// MarshalText encodes the cursor as an opaque URL-safe token.
+func (c Cursor) MarshalText() ([]byte, error) {
+	b, err := json.Marshal(cursorFields(c))
+	if err != nil {
+		return nil, err
+	}
+	token := make([]byte, base64.RawURLEncoding.EncodedLen(len(b)))
+	base64.RawURLEncoding.Encode(token, b)
+	return token, nil
+}
+
+// UnmarshalText decodes a token made by MarshalText.
+func (c *Cursor) UnmarshalText(token []byte) error {
+	b := make([]byte, base64.RawURLEncoding.DecodedLen(len(token)))
+	n, err := base64.RawURLEncoding.Decode(b, token)
+	if err != nil {
+		return fmt.Errorf("%w: malformed cursor", ErrBadRequest)
+	}
+	var fields cursorFields
+	if err := json.Unmarshal(b[:n], &fields); err != nil {
+		return fmt.Errorf("%w: malformed cursor", ErrBadRequest)
+	}
+	*c = Cursor(fields)
+	return nil
+}
+
+// String returns the token of the cursor.
+func (c Cursor) String() string {
+	token, _ := c.MarshalText()
+	return string(token)
+}
+
+// PageRequest asks for up to Limit items after Cursor. A nil Cursor asks for
+// the first page.
+type PageRequest struct {
+	Cursor *Cursor
+	Limit  int
+}
+
+// Clamp applies the default limit when none was asked for and caps it at max.
+func (p PageRequest) Clamp(defaultLimit, maxLimit int) PageRequest {
+	if p.Limit <= 0 {
+		p.Limit = defaultLimit
+	}
+	if p.Limit > maxLimit {
+		p.Limit = maxLimit
+	}
+	return p
+}
+
+// FetchLimit is the number of rows to query: one more than the page, to tell
+// whether another page follows.
+func (p PageRequest) FetchLimit() int {
+	return p.Limit + 1
+}
+
+// NextPage trims items queried with FetchLimit to the page and returns the
+// cursor of the next page, nil on the last page.
+func NextPage[T any](items []T, limit int, cursorOf func(T) Cursor) ([]T, *Cursor) {
+	if len(items) <= limit {
+		return items, nil
+	}
+	items = items[:limit]
+	next := cursorOf(items[limit-1])
+	return items, &next
+}
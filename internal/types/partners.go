@@ -0,0 +1,111 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of place a partner listing can point at.
+const (
+	PartnerTargetPOI        = "poi"
+	PartnerTargetHotel      = "hotel"
+	PartnerTargetRestaurant = "restaurant"
+)
+
+// IsPartnerTarget reports whether kind is one of the partner target kinds.
+func IsPartnerTarget(kind string) bool {
+	switch kind {
+	case PartnerTargetPOI, PartnerTargetHotel, PartnerTargetRestaurant:
+		return true
+	}
+	return false
+}
+
+// Cancellation policies and booking modes of partner offers, with the same
+// values as AccommodationPreferences.CancellationPolicy and BookingFlexibility.
+const (
+	CancellationFree          = "free_cancellation"
+	CancellationPartialRefund = "partial_refund"
+	CancellationNonRefundable = "non_refundable"
+
+	BookingInstant     = "instant_book"
+	BookingRequestOnly = "request_only"
+)
+
+// PartnerInfo describes a booking partner in the registry.
+type PartnerInfo struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+// PartnerListing maps a hotel, restaurant or POI to its listing at a partner.
+type PartnerListing struct {
+	ID         uuid.UUID `json:"id"`
+	Partner    string    `json:"partner"`     // Registry key of the partner's adapter
+	ExternalID string    `json:"external_id"` // The partner's ID for the place
+	TargetKind string    `json:"target_kind"` // poi, hotel or restaurant
+	TargetID   uuid.UUID `json:"target_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type CreatePartnerListingRequest struct {
+	Partner    string    `json:"partner"`
+	ExternalID string    `json:"external_id"`
+	TargetKind string    `json:"target_kind"`
+	TargetID   uuid.UUID `json:"target_id"`
+}
+
+// BookingRequest is what a traveller wants to book: a stay from CheckIn to
+// CheckOut at a hotel, or a table at CheckIn at a restaurant. Without a
+// CheckIn partners are only asked for a deep link.
+type BookingRequest struct {
+	CheckIn  time.Time
+	CheckOut time.Time
+	Guests   int
+	Rooms    int
+}
+
+// Dated reports whether the request has a date to check availability for.
+func (r BookingRequest) Dated() bool {
+	return !r.CheckIn.IsZero()
+}
+
+// Nights is the length of a hotel stay, at least one.
+func (r BookingRequest) Nights() int {
+	nights := int(r.CheckOut.Sub(r.CheckIn).Hours()+12) / 24
+	if nights < 1 {
+		return 1
+	}
+	return nights
+}
+
+// PartnerAvailability is a partner's answer to whether a place can be booked.
+type PartnerAvailability struct {
+	Available          bool   `json:"available"`
+	InstantBook        bool   `json:"instant_book"`
+	CancellationPolicy string `json:"cancellation_policy,omitempty"`
+}
+
+// PriceQuote is a partner's total price for a booking.
+type PriceQuote struct {
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"` // ISO 4217 code
+}
+
+// PartnerOffer is one partner's offer for a place, shown on its detail page.
+// Availability and the quote are only set for dated requests.
+type PartnerOffer struct {
+	Partner            string      `json:"partner"`
+	PartnerName        string      `json:"partner_name"`
+	ExternalID         string      `json:"external_id"`
+	Available          *bool       `json:"available,omitempty"`
+	InstantBook        bool        `json:"instant_book"`
+	CancellationPolicy string      `json:"cancellation_policy,omitempty"`
+	Quote              *PriceQuote `json:"quote,omitempty"`
+	DeepLink           string      `json:"deep_link"`
+	// MatchesPreferences is false when the offer's cancellation policy or
+	// booking mode is not one the traveller's profile accepts.
+	MatchesPreferences bool `json:"matches_preferences"`
+}
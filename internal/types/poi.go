@@ -76,6 +76,10 @@ type POIDetailedInfo struct {
 	CreatedAt        time.Time         `json:"created_at"`
 	CuisineType      string            `json:"cuisine_type,omitempty"` // For restaurants
 	StarRating       string            `json:"star_rating,omitempty"`  // For hotels
+	Snippet          string            `json:"snippet,omitempty"`      // Matching text with <mark> highlights, for text searches
+	Explain          *HybridExplain    `json:"explain,omitempty"`      // Score breakdown, for hybrid searches with explain on
+	Accessibility    map[string]bool   `json:"accessibility,omitempty"`        // Known features; unlisted ones are unknown
+	AccessStatus     string            `json:"accessibility_status,omitempty"` // How the POI meets the user's accessibility needs, when they have any
 	Err              error             `json:"-"`
 }
 
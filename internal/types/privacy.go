@@ -0,0 +1,44 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Data export statuses, matching the user_data_exports.status check constraint.
+const (
+	DataExportPending    = "pending"
+	DataExportProcessing = "processing"
+	DataExportCompleted  = "completed"
+	DataExportFailed     = "failed"
+	DataExportExpired    = "expired"
+)
+
+// DataExport is a "download my data" job.
+type DataExport struct {
+	ID            uuid.UUID  `json:"id"`
+	UserID        uuid.UUID  `json:"user_id"`
+	Status        string     `json:"status"`
+	FilePath      *string    `json:"-"`
+	FileSizeBytes *int64     `json:"file_size_bytes,omitempty"`
+	ErrorMessage  *string    `json:"error_message,omitempty"`
+	RequestedAt   time.Time  `json:"requested_at"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+}
+
+// UserDataSection is one table of a user's data, already serialised as a JSON array.
+// Each section becomes <Name>.json in the export archive.
+type UserDataSection struct {
+	Name string
+	Data []byte
+}
+
+// AccountDeletionStatus reports whether an account is scheduled for deletion.
+type AccountDeletionStatus struct {
+	Scheduled    bool       `json:"scheduled"`
+	RequestedAt  *time.Time `json:"requested_at,omitempty"`
+	ScheduledFor *time.Time `json:"scheduled_for,omitempty"`
+}
@@ -153,6 +153,7 @@ type UserPreferenceProfileResponse struct {
 	DiningPreferences        *DiningPreferences        `json:"dining_preferences,omitempty"`
 	ActivityPreferences      *ActivityPreferences      `json:"activity_preferences,omitempty"`
 	ItineraryPreferences     *ItineraryPreferences     `json:"itinerary_preferences,omitempty"`
+	AccessibilityNeeds       *AccessibilityNeeds       `json:"accessibility_needs,omitempty"`
 	CreatedAt                time.Time                 `json:"created_at"`
 	UpdatedAt                time.Time                 `json:"updated_at"`
 }
@@ -178,6 +179,7 @@ type CreateUserPreferenceProfileParams struct {
 	DiningPreferences        *DiningPreferences        `json:"dining_preferences,omitempty"`
 	ActivityPreferences      *ActivityPreferences      `json:"activity_preferences,omitempty"`
 	ItineraryPreferences     *ItineraryPreferences     `json:"itinerary_preferences,omitempty"`
+	AccessibilityNeeds       *AccessibilityNeeds       `json:"accessibility_needs,omitempty"`
 }
 
 func (p *CreateUserPreferenceProfileParams) UnmarshalJSON(b []byte) error {
@@ -252,6 +254,7 @@ type UpdateSearchProfileParams struct {
 	DiningPreferences        *DiningPreferences        `json:"dining_preferences,omitempty"`
 	ActivityPreferences      *ActivityPreferences      `json:"activity_preferences,omitempty"`
 	ItineraryPreferences     *ItineraryPreferences     `json:"itinerary_preferences,omitempty"`
+	AccessibilityNeeds       *AccessibilityNeeds       `json:"accessibility_needs,omitempty"`
 	UpdateAt                 *time.Time                `json:"updated_at,omitempty"` // Optional, can be set to nil
 }
 
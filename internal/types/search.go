@@ -0,0 +1,187 @@
+package types
+
+import "fmt"
+
+// Ways hybrid search fuses its ranking signals.
+const (
+	// FusionRRF sums weight / (k + rank) over the signals, so only the order
+	// within each signal matters, not how its scores are scaled.
+	FusionRRF = "rrf"
+	// FusionLinear sums the weighted 0-1 scores, for weights fitted offline.
+	FusionLinear = "linear"
+)
+
+// HybridWeights is how much each ranking signal counts in hybrid search.
+type HybridWeights struct {
+	Lexical    float64 `json:"lexical"`
+	Semantic   float64 `json:"semantic"`
+	Spatial    float64 `json:"spatial"`
+	Rating     float64 `json:"rating"`
+	Popularity float64 `json:"popularity"`
+}
+
+// DefaultHybridWeights ranks by relevance and distance first; rating and
+// popularity break ties between similar POIs.
+var DefaultHybridWeights = HybridWeights{
+	Lexical:    1.0,
+	Semantic:   1.0,
+	Spatial:    1.0,
+	Rating:     0.3,
+	Popularity: 0.2,
+}
+
+// HybridWeightsFromSemantic maps the older single semantic weight, the share
+// of text relevance against proximity, onto the default weights. 0.5 gives
+// DefaultHybridWeights.
+func HybridWeightsFromSemantic(semanticWeight float64) HybridWeights {
+	w := DefaultHybridWeights
+	w.Lexical *= 2 * semanticWeight
+	w.Semantic *= 2 * semanticWeight
+	w.Spatial *= 2 * (1 - semanticWeight)
+	return w
+}
+
+// Validate checks that no weight is negative and that at least one counts.
+func (w HybridWeights) Validate() error {
+	for name, v := range map[string]float64{
+		"lexical":    w.Lexical,
+		"semantic":   w.Semantic,
+		"spatial":    w.Spatial,
+		"rating":     w.Rating,
+		"popularity": w.Popularity,
+	} {
+		if v < 0 {
+			return fmt.Errorf("%w: %s weight must not be negative, got %f", ErrBadRequest, name, v)
+		}
+	}
+	if w.Lexical+w.Semantic+w.Spatial+w.Rating+w.Popularity == 0 {
+		return fmt.Errorf("%w: at least one weight must be positive", ErrBadRequest)
+	}
+	return nil
+}
+
+// HybridSearchOptions controls how hybrid search ranks its results.
+type HybridSearchOptions struct {
+	Fusion  string        `json:"fusion"`
+	Weights HybridWeights `json:"weights"`
+	// Explain attaches a HybridExplain to every result.
+	Explain bool `json:"explain"`
+}
+
+// DefaultHybridSearchOptions fuses the default weights with RRF.
+func DefaultHybridSearchOptions() HybridSearchOptions {
+	return HybridSearchOptions{Fusion: FusionRRF, Weights: DefaultHybridWeights}
+}
+
+// Validate checks the fusion mode and the weights.
+func (o HybridSearchOptions) Validate() error {
+	if o.Fusion != FusionRRF && o.Fusion != FusionLinear {
+		return fmt.Errorf("%w: unknown fusion %q, want %q or %q", ErrBadRequest, o.Fusion, FusionRRF, FusionLinear)
+	}
+	return o.Weights.Validate()
+}
+
+// HybridComponent is one signal of a hybrid search result. Score and Rank are
+// nil when the POI lacks the signal, e.g. no embedding or no text match; the
+// signal then adds nothing instead of counting as a zero score.
+type HybridComponent struct {
+	Score        *float64 `json:"score"`
+	Rank         *int     `json:"rank"`
+	Contribution float64  `json:"contribution"`
+}
+
+// HybridExplain breaks a hybrid search score down into its signals, for
+// tuning relevance.
+type HybridExplain struct {
+	Fusion     string          `json:"fusion"`
+	Weights    HybridWeights   `json:"weights"`
+	Lexical    HybridComponent `json:"lexical"`
+	Semantic   HybridComponent `json:"semantic"`
+	Spatial    HybridComponent `json:"spatial"`
+	Rating     HybridComponent `json:"rating"`
+	Popularity HybridComponent `json:"popularity"`
+	Score      float64         `json:"score"`
+}
+
+// Facets of POI search results.
+const (
+	FacetCategory   = "category"
+	FacetPriceLevel = "price_level"
+	FacetRating     = "rating"
+	FacetTag        = "tag"
+	FacetDistance   = "distance"
+)
+
+// FacetSelection is a multi-select facet filter. A POI must have one of the
+// selected values of every facet that has a selection. Price levels are "free"
+// or "1" to "4"; ratings and distances are the bucket values returned in
+// POIFacets, e.g. "4-4.5" or "1-3km".
+type FacetSelection struct {
+	Categories  []string `json:"categories,omitempty"`
+	PriceLevels []string `json:"price_levels,omitempty"`
+	Ratings     []string `json:"ratings,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Distances   []string `json:"distances,omitempty"`
+}
+
+// Values returns the selected values of a facet.
+func (s FacetSelection) Values(facet string) []string {
+	switch facet {
+	case FacetCategory:
+		return s.Categories
+	case FacetPriceLevel:
+		return s.PriceLevels
+	case FacetRating:
+		return s.Ratings
+	case FacetTag:
+		return s.Tags
+	case FacetDistance:
+		return s.Distances
+	}
+	return nil
+}
+
+// IsEmpty reports whether nothing is selected.
+func (s FacetSelection) IsEmpty() bool {
+	return len(s.Categories)+len(s.PriceLevels)+len(s.Ratings)+len(s.Tags)+len(s.Distances) == 0
+}
+
+// FacetBucket is one value of a facet and how many results have it.
+type FacetBucket struct {
+	Value    string `json:"value"`
+	Count    int    `json:"count"`
+	Selected bool   `json:"selected,omitempty"`
+}
+
+// POIFacets counts search results by facet value. A facet's counts ignore its
+// own selection, so every value shows how many results picking it would add.
+type POIFacets struct {
+	Categories  []FacetBucket `json:"categories"`
+	PriceLevels []FacetBucket `json:"price_levels"`
+	Ratings     []FacetBucket `json:"ratings"`
+	Tags        []FacetBucket `json:"tags"`
+	Distances   []FacetBucket `json:"distances,omitempty"`
+}
+
+// Buckets returns a pointer to the buckets of a facet, nil for unknown facets.
+func (f *POIFacets) Buckets(facet string) *[]FacetBucket {
+	switch facet {
+	case FacetCategory:
+		return &f.Categories
+	case FacetPriceLevel:
+		return &f.PriceLevels
+	case FacetRating:
+		return &f.Ratings
+	case FacetTag:
+		return &f.Tags
+	case FacetDistance:
+		return &f.Distances
+	}
+	return nil
+}
+
+// POISearchResult is a page of POI search results with their facets.
+type POISearchResult struct {
+	POIs   []POIDetailedInfo `json:"results"`
+	Facets POIFacets         `json:"facets"`
+}
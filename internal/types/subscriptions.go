@@ -1,6 +1,42 @@
 package types
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Subscription plans, matching the subscription_plan_type enum.
+const (
+	PlanFree           = "free"
+	PlanPremiumMonthly = "premium_monthly"
+	PlanPremiumAnnual  = "premium_annual"
+)
+
+// Subscription statuses, matching the subscription_status enum.
+const (
+	SubscriptionActive   = "active"
+	SubscriptionTrialing = "trialing"
+	SubscriptionPastDue  = "past_due"
+	SubscriptionCanceled = "canceled"
+	SubscriptionExpired  = "expired"
+)
+
+// Payment provider webhook event types.
+const (
+	PaymentEventSubscriptionActivated = "subscription.activated"
+)
+
+// PremiumPlans lists the paid plans.
+var PremiumPlans = []string{PlanPremiumMonthly, PlanPremiumAnnual}
+
+// ValidPlans is the set of known plans.
+var ValidPlans = map[string]struct{}{
+	PlanFree:           {},
+	PlanPremiumMonthly: {},
+	PlanPremiumAnnual:  {},
+}
 
 // SubscriptionRepository defines methods for accessing subscription data.
 type SubscriptionRepository interface {
@@ -12,6 +48,43 @@ type SubscriptionRepository interface {
 
 // Subscription holds basic plan and status information.
 type Subscription struct {
-	Plan   string `json:"plan"`   // e.g., "free", "premium_monthly".
-	Status string `json:"status"` // e.g., "active", "past_due".
+	Plan                   string     `json:"plan"`   // e.g., "free", "premium_monthly".
+	Status                 string     `json:"status"` // e.g., "active", "past_due".
+	UserID                 uuid.UUID  `json:"user_id,omitempty"`
+	StartDate              *time.Time `json:"start_date,omitempty"`
+	EndDate                *time.Time `json:"end_date,omitempty"` // NULL for the free plan.
+	TrialEndDate           *time.Time `json:"trial_end_date,omitempty"`
+	ExternalProvider       *string    `json:"external_provider,omitempty"`
+	ExternalSubscriptionID *string    `json:"external_subscription_id,omitempty"`
+	UpdatedAt              *time.Time `json:"updated_at,omitempty"`
+}
+
+// StartTrialRequest starts a free trial of a premium plan.
+type StartTrialRequest struct {
+	Plan string `json:"plan"` // premium_monthly or premium_annual.
+}
+
+// ChangePlanRequest cancels the user's paid plan or trial. Paid plans are only
+// granted once the payment provider confirms them, or by an admin override.
+type ChangePlanRequest struct {
+	Plan string `json:"plan"` // free.
+}
+
+// SubscriptionResponse is returned after a plan change. AccessToken carries the new
+// plan claims so the client does not have to wait for its next refresh.
+type SubscriptionResponse struct {
+	Subscription *Subscription `json:"subscription"`
+	AccessToken  string        `json:"access_token,omitempty"`
+}
+
+// PaymentEvent is a signed notification from the payment provider. Only
+// subscription.activated changes the plan; other types are acknowledged and ignored.
+type PaymentEvent struct {
+	ID                     string    `json:"id"`
+	Type                   string    `json:"type"`
+	Provider               string    `json:"provider"`
+	UserID                 uuid.UUID `json:"user_id"`
+	Plan                   string    `json:"plan"`
+	ExternalSubscriptionID string    `json:"subscription_id"`
+	PeriodEnd              time.Time `json:"period_end"` // End of the paid billing period.
 }
@@ -0,0 +1,76 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Where a travel time comes from.
+const (
+	TravelSourceHeuristic = "heuristic" // Straight-line distance at a typical speed for the mode
+	TravelSourceGTFS      = "gtfs"      // A timetabled ride from an imported transit feed
+	TravelSourceUnknown   = "unknown"   // A stop has no coordinates
+)
+
+// TravelLeg is the trip from one itinerary stop to the next.
+type TravelLeg struct {
+	From            string              `json:"from"`
+	To              string              `json:"to"`
+	Mode            TransportPreference `json:"mode"`            // walk, public or car, never any
+	DistanceMeters  float64             `json:"distance_meters"` // Straight line
+	DurationMinutes int                 `json:"duration_minutes"`
+	Source          string              `json:"source"`
+	// Transit stops boarded and left, for GTFS legs
+	BoardAt  string `json:"board_at,omitempty"`
+	AlightAt string `json:"alight_at,omitempty"`
+}
+
+// TotalTravelMinutes adds up the legs whose duration is known.
+func TotalTravelMinutes(legs []TravelLeg) int {
+	total := 0
+	for _, leg := range legs {
+		total += leg.DurationMinutes
+	}
+	return total
+}
+
+// TransitFeed is a GTFS feed whose timetable is used for public transport legs.
+type TransitFeed struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	SourceURL   string     `json:"source_url"`
+	CityID      *uuid.UUID `json:"city_id,omitempty"`
+	StopCount   int        `json:"stop_count"`
+	RideCount   int        `json:"ride_count"`
+	ImportedAt  *time.Time `json:"imported_at,omitempty"`
+	ImportJobID *uuid.UUID `json:"import_job_id,omitempty"` // The import just queued, when there is one
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// CreateTransitFeedRequest registers a GTFS feed to import from SourceURL.
+type CreateTransitFeedRequest struct {
+	Name      string     `json:"name"`
+	SourceURL string     `json:"source_url"`
+	CityID    *uuid.UUID `json:"city_id,omitempty"`
+}
+
+// TransitStop is a stop of an imported feed. WalkMeters is the straight-line
+// distance from the location it was looked up from.
+type TransitStop struct {
+	FeedID     uuid.UUID
+	StopID     string
+	Name       string
+	Latitude   float64
+	Longitude  float64
+	WalkMeters float64
+}
+
+// TransitRide is the quickest single ride between two stops of a feed.
+type TransitRide struct {
+	FeedID      uuid.UUID
+	FromStopID  string
+	ToStopID    string
+	RideSeconds int
+}
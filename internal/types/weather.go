@@ -0,0 +1,148 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Weather conditions of a forecast day, from best to worst.
+const (
+	WeatherClear  = "clear"
+	WeatherCloudy = "cloudy"
+	WeatherFog    = "fog"
+	WeatherRain   = "rain"
+	WeatherSnow   = "snow"
+	WeatherStorm  = "storm"
+)
+
+// rainyPrecipitationProbability is the chance of precipitation, in percent,
+// from which a day counts as rainy whatever its condition.
+const rainyPrecipitationProbability = 60
+
+// WeatherForecast is the daily forecast for a location, today first.
+type WeatherForecast struct {
+	Latitude  float64         `json:"latitude"`
+	Longitude float64         `json:"longitude"`
+	Timezone  string          `json:"timezone,omitempty"`
+	Source    string          `json:"source"`
+	Days      []DailyForecast `json:"days"`
+}
+
+// DailyForecast is the forecast for a single day.
+type DailyForecast struct {
+	Date                     string  `json:"date"` // YYYY-MM-DD, local to the location
+	Condition                string  `json:"condition"`
+	TempMinC                 float64 `json:"temp_min_c"`
+	TempMaxC                 float64 `json:"temp_max_c"`
+	PrecipitationMm          float64 `json:"precipitation_mm"`
+	PrecipitationProbability int     `json:"precipitation_probability"` // Percent
+}
+
+// Rainy reports whether outdoor plans are best avoided on the day.
+func (d DailyForecast) Rainy() bool {
+	switch d.Condition {
+	case WeatherRain, WeatherSnow, WeatherStorm:
+		return true
+	}
+	return d.PrecipitationProbability >= rainyPrecipitationProbability
+}
+
+// Today returns the first forecast day, or false when there is none.
+func (f *WeatherForecast) Today() (DailyForecast, bool) {
+	if f == nil || len(f.Days) == 0 {
+		return DailyForecast{}, false
+	}
+	return f.Days[0], true
+}
+
+// During returns the forecast days on the travel dates, in order. It is empty
+// when the trip is past the end of the forecast.
+func (f *WeatherForecast) During(dates TravelDates) ([]DailyForecast, error) {
+	if _, _, err := dates.Range(time.UTC); err != nil {
+		return nil, err
+	}
+	end := dates.End
+	if end == "" {
+		end = dates.Start
+	}
+	var days []DailyForecast
+	if f == nil {
+		return days, nil
+	}
+	// YYYY-MM-DD dates sort as strings
+	for _, d := range f.Days {
+		if d.Date >= dates.Start && d.Date <= end {
+			days = append(days, d)
+		}
+	}
+	return days, nil
+}
+
+// Summary describes today's weather in a sentence, for GeneralCityData.Weather.
+func (f *WeatherForecast) Summary() string {
+	today, ok := f.Today()
+	if !ok {
+		return ""
+	}
+	return "Today: " + today.describe()
+}
+
+// SummarizeDays describes the weather of each day, for GeneralCityData.Weather.
+func SummarizeDays(days []DailyForecast) string {
+	parts := make([]string, len(days))
+	for i, d := range days {
+		parts[i] = d.Date + ": " + d.describe()
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (d DailyForecast) describe() string {
+	return fmt.Sprintf("%s, %.0f-%.0f°C, %d%% chance of precipitation",
+		d.Condition, d.TempMinC, d.TempMaxC, d.PrecipitationProbability)
+}
+
+// WeatherSwap records an outdoor itinerary stop replaced by an indoor one
+// because of the forecast.
+type WeatherSwap struct {
+	Replaced    string `json:"replaced"`
+	Replacement string `json:"replacement"`
+	Reason      string `json:"reason"`
+}
+
+// outdoorKeywords mark a POI category or tag as outdoors.
+var outdoorKeywords = []string{
+	"park", "garden", "beach", "viewpoint", "lookout", "zoo", "hiking", "trail",
+	"outdoor", "nature", "square", "plaza", "promenade", "waterfront", "harbour",
+	"harbor", "lake", "river", "mountain", "bridge", "market", "landmark",
+}
+
+// indoorKeywords mark a POI category or tag as indoors. They win over
+// outdoorKeywords, so "indoor market" is indoors.
+var indoorKeywords = []string{
+	"indoor", "museum", "gallery", "theatre", "theater", "cinema", "library",
+	"aquarium", "church", "cathedral", "mall", "shopping", "restaurant", "cafe",
+	"bar", "spa", "concert", "exhibition",
+}
+
+// IsOutdoor reports whether a POI is enjoyed outdoors, judged by its category
+// and tags. A POI that is neither clearly indoor nor outdoor is not outdoor.
+func IsOutdoor(poi POIDetailedInfo) bool {
+	return poiMatches(poi, outdoorKeywords) && !poiMatches(poi, indoorKeywords)
+}
+
+// IsIndoor reports whether a POI is clearly enjoyed indoors.
+func IsIndoor(poi POIDetailedInfo) bool {
+	return poiMatches(poi, indoorKeywords)
+}
+
+func poiMatches(poi POIDetailedInfo, keywords []string) bool {
+	labels := append([]string{poi.Category}, poi.Tags...)
+	return slices.ContainsFunc(labels, func(label string) bool {
+		label = strings.ToLower(label)
+		return slices.ContainsFunc(keywords, func(k string) bool {
+			return strings.Contains(label, k)
+		})
+	})
+}
@@ -19,8 +19,10 @@ import (
 	"github.com/go-chi/httprate"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 
+	appMiddleware "github.com/FACorreiaa/go-poi-au-suggestions/app/middleware"
 	"github.com/FACorreiaa/go-poi-au-suggestions/app/observability/metrics"
 	"github.com/FACorreiaa/go-poi-au-suggestions/app/observability/tracer"
+	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/audit"
 	"github.com/FACorreiaa/go-poi-au-suggestions/internal/api/auth"
 	router "github.com/FACorreiaa/go-poi-au-suggestions/internal/router"
 
@@ -132,7 +134,16 @@ func main() {
 		os.Exit(1)
 	}
 
-	authenticateMiddleware := auth.Authenticate(logger, cfg.JWT)
+	// --- Background Workers ---
+	go c.PrivacyService.Run(ctx)      // Data exports and account deletion after the grace period
+	go c.SubscriptionService.Run(ctx) // Expires subscriptions past their end date
+	go c.JobsService.Run(ctx)         // Embedding generation and other queued jobs
+	go c.AutocompleteService.Run(ctx) // Keeps the autocomplete dictionary warm
+	go c.AnalyticsService.Run(ctx)    // Writes the server-side analytics events
+
+	authenticateMiddleware := auth.Authenticate(logger, cfg.JWT, c.JWTKeys)
+	appMiddleware.KeyFunc = c.JWTKeys.Keyfunc
+	appMiddleware.ValidMethods = c.JWTKeys.ValidMethods()
 	// --- Router Setup ---
 	routerConfig := &router.Config{
 		AuthHandler:             c.AuthHandler,
@@ -145,6 +156,17 @@ func main() {
 		ItineraryListHandler:    c.ItineraryListHandler,
 		CityHandler:             c.CityHandler,
 		RecentsHandler:          c.RecentsHandler,
+		AdminHandler:            c.AdminHandler,
+		AuditHandler:            c.AuditHandler,
+		PrivacyHandler:          c.PrivacyHandler,
+		SubscriptionHandler:     c.SubscriptionHandler,
+		JobsHandler:             c.JobsHandler,
+		EmbeddingsHandler:       c.EmbeddingsHandler,
+		TravelHandler:           c.TravelHandler,
+		EventsHandler:           c.EventsHandler,
+		PartnersHandler:         c.PartnersHandler,
+		AnalyticsHandler:        c.AnalyticsHandler,
+		AutocompleteHandler:     c.AutocompleteHandler,
 		AuthenticateMiddleware:  authenticateMiddleware,
 		Logger:                  logger,
 	}
@@ -155,6 +177,7 @@ func main() {
 	//r.Use(authenticateMiddleware)
 	r.Use(chiMiddleware.RequestID)
 	r.Use(chiMiddleware.RealIP)
+	r.Use(audit.RequestMetadata) // Client IP and user agent for audit entries
 	r.Use(l.StructuredLogger(logger))
 	r.Use(chiMiddleware.Recoverer)
 	r.Use(chiMiddleware.StripSlashes)